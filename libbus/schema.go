@@ -0,0 +1,107 @@
+package libbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersionField is the top-level JSON field that carries the schema
+// version of a bus message.
+const SchemaVersionField = "schema_version"
+
+var (
+	// ErrUnsupportedSchemaVersion is returned when a message was produced with a
+	// schema version outside the range the consumer understands.
+	ErrUnsupportedSchemaVersion = errors.New("unsupported message schema version")
+	// ErrInvalidMessage is returned when a message does not match its schema.
+	ErrInvalidMessage = errors.New("message does not match schema")
+)
+
+// MessageSchema describes a versioned JSON message exchanged over the bus.
+//
+// Producers stamp Version into every message via Encode. Consumers accept any
+// version in [MinVersion, Version] via Decode, so during a rolling upgrade a
+// newer runtime keeps reading what older runtimes publish, and an older
+// runtime rejects (instead of silently misparsing) messages it cannot read.
+// Additive, backwards compatible changes do not require a version bump.
+//
+// Messages published before versioning existed carry no version field and are
+// treated as version 0; they are only accepted while MinVersion is 0.
+type MessageSchema struct {
+	// Name identifies the message type in errors, e.g. "taskengine.task_event".
+	Name string
+	// Version is the version written by this runtime.
+	Version int
+	// MinVersion is the oldest version this runtime can still read.
+	MinVersion int
+	// Required lists top-level JSON fields every message must contain.
+	Required []string
+}
+
+// Encode marshals v as a JSON object, stamps the schema version and validates
+// the result against the schema.
+func (s MessageSchema) Encode(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal: %w", s.Name, err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("%s: message must be a JSON object: %w", s.Name, ErrInvalidMessage)
+	}
+	version, err := json.Marshal(s.Version)
+	if err != nil {
+		return nil, err
+	}
+	fields[SchemaVersionField] = version
+	if err := s.checkRequired(fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// Decode validates data against the schema and unmarshals it into v.
+// It returns the schema version the message was produced with.
+func (s MessageSchema) Decode(data []byte, v any) (int, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return 0, fmt.Errorf("%s: message must be a JSON object: %w", s.Name, ErrInvalidMessage)
+	}
+	version := 0
+	if raw, ok := fields[SchemaVersionField]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return 0, fmt.Errorf("%s: invalid %s: %w", s.Name, SchemaVersionField, ErrInvalidMessage)
+		}
+	}
+	if version < s.MinVersion || version > s.Version {
+		return version, fmt.Errorf("%s: version %d not in [%d, %d]: %w", s.Name, version, s.MinVersion, s.Version, ErrUnsupportedSchemaVersion)
+	}
+	if err := s.checkRequired(fields); err != nil {
+		return version, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return version, fmt.Errorf("%s: %v: %w", s.Name, err, ErrInvalidMessage)
+	}
+	return version, nil
+}
+
+func (s MessageSchema) checkRequired(fields map[string]json.RawMessage) error {
+	for _, name := range s.Required {
+		raw, ok := fields[name]
+		if !ok || string(raw) == "null" {
+			return fmt.Errorf("%s: missing required field %q: %w", s.Name, name, ErrInvalidMessage)
+		}
+	}
+	return nil
+}
+
+// PublishMessage encodes v with schema and publishes it to subject.
+func PublishMessage(ctx context.Context, m Messenger, subject string, schema MessageSchema, v any) error {
+	data, err := schema.Encode(v)
+	if err != nil {
+		return err
+	}
+	return m.Publish(ctx, subject, data)
+}
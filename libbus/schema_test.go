@@ -0,0 +1,66 @@
+package libbus_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	libbus "github.com/contenox/contenox/libbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusMessage struct {
+	ID     string `json:"id"`
+	Status string `json:"status,omitempty"`
+}
+
+var statusSchema = libbus.MessageSchema{
+	Name:       "test.status",
+	Version:    2,
+	MinVersion: 0,
+	Required:   []string{"id"},
+}
+
+func TestUnit_MessageSchema_RoundTrip(t *testing.T) {
+	data, err := statusSchema.Encode(statusMessage{ID: "m1", Status: "done"})
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, float64(2), fields[libbus.SchemaVersionField])
+
+	var got statusMessage
+	version, err := statusSchema.Decode(data, &got)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, statusMessage{ID: "m1", Status: "done"}, got)
+}
+
+func TestUnit_MessageSchema_AcceptsLegacyMessages(t *testing.T) {
+	var got statusMessage
+	version, err := statusSchema.Decode([]byte(`{"id":"old"}`), &got)
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.Equal(t, "old", got.ID)
+
+	strict := statusSchema
+	strict.MinVersion = 1
+	_, err = strict.Decode([]byte(`{"id":"old"}`), &got)
+	assert.ErrorIs(t, err, libbus.ErrUnsupportedSchemaVersion)
+}
+
+func TestUnit_MessageSchema_RejectsInvalidMessages(t *testing.T) {
+	var got statusMessage
+
+	_, err := statusSchema.Decode([]byte(`{"id":"x","schema_version":3}`), &got)
+	assert.ErrorIs(t, err, libbus.ErrUnsupportedSchemaVersion)
+
+	_, err = statusSchema.Decode([]byte(`{"status":"done","schema_version":2}`), &got)
+	assert.ErrorIs(t, err, libbus.ErrInvalidMessage)
+
+	_, err = statusSchema.Decode([]byte(`["not","an","object"]`), &got)
+	assert.ErrorIs(t, err, libbus.ErrInvalidMessage)
+
+	_, err = statusSchema.Encode("plain string")
+	assert.ErrorIs(t, err, libbus.ErrInvalidMessage)
+}
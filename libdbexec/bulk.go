@@ -0,0 +1,196 @@
+package libdbexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Bind parameters per batched statement. PostgreSQL's wire protocol caps
+// parameters at 65535. SQLite accepts 32766, but binding numbered parameters
+// slows down superlinearly with the statement size, and batches beyond a few
+// hundred parameters are slower than smaller ones.
+const (
+	maxParamsSQLite   = 256
+	maxParamsPostgres = 65535
+)
+
+// copyThreshold is the row count from which InsertRows uses COPY on PostgreSQL.
+// Below it a multi-row INSERT is cheaper than setting up the copy.
+const copyThreshold = 1000
+
+// InsertRows inserts rows into table in as few statements as possible and
+// returns the number of rows inserted. Each row holds one value per column,
+// in column order.
+//
+// On PostgreSQL large inserts use COPY, unless a row holds []byte values:
+// COPY encodes those as bytea, which would corrupt JSON columns fed with raw
+// bytes. Otherwise rows are sent as multi-row INSERT ... VALUES statements
+// sized for the driver. Either way the insert is atomic: on an Exec without
+// a transaction, multi-statement inserts run in a transaction of their own.
+func InsertRows(ctx context.Context, exec Exec, table string, columns []string, rows [][]any) (int64, error) {
+	if err := checkRows(columns, rows); err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	if db, ok := useCopy(exec, rows); ok {
+		return db.copyIn(ctx, table, columns, rows)
+	}
+	return insertBatches(ctx, exec, table, columns, rows, "")
+}
+
+// UpsertRows inserts rows like InsertRows; rows that conflict with an existing
+// row on conflictColumns update that row's remaining columns instead. When
+// every column is a conflict column, conflicting rows are skipped.
+// UpsertRows always uses INSERT ... ON CONFLICT, which PostgreSQL and SQLite
+// both support. rows must not repeat a conflict key: PostgreSQL rejects a
+// statement that updates the same row twice.
+func UpsertRows(ctx context.Context, exec Exec, table string, columns, conflictColumns []string, rows [][]any) (int64, error) {
+	if err := checkRows(columns, rows); err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	if len(conflictColumns) == 0 {
+		return 0, errors.New("libdb: upsert requires conflict columns")
+	}
+	var updates []string
+	for _, col := range columns {
+		if !slices.Contains(conflictColumns, col) {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+		}
+	}
+	onConflict := fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(conflictColumns, ", "))
+	if len(updates) > 0 {
+		onConflict = fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflictColumns, ", "), strings.Join(updates, ", "))
+	}
+	return insertBatches(ctx, exec, table, columns, rows, onConflict)
+}
+
+func checkRows(columns []string, rows [][]any) error {
+	if len(columns) == 0 {
+		return errors.New("libdb: bulk insert requires columns")
+	}
+	for i, r := range rows {
+		if len(r) != len(columns) {
+			return fmt.Errorf("libdb: row %d has %d values for %d columns", i, len(r), len(columns))
+		}
+	}
+	return nil
+}
+
+// insertBatches sends rows as multi-row INSERT statements, each followed by
+// suffix (e.g. an ON CONFLICT clause).
+func insertBatches(ctx context.Context, exec Exec, table string, columns []string, rows [][]any, suffix string) (int64, error) {
+	maxParams := maxParamsSQLite
+	if exec.DriverName() == "postgres" {
+		maxParams = maxParamsPostgres
+	}
+	batchSize := max(maxParams/len(columns), 1)
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+
+	if db, ok := exec.(*txAwareDB); ok && db.tx == nil && len(rows) > batchSize {
+		// One transaction instead of a commit per batch.
+		return db.inTransaction(ctx, func(tx Exec) (int64, error) {
+			return insertBatches(ctx, tx, table, columns, rows, suffix)
+		})
+	}
+
+	var inserted int64
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+		var stmt strings.Builder
+		stmt.WriteString(prefix)
+		args := make([]any, 0, len(batch)*len(columns))
+		for i, r := range batch {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			stmt.WriteByte('(')
+			for j := range columns {
+				if j > 0 {
+					stmt.WriteString(", ")
+				}
+				fmt.Fprintf(&stmt, "$%d", len(args)+j+1)
+			}
+			stmt.WriteByte(')')
+			args = append(args, r...)
+		}
+		if suffix != "" {
+			stmt.WriteString(" " + suffix)
+		}
+		res, err := exec.ExecContext(ctx, stmt.String(), args...)
+		if err != nil {
+			return inserted, err
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			inserted += n
+		}
+	}
+	return inserted, nil
+}
+
+// inTransaction runs fn on an executor bound to a new transaction and commits
+// it when fn succeeds.
+func (s *txAwareDB) inTransaction(ctx context.Context, fn func(tx Exec) (int64, error)) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: begin transaction failed: %w", ErrTxFailed, s.errTranslate(err))
+	}
+	defer tx.Rollback()
+	n, err := fn(&txAwareDB{tx: tx, errTranslate: s.errTranslate, driverName: s.driverName})
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%w: commit failed: %w", ErrTxFailed, s.errTranslate(err))
+	}
+	return n, nil
+}
+
+// copyIn loads rows with PostgreSQL's COPY FROM STDIN. Outside a transaction
+// it runs in one of its own so the copy is atomic either way.
+func (s *txAwareDB) copyIn(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	if s.tx == nil {
+		return s.inTransaction(ctx, func(tx Exec) (int64, error) {
+			return tx.(*txAwareDB).copyIn(ctx, table, columns, rows)
+		})
+	}
+	stmt, err := s.tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return 0, s.errTranslate(err)
+	}
+	defer stmt.Close()
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx, r...); err != nil {
+			return 0, s.errTranslate(err)
+		}
+	}
+	// The final Exec without arguments flushes the buffered rows.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, s.errTranslate(err)
+	}
+	return int64(len(rows)), nil
+}
+
+// useCopy reports whether InsertRows loads rows with COPY and returns the
+// PostgreSQL executor to copy with.
+func useCopy(exec Exec, rows [][]any) (*txAwareDB, bool) {
+	db, ok := exec.(*txAwareDB)
+	if !ok || db.driverName != "postgres" || len(rows) < copyThreshold || hasBytes(rows) {
+		return nil, false
+	}
+	return db, true
+}
+
+func hasBytes(rows [][]any) bool {
+	for _, r := range rows {
+		for _, v := range r {
+			if _, ok := v.([]byte); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package libdbexec
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bulkTestSchema = `CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, data BLOB, n INTEGER);`
+
+var itemColumns = []string{"id", "name", "data", "n"}
+
+// itemsPerBatch is how many item rows fit into one SQLite statement.
+const itemsPerBatch = maxParamsSQLite / 4
+
+func setupBulkDB(t *testing.T) (context.Context, Exec) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "bulk.db"), bulkTestSchema)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return ctx, db.WithoutTransaction()
+}
+
+// recordingExec records the statements and arguments sent through it.
+type recordingExec struct {
+	Exec
+	stmts []string
+	args  [][]any
+}
+
+func (r *recordingExec) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r.stmts = append(r.stmts, query)
+	r.args = append(r.args, args)
+	return r.Exec.ExecContext(ctx, query, args...)
+}
+
+func itemRows(n int) [][]any {
+	rows := make([][]any, n)
+	for i := range rows {
+		rows[i] = []any{i + 1, fmt.Sprintf("item-%d", i+1), nil, i}
+	}
+	return rows
+}
+
+func countItems(t *testing.T, ctx context.Context, exec Exec) int {
+	t.Helper()
+	var n int
+	require.NoError(t, exec.QueryRowContext(ctx, `SELECT COUNT(*) FROM items`).Scan(&n))
+	return n
+}
+
+func TestInsertRows_Batches(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows       int
+		statements int
+	}{
+		{name: "no rows", rows: 0, statements: 0},
+		{name: "one row", rows: 1, statements: 1},
+		{name: "exactly one batch", rows: itemsPerBatch, statements: 1},
+		{name: "one row over a batch", rows: itemsPerBatch + 1, statements: 2},
+		{name: "one row short of two batches", rows: 2*itemsPerBatch - 1, statements: 2},
+		{name: "exactly two batches", rows: 2 * itemsPerBatch, statements: 2},
+		{name: "many batches", rows: 10*itemsPerBatch + 3, statements: 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, db := setupBulkDB(t)
+			exec := &recordingExec{Exec: db}
+
+			n, err := InsertRows(ctx, exec, "items", itemColumns, itemRows(tt.rows))
+			require.NoError(t, err)
+			assert.Equal(t, int64(tt.rows), n)
+			assert.Len(t, exec.stmts, tt.statements)
+			assert.Equal(t, tt.rows, countItems(t, ctx, db))
+
+			// Every statement numbers its own placeholders from $1.
+			for i, stmt := range exec.stmts {
+				assert.LessOrEqual(t, len(exec.args[i]), maxParamsSQLite)
+				assert.Contains(t, stmt, "($1, $2, $3, $4)")
+				assert.Contains(t, stmt, fmt.Sprintf("$%d)", len(exec.args[i])))
+				assert.NotContains(t, stmt, fmt.Sprintf("$%d", len(exec.args[i])+1))
+			}
+		})
+	}
+}
+
+func TestInsertRows_RowWidthMismatch(t *testing.T) {
+	ctx, db := setupBulkDB(t)
+	rows := itemRows(3)
+	rows[1] = rows[1][:3]
+
+	_, err := InsertRows(ctx, db, "items", itemColumns, rows)
+	require.EqualError(t, err, "libdb: row 1 has 3 values for 4 columns")
+	assert.Equal(t, 0, countItems(t, ctx, db), "nothing is inserted")
+
+	_, err = InsertRows(ctx, db, "items", nil, rows)
+	require.Error(t, err)
+	_, err = UpsertRows(ctx, db, "items", itemColumns, []string{"id"}, rows)
+	require.Error(t, err)
+}
+
+func TestInsertRows_FailedBatchRollsBack(t *testing.T) {
+	ctx, db := setupBulkDB(t)
+	rows := itemRows(2*itemsPerBatch + 1)
+	rows[len(rows)-1][0] = 1 // repeats the first id in the last batch
+
+	_, err := InsertRows(ctx, db, "items", itemColumns, rows)
+	require.Error(t, err)
+	assert.Equal(t, 0, countItems(t, ctx, db), "earlier batches are rolled back")
+}
+
+func TestUseCopy(t *testing.T) {
+	postgres := &txAwareDB{driverName: "postgres"}
+	withBytes := itemRows(copyThreshold)
+	withBytes[copyThreshold/2][2] = []byte(`{"raw":"json"}`)
+
+	tests := []struct {
+		name string
+		exec Exec
+		rows [][]any
+		want bool
+	}{
+		{name: "postgres below threshold", exec: postgres, rows: itemRows(copyThreshold - 1), want: false},
+		{name: "postgres at threshold", exec: postgres, rows: itemRows(copyThreshold), want: true},
+		{name: "postgres with bytes", exec: postgres, rows: withBytes, want: false},
+		{name: "sqlite", exec: &txAwareDB{driverName: "sqlite"}, rows: itemRows(copyThreshold), want: false},
+		{name: "wrapped executor", exec: &recordingExec{Exec: postgres}, rows: itemRows(copyThreshold), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := useCopy(tt.exec, tt.rows)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestUpsertRows(t *testing.T) {
+	ctx, db := setupBulkDB(t)
+	_, err := InsertRows(ctx, db, "items", itemColumns, itemRows(itemsPerBatch))
+	require.NoError(t, err)
+
+	// Update the existing rows and add new ones across several batches.
+	rows := itemRows(2*itemsPerBatch + 1)
+	for _, r := range rows {
+		r[1] = strings.Replace(r[1].(string), "item", "updated", 1)
+	}
+	exec := &recordingExec{Exec: db}
+	n, err := UpsertRows(ctx, exec, "items", itemColumns, []string{"id"}, rows)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(rows)), n)
+	require.Len(t, exec.stmts, 3)
+	assert.Contains(t, exec.stmts[0], "ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, data = EXCLUDED.data, n = EXCLUDED.n")
+
+	assert.Equal(t, len(rows), countItems(t, ctx, db))
+	var name string
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT name FROM items WHERE id = 1`).Scan(&name))
+	assert.Equal(t, "updated-1", name)
+
+	// Without columns to update, conflicting rows are skipped.
+	n, err = UpsertRows(ctx, db, "items", []string{"id"}, []string{"id"}, [][]any{{1}, {2}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	_, err = UpsertRows(ctx, db, "items", itemColumns, nil, rows)
+	require.EqualError(t, err, "libdb: upsert requires conflict columns")
+}
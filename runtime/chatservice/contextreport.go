@@ -0,0 +1,138 @@
+package chatservice
+
+import (
+	"context"
+	"fmt"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/contenox/contenox/runtime/taskengine/compact"
+)
+
+// Summarization states reported by ContextReport.
+const (
+	// SummarizationUnknown means the model's context length is unknown.
+	SummarizationUnknown = "unknown"
+	// SummarizationNotNeeded means the history is below the compaction threshold.
+	SummarizationNotNeeded = "not_needed"
+	// SummarizationDone means older messages were already replaced by a
+	// summary and the history is below the threshold again.
+	SummarizationDone = "summarized"
+	// SummarizationDue means the next chat_completion with a compact policy
+	// will summarize the history.
+	SummarizationDue = "due"
+)
+
+// messageOverhead approximates the per-message tokens chat templates add for
+// the role and separators.
+const messageOverhead = 4
+
+// ContextReport is the token usage of a session measured against the context
+// window of a model.
+type ContextReport struct {
+	SessionID string `json:"sessionId"`
+	Model     string `json:"model"`
+	// ContextLength is the model's context window; 0 when unknown.
+	ContextLength int `json:"contextLength"`
+	Messages      int `json:"messages"`
+	Tokens        int `json:"tokens"`
+	// Remaining is ContextLength minus Tokens; negative on overflow.
+	Remaining    int     `json:"remaining"`
+	UsedFraction float64 `json:"usedFraction"`
+	// TruncatedMessages is the number of oldest messages a provider has to
+	// drop (leading system messages are kept) for the history to fit.
+	TruncatedMessages int `json:"truncatedMessages"`
+	// CompactionThreshold is the token count at which compaction triggers
+	// with the default compact policy.
+	CompactionThreshold int `json:"compactionThreshold"`
+	// Summaries counts the compaction summaries already in the history.
+	Summaries           int    `json:"summaries"`
+	SummarizationStatus string `json:"summarizationStatus"`
+}
+
+// ContextReport counts the tokens of a session's history with count and
+// reports them against contextLength, the context window of model. A
+// contextLength of 0 reports usage only.
+func (m *Manager) ContextReport(ctx context.Context, tx libdb.Exec, sessionID, model string, contextLength int, count compact.TokenCounter) (*ContextReport, error) {
+	messages, err := m.ListMessages(ctx, tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return BuildContextReport(ctx, sessionID, model, contextLength, messages, count)
+}
+
+// BuildContextReport computes a ContextReport for messages.
+func BuildContextReport(ctx context.Context, sessionID, model string, contextLength int, messages []taskengine.Message, count compact.TokenCounter) (*ContextReport, error) {
+	if count == nil {
+		return nil, fmt.Errorf("no token counter")
+	}
+	report := &ContextReport{
+		SessionID:     sessionID,
+		Model:         model,
+		ContextLength: contextLength,
+		Messages:      len(messages),
+	}
+	perMessage := make([]int, len(messages))
+	for i, msg := range messages {
+		n, err := messageTokens(ctx, count, model, msg)
+		if err != nil {
+			return nil, fmt.Errorf("count tokens of message %d: %w", i, err)
+		}
+		perMessage[i] = n
+		report.Tokens += n
+		if msg.Role == "user" && compact.IsSummary(msg.Content) {
+			report.Summaries++
+		}
+	}
+
+	if contextLength <= 0 {
+		report.SummarizationStatus = SummarizationUnknown
+		return report, nil
+	}
+	report.Remaining = contextLength - report.Tokens
+	report.UsedFraction = float64(report.Tokens) / float64(contextLength)
+	report.CompactionThreshold = int(float64(contextLength) * compact.DefaultTriggerFraction)
+	report.TruncatedMessages = truncatedMessages(messages, perMessage, report.Tokens, contextLength)
+	switch {
+	case report.Tokens >= report.CompactionThreshold:
+		report.SummarizationStatus = SummarizationDue
+	case report.Summaries > 0:
+		report.SummarizationStatus = SummarizationDone
+	default:
+		report.SummarizationStatus = SummarizationNotNeeded
+	}
+	return report, nil
+}
+
+func messageTokens(ctx context.Context, count compact.TokenCounter, model string, msg taskengine.Message) (int, error) {
+	total := messageOverhead
+	parts := []string{msg.Content}
+	for _, call := range msg.CallTools {
+		parts = append(parts, call.Function.Name, call.Function.Arguments)
+	}
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		n, err := count(ctx, model, part)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// truncatedMessages returns how many of the oldest non-system messages must be
+// dropped for total to fit into limit.
+func truncatedMessages(messages []taskengine.Message, perMessage []int, total, limit int) int {
+	dropped := 0
+	for i := 0; i < len(messages) && total > limit; i++ {
+		if messages[i].Role == "system" {
+			continue
+		}
+		total -= perMessage[i]
+		dropped++
+	}
+	return dropped
+}
@@ -0,0 +1,225 @@
+package chatservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/messagestore"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// Feedback ratings.
+const (
+	RatingUp   = 1
+	RatingDown = -1
+)
+
+// Preference export formats supported by ExportPreferences.
+const (
+	// FormatDPO writes prompt/chosen/rejected pairs in the conversational
+	// preference format of TRL's DPOTrainer.
+	FormatDPO = "dpo"
+	// FormatOpenAI writes pairs in the OpenAI preference fine-tuning format.
+	FormatOpenAI = "openai"
+	// FormatKTO writes every rated message as an unpaired
+	// prompt/completion/label record, as used by TRL's KTOTrainer.
+	FormatKTO = "kto"
+)
+
+// RateMessage records thumbs-up (RatingUp) or thumbs-down (RatingDown) and an
+// optional comment on an assistant message of a session. messageID may be a
+// unique prefix; when empty, the last assistant message is rated. Returns the
+// ID of the rated message.
+func (m *Manager) RateMessage(ctx context.Context, tx libdb.Exec, sessionID, messageID string, rating int, comment string) (string, error) {
+	if rating != RatingUp && rating != RatingDown {
+		return "", fmt.Errorf("invalid rating %d: must be %d or %d", rating, RatingUp, RatingDown)
+	}
+	store := messagestore.New(tx, m.workspaceID)
+	conversation, err := store.ListMessages(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, raw := range conversation {
+		var msg taskengine.Message
+		if err := json.Unmarshal(raw.Payload, &msg); err != nil {
+			return "", fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		if msg.Role != "assistant" || msg.Content == "" {
+			continue
+		}
+		if messageID == "" {
+			matches = []string{raw.ID}
+			continue
+		}
+		if raw.ID == messageID {
+			matches = []string{raw.ID}
+			break
+		}
+		if strings.HasPrefix(raw.ID, messageID) {
+			matches = append(matches, raw.ID)
+		}
+	}
+	switch {
+	case len(matches) == 0 && messageID == "":
+		return "", errors.New("session has no assistant message to rate")
+	case len(matches) == 0:
+		return "", fmt.Errorf("no assistant message %q in session", messageID)
+	case len(matches) > 1:
+		return "", fmt.Errorf("message ID %q is ambiguous (%d matches)", messageID, len(matches))
+	}
+
+	err = store.SetFeedback(ctx, &messagestore.Feedback{
+		MessageID: matches[0],
+		IDX:       sessionID,
+		Rating:    rating,
+		Comment:   comment,
+	})
+	if err != nil {
+		return "", err
+	}
+	return matches[0], nil
+}
+
+// preferenceMessage is a message of an exported record.
+type preferenceMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ratedAnswer is a rated assistant message and the conversation before it.
+type ratedAnswer struct {
+	prompt []preferenceMessage
+	answer preferenceMessage
+	rating int
+}
+
+// ExportPreferences writes the feedback recorded in sessionIDs as a JSONL
+// fine-tuning dataset in format and returns the number of records written.
+//
+// The prompt of a rated answer is the system, user and assistant messages
+// before it. The paired formats (FormatDPO, FormatOpenAI) pair every
+// thumbs-up answer with every thumbs-down answer to the same prompt, e.g. the
+// same question asked in several sessions; FormatKTO needs no pairs.
+func (m *Manager) ExportPreferences(ctx context.Context, tx libdb.Exec, sessionIDs []string, format string, w io.Writer) (int, error) {
+	if format != FormatDPO && format != FormatOpenAI && format != FormatKTO {
+		return 0, fmt.Errorf("unknown export format %q: use %s, %s or %s", format, FormatDPO, FormatOpenAI, FormatKTO)
+	}
+	var answers []ratedAnswer
+	for _, id := range sessionIDs {
+		rated, err := m.ratedAnswers(ctx, tx, id)
+		if err != nil {
+			return 0, fmt.Errorf("session %s: %w", id, err)
+		}
+		answers = append(answers, rated...)
+	}
+
+	enc := json.NewEncoder(w)
+	written := 0
+	if format == FormatKTO {
+		for _, a := range answers {
+			err := enc.Encode(map[string]any{
+				"prompt":     a.prompt,
+				"completion": []preferenceMessage{a.answer},
+				"label":      a.rating > 0,
+			})
+			if err != nil {
+				return written, err
+			}
+			written++
+		}
+		return written, nil
+	}
+
+	// Group answers by prompt, keeping the order prompts were first seen.
+	var keys []string
+	byPrompt := make(map[string][]ratedAnswer)
+	for _, a := range answers {
+		data, _ := json.Marshal(a.prompt)
+		key := string(data)
+		if _, seen := byPrompt[key]; !seen {
+			keys = append(keys, key)
+		}
+		byPrompt[key] = append(byPrompt[key], a)
+	}
+	for _, key := range keys {
+		group := byPrompt[key]
+		for _, chosen := range group {
+			if chosen.rating <= 0 {
+				continue
+			}
+			for _, rejected := range group {
+				if rejected.rating >= 0 {
+					continue
+				}
+				var record any
+				if format == FormatOpenAI {
+					record = map[string]any{
+						"input":                map[string]any{"messages": chosen.prompt},
+						"preferred_output":     []preferenceMessage{chosen.answer},
+						"non_preferred_output": []preferenceMessage{rejected.answer},
+					}
+				} else {
+					record = map[string]any{
+						"prompt":   chosen.prompt,
+						"chosen":   []preferenceMessage{chosen.answer},
+						"rejected": []preferenceMessage{rejected.answer},
+					}
+				}
+				if err := enc.Encode(record); err != nil {
+					return written, err
+				}
+				written++
+			}
+		}
+	}
+	return written, nil
+}
+
+// ratedAnswers returns the rated assistant messages of a session.
+func (m *Manager) ratedAnswers(ctx context.Context, tx libdb.Exec, sessionID string) ([]ratedAnswer, error) {
+	store := messagestore.New(tx, m.workspaceID)
+	feedback, err := store.ListFeedback(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(feedback) == 0 {
+		return nil, nil
+	}
+	byMessage := make(map[string]*messagestore.Feedback, len(feedback))
+	for _, f := range feedback {
+		byMessage[f.MessageID] = f
+	}
+	conversation, err := store.ListMessages(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompt []preferenceMessage
+	var rated []ratedAnswer
+	for _, raw := range conversation {
+		var msg taskengine.Message
+		if err := json.Unmarshal(raw.Payload, &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		if msg.Content == "" || (msg.Role != "system" && msg.Role != "user" && msg.Role != "assistant") {
+			continue
+		}
+		pm := preferenceMessage{Role: msg.Role, Content: msg.Content}
+		if f, ok := byMessage[raw.ID]; ok && msg.Role == "assistant" {
+			rated = append(rated, ratedAnswer{
+				prompt: append([]preferenceMessage(nil), prompt...),
+				answer: pm,
+				rating: f.Rating,
+			})
+		}
+		prompt = append(prompt, pm)
+	}
+	return rated, nil
+}
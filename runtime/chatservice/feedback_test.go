@@ -0,0 +1,66 @@
+package chatservice_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/chatservice"
+	"github.com/contenox/contenox/runtime/messagestore"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedback_RateAndExport(t *testing.T) {
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "feedback.db"), runtimetypes.SchemaSQLite)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	exec := db.WithoutTransaction()
+	mgr := chatservice.NewManager("")
+
+	now := time.Now().UTC()
+	for i, answer := range []string{"Paris.", "Lyon."} {
+		id := []string{"s1", "s2"}[i]
+		require.NoError(t, messagestore.New(exec, "").CreateMessageIndex(ctx, id, "alice"))
+		require.NoError(t, mgr.PersistDiff(ctx, exec, id, []taskengine.Message{
+			{ID: id + "-q", Role: "user", Content: "Capital of France?", Timestamp: now},
+			{ID: id + "-a", Role: "assistant", Content: answer, Timestamp: now.Add(time.Millisecond)},
+		}))
+	}
+
+	rated, err := mgr.RateMessage(ctx, exec, "s1", "", chatservice.RatingUp, "")
+	require.NoError(t, err)
+	assert.Equal(t, "s1-a", rated)
+	_, err = mgr.RateMessage(ctx, exec, "s2", "s2-", chatservice.RatingDown, "wrong city")
+	require.NoError(t, err)
+	_, err = mgr.RateMessage(ctx, exec, "s2", "s2-q", chatservice.RatingDown, "")
+	assert.ErrorContains(t, err, "no assistant message")
+
+	var out bytes.Buffer
+	n, err := mgr.ExportPreferences(ctx, exec, []string{"s1", "s2"}, chatservice.FormatDPO, &out)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	var pair struct {
+		Prompt   []map[string]string `json:"prompt"`
+		Chosen   []map[string]string `json:"chosen"`
+		Rejected []map[string]string `json:"rejected"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &pair))
+	assert.Equal(t, "Capital of France?", pair.Prompt[0]["content"])
+	assert.Equal(t, "Paris.", pair.Chosen[0]["content"])
+	assert.Equal(t, "Lyon.", pair.Rejected[0]["content"])
+
+	out.Reset()
+	n, err = mgr.ExportPreferences(ctx, exec, []string{"s1", "s2"}, chatservice.FormatKTO, &out)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"))
+}
@@ -59,6 +59,14 @@ func New(deps Deps) *Service {
 
 // SendTurn runs load history → inject → execute → persist.
 func (s *Service) SendTurn(ctx context.Context, in TurnInput) (*TurnResult, error) {
+	if in.SessionID != "" {
+		lockCtx, unlock, err := runtimetypes.LockSession(ctx, runtimetypes.New(s.db.WithoutTransaction()), in.SessionID, 0)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		ctx = lockCtx
+	}
 	if strings.EqualFold(strings.TrimSpace(in.Mode), "build") {
 		return s.sendBuildTurn(ctx, in)
 	}
@@ -150,7 +158,7 @@ func (s *Service) SendTurn(ctx context.Context, in TurnInput) (*TurnResult, erro
 			return nil, perr
 		}
 	case taskengine.DataTypeString, taskengine.DataTypeJSON, taskengine.DataTypeAny, taskengine.DataTypeNil,
-		taskengine.DataTypeInt:
+		taskengine.DataTypeInt, taskengine.DataTypeVector, taskengine.DataTypeFile:
 		out.Response = FormatChainResultForChat(result)
 		messages = append(messages, taskengine.Message{
 			ID:        uuid.NewString(),
@@ -0,0 +1,53 @@
+// answer_cmd.go — contenox answer for runs paused at a clarify task.
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/spf13/cobra"
+)
+
+var answerCmd = &cobra.Command{
+	Use:   "answer <run-id> <answer...>",
+	Short: "Answer the question of a run waiting at a clarify task.",
+	Long: `Records the answer to the question a run stopped at in a clarify task.
+Resume the run afterwards with 'contenox run --resume <run-id>'; it continues
+with the answer as the task's output and the "answered" transition.
+
+Examples:
+  contenox answer 3f2b9c1e-5d7a-4c1b-9e8f-2a6d4b7c0e11 "the staging cluster"`,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		runID, answer := args[0], strings.Join(args[1:], " ")
+
+		dbPath, err := resolveDBPath(cmd)
+		if err != nil {
+			return err
+		}
+		db, err := OpenDBAt(ctx, dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		cp, err := taskengine.Answer(ctx, newKVCheckpointStore(db), runID, answer)
+		if errors.Is(err, taskengine.ErrCheckpointNotFound) {
+			return fmt.Errorf("no checkpoint for run %q: it completed already or never finished a step", runID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Answered task %q of run %s: %s\n  → %s\n", cp.Clarification.TaskID, runID, cp.Clarification.Question, answer)
+		fmt.Fprintf(cmd.OutOrStdout(), "Continue with: contenox run --resume %s\n", runID)
+		return nil
+	},
+}
@@ -0,0 +1,84 @@
+// approve_cmd.go — contenox approve / reject for runs paused at an await_approval task.
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/spf13/cobra"
+)
+
+var approveCmd = &cobra.Command{
+	Use:   "approve <run-id>",
+	Short: "Approve a run waiting at an await_approval task.",
+	Long: `Records the approval of a run that stopped at an await_approval task.
+Resume the run afterwards with 'contenox run --resume <run-id>'; it continues
+with the task's "approved" transition.
+
+Examples:
+  contenox approve 3f2b9c1e-5d7a-4c1b-9e8f-2a6d4b7c0e11
+  contenox approve 3f2b9c1e-5d7a-4c1b-9e8f-2a6d4b7c0e11 --reason "change ticket OPS-12"`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApprovalDecision(cmd, args[0], true)
+	},
+}
+
+var rejectCmd = &cobra.Command{
+	Use:   "reject <run-id>",
+	Short: "Reject a run waiting at an await_approval task.",
+	Long: `Records the rejection of a run that stopped at an await_approval task.
+Resume the run afterwards with 'contenox run --resume <run-id>'; it follows the
+task's "rejected" branch, or fails if the chain has none.
+
+Examples:
+  contenox reject 3f2b9c1e-5d7a-4c1b-9e8f-2a6d4b7c0e11 --reason "not during the release freeze"`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApprovalDecision(cmd, args[0], false)
+	},
+}
+
+func init() {
+	approveCmd.Flags().String("reason", "", "Reason recorded with the decision")
+	rejectCmd.Flags().String("reason", "", "Reason recorded with the decision")
+}
+
+func runApprovalDecision(cmd *cobra.Command, runID string, approved bool) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+
+	dbPath, err := resolveDBPath(cmd)
+	if err != nil {
+		return err
+	}
+	db, err := OpenDBAt(ctx, dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	store := newKVCheckpointStore(db)
+	decide := taskengine.Reject
+	verb := "Rejected"
+	if approved {
+		decide, verb = taskengine.Approve, "Approved"
+	}
+	cp, err := decide(ctx, store, runID, reason)
+	if errors.Is(err, taskengine.ErrCheckpointNotFound) {
+		return fmt.Errorf("no checkpoint for run %q: it completed already or never finished a step", runID)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s task %q of run %s: %s\n", verb, cp.Approval.TaskID, runID, cp.PendingApproval.Message)
+	fmt.Fprintf(cmd.OutOrStdout(), "Continue with: contenox run --resume %s\n", runID)
+	return nil
+}
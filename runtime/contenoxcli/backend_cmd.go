@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/contenox/contenox/runtime/backendservice"
+	"github.com/contenox/contenox/runtime/internal/runtimestate"
 	libdb "github.com/contenox/contenox/libdbexec"
 	"github.com/contenox/contenox/libtracker"
 	"github.com/contenox/contenox/runtime/runtimetypes"
@@ -19,7 +21,7 @@ import (
 
 var backendCmd = &cobra.Command{
 	Use:   "backend",
-	Short: "Manage LLM backends (add, list, show, remove).",
+	Short: "Manage LLM backends (add, list, show, remove, sync-interval).",
 	Long: `Register and manage LLM backend endpoints.
 
 A backend points at an LLM provider. Supported types:
@@ -29,6 +31,13 @@ A backend points at an LLM provider. Supported types:
   ollama                        Local Ollama daemon (requires: ollama serve) or hosted Ollama Cloud.
   openai                        api.openai.com (requires --api-key-env).
   gemini                        Google Gemini (requires --api-key-env).
+  anthropic                     Anthropic Claude (requires --api-key-env).
+  mistral                       Mistral AI (requires --api-key-env).
+  groq                          Groq (requires --api-key-env). Low-latency hosted open models.
+  azure-openai                  Azure OpenAI resource (requires --url and --api-key-env). Models are
+                                addressed by deployment name.
+  bedrock                       AWS Bedrock (uses the AWS credentials of the environment, or
+                                --api-key-env holding ACCESS_KEY_ID:SECRET_ACCESS_KEY).
   vllm                          Self-hosted OpenAI-compatible endpoint (requires --url).
   vertex-google / -anthropic    Google Cloud Vertex AI (requires gcloud auth application-default login
   / -meta / -mistralai          and GOOGLE_CLOUD_PROJECT).
@@ -49,6 +58,20 @@ Examples:
   # Register Google Gemini:
   contenox backend add gemini --type gemini --api-key-env GEMINI_API_KEY
 
+  # Register Anthropic Claude:
+  contenox backend add anthropic --type anthropic --api-key-env ANTHROPIC_API_KEY
+
+  # Register Mistral AI or Groq:
+  contenox backend add mistral --type mistral --api-key-env MISTRAL_API_KEY
+  contenox backend add groq --type groq --api-key-env GROQ_API_KEY
+
+  # Register an Azure OpenAI resource (models are its deployment names):
+  contenox backend add azure --type azure-openai --api-key-env AZURE_OPENAI_API_KEY \
+    --url "https://<resource>.openai.azure.com/?api-version=2024-10-21"
+
+  # Register AWS Bedrock with the AWS credentials of the environment:
+  contenox backend add bedrock --type bedrock --url https://bedrock-runtime.us-east-1.amazonaws.com
+
   # Register a Google Vertex AI backend (run gcloud auth application-default login first):
   export GOOGLE_CLOUD_PROJECT=my-project-id
   contenox backend add vertex --type vertex-google \
@@ -57,6 +80,12 @@ Examples:
   # Register a custom vLLM server:
   contenox backend add myvllm --type vllm --url http://gpu-host:8000
 
+  # Find Ollama and vLLM servers on the local network and register approved ones:
+  contenox backend discover --cidr 192.168.1.0/24
+
+  # Ask a rate-limited provider for its model list at most every 6 hours:
+  contenox backend sync-interval openai 6h
+
   contenox backend list
   contenox backend show openai
   contenox backend remove myvllm`,
@@ -71,7 +100,15 @@ The --type flag determines which provider protocol is used.
   local                         Embedded llama.cpp inference compiled into the contenox binary.
                                 No Ollama, no external server, no API key required. Pass --url with the
                                 path to a GGUF file or a huggingface.co URL.
-  openai, gemini                Cloud providers. Base URL inferred if --url is omitted. Requires --api-key-env.
+  openai, gemini, anthropic,    Cloud providers. Base URL inferred if --url is omitted. Requires --api-key-env.
+  mistral, groq
+  azure-openai                  Azure OpenAI resource. Requires --url and --api-key-env. The api-version
+                                query of --url selects the API version; models are deployment names.
+                                Add ?deployments=a,b when the resource does not list its deployments.
+  bedrock                       AWS Bedrock. --url is the runtime endpoint of the region, inferred from
+                                AWS_REGION if omitted. Requests are signed with the AWS environment
+                                credentials or shared credentials file, or with --api-key-env holding
+                                ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN].
   ollama                        Local daemon (requires 'ollama serve') or hosted Ollama Cloud (use
                                 --url https://ollama.com/api and --api-key-env OLLAMA_API_KEY).
   vllm                          Self-hosted OpenAI-compatible endpoint (requires --url).
@@ -80,6 +117,9 @@ The --type flag determines which provider protocol is used.
 
 API keys should be passed via --api-key-env (reads from environment) rather than
 --api-key (inline literal) to avoid leaking secrets into shell history.
+A key is stored as the provider-wide key of the backend type unless
+--backend-key keeps it on this backend alone, which lets several backends of
+one type use different keys. --header adds HTTP headers to every request.
 
 Examples:
   contenox backend add embedded --type local  --url <path-or-hf-url>
@@ -87,7 +127,14 @@ Examples:
   contenox backend add ollama-cloud --type ollama --url https://ollama.com/api --api-key-env OLLAMA_API_KEY
   contenox backend add openai  --type openai  --api-key-env OPENAI_API_KEY
   contenox backend add gemini  --type gemini  --api-key-env GEMINI_API_KEY
-  contenox backend add myvllm --type vllm    --url http://gpu-host:8000`,
+  contenox backend add anthropic --type anthropic --api-key-env ANTHROPIC_API_KEY
+  contenox backend add mistral --type mistral --api-key-env MISTRAL_API_KEY
+  contenox backend add groq    --type groq    --api-key-env GROQ_API_KEY
+  contenox backend add azure --type azure-openai --api-key-env AZURE_OPENAI_API_KEY --url "https://<resource>.openai.azure.com/?api-version=2024-10-21"
+  contenox backend add bedrock --type bedrock --url https://bedrock-runtime.us-east-1.amazonaws.com
+  contenox backend add myvllm --type vllm    --url http://gpu-host:8000
+  contenox backend add openai  --type openai  --api-key-env OPENAI_API_KEY --sync-interval 6h
+  contenox backend add gateway --type openai  --url https://llm.internal/v1 --api-key-env GATEWAY_KEY --backend-key --header "X-Tenant: acme"`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := libtracker.WithNewRequestID(context.Background())
@@ -98,6 +145,9 @@ Examples:
 		baseURL, _ := flags.GetString("url")
 		apiKeyEnv, _ := flags.GetString("api-key-env")
 		apiKeyLit, _ := flags.GetString("api-key")
+		backendKey, _ := flags.GetBool("backend-key")
+		rawHeaders, _ := flags.GetStringArray("header")
+		syncInterval, _ := flags.GetString("sync-interval")
 
 		typ = strings.ToLower(strings.TrimSpace(typ))
 		if typ == "" {
@@ -111,14 +161,39 @@ Examples:
 				baseURL = "https://api.openai.com/v1"
 			case "gemini":
 				baseURL = "https://generativelanguage.googleapis.com"
+			case "anthropic":
+				baseURL = "https://api.anthropic.com/v1"
+			case "mistral":
+				baseURL = "https://api.mistral.ai/v1"
+			case "groq":
+				baseURL = "https://api.groq.com/openai/v1"
+			case "azure-openai":
+				return fmt.Errorf("--url is required for azure-openai backends\n  Use the endpoint of your resource, e.g.:\n  --url \"https://<resource>.openai.azure.com/?api-version=2024-10-21\"")
+			case "bedrock":
+				region := os.Getenv("AWS_REGION")
+				if region == "" {
+					return fmt.Errorf("--url is required for bedrock backends when AWS_REGION is not set\n  Use the runtime endpoint of your region, e.g.:\n  --url https://bedrock-runtime.us-east-1.amazonaws.com")
+				}
+				baseURL = "https://bedrock-runtime." + region + ".amazonaws.com"
 			case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 				return fmt.Errorf("--url is required for %s backends\n  Include project and location, e.g.:\n  --url \"https://us-central1-aiplatform.googleapis.com/v1/projects/$GOOGLE_CLOUD_PROJECT/locations/us-central1\"", typ)
 			}
 		}
+		var interval time.Duration
+		if syncInterval != "" {
+			var err error
+			if interval, err = runtimestate.ParseSyncInterval(syncInterval); err != nil {
+				return err
+			}
+		}
 		apiKey := apiKeyLit
 		if apiKey == "" && apiKeyEnv != "" {
 			apiKey = os.Getenv(apiKeyEnv)
 		}
+		headers, err := parseHeaders(rawHeaders)
+		if err != nil {
+			return err
+		}
 
 		// Sanity-check the URL: a double-slash in the path (after stripping the scheme)
 		// is almost always caused by an un-expanded environment variable such as
@@ -150,16 +225,25 @@ Examples:
 			Name:    name,
 			Type:    typ,
 			BaseURL: baseURL,
+			Headers: headers,
+		}
+		if backendKey {
+			backend.APIKey = apiKey
 		}
 		if err := svc.Create(ctx, backend); err != nil {
 			return fmt.Errorf("failed to add backend: %w", err)
 		}
 
-		if apiKey != "" {
+		if apiKey != "" && !backendKey {
 			if err := setProviderConfigKV(ctx, runtimetypes.New(db.WithoutTransaction()), typ, apiKey); err != nil {
 				return fmt.Errorf("backend added but failed to store API key: %w", err)
 			}
 		}
+		if interval > 0 {
+			if err := runtimestate.SetBackendSyncInterval(ctx, runtimetypes.New(db.WithoutTransaction()), backend.ID, interval); err != nil {
+				return fmt.Errorf("backend added but failed to store sync interval: %w", err)
+			}
+		}
 
 		fmt.Fprintf(cmd.OutOrStdout(), "Backend %q added (%s → %s).\n", name, typ, baseURL)
 		return nil
@@ -212,12 +296,154 @@ var backendShowCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("backend %q not found: %w", args[0], err)
 		}
-		data, _ := json.MarshalIndent(b, "", "  ")
+		interval, err := runtimestate.GetBackendSyncInterval(ctx, store, b.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read sync interval: %w", err)
+		}
+		// Never print credentials.
+		display := *b
+		if len(display.Headers) > 0 {
+			hidden := make(map[string]string, len(display.Headers))
+			for k := range display.Headers {
+				hidden[k] = "(hidden)"
+			}
+			display.Headers = hidden
+		}
+		shown := struct {
+			*runtimetypes.Backend
+			APIKey       string `json:"apiKey,omitempty"`
+			SyncInterval string `json:"syncInterval,omitempty"`
+		}{Backend: &display}
+		if b.APIKey != "" {
+			shown.APIKey = "(set, value hidden)"
+		}
+		if interval > 0 {
+			shown.SyncInterval = interval.String()
+		}
+		data, _ := json.MarshalIndent(shown, "", "  ")
 		fmt.Fprintln(cmd.OutOrStdout(), string(data))
 		return nil
 	},
 }
 
+var backendUpdateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Update the URL, credentials or headers of a registered backend.",
+	Long: `Update a registered backend. Only the given flags change.
+
+An API key set here belongs to this backend alone and takes precedence over
+the provider-wide key of its type, so several backends of one type (e.g. two
+OpenAI-compatible gateways) can use different keys. --header replaces all
+extra headers of the backend.
+
+Examples:
+  contenox backend update gateway --api-key-env GATEWAY_API_KEY
+  contenox backend update gateway --header "X-Tenant: acme" --header "OpenAI-Organization: org-123"
+  contenox backend update gateway --clear-api-key`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		db, svc, err := openBackendDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		b, err := runtimetypes.New(db.WithoutTransaction()).GetBackendByName(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("backend %q not found: %w", args[0], err)
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("url") {
+			b.BaseURL, _ = flags.GetString("url")
+		}
+		if flags.Changed("api-key-env") {
+			env, _ := flags.GetString("api-key-env")
+			if b.APIKey = os.Getenv(env); b.APIKey == "" {
+				return fmt.Errorf("environment variable %s is empty or not set", env)
+			}
+		}
+		if flags.Changed("api-key") {
+			b.APIKey, _ = flags.GetString("api-key")
+		}
+		if clearKey, _ := flags.GetBool("clear-api-key"); clearKey {
+			b.APIKey = ""
+		}
+		if flags.Changed("header") {
+			rawHeaders, _ := flags.GetStringArray("header")
+			if b.Headers, err = parseHeaders(rawHeaders); err != nil {
+				return err
+			}
+		}
+
+		if err := svc.Update(ctx, b); err != nil {
+			return fmt.Errorf("failed to update backend: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Backend %q updated.\n", b.Name)
+		return nil
+	},
+}
+
+var backendSyncIntervalCmd = &cobra.Command{
+	Use:   "sync-interval <name> [interval|default]",
+	Short: "Show or set how often a backend is reconciled.",
+	Long: `Show or set the sync interval of a backend.
+
+Without an interval, local backends (ollama, vllm, local) are observed on every
+reconciliation and cloud model lists are cached for an hour. With one, a
+backend observed less than the interval ago keeps its state and cloud model
+lists are cached for the interval: use a short interval for a local Ollama
+whose models change often, a long one for rate-limited providers.
+
+Examples:
+  contenox backend sync-interval openai 6h
+  contenox backend sync-interval ollama 10s
+  contenox backend sync-interval openai default`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		db, _, err := openBackendDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		store := runtimetypes.New(db.WithoutTransaction())
+		b, err := store.GetBackendByName(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("backend %q not found: %w", args[0], err)
+		}
+		if len(args) == 1 {
+			interval, err := runtimestate.GetBackendSyncInterval(ctx, store, b.ID)
+			if err != nil {
+				return fmt.Errorf("failed to read sync interval: %w", err)
+			}
+			if interval == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Backend %q uses the default sync interval.\n", b.Name)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Backend %q syncs every %s.\n", b.Name, interval)
+			return nil
+		}
+		var interval time.Duration
+		if args[1] != "default" {
+			if interval, err = runtimestate.ParseSyncInterval(args[1]); err != nil {
+				return err
+			}
+		}
+		if err := runtimestate.SetBackendSyncInterval(ctx, store, b.ID, interval); err != nil {
+			return fmt.Errorf("failed to store sync interval: %w", err)
+		}
+		if interval == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Backend %q reset to the default sync interval.\n", b.Name)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Backend %q syncs every %s.\n", b.Name, interval)
+		return nil
+	},
+}
+
 var backendRemoveCmd = &cobra.Command{
 	Use:     "remove <name>",
 	Aliases: []string{"rm", "delete"},
@@ -236,6 +462,18 @@ var backendRemoveCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("backend %q not found: %w", args[0], err)
 		}
+		if at, _ := cmd.Flags().GetString("at"); at != "" {
+			applyAt, err := parseApplyAt(at, time.Now())
+			if err != nil {
+				return err
+			}
+			return scheduleChange(ctx, store, cmd.OutOrStdout(), &runtimetypes.ScheduledChange{
+				Kind:    runtimetypes.ChangeDeleteBackend,
+				Target:  b.ID,
+				Value:   b.Name,
+				ApplyAt: applyAt,
+			})
+		}
 		if err := svc.Delete(ctx, b.ID); err != nil {
 			return fmt.Errorf("failed to remove backend: %w", err)
 		}
@@ -281,13 +519,26 @@ func globalDBPath() (string, error) {
 }
 
 func init() {
-	backendAddCmd.Flags().String("type", "ollama", "Backend type: local (embedded llama.cpp, no external server), ollama, openai, gemini, vllm, vertex-google, vertex-anthropic, vertex-meta, vertex-mistralai")
+	backendAddCmd.Flags().String("type", "ollama", "Backend type: local (embedded llama.cpp, no external server), ollama, openai, gemini, anthropic, mistral, groq, azure-openai, bedrock, vllm, vertex-google, vertex-anthropic, vertex-meta, vertex-mistralai")
 	backendAddCmd.Flags().String("url", "", "Base URL of the backend (auto-inferred for openai/gemini if omitted; set https://ollama.com/api for hosted Ollama)")
 	backendAddCmd.Flags().String("api-key-env", "", "Name of the environment variable holding the API key (preferred over --api-key)")
 	backendAddCmd.Flags().String("api-key", "", "API key literal — prefer --api-key-env to avoid leaking into shell history")
+	backendAddCmd.Flags().Bool("backend-key", false, "Store the API key on this backend only instead of as the provider-wide key of its type")
+	backendAddCmd.Flags().StringArray("header", nil, `Extra HTTP header sent with every request, e.g. "X-Tenant: acme" (repeatable)`)
+	backendAddCmd.Flags().String("sync-interval", "", "How often the backend is reconciled, e.g. 10s or 6h (default: every cycle for local backends, 1h for cloud model lists)")
+
+	backendUpdateCmd.Flags().String("url", "", "New base URL")
+	backendUpdateCmd.Flags().String("api-key-env", "", "Environment variable holding the API key of this backend (preferred over --api-key)")
+	backendUpdateCmd.Flags().String("api-key", "", "API key literal of this backend — prefer --api-key-env to avoid leaking into shell history")
+	backendUpdateCmd.Flags().Bool("clear-api-key", false, "Drop the key of this backend and use the provider-wide key of its type again")
+	backendUpdateCmd.Flags().StringArray("header", nil, "Extra HTTP headers (replaces all existing headers)")
+
+	backendRemoveCmd.Flags().String("at", "", "Schedule the removal instead of removing now (RFC 3339, \"YYYY-MM-DD HH:MM\", \"HH:MM\" or \"+<duration>\")")
 
 	backendCmd.AddCommand(backendAddCmd)
 	backendCmd.AddCommand(backendListCmd)
 	backendCmd.AddCommand(backendShowCmd)
+	backendCmd.AddCommand(backendUpdateCmd)
 	backendCmd.AddCommand(backendRemoveCmd)
+	backendCmd.AddCommand(backendSyncIntervalCmd)
 }
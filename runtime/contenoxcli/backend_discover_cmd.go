@@ -0,0 +1,188 @@
+package contenoxcli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/backendservice"
+	"github.com/contenox/contenox/runtime/internal/runtimestate"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var backendDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Find Ollama and vLLM servers on the local network and register approved ones.",
+	Long: `Probe the local network for Ollama and vLLM servers and propose them as backends.
+
+Every address of the CIDR ranges is probed on each port: an Ollama answers
+/api/version, a vLLM (or other OpenAI-compatible server) answers /v1/models.
+Without --cidr the ranges stored with --save are probed, or else the /24
+networks of this machine's interfaces. Servers already registered as a
+backend are not proposed again.
+
+Each candidate is shown with its models and registered only when approved;
+--yes approves all of them and --dry-run only lists them.
+
+Examples:
+  contenox backend discover
+  contenox backend discover --cidr 192.168.1.0/24 --cidr 10.0.0.0/28 --port 11434 --port 8000
+  contenox backend discover --cidr 192.168.1.0/24 --save   # remember the range for later runs
+  contenox backend discover --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		flags := cmd.Flags()
+		cidrs, _ := flags.GetStringSlice("cidr")
+		ports, _ := flags.GetIntSlice("port")
+		timeout, _ := flags.GetString("timeout")
+		save, _ := flags.GetBool("save")
+		yes, _ := flags.GetBool("yes")
+		dryRun, _ := flags.GetBool("dry-run")
+
+		db, svc, err := openBackendDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		store := runtimetypes.New(db.WithoutTransaction())
+
+		cfg, err := runtimestate.GetDiscoveryConfig(ctx, store)
+		if err != nil {
+			return fmt.Errorf("failed to read discovery settings: %w", err)
+		}
+		if len(cidrs) > 0 {
+			cfg.CIDRs = cidrs
+		}
+		if len(ports) > 0 {
+			cfg.Ports = ports
+		}
+		if timeout != "" {
+			cfg.Timeout = timeout
+		}
+		if save {
+			if err := runtimestate.SetDiscoveryConfig(ctx, store, cfg); err != nil {
+				return fmt.Errorf("failed to store discovery settings: %w", err)
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		candidates, err := runtimestate.DiscoverBackends(ctx, cfg, nil)
+		if err != nil {
+			return err
+		}
+		backends, err := svc.List(ctx, nil, 1000)
+		if err != nil {
+			return fmt.Errorf("failed to list backends: %w", err)
+		}
+		candidates = newDiscoveredBackends(candidates, backends)
+		if len(candidates) == 0 {
+			fmt.Fprintln(out, "No new Ollama or vLLM servers found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tURL\tMODELS")
+		for _, c := range candidates {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", discoveredBackendName(c), c.Type, c.BaseURL, summarizeModels(c.Models))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+
+		in := bufio.NewReader(cmd.InOrStdin())
+		added := 0
+		for _, c := range candidates {
+			name := discoveredBackendName(c)
+			if !yes && !confirmDiscovered(out, in, name, c) {
+				continue
+			}
+			if err := registerDiscoveredBackend(ctx, svc, name, c); err != nil {
+				fmt.Fprintf(out, "  failed to add %q: %v\n", name, err)
+				continue
+			}
+			fmt.Fprintf(out, "Backend %q added (%s → %s).\n", name, c.Type, c.BaseURL)
+			added++
+		}
+		if added > 0 {
+			fmt.Fprintln(out, "Run 'contenox doctor' to check the new backends.")
+		}
+		return nil
+	},
+}
+
+// newDiscoveredBackends drops the candidates whose URL is already registered.
+func newDiscoveredBackends(candidates []runtimestate.DiscoveredBackend, backends []*runtimetypes.Backend) []runtimestate.DiscoveredBackend {
+	registered := map[string]bool{}
+	for _, b := range backends {
+		registered[strings.TrimRight(strings.ToLower(b.BaseURL), "/")] = true
+	}
+	var fresh []runtimestate.DiscoveredBackend
+	for _, c := range candidates {
+		if !registered[strings.ToLower(c.BaseURL)] {
+			fresh = append(fresh, c)
+		}
+	}
+	return fresh
+}
+
+// discoveredBackendName names a candidate after its type, host and port,
+// e.g. "ollama-192-168-1-20-11434".
+func discoveredBackendName(c runtimestate.DiscoveredBackend) string {
+	host := c.BaseURL
+	if u, err := url.Parse(c.BaseURL); err == nil {
+		host = u.Host
+	}
+	return c.Type + "-" + strings.NewReplacer(".", "-", ":", "-").Replace(host)
+}
+
+// summarizeModels lists up to three models and counts the rest.
+func summarizeModels(models []string) string {
+	switch {
+	case len(models) == 0:
+		return "-"
+	case len(models) <= 3:
+		return strings.Join(models, ", ")
+	default:
+		return fmt.Sprintf("%s, … (+%d)", strings.Join(models[:3], ", "), len(models)-3)
+	}
+}
+
+// confirmDiscovered asks whether to register candidate c as name. Only "y"
+// or "yes" approves; blank input and EOF decline.
+func confirmDiscovered(out io.Writer, in *bufio.Reader, name string, c runtimestate.DiscoveredBackend) bool {
+	fmt.Fprintf(out, "Register %s at %s as %q? [y/N]: ", c.Type, c.BaseURL, name)
+	line, _ := in.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+func registerDiscoveredBackend(ctx context.Context, svc backendservice.Service, name string, c runtimestate.DiscoveredBackend) error {
+	return svc.Create(ctx, &runtimetypes.Backend{
+		ID:      uuid.NewString(),
+		Name:    name,
+		Type:    c.Type,
+		BaseURL: c.BaseURL,
+	})
+}
+
+func init() {
+	backendDiscoverCmd.Flags().StringSlice("cidr", nil, "IPv4 range to probe, e.g. 192.168.1.0/24 (repeatable; default: stored ranges, else this machine's networks)")
+	backendDiscoverCmd.Flags().IntSlice("port", nil, "Port to probe on every address (repeatable; default: 11434 and 8000)")
+	backendDiscoverCmd.Flags().String("timeout", "", "Timeout of each probe, e.g. 300ms (default: 500ms)")
+	backendDiscoverCmd.Flags().Bool("save", false, "Store the given ranges, ports and timeout as the defaults of later runs")
+	backendDiscoverCmd.Flags().BoolP("yes", "y", false, "Register every candidate without asking")
+	backendDiscoverCmd.Flags().Bool("dry-run", false, "Only list the candidates")
+
+	backendCmd.AddCommand(backendDiscoverCmd)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/contenox/contenox/runtime/backendservice"
 	"github.com/contenox/contenox/runtime/internal/clikv"
@@ -117,6 +118,46 @@ func Test_backendService_update(t *testing.T) {
 	require.Equal(t, "http://new:11434", list[0].BaseURL)
 }
 
+func Test_backendService_credentialsSurviveReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := libdb.NewSQLiteDBManager(ctx, path, runtimetypes.SchemaSQLite)
+	require.NoError(t, err)
+	svc := backendservice.New(db)
+
+	b := &runtimetypes.Backend{
+		ID:      uuid.NewString(),
+		Name:    "gateway",
+		Type:    "openai",
+		BaseURL: "https://llm.internal/v1",
+		APIKey:  "sk-gateway",
+		Headers: map[string]string{"X-Tenant": "acme"},
+	}
+	require.NoError(t, svc.Create(ctx, b))
+	b.Headers["OpenAI-Organization"] = "org-123"
+	require.NoError(t, svc.Update(ctx, b))
+	require.NoError(t, db.Close())
+
+	// Opening the database again runs the schema migrations once more.
+	db, err = libdb.NewSQLiteDBManager(ctx, path, runtimetypes.SchemaSQLite)
+	require.NoError(t, err)
+	defer db.Close()
+	got, err := runtimetypes.New(db.WithoutTransaction()).GetBackendByName(ctx, "gateway")
+	require.NoError(t, err)
+	require.Equal(t, "sk-gateway", got.APIKey)
+	require.Equal(t, map[string]string{"X-Tenant": "acme", "OpenAI-Organization": "org-123"}, got.Headers)
+	encoded, err := json.Marshal(got)
+	require.NoError(t, err)
+	require.NotContains(t, string(encoded), "sk-gateway", "the API key is never encoded")
+
+	plain := &runtimetypes.Backend{ID: uuid.NewString(), Name: "openai", Type: "openai", BaseURL: "https://api.openai.com/v1"}
+	require.NoError(t, backendservice.New(db).Create(ctx, plain))
+	got, err = runtimetypes.New(db.WithoutTransaction()).GetBackend(ctx, plain.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.APIKey)
+	require.Nil(t, got.Headers)
+}
+
 func Test_backendService_delete(t *testing.T) {
 	ctx, db, _ := setupSQLiteStore(t)
 	svc := backendservice.New(db)
@@ -163,6 +204,26 @@ func Test_backendService_differentType_sameURL_allowed(t *testing.T) {
 // getConfigKV / clikv.Prefix (new config cmd helpers)
 // ---------------------------------------------------------------------------
 
+func Test_backendSyncInterval_roundTrip(t *testing.T) {
+	ctx, _, store := setupSQLiteStore(t)
+
+	got, err := runtimestate.GetBackendSyncInterval(ctx, store, "b1")
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	require.NoError(t, runtimestate.SetBackendSyncInterval(ctx, store, "b1", 6*time.Hour))
+	require.NoError(t, runtimestate.SetBackendSyncInterval(ctx, store, "b1", 2*time.Hour))
+	got, err = runtimestate.GetBackendSyncInterval(ctx, store, "b1")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, got)
+
+	require.NoError(t, runtimestate.SetBackendSyncInterval(ctx, store, "b1", 0))
+	require.NoError(t, runtimestate.SetBackendSyncInterval(ctx, store, "b1", 0), "resetting twice is not an error")
+	got, err = runtimestate.GetBackendSyncInterval(ctx, store, "b1")
+	require.NoError(t, err)
+	require.Zero(t, got)
+}
+
 func Test_getConfigKV_emptyIfNotSet(t *testing.T) {
 	ctx, _, store := setupSQLiteStore(t)
 	val, err := getConfigKV(ctx, store, "default-model")
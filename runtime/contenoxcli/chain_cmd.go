@@ -0,0 +1,174 @@
+// chain_cmd.go — contenox chain subcommand tree (lint).
+package contenoxcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/spf13/cobra"
+)
+
+// errChainLintFailed is returned when at least one linted chain has errors.
+var errChainLintFailed = errors.New("chain lint found errors")
+
+// lintLocalTools lists the local tools BuildEngine can register. They are
+// always known to the linter, independent of the flags of a later run.
+var lintLocalTools = []string{"echo", "print", "webtools", "local_fs", "plan_summary", "desktop", "calc", "local_shell", "python_sandbox"}
+
+var chainCmd = &cobra.Command{
+	Use:          "chain",
+	Short:        "Work with task chain files (lint).",
+	SilenceUsage: true,
+}
+
+var chainLintCmd = &cobra.Command{
+	Use:   "lint <file>...",
+	Short: "Check task chain files for mistakes before running them.",
+	Long: `Statically checks task chain files (.json, .yaml or .yml) and reports:
+
+  - unknown handlers and operators
+  - missing transitions and transitions to unknown tasks
+  - tasks that are unreachable from the first task
+  - incomplete handler configuration (tools, parallel, foreach, coerce, ...)
+  - type mismatches between a task's output and what the next task accepts
+  - tools that are not registered (local tools, 'contenox tools' and 'contenox mcp')
+
+Findings are errors (the chain fails at runtime) or warnings (likely mistakes).
+The command exits non-zero when any file has errors.
+
+Examples:
+  contenox chain lint .contenox/default-chain.json
+  contenox chain lint .contenox/*.yaml
+  contenox chain lint --json --no-tools review.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runChainLint,
+}
+
+func init() {
+	chainLintCmd.Flags().Bool("json", false, "Print diagnostics as JSON")
+	chainLintCmd.Flags().Bool("no-tools", false, "Do not check tools names against the registered tools")
+	chainCmd.AddCommand(chainLintCmd)
+}
+
+// chainLintResult is the lint output of one chain file.
+type chainLintResult struct {
+	Path        string                 `json:"path"`
+	Error       string                 `json:"error,omitempty"`
+	Diagnostics taskengine.Diagnostics `json:"diagnostics"`
+}
+
+func runChainLint(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	asJSON, _ := cmd.Flags().GetBool("json")
+	noTools, _ := cmd.Flags().GetBool("no-tools")
+
+	var knownTools []string
+	if !noTools {
+		names, err := registeredToolNames(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("failed to list registered tools (use --no-tools to skip): %w", err)
+		}
+		knownTools = names
+	}
+
+	results := make([]chainLintResult, 0, len(args))
+	failed := false
+	for _, path := range args {
+		res := lintChainFile(path, knownTools, !noTools)
+		if res.Error != "" || res.Diagnostics.HasErrors() {
+			failed = true
+		}
+		results = append(results, res)
+	}
+
+	out := cmd.OutOrStdout()
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printChainLint(out, results)
+	}
+	if failed {
+		return errChainLintFailed
+	}
+	return nil
+}
+
+func lintChainFile(path string, knownTools []string, checkTools bool) chainLintResult {
+	res := chainLintResult{Path: path, Diagnostics: taskengine.Diagnostics{}}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	var chain taskengine.TaskChainDefinition
+	if err := unmarshalChain(abs, data, &chain); err != nil {
+		res.Error = fmt.Sprintf("failed to parse chain: %v", err)
+		return res
+	}
+	res.Diagnostics = append(res.Diagnostics, taskengine.ValidateChain(&chain)...)
+	if checkTools {
+		res.Diagnostics = append(res.Diagnostics, taskengine.ValidateChainTools(&chain, knownTools)...)
+	}
+	return res
+}
+
+func printChainLint(w io.Writer, results []chainLintResult) {
+	for _, res := range results {
+		switch {
+		case res.Error != "":
+			fmt.Fprintf(w, "%s: %s\n", res.Path, res.Error)
+		case len(res.Diagnostics) == 0:
+			fmt.Fprintf(w, "%s: ok\n", res.Path)
+		default:
+			errs := 0
+			for _, d := range res.Diagnostics {
+				if d.Severity == taskengine.SeverityError {
+					errs++
+				}
+			}
+			fmt.Fprintf(w, "%s: %d error(s), %d warning(s)\n", res.Path, errs, len(res.Diagnostics)-errs)
+			for _, d := range res.Diagnostics {
+				fmt.Fprintf(w, "  %s\n", d.String())
+			}
+		}
+	}
+}
+
+// registeredToolNames returns the local tools plus the MCP servers and remote
+// tools registered in the database.
+func registeredToolNames(ctx context.Context, cmd *cobra.Command) ([]string, error) {
+	dbPath, err := resolveDBPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	db, err := OpenDBAt(ctx, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	// Supports only needs the names of the local tools.
+	local := make(map[string]taskengine.ToolsRepo, len(lintLocalTools))
+	for _, name := range lintLocalTools {
+		local[name] = nil
+	}
+	return tools.NewPersistentRepo(local, db, nil, nil).Supports(ctx)
+}
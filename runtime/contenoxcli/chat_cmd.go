@@ -3,7 +3,7 @@ package contenoxcli
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -28,18 +28,34 @@ type chatOpts struct {
 	EffectiveNoDeleteModels      bool
 	EffectiveEnableLocalExec     bool
 	EffectiveLocalExecAllowedDir string
+	EffectiveEnablePython        bool
 	EffectiveTracing             bool
 	EffectiveSteps               bool
 	EffectiveHITL                bool
-	EffectiveRaw                 bool
-	EffectiveThink               bool
-	HistoryTrim                  int
-	LastN                        int
-	InputValue                   string
-	InputFlagPassed              bool
-	ContenoxDir                  string
+	// EffectiveConfirmTools asks before every local_shell, ssh, python_sandbox and file write call.
+	EffectiveConfirmTools bool
+	EffectiveRaw          bool
+	// EffectiveStream prints the reply while the model generates it.
+	EffectiveStream bool
+	EffectiveThink  bool
+	HistoryTrim     int
+	LastN           int
+	InputValue      string
+	InputFlagPassed bool
+	ContenoxDir     string
+	// Recorder records model and tool calls for 'contenox run --record'.
+	Recorder *callRecorder
+	// Replay answers model and tool calls from a recording ('contenox replay').
+	Replay *callReplayer
+	// PromptCacheTTL caches prompt responses in the KV table for this long (0 = off).
+	PromptCacheTTL time.Duration
+	// RateLimits caps model calls per minute per provider type ("*" = any other).
+	RateLimits map[string]int
 	// EffectiveSkipBackendCycle skips state.RunBackendCycle (e.g. contenox-runtime doctor --skip-cycle).
 	EffectiveSkipBackendCycle bool
+	// Unattended denies tool calls that --hitl or --confirm-tools would ask
+	// about instead of prompting (e.g. 'contenox warmup').
+	Unattended bool
 }
 
 // execChat runs the full chat pipeline and returns any error encountered.
@@ -70,8 +86,8 @@ func execChat(ctx context.Context, db libdb.DBManager, opts chatOpts, out, errW
 		return fmt.Errorf("failed to read chain file %q: %w", chainPathAbs, err)
 	}
 	var chain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(chainData, &chain); err != nil {
-		return fmt.Errorf("failed to parse chain JSON: %w", err)
+	if err := unmarshalChain(chainPathAbs, chainData, &chain); err != nil {
+		return fmt.Errorf("failed to parse chain %q: %w", chainPathAbs, err)
 	}
 
 	// Determine input: from flag, positional args (+optional stdin), or stdin alone.
@@ -114,10 +130,29 @@ func execChat(ctx context.Context, db libdb.DBManager, opts chatOpts, out, errW
 		ctx = context.WithValue(ctx, runtimetypes.SessionIDContextKey, sessionID)
 	}
 	chatMgr := chatservice.NewManager(ResolveWorkspaceID(opts.ContenoxDir))
+	if sessionID != "" {
+		// Hold the session for the whole turn so a concurrent chat cannot
+		// read the history before this one persists its reply.
+		lockCtx, unlock, err := runtimetypes.LockSession(ctx, runtimetypes.New(db.WithoutTransaction()), sessionID, 0)
+		if errors.Is(err, runtimetypes.ErrSessionBusy) {
+			return fmt.Errorf("%w\nwait for it to finish, or start another session with 'contenox session new'", err)
+		}
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		ctx = lockCtx
+	}
 
+	var streamer *replyStreamer
+	if opts.EffectiveStream {
+		streamer = &replyStreamer{w: out}
+		ctx = taskengine.WithTokenStream(ctx, streamer.write)
+	}
 	stopTaskEvents := startCLITaskEventStream(ctx, engine, errW, cliTaskEventRenderOptions{
 		Trace:        opts.EffectiveTracing,
 		ShowThinking: opts.EffectiveThink,
+		HideContent:  opts.EffectiveStream,
 	})
 	defer stopTaskEvents()
 
@@ -189,7 +224,11 @@ func execChat(ctx context.Context, db libdb.DBManager, opts chatOpts, out, errW
 			}
 		}
 	}
-	printRelevantOutput(out, output, outputType, opts.EffectiveRaw)
+	if streamer != nil {
+		streamer.finish(output, outputType, opts.EffectiveRaw)
+	} else {
+		printRelevantOutput(out, output, outputType, opts.EffectiveRaw)
+	}
 
 	// --last N: print last N non-system messages from the updated history.
 	if opts.LastN > 0 {
@@ -215,8 +254,13 @@ func execChat(ctx context.Context, db libdb.DBManager, opts chatOpts, out, errW
 	if opts.EffectiveSteps && len(stateUnits) > 0 {
 		fmt.Fprintln(errW, "\n📋 Steps:")
 		for i, u := range stateUnits {
-			fmt.Fprintf(errW, "  %d. %s (%s) %s %s\n", i+1, u.TaskID, u.TaskHandler, formatDuration(u.Duration), u.Transition)
+			attempt := ""
+			if u.MaxAttempts > 1 {
+				attempt = fmt.Sprintf(" [attempt %d/%d]", u.Attempt, u.MaxAttempts)
+			}
+			fmt.Fprintf(errW, "  %d. %s (%s) %s %s%s%s\n", i+1, u.TaskID, u.TaskHandler, formatDuration(u.Duration), u.Transition, attempt, stepUsage(u))
 		}
+		printStepUsage(errW, stateUnits)
 	}
 	return nil
 }
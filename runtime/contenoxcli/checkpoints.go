@@ -0,0 +1,53 @@
+package contenoxcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// checkpointKVPrefix namespaces chain run checkpoints in the KV table.
+const checkpointKVPrefix = "checkpoint:"
+
+// kvCheckpointStore persists taskengine checkpoints in the local KV table so
+// 'contenox run --resume <run-id>' can continue an interrupted run.
+type kvCheckpointStore struct {
+	db libdb.DBManager
+}
+
+func newKVCheckpointStore(db libdb.DBManager) taskengine.CheckpointStore {
+	return &kvCheckpointStore{db: db}
+}
+
+func (s *kvCheckpointStore) SaveCheckpoint(ctx context.Context, cp *taskengine.Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+	return runtimetypes.New(s.db.WithoutTransaction()).SetKV(ctx, checkpointKVPrefix+cp.RunID, data)
+}
+
+func (s *kvCheckpointStore) LoadCheckpoint(ctx context.Context, runID string) (*taskengine.Checkpoint, error) {
+	var cp taskengine.Checkpoint
+	err := runtimetypes.New(s.db.WithoutTransaction()).GetKV(ctx, checkpointKVPrefix+runID, &cp)
+	if errors.Is(err, libdb.ErrNotFound) {
+		return nil, taskengine.ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s *kvCheckpointStore) DeleteCheckpoint(ctx context.Context, runID string) error {
+	err := runtimetypes.New(s.db.WithoutTransaction()).DeleteKV(ctx, checkpointKVPrefix+runID)
+	if errors.Is(err, libdb.ErrNotFound) {
+		return taskengine.ErrCheckpointNotFound
+	}
+	return err
+}
@@ -46,7 +46,7 @@ const (
 )
 
 // reservedSubcommands are first-arg names that must not be treated as run input (Cobra or our subcommands).
-var reservedSubcommands = map[string]bool{"init": true, "chat": true, "help": true, "completion": true, "session": true, "plan": true, "run": true, "tools": true, "mcp": true, "backend": true, "config": true, "model": true, "models": true, "doctor": true, "version": true}
+var reservedSubcommands = map[string]bool{"init": true, "chat": true, "help": true, "completion": true, "session": true, "plan": true, "run": true, "tools": true, "mcp": true, "backend": true, "config": true, "model": true, "models": true, "doctor": true, "version": true, "chain": true, "approve": true, "reject": true, "answer": true, "replay": true, "warmup": true, "logs": true}
 
 // Main runs the contenox CLI: init subcommand or run (default) with optional positional input.
 func Main() {
@@ -83,7 +83,7 @@ func firstNonFlagIsReserved(args []string) bool {
 	// Without this list, `contenox --trace chat` would mistake "chat" for the
 	// value of --trace and then forward it to the chat command as text input.
 	boolFlags := map[string]bool{
-		"--shell": true, "--trace": true, "--steps": true, "--raw": true,
+		"--shell": true, "--trace": true, "--steps": true, "--raw": true, "--stream": true,
 		"--think": true, "--no-delete-models": true,
 		"-h": true, "--help": true, "-v": true, "--version": true,
 	}
@@ -193,6 +193,9 @@ Giving the model tools (file system and shell access):
   --shell                            enable local_shell (command policy is defined in the chain)
   --hitl                             pause before write_file, sed, and local_shell calls;
                                      require y/n approval at the terminal (human-in-the-loop)
+  --confirm-tools                    ask y/n before every local_shell, ssh, python_sandbox and
+                                     file write call;
+                                     'a' always allows the command pattern (config: confirm-tools)
 
 Examples:
   # Chat with file system access to the current project:
@@ -272,15 +275,17 @@ func init() {
 	f.String("provider", "", "Provider type override (ollama, openai, vllm, gemini). Overrides config default_provider.")
 	f.Int("context", defaultContext, "Context length")
 	f.Bool("no-delete-models", true, "Legacy compatibility flag; OSS runtime model deletion is disabled.")
-	f.String("chain", "", "Path to a task chain JSON file. Chains define the LLM workflow: which model, which tools, how to branch. Falls back to default_chain in config, then .contenox/default-chain.json")
+	f.String("chain", "", "Path to a task chain file (.json, .yaml or .yml). Chains define the LLM workflow: which model, which tools, how to branch. Falls back to default_chain in config, then .contenox/default-chain.json")
 	f.String("input", "", "Input for the chain (default: positional args or stdin if piped)")
 	f.Bool("shell", false, "Enable the local_shell tools (use only in trusted environments)")
 	f.String("local-exec-allowed-dir", "", "If set, local_shell may only run scripts/binaries under this directory")
+	f.Bool("python", false, "Enable the python_sandbox tools (Linux only; snippets run without network in separate namespaces)")
 	f.Duration("timeout", defaultTimeout, "Maximum execution time (e.g., 5m, 1h)")
 	f.Bool("trace", false, "Enable operation telemetry on stderr")
 
 	f.Bool("steps", false, "Print execution steps after the result")
 	f.Bool("raw", false, "Print full output (e.g. entire chat JSON)")
+	f.Bool("stream", false, "Print the model's reply to stdout while it is generated")
 	f.Bool("think", false, "Print model reasoning trace to stderr (for thinking models)")
 
 	rootCmd.AddCommand(initCmd, chatCmd, sessionCmd, planCmd, runCmd, toolsCmd, doctorCmd, versionCmd)
@@ -288,6 +293,12 @@ func init() {
 	rootCmd.AddCommand(backendCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(modelCmd)
+	rootCmd.AddCommand(chainCmd)
+	rootCmd.AddCommand(approveCmd, rejectCmd, answerCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(warmupCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(varsCmd)
 
 	rootCmd.InitDefaultHelpCmd() // so "contenox help" is handled by Cobra, not passed as run input
 	initCmd.Flags().BoolP("force", "f", false, "Overwrite existing files")
@@ -296,6 +307,7 @@ func init() {
 	chatCmd.Flags().Int("trim", 0, "Only send the last N messages from session history to the model (0 = send all)")
 	chatCmd.Flags().Int("last", 0, "Print last N user/assistant turns after the reply (0 = only print new reply)")
 	chatCmd.Flags().Bool("hitl", false, "Pause before write_file, sed, and local_shell calls; require y/n approval in the terminal")
+	chatCmd.Flags().Bool("confirm-tools", false, "Ask y/n before every local_shell, ssh, python_sandbox and file write call the model makes; 'a' always allows the command pattern (default: confirm-tools config)")
 }
 
 // ResolveContenoxDir finds the closest .contenox directory by walking up from the
@@ -432,10 +444,12 @@ func runChat(cmd *cobra.Command, args []string) error {
 
 	effectiveEnableLocalExec, _ := flags.GetBool("shell")
 	effectiveLocalExecAllowedDir, _ := flags.GetString("local-exec-allowed-dir")
+	effectiveEnablePython, _ := flags.GetBool("python")
 
 	effectiveTracing, _ := flags.GetBool("trace")
 	effectiveSteps, _ := flags.GetBool("steps")
 	effectiveRaw, _ := flags.GetBool("raw")
+	effectiveStream, _ := flags.GetBool("stream")
 
 	var inputValue string
 	var inputPassed bool
@@ -469,10 +483,13 @@ func runChat(cmd *cobra.Command, args []string) error {
 		EffectiveNoDeleteModels:      effectiveNoDeleteModels,
 		EffectiveEnableLocalExec:     effectiveEnableLocalExec,
 		EffectiveLocalExecAllowedDir: effectiveLocalExecAllowedDir,
+		EffectiveEnablePython:        effectiveEnablePython,
 		EffectiveTracing:             effectiveTracing,
 		EffectiveSteps:               effectiveSteps,
 		EffectiveHITL:                effectiveHITL,
+		EffectiveConfirmTools:        resolveConfirmTools(dbCtx, store, cmd),
 		EffectiveRaw:                 effectiveRaw,
+		EffectiveStream:              effectiveStream,
 		EffectiveThink:               effectiveThink,
 		HistoryTrim:                  historyTrim,
 		LastN:                        lastN,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"text/tabwriter"
+	"time"
 
 	"github.com/contenox/contenox/runtime/internal/clikv"
 	libdb "github.com/contenox/contenox/libdbexec"
@@ -14,10 +15,16 @@ import (
 
 // validConfigKeys lists the keys users can set via `contenox config set`.
 var validConfigKeys = map[string]string{
-	"default-model":    "Default LLM model name (e.g. qwen2.5:7b)",
-	"default-provider": "Default LLM provider type (e.g. ollama, openai, gemini)",
-	"default-chain":    "Default chain file path (relative to .contenox/ or absolute)",
-	"hitl-policy-name": "Active HITL policy file name (e.g. hitl-policy-strict.json). Empty = use hitl-policy-default.json.",
+	"default-model":          "Default LLM model name (e.g. qwen2.5:7b)",
+	"default-provider":       "Default LLM provider type (e.g. ollama, openai, gemini)",
+	"default-chain":          "Default chain file path (relative to .contenox/ or absolute)",
+	"hitl-policy-name":       "Active HITL policy file name (e.g. hitl-policy-strict.json). Empty = use hitl-policy-default.json.",
+	"change-window":          "Window scheduled changes are applied in (e.g. \"sat,sun 02:00-06:00\"). Empty = any time.",
+	"template-vars-from-env": "Comma-separated environment variables chains can read as {{var:NAME}} (e.g. API_BASE,TEAM).",
+	"confirm-tools":          "\"true\" asks y/n before every local_shell, ssh, python_sandbox and file write tool call (--confirm-tools overrides it).",
+	"confirm-tools-allow":    "Comma-separated tool calls confirm-tools runs without asking (e.g. local_shell:git *,local_fs:src/*).",
+	"pull-rate-limit":        "Bandwidth cap of 'model pull' in bytes per second (e.g. 10MB). Empty = unlimited. --limit-rate overrides it.",
+	"history-retention":      "How long the 'contenox logs' history and 'model usage' statistics are kept (e.g. 720h). Empty = forever.",
 }
 
 var configCmd = &cobra.Command{
@@ -25,14 +32,20 @@ var configCmd = &cobra.Command{
 	Short: "Manage persistent CLI settings (default model, provider, chain, HITL policy).",
 	Long: `Store and retrieve persistent CLI defaults backed by SQLite.
 
-Global keys (shared across all projects): default-model, default-provider
-Workspace keys (scoped to current project): default-chain, hitl-policy-name
+Global keys (shared across all projects): default-model, default-provider, change-window, confirm-tools, pull-rate-limit, history-retention
+Workspace keys (scoped to current project): default-chain, hitl-policy-name, template-vars-from-env, confirm-tools-allow
 
 Supported keys:
   default-model      Default LLM model name (e.g. qwen2.5:7b)
   default-provider   Default LLM provider type (e.g. ollama, openai, gemini)
   default-chain      Default chain file path
-  hitl-policy-name   Active HITL policy file name (e.g. hitl-policy-strict.json)`,
+  hitl-policy-name   Active HITL policy file name (e.g. hitl-policy-strict.json)
+  change-window      Window scheduled changes are applied in (e.g. "sat,sun 02:00-06:00")
+  template-vars-from-env  Environment variables exposed to chains as {{var:NAME}} (e.g. API_BASE,TEAM)
+  confirm-tools      "true" asks before every local_shell, ssh, python_sandbox and file write tool call
+  confirm-tools-allow  Tool calls confirm-tools runs without asking (e.g. local_shell:git *,local_fs:src/*)
+  pull-rate-limit    Bandwidth cap of 'model pull' in bytes per second (e.g. 10MB)
+  history-retention  How long execution history and model usage are kept (e.g. 720h)`,
 }
 
 var configSetCmd = &cobra.Command{
@@ -40,20 +53,43 @@ var configSetCmd = &cobra.Command{
 	Short: "Set a persistent config value.",
 	Long: `Set a persistent CLI default stored in the SQLite database.
 
-Global keys (default-model, default-provider) are shared across all projects.
-Workspace keys (default-chain, hitl-policy-name) are scoped to the current project
+Global keys (default-model, default-provider, change-window, confirm-tools, pull-rate-limit, history-retention) are shared across all projects.
+Workspace keys (default-chain, hitl-policy-name, template-vars-from-env, confirm-tools-allow) are scoped to the current project
 workspace and fall back to the global value when not set locally.
 
+With --at the change is scheduled instead of applied now; see 'contenox config scheduled'.
+
 Examples:
   contenox config set default-model    qwen2.5:7b
   contenox config set default-provider ollama
   contenox config set default-chain    .contenox/default-chain.json
-  contenox config set hitl-policy-name hitl-policy-strict.json`,
+  contenox config set hitl-policy-name hitl-policy-strict.json
+  contenox config set change-window    "sat,sun 02:00-06:00"
+  contenox config set template-vars-from-env API_BASE,TEAM
+  contenox config set confirm-tools  true
+  contenox config set pull-rate-limit 10MB
+  contenox config set history-retention 720h
+  contenox config set default-model    qwen2.5:14b --at "2025-06-07 02:00"`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key, value := args[0], args[1]
 		if _, ok := validConfigKeys[key]; !ok {
-			return fmt.Errorf("unknown key %q — valid keys: default-model, default-provider, default-chain, hitl-policy-name", key)
+			return fmt.Errorf("unknown key %q — valid keys: default-model, default-provider, default-chain, hitl-policy-name, change-window, template-vars-from-env, confirm-tools, confirm-tools-allow, pull-rate-limit, history-retention", key)
+		}
+		if key == "change-window" && value != "" {
+			if _, err := runtimetypes.ParseChangeWindow(value); err != nil {
+				return err
+			}
+		}
+		if key == pullRateLimitKey && value != "" {
+			if _, err := parseByteSize(value); err != nil {
+				return err
+			}
+		}
+		if key == historyRetentionKey && value != "" {
+			if _, err := parseHistoryRetention(value); err != nil {
+				return err
+			}
 		}
 		db, store, workspaceID, err := openConfigDBWithWorkspace(cmd)
 		if err != nil {
@@ -62,6 +98,23 @@ Examples:
 		defer db.Close()
 
 		ctx := libtracker.WithNewRequestID(context.Background())
+		if key == "default-model" && value != "" {
+			force, _ := cmd.Flags().GetBool("force")
+			if err := checkDefaultModel(ctx, db, value, cmd.ErrOrStderr(), force); err != nil {
+				return err
+			}
+		}
+		if at, _ := cmd.Flags().GetString("at"); at != "" {
+			applyAt, err := parseApplyAt(at, time.Now())
+			if err != nil {
+				return err
+			}
+			change, err := clikv.ScheduleConfig(workspaceID, key, value, applyAt)
+			if err != nil {
+				return err
+			}
+			return scheduleChange(ctx, store, cmd.OutOrStdout(), change)
+		}
 		if err := clikv.WriteConfig(ctx, store, workspaceID, key, value); err != nil {
 			return fmt.Errorf("failed to set %q: %w", key, err)
 		}
@@ -155,6 +208,8 @@ func openConfigDBWithWorkspace(cmd *cobra.Command) (libdb.DBManager, runtimetype
 }
 
 func init() {
+	configSetCmd.Flags().Bool("force", false, "Set a default-model no provider catalog lists, warning instead of failing")
+	configSetCmd.Flags().String("at", "", "Schedule the change instead of applying it now (RFC 3339, \"YYYY-MM-DD HH:MM\", \"HH:MM\" or \"+<duration>\")")
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configListCmd)
@@ -0,0 +1,297 @@
+package contenoxcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/contenox/contenox/runtime/hitlservice"
+	"github.com/contenox/contenox/runtime/internal/clikv"
+	"github.com/contenox/contenox/runtime/localtools"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/spf13/cobra"
+)
+
+// Config keys of confirm-tools mode. confirm-tools enables the mode when set
+// to "true" (the --confirm-tools flag overrides it); confirm-tools-allow holds
+// the comma-separated "<tools>:<pattern>" entries that run without asking.
+const (
+	confirmToolsKey      = "confirm-tools"
+	confirmToolsAllowKey = "confirm-tools-allow"
+)
+
+// reasonConfirmTools is the EvaluationResult.Reason of decisions made by
+// confirm-tools mode.
+const reasonConfirmTools = "confirm_tools"
+
+// shellMetaChars make a command line do more than its first word suggests;
+// commands containing them are never allowed by a pattern.
+const shellMetaChars = ";|&$`<>()\n"
+
+// resolveConfirmTools returns the --confirm-tools flag of cmd when it was
+// passed and the confirm-tools config value otherwise.
+func resolveConfirmTools(ctx context.Context, store runtimetypes.Store, cmd *cobra.Command) bool {
+	if flags := cmd.Flags(); flags.Lookup("confirm-tools") != nil && flags.Changed("confirm-tools") {
+		v, _ := flags.GetBool("confirm-tools")
+		return v
+	}
+	v, _ := getConfigKV(ctx, store, confirmToolsKey)
+	return v == "true"
+}
+
+// confirmSubject returns what a confirmed tool call acts on: the command line
+// of local_shell calls, "<user>@<host>:<command line>" of ssh calls, the
+// cleaned path of local_fs writes and the code of python_sandbox calls. ok is
+// false for calls confirm-tools mode lets through.
+func confirmSubject(toolsName, toolName string, args map[string]any) (subject string, ok bool) {
+	switch {
+	case toolsName == "local_shell":
+		return commandLine(args), true
+	case toolsName == "ssh":
+		host := argString(args["host"])
+		if user := argString(args["user"]); user != "" {
+			host = user + "@" + host
+		}
+		return host + ":" + commandLine(args), true
+	case toolsName == "local_fs" && (toolName == "write_file" || toolName == "sed"):
+		return cleanFSPath(argString(args["path"])), true
+	case toolsName == "python_sandbox":
+		return argString(args["code"]), true
+	}
+	return "", false
+}
+
+func commandLine(args map[string]any) string {
+	line := argString(args["command"])
+	if rest := argString(args["args"]); rest != "" {
+		line += " " + rest
+	}
+	return strings.TrimSpace(line)
+}
+
+// cleanFSPath returns p cleaned and with forward slashes, or "" for an empty
+// path.
+func cleanFSPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Clean(p))
+}
+
+// escapesDir reports whether the cleaned path p leaves the directory it is
+// relative to.
+func escapesDir(p string) bool {
+	return p == ".." || strings.HasPrefix(p, "../")
+}
+
+func argString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []any:
+		parts := make([]string, len(t))
+		for i, p := range t {
+			parts[i] = fmt.Sprint(p)
+		}
+		return strings.Join(parts, " ")
+	}
+	return fmt.Sprint(v)
+}
+
+// suggestPattern returns the allow-list entry offered for a call: the command
+// with any arguments for shell calls (on the same host for ssh), every file in
+// the directory for writes. It is empty when the call cannot be allowed by a
+// pattern, which is always the case for python_sandbox.
+func suggestPattern(toolsName, subject string) string {
+	switch toolsName {
+	case "python_sandbox":
+		return ""
+	case "local_fs":
+		subject = cleanFSPath(subject)
+		if subject == "" || escapesDir(subject) {
+			return ""
+		}
+		dir := path.Dir(subject)
+		if dir == "." {
+			return "local_fs:*"
+		}
+		return "local_fs:" + strings.TrimSuffix(dir, "/") + "/*"
+	}
+	if strings.ContainsAny(subject, shellMetaChars) {
+		return ""
+	}
+	prefix := toolsName + ":"
+	if toolsName == "ssh" {
+		host, line, _ := strings.Cut(subject, ":")
+		prefix, subject = prefix+host+":", line
+	}
+	fields := strings.Fields(subject)
+	switch len(fields) {
+	case 0:
+		return ""
+	case 1:
+		return prefix + fields[0]
+	}
+	return prefix + fields[0] + " *"
+}
+
+// patternMatches reports whether the allow-list entry pattern covers subject
+// of a toolsName call. "*" matches any characters, including "/"; a trailing
+// " *" also matches a command without arguments. ssh patterns match the host
+// and the command line separately, and local_fs paths are cleaned first:
+// paths escaping the directory are never allowed, nor are absolute paths by a
+// relative pattern.
+func patternMatches(pattern, toolsName, subject string) bool {
+	tools, glob, ok := strings.Cut(pattern, ":")
+	if !ok || tools != toolsName || subject == "" {
+		return false
+	}
+	switch toolsName {
+	case "local_fs":
+		subject = cleanFSPath(subject)
+		if escapesDir(subject) || path.IsAbs(subject) && !path.IsAbs(glob) {
+			return false
+		}
+		return globMatches(glob, subject)
+	case "ssh":
+		hostGlob, lineGlob, ok := strings.Cut(glob, ":")
+		host, line, _ := strings.Cut(subject, ":")
+		if !ok || !globMatches(hostGlob, host) {
+			return false
+		}
+		glob, subject = lineGlob, line
+	}
+	if strings.ContainsAny(subject, shellMetaChars) {
+		return false
+	}
+	return globMatches(glob, subject)
+}
+
+func globMatches(glob, subject string) bool {
+	optionalArgs := strings.HasSuffix(glob, " *")
+	glob = strings.TrimSuffix(glob, " *")
+	expr := strings.ReplaceAll(regexp.QuoteMeta(glob), `\*`, `.*`)
+	if optionalArgs {
+		expr += `(?: .*)?`
+	}
+	matched, _ := regexp.MatchString("^"+expr+"$", subject)
+	return matched
+}
+
+// toolAllowList is the confirm-tools-allow list of a workspace. Entries added
+// with "always allow" are written back to the config.
+type toolAllowList struct {
+	mu       sync.Mutex
+	patterns []string
+	save     func(ctx context.Context, value string) error
+}
+
+func loadToolAllowList(ctx context.Context, store runtimetypes.Store, workspaceID string) *toolAllowList {
+	value, _ := clikv.ReadConfig(ctx, store, workspaceID, confirmToolsAllowKey)
+	list := &toolAllowList{
+		save: func(ctx context.Context, value string) error {
+			return clikv.WriteConfig(ctx, store, workspaceID, confirmToolsAllowKey, value)
+		},
+	}
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			list.patterns = append(list.patterns, p)
+		}
+	}
+	return list
+}
+
+func (l *toolAllowList) allows(toolsName, subject string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range l.patterns {
+		if patternMatches(p, toolsName, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *toolAllowList) add(ctx context.Context, pattern string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slices.Contains(l.patterns, pattern) {
+		return nil
+	}
+	l.patterns = append(l.patterns, pattern)
+	return l.save(ctx, strings.Join(l.patterns, ","))
+}
+
+// confirmToolsPolicy requires approval for every local_shell, ssh,
+// python_sandbox and local_fs write call not covered by the allow list. Other calls, and denials
+// of the HITL policy, are left to inner, which is nil without --hitl.
+type confirmToolsPolicy struct {
+	inner hitlservice.PolicyEvaluator
+	allow *toolAllowList
+}
+
+func (p *confirmToolsPolicy) Evaluate(ctx context.Context, toolsName, toolName string, args map[string]any) (hitlservice.EvaluationResult, error) {
+	var inner hitlservice.EvaluationResult
+	if p.inner != nil {
+		var err error
+		if inner, err = p.inner.Evaluate(ctx, toolsName, toolName, args); err != nil {
+			return hitlservice.EvaluationResult{}, err
+		}
+	}
+	subject, confirmed := confirmSubject(toolsName, toolName, args)
+	switch {
+	case confirmed && inner.Action != hitlservice.ActionDeny:
+		action := hitlservice.ActionApprove
+		if p.allow.allows(toolsName, subject) {
+			action = hitlservice.ActionAllow
+		}
+		return hitlservice.EvaluationResult{Action: action, Reason: reasonConfirmTools}, nil
+	case p.inner != nil:
+		return inner, nil
+	}
+	return hitlservice.EvaluationResult{Action: hitlservice.ActionAllow, Reason: hitlservice.ReasonDefaultAction}, nil
+}
+
+var _ hitlservice.PolicyEvaluator = (*confirmToolsPolicy)(nil)
+
+// newConfirmAskApproval returns the AskApproval callback of confirm-tools
+// mode. Besides y/n it offers "a" to approve the call and add the suggested
+// pattern to allow, so matching calls run without asking from then on.
+func newConfirmAskApproval(w io.Writer, allow *toolAllowList) localtools.AskApproval {
+	return func(ctx context.Context, req hitlservice.ApprovalRequest) (bool, error) {
+		pattern := ""
+		if subject, ok := confirmSubject(req.ToolsName, req.ToolName, req.Args); ok {
+			pattern = suggestPattern(req.ToolsName, subject)
+		}
+		printApprovalRequest(w, "Tool call confirmation", req)
+		prompt := "  Run? [y/N]: "
+		if pattern != "" {
+			prompt = fmt.Sprintf("  Run? [y/N, a = always allow %s]: ", pattern)
+		}
+		answer, err := readApprovalAnswer(ctx, w, prompt)
+		if err != nil {
+			return false, err
+		}
+		switch answer {
+		case "y", "yes":
+			return true, nil
+		case "a", "always":
+			if pattern == "" {
+				return false, nil
+			}
+			if err := allow.add(ctx, pattern); err != nil {
+				fmt.Fprintf(w, "  warning: could not save %s: %v\n", pattern, err)
+			}
+			return true, nil
+		}
+		return false, nil
+	}
+}
@@ -0,0 +1,91 @@
+package contenoxcli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/hitlservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedPolicy hitlservice.Action
+
+func (p fixedPolicy) Evaluate(context.Context, string, string, map[string]any) (hitlservice.EvaluationResult, error) {
+	return hitlservice.EvaluationResult{Action: hitlservice.Action(p)}, nil
+}
+
+func TestConfirmTools_Patterns(t *testing.T) {
+	assert.Equal(t, "local_shell:git *", suggestPattern("local_shell", "git status --short"))
+	assert.Equal(t, "local_shell:make", suggestPattern("local_shell", "make"))
+	assert.Empty(t, suggestPattern("local_shell", "ls; rm -rf /"))
+	assert.Equal(t, "local_fs:src/pkg/*", suggestPattern("local_fs", "src/pkg/main.go"))
+	assert.Equal(t, "local_fs:*", suggestPattern("local_fs", "README.md"))
+
+	assert.True(t, patternMatches("local_shell:git *", "local_shell", "git"))
+	assert.True(t, patternMatches("local_shell:git *", "local_shell", "git log -n 3"))
+	assert.False(t, patternMatches("local_shell:git *", "local_shell", "gitk"))
+	assert.False(t, patternMatches("local_shell:git *", "local_shell", "git log && rm -rf /"), "shell operators are never allowed by a pattern")
+	assert.False(t, patternMatches("local_shell:git *", "ssh", "git log"))
+	assert.True(t, patternMatches("local_fs:src/*", "local_fs", "src/a/b.go"))
+	assert.False(t, patternMatches("local_fs:src/*", "local_fs", "docs/a.md"))
+}
+
+func TestConfirmTools_PathsAreCleaned(t *testing.T) {
+	assert.True(t, patternMatches("local_fs:src/*", "local_fs", "./src//a.go"))
+	assert.False(t, patternMatches("local_fs:src/*", "local_fs", "src/../../etc/passwd"))
+	assert.False(t, patternMatches("local_fs:*", "local_fs", "../outside.txt"), "paths escaping the directory are never allowed")
+	assert.False(t, patternMatches("local_fs:*", "local_fs", "/etc/passwd"), "relative patterns do not cover absolute paths")
+	assert.True(t, patternMatches("local_fs:/srv/app/*", "local_fs", "/srv/app/config.yaml"))
+	assert.Equal(t, "local_fs:src/*", suggestPattern("local_fs", "src/./a/../b.go"))
+	assert.Empty(t, suggestPattern("local_fs", "../b.go"))
+}
+
+func TestConfirmTools_SSHAndPython(t *testing.T) {
+	subject, ok := confirmSubject("ssh", "ssh", map[string]any{"host": "db1", "user": "ops", "command": "uptime"})
+	require.True(t, ok)
+	assert.Equal(t, "ops@db1:uptime", subject)
+	assert.Equal(t, "ssh:ops@db1:uptime", suggestPattern("ssh", subject))
+	assert.Equal(t, "ssh:ops@db1:git *", suggestPattern("ssh", "ops@db1:git log"))
+
+	assert.True(t, patternMatches("ssh:ops@db1:git *", "ssh", "ops@db1:git log"))
+	assert.False(t, patternMatches("ssh:ops@db1:git *", "ssh", "ops@prod:git log"), "patterns are scoped to the host")
+	assert.False(t, patternMatches("ssh:git *", "ssh", "ops@db1:git log"))
+	assert.True(t, patternMatches("ssh:*:uptime", "ssh", "ops@db1:uptime"))
+	assert.False(t, patternMatches("ssh:*:git *", "ssh", "ops@db1:rm -rf x :git log"), "the host glob does not reach into the command")
+
+	subject, ok = confirmSubject("python_sandbox", "python_sandbox", map[string]any{"code": "result = 1"})
+	require.True(t, ok)
+	assert.Equal(t, "result = 1", subject)
+	assert.Empty(t, suggestPattern("python_sandbox", subject))
+}
+
+func TestConfirmTools_Policy(t *testing.T) {
+	ctx, _, store := setupSQLiteStore(t)
+	allow := loadToolAllowList(ctx, store, "ws1")
+	policy := &confirmToolsPolicy{allow: allow}
+
+	eval := func(p *confirmToolsPolicy, tools, tool string, args map[string]any) hitlservice.Action {
+		res, err := p.Evaluate(ctx, tools, tool, args)
+		require.NoError(t, err)
+		return res.Action
+	}
+	shell := map[string]any{"command": "go", "args": "test ./..."}
+	assert.Equal(t, hitlservice.ActionApprove, eval(policy, "local_shell", "local_shell", shell))
+	assert.Equal(t, hitlservice.ActionApprove, eval(policy, "local_fs", "write_file", map[string]any{"path": "main.go"}))
+	assert.Equal(t, hitlservice.ActionAllow, eval(policy, "local_fs", "read_file", map[string]any{"path": "main.go"}))
+	assert.Equal(t, hitlservice.ActionApprove, eval(policy, "python_sandbox", "python_sandbox", map[string]any{"code": "result = 1"}))
+
+	require.NoError(t, allow.add(ctx, "local_shell:go *"))
+	assert.Equal(t, hitlservice.ActionAllow, eval(policy, "local_shell", "local_shell", shell))
+
+	reloaded := &confirmToolsPolicy{allow: loadToolAllowList(ctx, store, "ws1")}
+	assert.Equal(t, hitlservice.ActionAllow, eval(reloaded, "local_shell", "local_shell", shell), "always-allow entries are persisted")
+	other := &confirmToolsPolicy{allow: loadToolAllowList(ctx, store, "ws2")}
+	assert.Equal(t, hitlservice.ActionApprove, eval(other, "local_shell", "local_shell", shell), "entries are scoped to the workspace")
+
+	withHITL := &confirmToolsPolicy{inner: fixedPolicy(hitlservice.ActionDeny), allow: allow}
+	assert.Equal(t, hitlservice.ActionDeny, eval(withHITL, "local_shell", "local_shell", shell), "HITL denials win")
+	withHITL.inner = fixedPolicy(hitlservice.ActionApprove)
+	assert.Equal(t, hitlservice.ActionApprove, eval(withHITL, "webtools", "get", nil), "other tools follow the HITL policy")
+}
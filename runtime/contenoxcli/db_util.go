@@ -13,7 +13,8 @@ import (
 
 // OpenDBAt opens (and creates if needed) the SQLite database at the given path.
 // It applies the application schema and the KV store schema so the kv_store table
-// is always present for provider model-list caching.
+// is always present for provider model-list caching. Scheduled configuration
+// changes that fell due since the last run are applied before it returns.
 func OpenDBAt(ctx context.Context, dbPath string) (libdb.DBManager, error) {
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("cannot create database directory: %w", err)
@@ -23,6 +24,7 @@ func OpenDBAt(ctx context.Context, dbPath string) (libdb.DBManager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database %q: %w", dbPath, err)
 	}
+	applyScheduledChanges(ctx, db, os.Stderr)
 	return db, nil
 }
 
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/contenox/contenox/runtime/internal/setupcheck"
+	"github.com/contenox/contenox/runtime/stateservice"
 	"github.com/spf13/cobra"
 )
 
@@ -27,16 +31,25 @@ Note on the 'local' backend type: contenox ships with embedded llama.cpp inferen
 binary itself. A 'local'-type backend runs the model in-process (no external server, no API
 key, no network) — see 'contenox backend add --help' for details.
 
+With --capacity, doctor prints a capacity report instead: backends, declared vs pulled
+models, disk and VRAM usage, request volume and its trend over --window. Give
+--daily-request-limit and/or --disk-limit to project when the current trend reaches them.
+
 Examples:
   contenox doctor
   contenox doctor --json
-  contenox doctor --skip-cycle`,
+  contenox doctor --skip-cycle
+  contenox doctor --capacity --daily-request-limit 5000 --disk-limit 500GB`,
 	RunE: runDoctor,
 }
 
 func init() {
 	doctorCmd.Flags().Bool("json", false, "Print results as JSON")
 	doctorCmd.Flags().Bool("skip-cycle", false, "Skip syncing backends (faster; status may be outdated)")
+	doctorCmd.Flags().Bool("capacity", false, "Print a capacity planning report")
+	doctorCmd.Flags().Duration("window", stateservice.DefaultCapacityWindow, "Usage history the capacity trends are computed from")
+	doctorCmd.Flags().Int64("daily-request-limit", 0, "Requests per day the deployment can serve (projects when the trend reaches it)")
+	doctorCmd.Flags().String("disk-limit", "", "Disk space available for models, e.g. 500GB (projects when pulled models fill it)")
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
@@ -69,6 +82,9 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	defer engine.Stop()
 
 	jsonOut, _ := cmd.Flags().GetBool("json")
+	if capacity, _ := cmd.Flags().GetBool("capacity"); capacity {
+		return runCapacityReport(ctx, cmd, engine.State, jsonOut)
+	}
 	res := setupcheck.EnrichResultWithOllamaProbe(ctx, engine.SetupCheck)
 	if jsonOut {
 		enc := json.NewEncoder(cmd.OutOrStdout())
@@ -91,3 +107,112 @@ func printDoctorText(w io.Writer, res setupcheck.Result) {
 	}
 	PrintSetupIssues(w, res)
 }
+
+func runCapacityReport(ctx context.Context, cmd *cobra.Command, state stateservice.Service, jsonOut bool) error {
+	var opts stateservice.CapacityOptions
+	opts.Window, _ = cmd.Flags().GetDuration("window")
+	opts.DailyRequestLimit, _ = cmd.Flags().GetInt64("daily-request-limit")
+	if s, _ := cmd.Flags().GetString("disk-limit"); s != "" {
+		n, err := parseByteSize(s)
+		if err != nil {
+			return fmt.Errorf("invalid --disk-limit: %w", err)
+		}
+		opts.DiskLimitBytes = n
+	}
+	report, err := state.CapacityReport(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("capacity report: %w", err)
+	}
+	if jsonOut {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printCapacityReport(cmd.OutOrStdout(), report)
+	return nil
+}
+
+func printCapacityReport(w io.Writer, r *stateservice.CapacityReport) {
+	fmt.Fprintf(w, "Backends:        %d (%d reachable)\n", r.BackendCount, r.ReachableBackends)
+	fmt.Fprintf(w, "Models:          %d declared, %d pulled\n", len(r.DeclaredModels), len(r.PulledModels))
+	if len(r.MissingModels) > 0 {
+		fmt.Fprintf(w, "  not pulled:    %s\n", strings.Join(r.MissingModels, ", "))
+	}
+	if len(r.UndeclaredModels) > 0 {
+		fmt.Fprintf(w, "  undeclared:    %s\n", strings.Join(r.UndeclaredModels, ", "))
+	}
+	fmt.Fprintf(w, "Disk:            %s\n", formatByteSize(r.DiskBytes))
+	fmt.Fprintf(w, "VRAM (loaded):   %s\n", formatByteSize(r.VRAMBytes))
+	fmt.Fprintf(w, "Requests (%gd):  %d, %d errors, %.1f/day, trend %+.1f/day per day\n",
+		r.WindowDays, r.Requests.Total, r.Requests.Errors, r.Requests.PerDay, r.Requests.TrendPerDay)
+
+	if len(r.Backends) > 0 {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "BACKEND\tTYPE\tREACHABLE\tMODELS\tDISK\tVRAM\tREQUESTS\tERRORS")
+		for _, b := range r.Backends {
+			vram := "-"
+			if b.VRAMBytes != nil {
+				vram = formatByteSize(*b.VRAMBytes)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%t\t%d\t%s\t%s\t%d\t%d\n",
+				b.Name, b.Type, b.Reachable, b.Models, formatByteSize(b.DiskBytes), vram, b.Requests, b.Errors)
+		}
+		tw.Flush()
+	}
+
+	if len(r.Projections) > 0 {
+		fmt.Fprintln(w)
+		for _, p := range r.Projections {
+			limit := strconv.FormatInt(p.Limit, 10) + "/day"
+			current := fmt.Sprintf("%.0f/day", p.Current)
+			if p.Resource == "disk" {
+				limit, current = formatByteSize(p.Limit), formatByteSize(int64(p.Current))
+			}
+			when := "not reached at the current trend"
+			if p.ExhaustedAt != nil {
+				when = "reached " + p.ExhaustedAt.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "%s: %s of %s, limit %s (%s)\n", p.Resource, current, limit, when, p.Basis)
+		}
+	}
+}
+
+// parseByteSize parses sizes such as "500GB", "1.5TiB" or a plain byte count.
+// Decimal units are powers of 1000, binary units (KiB, MiB, ...) powers of 1024.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"B", 1},
+	}
+	factor := 1.0
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, factor = strings.TrimSpace(num), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("want a size such as 500GB, got %q", s)
+	}
+	return int64(n * factor), nil
+}
+
+// formatByteSize renders n bytes with a decimal unit, e.g. "4.7 GB".
+func formatByteSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,64 @@
+package contenoxcli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/stateservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{
+		"1024":    1024,
+		"500GB":   500e9,
+		"1.5 TiB": 3 << 39,
+		"64mib":   64 << 20,
+	} {
+		got, err := parseByteSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := parseByteSize("lots")
+	assert.Error(t, err)
+	_, err = parseByteSize("-1GB")
+	assert.Error(t, err)
+
+	assert.Equal(t, "512 B", formatByteSize(512))
+	assert.Equal(t, "4.7 GB", formatByteSize(4709611008))
+}
+
+func TestPrintCapacityReport(t *testing.T) {
+	vram := int64(5e9)
+	exhausted := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	report := &stateservice.CapacityReport{
+		WindowDays:        14,
+		BackendCount:      2,
+		ReachableBackends: 1,
+		Backends: []stateservice.BackendCapacity{
+			{Name: "gpu-1", Type: "ollama", Reachable: true, Models: 2, DiskBytes: 9e9, VRAMBytes: &vram, Requests: 700},
+			{Name: "cloud", Type: "openai", Models: 0},
+		},
+		DeclaredModels: []string{"llama3:8b", "qwen2.5:7b"},
+		PulledModels:   []string{"nomic-embed-text", "qwen2.5:7b"},
+		MissingModels:  []string{"llama3:8b"},
+		DiskBytes:      9e9,
+		VRAMBytes:      vram,
+		Requests:       stateservice.RequestVolume{Total: 700, PerDay: 50, TrendPerDay: 2.5},
+		Projections: []stateservice.CapacityProjection{
+			{Resource: "requests", Limit: 100, Current: 60, ExhaustedAt: &exhausted, Basis: "+2.5 requests/day per day"},
+			{Resource: "disk", Limit: 500e9, Current: 9e9, Basis: "+0 bytes/day pulled"},
+		},
+	}
+	var out bytes.Buffer
+	printCapacityReport(&out, report)
+	s := out.String()
+	assert.Contains(t, s, "Backends:        2 (1 reachable)")
+	assert.Contains(t, s, "not pulled:    llama3:8b")
+	assert.Contains(t, s, "VRAM (loaded):   5.0 GB")
+	assert.Contains(t, s, "trend +2.5/day per day")
+	assert.Contains(t, s, "requests: 60/day of 100/day, limit reached 2025-07-01")
+	assert.Contains(t, s, "disk: 9.0 GB of 500.0 GB, limit not reached at the current trend")
+}
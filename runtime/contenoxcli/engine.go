@@ -2,13 +2,14 @@ package contenoxcli
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/contenox/contenox/runtime/execservice"
 	"github.com/contenox/contenox/runtime/hitlservice"
@@ -27,6 +28,7 @@ import (
 	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/contenox/contenox/runtime/stateservice"
 	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/contenox/contenox/runtime/toolsproviderservice"
 	"github.com/contenox/contenox/runtime/vfsservice"
 )
 
@@ -40,6 +42,8 @@ type Engine struct {
 	LocalTools []string
 	// SetupCheck is the last SetupStatus evaluation after RunBackendCycle (for resolver-failure hints).
 	SetupCheck setupcheck.Result
+	// State exposes backend runtime state, usage and capacity reports.
+	State stateservice.Service
 }
 
 // BuildEngine scaffolds the complex dependency graph needed to run task chains.
@@ -119,6 +123,10 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 		}
 	}
 
+	if err := pruneHistory(ctx, runtimetypes.New(db.WithoutTransaction()), time.Now()); err != nil {
+		slog.Warn("Pruning the execution history failed", "error", err)
+	}
+
 	// 5. Backends are already in SQLite from `contenox backend add`; just run the sync cycle.
 	// 6. Run backend cycle
 	if !opts.EffectiveSkipBackendCycle {
@@ -145,6 +153,7 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 	}
 
 	ss := stateservice.New(state, db, ResolveWorkspaceID(opts.ContenoxDir))
+	engine.State = ss
 	res, err := ss.SetupStatus(ctx)
 	if err != nil {
 		slog.Debug("setup status failed", "error", err)
@@ -160,14 +169,21 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 	} else {
 		tracker = libtracker.NoopTracker{}
 	}
-	repo, err := llmrepo.NewModelManager(state, tokenizer, llmrepo.ModelManagerConfig{
+	manager, err := llmrepo.NewModelManager(state, tokenizer, llmrepo.ModelManagerConfig{
 		DefaultPromptModel:    llmrepo.ModelConfig{Name: opts.EffectiveDefaultModel, Provider: opts.EffectiveDefaultProvider},
 		DefaultEmbeddingModel: llmrepo.ModelConfig{Name: opts.EffectiveDefaultModel, Provider: opts.EffectiveDefaultProvider},
 		DefaultChatModel:      llmrepo.ModelConfig{Name: opts.EffectiveDefaultModel, Provider: opts.EffectiveDefaultProvider},
-	}, tracker)
+	}, tracker, llmrepo.WithUsageRecorder(runtimetypes.New(db.WithoutTransaction())))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model manager: %w", err)
 	}
+	var repo llmrepo.ModelRepo = manager
+	switch {
+	case opts.Replay != nil:
+		repo = &replayModelRepo{replay: opts.Replay, tokenizer: tokenizer}
+	case opts.Recorder != nil:
+		repo = &recordingModelRepo{ModelRepo: manager, rec: opts.Recorder}
+	}
 
 	// 8. Local tools
 	localTools := map[string]taskengine.ToolsRepo{
@@ -176,6 +192,8 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 		"webtools":      localtools.NewWebCaller(),
 		"local_fs":     localtools.NewLocalFSTools(opts.EffectiveLocalExecAllowedDir),
 		"plan_summary": localtools.NewPlanSummaryTools(planstore.New(db.WithoutTransaction(), ResolveWorkspaceID(opts.ContenoxDir))),
+		"desktop":      localtools.NewDesktopTools(),
+		"calc":         localtools.NewCalcTools(),
 	}
 	jsTools := map[string]taskengine.ToolsRepo{
 		"echo":    localtools.NewEchoTools(),
@@ -187,6 +205,18 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 	} else {
 		jsTools["ssh"] = sshTools
 	}
+	if opts.EffectiveEnablePython {
+		pyTools, err := localtools.NewPythonSandboxTools()
+		switch {
+		case err == nil:
+			localTools["python_sandbox"] = pyTools
+		case opts.Replay != nil:
+			// Replayed tool calls never reach the interpreter.
+			slog.Debug("python_sandbox tools not registered", "error", err)
+		default:
+			return nil, fmt.Errorf("--python: %w", err)
+		}
+	}
 	if opts.EffectiveEnableLocalExec {
 		toolsOpts := []localtools.LocalExecOption{}
 		if opts.EffectiveLocalExecAllowedDir != "" {
@@ -212,20 +242,63 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 	for name := range localTools {
 		engine.LocalTools = append(engine.LocalTools, name)
 	}
-	toolsRepo := tools.NewPersistentRepo(localTools, db, http.DefaultClient, bus)
+	toolsRepo := toolsproviderservice.WithUsageTracking(tools.NewPersistentRepo(localTools, db, http.DefaultClient, bus), db)
 
-	// Wrap with HITL interceptor when --hitl is requested.
-	if opts.EffectiveHITL {
-		hitlVFS := vfsservice.NewLocalFS(opts.ContenoxDir)
-		if err := ensureHITLPolicies(opts.ContenoxDir); err != nil {
-			slog.Warn("hitl: failed to write embedded policy presets", "error", err)
+	// Wrap with HITL interceptor when --hitl or --confirm-tools is requested.
+	if opts.EffectiveHITL || opts.EffectiveConfirmTools {
+		var policy hitlservice.PolicyEvaluator
+		ask := NewCLIAskApproval(os.Stderr)
+		if opts.EffectiveHITL {
+			hitlVFS := vfsservice.NewLocalFS(opts.ContenoxDir)
+			if err := ensureHITLPolicies(opts.ContenoxDir); err != nil {
+				slog.Warn("hitl: failed to write embedded policy presets", "error", err)
+			}
+			policy = hitlservice.New(hitlVFS, store, tracker)
+		}
+		if opts.EffectiveConfirmTools {
+			allow := loadToolAllowList(engineCtx, store, ResolveWorkspaceID(opts.ContenoxDir))
+			policy = &confirmToolsPolicy{inner: policy, allow: allow}
+			ask = newConfirmAskApproval(os.Stderr, allow)
 		}
-		hitlSvc := hitlservice.New(hitlVFS, store, tracker)
-		toolsRepo = localtools.NewHITLWrapper(toolsRepo, NewCLIAskApproval(os.Stderr), hitlSvc, tracker)
+		if opts.Unattended {
+			ask = newDenyApproval(os.Stderr)
+		}
+		toolsRepo = localtools.NewHITLWrapper(toolsRepo, ask, policy, tracker)
+	}
+
+	switch {
+	case opts.Replay != nil:
+		toolsRepo = &replayTools{ToolsRepo: toolsRepo, replay: opts.Replay}
+	case opts.Recorder != nil:
+		toolsRepo = &recordingTools{ToolsRepo: toolsRepo, rec: opts.Recorder}
 	}
 
 	// 9. Task engine
 	taskEngineCtx := taskengine.WithTaskEventSink(engineCtx, taskengine.NewBusTaskEventSink(bus))
+	taskEngineCtx = taskengine.WithPromptCache(taskEngineCtx, newKVPromptCache(db), opts.PromptCacheTTL)
+	pricing, err := readPricing(opts.ContenoxDir)
+	if err != nil {
+		return nil, err
+	}
+	taskEngineCtx = taskengine.WithPricing(taskEngineCtx, pricing)
+	taskEngineCtx = taskengine.WithCostRecorder(taskEngineCtx, store)
+	redactor, err := readRedaction(opts.ContenoxDir)
+	if err != nil {
+		return nil, err
+	}
+	taskEngineCtx = taskengine.WithRedactor(taskEngineCtx, redactor)
+	guardrails, err := readGuardrails(opts.ContenoxDir)
+	if err != nil {
+		return nil, err
+	}
+	taskEngineCtx = taskengine.WithGuardrails(taskEngineCtx, guardrails...)
+	if len(opts.RateLimits) > 0 {
+		limiter, err := taskengine.NewRateLimiter(opts.RateLimits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limits: %w", err)
+		}
+		taskEngineCtx = taskengine.WithRateLimiter(taskEngineCtx, limiter)
+	}
 	exec, err := taskengine.NewExec(taskEngineCtx, repo, toolsRepo, tracker)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task executor: %w", err)
@@ -238,7 +311,7 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 	if err != nil {
 		return nil, fmt.Errorf("failed to create macro environment: %w", err)
 	}
-	taskService := execservice.NewTasksEnv(engineCtx, envExec, toolsRepo)
+	taskService := execservice.EnvWithExecutionLog(execservice.NewTasksEnv(engineCtx, envExec, toolsRepo), store, redactor)
 
 	engine.TaskService = taskService
 	engine.Tracker = tracker
@@ -248,6 +321,12 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 		mgr.StopAll() // terminates all stdio MCP child processes
 		oldStop()
 	}
+
+	// Keep applying scheduled configuration changes while the session runs;
+	// OpenDBAt already applied the ones due at startup.
+	windowStore := runtimetypes.New(db.WithoutTransaction())
+	window := func() *runtimetypes.ChangeWindow { return changeWindow(engineCtx, windowStore) }
+	go runtimetypes.RunScheduledChanges(engineCtx, db, scheduledChangesInterval, window, scheduledChangeNotifier(os.Stderr))
 	success = true
 	return engine, nil
 }
@@ -281,7 +360,7 @@ func (e *Engine) WatchTaskEvents(ctx context.Context, requestID string, ch chan<
 					return
 				}
 				var event taskengine.TaskEvent
-				if err := json.Unmarshal(payload, &event); err != nil {
+				if _, err := taskengine.TaskEventSchema.Decode(payload, &event); err != nil {
 					slog.Warn("failed to decode task event", "error", err)
 					continue
 				}
@@ -296,3 +375,99 @@ func (e *Engine) WatchTaskEvents(ctx context.Context, requestID string, ch chan<
 
 	return sub, nil
 }
+
+// unmarshalChain decodes a chain file into chain. Files ending in .yaml or
+// .yml are read as YAML, everything else as JSON.
+//
+// Imports are resolved against the directory of path (see chainFileLoader).
+func unmarshalChain(path string, data []byte, chain *taskengine.TaskChainDefinition) error {
+	parsed, err := taskengine.ParseChainDefinition(path, data)
+	if err != nil {
+		return err
+	}
+	parsed, err = taskengine.ResolveImports(context.Background(), parsed, chainFileLoader(filepath.Dir(path)))
+	if err != nil {
+		return err
+	}
+	*chain = *parsed
+	return nil
+}
+
+// chainFileLoader loads imported chains from dir. A reference with a chain
+// file extension is a path relative to dir; any other reference is the ID of
+// a chain file in dir. The imports of a loaded chain are anchored to its own
+// directory, so nested imports resolve relative to the file that declares
+// them.
+func chainFileLoader(dir string) taskengine.ChainLoader {
+	return func(_ context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
+		path, chain, err := findChainFile(dir, ref)
+		if err != nil {
+			return nil, err
+		}
+		if err := anchorImports(chain, filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+		return chain, nil
+	}
+}
+
+// anchorImports rewrites the imports of chain into paths of the chain files
+// they refer to, resolved against dir.
+func anchorImports(chain *taskengine.TaskChainDefinition, dir string) error {
+	for i, imp := range chain.Imports {
+		if imp.Chain == "" {
+			continue // reported by ResolveImports
+		}
+		path, err := chainFilePath(dir, imp.Chain)
+		if err != nil {
+			return fmt.Errorf("chain %s: import %q: %w", chain.ID, imp.Chain, err)
+		}
+		chain.Imports[i].Chain = path
+	}
+	return nil
+}
+
+// chainFilePath returns the path of the chain file ref refers to in dir.
+func chainFilePath(dir, ref string) (string, error) {
+	if !taskengine.IsChainPath(ref) {
+		path, _, err := findChainFile(dir, ref)
+		return path, err
+	}
+	if filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(dir, ref), nil
+}
+
+// findChainFile loads the chain ref refers to in dir: a chain file path, or
+// the ID of a chain file in dir.
+func findChainFile(dir, ref string) (string, *taskengine.TaskChainDefinition, error) {
+	if taskengine.IsChainPath(ref) {
+		path, _ := chainFilePath(dir, ref)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		chain, err := taskengine.ParseChainDefinition(path, data)
+		return path, chain, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !taskengine.IsChainPath(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		chain, err := taskengine.ParseChainDefinition(path, data)
+		if err == nil && chain.ID == ref {
+			return path, chain, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no chain with id %q in %s", ref, dir)
+}
@@ -0,0 +1,38 @@
+package contenoxcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalChain_NestedImportsAreRelativeToTheirFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	write("lib/setup.yaml", "id: setup\nimports:\n  - chain: helpers.yaml\n    as: h\n  - chain: errors\n    as: e\ntasks:\n  - id: tools\n    handler: noop\n")
+	write("lib/helpers.yaml", "id: helpers\ntasks:\n  - id: trim\n    handler: noop\n")
+	write("lib/errs.yaml", "id: errors\ntasks:\n  - id: fail\n    handler: raise_error\n")
+	// Same names next to main.yaml must not be picked up for lib/setup.yaml.
+	write("helpers.yaml", "id: other\ntasks:\n  - id: wrong\n    handler: noop\n")
+	path := write("main.yaml", "id: main\nimports:\n  - chain: lib/setup.yaml\n    as: setup\ntasks:\n  - id: answer\n    handler: noop\n")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var chain taskengine.TaskChainDefinition
+	require.NoError(t, unmarshalChain(path, data, &chain))
+
+	var ids []string
+	for _, task := range chain.Tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"answer", "setup_tools", "setup_h_trim", "setup_e_fail"}, ids)
+}
@@ -0,0 +1,74 @@
+// guardrails.go — input/output guardrails applied to every task of a run.
+package contenoxcli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"gopkg.in/yaml.v3"
+)
+
+// guardrailsFileName holds the guardrails looked up in the .contenox
+// directory. pii redacts personal data; each rule rejects or redacts text
+// matching any of its Go regular expressions:
+//
+//	pii: true
+//	rules:
+//	  - name: confidential
+//	    action: reject
+//	    patterns: ['(?i)\bconfidential\b']
+const guardrailsFileName = "guardrails.yaml"
+
+type guardrailsFile struct {
+	PII   bool            `yaml:"pii"`
+	Rules []guardrailRule `yaml:"rules"`
+}
+
+type guardrailRule struct {
+	Name        string   `yaml:"name"`
+	Action      string   `yaml:"action"`
+	Patterns    []string `yaml:"patterns"`
+	Replacement *string  `yaml:"replacement"`
+}
+
+// readGuardrails reads the guardrails of contenoxDir in the order they run:
+// pii first, then the rules as listed. A missing file means no guardrails.
+func readGuardrails(contenoxDir string) ([]taskengine.Guardrail, error) {
+	if contenoxDir == "" {
+		return nil, nil
+	}
+	path := filepath.Join(contenoxDir, guardrailsFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read guardrails file: %w", err)
+	}
+	var file guardrailsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse guardrails file %q: %w", path, err)
+	}
+	var guardrails []taskengine.Guardrail
+	if file.PII {
+		guardrails = append(guardrails, taskengine.NewPIIGuardrail())
+	}
+	for i, rule := range file.Rules {
+		if rule.Name == "" || len(rule.Patterns) == 0 {
+			return nil, fmt.Errorf("guardrails file %q: rule %d needs a name and patterns", path, i+1)
+		}
+		g, err := taskengine.NewRegexGuardrail(rule.Name, taskengine.GuardrailAction(rule.Action), rule.Patterns...)
+		if err != nil {
+			return nil, fmt.Errorf("guardrails file %q: %w", path, err)
+		}
+		if rule.Replacement != nil {
+			g.Replacement = *rule.Replacement
+		}
+		guardrails = append(guardrails, g)
+	}
+	return guardrails, nil
+}
@@ -0,0 +1,45 @@
+package contenoxcli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadGuardrails(t *testing.T) {
+	dir := t.TempDir()
+	guardrails, err := readGuardrails(dir)
+	require.NoError(t, err)
+	assert.Empty(t, guardrails, "no file means no guardrails")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, guardrailsFileName), []byte(`
+pii: true
+rules:
+  - name: confidential
+    action: reject
+    patterns: ['(?i)\bconfidential\b']
+`), 0o600))
+	guardrails, err = readGuardrails(dir)
+	require.NoError(t, err)
+	require.Len(t, guardrails, 2)
+	assert.Equal(t, "pii", guardrails[0].Name())
+	assert.Equal(t, "confidential", guardrails[1].Name())
+
+	res, err := guardrails[1].Check(context.Background(), taskengine.GuardrailInput, nil, "Confidential: Q3 numbers", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.GuardrailReject, res.Action)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, guardrailsFileName), []byte(`
+rules:
+  - name: typo
+    action: block
+    patterns: ['x']
+`), 0o600))
+	_, err = readGuardrails(dir)
+	assert.ErrorContains(t, err, "action must be")
+}
@@ -23,60 +23,84 @@ import (
 // (including blank input and EOF) denies.
 func NewCLIAskApproval(w io.Writer) localtools.AskApproval {
 	return func(ctx context.Context, req hitlservice.ApprovalRequest) (bool, error) {
-		// Try to open the controlling terminal directly so we can prompt even
-		// when stdin is a pipe.
-		tty, err := os.Open("/dev/tty")
+		printApprovalRequest(w, "HITL approval required", req)
+		answer, err := readApprovalAnswer(ctx, w, "  Approve? [y/N]: ")
 		if err != nil {
-			tty = os.Stdin
-		} else {
-			defer tty.Close()
+			return false, err
 		}
+		return answer == "y" || answer == "yes", nil
+	}
+}
 
-		fmt.Fprintln(w, "\n────────────────────────────────────────────────────")
-		fmt.Fprintf(w, "  HITL approval required\n")
-		fmt.Fprintf(w, "  Tools : %s\n", req.ToolsName)
-		fmt.Fprintf(w, "  Tool : %s\n", req.ToolName)
-		if len(req.Args) > 0 {
-			fmt.Fprintln(w, "  Args :")
-			for k, v := range req.Args {
-				fmt.Fprintf(w, "    %s = %v\n", k, v)
-			}
+// newDenyApproval returns the AskApproval callback of unattended runs: it
+// reports the tool call to w and denies it without prompting.
+func newDenyApproval(w io.Writer) localtools.AskApproval {
+	return func(_ context.Context, req hitlservice.ApprovalRequest) (bool, error) {
+		fmt.Fprintf(w, "denied %s.%s: approval required, but nobody can be asked\n", req.ToolsName, req.ToolName)
+		return false, nil
+	}
+}
+
+// printApprovalRequest prints the tool name, args, and diff (if any) of req
+// under title.
+func printApprovalRequest(w io.Writer, title string, req hitlservice.ApprovalRequest) {
+	fmt.Fprintln(w, "\n────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "  %s\n", title)
+	fmt.Fprintf(w, "  Tools : %s\n", req.ToolsName)
+	fmt.Fprintf(w, "  Tool : %s\n", req.ToolName)
+	if len(req.Args) > 0 {
+		fmt.Fprintln(w, "  Args :")
+		for k, v := range req.Args {
+			fmt.Fprintf(w, "    %s = %v\n", k, v)
 		}
-		if req.Diff != "" {
-			fmt.Fprintln(w, "  Diff :")
-			for _, line := range strings.Split(req.Diff, "\n") {
-				fmt.Fprintf(w, "    %s\n", line)
-			}
+	}
+	if req.Diff != "" {
+		fmt.Fprintln(w, "  Diff :")
+		for _, line := range strings.Split(req.Diff, "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
 		}
-		fmt.Fprintln(w, "────────────────────────────────────────────────────")
-		fmt.Fprint(w, "  Approve? [y/N]: ")
+	}
+	fmt.Fprintln(w, "────────────────────────────────────────────────────")
+}
+
+// readApprovalAnswer prints prompt to w and returns the trimmed, lower-cased
+// line the user enters. EOF yields "". It blocks until the user responds or
+// ctx is cancelled.
+func readApprovalAnswer(ctx context.Context, w io.Writer, prompt string) (string, error) {
+	// Try to open the controlling terminal directly so we can prompt even
+	// when stdin is a pipe.
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		tty = os.Stdin
+	} else {
+		defer tty.Close()
+	}
+	fmt.Fprint(w, prompt)
 
-		// Read the response in a goroutine so we can respect ctx cancellation.
-		type result struct {
-			line string
-			ok   bool
+	// Read the response in a goroutine so we can respect ctx cancellation.
+	type result struct {
+		line string
+		ok   bool
+	}
+	ch := make(chan result, 1)
+	go func() {
+		scanner := bufio.NewScanner(tty)
+		if scanner.Scan() {
+			ch <- result{line: scanner.Text(), ok: true}
+		} else {
+			ch <- result{ok: false} // EOF or error
 		}
-		ch := make(chan result, 1)
-		go func() {
-			scanner := bufio.NewScanner(tty)
-			if scanner.Scan() {
-				ch <- result{line: scanner.Text(), ok: true}
-			} else {
-				ch <- result{ok: false} // EOF or error
-			}
-		}()
+	}()
 
-		select {
-		case r := <-ch:
-			fmt.Fprintln(w) // newline after inline prompt
-			if !r.ok {
-				return false, nil
-			}
-			trimmed := strings.TrimSpace(strings.ToLower(r.line))
-			return trimmed == "y" || trimmed == "yes", nil
-		case <-ctx.Done():
-			fmt.Fprintln(w, "\n  (cancelled)")
-			return false, ctx.Err()
+	select {
+	case r := <-ch:
+		fmt.Fprintln(w) // newline after inline prompt
+		if !r.ok {
+			return "", nil
 		}
+		return strings.TrimSpace(strings.ToLower(r.line)), nil
+	case <-ctx.Done():
+		fmt.Fprintln(w, "\n  (cancelled)")
+		return "", ctx.Err()
 	}
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/contenox/contenox/libtracker"
 	"github.com/contenox/contenox/runtime/backendservice"
+	"github.com/contenox/contenox/runtime/internal/clikv"
 	"github.com/contenox/contenox/runtime/internal/runtimestate"
 	"github.com/contenox/contenox/runtime/internal/setupcheck"
 	"github.com/contenox/contenox/runtime/runtimetypes"
@@ -47,6 +48,31 @@ var providerConfigs = map[string]providerConfig{
 		defaultModel: "gpt-5-mini",
 		envKey:       "OPENAI_API_KEY",
 	},
+	"anthropic": {
+		name:         "Anthropic",
+		defaultModel: "claude-sonnet-4-5-20250929",
+		envKey:       "ANTHROPIC_API_KEY",
+	},
+	"mistral": {
+		name:         "Mistral AI",
+		defaultModel: "mistral-large-latest",
+		envKey:       "MISTRAL_API_KEY",
+	},
+	"groq": {
+		name:         "Groq",
+		defaultModel: "llama-3.3-70b-versatile",
+		envKey:       "GROQ_API_KEY",
+	},
+	"azure-openai": {
+		name:         "Azure OpenAI",
+		defaultModel: "gpt-4o",
+		envKey:       "AZURE_OPENAI_API_KEY",
+	},
+	"bedrock": {
+		name:         "AWS Bedrock",
+		defaultModel: "us.anthropic.claude-sonnet-4-5-20250929-v1:0",
+		envKey:       "",
+	},
 	"local": {
 		name:         "Local (GGUF)",
 		defaultModel: "",
@@ -100,7 +126,7 @@ func hasBackendOfType(providerType string) bool {
 }
 
 // RunInit scaffolds .contenox/ with default chain files.
-// provider is "" (defaults to the already-configured provider or "local"), "ollama", "gemini", "openai", or "local".
+// provider is "" (defaults to the already-configured provider or "local"), "ollama", "gemini", "openai", "anthropic", "mistral", "groq", "azure-openai", "bedrock", or "local".
 // contenoxDir is the target data directory (e.g. from --data-dir or the default .contenox/).
 func RunInit(out, errOut io.Writer, force bool, provider string, contenoxDir string) error {
 	provider = strings.ToLower(strings.TrimSpace(provider))
@@ -125,7 +151,7 @@ func RunInit(out, errOut io.Writer, force bool, provider string, contenoxDir str
 
 	pc, ok := providerConfigs[provider]
 	if !ok {
-		return fmt.Errorf("unknown provider %q — valid options: ollama, gemini, openai, local, vertex-google, vertex-anthropic, vertex-meta, vertex-mistralai", provider)
+		return fmt.Errorf("unknown provider %q — valid options: ollama, gemini, openai, anthropic, mistral, groq, azure-openai, bedrock, local, vertex-google, vertex-anthropic, vertex-meta, vertex-mistralai", provider)
 	}
 	if err := os.MkdirAll(contenoxDir, 0750); err != nil {
 		return fmt.Errorf("failed to create .contenox directory: %w", err)
@@ -200,6 +226,14 @@ func RunInit(out, errOut io.Writer, force bool, provider string, contenoxDir str
 			if err != nil {
 				return err
 			}
+			// New installs ask before the model runs commands or writes files.
+			if cur, _ := getConfigKV(ctx, store, confirmToolsKey); cur == "" {
+				if err := clikv.SetString(ctx, store, confirmToolsKey, "true"); err == nil {
+					fmt.Fprintln(out, "Tool calls that run commands or write files ask for confirmation first.")
+					fmt.Fprintln(out, "  To turn this off: contenox config set confirm-tools false")
+					fmt.Fprintln(out, "")
+				}
+			}
 			db.Close()
 			if curModel != "" || curProvider != "" {
 				fmt.Fprintln(out, "Current config (from ~/.contenox/local.db):")
@@ -268,6 +302,22 @@ func RunInit(out, errOut io.Writer, force bool, provider string, contenoxDir str
 		fmt.Fprintln(out, "  Get started with Vertex AI: https://cloud.google.com/vertex-ai/generative-ai/docs/start/quickstarts")
 		fmt.Fprintln(out, "")
 		chatStep = 4
+	case "bedrock":
+		fmt.Fprintln(out, "  1. Provide AWS credentials with access to Bedrock models:")
+		fmt.Fprintln(out, "       export AWS_REGION=us-east-1")
+		fmt.Fprintln(out, "       export AWS_ACCESS_KEY_ID=... AWS_SECRET_ACCESS_KEY=...   # or AWS_PROFILE=...")
+		fmt.Fprintln(out, "")
+		fmt.Fprintf(out, "  2. Register the %s backend:\n", pc.name)
+		fmt.Fprintf(out, "       contenox backend add %s --type %s\n", provider, provider)
+		fmt.Fprintln(out, "       contenox doctor")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "  3. Set defaults:")
+		fmt.Fprintf(out, "       contenox config set default-provider %s\n", provider)
+		fmt.Fprintf(out, "       contenox config set default-model %s\n", pc.defaultModel)
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "  Enable model access in the Bedrock console: https://console.aws.amazon.com/bedrock/home#/modelaccess")
+		fmt.Fprintln(out, "")
+		chatStep = 4
 	case "local":
 		fmt.Fprintln(out, "  1. Pull a model (choose by available VRAM):")
 		fmt.Fprintln(out, "")
@@ -325,15 +375,32 @@ func RunInit(out, errOut io.Writer, force bool, provider string, contenoxDir str
 				fmt.Fprintln(out, "  Get a free Gemini API key: https://aistudio.google.com/apikey")
 			case "openai":
 				fmt.Fprintln(out, "  Get an OpenAI API key: https://platform.openai.com/api-keys")
+			case "anthropic":
+				fmt.Fprintln(out, "  Get an Anthropic API key: https://console.anthropic.com/settings/keys")
+			case "mistral":
+				fmt.Fprintln(out, "  Get a Mistral API key: https://console.mistral.ai/api-keys")
+			case "groq":
+				fmt.Fprintln(out, "  Get a Groq API key: https://console.groq.com/keys")
+			case "azure-openai":
+				fmt.Fprintln(out, "  Find the key and endpoint of your resource under Keys and Endpoint in the Azure portal.")
 			}
 			fmt.Fprintln(out, "")
 			registerStep = 2
 		}
 		if !backendRegistered {
 			fmt.Fprintf(out, "  %d. Register the %s backend and set defaults:\n", registerStep, pc.name)
-			fmt.Fprintf(out, "       contenox backend add %s --type %s --api-key-env %s\n", provider, provider, pc.envKey)
+			if provider == "azure-openai" {
+				fmt.Fprintf(out, "       contenox backend add %s --type %s --api-key-env %s \\\n", provider, provider, pc.envKey)
+				fmt.Fprintln(out, `         --url "https://<resource>.openai.azure.com/?api-version=2024-10-21"`)
+			} else {
+				fmt.Fprintf(out, "       contenox backend add %s --type %s --api-key-env %s\n", provider, provider, pc.envKey)
+			}
 			fmt.Fprintf(out, "       contenox config set default-provider %s\n", provider)
-			fmt.Fprintf(out, "       contenox config set default-model %s\n", pc.defaultModel)
+			if provider == "azure-openai" {
+				fmt.Fprintf(out, "       contenox config set default-model %s   # a deployment name\n", pc.defaultModel)
+			} else {
+				fmt.Fprintf(out, "       contenox config set default-model %s\n", pc.defaultModel)
+			}
 			fmt.Fprintln(out, "       contenox doctor")
 			fmt.Fprintln(out, "")
 			chatStep = registerStep + 1
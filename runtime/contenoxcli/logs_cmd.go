@@ -0,0 +1,225 @@
+// logs_cmd.go — contenox logs: browse and tail the local execution history.
+package contenoxcli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/spf13/cobra"
+)
+
+// logsPollInterval is how often --follow checks for new executions.
+const logsPollInterval = time.Second
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the history of chain executions on this machine.",
+	Long: `Show the chain executions recorded in the local database.
+
+Every run, chat turn and plan step records the chain, a digest of its input,
+its status, duration, step count and token usage. The cost is estimated from
+.contenox/pricing.yaml.
+
+With --follow, new executions are printed as they finish until interrupted.
+
+Examples:
+  contenox logs
+  contenox logs --failed --since 168h
+  contenox logs -n 50
+  contenox logs --follow`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		limit, _ := cmd.Flags().GetInt("limit")
+		since, _ := cmd.Flags().GetDuration("since")
+		failed, _ := cmd.Flags().GetBool("failed")
+		follow, _ := cmd.Flags().GetBool("follow")
+
+		db, store, err := openConfigDB(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		filter := runtimetypes.ExecutionLogFilter{FailedOnly: failed, Limit: limit}
+		if since > 0 {
+			filter.After = time.Now().Add(-since)
+		}
+		entries, err := store.ListExecutionLog(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list execution log: %w", err)
+		}
+		out := cmd.OutOrStdout()
+		if len(entries) == 0 && !follow {
+			fmt.Fprintln(out, "No executions recorded in this window.")
+			return nil
+		}
+		if err := printExecutionLog(out, entries, true); err != nil {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if n := len(entries); n > 0 {
+			filter.After = entries[n-1].FinishedAt
+		} else {
+			filter.After = time.Now()
+		}
+		filter.Limit = 0
+		ticker := time.NewTicker(logsPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			entries, err := store.ListExecutionLog(ctx, filter)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("failed to list execution log: %w", err)
+			}
+			if n := len(entries); n > 0 {
+				filter.After = entries[n-1].FinishedAt
+			}
+			if err := printExecutionLog(out, entries, false); err != nil {
+				return err
+			}
+		}
+	},
+}
+
+// printExecutionLog prints one line per entry, after a header line if header
+// is set. Failed executions show their error in place of the input.
+func printExecutionLog(out io.Writer, entries []*runtimetypes.ExecutionLogEntry, header bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(w, "FINISHED\tCHAIN\tSTATUS\tDURATION\tSTEPS\tTOKENS\tEST. COST\tINPUT")
+	}
+	for _, e := range entries {
+		tokens, cost := "-", "-"
+		if e.InputTokens+e.OutputTokens > 0 {
+			tokens = fmt.Sprintf("%d→%d", e.InputTokens, e.OutputTokens)
+		}
+		if e.Cost > 0 {
+			cost = formatCost(e.Cost)
+		}
+		detail := e.InputPreview
+		if e.Status == runtimetypes.ExecutionFailed && e.Error != "" {
+			detail = strings.Join(strings.Fields(e.Error), " ")
+		}
+		chain := e.ChainID
+		if chain == "" {
+			chain = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			e.FinishedAt.Local().Format("2006-01-02 15:04:05"), chain, e.Status,
+			formatDuration(e.Duration()), e.Steps, tokens, cost, detail)
+	}
+	return w.Flush()
+}
+
+var logsVariantsCmd = &cobra.Command{
+	Use:   "variants [chain-id]",
+	Short: "Compare the outcomes of prompt variants.",
+	Long: `Compare the prompt variants of tasks with prompt_variants.
+
+For every chain, task and variant recorded in the local execution history it
+shows how often the variant was drawn, the share of those runs that succeeded
+and how often the task took each transition, e.g. a classifier's labels.
+
+Examples:
+  contenox logs variants
+  contenox logs variants support-chain --since 168h
+  contenox logs variants --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		since, _ := cmd.Flags().GetDuration("since")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		db, store, err := openConfigDB(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		filter := runtimetypes.ExecutionLogFilter{}
+		if len(args) == 1 {
+			filter.ChainID = args[0]
+		}
+		if since > 0 {
+			filter.After = time.Now().Add(-since)
+		}
+		entries, err := store.ListExecutionLog(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list execution log: %w", err)
+		}
+		stats := runtimetypes.SummarizePromptVariants(entries)
+
+		out := cmd.OutOrStdout()
+		if asJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		}
+		if len(stats) == 0 {
+			fmt.Fprintln(out, "No prompt variants recorded in this window.")
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHAIN\tTASK\tVARIANT\tRUNS\tSUCCESS\tTRANSITIONS")
+		for _, st := range stats {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.0f%%\t%s\n",
+				st.ChainID, st.TaskID, st.Variant, st.Runs, st.SuccessRate()*100, formatTransitionRates(st))
+		}
+		return w.Flush()
+	},
+}
+
+// formatTransitionRates lists the transitions of st by share, most frequent
+// first, e.g. "ok 92%, failed 8%".
+func formatTransitionRates(st *runtimetypes.PromptVariantStats) string {
+	names := slices.Collect(maps.Keys(st.Transitions))
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(st.Transitions[b], st.Transitions[a]), strings.Compare(a, b))
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		if name == "" {
+			name = "-"
+		}
+		parts[i] = fmt.Sprintf("%s %.0f%%", name, float64(st.Transitions[names[i]])/float64(st.Runs)*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	logsCmd.AddCommand(logsVariantsCmd)
+	logsVariantsCmd.Flags().Duration("since", 0, "Only count executions that finished within this window, e.g. 168h.")
+	logsVariantsCmd.Flags().Bool("json", false, "Print the statistics as JSON")
+
+	logsCmd.Flags().IntP("limit", "n", 20, "Number of most recent executions to show.")
+	logsCmd.Flags().Duration("since", 0, "Only show executions that finished within this window, e.g. 24h.")
+	logsCmd.Flags().Bool("failed", false, "Only show failed executions.")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new executions as they finish.")
+}
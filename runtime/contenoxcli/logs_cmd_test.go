@@ -0,0 +1,32 @@
+package contenoxcli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintExecutionLog(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	entries := []*runtimetypes.ExecutionLogEntry{
+		{
+			ChainID: "review", InputPreview: "summarize main.go", Status: runtimetypes.ExecutionSucceeded,
+			Steps: 3, InputTokens: 1200, OutputTokens: 300, Cost: 0.0042,
+			StartedAt: started, FinishedAt: started.Add(1500 * time.Millisecond),
+		},
+		{
+			InputPreview: "hello", Status: runtimetypes.ExecutionFailed, Error: "task answer:\nchat failed",
+			Steps: 1, StartedAt: started, FinishedAt: started.Add(20 * time.Millisecond),
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, printExecutionLog(&buf, entries, true))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	require.Contains(t, string(lines[0]), "FINISHED")
+	require.Regexp(t, `2026-03-01 12:00:01\s+review\s+succeeded\s+1\.50s\s+3\s+1200→300\s+\$0\.0042\s+summarize main\.go`, string(lines[1]))
+	require.Regexp(t, `-\s+failed\s+20ms\s+1\s+-\s+-\s+task answer: chat failed$`, string(lines[2]))
+}
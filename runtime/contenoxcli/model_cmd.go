@@ -2,17 +2,20 @@ package contenoxcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 	"unicode"
 
 	"github.com/contenox/contenox/runtime/internal/runtimestate"
 	libbus "github.com/contenox/contenox/libbus"
 	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/libkvstore"
 	"github.com/contenox/contenox/libtracker"
 	"github.com/contenox/contenox/runtime/modelservice"
 	"github.com/contenox/contenox/runtime/runtimetypes"
@@ -267,7 +270,113 @@ Examples:
 	},
 }
 
+// newModelService returns the model service with names checked against the
+// provider catalogs cached by the last backend sync. A near-miss of a catalog
+// name is refused, or with force only printed as a warning to errW.
+func newModelService(db libdb.DBManager, errW io.Writer, force bool) modelservice.Service {
+	var warn func(error)
+	if force {
+		warn = func(err error) { fmt.Fprintf(errW, "warning: %v\n", err) }
+	}
+	return modelservice.WithCatalogValidation(modelservice.New(db, ""), cachedCatalogs(db), warn)
+}
+
+// cachedCatalogs looks up the provider catalogs cached by the last backend
+// sync, without contacting the providers.
+func cachedCatalogs(db libdb.DBManager) modelservice.CatalogLookup {
+	kv := libkvstore.NewSQLiteManager(db)
+	return func(ctx context.Context) (map[string][]string, error) {
+		return runtimestate.CachedCatalogModels(ctx, db, kv)
+	}
+}
+
+// checkDefaultModel checks a new default-model value against the cached
+// provider catalogs. The engine creates a model record for the default model
+// on every start, so a typo would otherwise only surface as a failing model
+// call. With force a near-miss is printed as a warning to errW instead.
+func checkDefaultModel(ctx context.Context, db libdb.DBManager, name string, errW io.Writer, force bool) error {
+	err := modelservice.CheckModelName(ctx, cachedCatalogs(db), name)
+	if err != nil && force && errors.Is(err, modelservice.ErrUnknownModel) {
+		fmt.Fprintf(errW, "warning: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+var modelUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show request counts, error rates and latency per backend and model.",
+	Long: `Show aggregated model usage recorded by this installation.
+
+Every chat, prompt, embed and stream call that reached a backend is counted
+in hourly buckets. Use --since to pick the reporting window. Tokens are those
+of chain tasks; the cost is estimated from .contenox/pricing.yaml.
+
+Examples:
+  contenox model usage
+  contenox model usage --since 168h`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		since, _ := cmd.Flags().GetDuration("since")
+		db, svc, err := openBackendDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		store := runtimetypes.New(db.WithoutTransaction())
+		usage, err := store.ListModelUsage(ctx, time.Now().UTC().Add(-since))
+		if err != nil {
+			return fmt.Errorf("failed to list model usage: %w", err)
+		}
+		costs, err := store.ListModelCost(ctx, time.Now().UTC().Add(-since))
+		if err != nil {
+			return fmt.Errorf("failed to list model cost: %w", err)
+		}
+		costByModel := make(map[string]*runtimetypes.ModelCost, len(costs))
+		for _, c := range costs {
+			costByModel[c.BackendID+"\x00"+c.ModelName] = c
+		}
+		if len(usage) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No model usage recorded in this window.")
+			return nil
+		}
+		backendNames := map[string]string{}
+		if backends, err := svc.List(ctx, nil, 100); err == nil {
+			for _, b := range backends {
+				backendNames[b.ID] = b.Name
+			}
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BACKEND\tMODEL\tREQUESTS\tERRORS\tERROR RATE\tAVG LATENCY\tTOKENS IN\tTOKENS OUT\tEST. COST")
+		var totalCost float64
+		for _, u := range usage {
+			backend := backendNames[u.BackendID]
+			if backend == "" {
+				backend = u.BackendID
+			}
+			tokensIn, tokensOut, cost := "-", "-", "-"
+			if c := costByModel[u.BackendID+"\x00"+u.ModelName]; c != nil {
+				tokensIn, tokensOut = fmt.Sprint(c.InputTokens), fmt.Sprint(c.OutputTokens)
+				if c.Cost > 0 {
+					cost = formatCost(c.Cost)
+					totalCost += c.Cost
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.1f%%\t%s\t%s\t%s\t%s\n",
+				backend, u.ModelName, u.RequestCount, u.ErrorCount, u.ErrorRate()*100, u.AvgLatency(), tokensIn, tokensOut, cost)
+		}
+		if totalCost > 0 {
+			fmt.Fprintf(w, "\t\t\t\t\t\t\t\t%s\n", formatCost(totalCost))
+		}
+		return w.Flush()
+	},
+}
+
 func init() {
+	modelUsageCmd.Flags().Duration("since", 24*time.Hour, "Reporting window, counted back from now.")
+	modelCmd.AddCommand(modelUsageCmd)
 	modelSetContextCmd.Flags().String("context", "", "Context window size: bare int or shorthand (12k, 128k, 1m).")
 	_ = modelSetContextCmd.MarkFlagRequired("context")
 	modelCmd.AddCommand(modelListCmd)
@@ -0,0 +1,186 @@
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/modelregistry"
+	"github.com/contenox/contenox/runtime/modelregistryservice"
+	"github.com/contenox/contenox/runtime/modelservice"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var modelImportCmd = &cobra.Command{
+	Use:   "import <owner/repo>",
+	Short: "Declare a model from its Hugging Face hub metadata.",
+	Long: `Read a GGUF model repository from the Hugging Face hub and declare it locally:
+a registry entry for 'contenox model pull' and a model record carrying the
+context length and capabilities, so neither has to be typed by hand.
+
+The recommended quantization (Q4_K_M when available) is used unless --quant
+names another. The Ollama pull spec of the chosen file is printed; with
+--ollama the model record is named by it, so it matches the model Ollama
+serves after 'ollama pull'.
+
+Examples:
+  contenox model import bartowski/Qwen_Qwen3-4B-GGUF
+  contenox model import Qwen/Qwen2.5-7B-Instruct-GGUF --quant Q5_K_M --name qwen-7b
+  contenox model import bartowski/Qwen_Qwen3-4B-GGUF --ollama`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := libtracker.WithNewRequestID(context.Background())
+		quant, _ := cmd.Flags().GetString("quant")
+		name, _ := cmd.Flags().GetString("name")
+		ollama, _ := cmd.Flags().GetBool("ollama")
+		hubURL, _ := cmd.Flags().GetString("hub-url")
+
+		m, err := modelregistry.FetchHuggingFaceModel(ctx, http.DefaultClient, hubURL, args[0])
+		if err != nil {
+			return err
+		}
+		q, err := m.Quantization(quant)
+		if errors.Is(err, modelregistry.ErrNoGGUF) {
+			return fmt.Errorf("%w; import the GGUF repository of the model instead", err)
+		}
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = importedModelName(m.ID, q.Name)
+		}
+		pullSpec := m.OllamaPullSpec(q)
+
+		db, svc, _, err := openModelRegistryDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := upsertRegistryEntry(ctx, svc, &runtimetypes.ModelRegistryEntry{
+			Name:      name,
+			SourceURL: m.DownloadURL(q),
+			SizeBytes: q.SizeBytes,
+		}); err != nil {
+			return err
+		}
+		record := &runtimetypes.Model{
+			Model:         name,
+			ContextLength: m.ContextLength,
+			CanChat:       m.CanChat,
+			CanEmbed:      m.CanEmbed,
+			CanPrompt:     m.CanPrompt,
+			CanStream:     m.CanStream,
+		}
+		if ollama {
+			record.Model = pullSpec
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if err := upsertModelRecord(ctx, db, newModelService(db, cmd.ErrOrStderr(), force), record); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Imported %s as %q.\n", m.ID, name)
+		fmt.Fprintf(out, "  quantization: %s (%s, %s)\n", q.Name, q.File, sizeMB(q.SizeBytes))
+		if m.ContextLength > 0 {
+			fmt.Fprintf(out, "  context:      %d\n", m.ContextLength)
+		} else {
+			fmt.Fprintf(out, "  context:      unknown; set it with 'contenox model set-context %s --context <n>'\n", record.Model)
+		}
+		fmt.Fprintf(out, "  capabilities: %s\n", modelCapabilities(record))
+		if len(m.Quantizations) > 1 {
+			names := make([]string, len(m.Quantizations))
+			for i, other := range m.Quantizations {
+				names[i] = other.Name
+			}
+			fmt.Fprintf(out, "  available:    %s\n", strings.Join(names, ", "))
+		}
+		fmt.Fprintf(out, "\nDownload for the local backend:\n  contenox model pull %s\n", name)
+		fmt.Fprintf(out, "Or pull with Ollama:\n  ollama pull %s\n", pullSpec)
+		return nil
+	},
+}
+
+// importedModelName derives a registry name from the repository and the
+// quantization, e.g. bartowski/Qwen_Qwen3-4B-GGUF and Q4_K_M become
+// "qwen_qwen3-4b-q4_k_m".
+func importedModelName(id, quant string) string {
+	repo := path.Base(id)
+	for _, suffix := range []string{"-GGUF", "-gguf", "_GGUF", ".GGUF"} {
+		repo = strings.TrimSuffix(repo, suffix)
+	}
+	return strings.ToLower(repo + "-" + quant)
+}
+
+// upsertRegistryEntry creates e or replaces the entry of the same name.
+func upsertRegistryEntry(ctx context.Context, svc modelregistryservice.Service, e *runtimetypes.ModelRegistryEntry) error {
+	existing, err := svc.GetByName(ctx, e.Name)
+	switch {
+	case err == nil:
+		e.ID, e.CreatedAt = existing.ID, existing.CreatedAt
+		if err := svc.Update(ctx, e); err != nil {
+			return fmt.Errorf("failed to update model registry entry: %w", err)
+		}
+	case errors.Is(err, libdb.ErrNotFound):
+		e.ID = uuid.NewString()
+		if err := svc.Create(ctx, e); err != nil {
+			return fmt.Errorf("failed to add model registry entry: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to look up model registry entry: %w", err)
+	}
+	return nil
+}
+
+// upsertModelRecord creates m or updates the record of the same model name.
+func upsertModelRecord(ctx context.Context, db libdb.DBManager, svc modelservice.Service, m *runtimetypes.Model) error {
+	existing, err := runtimetypes.New(db.WithoutTransaction()).GetModelByName(ctx, m.Model)
+	switch {
+	case err == nil:
+		m.ID, m.CreatedAt = existing.ID, existing.CreatedAt
+		if err := svc.Update(ctx, m); err != nil {
+			return fmt.Errorf("failed to update model: %w", err)
+		}
+	case errors.Is(err, libdb.ErrNotFound):
+		m.ID = uuid.NewString()
+		if err := svc.Append(ctx, m); err != nil {
+			return fmt.Errorf("failed to add model: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to look up model: %w", err)
+	}
+	return nil
+}
+
+// modelCapabilities lists what m can do, e.g. "chat, prompt, stream".
+func modelCapabilities(m *runtimetypes.Model) string {
+	var caps []string
+	for _, c := range []struct {
+		ok   bool
+		name string
+	}{{m.CanChat, "chat"}, {m.CanPrompt, "prompt"}, {m.CanStream, "stream"}, {m.CanEmbed, "embed"}} {
+		if c.ok {
+			caps = append(caps, c.name)
+		}
+	}
+	return strings.Join(caps, ", ")
+}
+
+func init() {
+	modelImportCmd.Flags().String("quant", "", "GGUF quantization to use, e.g. Q5_K_M (default: the recommended one)")
+	modelImportCmd.Flags().String("name", "", "Local name of the model (default: derived from the repository and quantization)")
+	modelImportCmd.Flags().Bool("ollama", false, "Name the model record by its Ollama pull spec (hf.co/<owner>/<repo>:<quant>)")
+	modelImportCmd.Flags().Bool("force", false, "Keep a model name no provider catalog lists, warning instead of failing")
+	modelImportCmd.Flags().String("hub-url", modelregistry.HuggingFaceURL, "Hugging Face hub address")
+	_ = modelImportCmd.Flags().MarkHidden("hub-url")
+	modelCmd.AddCommand(modelImportCmd)
+}
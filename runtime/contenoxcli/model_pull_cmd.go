@@ -2,18 +2,24 @@ package contenoxcli
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/contenox/contenox/libtracker"
 	"github.com/contenox/contenox/runtime/modelregistry"
 	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
 )
 
 var modelPullCmd = &cobra.Command{
@@ -94,9 +100,20 @@ After downloading, register a local backend and start using the model:
 			return nil
 		}
 
+		stallTimeout, _ := cmd.Flags().GetDuration("stall-timeout")
+		retries, _ := cmd.Flags().GetInt("retries")
+		digest, _ := cmd.Flags().GetString("sha256")
+		if digest != "" && !isSHA256Hex(digest) {
+			return fmt.Errorf("--sha256 must be a 64-character hex digest")
+		}
+		rateLimit, err := resolvePullRateLimit(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		opts := pullOptions{stallTimeout: stallTimeout, retries: retries, backoff: 5 * time.Second, sha256: digest, rateLimit: rateLimit}
+
 		fmt.Fprintf(cmd.OutOrStdout(), "Downloading %s...\n  → %s\n", name, destPath)
-		if err := downloadGGUF(downloadURL, destPath, cmd.OutOrStdout()); err != nil {
-			_ = os.Remove(destPath)
+		if err := downloadGGUF(ctx, downloadURL, destPath, cmd.OutOrStdout(), opts); err != nil {
 			return fmt.Errorf("download failed: %w", err)
 		}
 
@@ -116,30 +133,206 @@ After downloading, register a local backend and start using the model:
 	},
 }
 
-func downloadGGUF(url, destPath string, out io.Writer) error {
-	resp, err := http.Get(url) //nolint:gosec
+// pullOptions controls how downloadGGUF detects and recovers from stalled
+// downloads and how it verifies the result.
+type pullOptions struct {
+	// stallTimeout fails an attempt that receives no data for this long.
+	stallTimeout time.Duration
+	// retries is the number of further attempts after a failed one.
+	retries int
+	// backoff is the wait before the first retry; it doubles per retry.
+	backoff time.Duration
+	// sha256 is the expected hex digest of the file. When empty, the digest
+	// the server announces (Hugging Face's X-Linked-Etag) is used, if any.
+	sha256 string
+	// rateLimit caps the download speed in bytes per second; zero is unlimited.
+	rateLimit int64
+}
+
+// pullRateLimitKey is the config key of the default bandwidth cap of model
+// pulls; --limit-rate overrides it.
+const pullRateLimitKey = "pull-rate-limit"
+
+// resolvePullRateLimit returns the bandwidth cap in bytes per second from the
+// --limit-rate flag, or the pull-rate-limit config value when the flag is not
+// set. Zero means unlimited.
+func resolvePullRateLimit(ctx context.Context, cmd *cobra.Command) (int64, error) {
+	value, _ := cmd.Flags().GetString("limit-rate")
+	source := "--limit-rate"
+	if !cmd.Flags().Changed("limit-rate") {
+		db, store, err := openConfigDB(cmd)
+		if err != nil {
+			return 0, nil
+		}
+		defer db.Close()
+		value, _ = getConfigKV(ctx, store, pullRateLimitKey)
+		source = pullRateLimitKey
+	}
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := parseByteSize(value)
 	if err != nil {
+		return 0, fmt.Errorf("%s: %w", source, err)
+	}
+	return limit, nil
+}
+
+// throttledReader limits reads from r to the rate of lim.
+type throttledReader struct {
+	ctx context.Context
+	r   io.Reader
+	lim *rate.Limiter
+}
+
+// newThrottledReader returns a reader yielding at most bytesPerSec bytes of r
+// per second, in chunks of up to 32 KiB.
+func newThrottledReader(ctx context.Context, r io.Reader, bytesPerSec int64) *throttledReader {
+	burst := int(min(bytesPerSec, 32*1024))
+	return &throttledReader{ctx: ctx, r: r, lim: rate.NewLimiter(rate.Limit(bytesPerSec), burst)}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > t.lim.Burst() {
+		p = p[:t.lim.Burst()]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if werr := t.lim.WaitN(t.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// errDownloadStalled is the cause of an attempt cancelled by the stall watchdog.
+var errDownloadStalled = errors.New("download stalled")
+
+// errChecksumMismatch fails a download whose SHA-256 digest differs from the
+// expected one.
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// downloadGGUF downloads url to destPath, retrying failed and stalled attempts
+// with exponential backoff. The file is written to destPath+".part" and only
+// renamed into place once complete and verified, so an interrupted pull is
+// never mistaken for a finished one. Retries, and later pulls of the same
+// file, continue from the bytes already in the .part file when the server
+// supports range requests.
+func downloadGGUF(ctx context.Context, url, destPath string, out io.Writer, opts pullOptions) error {
+	partPath := destPath + ".part"
+	backoff := opts.backoff
+	for attempt := 0; ; attempt++ {
+		digest, resumed, err := downloadAttempt(ctx, url, partPath, out, opts.stallTimeout, opts.rateLimit)
+		if err == nil {
+			if opts.sha256 != "" {
+				digest = opts.sha256
+			}
+			if err = verifySHA256(partPath, digest); err == nil {
+				return os.Rename(partPath, destPath)
+			}
+			_ = os.Remove(partPath)
+			if !resumed {
+				// The same bytes would arrive again; only a file assembled
+				// from an earlier partial download is worth fetching anew.
+				return err
+			}
+		}
+		if attempt >= opts.retries || ctx.Err() != nil {
+			if info, statErr := os.Stat(partPath); statErr == nil && info.Size() == 0 {
+				_ = os.Remove(partPath)
+			}
+			return err
+		}
+		fmt.Fprintf(out, "\n  %v; retrying in %s (%d/%d)\n", err, backoff, attempt+1, opts.retries)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// downloadAttempt makes a single download of url to path, continuing after
+// the bytes path already holds when the server answers the range request.
+// It returns the digest the server announces and whether the attempt resumed
+// a partial file. It fails with errDownloadStalled when no data arrives for
+// stallTimeout (zero disables the check) and reads at most rateLimit bytes
+// per second (zero is unlimited).
+func downloadAttempt(ctx context.Context, url, path string, out io.Writer, stallTimeout time.Duration, rateLimit int64) (digest string, resumed bool, err error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var watchdog *time.Timer
+	if stallTimeout > 0 {
+		watchdog = time.AfterFunc(stallTimeout, func() { cancel(errDownloadStalled) })
+		defer watchdog.Stop()
+	}
+
+	var total, written int64
+	fail := func(err error) error {
+		if errors.Is(context.Cause(ctx), errDownloadStalled) {
+			return fmt.Errorf("%w: no data for %s after %d MB of %s", errDownloadStalled, stallTimeout, written/1024/1024, sizeMB(total))
+		}
 		return err
 	}
+
+	var offset int64
+	if info, statErr := os.Stat(path); statErr == nil {
+		offset = info.Size()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec
+	if err != nil {
+		return "", false, fail(err)
+	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %s", resp.Status)
+	digest = announcedSHA256(resp.Header)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		total = resp.ContentLength
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags = os.O_WRONLY | os.O_APPEND
+		resumed = true
+		written = offset
+		total = -1
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+		fmt.Fprintf(out, "  resuming at %d MB\n", offset/1024/1024)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds every byte.
+		return digest, true, nil
+	default:
+		return "", false, fmt.Errorf("HTTP %s", resp.Status)
 	}
 
-	f, err := os.Create(destPath)
+	f, err := os.OpenFile(path, flags, 0o644)
 	if err != nil {
-		return err
+		return "", resumed, err
 	}
 	defer f.Close()
 
-	total := resp.ContentLength
-	var written int64
+	var body io.Reader = resp.Body
+	if rateLimit > 0 {
+		body = newThrottledReader(ctx, body, rateLimit)
+	}
 	buf := make([]byte, 32*1024)
 	for {
-		n, err := resp.Body.Read(buf)
+		n, err := body.Read(buf)
 		if n > 0 {
+			if watchdog != nil {
+				watchdog.Reset(stallTimeout)
+			}
 			if _, werr := f.Write(buf[:n]); werr != nil {
-				return werr
+				return "", resumed, werr
 			}
 			written += int64(n)
 			if total > 0 {
@@ -153,14 +346,68 @@ func downloadGGUF(url, destPath string, out io.Writer) error {
 			break
 		}
 		if err != nil {
-			return err
+			return "", resumed, fail(err)
 		}
 	}
+	if total > 0 && written != total {
+		return "", resumed, fmt.Errorf("download incomplete: got %d of %d bytes", written, total)
+	}
 	fmt.Fprintln(out)
-	return f.Sync()
+	return digest, resumed, f.Sync()
+}
+
+// announcedSHA256 returns the SHA-256 digest Hugging Face sends for LFS files
+// as X-Linked-Etag, or "" when h carries none.
+func announcedSHA256(h http.Header) string {
+	etag := strings.Trim(strings.TrimPrefix(h.Get("X-Linked-Etag"), "W/"), `"`)
+	if !isSHA256Hex(etag) {
+		return ""
+	}
+	return strings.ToLower(etag)
+}
+
+func isSHA256Hex(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// verifySHA256 checks that the file at path has the hex digest want. An empty
+// want skips the check.
+func verifySHA256(path, want string) error {
+	if want == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: expected sha256 %s, got %s", errChecksumMismatch, strings.ToLower(want), got)
+	}
+	return nil
+}
+
+// sizeMB formats a content length for diagnostics.
+func sizeMB(total int64) string {
+	if total <= 0 {
+		return "unknown size"
+	}
+	return fmt.Sprintf("%d MB", total/1024/1024)
 }
 
 func init() {
 	modelPullCmd.Flags().String("url", "", "Direct GGUF download URL (use with a model name as first argument)")
+	modelPullCmd.Flags().Duration("stall-timeout", 2*time.Minute, "Abort and retry the download when no data arrives for this long (0 = never)")
+	modelPullCmd.Flags().Int("retries", 3, "Retry a failed or stalled download this many times, with exponential backoff")
+	modelPullCmd.Flags().String("limit-rate", "", "Cap the download speed in bytes per second, e.g. 10MB (default: config pull-rate-limit, unlimited when unset)")
+	modelPullCmd.Flags().String("sha256", "", "Expected SHA-256 digest of the file (default: the digest Hugging Face announces, if any)")
 	modelCmd.AddCommand(modelPullCmd)
 }
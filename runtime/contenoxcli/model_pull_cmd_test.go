@@ -0,0 +1,138 @@
+package contenoxcli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadGGUF_RetriesStalledDownload(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "8")
+		if requests.Add(1) == 1 {
+			// First attempt: send half the file, then hang.
+			_, _ = w.Write([]byte("GGUF"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("GGUFdata"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	opts := pullOptions{stallTimeout: 100 * time.Millisecond, retries: 2, backoff: time.Millisecond}
+	require.NoError(t, downloadGGUF(context.Background(), srv.URL, dest, io.Discard, opts))
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	require.Equal(t, "GGUFdata", string(data))
+	require.EqualValues(t, 2, requests.Load())
+	_, err = os.Stat(dest + ".part")
+	require.True(t, os.IsNotExist(err), "partial file must be removed")
+}
+
+func TestDownloadGGUF_GivesUpAfterRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	opts := pullOptions{stallTimeout: 50 * time.Millisecond, retries: 1, backoff: time.Millisecond}
+	err := downloadGGUF(context.Background(), srv.URL, dest, io.Discard, opts)
+	require.True(t, errors.Is(err, errDownloadStalled), "got %v", err)
+
+	for _, path := range []string{dest, dest + ".part"} {
+		_, statErr := os.Stat(path)
+		require.True(t, os.IsNotExist(statErr), "%s must not exist", path)
+	}
+}
+
+func TestDownloadGGUF_ResumesPartialDownload(t *testing.T) {
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("X-Linked-Etag", `"`+sha256Hex("GGUFdata")+`"`)
+		if r.Header.Get("Range") == "bytes=4-" {
+			w.Header().Set("Content-Length", "4")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("data"))
+			return
+		}
+		w.Header().Set("Content-Length", "8")
+		_, _ = w.Write([]byte("GGUF"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	opts := pullOptions{stallTimeout: 100 * time.Millisecond, retries: 2, backoff: time.Millisecond}
+	require.NoError(t, downloadGGUF(context.Background(), srv.URL, dest, io.Discard, opts))
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	require.Equal(t, "GGUFdata", string(data))
+	require.Equal(t, []string{"", "bytes=4-"}, ranges)
+}
+
+func TestDownloadGGUF_ChecksumMismatch(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("GGUFdata"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	opts := pullOptions{retries: 3, backoff: time.Millisecond, sha256: sha256Hex("other")}
+	err := downloadGGUF(context.Background(), srv.URL, dest, io.Discard, opts)
+	require.True(t, errors.Is(err, errChecksumMismatch), "got %v", err)
+	require.EqualValues(t, 1, requests.Load(), "a fresh download with the wrong digest is not retried")
+	for _, path := range []string{dest, dest + ".part"} {
+		_, statErr := os.Stat(path)
+		require.True(t, os.IsNotExist(statErr), "%s must not exist", path)
+	}
+
+	opts.sha256 = sha256Hex("GGUFdata")
+	require.NoError(t, downloadGGUF(context.Background(), srv.URL, dest, io.Discard, opts))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadGGUF_RateLimit(t *testing.T) {
+	payload := make([]byte, 82*1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(payload)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	// The first 32 KiB pass as a burst; the remaining 50 KiB take 0.5 s.
+	opts := pullOptions{rateLimit: 100 * 1024}
+	start := time.Now()
+	require.NoError(t, downloadGGUF(context.Background(), srv.URL, dest, io.Discard, opts))
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	info, err := os.Stat(dest)
+	require.NoError(t, err)
+	require.EqualValues(t, len(payload), info.Size())
+}
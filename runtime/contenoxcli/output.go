@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/contenox/contenox/runtime/taskengine"
@@ -52,15 +53,74 @@ func printRelevantOutput(w io.Writer, output any, outputType taskengine.DataType
 			fmt.Fprintln(w, s)
 			return
 		}
+	case taskengine.DataTypeFile:
+		// Binary content is only written with --raw, e.g. to redirect it into a file.
+		if f, ok := output.(taskengine.File); ok {
+			fmt.Fprintln(w, f)
+			return
+		}
 	}
 	printOutput(w, output)
 }
 
+// replyStreamer prints model responses to w while they are generated
+// (--stream). Each task's response starts on its own line.
+type replyStreamer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	taskID string
+	// last is the text streamed by the task that streamed last.
+	last strings.Builder
+}
+
+func (s *replyStreamer) write(chunk taskengine.TokenChunk) {
+	if chunk.Content == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if chunk.TaskID != s.taskID {
+		if s.last.Len() > 0 {
+			fmt.Fprintln(s.w)
+		}
+		s.taskID = chunk.TaskID
+		s.last.Reset()
+	}
+	fmt.Fprint(s.w, chunk.Content)
+	s.last.WriteString(chunk.Content)
+}
+
+// finish ends the streamed text and prints the result like
+// printRelevantOutput, unless the relevant part was the text streamed last.
+func (s *replyStreamer) finish(output any, outputType taskengine.DataType, raw bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	streamed := strings.TrimSpace(s.last.String())
+	if s.last.Len() > 0 {
+		fmt.Fprintln(s.w)
+	}
+	if !raw && streamed != "" {
+		var reply string
+		switch v := output.(type) {
+		case taskengine.ChatHistory:
+			reply = lastAssistantContentFromHistory(v)
+		case string:
+			reply = v
+		}
+		if strings.TrimSpace(reply) == streamed {
+			return
+		}
+	}
+	printRelevantOutput(s.w, output, outputType, raw)
+}
+
 // printOutput prints output in a human-friendly way.
 func printOutput(w io.Writer, output any) {
 	switch v := output.(type) {
 	case string:
 		fmt.Fprintln(w, v)
+	case taskengine.File:
+		_, _ = w.Write(v.Data)
 	case []byte:
 		fmt.Fprintln(w, string(v))
 	default:
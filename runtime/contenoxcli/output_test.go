@@ -1,6 +1,7 @@
 package contenoxcli
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -103,3 +104,26 @@ func Test_lastAssistantContentFromHistory_returns_last_non_empty_assistant(t *te
 	}
 	require.Equal(t, "b", lastAssistantContentFromHistory(chat))
 }
+
+func Test_replyStreamer(t *testing.T) {
+	var buf bytes.Buffer
+	s := &replyStreamer{w: &buf}
+	s.write(taskengine.TokenChunk{TaskID: "draft", Content: "first "})
+	s.write(taskengine.TokenChunk{TaskID: "draft", Content: "draft"})
+	s.write(taskengine.TokenChunk{TaskID: "answer", Content: "Hello"})
+	s.write(taskengine.TokenChunk{TaskID: "answer", Thinking: "hmm"})
+	s.write(taskengine.TokenChunk{TaskID: "answer", Content: " world"})
+	s.finish(taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "assistant", Content: "Hello world"}}}, taskengine.DataTypeChatHistory, false)
+	assert.Equal(t, "first draft\nHello world\n", buf.String(), "a streamed reply is not printed again")
+
+	buf.Reset()
+	s = &replyStreamer{w: &buf}
+	s.write(taskengine.TokenChunk{TaskID: "classify", Content: "positive"})
+	s.finish("routed to positive", taskengine.DataTypeString, false)
+	assert.Equal(t, "positive\nrouted to positive\n", buf.String())
+
+	buf.Reset()
+	s = &replyStreamer{w: &buf}
+	s.finish("not streamed", taskengine.DataTypeString, false)
+	assert.Equal(t, "not streamed\n", buf.String())
+}
@@ -228,6 +228,7 @@ func init() {
 	planNextCmd.Flags().Bool("shell", false, "Enable the local_shell tools for this plan step (required for shell-based tasks)")
 	planNextCmd.Flags().Bool("gate", false, "Use chain-step-executor-gated.json: after each tool round, a small model scores whether to continue (extra latency/cost; aborts bad/corrupt tool output)")
 	planNextCmd.Flags().Bool("hitl", false, "Pause before each write/shell tool call and require y/n approval in the terminal (human-in-the-loop)")
+	planNextCmd.Flags().Bool("confirm-tools", false, "Ask y/n before every local_shell, ssh, python_sandbox and file write call the model makes; 'a' always allows the command pattern (default: confirm-tools config)")
 	planNewCmd.Flags().Bool("explore", false, "Also run 'plan explore' on the new plan to seed it with a RepoContext")
 }
 
@@ -307,6 +308,7 @@ func buildPlanOpts(cmd *cobra.Command, db libdbexec.DBManager, input string) cha
 	}
 
 	effectiveLocalExecAllowedDir, _ := flags.GetString("local-exec-allowed-dir")
+	effectiveEnablePython, _ := flags.GetBool("python")
 
 	return chatOpts{
 		InputFlagPassed:              true,
@@ -316,8 +318,10 @@ func buildPlanOpts(cmd *cobra.Command, db libdbexec.DBManager, input string) cha
 		EffectiveContext:             effectiveContext,
 		EffectiveEnableLocalExec:     effectiveEnableLocalExec,
 		EffectiveLocalExecAllowedDir: effectiveLocalExecAllowedDir,
+		EffectiveEnablePython:        effectiveEnablePython,
 		EffectiveTracing:             effectiveTracing,
 		EffectiveHITL:                effectiveHITL,
+		EffectiveConfirmTools:        resolveConfirmTools(ctx, store, cmd),
 	}
 }
 
@@ -399,7 +403,7 @@ func runPlanNew(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read planner chain: %w", err)
 	}
 	var plannerChain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(chainData, &plannerChain); err != nil {
+	if err := unmarshalChain(plannerPath, chainData, &plannerChain); err != nil {
 		return fmt.Errorf("failed to parse planner chain: %w", err)
 	}
 	if err := validatePlannerChain(&plannerChain, plannerPath); err != nil {
@@ -457,7 +461,7 @@ func runExplorerOnPlan(
 		return fmt.Errorf("failed to read explorer chain: %w", err)
 	}
 	var explorerChain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(chainData, &explorerChain); err != nil {
+	if err := unmarshalChain(explorerPath, chainData, &explorerChain); err != nil {
 		return fmt.Errorf("failed to parse explorer chain: %w", err)
 	}
 	if err := validatePlanExplorerChain(&explorerChain, explorerPath); err != nil {
@@ -586,11 +590,31 @@ func runPlanShow(cmd *cobra.Command, _ []string) error {
 		default:
 			checkbox = "[ ]"
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "%d. %s %s\n", s.Ordinal, checkbox, s.Description)
+		timing := ""
+		switch {
+		case s.Duration() > 0:
+			timing = fmt.Sprintf(" (%s)", s.Duration().Round(time.Second))
+		case s.Status == planstore.StepStatusRunning && !s.StartedAt.IsZero():
+			timing = fmt.Sprintf(" (running for %s)", time.Since(s.StartedAt).Round(time.Second))
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d. %s %s%s\n", s.Ordinal, checkbox, s.Description, timing)
+	}
+
+	history, err := store.ListStepDurations(ctx, planETAHistory)
+	if err != nil {
+		return err
+	}
+	if est := planstore.EstimateRemaining(steps, history, time.Now()); est.Steps > 0 && est.Basis > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Estimated remaining: ~%s for %d step(s), based on %d completed step(s)\n",
+			est.Remaining.Round(time.Second), est.Steps, est.Basis)
 	}
 	return nil
 }
 
+// planETAHistory is how many recently completed steps 'plan show' bases its
+// remaining time estimate on.
+const planETAHistory = 200
+
 func runPlanNext(cmd *cobra.Command, _ []string) error {
 	ctx, db, cDir, cleanup, err := openPlanDB(cmd)
 	if err != nil {
@@ -631,7 +655,7 @@ func runPlanNext(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 	var chain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(chainData, &chain); err != nil {
+	if err := unmarshalChain(executorPath, chainData, &chain); err != nil {
 		return err
 	}
 	if err := validateExecutorChain(&chain, executorPath); err != nil {
@@ -642,7 +666,7 @@ func runPlanNext(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 	var sumChain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(sumData, &sumChain); err != nil {
+	if err := unmarshalChain(summarizerPath, sumData, &sumChain); err != nil {
 		return err
 	}
 	if err := validateSummarizerChain(&sumChain, summarizerPath); err != nil {
@@ -843,7 +867,7 @@ func runPlanReplan(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 	var plannerChain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(chainData, &plannerChain); err != nil {
+	if err := unmarshalChain(plannerPath, chainData, &plannerChain); err != nil {
 		return err
 	}
 
@@ -242,7 +242,8 @@ func validatePlanExplorerChain(chain *taskengine.TaskChainDefinition, path strin
 	if err := validateExecutorChain(chain, path); err != nil {
 		return err
 	}
-	for _, task := range chain.Tasks {
+	// Check the effective allowlists, including tools inherited from default_execute_config.
+	for _, task := range taskengine.ResolveChainDefaults(chain).Tasks {
 		if task.Handler != taskengine.HandleChatCompletion || task.ExecuteConfig == nil {
 			continue
 		}
@@ -0,0 +1,85 @@
+// pricing.go — provider pricing table used to estimate the cost of chain steps.
+package contenoxcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"gopkg.in/yaml.v3"
+)
+
+// pricingFileName is the pricing table looked up in the .contenox directory.
+// It maps model names, optionally qualified with the provider type, to USD
+// per million input and output tokens. YAML and JSON are both accepted:
+//
+//	gpt-4o-mini: {input: 0.15, output: 0.6}
+//	openai/gpt-4o: {input: 2.5, output: 10}
+const pricingFileName = "pricing.yaml"
+
+// readPricing reads the pricing table of contenoxDir. A missing file means
+// no pricing.
+func readPricing(contenoxDir string) (taskengine.Pricing, error) {
+	if contenoxDir == "" {
+		return nil, nil
+	}
+	path := filepath.Join(contenoxDir, pricingFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read pricing file: %w", err)
+	}
+	var pricing taskengine.Pricing
+	if err := yaml.Unmarshal(data, &pricing); err != nil {
+		return nil, fmt.Errorf("parse pricing file %q: %w", path, err)
+	}
+	if err := pricing.Validate(); err != nil {
+		return nil, fmt.Errorf("pricing file %q: %w", path, err)
+	}
+	return pricing, nil
+}
+
+// printStepUsage prints the token usage and cost total of a run after its
+// --steps list.
+func printStepUsage(w io.Writer, steps []taskengine.CapturedStateUnit) {
+	usage := taskengine.ChainCost(steps)
+	if usage.InputTokens+usage.OutputTokens == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  Total: %d input + %d output tokens", usage.InputTokens, usage.OutputTokens)
+	if slices.ContainsFunc(steps, func(s taskengine.CapturedStateUnit) bool { return s.Priced }) {
+		fmt.Fprintf(w, ", %s", formatCost(usage.Cost))
+		if len(usage.Unpriced) > 0 {
+			fmt.Fprintf(w, " (no price for %d steps)", len(usage.Unpriced))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// stepUsage formats the tokens and cost of a step for the --steps list, or
+// returns "" for steps without LLM calls.
+func stepUsage(step taskengine.CapturedStateUnit) string {
+	if step.InputTokens+step.OutputTokens == 0 {
+		return ""
+	}
+	s := fmt.Sprintf(" %d→%d tok", step.InputTokens, step.OutputTokens)
+	if step.Priced {
+		s += " " + formatCost(step.Cost)
+	}
+	return s
+}
+
+// formatCost formats a USD amount with enough precision for single calls.
+func formatCost(cost float64) string {
+	if cost != 0 && cost < 0.01 {
+		return fmt.Sprintf("$%.4f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -0,0 +1,56 @@
+package contenoxcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// promptCacheKVPrefix namespaces cached prompt responses in the KV table.
+const promptCacheKVPrefix = "prompt-cache:"
+
+// kvPromptCache stores prompt responses in the local KV table so they survive
+// between 'contenox run --cache-ttl' invocations.
+type kvPromptCache struct {
+	db libdb.DBManager
+}
+
+type kvPromptCacheEntry struct {
+	Response  string    `json:"response"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func newKVPromptCache(db libdb.DBManager) taskengine.PromptCache {
+	return &kvPromptCache{db: db}
+}
+
+func (c *kvPromptCache) Get(ctx context.Context, key string) (string, bool, error) {
+	store := runtimetypes.New(c.db.WithoutTransaction())
+	var entry kvPromptCacheEntry
+	err := store.GetKV(ctx, promptCacheKVPrefix+key, &entry)
+	if errors.Is(err, libdb.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if !time.Now().Before(entry.ExpiresAt) {
+		_ = store.DeleteKV(ctx, promptCacheKVPrefix+key)
+		return "", false, nil
+	}
+	return entry.Response, true, nil
+}
+
+func (c *kvPromptCache) Set(ctx context.Context, key, response string, ttl time.Duration) error {
+	data, err := json.Marshal(kvPromptCacheEntry{Response: response, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return fmt.Errorf("encode prompt cache entry: %w", err)
+	}
+	return runtimetypes.New(c.db.WithoutTransaction()).SetKV(ctx, promptCacheKVPrefix+key, data)
+}
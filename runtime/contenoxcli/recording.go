@@ -0,0 +1,455 @@
+// recording.go — record-and-replay of chain runs ('contenox run --record', 'contenox replay').
+package contenoxcli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/internal/ollamatokenizer"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// recordingVersion is bumped when the recording format changes incompatibly.
+const recordingVersion = 1
+
+// Kinds of recorded calls.
+const (
+	callPrompt     = "prompt"
+	callChat       = "chat"
+	callStream     = "stream"
+	callEmbed      = "embed"
+	callEmbedBatch = "embed_batch"
+	callTool       = "tool"
+)
+
+// runRecording is the portable file written by 'contenox run --record'. It
+// holds everything needed to re-execute the run without backends or tools.
+type runRecording struct {
+	Version    int                             `json:"version"`
+	CLIVersion string                          `json:"cliVersion"`
+	RecordedAt time.Time                       `json:"recordedAt"`
+	Chain      *taskengine.TaskChainDefinition `json:"chain"`
+	Input      string                          `json:"input"`
+	InputType  string                          `json:"inputType"`
+	Model      string                          `json:"model"`
+	Provider   string                          `json:"provider,omitempty"`
+	Calls      []*recordedCall                 `json:"calls"`
+	Tasks      *taskengine.ReplayBundle        `json:"tasks,omitempty"`
+	Output     any                             `json:"output,omitempty"`
+	OutputType string                          `json:"outputType,omitempty"`
+	Error      string                          `json:"error,omitempty"`
+	DurationMS int64                           `json:"durationMs"`
+}
+
+// recordedCall is one model or tool call of a recorded run. Key identifies
+// the request so replay can match calls that happen in a different order.
+type recordedCall struct {
+	Kind       string          `json:"kind"`
+	Key        string          `json:"key"`
+	Request    json.RawMessage `json:"request"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMS int64           `json:"durationMs"`
+}
+
+func readRecording(path string) (*runRecording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read recording: %w", err)
+	}
+	var rec runRecording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parse recording %q: %w", path, err)
+	}
+	if rec.Version != recordingVersion {
+		return nil, fmt.Errorf("recording %q has version %d, this CLI reads version %d", path, rec.Version, recordingVersion)
+	}
+	if rec.Chain == nil {
+		return nil, fmt.Errorf("recording %q has no chain definition", path)
+	}
+	return &rec, nil
+}
+
+func writeRecording(path string, rec *runRecording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode recording: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func callKey(kind string, request []byte) string {
+	sum := sha256.Sum256(append([]byte(kind+"\x00"), request...))
+	return hex.EncodeToString(sum[:])
+}
+
+// callRecorder collects the calls of a run. Safe for concurrent use.
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []*recordedCall
+}
+
+func (r *callRecorder) record(kind string, request, response any, callErr error, start time.Time) {
+	req, _ := json.Marshal(request)
+	call := &recordedCall{
+		Kind:       kind,
+		Key:        callKey(kind, req),
+		Request:    req,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if callErr != nil {
+		call.Error = callErr.Error()
+	} else {
+		call.Response, _ = json.Marshal(response)
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+}
+
+func (r *callRecorder) recorded() []*recordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*recordedCall(nil), r.calls...)
+}
+
+// callReplayer serves recorded responses. A call is answered by the first
+// unused recording with the same request; when the request changed (e.g. a
+// template renders the current time) the first unused call of the same kind
+// is used instead.
+type callReplayer struct {
+	mu    sync.Mutex
+	calls []*recordedCall
+	used  []bool
+}
+
+func newCallReplayer(calls []*recordedCall) *callReplayer {
+	return &callReplayer{calls: calls, used: make([]bool, len(calls))}
+}
+
+func (r *callReplayer) next(kind string, request, response any) error {
+	req, _ := json.Marshal(request)
+	key := callKey(kind, req)
+	r.mu.Lock()
+	idx := -1
+	for i, c := range r.calls {
+		if !r.used[i] && c.Key == key {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		for i, c := range r.calls {
+			if !r.used[i] && c.Kind == kind {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx >= 0 {
+		r.used[idx] = true
+	}
+	r.mu.Unlock()
+	if idx < 0 {
+		return fmt.Errorf("replay: no recorded %s call left for request %s", kind, truncateForError(string(req)))
+	}
+	call := r.calls[idx]
+	if call.Error != "" {
+		return errors.New(call.Error)
+	}
+	if err := json.Unmarshal(call.Response, response); err != nil {
+		return fmt.Errorf("replay: decode recorded %s response: %w", kind, err)
+	}
+	return nil
+}
+
+// unused returns the number of recorded calls the replay did not request.
+func (r *callReplayer) unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, u := range r.used {
+		if !u {
+			n++
+		}
+	}
+	return n
+}
+
+func truncateForError(s string) string {
+	const max = 200
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "…"
+}
+
+type promptCallRequest struct {
+	Models            []string `json:"models,omitempty"`
+	Providers         []string `json:"providers,omitempty"`
+	SystemInstruction string   `json:"systemInstruction,omitempty"`
+	Temperature       float32  `json:"temperature"`
+	Prompt            string   `json:"prompt"`
+}
+
+type promptCallResponse struct {
+	Response string       `json:"response"`
+	Meta     llmrepo.Meta `json:"meta"`
+}
+
+type chatCallRequest struct {
+	Models    []string                   `json:"models,omitempty"`
+	Providers []string                   `json:"providers,omitempty"`
+	Messages  []libmodelprovider.Message `json:"messages"`
+}
+
+type chatCallResponse struct {
+	Result libmodelprovider.ChatResult `json:"result"`
+	Meta   llmrepo.Meta                `json:"meta"`
+}
+
+type streamCallResponse struct {
+	Data     string       `json:"data"`
+	Thinking string       `json:"thinking,omitempty"`
+	Meta     llmrepo.Meta `json:"meta"`
+}
+
+type embedCallRequest struct {
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Prompt   string `json:"prompt"`
+}
+
+type embedCallResponse struct {
+	Embedding []float64    `json:"embedding"`
+	Meta      llmrepo.Meta `json:"meta"`
+}
+
+type embedBatchCallRequest struct {
+	Model    string   `json:"model,omitempty"`
+	Provider string   `json:"provider,omitempty"`
+	Prompts  []string `json:"prompts"`
+}
+
+type embedBatchCallResponse struct {
+	Embeddings [][]float64  `json:"embeddings"`
+	Meta       llmrepo.Meta `json:"meta"`
+}
+
+type toolCallRequest struct {
+	Name     string            `json:"name"`
+	ToolName string            `json:"toolName,omitempty"`
+	Args     map[string]string `json:"args,omitempty"`
+	Input    any               `json:"input"`
+}
+
+type toolCallResponse struct {
+	Output   json.RawMessage `json:"output"`
+	DataType string          `json:"dataType"`
+}
+
+// recordingModelRepo records every model call of the wrapped repo.
+type recordingModelRepo struct {
+	llmrepo.ModelRepo
+	rec *callRecorder
+}
+
+func (m *recordingModelRepo) PromptExecute(ctx context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string, opts ...libmodelprovider.ChatArgument) (string, llmrepo.Meta, error) {
+	start := time.Now()
+	resp, meta, err := m.ModelRepo.PromptExecute(ctx, req, systeminstruction, temperature, prompt, opts...)
+	m.rec.record(callPrompt, promptCallRequest{req.ModelNames, req.ProviderTypes, systeminstruction, temperature, prompt}, promptCallResponse{resp, meta}, err, start)
+	return resp, meta, err
+}
+
+func (m *recordingModelRepo) Chat(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	start := time.Now()
+	res, meta, err := m.ModelRepo.Chat(ctx, req, messages, opts...)
+	m.rec.record(callChat, chatCallRequest{req.ModelNames, req.ProviderTypes, messages}, chatCallResponse{res, meta}, err, start)
+	return res, meta, err
+}
+
+func (m *recordingModelRepo) Embed(ctx context.Context, embedReq llmrepo.EmbedRequest, prompt string) ([]float64, llmrepo.Meta, error) {
+	start := time.Now()
+	vec, meta, err := m.ModelRepo.Embed(ctx, embedReq, prompt)
+	m.rec.record(callEmbed, embedCallRequest{embedReq.ModelName, embedReq.ProviderType, prompt}, embedCallResponse{vec, meta}, err, start)
+	return vec, meta, err
+}
+
+func (m *recordingModelRepo) EmbedBatch(ctx context.Context, embedReq llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	start := time.Now()
+	vecs, meta, err := m.ModelRepo.EmbedBatch(ctx, embedReq, prompts)
+	m.rec.record(callEmbedBatch, embedBatchCallRequest{embedReq.ModelName, embedReq.ProviderType, prompts}, embedBatchCallResponse{vecs, meta}, err, start)
+	return vecs, meta, err
+}
+
+// Stream forwards the parcels and records the concatenated stream once it ends.
+func (m *recordingModelRepo) Stream(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
+	start := time.Now()
+	request := chatCallRequest{req.ModelNames, req.ProviderTypes, messages}
+	stream, meta, err := m.ModelRepo.Stream(ctx, req, messages, opts...)
+	if err != nil {
+		m.rec.record(callStream, request, nil, err, start)
+		return nil, meta, err
+	}
+	out := make(chan *libmodelprovider.StreamParcel)
+	go func() {
+		defer close(out)
+		var data, thinking strings.Builder
+		var streamErr error
+		for parcel := range stream {
+			if parcel.Error != nil {
+				streamErr = parcel.Error
+			}
+			data.WriteString(parcel.Data)
+			thinking.WriteString(parcel.Thinking)
+			select {
+			case out <- parcel:
+			case <-ctx.Done():
+			}
+		}
+		m.rec.record(callStream, request, streamCallResponse{data.String(), thinking.String(), meta}, streamErr, start)
+	}()
+	return out, meta, nil
+}
+
+// replayModelRepo answers model calls from a recording. Tokens are counted
+// with the estimate tokenizer so no backend is needed.
+type replayModelRepo struct {
+	replay    *callReplayer
+	tokenizer ollamatokenizer.Tokenizer
+}
+
+var _ llmrepo.ModelRepo = (*replayModelRepo)(nil)
+
+func (m *replayModelRepo) Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error) {
+	return m.tokenizer.Tokenize(ctx, modelName, prompt)
+}
+
+func (m *replayModelRepo) CountTokens(ctx context.Context, modelName string, prompt string) (int, error) {
+	return m.tokenizer.CountTokens(ctx, modelName, prompt)
+}
+
+func (m *replayModelRepo) PromptExecute(_ context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string, _ ...libmodelprovider.ChatArgument) (string, llmrepo.Meta, error) {
+	var resp promptCallResponse
+	err := m.replay.next(callPrompt, promptCallRequest{req.ModelNames, req.ProviderTypes, systeminstruction, temperature, prompt}, &resp)
+	return resp.Response, resp.Meta, err
+}
+
+func (m *replayModelRepo) Chat(_ context.Context, req llmrepo.Request, messages []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	var resp chatCallResponse
+	err := m.replay.next(callChat, chatCallRequest{req.ModelNames, req.ProviderTypes, messages}, &resp)
+	return resp.Result, resp.Meta, err
+}
+
+func (m *replayModelRepo) Embed(_ context.Context, embedReq llmrepo.EmbedRequest, prompt string) ([]float64, llmrepo.Meta, error) {
+	var resp embedCallResponse
+	err := m.replay.next(callEmbed, embedCallRequest{embedReq.ModelName, embedReq.ProviderType, prompt}, &resp)
+	return resp.Embedding, resp.Meta, err
+}
+
+func (m *replayModelRepo) EmbedBatch(_ context.Context, embedReq llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	var resp embedBatchCallResponse
+	err := m.replay.next(callEmbedBatch, embedBatchCallRequest{embedReq.ModelName, embedReq.ProviderType, prompts}, &resp)
+	return resp.Embeddings, resp.Meta, err
+}
+
+// Stream replays a recorded stream as a single parcel.
+func (m *replayModelRepo) Stream(_ context.Context, req llmrepo.Request, messages []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
+	var resp streamCallResponse
+	if err := m.replay.next(callStream, chatCallRequest{req.ModelNames, req.ProviderTypes, messages}, &resp); err != nil {
+		return nil, llmrepo.Meta{}, err
+	}
+	out := make(chan *libmodelprovider.StreamParcel, 1)
+	out <- &libmodelprovider.StreamParcel{Data: resp.Data, Thinking: resp.Thinking}
+	close(out)
+	return out, resp.Meta, nil
+}
+
+// recordingTools records the result of every tool call of the wrapped repo.
+type recordingTools struct {
+	taskengine.ToolsRepo
+	rec *callRecorder
+}
+
+func (t *recordingTools) Exec(ctx context.Context, startingTime time.Time, input any, debug bool, args *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	start := time.Now()
+	out, dt, err := t.ToolsRepo.Exec(ctx, startingTime, input, debug, args)
+	resp := toolCallResponse{DataType: dt.String()}
+	if err == nil {
+		resp.Output, err = json.Marshal(out)
+		if err != nil {
+			err = fmt.Errorf("record tool output: %w", err)
+		}
+	}
+	t.rec.record(callTool, toolRequest(input, args), resp, err, start)
+	return out, dt, err
+}
+
+// replayTools answers tool calls from a recording; the wrapped repo only
+// provides tool names and schemas.
+type replayTools struct {
+	taskengine.ToolsRepo
+	replay *callReplayer
+}
+
+func (t *replayTools) Exec(_ context.Context, _ time.Time, input any, _ bool, args *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	var resp toolCallResponse
+	if err := t.replay.next(callTool, toolRequest(input, args), &resp); err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+	dt, err := taskengine.DataTypeFromString(resp.DataType)
+	if err != nil {
+		return nil, taskengine.DataTypeAny, fmt.Errorf("replay: %w", err)
+	}
+	out, err := decodeRecordedValue(resp.Output, dt)
+	return out, dt, err
+}
+
+func toolRequest(input any, args *taskengine.ToolsCall) toolCallRequest {
+	req := toolCallRequest{Input: input}
+	if args != nil {
+		req.Name, req.ToolName, req.Args = args.Name, args.ToolName, args.Args
+	}
+	return req
+}
+
+// decodeRecordedValue restores the Go type the engine expects for dt.
+func decodeRecordedValue(raw json.RawMessage, dt taskengine.DataType) (any, error) {
+	var err error
+	switch dt {
+	case taskengine.DataTypeString:
+		var s string
+		err = json.Unmarshal(raw, &s)
+		return s, err
+	case taskengine.DataTypeInt:
+		var n int
+		err = json.Unmarshal(raw, &n)
+		return n, err
+	case taskengine.DataTypeChatHistory:
+		var h taskengine.ChatHistory
+		err = json.Unmarshal(raw, &h)
+		return h, err
+	case taskengine.DataTypeVector:
+		var v []float64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case taskengine.DataTypeFile:
+		var f taskengine.File
+		err = json.Unmarshal(raw, &f)
+		return f, err
+	default:
+		var v any
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	}
+}
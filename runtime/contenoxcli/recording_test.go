@@ -0,0 +1,114 @@
+package contenoxcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/internal/ollamatokenizer"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoModelRepo struct {
+	llmrepo.ModelRepo
+}
+
+func (echoModelRepo) PromptExecute(_ context.Context, _ llmrepo.Request, _ string, _ float32, prompt string, _ ...libmodelprovider.ChatArgument) (string, llmrepo.Meta, error) {
+	if prompt == "fail" {
+		return "", llmrepo.Meta{}, errors.New("backend unavailable")
+	}
+	return "re: " + prompt, llmrepo.Meta{ModelName: "m", BackendID: "b1"}, nil
+}
+
+func (echoModelRepo) Chat(_ context.Context, _ llmrepo.Request, messages []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	res := libmodelprovider.ChatResult{Message: libmodelprovider.Message{Role: "assistant", Content: "hi " + messages[len(messages)-1].Content}}
+	return res, llmrepo.Meta{ModelName: "m"}, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	rec := &callRecorder{}
+	repo := &recordingModelRepo{ModelRepo: echoModelRepo{}, rec: rec}
+	toolsRepo := tools.NewMockToolsRegistry()
+	toolsRepo.DefaultResponse.Output = "42"
+	recTools := &recordingTools{ToolsRepo: toolsRepo, rec: rec}
+
+	req := llmrepo.Request{ModelNames: []string{"m"}}
+	_, _, err := repo.PromptExecute(ctx, req, "sys", 0, "first")
+	require.NoError(t, err)
+	_, _, err = repo.PromptExecute(ctx, req, "sys", 0, "second")
+	require.NoError(t, err)
+	_, _, err = repo.PromptExecute(ctx, req, "sys", 0, "fail")
+	require.Error(t, err)
+	_, _, err = repo.Chat(ctx, req, []libmodelprovider.Message{{Role: "user", Content: "there"}})
+	require.NoError(t, err)
+	_, _, err = recTools.Exec(ctx, time.Now(), "in", false, &taskengine.ToolsCall{Name: "calc", ToolName: "add"})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	require.NoError(t, writeRecording(path, &runRecording{
+		Version: recordingVersion,
+		Chain:   &taskengine.TaskChainDefinition{ID: "c"},
+		Calls:   rec.recorded(),
+	}))
+	loaded, err := readRecording(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Calls, 5)
+
+	replay := newCallReplayer(loaded.Calls)
+	replayRepo := &replayModelRepo{replay: replay, tokenizer: ollamatokenizer.NewEstimateTokenizer()}
+	replayToolsRepo := &replayTools{ToolsRepo: tools.NewMockToolsRegistry(), replay: replay}
+
+	// Matching is by request, so order does not matter.
+	got, meta, err := replayRepo.PromptExecute(ctx, req, "sys", 0, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "re: second", got)
+	assert.Equal(t, "b1", meta.BackendID)
+	_, _, err = replayRepo.PromptExecute(ctx, req, "sys", 0, "fail")
+	assert.EqualError(t, err, "backend unavailable")
+
+	// A changed request falls back to the next unused call of its kind.
+	got, _, err = replayRepo.PromptExecute(ctx, req, "sys", 0, "first, edited")
+	require.NoError(t, err)
+	assert.Equal(t, "re: first", got)
+
+	chat, _, err := replayRepo.Chat(ctx, req, []libmodelprovider.Message{{Role: "user", Content: "there"}})
+	require.NoError(t, err)
+	assert.Equal(t, "hi there", chat.Message.Content)
+
+	out, dt, err := replayToolsRepo.Exec(ctx, time.Now(), "in", false, &taskengine.ToolsCall{Name: "calc", ToolName: "add"})
+	require.NoError(t, err)
+	assert.Equal(t, "42", out)
+	assert.Equal(t, taskengine.DataTypeAny, dt)
+	assert.Empty(t, toolsRepo.Calls[1:], "replay must not execute tools")
+
+	assert.Equal(t, 0, replay.unused())
+	_, _, err = replayRepo.PromptExecute(ctx, req, "sys", 0, "third")
+	assert.ErrorContains(t, err, "no recorded prompt call left")
+}
+
+func TestCompareReplay(t *testing.T) {
+	history := func(content string, at time.Time) taskengine.ChatHistory {
+		return taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "assistant", Content: content, Timestamp: at}}}
+	}
+	data, err := json.Marshal(history("done", time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+	recorded := &runRecording{}
+	require.NoError(t, json.Unmarshal(data, &recorded.Output))
+
+	replayed := history("done", time.Now())
+	assert.Equal(t, "Replay output matches the recording.", compareReplay(recorded, replayed, nil))
+
+	replayed.Messages[0].Content = "other"
+	assert.Contains(t, compareReplay(recorded, replayed, nil), "differs from the recording")
+
+	assert.Equal(t, "Replay reproduced the recorded error.", compareReplay(&runRecording{Error: "boom"}, nil, errors.New("boom")))
+}
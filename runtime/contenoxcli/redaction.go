@@ -0,0 +1,57 @@
+// redaction.go — secret masking of recorded step inputs, outputs and errors.
+package contenoxcli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"gopkg.in/yaml.v3"
+)
+
+// redactionFileName holds the redaction rules looked up in the .contenox
+// directory. Patterns are Go regular expressions masked in addition to the
+// built-in credential patterns, which defaults: false turns off:
+//
+//	patterns:
+//	  - 'corp-[0-9a-f]{32}'
+//	defaults: true
+//	replacement: '***'
+const redactionFileName = "redact.yaml"
+
+type redactionFile struct {
+	Patterns    []string `yaml:"patterns"`
+	Defaults    *bool    `yaml:"defaults"`
+	Replacement *string  `yaml:"replacement"`
+}
+
+// readRedaction reads the redaction rules of contenoxDir. Without a file, the
+// built-in credential patterns apply.
+func readRedaction(contenoxDir string) (*taskengine.Redactor, error) {
+	var rules redactionFile
+	if contenoxDir != "" {
+		path := filepath.Join(contenoxDir, redactionFileName)
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("read redaction file: %w", err)
+		default:
+			if err := yaml.Unmarshal(data, &rules); err != nil {
+				return nil, fmt.Errorf("parse redaction file %q: %w", path, err)
+			}
+		}
+	}
+	defaults := rules.Defaults == nil || *rules.Defaults
+	redactor, err := taskengine.NewRedactor(defaults, rules.Patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("redaction file: %w", err)
+	}
+	if rules.Replacement != nil {
+		redactor.Replacement = *rules.Replacement
+	}
+	return redactor, nil
+}
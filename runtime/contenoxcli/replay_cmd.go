@@ -0,0 +1,185 @@
+// replay_cmd.go — contenox replay: re-execute a run recorded with 'contenox run --record'.
+package contenoxcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <recording>",
+	Short: "Re-execute a run recorded with 'contenox run --record'.",
+	Long: `Re-executes the chain of a recording against a mock provider that answers
+every model and tool call with the recorded response. No backend is contacted
+and no tool has side effects, so maintainers can reproduce a reported chain
+failure exactly.
+
+Calls are matched to the recording by their request; a call whose request
+changed is answered with the next unused recorded call of the same kind. After
+the run, replay reports whether the output (or error) matches the recording.
+
+With --tasks, every task is answered with its recorded result instead, so
+only the chain logic (transitions, variables, compose) runs again. Use it
+when handler code or prompts changed since the recording.
+
+Examples:
+  contenox run --chain .contenox/review.json --record trace.json --input @main.go
+  contenox replay trace.json
+  contenox replay trace.json --steps
+  contenox replay trace.json --tasks --steps`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		rec, err := readRecording(args[0])
+		if err != nil {
+			return err
+		}
+		inputVal, inputType, err := parseRunInput(rec.Input, rec.InputType)
+		if err != nil {
+			return fmt.Errorf("recorded input: %w", err)
+		}
+
+		contenoxDir, err := ResolveContenoxDir(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to resolve .contenox dir: %w", err)
+		}
+		dbPath, err := resolveDBPath(cmd)
+		if err != nil {
+			return fmt.Errorf("invalid database path: %w", err)
+		}
+		db, err := OpenDBAt(libtracker.WithNewRequestID(context.Background()), dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		replayTasks, _ := cmd.Flags().GetBool("tasks")
+		if replayTasks && rec.Tasks == nil {
+			return fmt.Errorf("recording %q has no task results; record it again to use --tasks", args[0])
+		}
+		replay := newCallReplayer(rec.Calls)
+		o := buildRunOpts(cmd, db, contenoxDir)
+		o.EffectiveDB = dbPath
+		o.EffectiveDefaultModel = rec.Model
+		o.EffectiveDefaultProvider = rec.Provider
+		o.EffectiveSkipBackendCycle = true
+		// Tool calls are answered from the recording, so registering
+		// local_shell and python_sandbox only exposes their schemas.
+		o.EffectiveEnableLocalExec = true
+		o.EffectiveEnablePython = true
+		o.EffectiveHITL = false
+		o.EffectiveConfirmTools = false
+		o.Replay = replay
+
+		engine, err := BuildEngine(ctx, db, o)
+		if err != nil {
+			return fmt.Errorf("failed to build engine: %w", err)
+		}
+		defer engine.Stop()
+
+		execCtx := taskengine.WithTemplateVars(libtracker.WithNewRequestID(ctx), map[string]string{
+			"model":    rec.Model,
+			"provider": rec.Provider,
+			"chain":    rec.Chain.ID,
+		})
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		var taskReplay *taskengine.Replayer
+		if replayTasks {
+			taskReplay = taskengine.NewReplayer(rec.Tasks)
+			execCtx = taskengine.WithReplay(execCtx, taskReplay)
+		}
+		execCtx, cancel := context.WithTimeout(execCtx, timeout)
+		defer cancel()
+		execCtx, stop := signal.NotifyContext(execCtx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Replaying %s: chain %q, %d recorded calls, recorded %s with contenox %s\n",
+			args[0], rec.Chain.ID, len(rec.Calls), rec.RecordedAt.Format("2006-01-02 15:04:05 MST"), rec.CLIVersion)
+		output, outputType, stateUnits, runErr := engine.TaskService.Execute(execCtx, rec.Chain, inputVal, inputType)
+
+		if runErr == nil {
+			raw, _ := cmd.Flags().GetBool("raw")
+			printRelevantOutput(cmd.OutOrStdout(), output, outputType, raw)
+		}
+		if steps, _ := cmd.Flags().GetBool("steps"); steps && len(stateUnits) > 0 {
+			fmt.Fprintln(cmd.ErrOrStderr(), "\n📋 Steps:")
+			for i, u := range stateUnits {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  %d. %s (%s) %s %s\n", i+1, u.TaskID, u.TaskHandler, formatDuration(u.Duration), u.Transition)
+			}
+		}
+		if taskReplay != nil {
+			if n := taskReplay.Unused(); n > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Note: %d of %d recorded task results were not requested; the chain took a different path than the recording.\n", n, len(rec.Tasks.Steps))
+			}
+		} else if n := replay.unused(); n > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Note: %d of %d recorded calls were not requested; the chain took a different path than the recording.\n", n, len(rec.Calls))
+		}
+		fmt.Fprintln(cmd.ErrOrStderr(), compareReplay(rec, output, runErr))
+		if runErr != nil {
+			return fmt.Errorf("chain execution failed: %w", runErr)
+		}
+		return nil
+	},
+}
+
+// compareReplay describes how the outcome of a replay relates to the recording.
+func compareReplay(rec *runRecording, output any, runErr error) string {
+	switch {
+	case runErr != nil && rec.Error != "":
+		if runErr.Error() == rec.Error {
+			return "Replay reproduced the recorded error."
+		}
+		return fmt.Sprintf("Replay failed with a different error than recorded.\n  recorded: %s\n  replayed: %s", rec.Error, runErr)
+	case runErr != nil:
+		return fmt.Sprintf("Replay failed, but the recorded run succeeded: %s", runErr)
+	case rec.Error != "":
+		return fmt.Sprintf("Replay succeeded, but the recorded run failed: %s", rec.Error)
+	}
+	got, want := comparableOutput(output), comparableOutput(rec.Output)
+	if got == want {
+		return "Replay output matches the recording."
+	}
+	return fmt.Sprintf("Replay output differs from the recording.\n  recorded: %s\n  replayed: %s", truncateForError(want), truncateForError(got))
+}
+
+// comparableOutput encodes v as JSON without message timestamps, which differ
+// between the recorded and the replayed run.
+func comparableOutput(v any) string {
+	data, _ := json.Marshal(v)
+	var decoded any
+	if json.Unmarshal(data, &decoded) != nil {
+		return string(data)
+	}
+	data, _ = json.Marshal(stripTimestamps(decoded))
+	return string(data)
+}
+
+func stripTimestamps(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		delete(v, "timestamp")
+		for k, item := range v {
+			v[k] = stripTimestamps(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = stripTimestamps(item)
+		}
+	}
+	return v
+}
+
+func init() {
+	replayCmd.Flags().Bool("tasks", false, "Answer every task with its recorded result instead of replaying model and tool calls")
+}
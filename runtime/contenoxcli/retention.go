@@ -0,0 +1,49 @@
+package contenoxcli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+)
+
+// historyRetentionKey is the config key of how long the execution history and
+// the model usage statistics are kept. Empty keeps them forever.
+const historyRetentionKey = "history-retention"
+
+// parseHistoryRetention parses a history-retention value such as 720h.
+func parseHistoryRetention(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", historyRetentionKey, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s: must be positive, got %s", historyRetentionKey, value)
+	}
+	return d, nil
+}
+
+// pruneHistory deletes execution log entries and model usage buckets older
+// than the history-retention setting. It is called whenever an engine is built, so the history is trimmed
+// by regular use without a separate job.
+func pruneHistory(ctx context.Context, store runtimetypes.Store, now time.Time) error {
+	value, _ := getConfigKV(ctx, store, historyRetentionKey)
+	if value == "" {
+		return nil
+	}
+	retention, err := parseHistoryRetention(value)
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-retention)
+	if err := store.DeleteExecutionLogBefore(ctx, cutoff); err != nil {
+		return fmt.Errorf("prune execution log: %w", err)
+	}
+	if err := store.DeleteModelUsageBefore(ctx, cutoff); err != nil {
+		return fmt.Errorf("prune model usage: %w", err)
+	}
+	slog.Debug("pruned execution history", "before", cutoff)
+	return nil
+}
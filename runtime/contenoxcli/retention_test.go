@@ -0,0 +1,44 @@
+package contenoxcli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/clikv"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneHistory(t *testing.T) {
+	ctx, _, store := setupSQLiteStore(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, age := range []time.Duration{72 * time.Hour, time.Hour} {
+		require.NoError(t, store.AppendExecutionLog(ctx, &runtimetypes.ExecutionLogEntry{
+			ChainID:    "chain",
+			Status:     runtimetypes.ExecutionSucceeded,
+			StartedAt:  now.Add(-age - time.Second),
+			FinishedAt: now.Add(-age),
+		}))
+		require.NoError(t, store.RecordModelUsage(ctx, "backend", "model", time.Second, false, now.Add(-age)))
+	}
+
+	require.NoError(t, pruneHistory(ctx, store, now), "no retention keeps everything")
+	entries, err := store.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, clikv.SetString(ctx, store, historyRetentionKey, "24h"))
+	require.NoError(t, pruneHistory(ctx, store, now))
+	entries, err = store.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, now.Add(-time.Hour), entries[0].FinishedAt.UTC())
+	usage, err := store.ListModelUsage(ctx, now.Add(-100*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(1), usage[0].RequestCount, "only the recent bucket is left")
+
+	require.NoError(t, clikv.SetString(ctx, store, historyRetentionKey, "soon"))
+	assert.ErrorContains(t, pruneHistory(ctx, store, now), historyRetentionKey)
+}
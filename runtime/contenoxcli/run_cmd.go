@@ -3,6 +3,7 @@ package contenoxcli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"github.com/contenox/contenox/libtracker"
 	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
@@ -41,6 +43,7 @@ Input types (--input-type):
   chat              Wrapped as a single user message (DataTypeChatHistory)
   json              Parsed as a JSON object (DataTypeJSON)
   int               Parsed as integer (DataTypeInt)
+  file              Passed as binary file (DataTypeFile), e.g. --input @scan.pdf
 
 If --chain is not specified, falls back to .contenox/default-run-chain.json
 if that file exists in the current directory.
@@ -54,6 +57,26 @@ Examples:
 
   # Run with human approval before any write_file, sed, or local_shell tool call:
   contenox-runtime run --shell --hitl --chain .contenox/my-chain.json "fix the bug"
+
+  # Continue an interrupted run from its last completed step:
+  contenox-runtime run --resume 3f2b9c1e-5d7a-4c1b-9e8f-2a6d4b7c0e11
+
+Every run is checkpointed after each completed step. If a run is interrupted
+or fails, its run ID is printed and --resume continues it with the saved chain
+and variables; --chain and input are not needed.
+
+--record trace.json writes a portable recording of the run (chain, input,
+model responses, tool results, timings); 'contenox replay trace.json'
+re-executes it without backends or tools, e.g. to reproduce a bug report.
+
+--dry-run fixtures.yaml answers every model call with canned responses
+from a fixtures file, keyed by task ID, so transitions and hook wiring can be
+tested without a model backend. Hooks still run.
+
+A run that reaches an await_approval task stops until it is approved or
+rejected with 'contenox approve <run-id>' or 'contenox reject <run-id>'.
+A run that reaches a clarify task stops with a question until it is answered
+with 'contenox answer <run-id> "..."'.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -62,6 +85,25 @@ Examples:
 		}
 
 		flags := cmd.Flags()
+		resumeID, _ := flags.GetString("resume")
+		recordPath, _ := flags.GetString("record")
+		if recordPath != "" && resumeID != "" {
+			return fmt.Errorf("--record cannot be combined with --resume: a recording must cover the whole run")
+		}
+		dryRunPath, _ := flags.GetString("dry-run")
+		if dryRunPath != "" && (resumeID != "" || recordPath != "") {
+			return fmt.Errorf("--dry-run cannot be combined with --resume or --record")
+		}
+		var fixtures *taskengine.DryRunFixtures
+		if dryRunPath != "" {
+			data, err := os.ReadFile(dryRunPath)
+			if err != nil {
+				return fmt.Errorf("failed to read dry-run fixtures %q: %w", dryRunPath, err)
+			}
+			if fixtures, err = taskengine.ParseDryRunFixtures(dryRunPath, data); err != nil {
+				return fmt.Errorf("failed to parse dry-run fixtures %q: %w", dryRunPath, err)
+			}
+		}
 
 		// Resolve .contenox dir using Git-style parent walk.
 		contenoxDir, err := ResolveContenoxDir(cmd)
@@ -77,7 +119,7 @@ Examples:
 				chainPath = wellKnown
 			}
 		}
-		if chainPath == "" {
+		if chainPath == "" && resumeID == "" {
 			fmt.Fprintln(os.Stderr, "No .contenox/ project found in this directory or any parent directory.")
 			fmt.Fprintln(os.Stderr, "Run 'contenox-runtime init' to get started, or pass --chain explicitly.")
 			return errChainRequired
@@ -88,7 +130,7 @@ Examples:
 		if err != nil {
 			return err
 		}
-		if rawInput == "" {
+		if rawInput == "" && resumeID == "" {
 			return fmt.Errorf(
 				"no input provided\n" +
 					"  Pass input as positional args, --input, pipe via stdin, or use --input @file.txt",
@@ -100,9 +142,18 @@ Examples:
 		if !flags.Changed("input-type") && !flags.Changed("chain") {
 			inputTypeName = "string"
 		}
-		inputVal, inputType, err := parseRunInput(rawInput, inputTypeName)
-		if err != nil {
-			return fmt.Errorf("--input-type %q: %w", inputTypeName, err)
+		var inputVal any
+		var inputType taskengine.DataType
+		if resumeID == "" {
+			inputVal, inputType, err = parseRunInput(rawInput, inputTypeName)
+			if err != nil {
+				return fmt.Errorf("--input-type %q: %w", inputTypeName, err)
+			}
+			if f, ok := inputVal.(taskengine.File); ok {
+				if in, _ := flags.GetString("input"); strings.HasPrefix(in, "@") {
+					inputVal = taskengine.NewFile(filepath.Base(in[1:]), "", f.Data)
+				}
+			}
 		}
 
 		// Open database (needed for buildRunOpts KV read and engine).
@@ -120,6 +171,15 @@ Examples:
 		// Build chatOpts from flags and SQLite KV defaults.
 		o := buildRunOpts(cmd, db, contenoxDir)
 		o.EffectiveDB = dbPathAbs
+		var taskRecorder *taskengine.ReplayRecorder
+		if recordPath != "" {
+			o.Recorder = &callRecorder{}
+			taskRecorder = taskengine.NewReplayRecorder()
+		}
+		if fixtures != nil {
+			// Model calls are answered from the fixtures; no backend is needed.
+			o.EffectiveSkipBackendCycle = true
+		}
 
 		engine, err := BuildEngine(ctx, db, o)
 		if err != nil {
@@ -127,35 +187,65 @@ Examples:
 		}
 		defer engine.Stop()
 
-		if err := PreflightLLMSetup(cmd.ErrOrStderr(), engine.SetupCheck); err != nil {
-			return err
-		}
-
-		// Load chain
-		chainPathAbs, err := filepath.Abs(chainPath)
-		if err != nil {
-			return fmt.Errorf("invalid chain path: %w", err)
-		}
-		chainData, err := os.ReadFile(chainPathAbs)
-		if err != nil {
-			return fmt.Errorf("failed to read chain %q: %w", chainPathAbs, err)
+		if fixtures == nil {
+			if err := PreflightLLMSetup(cmd.ErrOrStderr(), engine.SetupCheck); err != nil {
+				return err
+			}
 		}
 
+		// Load chain: a resumed run continues with the chain it was started with.
+		checkpoints := newKVCheckpointStore(db)
+		runID := resumeID
 		var chain taskengine.TaskChainDefinition
-		if err := json.Unmarshal(chainData, &chain); err != nil {
-			return fmt.Errorf("failed to parse chain JSON: %w", err)
+		chainPathAbs := ""
+		if resumeID != "" {
+			cp, err := checkpoints.LoadCheckpoint(ctx, resumeID)
+			if errors.Is(err, taskengine.ErrCheckpointNotFound) {
+				return fmt.Errorf("no checkpoint for run %q: it completed already or never finished a step", resumeID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load checkpoint for run %q: %w", resumeID, err)
+			}
+			if cp.Chain == nil {
+				return fmt.Errorf("checkpoint for run %q has no chain definition", resumeID)
+			}
+			chain = *cp.Chain
+			fmt.Fprintf(cmd.ErrOrStderr(), "Resuming run %s at task %q (%d steps completed)\n", resumeID, cp.NextTaskID, cp.CompletedSteps)
+		} else {
+			runID = uuid.NewString()
+			chainPathAbs, err = filepath.Abs(chainPath)
+			if err != nil {
+				return fmt.Errorf("invalid chain path: %w", err)
+			}
+			chainData, err := os.ReadFile(chainPathAbs)
+			if err != nil {
+				return fmt.Errorf("failed to read chain %q: %w", chainPathAbs, err)
+			}
+			if err := unmarshalChain(chainPathAbs, chainData, &chain); err != nil {
+				return fmt.Errorf("failed to parse chain %q: %w", chainPathAbs, err)
+			}
 		}
 
 		// Set template vars
-		templateVars := map[string]string{
-			"model":    o.EffectiveDefaultModel,
-			"provider": o.EffectiveDefaultProvider,
-			"chain":    chain.ID,
+		envNames := templateVarsFromEnv(ctx, runtimetypes.New(db.WithoutTransaction()), ResolveWorkspaceID(contenoxDir))
+		templateVars := buildTemplateVars(o, chain.ID, envNames)
+		if requireAll, _ := flags.GetBool("require-all"); requireAll {
+			if err := requireTemplateVars(&chain, templateVars); err != nil {
+				return err
+			}
 		}
 		execCtx := taskengine.WithTemplateVars(
 			libtracker.WithNewRequestID(ctx),
 			templateVars,
 		)
+		if fixtures != nil {
+			// A dry run is not resumable, so it leaves no checkpoints behind.
+			execCtx = taskengine.WithDryRun(execCtx, fixtures)
+			fmt.Fprintf(cmd.ErrOrStderr(), "Dry run: model calls are answered from %s\n", dryRunPath)
+		} else {
+			execCtx = taskengine.WithCheckpoints(execCtx, checkpoints, runID)
+		}
+		execCtx = taskengine.WithReplayRecorder(execCtx, taskRecorder)
 
 		// Set timeout
 		timeout, _ := flags.GetDuration("timeout")
@@ -170,23 +260,76 @@ Examples:
 		if err != nil {
 			return fmt.Errorf("failed to get think flag: %w", err)
 		}
+		effectiveStream, _ := flags.GetBool("stream")
+		var streamer *replyStreamer
+		if effectiveStream {
+			streamer = &replyStreamer{w: cmd.OutOrStdout()}
+			execCtx = taskengine.WithTokenStream(execCtx, streamer.write)
+		}
 		stopTaskEvents := startCLITaskEventStream(execCtx, engine, cmd.ErrOrStderr(), cliTaskEventRenderOptions{
 			Trace:        o.EffectiveTracing,
 			ShowThinking: effectiveThink,
+			HideContent:  effectiveStream,
 		})
 		defer stopTaskEvents()
 
 		if o.EffectiveTracing {
-			slog.Info("Executing chain", "chain", chainPathAbs, "input_type", inputTypeName)
+			slog.Info("Executing chain", "chain", chainPathAbs, "input_type", inputTypeName, "run_id", runID)
 		} else {
 			fmt.Fprintln(cmd.ErrOrStderr(), "Thinking...")
 		}
 
+		started := time.Now()
 		output, outputType, stateUnits, err := engine.TaskService.Execute(execCtx, &chain, inputVal, inputType)
+		if o.Recorder != nil {
+			rec := &runRecording{
+				Version:    recordingVersion,
+				CLIVersion: cliVersion(),
+				RecordedAt: started.UTC(),
+				Chain:      &chain,
+				Input:      rawInput,
+				InputType:  inputTypeName,
+				Model:      o.EffectiveDefaultModel,
+				Provider:   o.EffectiveDefaultProvider,
+				Calls:      o.Recorder.recorded(),
+				Tasks:      taskRecorder.Bundle(),
+				DurationMS: time.Since(started).Milliseconds(),
+			}
+			if err != nil {
+				rec.Error = err.Error()
+			} else {
+				rec.Output, rec.OutputType = output, outputType.String()
+			}
+			if werr := writeRecording(recordPath, rec); werr != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to write recording: %v\n", werr)
+			} else {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Recorded run to %s (%d calls); reproduce it with: contenox replay %s\n", recordPath, len(rec.Calls), recordPath)
+			}
+		}
 		if err != nil {
 			if isModelResolverFailure(err) {
 				PrintSetupIssues(cmd.ErrOrStderr(), engine.SetupCheck)
 			}
+			var pause *taskengine.ApprovalRequiredError
+			if errors.As(err, &pause) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Run %s awaits approval at task %q:\n  %s\nApprove with: contenox approve %s\nReject with:  contenox reject %s --reason \"...\"\n",
+					runID, pause.TaskID, pause.Message, runID, runID)
+				return fmt.Errorf("run %s is waiting for approval", runID)
+			}
+			var question *taskengine.ClarificationRequiredError
+			if errors.As(err, &question) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Run %s needs clarification at task %q:\n  %s\nAnswer with: contenox answer %s \"...\"\nThen resume: contenox run --resume %s\n",
+					runID, question.TaskID, question.Question, runID, runID)
+				return fmt.Errorf("run %s is waiting for clarification", runID)
+			}
+			if errors.Is(err, taskengine.ErrRunQueued) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Run %s is queued: no model is available.\nResume it once a backend is reachable: contenox run --resume %s\n", runID, runID)
+				return fmt.Errorf("run %s is queued: %w", runID, err)
+			}
+			// Use a fresh context: execCtx is likely cancelled (Ctrl+C or --timeout).
+			if _, cpErr := checkpoints.LoadCheckpoint(context.Background(), runID); cpErr == nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Run %s stopped; resume with: contenox run --resume %s\n", runID, runID)
+			}
 			return fmt.Errorf("chain execution failed: %w", err)
 		}
 
@@ -202,12 +345,17 @@ Examples:
 				}
 			}
 		}
-		printRelevantOutput(cmd.OutOrStdout(), output, outputType, effectiveRaw)
+		if streamer != nil {
+			streamer.finish(output, outputType, effectiveRaw)
+		} else {
+			printRelevantOutput(cmd.OutOrStdout(), output, outputType, effectiveRaw)
+		}
 		if effectiveSteps && len(stateUnits) > 0 {
 			fmt.Fprintln(cmd.ErrOrStderr(), "\n📋 Steps:")
 			for i, u := range stateUnits {
-				fmt.Fprintf(cmd.ErrOrStderr(), "  %d. %s (%s) %s %s\n", i+1, u.TaskID, u.TaskHandler, formatDuration(u.Duration), u.Transition)
+				fmt.Fprintf(cmd.ErrOrStderr(), "  %d. %s (%s) %s %s%s\n", i+1, u.TaskID, u.TaskHandler, formatDuration(u.Duration), u.Transition, stepUsage(u))
 			}
+			printStepUsage(cmd.ErrOrStderr(), stateUnits)
 		}
 		return nil
 	},
@@ -279,9 +427,12 @@ func parseRunInput(raw, typeName string) (any, taskengine.DataType, error) {
 		}
 		return n, taskengine.DataTypeInt, nil
 
+	case "file":
+		return taskengine.NewFile("", "", []byte(raw)), taskengine.DataTypeFile, nil
+
 	default:
 		return nil, taskengine.DataTypeAny, fmt.Errorf(
-			"unknown input type %q — valid values: string, chat, json, int", typeName,
+			"unknown input type %q — valid values: string, chat, json, int, file", typeName,
 		)
 	}
 }
@@ -318,7 +469,10 @@ func buildRunOpts(cmd *cobra.Command, db libdbexec.DBManager, contenoxDir string
 
 	effectiveEnableLocalExec, _ := flags.GetBool("shell")
 	effectiveLocalExecAllowedDir, _ := flags.GetString("local-exec-allowed-dir")
+	effectiveEnablePython, _ := flags.GetBool("python")
 	effectiveHITL, _ := cmd.Flags().GetBool("hitl")
+	cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+	rateLimits, _ := cmd.Flags().GetStringToInt("rate-limit")
 
 	return chatOpts{
 		EffectiveDB:                  "", // resolved separately in RunE
@@ -329,16 +483,27 @@ func buildRunOpts(cmd *cobra.Command, db libdbexec.DBManager, contenoxDir string
 		EffectiveNoDeleteModels:      true,
 		EffectiveEnableLocalExec:     effectiveEnableLocalExec,
 		EffectiveLocalExecAllowedDir: effectiveLocalExecAllowedDir,
+		EffectiveEnablePython:        effectiveEnablePython,
 		EffectiveHITL:                effectiveHITL,
+		EffectiveConfirmTools:        resolveConfirmTools(ctx, store, cmd),
 		EffectiveTracing:             effectiveTracing,
 		ContenoxDir:                  contenoxDir,
+		PromptCacheTTL:               cacheTTL,
+		RateLimits:                   rateLimits,
 	}
 }
 
 func init() {
 	f := runCmd.Flags()
-	f.String("chain", "", "Path to a task chain JSON file (falls back to .contenox/default-run-chain.json if present)")
+	f.String("chain", "", "Path to a task chain file (.json, .yaml or .yml) (falls back to .contenox/default-run-chain.json if present)")
 	f.String("input", "", "Input value or @path to read from a file (e.g. --input @main.go)")
-	f.String("input-type", "string", "Input data type: string, chat, json, int")
+	f.String("input-type", "string", "Input data type: string, chat, json, int, file")
 	f.Bool("hitl", false, "Pause before write_file, sed, and local_shell calls; require y/n approval in the terminal")
+	f.Bool("confirm-tools", false, "Ask y/n before every local_shell, ssh, python_sandbox and file write call the model makes; 'a' always allows the command pattern (default: confirm-tools config)")
+	f.String("resume", "", "Resume an interrupted run by its run ID, continuing after the last completed step")
+	f.String("record", "", "Write the chain, input, model responses, tool results and timings to this file for 'contenox replay'")
+	f.String("dry-run", "", "Answer model calls from this fixtures file (.json, .yaml or .yml) instead of a backend, to test chain transitions and hook wiring")
+	f.Bool("require-all", false, "Fail before running when the chain references {{var:NAME}} variables that are unset or empty")
+	f.Duration("cache-ttl", 0, "Reuse responses of identical prompts (same prompt, system instruction, model and temperature) for this long, e.g. 30m (0 = no cache)")
+	f.StringToInt("rate-limit", nil, "Cap model calls per minute per provider type, e.g. openai=60 (* = any other provider); repeatable")
 }
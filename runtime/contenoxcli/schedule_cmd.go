@@ -0,0 +1,193 @@
+package contenoxcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/clikv"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/spf13/cobra"
+)
+
+// scheduledChangesInterval is how often a running engine applies due
+// scheduled changes.
+const scheduledChangesInterval = time.Minute
+
+var configScheduledCmd = &cobra.Command{
+	Use:   "scheduled",
+	Short: "List scheduled configuration changes.",
+	Long: `List configuration changes scheduled with --at, e.g.
+
+  contenox config set default-model qwen2.5:7b --at "2025-06-07 02:00"
+  contenox backend remove old-gpu --at +168h
+
+Due changes are applied the next time contenox opens its database and every
+minute while a session is running. When the change-window key is set they
+are only applied inside that window.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, store, err := openConfigDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		all, _ := cmd.Flags().GetBool("all")
+		status := runtimetypes.ChangePending
+		if all {
+			status = ""
+		}
+		ctx := libtracker.WithNewRequestID(context.Background())
+		changes, err := store.ListScheduledChanges(ctx, status)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No scheduled changes.")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tAPPLY AT\tSTATUS\tCHANGE")
+		for _, c := range changes {
+			state := string(c.Status)
+			if c.Error != "" {
+				state += ": " + c.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.ID, c.ApplyAt.Local().Format("2006-01-02 15:04"), state, describeScheduledChange(c))
+		}
+		return w.Flush()
+	},
+}
+
+var configCancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Cancel a pending scheduled change.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, store, err := openConfigDB(cmd)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		ctx := libtracker.WithNewRequestID(context.Background())
+		if err := store.CancelScheduledChange(ctx, args[0]); errors.Is(err, libdb.ErrNotFound) {
+			return fmt.Errorf("no pending scheduled change %q", args[0])
+		} else if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Scheduled change %s cancelled.\n", args[0])
+		return nil
+	},
+}
+
+// parseApplyAt parses the --at flag: an RFC 3339 timestamp, a local
+// "YYYY-MM-DD HH:MM", a local "HH:MM" (the next time that clock time
+// occurs) or a "+<duration>" offset from now.
+func parseApplyAt(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, ok := strings.CutPrefix(s, "+"); ok {
+		dur, err := time.ParseDuration(d)
+		if err != nil || dur <= 0 {
+			return time.Time{}, fmt.Errorf("invalid --at offset %q", s)
+		}
+		return now.Add(dur), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if clock, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: use RFC 3339, \"YYYY-MM-DD HH:MM\", \"HH:MM\" or \"+<duration>\"", s)
+}
+
+// scheduleChange stores change and confirms it on w.
+func scheduleChange(ctx context.Context, store runtimetypes.Store, w io.Writer, change *runtimetypes.ScheduledChange) error {
+	if err := store.CreateScheduledChange(ctx, change); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "⏰  %s scheduled for %s (id %s)\n", describeScheduledChange(change), change.ApplyAt.Local().Format("2006-01-02 15:04 MST"), change.ID)
+	if window := changeWindow(ctx, store); window != nil && !window.Contains(change.ApplyAt) {
+		fmt.Fprintln(w, "   outside the change window; it will be applied when the window next opens.")
+	}
+	return nil
+}
+
+// changeWindow returns the change window configured with the change-window
+// key, or nil when changes may be applied at any time.
+func changeWindow(ctx context.Context, store runtimetypes.Store) *runtimetypes.ChangeWindow {
+	spec := clikv.Read(ctx, store, "change-window")
+	if spec == "" {
+		return nil
+	}
+	window, err := runtimetypes.ParseChangeWindow(spec)
+	if err != nil {
+		// Validated by config set; an unreadable value must not block changes forever.
+		return nil
+	}
+	return window
+}
+
+// applyScheduledChanges applies the scheduled changes that are due and
+// reports each of them on w.
+func applyScheduledChanges(ctx context.Context, db libdb.DBManager, w io.Writer) {
+	window := changeWindow(ctx, runtimetypes.New(db.WithoutTransaction()))
+	_, err := runtimetypes.ApplyDueChanges(ctx, db, time.Now(), window, scheduledChangeNotifier(w))
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to apply scheduled changes: %v\n", err)
+	}
+}
+
+func scheduledChangeNotifier(w io.Writer) func(*runtimetypes.ScheduledChange) {
+	return func(c *runtimetypes.ScheduledChange) {
+		if c.Status == runtimetypes.ChangeFailed {
+			fmt.Fprintf(w, "Warning: scheduled change %s failed: %s: %s\n", c.ID, describeScheduledChange(c), c.Error)
+			return
+		}
+		fmt.Fprintf(w, "⏰  applied scheduled change: %s\n", describeScheduledChange(c))
+	}
+}
+
+func describeScheduledChange(c *runtimetypes.ScheduledChange) string {
+	key := strings.TrimPrefix(c.Target, clikv.Prefix)
+	switch c.Kind {
+	case runtimetypes.ChangeSetKV:
+		var value string
+		if err := json.Unmarshal([]byte(c.Value), &value); err != nil {
+			value = c.Value
+		}
+		return fmt.Sprintf("set %s = %s", key, value)
+	case runtimetypes.ChangeDeleteKV:
+		return "unset " + key
+	case runtimetypes.ChangeDeleteBackend:
+		if c.Value != "" {
+			return fmt.Sprintf("remove backend %s", c.Value)
+		}
+		return fmt.Sprintf("remove backend %s", c.Target)
+	}
+	return fmt.Sprintf("%s %s", c.Kind, c.Target)
+}
+
+func init() {
+	configScheduledCmd.Flags().Bool("all", false, "Include applied, cancelled and failed changes")
+	configCmd.AddCommand(configScheduledCmd)
+	configCmd.AddCommand(configCancelCmd)
+}
@@ -0,0 +1,132 @@
+package contenoxcli
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/clikv"
+	"github.com/contenox/contenox/runtime/internal/runtimestate"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledChanges_ApplyAndCancel(t *testing.T) {
+	ctx, db, store := setupSQLiteStore(t)
+	now := time.Now()
+
+	backend := &runtimetypes.Backend{Name: "old-gpu", BaseURL: "http://gpu:11434", Type: "ollama"}
+	require.NoError(t, store.CreateBackend(ctx, backend))
+	require.NoError(t, runtimestate.SetBackendSyncInterval(ctx, store, backend.ID, time.Hour))
+
+	model, err := clikv.ScheduleConfig("ws", "default-model", "qwen2.5:14b", now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, store.CreateScheduledChange(ctx, model))
+	chain, err := clikv.ScheduleConfig("ws", "default-chain", "night.json", now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "ws", chain.WorkspaceID)
+	require.NoError(t, store.CreateScheduledChange(ctx, chain))
+	removal := &runtimetypes.ScheduledChange{Kind: runtimetypes.ChangeDeleteBackend, Target: backend.ID, Value: backend.Name, ApplyAt: now.Add(time.Hour)}
+	require.NoError(t, store.CreateScheduledChange(ctx, removal))
+	cancelled := &runtimetypes.ScheduledChange{Kind: runtimetypes.ChangeDeleteKV, Target: clikv.Prefix + "default-provider", ApplyAt: now.Add(-time.Hour)}
+	require.NoError(t, store.CreateScheduledChange(ctx, cancelled))
+	require.NoError(t, store.CancelScheduledChange(ctx, cancelled.ID))
+	require.Error(t, store.CancelScheduledChange(ctx, cancelled.ID), "only pending changes can be cancelled")
+
+	var out bytes.Buffer
+	applyScheduledChanges(ctx, db, &out)
+	assert.Contains(t, out.String(), "applied scheduled change: set default-model = qwen2.5:14b")
+	assert.Equal(t, "qwen2.5:14b", clikv.Read(ctx, store, "default-model"))
+	val, scope := clikv.ReadConfig(ctx, store, "ws", "default-chain")
+	assert.Equal(t, "night.json", val)
+	assert.Equal(t, "workspace", scope)
+
+	pending, err := store.ListScheduledChanges(ctx, runtimetypes.ChangePending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, removal.ID, pending[0].ID)
+
+	// The removal is due but outside the change window until it opens.
+	window := &runtimetypes.ChangeWindow{End: time.Hour, Location: time.UTC}
+	out.Reset()
+	applied, err := runtimetypes.ApplyDueChanges(ctx, db, time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC), window, scheduledChangeNotifier(&out))
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	applied, err = runtimetypes.ApplyDueChanges(ctx, db, time.Date(2099, 1, 2, 0, 30, 0, 0, time.UTC), window, scheduledChangeNotifier(&out))
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, runtimetypes.ChangeApplied, applied[0].Status)
+	_, err = store.GetBackend(ctx, backend.ID)
+	require.Error(t, err, "backend is removed")
+	interval, err := runtimestate.GetBackendSyncInterval(ctx, store, backend.ID)
+	require.NoError(t, err)
+	assert.Zero(t, interval, "the sync interval is removed with the backend")
+	assert.Contains(t, out.String(), "remove backend old-gpu")
+
+	got, err := store.GetScheduledChange(ctx, cancelled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runtimetypes.ChangeCancelled, got.Status)
+	err = store.GetKV(ctx, clikv.Prefix+"default-provider", new(string))
+	require.Error(t, err, "cancelled change is not applied")
+}
+
+func TestScheduledChanges_FailureIsRecorded(t *testing.T) {
+	ctx, db, store := setupSQLiteStore(t)
+	change := &runtimetypes.ScheduledChange{Kind: runtimetypes.ChangeDeleteBackend, Target: "missing", ApplyAt: time.Now().Add(-time.Second)}
+	require.NoError(t, store.CreateScheduledChange(ctx, change))
+
+	var out bytes.Buffer
+	applyScheduledChanges(ctx, db, &out)
+	assert.Contains(t, out.String(), "Warning: scheduled change "+change.ID+" failed")
+	got, err := store.GetScheduledChange(ctx, change.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runtimetypes.ChangeFailed, got.Status)
+	assert.NotEmpty(t, got.Error)
+}
+
+func TestParseApplyAt(t *testing.T) {
+	now := time.Date(2024, 6, 7, 18, 30, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"+90m":                 now.Add(90 * time.Minute),
+		"2024-06-08T02:00:00Z": time.Date(2024, 6, 8, 2, 0, 0, 0, time.UTC),
+		"2024-06-08 02:00":     time.Date(2024, 6, 8, 2, 0, 0, 0, time.UTC),
+		"02:00":                time.Date(2024, 6, 8, 2, 0, 0, 0, time.UTC),
+		"19:00":                time.Date(2024, 6, 7, 19, 0, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := parseApplyAt(in, now)
+		require.NoError(t, err, in)
+		assert.True(t, want.Equal(got), "%s: got %s", in, got)
+	}
+	for _, bad := range []string{"tomorrow", "+-1h", ""} {
+		_, err := parseApplyAt(bad, now)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestScheduledChanges_WindowIsReadEveryTick(t *testing.T) {
+	ctx, db, _ := setupSQLiteStore(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtimetypes.RunScheduledChanges(ctx, db, 5*time.Millisecond, func() *runtimetypes.ChangeWindow {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+			return nil
+		}, nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunScheduledChanges did not stop")
+	}
+	assert.GreaterOrEqual(t, calls.Load(), int32(3))
+}
@@ -1,16 +1,22 @@
-// session_cmd.go — contenox session subcommand tree (new, list, switch, delete, show).
+// session_cmd.go — contenox session subcommand tree (new, list, switch, delete, show, context, rate, export).
 // Each subcommand opens only the DB via sessionservice; no LLM stack is needed.
 package contenoxcli
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	libdb "github.com/contenox/contenox/libdbexec"
 	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/chatservice"
+	"github.com/contenox/contenox/runtime/internal/ollamatokenizer"
 	"github.com/contenox/contenox/runtime/messagestore"
+	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/contenox/contenox/runtime/sessionservice"
 	"github.com/contenox/contenox/runtime/taskengine"
 	"github.com/spf13/cobra"
@@ -19,7 +25,7 @@ import (
 // sessionCmd is the parent "contenox session" command.
 var sessionCmd = &cobra.Command{
 	Use:   "session",
-	Short: "Manage chat sessions (new, list, switch, delete, show).",
+	Short: "Manage chat sessions (new, list, switch, delete, show, context, rate, export).",
 	Long: `Create and switch named chat sessions.
 Each session maintains its own persistent conversation history.
 
@@ -27,7 +33,10 @@ Each session maintains its own persistent conversation history.
   contenox session list           list all sessions (* = active)
   contenox session switch <name>  switch the active session
   contenox session delete <name>  delete a session and its messages
-  contenox session show           print the active session's conversation`,
+  contenox session show           print the active session's conversation
+  contenox session context        report token usage against the model's context window
+  contenox session rate up|down   rate the last answer (thumbs-up/down, optional comment)
+  contenox session export         export rated answers as a preference dataset`,
 	SilenceUsage: true,
 }
 
@@ -69,6 +78,7 @@ Defaults to the currently active session. Pass a session name to view another.
 Flags:
   --tail N    Show only the last N messages
   --head N    Show only the first N messages
+  --ids       Show message IDs (for 'contenox session rate --message')
 
 Examples:
   contenox session show
@@ -78,10 +88,78 @@ Examples:
 	RunE: runSessionShow,
 }
 
+var sessionContextCmd = &cobra.Command{
+	Use:   "context [name]",
+	Short: "Report a session's token usage against the model's context window.",
+	Long: `Count the tokens of a session's history (default: active session) and
+compare them with the context length of the target model.
+
+Reports how many of the oldest messages would be truncated to fit and whether
+the history was, or is about to be, summarized by compaction.
+
+The model defaults to the configured default-model; pass --model to check
+another one. The context length comes from --context, or from the model's
+declared context length ('contenox model list').
+
+Examples:
+  contenox session context
+  contenox session context my-session --model qwen2.5:7b
+  contenox session context --context 32768 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSessionContext,
+}
+
+var sessionRateCmd = &cobra.Command{
+	Use:   "rate <up|down> [name]",
+	Short: "Rate an assistant message with thumbs-up or thumbs-down.",
+	Long: `Record thumbs-up or thumbs-down feedback, and an optional free-text
+comment, on an assistant message of a session (default: active session).
+
+Rates the last assistant message unless --message names another one by ID or
+ID prefix ('contenox session show --ids' lists them). Rating a message again
+replaces the earlier feedback. Export the feedback with 'contenox session export'.
+
+Examples:
+  contenox session rate up
+  contenox session rate down --comment "ignored the file I attached"
+  contenox session rate down my-session --message 3fa2c1`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSessionRate,
+}
+
+var sessionExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export rated answers as a preference fine-tuning dataset (JSONL).",
+	Long: `Write the feedback recorded with 'contenox session rate' across all sessions
+as a JSONL dataset for preference fine-tuning.
+
+Formats:
+  dpo     prompt/chosen/rejected pairs (TRL DPOTrainer, conversational)
+  openai  input/preferred_output/non_preferred_output pairs (OpenAI preference fine-tuning)
+  kto     one prompt/completion/label record per rated answer (TRL KTOTrainer)
+
+The prompt of an answer is the system, user and assistant messages before it.
+Paired formats pair every thumbs-up answer with every thumbs-down answer to the
+same prompt, e.g. the same question asked in several sessions; answers without
+a counterpart are only exported by kto.
+
+Examples:
+  contenox session export --format dpo -o prefs.jsonl
+  contenox session export --format kto > kto.jsonl`,
+	Args: cobra.NoArgs,
+	RunE: runSessionExport,
+}
+
 func init() {
 	sessionShowCmd.Flags().Int("tail", 0, "Show last N messages (0 = all)")
 	sessionShowCmd.Flags().Int("head", 0, "Show first N messages (0 = all)")
-	sessionCmd.AddCommand(sessionNewCmd, sessionListCmd, sessionSwitchCmd, sessionDeleteCmd, sessionShowCmd)
+	sessionShowCmd.Flags().Bool("ids", false, "Show message IDs")
+	sessionContextCmd.Flags().Bool("json", false, "Print the report as JSON")
+	sessionRateCmd.Flags().String("message", "", "ID or ID prefix of the message to rate (default: last assistant message)")
+	sessionRateCmd.Flags().String("comment", "", "Free-text feedback")
+	sessionExportCmd.Flags().String("format", chatservice.FormatDPO, "Dataset format: dpo, openai or kto")
+	sessionExportCmd.Flags().StringP("output", "o", "", "Write to file instead of stdout")
+	sessionCmd.AddCommand(sessionNewCmd, sessionListCmd, sessionSwitchCmd, sessionDeleteCmd, sessionShowCmd, sessionContextCmd, sessionRateCmd, sessionExportCmd)
 }
 
 // openSessionService resolves the DB path and returns a sessionservice.Service.
@@ -187,49 +265,52 @@ func runSessionDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runSessionShow(cmd *cobra.Command, args []string) error {
-	ctx, db, svc, cleanup, err := openSessionService(cmd)
-	if err != nil {
-		return err
-	}
-	defer cleanup()
-
-	tailN, _ := cmd.Flags().GetInt("tail")
-	headN, _ := cmd.Flags().GetInt("head")
-
-	// Resolve which session to show.
-	var sessionID, sessionName string
+// resolveSessionArg returns the ID and display name of the session named in
+// args, or of the active session when args is empty.
+func resolveSessionArg(ctx context.Context, svc sessionservice.Service, args []string) (string, string, error) {
 	if len(args) > 0 {
 		// Resolve name → ID via raw messagestore (read-only, presentation path).
 		sessions, err := svc.List(ctx, localIdentity)
 		if err != nil {
-			return err
+			return "", "", err
 		}
 		for _, s := range sessions {
 			if s.Name == args[0] {
-				sessionID = s.ID
-				sessionName = s.Name
-				break
+				return s.ID, s.Name, nil
 			}
 		}
-		if sessionID == "" {
-			return fmt.Errorf("session %q not found; run 'contenox session list'", args[0])
-		}
-	} else {
-		activeID, err := svc.GetActiveID(ctx)
-		if err != nil || activeID == "" {
-			return fmt.Errorf("no active session; run 'contenox session new' to create one")
-		}
-		sessionID = activeID
-		sessionName = sessionID[:8] + "…"
-		sessions, _ := svc.List(ctx, localIdentity)
-		for _, s := range sessions {
-			if s.ID == sessionID && s.Name != "" {
-				sessionName = s.Name
-				break
-			}
+		return "", "", fmt.Errorf("session %q not found; run 'contenox session list'", args[0])
+	}
+	activeID, err := svc.GetActiveID(ctx)
+	if err != nil || activeID == "" {
+		return "", "", fmt.Errorf("no active session; run 'contenox session new' to create one")
+	}
+	sessionName := activeID[:8] + "…"
+	sessions, _ := svc.List(ctx, localIdentity)
+	for _, s := range sessions {
+		if s.ID == activeID && s.Name != "" {
+			sessionName = s.Name
+			break
 		}
 	}
+	return activeID, sessionName, nil
+}
+
+func runSessionShow(cmd *cobra.Command, args []string) error {
+	ctx, db, svc, cleanup, err := openSessionService(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	tailN, _ := cmd.Flags().GetInt("tail")
+	headN, _ := cmd.Flags().GetInt("head")
+	showIDs, _ := cmd.Flags().GetBool("ids")
+
+	sessionID, sessionName, err := resolveSessionArg(ctx, svc, args)
+	if err != nil {
+		return err
+	}
 
 	contenoxDir, _ := ResolveContenoxDir(cmd)
 	store := messagestore.New(db.WithoutTransaction(), ResolveWorkspaceID(contenoxDir))
@@ -261,13 +342,156 @@ func runSessionShow(cmd *cobra.Command, args []string) error {
 		if !m.Timestamp.IsZero() {
 			ts = m.Timestamp.Format(time.RFC3339)
 		}
+		role := m.Role
+		if showIDs {
+			role += " (" + raw.ID + ")"
+		}
 		if ts != "" {
-			fmt.Fprintf(out, "[%s] %s:\n", ts, m.Role)
+			fmt.Fprintf(out, "[%s] %s:\n", ts, role)
 		} else {
-			fmt.Fprintf(out, "%s:\n", m.Role)
+			fmt.Fprintf(out, "%s:\n", role)
 		}
 		fmt.Fprintf(out, "  %s\n\n", m.Content)
 	}
 	fmt.Fprintf(out, "━━━━━━━━━━━━━━━━━━━━\n")
 	return nil
 }
+
+func runSessionContext(cmd *cobra.Command, args []string) error {
+	ctx, db, svc, cleanup, err := openSessionService(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	sessionID, sessionName, err := resolveSessionArg(ctx, svc, args)
+	if err != nil {
+		return err
+	}
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	model, _ := cmd.Flags().GetString("model")
+	if !cmd.Flags().Changed("model") {
+		if kv, _ := getConfigKV(ctx, store, "default-model"); kv != "" {
+			model = kv
+		}
+	}
+	if model == "" {
+		return fmt.Errorf("no model: pass --model or run 'contenox config set default-model <name>'")
+	}
+	contextLength, _ := cmd.Flags().GetInt("context")
+	if contextLength <= 0 {
+		m, err := store.GetModelByName(ctx, model)
+		if err != nil && !errors.Is(err, libdb.ErrNotFound) {
+			return fmt.Errorf("failed to look up model %q: %w", model, err)
+		}
+		if m != nil {
+			contextLength = m.ContextLength
+		}
+	}
+
+	contenoxDir, _ := ResolveContenoxDir(cmd)
+	tokenizer := ollamatokenizer.NewEstimateTokenizer()
+	report, err := chatservice.NewManager(ResolveWorkspaceID(contenoxDir)).
+		ContextReport(ctx, db.WithoutTransaction(), sessionID, model, contextLength, tokenizer.CountTokens)
+	if err != nil {
+		return fmt.Errorf("failed to build context report: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Fprintf(out, "Session:        %s (%d messages)\n", sessionName, report.Messages)
+	fmt.Fprintf(out, "Model:          %s\n", report.Model)
+	if report.ContextLength == 0 {
+		fmt.Fprintf(out, "Tokens:         %d (context length unknown; pass --context)\n", report.Tokens)
+		return nil
+	}
+	fmt.Fprintf(out, "Tokens:         %d / %d (%.0f%%)\n", report.Tokens, report.ContextLength, report.UsedFraction*100)
+	if report.Remaining >= 0 {
+		fmt.Fprintf(out, "Remaining:      %d\n", report.Remaining)
+	} else {
+		fmt.Fprintf(out, "Overflow:       %d tokens; %d oldest message(s) would be truncated\n", -report.Remaining, report.TruncatedMessages)
+	}
+	fmt.Fprintf(out, "Summarization:  %s (threshold %d tokens, %d summaries in history)\n", report.SummarizationStatus, report.CompactionThreshold, report.Summaries)
+	return nil
+}
+
+func runSessionRate(cmd *cobra.Command, args []string) error {
+	var rating int
+	switch args[0] {
+	case "up", "+1":
+		rating = chatservice.RatingUp
+	case "down", "-1":
+		rating = chatservice.RatingDown
+	default:
+		return fmt.Errorf("invalid rating %q: use up or down", args[0])
+	}
+	ctx, db, svc, cleanup, err := openSessionService(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	sessionID, sessionName, err := resolveSessionArg(ctx, svc, args[1:])
+	if err != nil {
+		return err
+	}
+	messageID, _ := cmd.Flags().GetString("message")
+	comment, _ := cmd.Flags().GetString("comment")
+	contenoxDir, _ := ResolveContenoxDir(cmd)
+	rated, err := chatservice.NewManager(ResolveWorkspaceID(contenoxDir)).
+		RateMessage(ctx, db.WithoutTransaction(), sessionID, messageID, rating, comment)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Rated message %s of session %q %s.\n", shortID(rated), sessionName, args[0])
+	return nil
+}
+
+func runSessionExport(cmd *cobra.Command, _ []string) error {
+	ctx, db, svc, cleanup, err := openSessionService(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	sessions, err := svc.List(ctx, localIdentity)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if path, _ := cmd.Flags().GetString("output"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	format, _ := cmd.Flags().GetString("format")
+	contenoxDir, _ := ResolveContenoxDir(cmd)
+	n, err := chatservice.NewManager(ResolveWorkspaceID(contenoxDir)).
+		ExportPreferences(ctx, db.WithoutTransaction(), ids, format, out)
+	if err != nil {
+		return fmt.Errorf("failed to export feedback: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d %s record(s).\n", n, format)
+	return nil
+}
+
+// shortID abbreviates a message ID for display.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
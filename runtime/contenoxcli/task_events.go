@@ -15,12 +15,15 @@ import (
 type cliTaskEventRenderOptions struct {
 	Trace        bool
 	ShowThinking bool
+	// HideContent leaves response text out, e.g. when --stream prints it to stdout.
+	HideContent bool
 }
 
 type cliTaskEventRenderer struct {
 	w              io.Writer
 	trace          bool
 	showThinking   bool
+	hideContent    bool
 	lastTaskID     string
 	contentActive  bool
 	thinkingActive bool
@@ -53,6 +56,7 @@ func startCLITaskEventStream(
 		w:            errW,
 		trace:        opts.Trace,
 		showThinking: opts.ShowThinking,
+		hideContent:  opts.HideContent,
 	}
 
 	var once sync.Once
@@ -139,7 +143,7 @@ func (r *cliTaskEventRenderer) Render(event taskengine.TaskEvent) {
 			}
 			fmt.Fprint(r.w, event.Thinking)
 		}
-		if event.Content != "" {
+		if event.Content != "" && !r.hideContent {
 			if r.thinkingActive {
 				fmt.Fprintln(r.w)
 				r.thinkingActive = false
@@ -164,6 +168,15 @@ func (r *cliTaskEventRenderer) Render(event taskengine.TaskEvent) {
 		if r.trace {
 			fmt.Fprintf(r.w, "[step:%s] failed: %s\n", event.TaskID, event.Error)
 		}
+	case taskengine.TaskEventToolCalled:
+		if r.trace {
+			r.finishChunkLine()
+			if event.Error != "" {
+				fmt.Fprintf(r.w, "[tool:%s.%s] failed after %dms: %s\n", event.ToolsName, event.ToolName, event.DurationMS, event.Error)
+			} else {
+				fmt.Fprintf(r.w, "[tool:%s.%s] called (%dms)\n", event.ToolsName, event.ToolName, event.DurationMS)
+			}
+		}
 	case taskengine.TaskEventChainCompleted:
 		r.finishChunkLine()
 		if r.trace {
@@ -1,4 +1,4 @@
-// tools_cmd.go — contenox tools subcommand tree (add, list, show, remove, update).
+// tools_cmd.go — contenox tools subcommand tree (add, list, show, remove, update, stats).
 // Each subcommand opens only the DB; no LLM stack is needed.
 package contenoxcli
 
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,7 +23,7 @@ import (
 // toolsCmd is the parent "contenox tools" command.
 var toolsCmd = &cobra.Command{
 	Use:   "tools",
-	Short: "Manage remote tools (add, list, show, remove, update).",
+	Short: "Manage remote tools (add, list, show, remove, update, stats).",
 	Long: `Register and manage remote tools — external HTTP services exposed as LLM tools.
 
 A remote tools points at an OpenAPI v3 service. When used in a chain the runtime
@@ -39,7 +40,8 @@ Examples:
   contenox tools list
   contenox tools show myapi
   contenox tools update myapi --url http://new-host:8080
-  contenox tools remove myapi`,
+  contenox tools remove myapi
+  contenox tools stats`,
 	SilenceUsage: true,
 }
 
@@ -115,6 +117,23 @@ Examples:
 	RunE: runToolsUpdate,
 }
 
+var toolsStatsCmd = &cobra.Command{
+	Use:   "stats [name]",
+	Short: "Show how often each tool was called, its latency and error rate.",
+	Long: `Show usage statistics of every tool called by chains run on this machine:
+invocation count, error rate, average and maximum latency, and the chains and
+models that called it. A tool with a high error rate is often one the model
+calls with wrong arguments.
+
+Pass a tools name to only show its tools.
+
+Examples:
+  contenox tools stats
+  contenox tools stats nws`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runToolsStats,
+}
+
 func init() {
 	toolsAddCmd.Flags().String("url", "", "Base URL of the remote tools service (required)")
 	_ = toolsAddCmd.MarkFlagRequired("url")
@@ -129,7 +148,7 @@ func init() {
 	toolsUpdateCmd.Flags().Int("timeout", 0, "New timeout in milliseconds (0 = keep existing)")
 	toolsUpdateCmd.Flags().String("spec", "", "New spec URL or file path (replaces existing; pass empty string to clear)")
 
-	toolsCmd.AddCommand(toolsAddCmd, toolsListCmd, toolsShowCmd, toolsRemoveCmd, toolsUpdateCmd)
+	toolsCmd.AddCommand(toolsAddCmd, toolsListCmd, toolsShowCmd, toolsRemoveCmd, toolsUpdateCmd, toolsStatsCmd)
 }
 
 // openToolsService resolves the DB path, opens SQLite and returns a toolsproviderservice.
@@ -326,6 +345,76 @@ func runToolsList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runToolsStats(cmd *cobra.Command, args []string) error {
+	ctx := libtracker.WithNewRequestID(context.Background())
+	db, svc, err := openToolsService(cmd)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := svc.Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load tool usage: %w", err)
+	}
+	if len(args) == 1 {
+		filtered := stats[:0]
+		for _, u := range stats {
+			if u.Tools == args[0] {
+				filtered = append(filtered, u)
+			}
+		}
+		stats = filtered
+	}
+	if len(stats) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No tool calls recorded yet.")
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "%-32s  %6s  %6s  %8s  %8s  %s\n", "TOOL", "CALLS", "ERRORS", "AVG", "MAX", "CALLED BY")
+	for _, u := range stats {
+		name := u.Tools + "." + u.Tool
+		if len(name) > 32 {
+			name = name[:29] + "..."
+		}
+		fmt.Fprintf(out, "%-32s  %6d  %5.0f%%  %8s  %8s  %s\n",
+			name, u.Calls, u.ErrorRate()*100, u.AvgLatency(), time.Duration(u.MaxLatencyMS)*time.Millisecond,
+			formatUsageCounts(u.Chains, u.Models))
+		if u.LastError != "" {
+			fmt.Fprintf(out, "%-32s  last error: %s\n", "", u.LastError)
+		}
+	}
+	return nil
+}
+
+// formatUsageCounts lists the chains and models that called a tool, most
+// frequent first.
+func formatUsageCounts(chains, models map[string]int64) string {
+	format := func(prefix string, counts map[string]int64) []string {
+		names := make([]string, 0, len(counts))
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts[names[i]] != counts[names[j]] {
+				return counts[names[i]] > counts[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%s%s(%d)", prefix, name, counts[name])
+		}
+		return parts
+	}
+	parts := append(format("chain:", chains), format("model:", models)...)
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
 func runToolsShow(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	ctx := libtracker.WithNewRequestID(context.Background())
@@ -0,0 +1,56 @@
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/contenox/contenox/runtime/toolsproviderservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingTools struct {
+	taskengine.ToolsRepo
+}
+
+func (failingTools) Exec(_ context.Context, _ time.Time, _ any, _ bool, _ *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	return nil, taskengine.DataTypeAny, errors.New("missing required argument: city")
+}
+
+func TestToolUsageStats(t *testing.T) {
+	ctx, db, _ := setupSQLiteStore(t)
+	inner := tools.NewMockToolsRegistry()
+	inner.DefaultResponse.Output = "ok"
+	repo := toolsproviderservice.WithUsageTracking(inner, db)
+
+	chainCtx := taskengine.WithTaskEventScope(ctx, taskengine.TaskEventScope{ChainID: "weather", TaskID: "run_tools"})
+	for range 3 {
+		_, _, err := repo.Exec(chainCtx, time.Now(), map[string]any{}, false, &taskengine.ToolsCall{Name: "nws", ToolName: "forecast"})
+		require.NoError(t, err)
+	}
+	_, _, err := repo.Exec(ctx, time.Now(), "x", false, &taskengine.ToolsCall{Name: "calc", ToolName: "add"})
+	require.NoError(t, err)
+	failing := toolsproviderservice.WithUsageTracking(failingTools{inner}, db)
+	_, _, err = failing.Exec(chainCtx, time.Now(), "x", false, &taskengine.ToolsCall{Name: "nws", ToolName: "forecast"})
+	require.Error(t, err)
+
+	stats, err := toolsproviderservice.New(db, nil, nil).Stats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	forecast := stats[0]
+	assert.Equal(t, "forecast", forecast.Tool)
+	assert.Equal(t, int64(4), forecast.Calls)
+	assert.Equal(t, int64(1), forecast.Errors)
+	assert.InDelta(t, 0.25, forecast.ErrorRate(), 0.001)
+	assert.Equal(t, map[string]int64{"weather": 4}, forecast.Chains)
+	assert.Equal(t, "missing required argument: city", forecast.LastError)
+	assert.Equal(t, "add", stats[1].Tool)
+	assert.Empty(t, stats[1].Chains)
+
+	assert.Equal(t, "chain:weather(4) model:b(2) model:a(1)", formatUsageCounts(forecast.Chains, map[string]int64{"a": 1, "b": 2}))
+	assert.Equal(t, "-", formatUsageCounts(nil, nil))
+}
@@ -0,0 +1,170 @@
+// vars_cmd.go — contenox vars: audit the {{var:NAME}} template variables of a chain.
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/clikv"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/spf13/cobra"
+)
+
+// errTemplateVarsMissing is returned when a chain references template
+// variables that are not set or empty.
+var errTemplateVarsMissing = errors.New("unresolved template variables")
+
+// templateVarsFromEnvKey is the config key listing the environment variables
+// exposed to chains as {{var:NAME}}.
+const templateVarsFromEnvKey = "template-vars-from-env"
+
+var varsCmd = &cobra.Command{
+	Use:   "vars [chain]",
+	Short: "Show the template variables a chain references and whether they are set.",
+	Long: `Lists every {{var:NAME}} a chain references, where its value comes from and
+whether it is set:
+
+  builtin  model, provider and chain, set by contenox for every run
+  env      listed in 'contenox config set template-vars-from-env' and set in the environment
+
+Variables that are not set, or set to an empty string, are reported as
+missing or empty; the command then exits non-zero. Without an argument the
+chain of 'contenox run' (.contenox/default-run-chain.json) is checked.
+
+Use 'contenox run --require-all' to refuse to run a chain with unresolved
+variables instead of rendering them as empty text.
+
+Examples:
+  contenox vars .contenox/review.yaml
+  contenox config set template-vars-from-env API_BASE,TEAM
+  TEAM=platform contenox vars .contenox/review.yaml`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contenoxDir, err := ResolveContenoxDir(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to resolve .contenox dir: %w", err)
+		}
+		chainPath := filepath.Join(contenoxDir, "default-run-chain.json")
+		if len(args) == 1 {
+			chainPath = args[0]
+		}
+		data, err := os.ReadFile(chainPath)
+		if err != nil {
+			return fmt.Errorf("failed to read chain %q: %w", chainPath, err)
+		}
+		var chain taskengine.TaskChainDefinition
+		if err := unmarshalChain(chainPath, data, &chain); err != nil {
+			return fmt.Errorf("failed to parse chain %q: %w", chainPath, err)
+		}
+
+		dbPath, err := resolveDBPath(cmd)
+		if err != nil {
+			return fmt.Errorf("invalid database path: %w", err)
+		}
+		ctx := libtracker.WithNewRequestID(context.Background())
+		db, err := OpenDBAt(ctx, dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+		o := buildRunOpts(cmd, db, contenoxDir)
+		store := runtimetypes.New(db.WithoutTransaction())
+		envNames := templateVarsFromEnv(ctx, store, ResolveWorkspaceID(contenoxDir))
+		vars := buildTemplateVars(o, chain.ID, envNames)
+
+		refs := taskengine.TemplateVarRefs(&chain)
+		if len(refs) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s references no template variables.\n", chainPath)
+			return nil
+		}
+		var names []string
+		usedBy := map[string][]string{}
+		for _, ref := range refs {
+			if _, ok := usedBy[ref.Name]; !ok {
+				names = append(names, ref.Name)
+			}
+			where := ref.Field
+			if ref.TaskID != "" {
+				where = ref.TaskID + "." + ref.Field
+			}
+			usedBy[ref.Name] = append(usedBy[ref.Name], where)
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VAR\tSOURCE\tSTATUS\tUSED BY")
+		for _, name := range names {
+			source := "-"
+			switch {
+			case slices.Contains(builtinTemplateVars, name):
+				source = "builtin"
+			case slices.Contains(envNames, name):
+				source = "env"
+			}
+			value, ok := vars[name]
+			status := "set"
+			switch {
+			case !ok:
+				status = "missing"
+			case value == "":
+				status = "empty"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, source, status, strings.Join(usedBy[name], ", "))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if missing := taskengine.UnresolvedTemplateVars(&chain, vars); len(missing) > 0 {
+			return fmt.Errorf("%w: %s", errTemplateVarsMissing, strings.Join(missing, ", "))
+		}
+		return nil
+	},
+}
+
+// builtinTemplateVars are the template variables every CLI run sets.
+var builtinTemplateVars = []string{"model", "provider", "chain"}
+
+// templateVarsFromEnv returns the environment variable names configured with
+// template-vars-from-env.
+func templateVarsFromEnv(ctx context.Context, store runtimetypes.Store, workspaceID string) []string {
+	raw, _ := clikv.ReadConfig(ctx, store, workspaceID, templateVarsFromEnvKey)
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// buildTemplateVars returns the {{var:NAME}} values of a CLI run: the builtin
+// model, provider and chain, plus the listed environment variables that are
+// set. Builtins take precedence over environment variables of the same name.
+func buildTemplateVars(o chatOpts, chainID string, envNames []string) map[string]string {
+	vars := map[string]string{}
+	for _, name := range envNames {
+		if value, ok := os.LookupEnv(name); ok {
+			vars[name] = value
+		}
+	}
+	vars["model"] = o.EffectiveDefaultModel
+	vars["provider"] = o.EffectiveDefaultProvider
+	vars["chain"] = chainID
+	return vars
+}
+
+// requireTemplateVars fails when chain references template variables that
+// vars leaves unset or empty.
+func requireTemplateVars(chain *taskengine.TaskChainDefinition, vars map[string]string) error {
+	if missing := taskengine.UnresolvedTemplateVars(chain, vars); len(missing) > 0 {
+		return fmt.Errorf("%w: %s (see 'contenox vars')", errTemplateVarsMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// warmup_cmd.go — contenox warmup: load models and fill the prompt cache ahead of the first real request.
+package contenoxcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// warmupFileName is the warm-up file looked up in the .contenox directory.
+const warmupFileName = "warmup.yaml"
+
+// defaultWarmupCacheTTL is how long warmed prompt responses stay cached when
+// the warm-up file sets no cache_ttl.
+const defaultWarmupCacheTTL = 24 * time.Hour
+
+// warmupConfig is the warm-up file. YAML and JSON are both accepted.
+type warmupConfig struct {
+	// CacheTTL is how long the responses of prompt tasks stay cached, e.g. "12h".
+	CacheTTL string `yaml:"cache_ttl" json:"cache_ttl"`
+	// Models are loaded with a one-token chat request each.
+	Models []string `yaml:"models" json:"models"`
+	// Runs are executed like 'contenox run'; their prompt responses are cached.
+	Runs []warmupRun `yaml:"runs" json:"runs"`
+}
+
+type warmupRun struct {
+	// Chain is a chain file, relative to the warm-up file.
+	Chain string `yaml:"chain" json:"chain"`
+	// Input is the chain input, or @path to read it from a file relative to
+	// the warm-up file.
+	Input     string `yaml:"input" json:"input"`
+	InputType string `yaml:"input_type" json:"input_type"`
+}
+
+func readWarmupConfig(path string) (*warmupConfig, time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read warm-up file: %w", err)
+	}
+	var cfg warmupConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, 0, fmt.Errorf("parse warm-up file %q: %w", path, err)
+	}
+	ttl := defaultWarmupCacheTTL
+	if cfg.CacheTTL != "" {
+		if ttl, err = time.ParseDuration(cfg.CacheTTL); err != nil || ttl <= 0 {
+			return nil, 0, fmt.Errorf("warm-up file %q: invalid cache_ttl %q", path, cfg.CacheTTL)
+		}
+	}
+	if len(cfg.Models) == 0 && len(cfg.Runs) == 0 {
+		return nil, 0, fmt.Errorf("warm-up file %q lists no models and no runs", path)
+	}
+	for i, run := range cfg.Runs {
+		if run.Chain == "" {
+			return nil, 0, fmt.Errorf("warm-up file %q: runs[%d]: chain is required", path, i)
+		}
+	}
+	return &cfg, ttl, nil
+}
+
+// modelWarmupChain returns a chain that sends a single chat message to model,
+// which makes the backend load it. Chat tasks are never served from the
+// prompt cache, so the model is contacted on every warm-up.
+func modelWarmupChain(model string) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "warmup-model",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:            "load",
+			Handler:       taskengine.HandleChatCompletion,
+			ExecuteConfig: &taskengine.LLMExecutionConfig{Model: model, MaxTokens: 1},
+			Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+			},
+		}},
+	}
+}
+
+var warmupCmd = &cobra.Command{
+	Use:   "warmup [file]",
+	Short: "Load models and fill the prompt cache before the first real request.",
+	Long: `Runs the warm-up file (default .contenox/warmup.yaml) so the first requests
+after a restart or deployment do not pay for cold models and empty caches:
+
+  cache_ttl: 12h                 # how long warmed prompt responses are reused (default 24h)
+  models:                        # each is loaded with a one-token chat request
+    - qwen2.5:7b
+  runs:                          # executed like 'contenox run', prompt responses are cached
+    - chain: review.yaml         # relative to the warm-up file
+      input: "@samples/main.go"
+      input_type: string
+
+Runs use the prompt cache of 'contenox run --cache-ttl': a later run with the
+same --cache-ttl reuses the warmed responses. Nobody is asked to approve tool
+calls: calls that --hitl or confirm-tools would ask about are denied. The
+command exits non-zero if any model or run fails.
+
+Examples:
+  contenox warmup
+  contenox warmup deploy/warmup.yaml`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		contenoxDir, err := ResolveContenoxDir(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to resolve .contenox dir: %w", err)
+		}
+		path := filepath.Join(contenoxDir, warmupFileName)
+		if len(args) == 1 {
+			path = args[0]
+		}
+		cfg, ttl, err := readWarmupConfig(path)
+		if err != nil {
+			return err
+		}
+
+		dbPath, err := resolveDBPath(cmd)
+		if err != nil {
+			return fmt.Errorf("invalid database path: %w", err)
+		}
+		db, err := OpenDBAt(libtracker.WithNewRequestID(context.Background()), dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		o := buildRunOpts(cmd, db, contenoxDir)
+		o.EffectiveDB = dbPath
+		o.Unattended = true
+		o.PromptCacheTTL = ttl
+		engine, err := BuildEngine(ctx, db, o)
+		if err != nil {
+			return fmt.Errorf("failed to build engine: %w", err)
+		}
+		defer engine.Stop()
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		envNames := templateVarsFromEnv(ctx, runtimetypes.New(db.WithoutTransaction()), ResolveWorkspaceID(contenoxDir))
+		execute := func(chain *taskengine.TaskChainDefinition, input any, inputType taskengine.DataType) error {
+			execCtx := taskengine.WithTemplateVars(libtracker.WithNewRequestID(ctx), buildTemplateVars(o, chain.ID, envNames))
+			execCtx, cancel := context.WithTimeout(execCtx, timeout)
+			defer cancel()
+			_, _, _, err := engine.TaskService.Execute(execCtx, chain, input, inputType)
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		failed, total := 0, len(cfg.Models)+len(cfg.Runs)
+		for _, model := range cfg.Models {
+			input, inputType, _ := parseRunInput("Reply with OK.", "chat")
+			started := time.Now()
+			err := execute(modelWarmupChain(model), input, inputType)
+			failed += reportWarmup(out, "model "+model, started, err)
+		}
+		dir := filepath.Dir(path)
+		for _, run := range cfg.Runs {
+			started := time.Now()
+			err := runWarmupChain(dir, run, execute)
+			failed += reportWarmup(out, "run "+run.Chain, started, err)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d warm-up items failed", failed, total)
+		}
+		return nil
+	},
+}
+
+// runWarmupChain loads the chain and input of run, resolving paths against
+// dir, and executes it.
+func runWarmupChain(dir string, run warmupRun, execute func(*taskengine.TaskChainDefinition, any, taskengine.DataType) error) error {
+	chainPath := run.Chain
+	if !filepath.IsAbs(chainPath) {
+		chainPath = filepath.Join(dir, chainPath)
+	}
+	data, err := os.ReadFile(chainPath)
+	if err != nil {
+		return fmt.Errorf("read chain: %w", err)
+	}
+	var chain taskengine.TaskChainDefinition
+	if err := unmarshalChain(chainPath, data, &chain); err != nil {
+		return fmt.Errorf("parse chain: %w", err)
+	}
+	raw := run.Input
+	if name, ok := strings.CutPrefix(raw, "@"); ok && name != "" {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("read input: %w", err)
+		}
+		raw = string(content)
+	}
+	input, inputType, err := parseRunInput(raw, run.InputType)
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	return execute(&chain, input, inputType)
+}
+
+// reportWarmup prints the outcome of one warm-up item and returns 1 if it failed.
+func reportWarmup(w io.Writer, item string, started time.Time, err error) int {
+	if err != nil {
+		fmt.Fprintf(w, "failed  %s: %v\n", item, err)
+		return 1
+	}
+	fmt.Fprintf(w, "ok      %s (%s)\n", item, formatDuration(time.Since(started)))
+	return 0
+}
@@ -0,0 +1,67 @@
+package contenoxcli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/hitlservice"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup_ConfigAndRuns(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	write("review.yaml", "id: review\ntasks:\n  - id: answer\n    handler: noop\n")
+	write("samples/main.go", "package main\n")
+	path := write("warmup.yaml", `
+cache_ttl: 2h
+models: [qwen2.5:7b]
+runs:
+  - chain: review.yaml
+    input: "@samples/main.go"
+`)
+
+	cfg, ttl, err := readWarmupConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, ttl)
+	assert.Equal(t, []string{"qwen2.5:7b"}, cfg.Models)
+	require.Len(t, cfg.Runs, 1)
+
+	var gotChain string
+	var gotInput any
+	err = runWarmupChain(dir, cfg.Runs[0], func(chain *taskengine.TaskChainDefinition, input any, _ taskengine.DataType) error {
+		gotChain, gotInput = chain.ID, input
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "review", gotChain)
+	assert.Equal(t, "package main\n", gotInput)
+
+	_, ttl, err = readWarmupConfig(write("models.yaml", "models: [llama3]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, defaultWarmupCacheTTL, ttl)
+
+	_, _, err = readWarmupConfig(write("empty.yaml", "cache_ttl: 1h\n"))
+	assert.ErrorContains(t, err, "no models and no runs")
+	_, _, err = readWarmupConfig(write("bad.yaml", "cache_ttl: soon\nmodels: [llama3]\n"))
+	assert.ErrorContains(t, err, "invalid cache_ttl")
+}
+
+func TestWarmup_DeniesApprovals(t *testing.T) {
+	var out bytes.Buffer
+	ok, err := newDenyApproval(&out)(context.Background(), hitlservice.ApprovalRequest{ToolsName: "local_shell", ToolName: "local_shell"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, out.String(), "denied local_shell.local_shell")
+}
@@ -0,0 +1,127 @@
+package execservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// ExecutionLogStore persists finished executions; runtimetypes.Store
+// satisfies it.
+type ExecutionLogStore interface {
+	AppendExecutionLog(ctx context.Context, entry *runtimetypes.ExecutionLogEntry) error
+}
+
+// inputPreviewLen is the number of characters of the input kept in the log.
+const inputPreviewLen = 120
+
+// executionLogTimeout bounds the log write so a slow store cannot stall callers.
+const executionLogTimeout = 2 * time.Second
+
+type executionLogDecorator struct {
+	service  TasksEnvService
+	store    ExecutionLogStore
+	redactor *taskengine.Redactor
+}
+
+// EnvWithExecutionLog records every chain execution of service in store: the
+// chain, a digest and preview of the input, the outcome, the duration and the
+// token usage. The preview and error are masked with redactor, which may be
+// nil. Recording is best-effort and never fails the execution.
+func EnvWithExecutionLog(service TasksEnvService, store ExecutionLogStore, redactor *taskengine.Redactor) TasksEnvService {
+	return &executionLogDecorator{service: service, store: store, redactor: redactor}
+}
+
+func (d *executionLogDecorator) Execute(ctx context.Context, chain *taskengine.TaskChainDefinition, input any, inputType taskengine.DataType) (any, taskengine.DataType, []taskengine.CapturedStateUnit, error) {
+	started := time.Now()
+	result, outputType, history, err := d.service.Execute(ctx, chain, input, inputType)
+
+	usage := taskengine.ChainCost(history)
+	entry := &runtimetypes.ExecutionLogEntry{
+		Status:         runtimetypes.ExecutionSucceeded,
+		Steps:          len(history),
+		InputTokens:    int64(usage.InputTokens),
+		OutputTokens:   int64(usage.OutputTokens),
+		Cost:           usage.Cost,
+		StartedAt:      started,
+		FinishedAt:     time.Now(),
+		PromptVariants: promptVariants(history),
+	}
+	if chain != nil {
+		entry.ChainID = chain.ID
+	}
+	entry.InputDigest, entry.InputPreview = digestInput(input, d.redactor)
+	if err != nil {
+		entry.Status = runtimetypes.ExecutionFailed
+		entry.Error = d.redactor.Redact(err.Error())
+	}
+	logCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), executionLogTimeout)
+	defer cancel()
+	_ = d.store.AppendExecutionLog(logCtx, entry)
+
+	return result, outputType, history, err
+}
+
+func (d *executionLogDecorator) Supports(ctx context.Context) ([]string, error) {
+	return d.service.Supports(ctx)
+}
+
+// promptVariants returns the prompt variants drawn in history, one per task
+// visit with the transition of its last attempt.
+func promptVariants(history []taskengine.CapturedStateUnit) []runtimetypes.ExecutionPromptVariant {
+	var variants []runtimetypes.ExecutionPromptVariant
+	for _, step := range history {
+		if step.PromptVariant == "" {
+			continue
+		}
+		pv := runtimetypes.ExecutionPromptVariant{TaskID: step.TaskID, Variant: step.PromptVariant, Transition: step.Transition}
+		if step.Error.Error != "" {
+			pv.Transition = "failed"
+		}
+		// Retries of a visit replace the outcome of the attempt before.
+		if n := len(variants); n > 0 && step.Attempt > 1 && variants[n-1].TaskID == step.TaskID {
+			variants[n-1] = pv
+			continue
+		}
+		variants = append(variants, pv)
+	}
+	return variants
+}
+
+// digestInput returns the hex SHA-256 and a one-line preview of input. Chat
+// histories are previewed by their last message. The digest covers the input as
+// given; the preview is masked with redactor before it is cut.
+func digestInput(input any, redactor *taskengine.Redactor) (string, string) {
+	var text string
+	switch v := input.(type) {
+	case string:
+		text = v
+	case taskengine.ChatHistory:
+		if n := len(v.Messages); n > 0 {
+			text = v.Messages[n-1].Content
+		}
+	default:
+		text = fmt.Sprintf("%v", v)
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		data = []byte(text)
+	}
+	sum := sha256.Sum256(data)
+
+	preview := strings.Join(strings.Fields(redactor.Redact(text)), " ")
+	if utf8.RuneCountInString(preview) > inputPreviewLen {
+		preview = string([]rune(preview)[:inputPreviewLen-1]) + "…"
+	}
+	return hex.EncodeToString(sum[:]), preview
+}
+
+var _ TasksEnvService = (*executionLogDecorator)(nil)
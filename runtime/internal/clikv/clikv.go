@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/contenox/contenox/runtime/runtimetypes"
 )
@@ -11,8 +12,10 @@ import (
 const Prefix = "cli."
 
 var workspaceScopedKeys = map[string]bool{
-	"default-chain":    true,
-	"hitl-policy-name": true,
+	"default-chain":          true,
+	"hitl-policy-name":       true,
+	"template-vars-from-env": true,
+	"confirm-tools-allow":    true,
 }
 
 func Read(ctx context.Context, store runtimetypes.Store, key string) string {
@@ -66,3 +69,22 @@ func WriteConfig(ctx context.Context, store runtimetypes.Store, workspaceID, key
 	}
 	return store.SetKV(ctx, Prefix+key, json.RawMessage(data))
 }
+
+// ScheduleConfig returns a pending change that performs WriteConfig(key, value)
+// at applyAt once stored with runtimetypes.Store.CreateScheduledChange.
+func ScheduleConfig(workspaceID, key, value string, applyAt time.Time) (*runtimetypes.ScheduledChange, error) {
+	data, err := json.Marshal(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	change := &runtimetypes.ScheduledChange{
+		Kind:    runtimetypes.ChangeSetKV,
+		Target:  Prefix + key,
+		Value:   string(data),
+		ApplyAt: applyAt,
+	}
+	if workspaceScopedKeys[key] {
+		change.WorkspaceID = workspaceID
+	}
+	return change, nil
+}
@@ -0,0 +1,65 @@
+package llmrepo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrDimensionMismatch is returned when a provider returns an embedding whose
+// dimension differs from the one declared for the model.
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// DimensionMismatchError reports the declared and returned dimension of an
+// embedding. It unwraps to ErrDimensionMismatch.
+type DimensionMismatchError struct {
+	Model    string
+	Expected int
+	Got      int
+}
+
+func (e *DimensionMismatchError) Error() string {
+	return fmt.Sprintf("%s: model %q returned %d dimensions, expected %d", ErrDimensionMismatch, e.Model, e.Got, e.Expected)
+}
+
+func (e *DimensionMismatchError) Unwrap() error { return ErrDimensionMismatch }
+
+// checkEmbedding validates embedding against the declared dimension and
+// projects it to target. A zero declared or target dimension skips that step.
+func checkEmbedding(model string, embedding []float64, declared, target int) ([]float64, error) {
+	if declared > 0 && len(embedding) != declared {
+		return nil, &DimensionMismatchError{Model: model, Expected: declared, Got: len(embedding)}
+	}
+	if target <= 0 || target == len(embedding) {
+		return embedding, nil
+	}
+	return ProjectEmbedding(embedding, target)
+}
+
+// ProjectEmbedding truncates embedding to its first dims components and
+// rescales the result to unit length. This is the projection for models
+// trained with Matryoshka representation learning, whose leading components
+// form a usable lower-dimensional embedding. An embedding shorter than dims
+// cannot be projected and returns a DimensionMismatchError.
+func ProjectEmbedding(embedding []float64, dims int) ([]float64, error) {
+	if dims <= 0 {
+		return nil, fmt.Errorf("target dimension must be positive, got %d", dims)
+	}
+	if len(embedding) < dims {
+		return nil, &DimensionMismatchError{Expected: dims, Got: len(embedding)}
+	}
+	out := make([]float64, dims)
+	copy(out, embedding[:dims])
+	var norm float64
+	for _, v := range out {
+		norm += v * v
+	}
+	if norm == 0 {
+		return out, nil
+	}
+	norm = math.Sqrt(norm)
+	for i := range out {
+		out[i] /= norm
+	}
+	return out, nil
+}
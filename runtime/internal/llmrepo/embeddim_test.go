@@ -0,0 +1,35 @@
+package llmrepo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUnit_CheckEmbedding(t *testing.T) {
+	_, err := checkEmbedding("nomic-embed-text", []float64{1, 2, 3}, 768, 0)
+	var mismatch *DimensionMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("expected DimensionMismatchError, got %v", err)
+	}
+	if mismatch.Expected != 768 || mismatch.Got != 3 || mismatch.Model != "nomic-embed-text" {
+		t.Fatalf("unexpected mismatch %+v", mismatch)
+	}
+
+	got, err := checkEmbedding("m", []float64{3, 4, 12}, 3, 0)
+	if err != nil || len(got) != 3 || got[2] != 12 {
+		t.Fatalf("unchanged embedding expected, got %v, %v", got, err)
+	}
+
+	got, err = checkEmbedding("m", []float64{3, 4, 12}, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || math.Abs(got[0]-0.6) > 1e-9 || math.Abs(got[1]-0.8) > 1e-9 {
+		t.Fatalf("expected normalized projection [0.6 0.8], got %v", got)
+	}
+
+	if _, err := checkEmbedding("m", []float64{1, 2}, 0, 4); !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("projecting to a larger dimension must fail, got %v", err)
+	}
+}
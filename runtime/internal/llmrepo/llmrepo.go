@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/contenox/contenox/runtime/internal/llmresolver"
 	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
@@ -27,8 +28,21 @@ type EmbedRequest struct {
 	ModelName    string
 	ProviderType string
 	Tracker      libtracker.ActivityTracker
+	// Dimensions is the embedding dimension declared for the model. When set,
+	// an embedding of any other size fails with a DimensionMismatchError.
+	Dimensions int
+	// TargetDimensions projects the embedding to this size with
+	// ProjectEmbedding. Zero returns the embedding as produced.
+	TargetDimensions int
+	// BatchSize is the number of inputs EmbedBatch sends per backend request.
+	// Zero uses DefaultEmbedBatchSize.
+	BatchSize int
 }
 
+// DefaultEmbedBatchSize is the number of inputs EmbedBatch sends per backend
+// request when EmbedRequest.BatchSize is not set.
+const DefaultEmbedBatchSize = 32
+
 type Meta struct {
 	ModelName    string `json:"model_name"`
 	ProviderType string `json:"provider_type"`
@@ -42,6 +56,7 @@ type ModelRepo interface {
 		ctx context.Context,
 		req Request,
 		systeminstruction string, temperature float32, prompt string,
+		opts ...libmodelprovider.ChatArgument,
 	) (string, Meta, error)
 	Chat(
 		ctx context.Context,
@@ -53,6 +68,14 @@ type ModelRepo interface {
 		embedReq EmbedRequest,
 		prompt string,
 	) ([]float64, Meta, error)
+	// EmbedBatch embeds prompts with one model and returns one vector per
+	// prompt, in order. Backends that accept several inputs per request get
+	// them in batches of EmbedRequest.BatchSize; others are called per prompt.
+	EmbedBatch(
+		ctx context.Context,
+		embedReq EmbedRequest,
+		prompts []string,
+	) ([][]float64, Meta, error)
 	Stream(
 		ctx context.Context,
 		req Request,
@@ -74,8 +97,52 @@ type modelManager struct {
 	config    ModelManagerConfig
 	mu        sync.RWMutex
 	tracker   libtracker.ActivityTracker
+	usage     UsageRecorder
+}
+
+// UsageRecorder persists per-backend, per-model call statistics.
+// runtimetypes.Store satisfies it.
+type UsageRecorder interface {
+	RecordModelUsage(ctx context.Context, backendID, modelName string, latency time.Duration, failed bool, at time.Time) error
+}
+
+// Option configures optional modelManager behaviour.
+type Option func(*modelManager)
+
+// WithUsageRecorder records request counts, failures and latency of every
+// resolved model call. Recording is best-effort and never fails the call.
+func WithUsageRecorder(recorder UsageRecorder) Option {
+	return func(m *modelManager) {
+		m.usage = recorder
+	}
+}
+
+// CallGate is called with the type of the resolved provider before every
+// model call, and before every batch of EmbedBatch. An error aborts the call
+// and is returned by the ModelRepo method.
+type CallGate func(ctx context.Context, providerType string) error
+
+type callGateKey struct{}
+
+// WithCallGate makes model calls made with the returned context pass gate
+// first, e.g. to wait for a rate limit of the provider serving the call.
+func WithCallGate(ctx context.Context, gate CallGate) context.Context {
+	return context.WithValue(ctx, callGateKey{}, gate)
+}
+
+// PassCallGate runs the CallGate of ctx, if any, for a call to providerType.
+// ModelRepo implementations call it after resolving the provider.
+func PassCallGate(ctx context.Context, providerType string) error {
+	gate, _ := ctx.Value(callGateKey{}).(CallGate)
+	if gate == nil {
+		return nil
+	}
+	return gate(ctx, providerType)
 }
 
+// usageRecordTimeout bounds the usage write so a slow store cannot stall callers.
+const usageRecordTimeout = 2 * time.Second
+
 type ModelConfig struct {
 	Name     string
 	Provider string
@@ -87,7 +154,7 @@ type ModelManagerConfig struct {
 	DefaultChatModel      ModelConfig
 }
 
-func NewModelManager(runtime *runtimestate.State, tokenizer ollamatokenizer.Tokenizer, config ModelManagerConfig, tracker libtracker.ActivityTracker) (*modelManager, error) {
+func NewModelManager(runtime *runtimestate.State, tokenizer ollamatokenizer.Tokenizer, config ModelManagerConfig, tracker libtracker.ActivityTracker, opts ...Option) (*modelManager, error) {
 	if runtime == nil {
 		return nil, errors.New("runtime cannot be nil")
 	}
@@ -97,12 +164,36 @@ func NewModelManager(runtime *runtimestate.State, tokenizer ollamatokenizer.Toke
 	if tracker == nil {
 		tracker = libtracker.NoopTracker{}
 	}
-	return &modelManager{
+	m := &modelManager{
 		runtime:   runtime,
 		tokenizer: tokenizer,
 		config:    config,
 		tracker:   tracker,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// recordUsage feeds the outcome of a call made against backendID into the
+// runtime health statistics and stores it with the usage recorder. It runs
+// detached from ctx cancellation so aborted requests are still counted.
+func (e *modelManager) recordUsage(ctx context.Context, backendID, modelName string, start time.Time, callErr error) {
+	if backendID == "" {
+		return
+	}
+	e.runtime.RecordCall(backendID, time.Since(start), callErr != nil)
+	if e.usage == nil {
+		return
+	}
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), usageRecordTimeout)
+	defer cancel()
+	if err := e.usage.RecordModelUsage(recordCtx, backendID, modelName, time.Since(start), callErr != nil, time.Now().UTC()); err != nil {
+		reportErr, _, end := e.tracker.Start(ctx, "record", "model_usage", "backend_id", backendID, "model_name", modelName)
+		reportErr(err)
+		end()
+	}
 }
 
 func (e *modelManager) Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error) {
@@ -145,6 +236,7 @@ func (e *modelManager) PromptExecute(
 	ctx context.Context,
 	req Request,
 	systemInstruction string, temperature float32, prompt string,
+	opts ...libmodelprovider.ChatArgument,
 ) (string, Meta, error) {
 	if err := validateRequest(req); err != nil {
 		return "", Meta{}, fmt.Errorf("invalid request: %w", err)
@@ -171,7 +263,12 @@ func (e *modelManager) PromptExecute(
 	}
 	defer safeClose(client)
 
-	result, err := client.Prompt(ctx, systemInstruction, temperature, prompt)
+	if err := PassCallGate(ctx, provider.GetType()); err != nil {
+		return "", Meta{}, err
+	}
+	start := time.Now()
+	result, err := client.Prompt(ctx, systemInstruction, temperature, prompt, opts...)
+	e.recordUsage(ctx, backend, provider.ModelName(), start, err)
 	if err != nil {
 		return "", Meta{}, fmt.Errorf("prompt execution failed: %w", err)
 	}
@@ -218,7 +315,12 @@ func (e *modelManager) Chat(
 	}
 	defer safeClose(client)
 
+	if err := PassCallGate(ctx, provider.GetType()); err != nil {
+		return libmodelprovider.ChatResult{}, Meta{}, err
+	}
+	start := time.Now()
 	response, err := client.Chat(ctx, messages, opts...)
+	e.recordUsage(ctx, backend, provider.ModelName(), start, err)
 	if err != nil {
 		return libmodelprovider.ChatResult{}, Meta{}, fmt.Errorf("chat execution failed: %w", err)
 	}
@@ -239,7 +341,115 @@ func (e *modelManager) Embed(
 	if prompt == "" {
 		return nil, Meta{}, errors.New("prompt cannot be empty")
 	}
+	client, provider, backend, embedReq, err := e.resolveEmbed(ctx, embedReq)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	defer safeClose(client)
+
+	if err := PassCallGate(ctx, provider.GetType()); err != nil {
+		return nil, Meta{}, err
+	}
+	start := time.Now()
+	embeddings, err := client.Embed(ctx, prompt)
+	e.recordUsage(ctx, backend, provider.ModelName(), start, err)
+	if err != nil {
+		return nil, Meta{}, fmt.Errorf("embedding generation failed: %w", err)
+	}
+	embeddings, err = checkEmbedding(provider.ModelName(), embeddings, embedReq.Dimensions, embedReq.TargetDimensions)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+
+	meta := Meta{
+		ModelName:    provider.ModelName(),
+		ProviderType: provider.GetType(),
+		BackendID:    backend,
+	}
+	return embeddings, meta, nil
+}
+
+func (e *modelManager) EmbedBatch(
+	ctx context.Context,
+	embedReq EmbedRequest,
+	prompts []string,
+) ([][]float64, Meta, error) {
+	if len(prompts) == 0 {
+		return nil, Meta{}, errors.New("prompts cannot be empty")
+	}
+	for i, prompt := range prompts {
+		if prompt == "" {
+			return nil, Meta{}, fmt.Errorf("prompt %d cannot be empty", i)
+		}
+	}
+	if embedReq.BatchSize < 0 {
+		return nil, Meta{}, errors.New("embedding batch size must be non-negative")
+	}
+	client, provider, backend, embedReq, err := e.resolveEmbed(ctx, embedReq)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	defer safeClose(client)
+
+	batchSize := embedReq.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultEmbedBatchSize
+	}
+	batchClient, canBatch := client.(libmodelprovider.LLMBatchEmbedClient)
+	embeddings := make([][]float64, 0, len(prompts))
+	for len(prompts) > 0 {
+		n := min(batchSize, len(prompts))
+		batch := prompts[:n]
+		prompts = prompts[n:]
+
+		if err := PassCallGate(ctx, provider.GetType()); err != nil {
+			return nil, Meta{}, err
+		}
+		start := time.Now()
+		var vecs [][]float64
+		if canBatch {
+			vecs, err = batchClient.EmbedBatch(ctx, batch)
+		} else {
+			vecs, err = embedEach(ctx, client, batch)
+		}
+		e.recordUsage(ctx, backend, provider.ModelName(), start, err)
+		if err != nil {
+			return nil, Meta{}, fmt.Errorf("embedding generation failed: %w", err)
+		}
+		for _, vec := range vecs {
+			vec, err = checkEmbedding(provider.ModelName(), vec, embedReq.Dimensions, embedReq.TargetDimensions)
+			if err != nil {
+				return nil, Meta{}, err
+			}
+			embeddings = append(embeddings, vec)
+		}
+	}
+
+	meta := Meta{
+		ModelName:    provider.ModelName(),
+		ProviderType: provider.GetType(),
+		BackendID:    backend,
+	}
+	return embeddings, meta, nil
+}
+
+// embedEach embeds prompts one request at a time, for clients without batch
+// support.
+func embedEach(ctx context.Context, client libmodelprovider.LLMEmbedClient, prompts []string) ([][]float64, error) {
+	vecs := make([][]float64, len(prompts))
+	for i, prompt := range prompts {
+		vec, err := client.Embed(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+		vecs[i] = vec
+	}
+	return vecs, nil
+}
 
+// resolveEmbed applies the default embedding model to embedReq and resolves
+// a client for it. The returned request carries the applied defaults.
+func (e *modelManager) resolveEmbed(ctx context.Context, embedReq EmbedRequest) (libmodelprovider.LLMEmbedClient, libmodelprovider.Provider, string, EmbedRequest, error) {
 	runtimeStateResolution := e.GetRuntime(ctx)
 
 	// Apply defaults if not provided
@@ -249,6 +459,9 @@ func (e *modelManager) Embed(
 	if embedReq.ProviderType == "" {
 		embedReq.ProviderType = e.config.DefaultEmbeddingModel.Provider
 	}
+	if embedReq.Dimensions < 0 || embedReq.TargetDimensions < 0 {
+		return nil, nil, "", embedReq, errors.New("embedding dimensions must be non-negative")
+	}
 
 	resolverReq := e.convertToResolverEmbedRequest(embedReq)
 	client, provider, backend, err := llmresolver.Embed(ctx,
@@ -257,21 +470,9 @@ func (e *modelManager) Embed(
 		llmresolver.Randomly,
 	)
 	if err != nil {
-		return nil, Meta{}, fmt.Errorf("embed: client resolution failed: %w", err)
-	}
-	defer safeClose(client)
-
-	embeddings, err := client.Embed(ctx, prompt)
-	if err != nil {
-		return nil, Meta{}, fmt.Errorf("embedding generation failed: %w", err)
-	}
-
-	meta := Meta{
-		ModelName:    provider.ModelName(),
-		ProviderType: provider.GetType(),
-		BackendID:    backend,
+		return nil, nil, "", embedReq, fmt.Errorf("embed: client resolution failed: %w", err)
 	}
-	return embeddings, meta, nil
+	return client, provider, backend, embedReq, nil
 }
 
 func (e *modelManager) Stream(
@@ -308,8 +509,14 @@ func (e *modelManager) Stream(
 		return nil, Meta{}, fmt.Errorf("stream: client resolution failed: %w", err)
 	}
 
+	if err := PassCallGate(ctx, provider.GetType()); err != nil {
+		safeClose(client)
+		return nil, Meta{}, err
+	}
+	start := time.Now()
 	stream, err := client.Stream(ctx, messages, opts...)
 	if err != nil {
+		e.recordUsage(ctx, backend, provider.ModelName(), start, err)
 		safeClose(client)
 		return nil, Meta{}, fmt.Errorf("stream initialization failed: %w", err)
 	}
@@ -320,12 +527,15 @@ func (e *modelManager) Stream(
 		defer close(wrappedStream)
 		defer safeClose(client)
 
+		var streamErr error
 		for parcel := range stream {
 			wrappedStream <- parcel
 			if parcel.Error != nil {
+				streamErr = parcel.Error
 				break
 			}
 		}
+		e.recordUsage(ctx, backend, provider.ModelName(), start, streamErr)
 	}()
 
 	meta := Meta{
@@ -0,0 +1,156 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+const defaultBaseURL = "https://api.anthropic.com/v1"
+
+// defaultContextLength is the context window of Claude models whose listing
+// does not report one.
+const defaultContextLength = 200000
+
+type catalogProvider struct {
+	spec       modelrepo.BackendSpec
+	httpClient *http.Client
+	tracker    libtracker.ActivityTracker
+}
+
+func init() {
+	modelrepo.RegisterCatalogProvider("anthropic", func(spec modelrepo.BackendSpec, opts modelrepo.CatalogOptions) (modelrepo.CatalogProvider, error) {
+		return &catalogProvider{
+			spec:       spec,
+			httpClient: opts.HTTPClient,
+			tracker:    opts.Tracker,
+		}, nil
+	})
+}
+
+func (p *catalogProvider) Type() string {
+	return "anthropic"
+}
+
+func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedModel, error) {
+	listing, err := p.ListModelsConditional(ctx, modelrepo.CatalogValidators{})
+	if err != nil {
+		return nil, err
+	}
+	return listing.Models, nil
+}
+
+// ListModelsConditional lists models page by page, sending validators as
+// conditional request headers with the first page so an unchanged catalog is
+// answered with 304 Not Modified.
+func (p *catalogProvider) ListModelsConditional(ctx context.Context, validators modelrepo.CatalogValidators) (modelrepo.CatalogListing, error) {
+	client := anthropicClient{apiKey: p.spec.APIKey, baseURL: p.baseURL(), httpClient: p.httpClient}
+	listing := modelrepo.CatalogListing{}
+	afterID := ""
+	for {
+		query := url.Values{"limit": {"1000"}}
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+		req, err := client.newRequest(ctx, http.MethodGet, "/models?"+query.Encode(), nil)
+		if err != nil {
+			return modelrepo.CatalogListing{}, err
+		}
+		if afterID == "" {
+			validators.Apply(req)
+		}
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return modelrepo.CatalogListing{}, err
+		}
+		if afterID == "" {
+			listing.Validators, listing.MaxAge = modelrepo.CatalogCacheInfo(resp)
+			if resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				listing.NotModified = true
+				return listing, nil
+			}
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return modelrepo.CatalogListing{}, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return modelrepo.CatalogListing{}, fmt.Errorf("Anthropic catalog returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		var payload struct {
+			Data []struct {
+				ID             string    `json:"id"`
+				CreatedAt      time.Time `json:"created_at"`
+				MaxInputTokens int       `json:"max_input_tokens"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return modelrepo.CatalogListing{}, fmt.Errorf("decode Anthropic catalog response: %w", err)
+		}
+		for _, item := range payload.Data {
+			observed := inferObservedModel(item.ID)
+			observed.ModifiedAt = item.CreatedAt
+			if item.MaxInputTokens > 0 {
+				observed.ContextLength = item.MaxInputTokens
+				observed.CapabilityConfig.ContextLength = item.MaxInputTokens
+			}
+			listing.Models = append(listing.Models, observed)
+		}
+		if !payload.HasMore || payload.LastID == "" || payload.LastID == afterID {
+			return listing, nil
+		}
+		afterID = payload.LastID
+	}
+}
+
+func (p *catalogProvider) ProviderFor(model modelrepo.ObservedModel) modelrepo.Provider {
+	return NewAnthropicProvider(
+		p.spec.APIKey,
+		model.Name,
+		[]string{p.baseURL()},
+		model.CapabilityConfig,
+		p.httpClient,
+		p.tracker,
+	)
+}
+
+func (p *catalogProvider) baseURL() string {
+	base := strings.TrimSpace(p.spec.BaseURL)
+	if base == "" {
+		return defaultBaseURL
+	}
+	return base
+}
+
+// inferObservedModel maps a Claude model ID to its capabilities. Every
+// Claude model chats, prompts and streams; none embeds. Extended thinking is
+// supported from Claude 3.7 on.
+func inferObservedModel(id string) modelrepo.ObservedModel {
+	lower := strings.ToLower(id)
+	observed := modelrepo.ObservedModel{
+		Name:          id,
+		ContextLength: defaultContextLength,
+	}
+	observed.CapabilityConfig.ContextLength = defaultContextLength
+	observed.CanChat = true
+	observed.CanPrompt = true
+	observed.CanStream = true
+	observed.CanThink = !strings.HasPrefix(lower, "claude-3-") || strings.HasPrefix(lower, "claude-3-7-")
+	return observed
+}
+
+var _ modelrepo.ConditionalCatalogProvider = (*catalogProvider)(nil)
@@ -0,0 +1,90 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/models", r.URL.Path)
+		require.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		require.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+		w.Header().Set("Content-Type", "application/json")
+
+		// Two pages: the second is requested after the last ID of the first.
+		if r.URL.Query().Get("after_id") == "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": []map[string]any{
+					{"id": "claude-sonnet-4-5-20250929", "created_at": "2025-09-29T00:00:00Z"},
+					{"id": "claude-3-5-haiku-20241022", "created_at": "2024-10-22T00:00:00Z"},
+				},
+				"has_more": true,
+				"last_id":  "claude-3-5-haiku-20241022",
+			})
+			return
+		}
+		require.Equal(t, "claude-3-5-haiku-20241022", r.URL.Query().Get("after_id"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{
+				{"id": "claude-opus-4-1-20250805", "created_at": "2025-08-05T00:00:00Z", "max_input_tokens": 1000000},
+			},
+			"has_more": false,
+			"last_id":  "claude-opus-4-1-20250805",
+		})
+	}))
+	defer server.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{
+		Type:    "anthropic",
+		BaseURL: server.URL + "/v1",
+		APIKey:  "test-key",
+	})
+	require.NoError(t, err)
+
+	models, err := catalog.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+
+	sonnet := models[0]
+	require.Equal(t, "claude-sonnet-4-5-20250929", sonnet.Name)
+	require.Equal(t, defaultContextLength, sonnet.ContextLength)
+	require.True(t, sonnet.CanChat)
+	require.True(t, sonnet.CanPrompt)
+	require.True(t, sonnet.CanStream)
+	require.True(t, sonnet.CanThink)
+	require.False(t, sonnet.CanEmbed)
+	require.Equal(t, 2025, sonnet.ModifiedAt.Year())
+
+	require.False(t, models[1].CanThink, "Claude 3.5 has no extended thinking")
+	require.Equal(t, 1000000, models[2].ContextLength, "a reported context window wins")
+
+	provider := catalog.ProviderFor(sonnet)
+	require.Equal(t, "anthropic", provider.GetType())
+	require.Equal(t, "claude-sonnet-4-5-20250929", provider.ModelName())
+	require.Equal(t, defaultContextLength, provider.GetContextLength())
+	require.True(t, provider.CanThink())
+	_, err = provider.GetEmbedConnection(context.Background(), "")
+	require.Error(t, err)
+}
+
+func TestCatalogProvider_ListModelsNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, `"models-1"`, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"models-1"`)
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	catalog := &catalogProvider{spec: modelrepo.BackendSpec{Type: "anthropic", BaseURL: server.URL}, httpClient: server.Client()}
+	listing, err := catalog.ListModelsConditional(context.Background(), modelrepo.CatalogValidators{ETag: `"models-1"`})
+	require.NoError(t, err)
+	require.True(t, listing.NotModified)
+	require.Equal(t, `"models-1"`, listing.Validators.ETag)
+}
@@ -0,0 +1,77 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type AnthropicChatClient struct {
+	anthropicClient
+}
+
+// Chat implements modelrepo.LLMChatClient
+func (c *AnthropicChatClient) Chat(ctx context.Context, messages []modelrepo.Message, args ...modelrepo.ChatArgument) (modelrepo.ChatResult, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "chat", "anthropic", "model", c.modelName)
+	defer end()
+
+	req, err := buildAnthropicRequest(c.modelName, messages, args)
+	if err != nil {
+		reportErr(err)
+		return modelrepo.ChatResult{}, err
+	}
+
+	var resp anthropicResponse
+	if err := c.sendRequest(ctx, "/messages", req, &resp); err != nil {
+		reportErr(err)
+		return modelrepo.ChatResult{}, err
+	}
+
+	var (
+		outText      string
+		thinkingText string
+		signature    string
+		toolCalls    []modelrepo.ToolCall
+	)
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "thinking":
+			thinkingText += block.Thinking
+			if signature == "" {
+				signature = block.Signature
+			}
+		case "text":
+			outText += block.Text
+		case "tool_use":
+			tc := modelrepo.ToolCall{ID: block.ID, Type: "function"}
+			tc.Function.Name = block.Name
+			tc.Function.Arguments = string(block.Input)
+			if tc.Function.Arguments == "" {
+				tc.Function.Arguments = "{}"
+			}
+			toolCalls = append(toolCalls, tc)
+		}
+	}
+	// Keep the thinking block with the tool calls: the API requires it back
+	// together with their results.
+	if signature != "" && len(toolCalls) > 0 {
+		toolCalls[0].ProviderMeta = map[string]string{metaThinking: thinkingText, metaThinkingSignature: signature}
+	}
+
+	if outText == "" && len(toolCalls) == 0 {
+		err := fmt.Errorf("empty content from model %s: stop reason (%s)", c.modelName, resp.StopReason)
+		reportErr(err)
+		return modelrepo.ChatResult{}, err
+	}
+
+	result := modelrepo.ChatResult{
+		Message:   modelrepo.Message{Role: "assistant", Content: outText, Thinking: thinkingText},
+		ToolCalls: toolCalls,
+	}
+
+	reportChange("chat_completed", result)
+	return result, nil
+}
+
+var _ modelrepo.LLMChatClient = (*AnthropicChatClient)(nil)
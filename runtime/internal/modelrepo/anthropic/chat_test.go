@@ -0,0 +1,152 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toolCall(id, name, args string) modelrepo.ToolCall {
+	tc := modelrepo.ToolCall{ID: id, Type: "function"}
+	tc.Function.Name = name
+	tc.Function.Arguments = args
+	return tc
+}
+
+func TestBuildAnthropicRequest_MapsMessagesAndTools(t *testing.T) {
+	messages := []modelrepo.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Weather in Paris and Rome?", Attachments: []modelrepo.Attachment{{MimeType: "image/png", Data: []byte{1, 2}}}},
+		{Role: "assistant", ToolCalls: []modelrepo.ToolCall{
+			toolCall("toolu_1", "weather", `{"city":"Paris"}`),
+			toolCall("toolu_2", "weather", `not json`),
+		}},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "sunny"},
+		{Role: "tool", ToolCallID: "toolu_2", Content: "rain"},
+	}
+	req, err := buildAnthropicRequest("claude-test", messages, []modelrepo.ChatArgument{
+		modelrepo.WithTemperature(0.2),
+		modelrepo.WithTool(modelrepo.Tool{Type: "function", Function: &modelrepo.FunctionTool{Name: "weather", Description: "Look up the weather"}}),
+		modelrepo.WithToolChoice(modelrepo.ToolChoice{Name: "weather"}),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "claude-test", req.Model)
+	assert.Equal(t, "Be brief.", req.System)
+	assert.Equal(t, defaultMaxTokens, req.MaxTokens)
+	require.NotNil(t, req.Temperature)
+	assert.Equal(t, 0.2, *req.Temperature)
+	require.Len(t, req.Tools, 1)
+	assert.Equal(t, map[string]any{"type": "object", "properties": map[string]any{}}, req.Tools[0].InputSchema)
+	assert.Equal(t, &anthropicChoice{Type: "tool", Name: "weather"}, req.ToolChoice)
+
+	require.Len(t, req.Messages, 3)
+	assert.Equal(t, "user", req.Messages[0].Role)
+	require.Len(t, req.Messages[0].Content, 2)
+	assert.Equal(t, "image", req.Messages[0].Content[1].Type)
+	assert.Equal(t, "base64", req.Messages[0].Content[1].Source.Type)
+
+	assistant := req.Messages[1]
+	assert.Equal(t, "assistant", assistant.Role)
+	require.Len(t, assistant.Content, 2)
+	assert.Equal(t, "tool_use", assistant.Content[0].Type)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(assistant.Content[0].Input))
+	assert.JSONEq(t, `{}`, string(assistant.Content[1].Input))
+
+	// Both tool results go into one user turn so the roles alternate.
+	results := req.Messages[2]
+	assert.Equal(t, "user", results.Role)
+	require.Len(t, results.Content, 2)
+	assert.Equal(t, "tool_result", results.Content[0].Type)
+	assert.Equal(t, "toolu_1", results.Content[0].ToolUseID)
+	assert.Equal(t, "rain", results.Content[1].Content)
+}
+
+func TestBuildAnthropicRequest_Thinking(t *testing.T) {
+	messages := []modelrepo.Message{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", ToolCalls: []modelrepo.ToolCall{func() modelrepo.ToolCall {
+			tc := toolCall("toolu_1", "lookup", `{}`)
+			tc.ProviderMeta = map[string]string{metaThinking: "let me look", metaThinkingSignature: "sig"}
+			return tc
+		}()}},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "found"},
+	}
+	req, err := buildAnthropicRequest("claude-test", messages, []modelrepo.ChatArgument{
+		modelrepo.WithTemperature(0.2),
+		modelrepo.WithThink("medium"),
+		modelrepo.WithTool(modelrepo.Tool{Type: "function", Function: &modelrepo.FunctionTool{Name: "lookup"}}),
+		modelrepo.WithToolChoice(modelrepo.ToolChoice{Mode: modelrepo.ToolChoiceRequired}),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, req.Thinking)
+	assert.Equal(t, 8192, req.Thinking.BudgetTokens)
+	assert.Equal(t, defaultMaxTokens+8192, req.MaxTokens)
+	assert.Nil(t, req.Temperature, "temperature is rejected while thinking")
+	assert.Nil(t, req.ToolChoice, "forced tool calls are rejected while thinking")
+	assert.Contains(t, req.System, "You must respond with a call of one of the available tools.")
+
+	// The thinking block of the tool-calling turn is sent back first.
+	blocks := req.Messages[1].Content
+	require.Len(t, blocks, 2)
+	assert.Equal(t, anthropicContentBlock{Type: "thinking", Thinking: "let me look", Signature: "sig"}, blocks[0])
+	assert.Equal(t, "tool_use", blocks[1].Type)
+}
+
+func TestAnthropicChatClient_Chat(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "claude-test", req.Model)
+		_, _ = w.Write([]byte(`{"id":"msg_1","stop_reason":"tool_use","content":[
+			{"type":"thinking","thinking":"need data","signature":"sig-1"},
+			{"type":"text","text":"Checking."},
+			{"type":"tool_use","id":"toolu_9","name":"lookup","input":{"q":"x"}}]}`))
+	}))
+	defer srv.Close()
+
+	client := &AnthropicChatClient{anthropicClient: anthropicClient{
+		apiKey:     "test-key",
+		modelName:  "claude-test",
+		baseURL:    srv.URL + "/v1",
+		httpClient: srv.Client(),
+		tracker:    libtracker.NoopTracker{},
+	}}
+	result, err := client.Chat(context.Background(), []modelrepo.Message{{Role: "user", Content: "hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Checking.", result.Message.Content)
+	assert.Equal(t, "need data", result.Message.Thinking)
+	require.Len(t, result.ToolCalls, 1)
+	assert.Equal(t, "toolu_9", result.ToolCalls[0].ID)
+	assert.Equal(t, "lookup", result.ToolCalls[0].Function.Name)
+	assert.JSONEq(t, `{"q":"x"}`, result.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "sig-1", result.ToolCalls[0].ProviderMeta[metaThinkingSignature])
+}
+
+func TestAnthropicChatClient_ChatAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	defer srv.Close()
+
+	client := &AnthropicChatClient{anthropicClient: anthropicClient{
+		modelName:  "claude-test",
+		baseURL:    srv.URL,
+		httpClient: srv.Client(),
+		tracker:    libtracker.NoopTracker{},
+	}}
+	_, err := client.Chat(context.Background(), []modelrepo.Message{{Role: "user", Content: "hi"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 authentication_error - invalid x-api-key")
+}
@@ -0,0 +1,283 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// defaultMaxTokens caps the answer when the caller sets no max tokens; the
+// Messages API requires a limit.
+const defaultMaxTokens = 8192
+
+// Tool call ProviderMeta keys carrying the thinking block of an assistant turn.
+// The API requires it to be sent back with the tool results of that turn.
+const (
+	metaThinking          = "thinking"
+	metaThinkingSignature = "thinking_signature"
+)
+
+type anthropicClient struct {
+	apiKey     string
+	modelName  string
+	baseURL    string
+	httpClient *http.Client
+	tracker    libtracker.ActivityTracker
+}
+
+// newRequest creates an authenticated request to the API path endpoint.
+func (c *anthropicClient) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.baseURL, "/")+endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if c.apiKey != "" {
+		req.Header.Set("x-api-key", c.apiKey)
+	}
+	return req, nil
+}
+
+// sendRequest: shared HTTP helper for Anthropic clients
+func (c *anthropicClient) sendRequest(ctx context.Context, endpoint string, request interface{}, response interface{}) error {
+	reportErr, reportChange, end := c.tracker.Start(
+		ctx,
+		"http_request",
+		"anthropic",
+		"model", c.modelName,
+		"endpoint", endpoint,
+		"base_url", c.baseURL,
+	)
+	defer end()
+
+	b, err := json.Marshal(request)
+	if err != nil {
+		err = fmt.Errorf("failed to marshal request: %w", err)
+		reportErr(err)
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, endpoint, bytes.NewBuffer(b))
+	if err != nil {
+		err = fmt.Errorf("failed to create request: %w", err)
+		reportErr(err)
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("HTTP request failed for model %s: %w", c.modelName, err)
+		reportErr(err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	reportChange("http_response", map[string]any{
+		"status_code": resp.StatusCode,
+		"headers":     resp.Header,
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err = apiError(resp.StatusCode, body, c.modelName, req.URL.String())
+		reportErr(err)
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		err = fmt.Errorf("failed to decode response for model %s: %w", c.modelName, err)
+		reportErr(err)
+		return err
+	}
+
+	reportChange("request_completed", nil)
+	return nil
+}
+
+// apiError formats a non-200 response of the API.
+func apiError(status int, body []byte, modelName, url string) error {
+	var eresp anthropicErrorResponse
+	if err := json.Unmarshal(body, &eresp); err == nil && eresp.Error.Message != "" {
+		return fmt.Errorf("anthropic API error: %d %s - %s (model=%s url=%s)", status, eresp.Error.Type, eresp.Error.Message, modelName, url)
+	}
+	return fmt.Errorf("anthropic API error: %d - %s (model=%s url=%s)", status, string(body), modelName, url)
+}
+
+// buildAnthropicRequest converts modelrepo messages and args to a Messages
+// API request.
+func buildAnthropicRequest(modelName string, messages []modelrepo.Message, args []modelrepo.ChatArgument) (anthropicRequest, error) {
+	cfg := &modelrepo.ChatConfig{}
+	for _, a := range args {
+		a.Apply(cfg)
+	}
+
+	req := anthropicRequest{
+		Model:         modelName,
+		MaxTokens:     defaultMaxTokens,
+		StopSequences: cfg.Stop,
+	}
+	if cfg.MaxTokens != nil && *cfg.MaxTokens > 0 {
+		req.MaxTokens = *cfg.MaxTokens
+	}
+
+	if cfg.Think != nil {
+		if budget := thinkingBudget(*cfg.Think); budget > 0 {
+			// The budget counts towards max_tokens; keep room for the answer.
+			req.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: budget}
+			req.MaxTokens += budget
+			// Forcing a tool call is rejected while thinking; ask for it in
+			// the system prompt instead.
+			if c := cfg.ToolChoice; c != nil && (c.Name != "" || c.Mode == modelrepo.ToolChoiceRequired) {
+				messages = modelrepo.EmulateToolChoice(cfg, messages)
+				cfg.ToolChoice = nil
+			}
+		}
+	}
+	// Sampling parameters other than the defaults are rejected while thinking.
+	if req.Thinking == nil {
+		req.Temperature = cfg.Temperature
+		req.TopP = cfg.TopP
+		req.TopK = cfg.TopK
+	}
+
+	for _, t := range cfg.Tools {
+		if t.Type != "function" || t.Function == nil {
+			continue
+		}
+		schema := t.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	if len(req.Tools) > 0 {
+		req.ToolChoice = anthropicToolChoice(cfg.ToolChoice)
+	}
+
+	var system []string
+	for _, m := range messages {
+		if m.Role == "system" && strings.TrimSpace(m.Content) != "" {
+			system = append(system, m.Content)
+		}
+	}
+	req.System = strings.Join(system, "\n\n")
+	req.Messages = convertToAnthropicMessages(messages)
+	if len(req.Messages) == 0 {
+		return anthropicRequest{}, fmt.Errorf("anthropic request for model %s has no user or assistant messages", modelName)
+	}
+	return req, nil
+}
+
+// thinkingBudget maps a think level to a thinking token budget; 0 disables
+// thinking.
+func thinkingBudget(level string) int {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "minimal", "low":
+		return 1024
+	case "medium":
+		return 8192
+	case "true", "high":
+		return 16384
+	case "xhigh":
+		return 32768
+	default:
+		return 0
+	}
+}
+
+// anthropicToolChoice maps a tool choice to the tool_choice parameter, or
+// returns nil for the default (auto).
+func anthropicToolChoice(choice *modelrepo.ToolChoice) *anthropicChoice {
+	switch {
+	case choice == nil:
+		return nil
+	case choice.Name != "":
+		return &anthropicChoice{Type: "tool", Name: choice.Name}
+	case choice.Mode == modelrepo.ToolChoiceNone:
+		return &anthropicChoice{Type: "none"}
+	case choice.Mode == modelrepo.ToolChoiceRequired:
+		return &anthropicChoice{Type: "any"}
+	default:
+		return nil
+	}
+}
+
+// convertToAnthropicMessages maps modelrepo messages to Messages API turns.
+// System messages go to the top-level system prompt; tool results are sent
+// as tool_result blocks of a user turn. Consecutive turns of the same role
+// are merged, since the API requires user and assistant turns to alternate.
+func convertToAnthropicMessages(messages []modelrepo.Message) []anthropicMessage {
+	out := make([]anthropicMessage, 0, len(messages))
+	for _, m := range messages {
+		var role string
+		var blocks []anthropicContentBlock
+		switch m.Role {
+		case "system":
+			continue
+		case "assistant", "model":
+			role = "assistant"
+			blocks = assistantBlocks(m)
+		case "tool":
+			role = "user"
+			blocks = []anthropicContentBlock{{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content}}
+		default:
+			role = "user"
+			if m.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+			}
+			for _, a := range m.Attachments {
+				source := &anthropicImageSource{Type: "base64", MediaType: a.MimeType, Data: a.Data}
+				if a.URL != "" {
+					source = &anthropicImageSource{Type: "url", URL: a.URL}
+				}
+				blocks = append(blocks, anthropicContentBlock{Type: "image", Source: source})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if len(out) > 0 && out[len(out)-1].Role == role {
+			out[len(out)-1].Content = append(out[len(out)-1].Content, blocks...)
+			continue
+		}
+		out = append(out, anthropicMessage{Role: role, Content: blocks})
+	}
+	return out
+}
+
+// assistantBlocks returns the thinking block preserved on the tool calls of
+// m, its text and its tool_use blocks.
+func assistantBlocks(m modelrepo.Message) []anthropicContentBlock {
+	var blocks []anthropicContentBlock
+	for _, tc := range m.ToolCalls {
+		if sig := tc.ProviderMeta[metaThinkingSignature]; sig != "" {
+			blocks = append(blocks, anthropicContentBlock{Type: "thinking", Thinking: tc.ProviderMeta[metaThinking], Signature: sig})
+			break
+		}
+	}
+	if m.Content != "" {
+		blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+	}
+	for _, tc := range m.ToolCalls {
+		if tc.Function.Name == "" {
+			continue
+		}
+		input := json.RawMessage(tc.Function.Arguments)
+		if !json.Valid(input) || !bytes.HasPrefix(bytes.TrimSpace(input), []byte("{")) {
+			input = json.RawMessage("{}")
+		}
+		blocks = append(blocks, anthropicContentBlock{Type: "tool_use", ID: tc.ID, Name: tc.Function.Name, Input: input})
+	}
+	return blocks
+}
@@ -0,0 +1,100 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type AnthropicProvider struct {
+	id            string
+	apiKey        string
+	modelName     string
+	baseURL       string
+	httpClient    *http.Client
+	contextLength int
+	canChat       bool
+	canPrompt     bool
+	canStream     bool
+	canThink      bool
+	tracker       libtracker.ActivityTracker
+}
+
+// NewAnthropicProvider returns a modelrepo.Provider for a Claude model served
+// by the Anthropic Messages API at baseURLs[0] (defaultBaseURL when empty).
+func NewAnthropicProvider(apiKey string, modelName string, baseURLs []string, cap modelrepo.CapabilityConfig, httpClient *http.Client, tracker libtracker.ActivityTracker) modelrepo.Provider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if len(baseURLs) == 0 {
+		baseURLs = []string{defaultBaseURL}
+	}
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	return &AnthropicProvider{
+		id:            fmt.Sprintf("anthropic-%s", modelName),
+		apiKey:        apiKey,
+		modelName:     modelName,
+		baseURL:       baseURLs[0],
+		httpClient:    httpClient,
+		contextLength: cap.ContextLength,
+		canChat:       cap.CanChat,
+		canPrompt:     cap.CanPrompt,
+		canStream:     cap.CanStream,
+		canThink:      cap.CanThink,
+		tracker:       tracker,
+	}
+}
+
+func (p *AnthropicProvider) GetBackendIDs() []string { return []string{p.baseURL} }
+func (p *AnthropicProvider) ModelName() string       { return p.modelName }
+func (p *AnthropicProvider) GetID() string           { return p.id }
+func (p *AnthropicProvider) GetType() string         { return "anthropic" }
+func (p *AnthropicProvider) GetContextLength() int   { return p.contextLength }
+func (p *AnthropicProvider) CanChat() bool           { return p.canChat }
+func (p *AnthropicProvider) CanEmbed() bool          { return false }
+func (p *AnthropicProvider) CanStream() bool         { return p.canStream }
+func (p *AnthropicProvider) CanPrompt() bool         { return p.canPrompt }
+func (p *AnthropicProvider) CanThink() bool          { return p.canThink }
+
+func (p *AnthropicProvider) client() anthropicClient {
+	return anthropicClient{
+		apiKey:     p.apiKey,
+		modelName:  p.modelName,
+		baseURL:    p.baseURL,
+		httpClient: p.httpClient,
+		tracker:    p.tracker,
+	}
+}
+
+func (p *AnthropicProvider) GetChatConnection(ctx context.Context, backendID string) (modelrepo.LLMChatClient, error) {
+	if !p.CanChat() {
+		return nil, fmt.Errorf("model %s does not support chat interactions", p.modelName)
+	}
+	return &AnthropicChatClient{anthropicClient: p.client()}, nil
+}
+
+func (p *AnthropicProvider) GetPromptConnection(ctx context.Context, backendID string) (modelrepo.LLMPromptExecClient, error) {
+	if !p.CanPrompt() {
+		return nil, fmt.Errorf("model %s does not support prompt interactions", p.modelName)
+	}
+	return &AnthropicPromptClient{anthropicClient: p.client()}, nil
+}
+
+func (p *AnthropicProvider) GetStreamConnection(ctx context.Context, backendID string) (modelrepo.LLMStreamClient, error) {
+	if !p.CanStream() {
+		return nil, fmt.Errorf("model %s does not support streaming interactions", p.modelName)
+	}
+	return &AnthropicStreamClient{anthropicClient: p.client()}, nil
+}
+
+// GetEmbedConnection always fails: the Anthropic API has no embedding models.
+func (p *AnthropicProvider) GetEmbedConnection(ctx context.Context, backendID string) (modelrepo.LLMEmbedClient, error) {
+	return nil, fmt.Errorf("model %s (anthropic) does not support embeddings", p.modelName)
+}
+
+var _ modelrepo.Provider = (*AnthropicProvider)(nil)
@@ -0,0 +1,139 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type AnthropicStreamClient struct {
+	anthropicClient
+}
+
+// Stream implements modelrepo.LLMStreamClient.
+func (c *AnthropicStreamClient) Stream(ctx context.Context, messages []modelrepo.Message, args ...modelrepo.ChatArgument) (<-chan *modelrepo.StreamParcel, error) {
+	request, err := buildAnthropicRequest(c.modelName, messages, args)
+	if err != nil {
+		return nil, err
+	}
+	request.Stream = true
+
+	parcels := make(chan *modelrepo.StreamParcel)
+	go func() {
+		defer close(parcels)
+
+		reportErr, reportChange, end := c.tracker.Start(
+			ctx,
+			"http_stream",
+			"anthropic",
+			"model", c.modelName,
+			"base_url", c.baseURL,
+		)
+		defer end()
+
+		send := func(p *modelrepo.StreamParcel) bool {
+			select {
+			case parcels <- p:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		fail := func(err error) {
+			reportErr(err)
+			send(&modelrepo.StreamParcel{Error: err})
+		}
+
+		body, err := json.Marshal(request)
+		if err != nil {
+			fail(fmt.Errorf("failed to marshal stream request: %w", err))
+			return
+		}
+		req, err := c.newRequest(ctx, http.MethodPost, "/messages", bytes.NewBuffer(body))
+		if err != nil {
+			fail(fmt.Errorf("failed to create stream request: %w", err))
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			fail(fmt.Errorf("HTTP stream request failed for model %s: %w", c.modelName, err))
+			return
+		}
+		defer resp.Body.Close()
+
+		reportChange("anthropic_stream_response", map[string]any{
+			"status":  resp.StatusCode,
+			"headers": resp.Header,
+		})
+
+		if resp.StatusCode != http.StatusOK {
+			b, _ := io.ReadAll(resp.Body)
+			fail(apiError(resp.StatusCode, b, c.modelName, req.URL.String()))
+			return
+		}
+
+		var (
+			chunkCount   int
+			totalContent strings.Builder
+		)
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for sc.Scan() {
+			data, ok := strings.CutPrefix(sc.Text(), "data:")
+			if !ok {
+				continue
+			}
+			var event anthropicStreamEvent
+			if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+				continue
+			}
+			switch event.Type {
+			case "error":
+				msg := "unknown error"
+				if event.Error != nil {
+					msg = event.Error.Type + ": " + event.Error.Message
+				}
+				fail(fmt.Errorf("anthropic stream error for model %s: %s", c.modelName, msg))
+				return
+			case "content_block_delta":
+				var parcel modelrepo.StreamParcel
+				switch event.Delta.Type {
+				case "text_delta":
+					parcel.Data = event.Delta.Text
+				case "thinking_delta":
+					parcel.Thinking = event.Delta.Thinking
+				}
+				if parcel.Data == "" && parcel.Thinking == "" {
+					continue
+				}
+				chunkCount++
+				totalContent.WriteString(parcel.Data)
+				if !send(&parcel) {
+					return
+				}
+			case "message_stop":
+				reportChange("stream_completed", map[string]any{
+					"chunk_count":  chunkCount,
+					"total_length": totalContent.Len(),
+				})
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			fail(fmt.Errorf("error reading from stream: %w", err))
+		}
+	}()
+
+	return parcels, nil
+}
+
+var _ modelrepo.LLMStreamClient = (*AnthropicStreamClient)(nil)
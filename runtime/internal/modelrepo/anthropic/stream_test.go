@@ -0,0 +1,82 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicStreamClient_StreamsTextAndThinkingDeltas(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/messages", r.URL.Path)
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"think-1\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"signature_delta\",\"signature\":\"sig\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"hello\"}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer srv.Close()
+
+	client := &AnthropicStreamClient{anthropicClient: anthropicClient{
+		apiKey:     "test-key",
+		modelName:  "claude-test",
+		baseURL:    srv.URL,
+		httpClient: srv.Client(),
+		tracker:    libtracker.NoopTracker{},
+	}}
+
+	stream, err := client.Stream(context.Background(), []modelrepo.Message{{Role: "user", Content: "hello"}})
+	require.NoError(t, err)
+
+	var parcels []modelrepo.StreamParcel
+	for parcel := range stream {
+		require.NoError(t, parcel.Error)
+		parcels = append(parcels, *parcel)
+	}
+
+	require.Len(t, parcels, 2)
+	assert.Equal(t, "think-1", parcels[0].Thinking)
+	assert.Equal(t, "", parcels[0].Data)
+	assert.Equal(t, "hello", parcels[1].Data)
+}
+
+func TestAnthropicStreamClient_ErrorEvent(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	client := &AnthropicStreamClient{anthropicClient: anthropicClient{
+		modelName:  "claude-test",
+		baseURL:    srv.URL,
+		httpClient: srv.Client(),
+		tracker:    libtracker.NoopTracker{},
+	}}
+
+	stream, err := client.Stream(context.Background(), []modelrepo.Message{{Role: "user", Content: "hello"}})
+	require.NoError(t, err)
+
+	var errs []error
+	for parcel := range stream {
+		errs = append(errs, parcel.Error)
+	}
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "overloaded_error: Overloaded")
+}
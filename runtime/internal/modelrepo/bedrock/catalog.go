@@ -0,0 +1,184 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// claudeContextLength is the context window of the Claude models on Bedrock;
+// the listing reports none.
+const claudeContextLength = 200000
+
+type catalogProvider struct {
+	spec       modelrepo.BackendSpec
+	endpoint   Endpoint
+	httpClient *http.Client
+	tracker    libtracker.ActivityTracker
+}
+
+func init() {
+	modelrepo.RegisterCatalogProvider("bedrock", func(spec modelrepo.BackendSpec, opts modelrepo.CatalogOptions) (modelrepo.CatalogProvider, error) {
+		endpoint, err := ParseEndpoint(spec.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		client := opts.HTTPClient
+		if client == nil {
+			client = http.DefaultClient
+		}
+		tracker := opts.Tracker
+		if tracker == nil {
+			tracker = libtracker.NoopTracker{}
+		}
+		return &catalogProvider{
+			spec:       spec,
+			endpoint:   endpoint,
+			httpClient: client,
+			tracker:    tracker,
+		}, nil
+	})
+}
+
+func (p *catalogProvider) Type() string {
+	return "bedrock"
+}
+
+// ListModels lists the active foundation models that can be invoked on
+// demand, followed by the active inference profiles, which newer models
+// are only served through. A profile takes the capabilities of the model
+// it routes to. Accounts without access to inference profiles list the
+// foundation models only.
+func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedModel, error) {
+	var listing struct {
+		ModelSummaries []foundationModel `json:"modelSummaries"`
+	}
+	if err := p.get(ctx, "/foundation-models", &listing); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]foundationModel, len(listing.ModelSummaries))
+	var models []modelrepo.ObservedModel
+	for _, fm := range listing.ModelSummaries {
+		byID[fm.ModelID] = fm
+		if fm.ModelLifecycle.Status != "" && fm.ModelLifecycle.Status != "ACTIVE" {
+			continue
+		}
+		if !slices.Contains(fm.InferenceTypesSupported, "ON_DEMAND") {
+			continue
+		}
+		models = append(models, observedModel(fm.ModelID, fm))
+	}
+
+	profiles, err := p.listInferenceProfiles(ctx)
+	if err != nil {
+		return models, nil
+	}
+	for _, profile := range profiles {
+		if profile.Status != "" && profile.Status != "ACTIVE" || len(profile.Models) == 0 {
+			continue
+		}
+		_, modelID, _ := strings.Cut(profile.Models[0].ModelArn, "foundation-model/")
+		fm, ok := byID[modelID]
+		if !ok {
+			continue
+		}
+		models = append(models, observedModel(profile.InferenceProfileID, fm))
+	}
+	return models, nil
+}
+
+// listInferenceProfiles pages through ListInferenceProfiles.
+func (p *catalogProvider) listInferenceProfiles(ctx context.Context) ([]inferenceProfile, error) {
+	var profiles []inferenceProfile
+	token := ""
+	for {
+		query := url.Values{"maxResults": {"1000"}}
+		if token != "" {
+			query.Set("nextToken", token)
+		}
+		var page struct {
+			InferenceProfileSummaries []inferenceProfile `json:"inferenceProfileSummaries"`
+			NextToken                 string             `json:"nextToken"`
+		}
+		if err := p.get(ctx, "/inference-profiles?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, page.InferenceProfileSummaries...)
+		if page.NextToken == "" || page.NextToken == token {
+			return profiles, nil
+		}
+		token = page.NextToken
+	}
+}
+
+// get calls the control plane API path and decodes the response into out.
+func (p *catalogProvider) get(ctx context.Context, path string, out any) error {
+	req, err := newRequest(ctx, p.spec.APIKey, p.endpoint, http.MethodGet, p.endpoint.ControlURL, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Bedrock catalog returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode Bedrock catalog response: %w", err)
+	}
+	return nil
+}
+
+func (p *catalogProvider) ProviderFor(model modelrepo.ObservedModel) modelrepo.Provider {
+	return NewBedrockProvider(
+		p.spec.APIKey,
+		model.Name,
+		p.endpoint,
+		model.CapabilityConfig,
+		p.httpClient,
+		p.tracker,
+	)
+}
+
+// observedModel maps the listing of fm to the capabilities of the model or
+// inference profile name. Text models chat and prompt through Converse;
+// extended thinking is supported by Claude models from Claude 3.7 on.
+func observedModel(name string, fm foundationModel) modelrepo.ObservedModel {
+	observed := modelrepo.ObservedModel{
+		Name: name,
+		Meta: map[string]string{"provider": fm.ProviderName, "model": fm.ModelName},
+	}
+	if slices.Contains(fm.OutputModalities, "TEXT") {
+		observed.CanChat = true
+		observed.CanPrompt = true
+		observed.CanStream = fm.ResponseStreamingSupported
+	}
+	if slices.Contains(fm.OutputModalities, "EMBEDDING") {
+		observed.CanEmbed = true
+	}
+	if isClaude(fm.ModelID) {
+		observed.ContextLength = claudeContextLength
+		observed.CapabilityConfig.ContextLength = claudeContextLength
+		lower := strings.ToLower(fm.ModelID)
+		observed.CanThink = !strings.Contains(lower, "claude-3-") && !strings.Contains(lower, "claude-v2") && !strings.Contains(lower, "claude-instant") ||
+			strings.Contains(lower, "claude-3-7-")
+	}
+	return observed
+}
+
+var _ modelrepo.CatalogProvider = (*catalogProvider)(nil)
@@ -0,0 +1,90 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/bedrock/aws4_request")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/foundation-models":
+			_ = json.NewEncoder(w).Encode(map[string]any{"modelSummaries": []map[string]any{
+				{
+					"modelId": "anthropic.claude-3-7-sonnet-20250219-v1:0", "providerName": "Anthropic", "modelName": "Claude 3.7 Sonnet",
+					"outputModalities": []string{"TEXT"}, "responseStreamingSupported": true,
+					"inferenceTypesSupported": []string{"INFERENCE_PROFILE"}, "modelLifecycle": map[string]string{"status": "ACTIVE"},
+				},
+				{
+					"modelId": "anthropic.claude-3-haiku-20240307-v1:0", "providerName": "Anthropic",
+					"outputModalities": []string{"TEXT"}, "responseStreamingSupported": true,
+					"inferenceTypesSupported": []string{"ON_DEMAND"}, "modelLifecycle": map[string]string{"status": "ACTIVE"},
+				},
+				{
+					"modelId": "amazon.titan-embed-text-v2:0", "providerName": "Amazon",
+					"outputModalities":        []string{"EMBEDDING"},
+					"inferenceTypesSupported": []string{"ON_DEMAND"}, "modelLifecycle": map[string]string{"status": "ACTIVE"},
+				},
+				{
+					"modelId": "anthropic.claude-v2", "outputModalities": []string{"TEXT"},
+					"inferenceTypesSupported": []string{"ON_DEMAND"}, "modelLifecycle": map[string]string{"status": "LEGACY"},
+				},
+			}})
+		case "/inference-profiles":
+			assert.Equal(t, "1000", r.URL.Query().Get("maxResults"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"inferenceProfileSummaries": []map[string]any{
+				{
+					"inferenceProfileId": "us.anthropic.claude-3-7-sonnet-20250219-v1:0", "status": "ACTIVE",
+					"models": []map[string]string{{"modelArn": "arn:aws:bedrock:us-east-1::foundation-model/anthropic.claude-3-7-sonnet-20250219-v1:0"}},
+				},
+			}})
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{
+		Type:    "bedrock",
+		BaseURL: server.URL + "?region=us-east-1",
+		APIKey:  "AKID:SECRET",
+	})
+	require.NoError(t, err)
+
+	models, err := catalog.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+
+	haiku := models[0]
+	assert.Equal(t, "anthropic.claude-3-haiku-20240307-v1:0", haiku.Name)
+	assert.True(t, haiku.CanChat)
+	assert.True(t, haiku.CanStream)
+	assert.False(t, haiku.CanThink)
+	assert.Equal(t, claudeContextLength, haiku.ContextLength)
+
+	titan := models[1]
+	assert.Equal(t, "amazon.titan-embed-text-v2:0", titan.Name)
+	assert.True(t, titan.CanEmbed)
+	assert.False(t, titan.CanChat)
+
+	profile := models[2]
+	assert.Equal(t, "us.anthropic.claude-3-7-sonnet-20250219-v1:0", profile.Name)
+	assert.True(t, profile.CanChat)
+	assert.True(t, profile.CanThink)
+	assert.Equal(t, "Claude 3.7 Sonnet", profile.Meta["model"])
+
+	provider := catalog.ProviderFor(profile)
+	assert.Equal(t, "bedrock", provider.GetType())
+	assert.True(t, provider.CanThink())
+}
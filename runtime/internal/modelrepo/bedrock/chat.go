@@ -0,0 +1,77 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type BedrockChatClient struct {
+	bedrockClient
+}
+
+// Chat implements modelrepo.LLMChatClient using the Converse API.
+func (c *BedrockChatClient) Chat(ctx context.Context, messages []modelrepo.Message, args ...modelrepo.ChatArgument) (modelrepo.ChatResult, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "chat", "bedrock", "model", c.modelName)
+	defer end()
+
+	req, err := buildConverseRequest(c.modelName, messages, args)
+	if err != nil {
+		reportErr(err)
+		return modelrepo.ChatResult{}, err
+	}
+
+	var resp converseResponse
+	if err := c.sendRequest(ctx, c.modelPath("converse"), req, &resp); err != nil {
+		reportErr(err)
+		return modelrepo.ChatResult{}, err
+	}
+
+	var (
+		outText   string
+		reasoning string
+		signature string
+		toolCalls []modelrepo.ToolCall
+	)
+	for _, block := range resp.Output.Message.Content {
+		switch {
+		case block.ReasoningContent != nil && block.ReasoningContent.ReasoningText != nil:
+			reasoning += block.ReasoningContent.ReasoningText.Text
+			if signature == "" {
+				signature = block.ReasoningContent.ReasoningText.Signature
+			}
+		case block.Text != nil:
+			outText += *block.Text
+		case block.ToolUse != nil:
+			tc := modelrepo.ToolCall{ID: block.ToolUse.ToolUseID, Type: "function"}
+			tc.Function.Name = block.ToolUse.Name
+			tc.Function.Arguments = string(block.ToolUse.Input)
+			if tc.Function.Arguments == "" {
+				tc.Function.Arguments = "{}"
+			}
+			toolCalls = append(toolCalls, tc)
+		}
+	}
+	// Keep the reasoning block with the tool calls: Claude models require it
+	// back together with their results.
+	if signature != "" && len(toolCalls) > 0 {
+		toolCalls[0].ProviderMeta = map[string]string{metaReasoning: reasoning, metaReasoningSignature: signature}
+	}
+
+	if outText == "" && len(toolCalls) == 0 {
+		err := fmt.Errorf("empty content from model %s: stop reason (%s)", c.modelName, resp.StopReason)
+		reportErr(err)
+		return modelrepo.ChatResult{}, err
+	}
+
+	result := modelrepo.ChatResult{
+		Message:   modelrepo.Message{Role: "assistant", Content: outText, Thinking: reasoning},
+		ToolCalls: toolCalls,
+	}
+
+	reportChange("chat_completed", result)
+	return result, nil
+}
+
+var _ modelrepo.LLMChatClient = (*BedrockChatClient)(nil)
@@ -0,0 +1,109 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(url, model string) bedrockClient {
+	return bedrockClient{
+		apiKey:     "AKID:SECRET",
+		modelName:  model,
+		endpoint:   Endpoint{ControlURL: url, RuntimeURL: url, Region: "us-east-1"},
+		httpClient: http.DefaultClient,
+		tracker:    libtracker.NoopTracker{},
+	}
+}
+
+func TestBuildConverseRequest(t *testing.T) {
+	think := "low"
+	maxTokens := 100
+	messages := []modelrepo.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "look", Attachments: []modelrepo.Attachment{{MimeType: "image/jpg", Data: []byte("img")}}},
+		{Role: "assistant", ToolCalls: []modelrepo.ToolCall{toolCall("call-1", "lookup", `{"q":"x"}`, map[string]string{metaReasoning: "hmm", metaReasoningSignature: "sig"})}},
+		{Role: "tool", ToolCallID: "call-1", Content: "found"},
+		{Role: "user", Content: "and?"},
+	}
+	req, err := buildConverseRequest("us.anthropic.claude-sonnet-4-20250514-v1:0", messages, []modelrepo.ChatArgument{
+		modelrepo.WithThink(think),
+		modelrepo.WithMaxTokens(maxTokens),
+		modelrepo.WithTool(modelrepo.Tool{Type: "function", Function: &modelrepo.FunctionTool{Name: "lookup"}}),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []systemBlock{{Text: "be brief"}}, req.System)
+	require.NotNil(t, req.InferenceConfig)
+	assert.Equal(t, 100+1024, *req.InferenceConfig.MaxTokens)
+	assert.Equal(t, map[string]any{"type": "enabled", "budget_tokens": 1024}, req.AdditionalModelRequestFields["thinking"])
+	require.NotNil(t, req.ToolConfig)
+	assert.Equal(t, "lookup", req.ToolConfig.Tools[0].ToolSpec.Name)
+
+	require.Len(t, req.Messages, 3, "tool results and the next user turn are merged")
+	assert.Equal(t, "jpeg", req.Messages[0].Content[1].Image.Format)
+	assistant := req.Messages[1].Content
+	require.Len(t, assistant, 2)
+	assert.Equal(t, "sig", assistant[0].ReasoningContent.ReasoningText.Signature)
+	assert.JSONEq(t, `{"q":"x"}`, string(assistant[1].ToolUse.Input))
+	user := req.Messages[2].Content
+	require.Len(t, user, 2)
+	assert.Equal(t, "call-1", user[0].ToolResult.ToolUseID)
+	assert.Equal(t, "and?", *user[1].Text)
+}
+
+func TestBedrockChatClient_Converse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/model/anthropic.claude-3-7-sonnet-20250219-v1%3A0/converse", r.URL.EscapedPath())
+		assert.NotEmpty(t, r.Header.Get("X-Amz-Date"))
+		var req converseRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Messages, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"stopReason": "tool_use",
+			"output": map[string]any{"message": map[string]any{"role": "assistant", "content": []map[string]any{
+				{"reasoningContent": map[string]any{"reasoningText": map[string]any{"text": "thinking", "signature": "sig"}}},
+				{"text": "checking"},
+				{"toolUse": map[string]any{"toolUseId": "tu-1", "name": "lookup", "input": map[string]any{"q": "x"}}},
+			}}},
+		})
+	}))
+	defer server.Close()
+
+	client := &BedrockChatClient{bedrockClient: testClient(server.URL, "anthropic.claude-3-7-sonnet-20250219-v1:0")}
+	result, err := client.Chat(context.Background(), []modelrepo.Message{{Role: "user", Content: "hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, "checking", result.Message.Content)
+	assert.Equal(t, "thinking", result.Message.Thinking)
+	require.Len(t, result.ToolCalls, 1)
+	assert.Equal(t, "lookup", result.ToolCalls[0].Function.Name)
+	assert.JSONEq(t, `{"q":"x"}`, result.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "sig", result.ToolCalls[0].ProviderMeta[metaReasoningSignature])
+}
+
+func TestBedrockChatClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-Errortype", "AccessDeniedException:http://internal.amazon.com/coral/com.amazon.coral.service/")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"You don't have access to the model"}`))
+	}))
+	defer server.Close()
+
+	client := &BedrockChatClient{bedrockClient: testClient(server.URL, "amazon.nova-lite-v1:0")}
+	_, err := client.Chat(context.Background(), []modelrepo.Message{{Role: "user", Content: "hi"}})
+	require.ErrorContains(t, err, "403 AccessDeniedException - You don't have access to the model")
+}
+
+func toolCall(id, name, args string, meta map[string]string) modelrepo.ToolCall {
+	tc := modelrepo.ToolCall{ID: id, Type: "function", ProviderMeta: meta}
+	tc.Function.Name = name
+	tc.Function.Arguments = args
+	return tc
+}
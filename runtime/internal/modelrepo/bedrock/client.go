@@ -0,0 +1,338 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// defaultMaxTokens caps the answer of a thinking turn when the caller sets
+// no max tokens; the thinking budget counts towards the limit.
+const defaultMaxTokens = 8192
+
+// Tool call ProviderMeta keys carrying the reasoning block of an assistant
+// turn. Claude models require it back with the tool results of that turn.
+const (
+	metaReasoning          = "reasoning"
+	metaReasoningSignature = "reasoning_signature"
+)
+
+type bedrockClient struct {
+	apiKey     string
+	modelName  string
+	endpoint   Endpoint
+	httpClient *http.Client
+	tracker    libtracker.ActivityTracker
+}
+
+// newRequest creates a SigV4-signed request to path of baseURL. path must be
+// escaped already.
+func newRequest(ctx context.Context, apiKey string, endpoint Endpoint, method, baseURL, path string, body []byte) (*http.Request, error) {
+	creds, err := resolveCredentials(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(baseURL, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	signRequest(req, body, creds, endpoint.Region, signingService, time.Now())
+	return req, nil
+}
+
+// modelPath returns the runtime API path of action for the client's model.
+func (c *bedrockClient) modelPath(action string) string {
+	return "/model/" + awsURIEncode(c.modelName) + "/" + action
+}
+
+// post sends request as JSON to the runtime API path and returns the
+// response, which the caller must close. Non-200 responses are returned as
+// errors.
+func (c *bedrockClient) post(ctx context.Context, path string, request any) (*http.Response, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := newRequest(ctx, c.apiKey, c.endpoint, http.MethodPost, c.endpoint.RuntimeURL, path, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed for model %s: %w", c.modelName, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, apiError(resp, body, c.modelName)
+	}
+	return resp, nil
+}
+
+// sendRequest: shared HTTP helper for Bedrock clients
+func (c *bedrockClient) sendRequest(ctx context.Context, path string, request any, response any) error {
+	reportErr, reportChange, end := c.tracker.Start(
+		ctx,
+		"http_request",
+		"bedrock",
+		"model", c.modelName,
+		"endpoint", path,
+		"region", c.endpoint.Region,
+	)
+	defer end()
+
+	resp, err := c.post(ctx, path, request)
+	if err != nil {
+		reportErr(err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	reportChange("http_response", map[string]any{
+		"status_code": resp.StatusCode,
+		"headers":     resp.Header,
+	})
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		err = fmt.Errorf("failed to decode response for model %s: %w", c.modelName, err)
+		reportErr(err)
+		return err
+	}
+
+	reportChange("request_completed", nil)
+	return nil
+}
+
+// apiError formats a non-200 response of a Bedrock API.
+func apiError(resp *http.Response, body []byte, modelName string) error {
+	kind, _, _ := strings.Cut(resp.Header.Get("X-Amzn-Errortype"), ":")
+	var eresp errorResponse
+	msg := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &eresp); err == nil && eresp.Message != "" {
+		msg = eresp.Message
+	}
+	if kind != "" {
+		return fmt.Errorf("bedrock API error: %d %s - %s (model=%s url=%s)", resp.StatusCode, kind, msg, modelName, resp.Request.URL)
+	}
+	return fmt.Errorf("bedrock API error: %d - %s (model=%s url=%s)", resp.StatusCode, msg, modelName, resp.Request.URL)
+}
+
+// buildConverseRequest converts modelrepo messages and args to a Converse
+// request for modelName.
+func buildConverseRequest(modelName string, messages []modelrepo.Message, args []modelrepo.ChatArgument) (converseRequest, error) {
+	cfg := &modelrepo.ChatConfig{}
+	for _, a := range args {
+		a.Apply(cfg)
+	}
+
+	req := converseRequest{}
+	inference := inferenceConfig{
+		MaxTokens:     cfg.MaxTokens,
+		StopSequences: cfg.Stop,
+	}
+
+	if cfg.Think != nil && isClaude(modelName) {
+		if budget := thinkingBudget(*cfg.Think); budget > 0 {
+			maxTokens := defaultMaxTokens
+			if cfg.MaxTokens != nil && *cfg.MaxTokens > 0 {
+				maxTokens = *cfg.MaxTokens
+			}
+			// The budget counts towards maxTokens; keep room for the answer.
+			maxTokens += budget
+			inference.MaxTokens = &maxTokens
+			req.AdditionalModelRequestFields = map[string]any{
+				"thinking": map[string]any{"type": "enabled", "budget_tokens": budget},
+			}
+			// Forcing a tool call is rejected while thinking; ask for it in
+			// the system prompt instead.
+			if c := cfg.ToolChoice; c != nil && (c.Name != "" || c.Mode == modelrepo.ToolChoiceRequired) {
+				messages = modelrepo.EmulateToolChoice(cfg, messages)
+				cfg.ToolChoice = nil
+			}
+		}
+	}
+	// Sampling parameters other than the defaults are rejected while thinking.
+	if req.AdditionalModelRequestFields == nil {
+		inference.Temperature = cfg.Temperature
+		inference.TopP = cfg.TopP
+		if cfg.TopK != nil && isClaude(modelName) {
+			req.AdditionalModelRequestFields = map[string]any{"top_k": *cfg.TopK}
+		}
+	}
+	if inference.MaxTokens != nil || inference.Temperature != nil || inference.TopP != nil || len(inference.StopSequences) > 0 {
+		req.InferenceConfig = &inference
+	}
+
+	// Converse has no "none" tool choice; leave the tools out instead.
+	if c := cfg.ToolChoice; c == nil || c.Name != "" || c.Mode != modelrepo.ToolChoiceNone {
+		var tools []toolSpecBlock
+		for _, t := range cfg.Tools {
+			if t.Type != "function" || t.Function == nil {
+				continue
+			}
+			spec := toolSpec{Name: t.Function.Name, Description: t.Function.Description}
+			spec.InputSchema.JSON = t.Function.Parameters
+			if spec.InputSchema.JSON == nil {
+				spec.InputSchema.JSON = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			tools = append(tools, toolSpecBlock{ToolSpec: spec})
+		}
+		if len(tools) > 0 {
+			req.ToolConfig = &toolConfig{Tools: tools, ToolChoice: converseToolChoice(cfg.ToolChoice)}
+		}
+	}
+
+	for _, m := range messages {
+		if m.Role == "system" && strings.TrimSpace(m.Content) != "" {
+			req.System = append(req.System, systemBlock{Text: m.Content})
+		}
+	}
+	req.Messages = convertToConverseMessages(messages)
+	if len(req.Messages) == 0 {
+		return converseRequest{}, fmt.Errorf("bedrock request for model %s has no user or assistant messages", modelName)
+	}
+	return req, nil
+}
+
+// isClaude reports whether modelName is an Anthropic model or an inference
+// profile of one, e.g. us.anthropic.claude-3-7-sonnet-20250219-v1:0.
+func isClaude(modelName string) bool {
+	return strings.Contains(strings.ToLower(modelName), "anthropic.claude")
+}
+
+// thinkingBudget maps a think level to a thinking token budget; 0 disables
+// thinking.
+func thinkingBudget(level string) int {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "minimal", "low":
+		return 1024
+	case "medium":
+		return 8192
+	case "true", "high":
+		return 16384
+	case "xhigh":
+		return 32768
+	default:
+		return 0
+	}
+}
+
+// converseToolChoice maps a tool choice to the toolChoice parameter, or
+// returns nil for the default (auto).
+func converseToolChoice(choice *modelrepo.ToolChoice) map[string]any {
+	switch {
+	case choice == nil:
+		return nil
+	case choice.Name != "":
+		return map[string]any{"tool": map[string]any{"name": choice.Name}}
+	case choice.Mode == modelrepo.ToolChoiceRequired:
+		return map[string]any{"any": map[string]any{}}
+	default:
+		return nil
+	}
+}
+
+// convertToConverseMessages maps modelrepo messages to Converse turns.
+// System messages go to the top-level system prompt; tool results are sent
+// as toolResult blocks of a user turn. Consecutive turns of the same role
+// are merged, since the API requires user and assistant turns to alternate.
+func convertToConverseMessages(messages []modelrepo.Message) []converseMessage {
+	out := make([]converseMessage, 0, len(messages))
+	for _, m := range messages {
+		var role string
+		var blocks []contentBlock
+		switch m.Role {
+		case "system":
+			continue
+		case "assistant", "model":
+			role = "assistant"
+			blocks = assistantBlocks(m)
+		case "tool":
+			role = "user"
+			content := m.Content
+			if content == "" {
+				content = "(empty)"
+			}
+			blocks = []contentBlock{{ToolResult: &toolResultBlock{ToolUseID: m.ToolCallID, Content: []textBlock{{Text: content}}}}}
+		default:
+			role = "user"
+			if m.Content != "" {
+				blocks = append(blocks, textContent(m.Content))
+			}
+			for _, a := range m.Attachments {
+				// Converse takes images inline only; name linked ones.
+				if a.URL != "" {
+					blocks = append(blocks, textContent("[image: "+a.URL+"]"))
+					continue
+				}
+				format, ok := strings.CutPrefix(a.MimeType, "image/")
+				if !ok {
+					continue
+				}
+				if format == "jpg" {
+					format = "jpeg"
+				}
+				img := &imageBlock{Format: format}
+				img.Source.Bytes = a.Data
+				blocks = append(blocks, contentBlock{Image: img})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if len(out) > 0 && out[len(out)-1].Role == role {
+			out[len(out)-1].Content = append(out[len(out)-1].Content, blocks...)
+			continue
+		}
+		out = append(out, converseMessage{Role: role, Content: blocks})
+	}
+	return out
+}
+
+// assistantBlocks returns the reasoning block preserved on the tool calls of
+// m, its text and its toolUse blocks.
+func assistantBlocks(m modelrepo.Message) []contentBlock {
+	var blocks []contentBlock
+	for _, tc := range m.ToolCalls {
+		if sig := tc.ProviderMeta[metaReasoningSignature]; sig != "" {
+			blocks = append(blocks, contentBlock{ReasoningContent: &reasoningContent{
+				ReasoningText: &reasoningText{Text: tc.ProviderMeta[metaReasoning], Signature: sig},
+			}})
+			break
+		}
+	}
+	if m.Content != "" {
+		blocks = append(blocks, textContent(m.Content))
+	}
+	for _, tc := range m.ToolCalls {
+		if tc.Function.Name == "" {
+			continue
+		}
+		input := json.RawMessage(tc.Function.Arguments)
+		if !json.Valid(input) || !bytes.HasPrefix(bytes.TrimSpace(input), []byte("{")) {
+			input = json.RawMessage("{}")
+		}
+		blocks = append(blocks, contentBlock{ToolUse: &toolUseBlock{ToolUseID: tc.ID, Name: tc.Function.Name, Input: input}})
+	}
+	return blocks
+}
+
+func textContent(text string) contentBlock {
+	return contentBlock{Text: &text}
+}
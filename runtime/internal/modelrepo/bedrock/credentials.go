@@ -0,0 +1,110 @@
+package bedrock
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrCredentialsMissing is returned when neither a stored key nor the AWS
+// environment provides credentials.
+var ErrCredentialsMissing = errors.New("AWS credentials not configured: API key not configured, AWS_ACCESS_KEY_ID not set and no shared credentials file found")
+
+// credentials are the AWS access keys requests are signed with.
+type credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// parseCredentials parses a stored Bedrock API key of the form
+// ACCESS_KEY_ID:SECRET_ACCESS_KEY or ACCESS_KEY_ID:SECRET_ACCESS_KEY:SESSION_TOKEN.
+func parseCredentials(apiKey string) (credentials, error) {
+	parts := strings.SplitN(strings.TrimSpace(apiKey), ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return credentials{}, fmt.Errorf("bedrock credentials must be ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN]")
+	}
+	creds := credentials{AccessKeyID: parts[0], SecretAccessKey: parts[1]}
+	if len(parts) == 3 {
+		creds.SessionToken = parts[2]
+	}
+	return creds, nil
+}
+
+// resolveCredentials returns the credentials of apiKey, or when it is empty
+// those of the AWS environment: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN, then the AWS_PROFILE (default "default") profile of the
+// shared credentials file.
+func resolveCredentials(apiKey string) (credentials, error) {
+	if strings.TrimSpace(apiKey) != "" {
+		return parseCredentials(apiKey)
+	}
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return credentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return credentials{}, ErrCredentialsMissing
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	creds, err := sharedCredentials(path, profile)
+	if err != nil {
+		return credentials{}, err
+	}
+	return creds, nil
+}
+
+// sharedCredentials reads profile from the shared credentials file at path.
+func sharedCredentials(path, profile string) (credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return credentials{}, ErrCredentialsMissing
+	}
+	defer f.Close()
+
+	var creds credentials
+	section := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return credentials{}, fmt.Errorf("read AWS credentials file %s: %w", path, err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return credentials{}, fmt.Errorf("%w (profile %q not in %s)", ErrCredentialsMissing, profile, path)
+	}
+	return creds, nil
+}
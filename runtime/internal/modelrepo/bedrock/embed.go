@@ -0,0 +1,54 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type BedrockEmbedClient struct {
+	bedrockClient
+}
+
+// Embed implements modelrepo.LLMEmbedClient using InvokeModel. Cohere
+// models take a batch of texts; Amazon Titan models take one input text.
+func (c *BedrockEmbedClient) Embed(ctx context.Context, prompt string) ([]float64, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "embed", "bedrock", "model", c.modelName)
+	defer end()
+
+	var vector []float64
+	if strings.Contains(strings.ToLower(c.modelName), "cohere.") {
+		var resp struct {
+			Embeddings [][]float64 `json:"embeddings"`
+		}
+		req := map[string]any{"texts": []string{prompt}, "input_type": "search_document"}
+		if err := c.sendRequest(ctx, c.modelPath("invoke"), req, &resp); err != nil {
+			reportErr(err)
+			return nil, err
+		}
+		if len(resp.Embeddings) > 0 {
+			vector = resp.Embeddings[0]
+		}
+	} else {
+		var resp struct {
+			Embedding []float64 `json:"embedding"`
+		}
+		if err := c.sendRequest(ctx, c.modelPath("invoke"), map[string]any{"inputText": prompt}, &resp); err != nil {
+			reportErr(err)
+			return nil, err
+		}
+		vector = resp.Embedding
+	}
+	if len(vector) == 0 {
+		err := fmt.Errorf("empty embedding from model %s", c.modelName)
+		reportErr(err)
+		return nil, err
+	}
+
+	reportChange("embed_completed", map[string]any{"dimensions": len(vector)})
+	return vector, nil
+}
+
+var _ modelrepo.LLMEmbedClient = (*BedrockEmbedClient)(nil)
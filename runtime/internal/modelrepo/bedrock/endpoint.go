@@ -0,0 +1,56 @@
+package bedrock
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// awsHostPattern matches the Bedrock control plane and runtime hosts, e.g.
+// bedrock-runtime.us-east-1.amazonaws.com.
+var awsHostPattern = regexp.MustCompile(`^bedrock(?:-runtime)?(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// Endpoint locates the Bedrock APIs of one region.
+type Endpoint struct {
+	// ControlURL serves ListFoundationModels and ListInferenceProfiles.
+	ControlURL string
+	// RuntimeURL serves Converse, ConverseStream and InvokeModel.
+	RuntimeURL string
+	Region     string
+}
+
+// ParseEndpoint parses the URL of a Bedrock backend. For the AWS hosts
+// bedrock.<region>.amazonaws.com and bedrock-runtime.<region>.amazonaws.com
+// the region and both APIs follow from the host. Any other URL, e.g. a VPC
+// endpoint or a proxy, serves both APIs and names the region in its region
+// query parameter.
+func ParseEndpoint(rawURL string) (Endpoint, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Endpoint{}, fmt.Errorf("invalid bedrock URL %q: want e.g. https://bedrock-runtime.us-east-1.amazonaws.com", rawURL)
+	}
+	region := u.Query().Get("region")
+	if m := awsHostPattern.FindStringSubmatch(strings.ToLower(u.Hostname())); m != nil {
+		if region == "" {
+			region = m[1]
+		}
+		host := u.Host
+		fips := strings.Contains(host, "-fips.")
+		control, runtime := "bedrock", "bedrock-runtime"
+		if fips {
+			control, runtime = "bedrock-fips", "bedrock-runtime-fips"
+		}
+		suffix := host[strings.Index(host, "."):]
+		return Endpoint{
+			ControlURL: u.Scheme + "://" + control + suffix,
+			RuntimeURL: u.Scheme + "://" + runtime + suffix,
+			Region:     region,
+		}, nil
+	}
+	if region == "" {
+		return Endpoint{}, fmt.Errorf("bedrock URL %q is not an AWS Bedrock host: add the region, e.g. ?region=us-east-1", rawURL)
+	}
+	base := u.Scheme + "://" + u.Host + strings.TrimRight(u.Path, "/")
+	return Endpoint{ControlURL: base, RuntimeURL: base, Region: region}, nil
+}
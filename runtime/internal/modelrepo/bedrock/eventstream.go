@@ -0,0 +1,100 @@
+package bedrock
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// maxEventMessageSize bounds one event stream message; Bedrock events are far
+// smaller.
+const maxEventMessageSize = 16 << 20
+
+// eventMessage is one message of an application/vnd.amazon.eventstream
+// response. Only string headers are kept.
+type eventMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// readEventMessage reads the next message of an event stream. It returns
+// io.EOF at the end of the stream.
+func readEventMessage(r io.Reader) (eventMessage, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(r, prelude[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return eventMessage{}, fmt.Errorf("truncated event stream prelude")
+		}
+		return eventMessage{}, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return eventMessage{}, fmt.Errorf("event stream prelude checksum mismatch")
+	}
+	if totalLen < 16 || totalLen > maxEventMessageSize || headersLen > totalLen-16 {
+		return eventMessage{}, fmt.Errorf("invalid event stream message length %d", totalLen)
+	}
+	rest := make([]byte, totalLen-12)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return eventMessage{}, fmt.Errorf("truncated event stream message: %w", err)
+	}
+	crc := crc32.NewIEEE()
+	crc.Write(prelude[:])
+	crc.Write(rest[:len(rest)-4])
+	if crc.Sum32() != binary.BigEndian.Uint32(rest[len(rest)-4:]) {
+		return eventMessage{}, fmt.Errorf("event stream message checksum mismatch")
+	}
+	headers, err := parseEventHeaders(rest[:headersLen])
+	if err != nil {
+		return eventMessage{}, err
+	}
+	return eventMessage{Headers: headers, Payload: rest[headersLen : len(rest)-4]}, nil
+}
+
+// parseEventHeaders decodes the headers block of a message.
+func parseEventHeaders(b []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, fmt.Errorf("truncated event stream header")
+		}
+		name := string(b[1 : 1+nameLen])
+		typ := b[1+nameLen]
+		b = b[2+nameLen:]
+
+		var size int
+		switch typ {
+		case 0, 1: // bool true, bool false
+		case 2: // byte
+			size = 1
+		case 3: // short
+			size = 2
+		case 4: // int
+			size = 4
+		case 5, 8: // long, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // bytes, string
+			if len(b) < 2 {
+				return nil, fmt.Errorf("truncated event stream header %q", name)
+			}
+			size = int(binary.BigEndian.Uint16(b))
+			b = b[2:]
+		default:
+			return nil, fmt.Errorf("unknown event stream header type %d", typ)
+		}
+		if len(b) < size {
+			return nil, fmt.Errorf("truncated event stream header %q", name)
+		}
+		if typ == 7 {
+			headers[name] = string(b[:size])
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
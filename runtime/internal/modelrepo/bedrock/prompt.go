@@ -0,0 +1,40 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type BedrockPromptClient struct {
+	bedrockClient
+}
+
+// Prompt implements the LLMPromptExecClient interface for a single-turn, non-chat request.
+func (c *BedrockPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "prompt", "bedrock", "model", c.modelName)
+	defer end()
+
+	messages := []modelrepo.Message{
+		{Role: "user", Content: prompt},
+	}
+	if s := strings.TrimSpace(systemInstruction); s != "" {
+		messages = append([]modelrepo.Message{{Role: "system", Content: s}}, messages...)
+	}
+
+	chat := &BedrockChatClient{bedrockClient: c.bedrockClient}
+	resp, err := chat.Chat(ctx, messages, append([]modelrepo.ChatArgument{modelrepo.WithTemperature(float64(temperature))}, args...)...)
+	if err != nil {
+		reportErr(err)
+		return "", fmt.Errorf("Bedrock prompt execution failed: %w", err)
+	}
+
+	reportChange("prompt_completed", map[string]any{
+		"response_length": len(resp.Message.Content),
+	})
+	return resp.Message.Content, nil
+}
+
+var _ modelrepo.LLMPromptExecClient = (*BedrockPromptClient)(nil)
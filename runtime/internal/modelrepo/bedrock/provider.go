@@ -0,0 +1,103 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type BedrockProvider struct {
+	id            string
+	apiKey        string
+	modelName     string
+	endpoint      Endpoint
+	httpClient    *http.Client
+	contextLength int
+	canChat       bool
+	canPrompt     bool
+	canStream     bool
+	canEmbed      bool
+	canThink      bool
+	tracker       libtracker.ActivityTracker
+}
+
+// NewBedrockProvider returns a modelrepo.Provider for a model or inference
+// profile served by Bedrock at endpoint. apiKey holds
+// ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN]; when empty, requests are
+// signed with the credentials of the AWS environment.
+func NewBedrockProvider(apiKey string, modelName string, endpoint Endpoint, cap modelrepo.CapabilityConfig, httpClient *http.Client, tracker libtracker.ActivityTracker) modelrepo.Provider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	return &BedrockProvider{
+		id:            fmt.Sprintf("bedrock-%s", modelName),
+		apiKey:        apiKey,
+		modelName:     modelName,
+		endpoint:      endpoint,
+		httpClient:    httpClient,
+		contextLength: cap.ContextLength,
+		canChat:       cap.CanChat,
+		canPrompt:     cap.CanPrompt,
+		canStream:     cap.CanStream,
+		canEmbed:      cap.CanEmbed,
+		canThink:      cap.CanThink,
+		tracker:       tracker,
+	}
+}
+
+func (p *BedrockProvider) GetBackendIDs() []string { return []string{p.endpoint.RuntimeURL} }
+func (p *BedrockProvider) ModelName() string       { return p.modelName }
+func (p *BedrockProvider) GetID() string           { return p.id }
+func (p *BedrockProvider) GetType() string         { return "bedrock" }
+func (p *BedrockProvider) GetContextLength() int   { return p.contextLength }
+func (p *BedrockProvider) CanChat() bool           { return p.canChat }
+func (p *BedrockProvider) CanEmbed() bool          { return p.canEmbed }
+func (p *BedrockProvider) CanStream() bool         { return p.canStream }
+func (p *BedrockProvider) CanPrompt() bool         { return p.canPrompt }
+func (p *BedrockProvider) CanThink() bool          { return p.canThink }
+
+func (p *BedrockProvider) client() bedrockClient {
+	return bedrockClient{
+		apiKey:     p.apiKey,
+		modelName:  p.modelName,
+		endpoint:   p.endpoint,
+		httpClient: p.httpClient,
+		tracker:    p.tracker,
+	}
+}
+
+func (p *BedrockProvider) GetChatConnection(ctx context.Context, backendID string) (modelrepo.LLMChatClient, error) {
+	if !p.CanChat() {
+		return nil, fmt.Errorf("model %s does not support chat interactions", p.modelName)
+	}
+	return &BedrockChatClient{bedrockClient: p.client()}, nil
+}
+
+func (p *BedrockProvider) GetPromptConnection(ctx context.Context, backendID string) (modelrepo.LLMPromptExecClient, error) {
+	if !p.CanPrompt() {
+		return nil, fmt.Errorf("model %s does not support prompt interactions", p.modelName)
+	}
+	return &BedrockPromptClient{bedrockClient: p.client()}, nil
+}
+
+func (p *BedrockProvider) GetStreamConnection(ctx context.Context, backendID string) (modelrepo.LLMStreamClient, error) {
+	if !p.CanStream() {
+		return nil, fmt.Errorf("model %s does not support streaming interactions", p.modelName)
+	}
+	return &BedrockStreamClient{bedrockClient: p.client()}, nil
+}
+
+func (p *BedrockProvider) GetEmbedConnection(ctx context.Context, backendID string) (modelrepo.LLMEmbedClient, error) {
+	if !p.CanEmbed() {
+		return nil, fmt.Errorf("model %s does not support embeddings", p.modelName)
+	}
+	return &BedrockEmbedClient{bedrockClient: p.client()}, nil
+}
+
+var _ modelrepo.Provider = (*BedrockProvider)(nil)
@@ -0,0 +1,78 @@
+package bedrock
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignRequest_AWSTestSuite checks the get-vanilla case of the AWS
+// Signature Version 4 test suite.
+func TestSignRequest_AWSTestSuite(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	creds := credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signRequest(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestCanonicalURI_EncodesModelIDsTwice(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/"+awsURIEncode("anthropic.claude-v2:1")+"/converse", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/model/anthropic.claude-v2%3A1/converse", req.URL.EscapedPath())
+	assert.Equal(t, "/model/anthropic.claude-v2%253A1/converse", canonicalURI(req.URL))
+}
+
+func TestResolveCredentials(t *testing.T) {
+	creds, err := resolveCredentials("AKID:secret:token")
+	require.NoError(t, err)
+	assert.Equal(t, credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, creds)
+	_, err = resolveCredentials("just-a-key")
+	require.ErrorContains(t, err, "ACCESS_KEY_ID:SECRET_ACCESS_KEY")
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("[default]\naws_access_key_id = A\naws_secret_access_key = B\n\n[work]\naws_access_key_id=C\naws_secret_access_key=D\naws_session_token=E\n"), 0o600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "work")
+	creds, err = resolveCredentials("")
+	require.NoError(t, err)
+	assert.Equal(t, credentials{AccessKeyID: "C", SecretAccessKey: "D", SessionToken: "E"}, creds)
+
+	t.Setenv("AWS_PROFILE", "missing")
+	_, err = resolveCredentials("")
+	require.ErrorIs(t, err, ErrCredentialsMissing)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "envid")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+	creds, err = resolveCredentials("")
+	require.NoError(t, err)
+	assert.Equal(t, "envid", creds.AccessKeyID)
+}
+
+func TestParseEndpoint(t *testing.T) {
+	ep, err := ParseEndpoint("https://bedrock-runtime.eu-central-1.amazonaws.com")
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{
+		ControlURL: "https://bedrock.eu-central-1.amazonaws.com",
+		RuntimeURL: "https://bedrock-runtime.eu-central-1.amazonaws.com",
+		Region:     "eu-central-1",
+	}, ep)
+
+	ep, err = ParseEndpoint("https://proxy.internal/bedrock/?region=us-west-2")
+	require.NoError(t, err)
+	assert.Equal(t, Endpoint{ControlURL: "https://proxy.internal/bedrock", RuntimeURL: "https://proxy.internal/bedrock", Region: "us-west-2"}, ep)
+
+	_, err = ParseEndpoint("https://proxy.internal")
+	require.ErrorContains(t, err, "region")
+}
@@ -0,0 +1,118 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+type BedrockStreamClient struct {
+	bedrockClient
+}
+
+// Stream implements modelrepo.LLMStreamClient using the ConverseStream API,
+// whose events arrive in the AWS event stream encoding.
+func (c *BedrockStreamClient) Stream(ctx context.Context, messages []modelrepo.Message, args ...modelrepo.ChatArgument) (<-chan *modelrepo.StreamParcel, error) {
+	request, err := buildConverseRequest(c.modelName, messages, args)
+	if err != nil {
+		return nil, err
+	}
+
+	parcels := make(chan *modelrepo.StreamParcel)
+	go func() {
+		defer close(parcels)
+
+		reportErr, reportChange, end := c.tracker.Start(
+			ctx,
+			"http_stream",
+			"bedrock",
+			"model", c.modelName,
+			"region", c.endpoint.Region,
+		)
+		defer end()
+
+		send := func(p *modelrepo.StreamParcel) bool {
+			select {
+			case parcels <- p:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		fail := func(err error) {
+			reportErr(err)
+			send(&modelrepo.StreamParcel{Error: err})
+		}
+
+		resp, err := c.post(ctx, c.modelPath("converse-stream"), request)
+		if err != nil {
+			fail(err)
+			return
+		}
+		defer resp.Body.Close()
+
+		reportChange("bedrock_stream_response", map[string]any{
+			"status":  resp.StatusCode,
+			"headers": resp.Header,
+		})
+
+		var (
+			chunkCount   int
+			totalContent strings.Builder
+		)
+		for {
+			msg, err := readEventMessage(resp.Body)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				fail(fmt.Errorf("error reading from stream: %w", err))
+				return
+			}
+			if kind := msg.Headers[":message-type"]; kind == "exception" || kind == "error" {
+				var eresp errorResponse
+				_ = json.Unmarshal(msg.Payload, &eresp)
+				name := msg.Headers[":exception-type"]
+				if name == "" {
+					name = msg.Headers[":error-code"]
+				}
+				fail(fmt.Errorf("bedrock stream error for model %s: %s: %s", c.modelName, name, eresp.Message))
+				return
+			}
+			switch msg.Headers[":event-type"] {
+			case "contentBlockDelta":
+				var event streamDelta
+				if err := json.Unmarshal(msg.Payload, &event); err != nil {
+					continue
+				}
+				parcel := modelrepo.StreamParcel{
+					Data:     event.Delta.Text,
+					Thinking: event.Delta.ReasoningContent.Text,
+				}
+				if parcel.Data == "" && parcel.Thinking == "" {
+					continue
+				}
+				chunkCount++
+				totalContent.WriteString(parcel.Data)
+				if !send(&parcel) {
+					return
+				}
+			case "messageStop":
+				reportChange("stream_completed", map[string]any{
+					"chunk_count":  chunkCount,
+					"total_length": totalContent.Len(),
+				})
+				return
+			}
+		}
+	}()
+
+	return parcels, nil
+}
+
+var _ modelrepo.LLMStreamClient = (*BedrockStreamClient)(nil)
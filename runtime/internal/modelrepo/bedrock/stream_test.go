@@ -0,0 +1,92 @@
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeEvent encodes an event stream message with string headers.
+func encodeEvent(headers map[string]string, payload string) []byte {
+	var hb bytes.Buffer
+	for name, value := range headers {
+		hb.WriteByte(byte(len(name)))
+		hb.WriteString(name)
+		hb.WriteByte(7)
+		_ = binary.Write(&hb, binary.BigEndian, uint16(len(value)))
+		hb.WriteString(value)
+	}
+	var msg bytes.Buffer
+	_ = binary.Write(&msg, binary.BigEndian, uint32(16+hb.Len()+len(payload)))
+	_ = binary.Write(&msg, binary.BigEndian, uint32(hb.Len()))
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(hb.Bytes())
+	msg.WriteString(payload)
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func event(kind, payload string) []byte {
+	return encodeEvent(map[string]string{":message-type": "event", ":event-type": kind, ":content-type": "application/json"}, payload)
+}
+
+func TestBedrockStreamClient_StreamsTextAndReasoningDeltas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/model/amazon.nova-pro-v1:0/converse-stream", r.URL.Path)
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		_, _ = w.Write(event("messageStart", `{"role":"assistant"}`))
+		_, _ = w.Write(event("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"reasoningContent":{"text":"hmm"}}}`))
+		_, _ = w.Write(event("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"text":"Hel"}}`))
+		_, _ = w.Write(event("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"text":"lo"}}`))
+		_, _ = w.Write(event("messageStop", `{"stopReason":"end_turn"}`))
+	}))
+	defer server.Close()
+
+	client := &BedrockStreamClient{bedrockClient: testClient(server.URL, "amazon.nova-pro-v1:0")}
+	stream, err := client.Stream(context.Background(), []modelrepo.Message{{Role: "user", Content: "hi"}})
+	require.NoError(t, err)
+
+	var parcels []modelrepo.StreamParcel
+	for parcel := range stream {
+		require.NoError(t, parcel.Error)
+		parcels = append(parcels, *parcel)
+	}
+	require.Len(t, parcels, 3)
+	assert.Equal(t, "hmm", parcels[0].Thinking)
+	assert.Equal(t, "Hel", parcels[1].Data)
+	assert.Equal(t, "lo", parcels[2].Data)
+}
+
+func TestBedrockStreamClient_ExceptionEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(event("contentBlockDelta", `{"delta":{"text":"partial"}}`))
+		_, _ = w.Write(encodeEvent(map[string]string{":message-type": "exception", ":exception-type": "throttlingException"}, `{"message":"Too many requests"}`))
+	}))
+	defer server.Close()
+
+	client := &BedrockStreamClient{bedrockClient: testClient(server.URL, "amazon.nova-pro-v1:0")}
+	stream, err := client.Stream(context.Background(), []modelrepo.Message{{Role: "user", Content: "hi"}})
+	require.NoError(t, err)
+
+	var last *modelrepo.StreamParcel
+	for parcel := range stream {
+		last = parcel
+	}
+	require.NotNil(t, last)
+	require.ErrorContains(t, last.Error, "throttlingException: Too many requests")
+}
+
+func TestReadEventMessage_ChecksumMismatch(t *testing.T) {
+	msg := event("messageStop", `{}`)
+	msg[len(msg)-5] ^= 0xff
+	_, err := readEventMessage(bytes.NewReader(msg))
+	require.ErrorContains(t, err, "checksum")
+}
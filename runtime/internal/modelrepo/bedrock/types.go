@@ -0,0 +1,135 @@
+package bedrock
+
+import "encoding/json"
+
+// converseRequest is the wire format of the Converse and ConverseStream APIs.
+type converseRequest struct {
+	Messages        []converseMessage `json:"messages"`
+	System          []systemBlock     `json:"system,omitempty"`
+	InferenceConfig *inferenceConfig  `json:"inferenceConfig,omitempty"`
+	ToolConfig      *toolConfig       `json:"toolConfig,omitempty"`
+	// AdditionalModelRequestFields carries model-specific parameters, e.g.
+	// the thinking budget of Claude models.
+	AdditionalModelRequestFields map[string]any `json:"additionalModelRequestFields,omitempty"`
+}
+
+type systemBlock struct {
+	Text string `json:"text"`
+}
+
+type inferenceConfig struct {
+	MaxTokens     *int     `json:"maxTokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+type toolConfig struct {
+	Tools      []toolSpecBlock `json:"tools"`
+	ToolChoice map[string]any  `json:"toolChoice,omitempty"`
+}
+
+type toolSpecBlock struct {
+	ToolSpec toolSpec `json:"toolSpec"`
+}
+
+type toolSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema struct {
+		JSON any `json:"json"`
+	} `json:"inputSchema"`
+}
+
+type converseMessage struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+// contentBlock is one block of a message; exactly one field is set.
+type contentBlock struct {
+	Text             *string           `json:"text,omitempty"`
+	Image            *imageBlock       `json:"image,omitempty"`
+	ToolUse          *toolUseBlock     `json:"toolUse,omitempty"`
+	ToolResult       *toolResultBlock  `json:"toolResult,omitempty"`
+	ReasoningContent *reasoningContent `json:"reasoningContent,omitempty"`
+}
+
+// imageBlock is an inline image; Bytes is base64-encoded by encoding/json.
+type imageBlock struct {
+	Format string `json:"format"`
+	Source struct {
+		Bytes []byte `json:"bytes"`
+	} `json:"source"`
+}
+
+type toolUseBlock struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+}
+
+type toolResultBlock struct {
+	ToolUseID string      `json:"toolUseId"`
+	Content   []textBlock `json:"content"`
+}
+
+type textBlock struct {
+	Text string `json:"text"`
+}
+
+type reasoningContent struct {
+	ReasoningText *reasoningText `json:"reasoningText,omitempty"`
+}
+
+type reasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// converseResponse is the response of a Converse call.
+type converseResponse struct {
+	Output struct {
+		Message converseMessage `json:"message"`
+	} `json:"output"`
+	StopReason string `json:"stopReason"`
+}
+
+// streamDelta is the payload of a contentBlockDelta event of ConverseStream.
+type streamDelta struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Delta             struct {
+		Text             string `json:"text"`
+		ReasoningContent struct {
+			Text string `json:"text"`
+		} `json:"reasoningContent"`
+	} `json:"delta"`
+}
+
+// errorResponse is the body of a failed request or an exception event.
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// foundationModel is one entry of ListFoundationModels.
+type foundationModel struct {
+	ModelID                    string   `json:"modelId"`
+	ModelName                  string   `json:"modelName"`
+	ProviderName               string   `json:"providerName"`
+	InputModalities            []string `json:"inputModalities"`
+	OutputModalities           []string `json:"outputModalities"`
+	ResponseStreamingSupported bool     `json:"responseStreamingSupported"`
+	InferenceTypesSupported    []string `json:"inferenceTypesSupported"`
+	ModelLifecycle             struct {
+		Status string `json:"status"`
+	} `json:"modelLifecycle"`
+}
+
+// inferenceProfile is one entry of ListInferenceProfiles.
+type inferenceProfile struct {
+	InferenceProfileID string `json:"inferenceProfileId"`
+	Status             string `json:"status"`
+	Models             []struct {
+		ModelArn string `json:"modelArn"`
+	} `json:"models"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +18,9 @@ type BackendSpec struct {
 	Type    string
 	BaseURL string
 	APIKey  string
+	// Headers are sent with every request to the backend, replacing headers
+	// of the same name set by the provider.
+	Headers map[string]string
 }
 
 // ObservedModel is the normalized result of listing models from a backend.
@@ -31,6 +35,10 @@ type ObservedModel struct {
 	Meta map[string]string
 }
 
+// MetaParentModel is the ObservedModel.Meta key naming the base model an
+// adapter (e.g. a vLLM LoRA adapter) is applied to.
+const MetaParentModel = "parent_model"
+
 // CatalogProvider observes the models exposed by one backend instance and can
 // turn an observed model into the existing execution Provider abstraction.
 type CatalogProvider interface {
@@ -39,6 +47,72 @@ type CatalogProvider interface {
 	ProviderFor(model ObservedModel) Provider
 }
 
+// CatalogValidators are the HTTP cache validators returned with a model listing.
+// Sending them back lets the provider answer 304 Not Modified instead of
+// returning the full list again.
+type CatalogValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsZero reports whether no validator is set.
+func (v CatalogValidators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Apply sets the conditional request headers for v on req.
+func (v CatalogValidators) Apply(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// CatalogListing is the result of a conditional model listing.
+type CatalogListing struct {
+	// Models is empty when NotModified is set; the caller keeps its cached list.
+	Models      []ObservedModel
+	NotModified bool
+	Validators  CatalogValidators
+	// MaxAge is the freshness lifetime announced via Cache-Control max-age.
+	// It is nil when the response carries no max-age; zero when the response
+	// must be revalidated on every use (no-cache, no-store or max-age=0).
+	MaxAge *time.Duration
+}
+
+// ConditionalCatalogProvider is implemented by catalogs whose listing endpoint
+// supports HTTP conditional requests (ETag/If-None-Match, Last-Modified/If-Modified-Since).
+type ConditionalCatalogProvider interface {
+	CatalogProvider
+	ListModelsConditional(ctx context.Context, validators CatalogValidators) (CatalogListing, error)
+}
+
+// CatalogCacheInfo extracts the cache validators and Cache-Control freshness of resp.
+func CatalogCacheInfo(resp *http.Response) (CatalogValidators, *time.Duration) {
+	validators := CatalogValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	var maxAge *time.Duration
+	for _, directive := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			zero := time.Duration(0)
+			return validators, &zero
+		case strings.HasPrefix(directive, "max-age="):
+			secs, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err == nil && secs >= 0 {
+				d := time.Duration(secs) * time.Second
+				maxAge = &d
+			}
+		}
+	}
+	return validators, maxAge
+}
+
 // CatalogFactory constructs CatalogProvider implementations from backend specs.
 type CatalogFactory interface {
 	NewCatalogProvider(spec BackendSpec, opts ...CatalogOption) (CatalogProvider, error)
@@ -127,6 +201,9 @@ func (registryCatalogFactory) NewCatalogProvider(spec BackendSpec, opts ...Catal
 	if options.Tracker == nil {
 		options.Tracker = libtracker.NoopTracker{}
 	}
+	if len(spec.Headers) > 0 {
+		options.HTTPClient = withHeaders(options.HTTPClient, spec.Headers)
+	}
 
 	catalogRegistryMu.RLock()
 	constructor, ok := catalogRegistry[normalized]
@@ -138,3 +215,28 @@ func (registryCatalogFactory) NewCatalogProvider(spec BackendSpec, opts ...Catal
 	spec.Type = normalized
 	return constructor(spec, options)
 }
+
+// headerTransport sets fixed headers on every request before handing it to base.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withHeaders returns a copy of client that sends headers with every request.
+func withHeaders(client *http.Client, headers map[string]string) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = headerTransport{base: base, headers: headers}
+	return &wrapped
+}
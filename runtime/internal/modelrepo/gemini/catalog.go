@@ -35,26 +35,45 @@ func (p *catalogProvider) Type() string {
 }
 
 func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedModel, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.baseURL(), "/")+"/v1beta/models", nil)
+	listing, err := p.ListModelsConditional(ctx, modelrepo.CatalogValidators{})
 	if err != nil {
 		return nil, err
 	}
+	return listing.Models, nil
+}
+
+// ListModelsConditional lists models, sending validators as conditional
+// request headers. On 304 Not Modified the per-model describe calls are
+// skipped as well.
+func (p *catalogProvider) ListModelsConditional(ctx context.Context, validators modelrepo.CatalogValidators) (modelrepo.CatalogListing, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.baseURL(), "/")+"/v1beta/models", nil)
+	if err != nil {
+		return modelrepo.CatalogListing{}, err
+	}
 	if p.spec.APIKey != "" {
 		req.Header.Set("X-Goog-Api-Key", p.spec.APIKey)
 	}
+	validators.Apply(req)
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return modelrepo.CatalogListing{}, err
 	}
 	defer resp.Body.Close()
 
+	listing := modelrepo.CatalogListing{}
+	listing.Validators, listing.MaxAge = modelrepo.CatalogCacheInfo(resp)
+	if resp.StatusCode == http.StatusNotModified {
+		listing.NotModified = true
+		return listing, nil
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return modelrepo.CatalogListing{}, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Gemini catalog returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return modelrepo.CatalogListing{}, fmt.Errorf("Gemini catalog returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	var payload struct {
@@ -63,18 +82,18 @@ func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedM
 		} `json:"models"`
 	}
 	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, fmt.Errorf("decode Gemini catalog response: %w", err)
+		return modelrepo.CatalogListing{}, fmt.Errorf("decode Gemini catalog response: %w", err)
 	}
 
-	models := make([]modelrepo.ObservedModel, 0, len(payload.Models))
+	listing.Models = make([]modelrepo.ObservedModel, 0, len(payload.Models))
 	for _, item := range payload.Models {
 		observed, err := p.describeModel(ctx, item.Name)
 		if err != nil {
-			return nil, err
+			return modelrepo.CatalogListing{}, err
 		}
-		models = append(models, observed)
+		listing.Models = append(listing.Models, observed)
 	}
-	return models, nil
+	return listing, nil
 }
 
 func (p *catalogProvider) ProviderFor(model modelrepo.ObservedModel) modelrepo.Provider {
@@ -145,3 +164,5 @@ func (p *catalogProvider) describeModel(ctx context.Context, modelName string) (
 
 	return observed, nil
 }
+
+var _ modelrepo.ConditionalCatalogProvider = (*catalogProvider)(nil)
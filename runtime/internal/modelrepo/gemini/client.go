@@ -26,6 +26,7 @@ type geminiGenerateContentRequest struct {
 	Contents          []geminiContent          `json:"contents"`
 	GenerationConfig  *geminiGenerationConfig  `json:"generationConfig,omitempty"`
 	Tools             []geminiToolRequest      `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig        `json:"toolConfig,omitempty"`
 }
 
 type geminiGenerationConfig struct {
@@ -36,6 +37,9 @@ type geminiGenerationConfig struct {
 	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
 	StopSequences   []string `json:"stopSequences,omitempty"`
 	Seed            *int     `json:"seed,omitempty"`
+	// Penalties are only honoured by some Gemini models.
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	PresencePenalty  *float64 `json:"presencePenalty,omitempty"`
 	// ThinkingConfig controls extended thinking on Gemini 2.5+ models.
 	// Use nil to omit (default behaviour, no thinking).
 	ThinkingConfig *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
@@ -170,12 +174,19 @@ func buildGeminiRequest(_ string, messages []modelrepo.Message, systemInstructio
 		GenerationConfig:  &geminiGenerationConfig{},
 		Tools:             tools,
 	}
+	if len(tools) > 0 {
+		req.ToolConfig = geminiToolChoice(cfg.ToolChoice)
+	}
 	req.GenerationConfig.Temperature = cfg.Temperature
 	req.GenerationConfig.TopP = cfg.TopP
 	if cfg.MaxTokens != nil {
 		req.GenerationConfig.MaxOutputTokens = cfg.MaxTokens
 	}
 	req.GenerationConfig.Seed = cfg.Seed
+	req.GenerationConfig.TopK = cfg.TopK
+	req.GenerationConfig.FrequencyPenalty = cfg.FrequencyPenalty
+	req.GenerationConfig.PresencePenalty = cfg.PresencePenalty
+	req.GenerationConfig.StopSequences = cfg.Stop
 
 	// Wire ThinkingConfig for Gemini 2.5+ thinking models.
 	// Omitting it (nil) means the model uses its default (usually no thinking).
@@ -195,6 +206,23 @@ func buildGeminiRequest(_ string, messages []modelrepo.Message, systemInstructio
 	return req, nil
 }
 
+// geminiToolChoice maps a tool choice to Gemini's function calling mode, or
+// returns nil for the default.
+func geminiToolChoice(choice *modelrepo.ToolChoice) *geminiToolConfig {
+	switch {
+	case choice == nil:
+		return nil
+	case choice.Name != "":
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{choice.Name}}}
+	case choice.Mode == modelrepo.ToolChoiceNone:
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "NONE"}}
+	case choice.Mode == modelrepo.ToolChoiceRequired:
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY"}}
+	default:
+		return nil
+	}
+}
+
 // convert modelrepo messages to Gemini "contents"
 func convertToGeminiMessages(messages []modelrepo.Message) []geminiContent {
 	out := make([]geminiContent, 0, len(messages))
@@ -230,6 +258,13 @@ func convertToGeminiMessages(messages []modelrepo.Message) []geminiContent {
 		if m.Content != "" && m.Role != "tool" {
 			parts = append(parts, geminiPart{Text: m.Content})
 		}
+		for _, a := range m.Attachments {
+			if a.URL != "" {
+				parts = append(parts, geminiPart{FileData: &geminiFileData{MimeType: a.MimeType, FileURI: a.URL}})
+			} else {
+				parts = append(parts, geminiPart{InlineData: &geminiBlob{MimeType: a.MimeType, Data: a.Data}})
+			}
+		}
 
 		// Assistant tool calls: encode as functionCall parts
 		if len(m.ToolCalls) > 0 {
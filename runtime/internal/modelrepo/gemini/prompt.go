@@ -13,7 +13,7 @@ type GeminiPromptClient struct {
 }
 
 // Prompt implements the LLMPromptExecClient interface for a single-turn, non-chat request.
-func (c *GeminiPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error) {
+func (c *GeminiPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
 	// Start tracking the operation
 	reportErr, reportChange, end := c.tracker.Start(ctx, "prompt", "gemini", "model", c.modelName)
 	defer end()
@@ -27,7 +27,7 @@ func (c *GeminiPromptClient) Prompt(ctx context.Context, systemInstruction strin
 	}
 
 	chat := &GeminiChatClient{geminiClient: c.geminiClient}
-	resp, err := chat.Chat(ctx, messages, modelrepo.WithTemperature(float64(temperature)))
+	resp, err := chat.Chat(ctx, messages, append([]modelrepo.ChatArgument{modelrepo.WithTemperature(float64(temperature))}, args...)...)
 	if err != nil {
 		reportErr(err)
 		return "", fmt.Errorf("Gemini prompt execution failed: %w", err)
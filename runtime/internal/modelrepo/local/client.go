@@ -28,6 +28,9 @@ func (c *localChatClient) Chat(ctx context.Context, messages []modelrepo.Message
 		a.Apply(cfg)
 	}
 
+	if err := rejectAttachments(messages); err != nil {
+		return modelrepo.ChatResult{}, err
+	}
 	prompt := buildPrompt(messages)
 	text, err := generate(ctx, c.modelPath, prompt, cfg)
 	if err != nil {
@@ -43,19 +46,75 @@ type localPromptClient struct {
 	modelPath string
 }
 
-func (c *localPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error) {
+func (c *localPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
 	messages := []modelrepo.Message{
 		{Role: "system", Content: systemInstruction},
 		{Role: "user", Content: prompt},
 	}
 	temp := float64(temperature)
 	cfg := &modelrepo.ChatConfig{Temperature: &temp}
+	for _, a := range args {
+		a.Apply(cfg)
+	}
 	return generate(ctx, c.modelPath, buildPrompt(messages), cfg)
 }
 
+// samplingParams returns the llama.cpp sampler settings for cfg, starting from
+// the defaults used when a field is unset.
+func samplingParams(cfg *modelrepo.ChatConfig) llama.SamplingParams {
+	params := llama.SamplingParams{TopK: 40, TopP: 0.9, MinP: 0.05, Temp: 0.8}
+	if cfg == nil {
+		return params
+	}
+	if cfg.Temperature != nil {
+		params.Temp = float32(*cfg.Temperature)
+	}
+	if cfg.TopP != nil {
+		params.TopP = float32(*cfg.TopP)
+	}
+	if cfg.TopK != nil {
+		params.TopK = *cfg.TopK
+	}
+	if cfg.FrequencyPenalty != nil {
+		params.PenaltyFreq = float32(*cfg.FrequencyPenalty)
+	}
+	if cfg.PresencePenalty != nil {
+		params.PenaltyPresent = float32(*cfg.PresencePenalty)
+	}
+	if cfg.Seed != nil {
+		params.Seed = uint32(*cfg.Seed)
+	}
+	return params
+}
+
+// cutAtStop returns text up to the first stop sequence of cfg it contains.
+func cutAtStop(text string, cfg *modelrepo.ChatConfig) (string, bool) {
+	if cfg == nil {
+		return text, false
+	}
+	cut, found := len(text), false
+	for _, stop := range cfg.Stop {
+		if i := strings.Index(text, stop); stop != "" && i >= 0 && i < cut {
+			cut, found = i, true
+		}
+	}
+	return text[:cut], found
+}
+
 // buildPrompt converts messages to a simple chat-ML format.
 // Models with a bundled chat template will re-tokenize correctly;
 // for models without one this provides a reasonable fallback.
+// rejectAttachments fails for messages with images; the in-process backend
+// only runs text models.
+func rejectAttachments(messages []modelrepo.Message) error {
+	for _, m := range messages {
+		if len(m.Attachments) > 0 {
+			return fmt.Errorf("local provider does not support image attachments")
+		}
+	}
+	return nil
+}
+
 func buildPrompt(messages []modelrepo.Message) string {
 	var b strings.Builder
 	for _, m := range messages {
@@ -114,17 +173,7 @@ func generate(ctx context.Context, modelPath, prompt string, cfg *modelrepo.Chat
 		return "", fmt.Errorf("decode prompt: %w", err)
 	}
 
-	samplerParams := llama.SamplingParams{
-		TopK:  40,
-		TopP:  0.9,
-		MinP:  0.05,
-		Temp:  0.8,
-	}
-	if cfg != nil && cfg.Temperature != nil {
-		samplerParams.Temp = float32(*cfg.Temperature)
-	}
-
-	sampler, err := llama.NewSamplingContext(lm.model, samplerParams)
+	sampler, err := llama.NewSamplingContext(lm.model, samplingParams(cfg))
 	if err != nil {
 		return "", fmt.Errorf("create sampler: %w", err)
 	}
@@ -150,6 +199,9 @@ func generate(ctx context.Context, modelPath, prompt string, cfg *modelrepo.Chat
 		}
 
 		out.WriteString(lm.model.TokenToPiece(id))
+		if text, ok := cutAtStop(out.String(), cfg); ok {
+			return strings.TrimSpace(text), nil
+		}
 
 		batch.Clear()
 		batch.Add(id, nil, pos, true, 0)
@@ -172,6 +224,9 @@ func (c *localStreamClient) Stream(ctx context.Context, messages []modelrepo.Mes
 		a.Apply(cfg)
 	}
 
+	if err := rejectAttachments(messages); err != nil {
+		return nil, err
+	}
 	prompt := buildPrompt(messages)
 	ch := make(chan *modelrepo.StreamParcel, 16)
 
@@ -214,11 +269,7 @@ func (c *localStreamClient) Stream(ctx context.Context, messages []modelrepo.Mes
 			return
 		}
 
-		samplerParams := llama.SamplingParams{TopK: 40, TopP: 0.9, MinP: 0.05, Temp: 0.8}
-		if cfg.Temperature != nil {
-			samplerParams.Temp = float32(*cfg.Temperature)
-		}
-		sampler, err := llama.NewSamplingContext(lm.model, samplerParams)
+		sampler, err := llama.NewSamplingContext(lm.model, samplingParams(cfg))
 		if err != nil {
 			ch <- &modelrepo.StreamParcel{Error: fmt.Errorf("create sampler: %w", err)}
 			return
@@ -229,6 +280,8 @@ func (c *localStreamClient) Stream(ctx context.Context, messages []modelrepo.Mes
 			maxTokens = *cfg.MaxTokens
 		}
 
+		var out strings.Builder
+		sent := 0
 		for pos := len(tokens); pos < len(tokens)+maxTokens; pos++ {
 			select {
 			case <-ctx.Done():
@@ -241,7 +294,18 @@ func (c *localStreamClient) Stream(ctx context.Context, messages []modelrepo.Mes
 			if lm.model.TokenIsEog(id) {
 				break
 			}
-			ch <- &modelrepo.StreamParcel{Data: lm.model.TokenToPiece(id)}
+			piece := lm.model.TokenToPiece(id)
+			// Sent pieces cannot be withdrawn, so the start of a stop sequence
+			// spanning several tokens may appear in the stream.
+			out.WriteString(piece)
+			if text, ok := cutAtStop(out.String(), cfg); ok {
+				if len(text) > sent {
+					ch <- &modelrepo.StreamParcel{Data: text[sent:]}
+				}
+				return
+			}
+			sent += len(piece)
+			ch <- &modelrepo.StreamParcel{Data: piece}
 			batch.Clear()
 			batch.Add(id, nil, pos, true, 0)
 			if err := llamaCtx.Decode(batch); err != nil {
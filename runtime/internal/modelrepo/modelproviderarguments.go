@@ -32,6 +32,38 @@ func WithTopP(p float64) ChatArgument {
 	}
 }
 
+func WithTopK(k int) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.TopK = &k
+		},
+	}
+}
+
+func WithFrequencyPenalty(p float64) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.FrequencyPenalty = &p
+		},
+	}
+}
+
+func WithPresencePenalty(p float64) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.PresencePenalty = &p
+		},
+	}
+}
+
+func WithStop(sequences ...string) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.Stop = append(config.Stop, sequences...)
+		},
+	}
+}
+
 func WithSeed(seed int) ChatArgument {
 	return &chatArgument{
 		applyFunc: func(config *ChatConfig) {
@@ -55,3 +87,11 @@ func WithTools(tools ...Tool) ChatArgument {
 		},
 	}
 }
+
+func WithToolChoice(choice ToolChoice) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.ToolChoice = &choice
+		},
+	}
+}
@@ -1,6 +1,11 @@
 package modelrepo
 
-import "context"
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
 
 type ChatResult struct {
 	Message   Message
@@ -32,6 +37,24 @@ type Message struct {
 	// For tool calling (OpenAI / vLLM compatible).
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
+
+	// Attachments are images sent with the message to vision models.
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is an image of a message, given either by URL or inline Data.
+type Attachment struct {
+	MimeType string `json:"mime_type"`
+	URL      string `json:"url,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// URLOrData returns URL, or Data as an RFC 2397 data URL.
+func (a Attachment) URLOrData() string {
+	if a.URL != "" {
+		return a.URL
+	}
+	return "data:" + a.MimeType + ";base64," + base64.StdEncoding.EncodeToString(a.Data)
 }
 
 type ChatArgument interface {
@@ -62,8 +85,18 @@ type ChatConfig struct {
 	Temperature *float64 `json:"temperature,omitempty"`
 	MaxTokens   *int     `json:"max_tokens,omitempty"`
 	TopP        *float64 `json:"top_p,omitempty"`
+	TopK        *int     `json:"top_k,omitempty"`
 	Seed        *int     `json:"seed,omitempty"`
-	Tools       []Tool   `json:"tools,omitempty"`
+	// FrequencyPenalty and PresencePenalty follow the OpenAI convention:
+	// -2.0 to 2.0, positive values discourage repetition.
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	// Stop lists sequences that end generation; they are not part of the output.
+	Stop  []string `json:"stop,omitempty"`
+	Tools []Tool   `json:"tools,omitempty"`
+	// ToolChoice constrains the tool calls of the turn. nil = provider
+	// default (auto).
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
 	// Think controls reasoning-model behaviour. nil = use provider default (off).
 	// Accepts provider-specific levels such as "none", "minimal", "low",
 	// "medium", "high", and "xhigh" where supported.
@@ -75,6 +108,61 @@ type ChatConfig struct {
 	Truncate *bool `json:"truncate,omitempty"`
 }
 
+// Tool choice modes; see ToolChoice.
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// ToolChoice constrains the tool calls of one chat turn.
+type ToolChoice struct {
+	// Mode is ToolChoiceAuto, ToolChoiceNone or ToolChoiceRequired. It is
+	// ignored when Name is set.
+	Mode string `json:"mode,omitempty"`
+	// Name forces a call of the function tool with this name.
+	Name string `json:"name,omitempty"`
+}
+
+// EmulateToolChoice applies cfg.ToolChoice for providers without a native
+// tool_choice parameter. ToolChoiceNone removes the tools; a forced function
+// keeps only that tool. Forced calls are requested with an instruction added
+// to the leading system message, which is returned with the other messages.
+func EmulateToolChoice(cfg *ChatConfig, messages []Message) []Message {
+	choice := cfg.ToolChoice
+	if choice == nil || len(cfg.Tools) == 0 {
+		return messages
+	}
+	var instruction string
+	switch {
+	case choice.Name != "":
+		var kept []Tool
+		for _, t := range cfg.Tools {
+			if t.Function != nil && t.Function.Name == choice.Name {
+				kept = append(kept, t)
+			}
+		}
+		cfg.Tools = kept
+		instruction = fmt.Sprintf("You must respond with a call of the tool %q.", choice.Name)
+	case choice.Mode == ToolChoiceNone:
+		cfg.Tools = nil
+		return messages
+	case choice.Mode == ToolChoiceRequired:
+		instruction = "You must respond with a call of one of the available tools."
+	default:
+		return messages
+	}
+	out := make([]Message, 0, len(messages)+1)
+	if len(messages) > 0 && messages[0].Role == "system" {
+		first := messages[0]
+		first.Content = strings.TrimSpace(first.Content + "\n\n" + instruction)
+		out = append(out, first)
+		return append(out, messages[1:]...)
+	}
+	out = append(out, Message{Role: "system", Content: instruction})
+	return append(out, messages...)
+}
+
 // WithThink is a ChatArgument that enables/controls reasoning mode.
 type WithThink string
 
@@ -100,10 +188,19 @@ type LLMEmbedClient interface {
 	Embed(ctx context.Context, prompt string) ([]float64, error)
 }
 
+// LLMBatchEmbedClient is implemented by embed clients whose backend embeds
+// several inputs in one request. EmbedBatch returns one vector per prompt, in
+// order.
+type LLMBatchEmbedClient interface {
+	EmbedBatch(ctx context.Context, prompts []string) ([][]float64, error)
+}
+
 type LLMStreamClient interface {
 	Stream(ctx context.Context, messages []Message, args ...ChatArgument) (<-chan *StreamParcel, error)
 }
 
 type LLMPromptExecClient interface {
-	Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error)
+	// Prompt runs a single-turn request. args carries further sampling
+	// options; a temperature among them overrides the temperature argument.
+	Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...ChatArgument) (string, error)
 }
@@ -22,6 +22,13 @@ func (c *OllamaChatClient) Chat(ctx context.Context, messages []modelrepo.Messag
 	reportErr, reportChange, end := c.tracker.Start(ctx, "chat", "ollama", "model", c.modelName)
 	defer end()
 
+	config := &modelrepo.ChatConfig{}
+	for _, arg := range args {
+		arg.Apply(config)
+	}
+	// Ollama has no tool_choice parameter.
+	messages = modelrepo.EmulateToolChoice(config, messages)
+
 	// Convert messages to Ollama API format (we preserve role, including "tool").
 	// We must also map ToolCalls from assistant messages so Ollama knows what tools
 	// were already called — without this the LLM has no context of its prior tool calls.
@@ -44,19 +51,19 @@ func (c *OllamaChatClient) Chat(ctx context.Context, messages []modelrepo.Messag
 				})
 			}
 		}
+		images, err := buildOllamaImages(msg)
+		if err != nil {
+			reportErr(err)
+			return modelrepo.ChatResult{}, err
+		}
 		apiMessages = append(apiMessages, api.Message{
 			Role:      msg.Role,
 			Content:   msg.Content,
 			ToolCalls: apiToolCalls,
+			Images:    images,
 		})
 	}
 
-	// Build configuration from arguments
-	config := &modelrepo.ChatConfig{}
-	for _, arg := range args {
-		arg.Apply(config)
-	}
-
 	llamaOptions := buildOllamaOptions(config)
 	think := buildOllamaThink(config)
 	stream := false
@@ -222,6 +222,18 @@ func buildOllamaOptions(config *modelrepo.ChatConfig) map[string]any {
 	if config.TopP != nil {
 		opts["top_p"] = *config.TopP
 	}
+	if config.TopK != nil {
+		opts["top_k"] = *config.TopK
+	}
+	if config.FrequencyPenalty != nil {
+		opts["frequency_penalty"] = *config.FrequencyPenalty
+	}
+	if config.PresencePenalty != nil {
+		opts["presence_penalty"] = *config.PresencePenalty
+	}
+	if len(config.Stop) > 0 {
+		opts["stop"] = config.Stop
+	}
 	if config.Seed != nil {
 		opts["seed"] = *config.Seed
 	}
@@ -275,3 +287,42 @@ func buildOllamaTools(config *modelrepo.ChatConfig) (api.Tools, error) {
 	}
 	return apiTools, nil
 }
+
+// buildOllamaImages returns the attachments of msg as Ollama images. Ollama
+// only accepts inline image data, so URL attachments are rejected.
+func buildOllamaImages(msg modelrepo.Message) ([]api.ImageData, error) {
+	var images []api.ImageData
+	for _, a := range msg.Attachments {
+		if len(a.Data) == 0 {
+			return nil, fmt.Errorf("ollama does not fetch image URLs, attach the image data instead")
+		}
+		images = append(images, api.ImageData(a.Data))
+	}
+	return images, nil
+}
+
+func (c *ollamaHTTPClient) ListRunning(ctx context.Context) (*api.ProcessResponse, error) {
+	var resp api.ProcessResponse
+	if err := c.do(ctx, http.MethodGet, "/ps", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// LoadedVRAM returns the VRAM in bytes held by the models the Ollama server at
+// baseURL currently has loaded.
+func LoadedVRAM(ctx context.Context, baseURL, apiKey string, httpClient *http.Client) (int64, error) {
+	client, err := newOllamaHTTPClient(baseURL, apiKey, httpClient)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.ListRunning(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, m := range resp.Models {
+		total += m.SizeVRAM
+	}
+	return total, nil
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
 	"github.com/ollama/ollama/api"
 )
 
@@ -69,3 +70,38 @@ func TestOllamaHTTPClient_GenerateStreamsNDJSON(t *testing.T) {
 		t.Fatalf("unexpected streamed chunks: %#v", chunks)
 	}
 }
+
+func TestBuildOllamaOptions_SamplingParams(t *testing.T) {
+	config := &modelrepo.ChatConfig{}
+	for _, arg := range []modelrepo.ChatArgument{
+		modelrepo.WithTemperature(0.2),
+		modelrepo.WithTopP(0.9),
+		modelrepo.WithTopK(40),
+		modelrepo.WithFrequencyPenalty(0.5),
+		modelrepo.WithPresencePenalty(0.25),
+		modelrepo.WithStop("###"),
+		modelrepo.WithMaxTokens(128),
+	} {
+		arg.Apply(config)
+	}
+	opts := buildOllamaOptions(config)
+	want := map[string]any{
+		"temperature":       0.2,
+		"top_p":             0.9,
+		"top_k":             40,
+		"frequency_penalty": 0.5,
+		"presence_penalty":  0.25,
+		"num_predict":       128,
+	}
+	for k, v := range want {
+		if opts[k] != v {
+			t.Errorf("opts[%q] = %v, want %v", k, opts[k], v)
+		}
+	}
+	if stop, _ := opts["stop"].([]string); len(stop) != 1 || stop[0] != "###" {
+		t.Errorf("opts[stop] = %v, want [###]", opts["stop"])
+	}
+	if len(buildOllamaOptions(&modelrepo.ChatConfig{})) != 0 {
+		t.Error("expected no options for an empty config")
+	}
+}
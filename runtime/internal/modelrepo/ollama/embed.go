@@ -46,4 +46,40 @@ func (c *OllamaEmbedClient) Embed(ctx context.Context, text string) ([]float64,
 	return embedding, nil
 }
 
-var _ modelrepo.LLMEmbedClient = (*OllamaEmbedClient)(nil)
+// EmbedBatch embeds all prompts with a single /api/embed request.
+func (c *OllamaEmbedClient) EmbedBatch(ctx context.Context, prompts []string) ([][]float64, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "embed_batch", "ollama", "model", c.modelName)
+	defer end()
+
+	resp, err := c.ollamaClient.Embed(ctx, &api.EmbedRequest{
+		Model: c.modelName,
+		Input: prompts,
+	})
+	if err != nil {
+		reportErr(err)
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	if len(resp.Embeddings) != len(prompts) {
+		err := fmt.Errorf("embedding response for model %s has %d vectors for %d inputs", c.modelName, len(resp.Embeddings), len(prompts))
+		reportErr(err)
+		return nil, err
+	}
+
+	embeddings := make([][]float64, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		embeddings[i] = make([]float64, len(e))
+		for j, v := range e {
+			embeddings[i][j] = float64(v)
+		}
+	}
+
+	reportChange("embedding_completed", map[string]any{
+		"inputs": len(prompts),
+	})
+	return embeddings, nil
+}
+
+var (
+	_ modelrepo.LLMEmbedClient      = (*OllamaEmbedClient)(nil)
+	_ modelrepo.LLMBatchEmbedClient = (*OllamaEmbedClient)(nil)
+)
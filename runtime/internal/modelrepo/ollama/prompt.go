@@ -17,7 +17,7 @@ type OllamaPromptClient struct {
 }
 
 // Prompt implements LLMPromptExecClient interface
-func (o *OllamaPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error) {
+func (o *OllamaPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
 	// Start tracking the operation
 	reportErr, reportChange, end := o.tracker.Start(ctx, "prompt", "ollama", "model", o.modelName)
 	defer end()
@@ -25,6 +25,9 @@ func (o *OllamaPromptClient) Prompt(ctx context.Context, systemInstruction strin
 	stream := false
 	config := &modelrepo.ChatConfig{}
 	modelrepo.WithTemperature(float64(temperature)).Apply(config)
+	for _, arg := range args {
+		arg.Apply(config)
+	}
 	think := buildOllamaThink(config)
 	req := &api.GenerateRequest{
 		Model:   o.modelName,
@@ -20,6 +20,13 @@ type OllamaStreamClient struct {
 func (c *OllamaStreamClient) Stream(ctx context.Context, messages []modelrepo.Message, args ...modelrepo.ChatArgument) (<-chan *modelrepo.StreamParcel, error) {
 	reportErr, reportChange, end := c.tracker.Start(ctx, "stream", "ollama", "model", c.modelName)
 
+	config := &modelrepo.ChatConfig{}
+	for _, arg := range args {
+		arg.Apply(config)
+	}
+	// Ollama has no tool_choice parameter.
+	messages = modelrepo.EmulateToolChoice(config, messages)
+
 	apiMessages := make([]api.Message, 0, len(messages))
 	for _, msg := range messages {
 		var apiToolCalls []api.ToolCall
@@ -39,18 +46,20 @@ func (c *OllamaStreamClient) Stream(ctx context.Context, messages []modelrepo.Me
 				})
 			}
 		}
+		images, err := buildOllamaImages(msg)
+		if err != nil {
+			reportErr(err)
+			end()
+			return nil, err
+		}
 		apiMessages = append(apiMessages, api.Message{
 			Role:      msg.Role,
 			Content:   msg.Content,
 			ToolCalls: apiToolCalls,
+			Images:    images,
 		})
 	}
 
-	config := &modelrepo.ChatConfig{}
-	for _, arg := range args {
-		arg.Apply(config)
-	}
-
 	stream := true
 	think := buildOllamaThink(config)
 	apiTools, err := buildOllamaTools(config)
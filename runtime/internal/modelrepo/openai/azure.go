@@ -0,0 +1,185 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI data-plane API version used when
+// the backend URL does not name one.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// ErrAzureDeploymentListingUnavailable is returned by the Azure OpenAI
+// catalog when the resource does not serve the deployment listing and the
+// backend URL declares no deployments.
+var ErrAzureDeploymentListingUnavailable = errors.New("Azure OpenAI resource does not list its deployments")
+
+// azureDeploymentsAPIVersion is the last API version serving the deployment
+// listing on the data plane.
+const azureDeploymentsAPIVersion = "2022-12-01"
+
+// ParseAzureEndpoint splits an Azure OpenAI backend URL such as
+// "https://myresource.openai.azure.com/?api-version=2024-10-21" into the
+// resource endpoint and the API version (DefaultAzureAPIVersion when absent).
+// A trailing "/openai" path is dropped. The optional "deployments" query
+// parameter is read by azureDeclaredDeployments.
+func ParseAzureEndpoint(raw string) (endpoint, apiVersion string, err error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid Azure OpenAI endpoint %q: %w", raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid Azure OpenAI endpoint %q: want https://<resource>.openai.azure.com", raw)
+	}
+	apiVersion = u.Query().Get("api-version")
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+	path := strings.TrimSuffix(strings.TrimRight(u.Path, "/"), "/openai")
+	return u.Scheme + "://" + u.Host + path, apiVersion, nil
+}
+
+// azureDeclaredDeployments returns the comma-separated deployment names of
+// the "deployments" query parameter of an Azure OpenAI backend URL. They are
+// served when the resource does not list its deployments.
+func azureDeclaredDeployments(raw string) []string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil
+	}
+	var deployments []string
+	for _, name := range strings.Split(u.Query().Get("deployments"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			deployments = append(deployments, name)
+		}
+	}
+	return deployments
+}
+
+// NewAzureOpenAIProvider returns a provider for the Azure OpenAI deployment
+// named deployment. Requests go to the deployment at endpoint with the given
+// API version and authenticate with the api-key header.
+func NewAzureOpenAIProvider(apiKey, deployment, endpoint, apiVersion string, capability modelrepo.CapabilityConfig, httpClient *http.Client, tracker libtracker.ActivityTracker) modelrepo.Provider {
+	p := NewOpenAIProvider(apiKey, deployment, []string{endpoint}, capability, httpClient, tracker).(*OpenAIProvider)
+	p.id = fmt.Sprintf("azure-openai-%s", deployment)
+	p.providerType = "azure-openai"
+	p.apiVersion = apiVersion
+	return p
+}
+
+type azureCatalogProvider struct {
+	spec       modelrepo.BackendSpec
+	httpClient *http.Client
+	tracker    libtracker.ActivityTracker
+}
+
+func init() {
+	modelrepo.RegisterCatalogProvider("azure-openai", func(spec modelrepo.BackendSpec, opts modelrepo.CatalogOptions) (modelrepo.CatalogProvider, error) {
+		if _, _, err := ParseAzureEndpoint(spec.BaseURL); err != nil {
+			return nil, err
+		}
+		return &azureCatalogProvider{
+			spec:       spec,
+			httpClient: opts.HTTPClient,
+			tracker:    opts.Tracker,
+		}, nil
+	})
+}
+
+func (p *azureCatalogProvider) Type() string {
+	return "azure-openai"
+}
+
+// ListModels lists the deployments of the resource. Each is observed under
+// its deployment name with the capabilities of the model it serves; the
+// model is kept in Meta["model"]. Resources that no longer serve the listing
+// fall back to the deployments declared in the backend URL, whose
+// capabilities are inferred from the deployment name.
+func (p *azureCatalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedModel, error) {
+	endpoint, _, err := ParseAzureEndpoint(p.spec.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/openai/deployments?api-version="+azureDeploymentsAPIVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.spec.APIKey != "" {
+		req.Header.Set("api-key", p.spec.APIKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		declared := azureDeclaredDeployments(p.spec.BaseURL)
+		if len(declared) == 0 {
+			return nil, fmt.Errorf("%w (%d): name them in the backend URL, e.g. ?deployments=gpt-4o,text-embedding-3-small", ErrAzureDeploymentListingUnavailable, resp.StatusCode)
+		}
+		models := make([]modelrepo.ObservedModel, 0, len(declared))
+		for _, name := range declared {
+			models = append(models, inferObservedModel(name))
+		}
+		return models, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Azure OpenAI deployment listing returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data []struct {
+			ID     string `json:"id"`
+			Model  string `json:"model"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode Azure OpenAI deployment listing: %w", err)
+	}
+
+	models := make([]modelrepo.ObservedModel, 0, len(payload.Data))
+	for _, d := range payload.Data {
+		if d.Status != "" && d.Status != "succeeded" {
+			continue
+		}
+		observed := inferObservedModel(d.Model)
+		observed.Name = d.ID
+		observed.Meta = map[string]string{"model": d.Model}
+		models = append(models, observed)
+	}
+	return models, nil
+}
+
+func (p *azureCatalogProvider) ProviderFor(model modelrepo.ObservedModel) modelrepo.Provider {
+	endpoint, apiVersion, err := ParseAzureEndpoint(p.spec.BaseURL)
+	if err != nil {
+		endpoint, apiVersion = p.spec.BaseURL, DefaultAzureAPIVersion
+	}
+	return NewAzureOpenAIProvider(
+		p.spec.APIKey,
+		model.Name,
+		endpoint,
+		apiVersion,
+		model.CapabilityConfig,
+		p.httpClient,
+		p.tracker,
+	)
+}
+
+var _ modelrepo.CatalogProvider = (*azureCatalogProvider)(nil)
@@ -0,0 +1,120 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAzureEndpoint(t *testing.T) {
+	endpoint, apiVersion, err := ParseAzureEndpoint("https://res.openai.azure.com/openai/?api-version=2025-01-01-preview&deployments=a")
+	require.NoError(t, err)
+	assert.Equal(t, "https://res.openai.azure.com", endpoint)
+	assert.Equal(t, "2025-01-01-preview", apiVersion)
+
+	_, apiVersion, err = ParseAzureEndpoint("https://res.openai.azure.com")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAzureAPIVersion, apiVersion)
+
+	_, _, err = ParseAzureEndpoint("res.openai.azure.com")
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"gpt-4o", "embed"}, azureDeclaredDeployments("https://res.openai.azure.com/?deployments=gpt-4o,+embed,"))
+}
+
+func TestAzureCatalogProvider_ListsDeployments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/openai/deployments", r.URL.Path)
+		require.Equal(t, azureDeploymentsAPIVersion, r.URL.Query().Get("api-version"))
+		require.Equal(t, "test-key", r.Header.Get("api-key"))
+		require.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"data":[
+			{"id":"chat","model":"gpt-4o","status":"succeeded"},
+			{"id":"vectors","model":"text-embedding-3-small","status":"succeeded"},
+			{"id":"pending","model":"gpt-4o","status":"running"}]}`)
+	}))
+	defer srv.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "azure-openai", BaseURL: srv.URL + "/?api-version=2024-10-21", APIKey: "test-key"})
+	require.NoError(t, err)
+
+	models, err := catalog.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, "chat", models[0].Name)
+	assert.True(t, models[0].CanChat)
+	assert.Equal(t, "gpt-4o", models[0].Meta["model"])
+	assert.Equal(t, "vectors", models[1].Name)
+	assert.True(t, models[1].CanEmbed)
+
+	provider := catalog.ProviderFor(models[0])
+	assert.Equal(t, "azure-openai", provider.GetType())
+	assert.Equal(t, "chat", provider.ModelName())
+}
+
+func TestAzureCatalogProvider_FallsBackToDeclaredDeployments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"code":"404","message":"Resource not found"}}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "azure-openai", BaseURL: srv.URL + "/?deployments=gpt-4o", APIKey: "test-key"})
+	require.NoError(t, err)
+	models, err := catalog.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "gpt-4o", models[0].Name)
+	assert.True(t, models[0].CanChat)
+
+	catalog, err = modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "azure-openai", BaseURL: srv.URL, APIKey: "test-key"})
+	require.NoError(t, err)
+	_, err = catalog.ListModels(context.Background())
+	require.ErrorIs(t, err, ErrAzureDeploymentListingUnavailable)
+}
+
+func TestAzureOpenAIProvider_AddressesDeployment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "2024-10-21", r.URL.Query().Get("api-version"))
+		require.Equal(t, "test-key", r.Header.Get("api-key"))
+		require.Empty(t, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/openai/deployments/my chat/chat/completions":
+			var req map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			fmt.Fprint(w, `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+		case "/openai/deployments/vectors/embeddings":
+			fmt.Fprint(w, `{"object":"list","data":[{"index":0,"embedding":[1,0]}],"model":"text-embedding-3-small"}`)
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var capability modelrepo.CapabilityConfig
+	capability.CanChat = true
+	chatProvider := NewAzureOpenAIProvider("test-key", "my chat", srv.URL, "2024-10-21", capability, srv.Client(), libtracker.NoopTracker{})
+	chat, err := chatProvider.GetChatConnection(context.Background(), "backend")
+	require.NoError(t, err)
+	result, err := chat.Chat(context.Background(), []modelrepo.Message{{Role: "user", Content: "hello"}})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Message.Content)
+
+	capability = modelrepo.CapabilityConfig{}
+	capability.CanEmbed = true
+	embedProvider := NewAzureOpenAIProvider("test-key", "vectors", srv.URL, "2024-10-21", capability, srv.Client(), libtracker.NoopTracker{})
+	embed, err := embedProvider.GetEmbedConnection(context.Background(), "backend")
+	require.NoError(t, err)
+	vec, err := embed.Embed(context.Background(), "text")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 0}, vec)
+}
@@ -35,26 +35,44 @@ func (p *catalogProvider) Type() string {
 }
 
 func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedModel, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.baseURL(), "/")+"/models", nil)
+	listing, err := p.ListModelsConditional(ctx, modelrepo.CatalogValidators{})
 	if err != nil {
 		return nil, err
 	}
+	return listing.Models, nil
+}
+
+// ListModelsConditional lists models, sending validators as conditional
+// request headers so an unchanged catalog is answered with 304 Not Modified.
+func (p *catalogProvider) ListModelsConditional(ctx context.Context, validators modelrepo.CatalogValidators) (modelrepo.CatalogListing, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.baseURL(), "/")+"/models", nil)
+	if err != nil {
+		return modelrepo.CatalogListing{}, err
+	}
 	if p.spec.APIKey != "" {
 		req.Header.Set("Authorization", "Bearer "+p.spec.APIKey)
 	}
+	validators.Apply(req)
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return modelrepo.CatalogListing{}, err
 	}
 	defer resp.Body.Close()
 
+	listing := modelrepo.CatalogListing{}
+	listing.Validators, listing.MaxAge = modelrepo.CatalogCacheInfo(resp)
+	if resp.StatusCode == http.StatusNotModified {
+		listing.NotModified = true
+		return listing, nil
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return modelrepo.CatalogListing{}, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI catalog returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return modelrepo.CatalogListing{}, fmt.Errorf("OpenAI catalog returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	var payload struct {
@@ -63,14 +81,14 @@ func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedM
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, fmt.Errorf("decode OpenAI catalog response: %w", err)
+		return modelrepo.CatalogListing{}, fmt.Errorf("decode OpenAI catalog response: %w", err)
 	}
 
-	models := make([]modelrepo.ObservedModel, 0, len(payload.Data))
+	listing.Models = make([]modelrepo.ObservedModel, 0, len(payload.Data))
 	for _, item := range payload.Data {
-		models = append(models, inferObservedModel(item.ID))
+		listing.Models = append(listing.Models, inferObservedModel(item.ID))
 	}
-	return models, nil
+	return listing, nil
 }
 
 func (p *catalogProvider) ProviderFor(model modelrepo.ObservedModel) modelrepo.Provider {
@@ -136,3 +154,5 @@ func inferObservedModel(id string) modelrepo.ObservedModel {
 
 	return observed
 }
+
+var _ modelrepo.ConditionalCatalogProvider = (*catalogProvider)(nil)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/contenox/contenox/runtime/internal/modelrepo"
 	"github.com/stretchr/testify/require"
@@ -51,3 +52,39 @@ func TestCatalogProvider_ListModels(t *testing.T) {
 	require.Equal(t, "openai", provider.GetType())
 	require.Equal(t, "gpt-5", provider.ModelName())
 }
+
+func TestCatalogProvider_ListModelsConditional(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "private, max-age=600")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "gpt-5"}},
+		})
+	}))
+	defer server.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "openai", BaseURL: server.URL})
+	require.NoError(t, err)
+	conditional, ok := catalog.(modelrepo.ConditionalCatalogProvider)
+	require.True(t, ok)
+
+	listing, err := conditional.ListModelsConditional(context.Background(), modelrepo.CatalogValidators{})
+	require.NoError(t, err)
+	require.False(t, listing.NotModified)
+	require.Len(t, listing.Models, 1)
+	require.Equal(t, `"v1"`, listing.Validators.ETag)
+	require.NotNil(t, listing.MaxAge)
+	require.Equal(t, 600*time.Second, *listing.MaxAge)
+
+	listing, err = conditional.ListModelsConditional(context.Background(), listing.Validators)
+	require.NoError(t, err)
+	require.True(t, listing.NotModified)
+	require.Empty(t, listing.Models)
+	require.Equal(t, 2, requests)
+}
@@ -44,7 +44,7 @@ func (c *OpenAIChatClient) Chat(ctx context.Context, messages []modelrepo.Messag
 	reportErr, reportChange, end := c.tracker.Start(ctx, "chat", "openai", "model", c.modelName)
 	defer end()
 
-	req, nameMap := buildOpenAIRequest(c.modelName, messages, args)
+	req, nameMap := c.buildRequest(messages, args)
 
 	var response openAIChatCompletionResponse
 
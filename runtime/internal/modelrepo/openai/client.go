@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +23,31 @@ type openAIClient struct {
 	modelName  string
 	maxTokens  int
 	tracker    libtracker.ActivityTracker
+	// apiVersion is set for Azure OpenAI, where modelName is a deployment.
+	apiVersion string
+	// providerType selects the request dialect of OpenAI-compatible hosted
+	// providers, see adaptHostedRequest.
+	providerType string
+}
+
+// endpointURL returns the URL of the API path endpoint, e.g. "/chat/completions".
+// Azure OpenAI addresses the deployment in the path and versions the API
+// with the api-version query parameter.
+func (c *openAIClient) endpointURL(endpoint string) string {
+	if c.apiVersion == "" {
+		return c.baseURL + endpoint
+	}
+	return c.baseURL + "/openai/deployments/" + url.PathEscape(c.modelName) + endpoint + "?api-version=" + url.QueryEscape(c.apiVersion)
+}
+
+// setAuth authenticates req: with a bearer token, or the api-key header on
+// Azure OpenAI.
+func (c *openAIClient) setAuth(req *http.Request) {
+	if c.apiVersion != "" {
+		req.Header.Set("api-key", c.apiKey)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 }
 
 type openAIChatRequest struct {
@@ -29,10 +55,17 @@ type openAIChatRequest struct {
 	Messages            []apiChatMessage `json:"messages"`
 	Temperature         *float64         `json:"temperature,omitempty"`
 	MaxCompletionTokens *int             `json:"max_completion_tokens,omitempty"`
+	MaxTokens           *int             `json:"max_tokens,omitempty"`
 	TopP                *float64         `json:"top_p,omitempty"`
 	Seed                *int             `json:"seed,omitempty"`
+	RandomSeed          *int             `json:"random_seed,omitempty"`
+	FrequencyPenalty    *float64         `json:"frequency_penalty,omitempty"`
+	PresencePenalty     *float64         `json:"presence_penalty,omitempty"`
+	Stop                []string         `json:"stop,omitempty"`
 	Stream              bool             `json:"stream,omitempty"`
 	Tools               []openAITool     `json:"tools,omitempty"`
+	// ToolChoice is "none", "auto", "required" or a named function.
+	ToolChoice any `json:"tool_choice,omitempty"`
 	// ReasoningEffort maps the existing modelrepo.WithThink values onto OpenAI's
 	// chat-completions `reasoning_effort` parameter without widening the public
 	// package API. Supported values are model-dependent.
@@ -40,14 +73,26 @@ type openAIChatRequest struct {
 }
 
 // apiChatMessage is the wire-format message sent to the OpenAI REST API.
-// We use *string for Content so assistant messages with tool_calls can have null content.
+// Content is a *string so assistant messages with tool_calls can have null
+// content, or an []apiContentPart for messages with images.
 type apiChatMessage struct {
 	Role       string           `json:"role"`
-	Content    *string          `json:"content"`
+	Content    any              `json:"content"`
 	ToolCallID string           `json:"tool_call_id,omitempty"`
 	ToolCalls  []apiToolCallReq `json:"tool_calls,omitempty"`
 }
 
+// apiContentPart is a text or image_url part of a multimodal message.
+type apiContentPart struct {
+	Type     string       `json:"type"`
+	Text     string       `json:"text,omitempty"`
+	ImageURL *apiImageURL `json:"image_url,omitempty"`
+}
+
+type apiImageURL struct {
+	URL string `json:"url"`
+}
+
 type apiToolCallReq struct {
 	ID       string          `json:"id"`
 	Type     string          `json:"type"`
@@ -71,7 +116,7 @@ type openAIFunction struct {
 }
 
 func (c *openAIClient) sendRequest(ctx context.Context, endpoint string, request any, response any) error {
-	url := c.baseURL + endpoint
+	url := c.endpointURL(endpoint)
 
 	tracker := c.tracker
 	// Never log API key material (even a prefix) in activity telemetry — trace logs are not secret-safe.
@@ -115,7 +160,7 @@ func (c *openAIClient) sendRequest(ctx context.Context, endpoint string, request
 			return err
 		}
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		c.setAuth(req)
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
@@ -199,6 +244,37 @@ func parseRetryAfterMs(h http.Header) time.Duration {
 	return 2 * time.Second
 }
 
+// openAIToolChoice returns the tool_choice of the request, or nil for the
+// default. Forced function names are sanitized like the tools.
+func openAIToolChoice(choice *modelrepo.ToolChoice, origToSanitized map[string]string) any {
+	switch {
+	case choice == nil:
+		return nil
+	case choice.Name != "":
+		name, ok := origToSanitized[choice.Name]
+		if !ok {
+			name = sanitizeToolName(choice.Name)
+		}
+		return map[string]any{"type": "function", "function": map[string]string{"name": name}}
+	case choice.Mode != "":
+		return choice.Mode
+	default:
+		return nil
+	}
+}
+
+// buildRequest builds the chat request for the client's model in the
+// dialect of its provider.
+func (c *openAIClient) buildRequest(messages []modelrepo.Message, args []modelrepo.ChatArgument) (openAIChatRequest, map[string]string) {
+	req, nameMap := buildOpenAIRequest(c.modelName, messages, args)
+	cfg := &modelrepo.ChatConfig{}
+	for _, a := range args {
+		a.Apply(cfg)
+	}
+	adaptHostedRequest(c.providerType, &req, cfg.Think)
+	return req, nameMap
+}
+
 // buildOpenAIRequest builds a compliant request and sanitizes tool names per
 // OpenAI's pattern (^[a-zA-Z0-9_-]+$). It ALSO returns a map from
 // sanitized->original so callers can translate tool-call names back.
@@ -222,6 +298,10 @@ func buildOpenAIRequest(modelName string, messages []modelrepo.Message, args []m
 	req.MaxCompletionTokens = cfg.MaxTokens
 	req.TopP = cfg.TopP
 	req.Seed = cfg.Seed
+	req.FrequencyPenalty = cfg.FrequencyPenalty
+	req.PresencePenalty = cfg.PresencePenalty
+	req.Stop = cfg.Stop
+	// OpenAI has no top_k; cfg.TopK is ignored.
 
 	req.ReasoningEffort = openAIReasoningEffort(modelName, cfg.Think)
 
@@ -231,6 +311,8 @@ func buildOpenAIRequest(modelName string, messages []modelrepo.Message, args []m
 	if openAIShouldOmitSamplingParams(modelName, req.ReasoningEffort) {
 		req.Temperature = nil
 		req.TopP = nil
+		req.FrequencyPenalty = nil
+		req.PresencePenalty = nil
 	}
 
 	// Convert tools to OpenAI tools with sanitized/unique function names.
@@ -268,6 +350,9 @@ func buildOpenAIRequest(modelName string, messages []modelrepo.Message, args []m
 	for san, orig := range nameMap {
 		origToSanitized[orig] = san
 	}
+	if len(req.Tools) > 0 {
+		req.ToolChoice = openAIToolChoice(cfg.ToolChoice, origToSanitized)
+	}
 
 	// Convert messages to the explicit wire format.
 	// • Content is *string so assistant messages with tool_calls can have a null body.
@@ -287,6 +372,16 @@ func buildOpenAIRequest(modelName string, messages []modelrepo.Message, args []m
 			Content:    contentPtr,
 			ToolCallID: msg.ToolCallID,
 		}
+		if len(msg.Attachments) > 0 {
+			parts := make([]apiContentPart, 0, len(msg.Attachments)+1)
+			if content != "" {
+				parts = append(parts, apiContentPart{Type: "text", Text: content})
+			}
+			for _, a := range msg.Attachments {
+				parts = append(parts, apiContentPart{Type: "image_url", ImageURL: &apiImageURL{URL: a.URLOrData()}})
+			}
+			apiMsg.Content = parts
+		}
 
 		if len(msg.ToolCalls) > 0 {
 			apiMsg.ToolCalls = make([]apiToolCallReq, 0, len(msg.ToolCalls))
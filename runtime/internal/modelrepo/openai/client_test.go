@@ -143,3 +143,102 @@ func TestBuildOpenAIRequest_GPT4KeepsTemperature(t *testing.T) {
 		t.Fatalf("temperature = %v, want 0.7", *req.Temperature)
 	}
 }
+
+func TestBuildOpenAIRequest_SamplingParams(t *testing.T) {
+	t.Parallel()
+	msgs := []modelrepo.Message{{Role: "user", Content: "hi"}}
+	req, _ := buildOpenAIRequest("gpt-4o", msgs, []modelrepo.ChatArgument{
+		modelrepo.WithTopP(0.9),
+		modelrepo.WithTopK(40),
+		modelrepo.WithFrequencyPenalty(0.5),
+		modelrepo.WithPresencePenalty(-0.5),
+		modelrepo.WithStop("###", "END"),
+	})
+	raw, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(raw)
+	for _, want := range []string{`"top_p":0.9`, `"frequency_penalty":0.5`, `"presence_penalty":-0.5`, `"stop":["###","END"]`} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("expected %s in payload, got %s", want, s)
+		}
+	}
+	if strings.Contains(s, "top_k") {
+		t.Fatalf("OpenAI has no top_k, got %s", s)
+	}
+}
+
+func TestBuildOpenAIRequest_GPT5OmitsPenalties(t *testing.T) {
+	t.Parallel()
+	msgs := []modelrepo.Message{{Role: "user", Content: "hi"}}
+	req, _ := buildOpenAIRequest("gpt-5", msgs, []modelrepo.ChatArgument{
+		modelrepo.WithFrequencyPenalty(0.5),
+		modelrepo.WithPresencePenalty(0.5),
+		modelrepo.WithStop("###"),
+	})
+	if req.FrequencyPenalty != nil || req.PresencePenalty != nil {
+		t.Fatal("expected penalties omitted for gpt-5")
+	}
+	if len(req.Stop) != 1 {
+		t.Fatalf("stop = %v, want [###]", req.Stop)
+	}
+}
+
+func TestBuildOpenAIRequest_ImageAttachments(t *testing.T) {
+	t.Parallel()
+	msgs := []modelrepo.Message{{
+		Role:    "user",
+		Content: "what is this?",
+		Attachments: []modelrepo.Attachment{
+			{MimeType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}},
+			{MimeType: "image/jpeg", URL: "https://example.com/cat.jpg"},
+		},
+	}}
+	req, _ := buildOpenAIRequest("gpt-4o", msgs, nil)
+	b, err := json.Marshal(req.Messages[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"role":"user","content":[{"type":"text","text":"what is this?"},` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw=="}},` +
+		`{"type":"image_url","image_url":{"url":"https://example.com/cat.jpg"}}]}`
+	if string(b) != want {
+		t.Fatalf("message = %s\nwant %s", b, want)
+	}
+}
+
+func TestBuildOpenAIRequest_ToolChoice(t *testing.T) {
+	t.Parallel()
+	msgs := []modelrepo.Message{{Role: "user", Content: "hi"}}
+	tool := modelrepo.Tool{Type: "function", Function: &modelrepo.FunctionTool{Name: "local_shell.run"}}
+	cases := []struct {
+		choice modelrepo.ToolChoice
+		want   string
+	}{
+		{modelrepo.ToolChoice{Mode: modelrepo.ToolChoiceNone}, `"tool_choice":"none"`},
+		{modelrepo.ToolChoice{Mode: modelrepo.ToolChoiceRequired}, `"tool_choice":"required"`},
+		{modelrepo.ToolChoice{Name: "local_shell.run"}, `"tool_choice":{"function":{"name":"local_shell_run"},"type":"function"}`},
+	}
+	for _, tc := range cases {
+		req, _ := buildOpenAIRequest("gpt-4o", msgs, []modelrepo.ChatArgument{
+			modelrepo.WithTools(tool),
+			modelrepo.WithToolChoice(tc.choice),
+		})
+		raw, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(raw), tc.want) {
+			t.Errorf("request %s does not contain %s", raw, tc.want)
+		}
+	}
+
+	// Without tools there is nothing to choose from.
+	req, _ := buildOpenAIRequest("gpt-4o", msgs, []modelrepo.ChatArgument{
+		modelrepo.WithToolChoice(modelrepo.ToolChoice{Mode: modelrepo.ToolChoiceNone}),
+	})
+	if req.ToolChoice != nil {
+		t.Fatalf("expected tool_choice omitted without tools, got %v", req.ToolChoice)
+	}
+}
@@ -63,4 +63,54 @@ func (c *OpenAIEmbedClient) Embed(ctx context.Context, prompt string) ([]float64
 	return embedding, nil
 }
 
-var _ modelrepo.LLMEmbedClient = (*OpenAIEmbedClient)(nil)
+type openAIEmbedBatchRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+}
+
+// EmbedBatch embeds all prompts with a single /embeddings request.
+func (c *OpenAIEmbedClient) EmbedBatch(ctx context.Context, prompts []string) ([][]float64, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "embed_batch", "openai", "model", c.modelName)
+	defer end()
+
+	request := openAIEmbedBatchRequest{
+		Model:          c.modelName,
+		Input:          prompts,
+		EncodingFormat: "float",
+	}
+
+	var response openAIEmbedResponse
+	if err := c.sendRequest(ctx, "/embeddings", request, &response); err != nil {
+		reportErr(err)
+		return nil, err
+	}
+	if len(response.Data) != len(prompts) {
+		err := fmt.Errorf("OpenAI returned %d embeddings for %d inputs for model %s", len(response.Data), len(prompts), c.modelName)
+		reportErr(err)
+		return nil, err
+	}
+
+	// Data is not guaranteed to be in input order; Index identifies the input.
+	embeddings := make([][]float64, len(prompts))
+	for _, d := range response.Data {
+		if d.Index < 0 || d.Index >= len(prompts) || embeddings[d.Index] != nil || len(d.Embedding) == 0 {
+			err := fmt.Errorf("invalid embedding at index %d returned from OpenAI for model %s", d.Index, c.modelName)
+			reportErr(err)
+			return nil, err
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+
+	reportChange("embedding_completed", map[string]any{
+		"inputs":        len(prompts),
+		"prompt_tokens": response.Usage.PromptTokens,
+		"total_tokens":  response.Usage.TotalTokens,
+	})
+	return embeddings, nil
+}
+
+var (
+	_ modelrepo.LLMEmbedClient      = (*OpenAIEmbedClient)(nil)
+	_ modelrepo.LLMBatchEmbedClient = (*OpenAIEmbedClient)(nil)
+)
@@ -0,0 +1,47 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbedClient_EmbedBatchOrdersByIndex(t *testing.T) {
+	t.Parallel()
+
+	var inputs [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/embeddings", r.URL.Path)
+		var req openAIEmbedBatchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		inputs = append(inputs, req.Input)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}],"model":"text-embedding-3-small"}`)
+	}))
+	defer srv.Close()
+
+	client := &OpenAIEmbedClient{
+		openAIClient: openAIClient{
+			baseURL:    srv.URL,
+			apiKey:     "test-key",
+			httpClient: srv.Client(),
+			modelName:  "text-embedding-3-small",
+			tracker:    libtracker.NoopTracker{},
+		},
+	}
+
+	vecs, err := client.EmbedBatch(context.Background(), []string{"first", "second"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, vecs)
+	assert.Equal(t, [][]string{{"first", "second"}}, inputs, "one request for both inputs")
+
+	_, err = client.EmbedBatch(context.Background(), []string{"first", "second", "third"})
+	require.Error(t, err)
+}
@@ -0,0 +1,246 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// Default API base URLs of the OpenAI-compatible hosted providers.
+const (
+	DefaultMistralBaseURL = "https://api.mistral.ai/v1"
+	DefaultGroqBaseURL    = "https://api.groq.com/openai/v1"
+)
+
+// hostedProvider describes an OpenAI-compatible hosted provider: its name in
+// errors, its default base URL and how its model listing maps to observed
+// models.
+type hostedProvider struct {
+	displayName    string
+	defaultBaseURL string
+	decodeModels   func(body []byte) ([]modelrepo.ObservedModel, error)
+}
+
+var hostedProviders = map[string]hostedProvider{
+	"mistral": {
+		displayName:    "Mistral",
+		defaultBaseURL: DefaultMistralBaseURL,
+		decodeModels:   decodeMistralModels,
+	},
+	"groq": {
+		displayName:    "Groq",
+		defaultBaseURL: DefaultGroqBaseURL,
+		decodeModels:   decodeGroqModels,
+	},
+}
+
+// NewHostedProvider returns a provider for modelName on the OpenAI-compatible
+// hosted provider providerType ("mistral" or "groq") at baseURL.
+func NewHostedProvider(providerType, apiKey, modelName, baseURL string, capability modelrepo.CapabilityConfig, httpClient *http.Client, tracker libtracker.ActivityTracker) modelrepo.Provider {
+	p := NewOpenAIProvider(apiKey, modelName, []string{baseURL}, capability, httpClient, tracker).(*OpenAIProvider)
+	p.id = fmt.Sprintf("%s-%s", providerType, modelName)
+	p.providerType = providerType
+	return p
+}
+
+type hostedCatalogProvider struct {
+	providerType string
+	hosted       hostedProvider
+	spec         modelrepo.BackendSpec
+	httpClient   *http.Client
+	tracker      libtracker.ActivityTracker
+}
+
+func init() {
+	for providerType, hosted := range hostedProviders {
+		modelrepo.RegisterCatalogProvider(providerType, func(spec modelrepo.BackendSpec, opts modelrepo.CatalogOptions) (modelrepo.CatalogProvider, error) {
+			return &hostedCatalogProvider{
+				providerType: providerType,
+				hosted:       hosted,
+				spec:         spec,
+				httpClient:   opts.HTTPClient,
+				tracker:      opts.Tracker,
+			}, nil
+		})
+	}
+}
+
+func (p *hostedCatalogProvider) Type() string {
+	return p.providerType
+}
+
+func (p *hostedCatalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedModel, error) {
+	listing, err := p.ListModelsConditional(ctx, modelrepo.CatalogValidators{})
+	if err != nil {
+		return nil, err
+	}
+	return listing.Models, nil
+}
+
+// ListModelsConditional lists the models of the provider, sending validators
+// as conditional request headers so an unchanged catalog is answered with
+// 304 Not Modified.
+func (p *hostedCatalogProvider) ListModelsConditional(ctx context.Context, validators modelrepo.CatalogValidators) (modelrepo.CatalogListing, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.baseURL(), "/")+"/models", nil)
+	if err != nil {
+		return modelrepo.CatalogListing{}, err
+	}
+	if p.spec.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.spec.APIKey)
+	}
+	validators.Apply(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return modelrepo.CatalogListing{}, err
+	}
+	defer resp.Body.Close()
+
+	listing := modelrepo.CatalogListing{}
+	listing.Validators, listing.MaxAge = modelrepo.CatalogCacheInfo(resp)
+	if resp.StatusCode == http.StatusNotModified {
+		listing.NotModified = true
+		return listing, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return modelrepo.CatalogListing{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return modelrepo.CatalogListing{}, fmt.Errorf("%s catalog returned %d: %s", p.hosted.displayName, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	listing.Models, err = p.hosted.decodeModels(body)
+	if err != nil {
+		return modelrepo.CatalogListing{}, fmt.Errorf("decode %s catalog response: %w", p.hosted.displayName, err)
+	}
+	return listing, nil
+}
+
+func (p *hostedCatalogProvider) ProviderFor(model modelrepo.ObservedModel) modelrepo.Provider {
+	return NewHostedProvider(
+		p.providerType,
+		p.spec.APIKey,
+		model.Name,
+		p.baseURL(),
+		model.CapabilityConfig,
+		p.httpClient,
+		p.tracker,
+	)
+}
+
+func (p *hostedCatalogProvider) baseURL() string {
+	base := strings.TrimSpace(p.spec.BaseURL)
+	if base == "" {
+		return p.hosted.defaultBaseURL
+	}
+	return base
+}
+
+// decodeMistralModels maps the Mistral model listing, which reports the
+// capabilities and context window of each model. Embedding models are the
+// ones named *embed*; models without chat completion (OCR, moderation) are
+// listed without capabilities.
+func decodeMistralModels(body []byte) ([]modelrepo.ObservedModel, error) {
+	var payload struct {
+		Data []struct {
+			ID           string `json:"id"`
+			Capabilities struct {
+				CompletionChat bool `json:"completion_chat"`
+			} `json:"capabilities"`
+			MaxContextLength int `json:"max_context_length"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	models := make([]modelrepo.ObservedModel, 0, len(payload.Data))
+	for _, item := range payload.Data {
+		observed := modelrepo.ObservedModel{Name: item.ID, ContextLength: item.MaxContextLength}
+		observed.CapabilityConfig.ContextLength = item.MaxContextLength
+		switch {
+		case strings.Contains(strings.ToLower(item.ID), "embed"):
+			observed.CanEmbed = true
+		case item.Capabilities.CompletionChat:
+			observed.CanChat = true
+			observed.CanPrompt = true
+			observed.CanStream = true
+		}
+		models = append(models, observed)
+	}
+	return models, nil
+}
+
+// decodeGroqModels maps the Groq model listing. Inactive models are skipped;
+// speech-to-text and text-to-speech models are listed without capabilities.
+// Groq serves no embedding models.
+func decodeGroqModels(body []byte) ([]modelrepo.ObservedModel, error) {
+	var payload struct {
+		Data []struct {
+			ID            string `json:"id"`
+			Active        *bool  `json:"active"`
+			ContextWindow int    `json:"context_window"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	models := make([]modelrepo.ObservedModel, 0, len(payload.Data))
+	for _, item := range payload.Data {
+		if item.Active != nil && !*item.Active {
+			continue
+		}
+		observed := modelrepo.ObservedModel{Name: item.ID, ContextLength: item.ContextWindow}
+		observed.CapabilityConfig.ContextLength = item.ContextWindow
+		lower := strings.ToLower(item.ID)
+		if !strings.Contains(lower, "whisper") && !strings.Contains(lower, "tts") && !strings.Contains(lower, "orpheus") {
+			observed.CanChat = true
+			observed.CanPrompt = true
+			observed.CanStream = true
+		}
+		models = append(models, observed)
+	}
+	return models, nil
+}
+
+// adaptHostedRequest rewrites an OpenAI chat request into the dialect of the
+// hosted provider providerType. Mistral names the token limit max_tokens and
+// the seed random_seed, and has no reasoning_effort. Groq only accepts
+// reasoning_effort for gpt-oss models (low, medium, high) and Qwen 3 models
+// (none, default), where think switches reasoning off or on.
+func adaptHostedRequest(providerType string, req *openAIChatRequest, think *string) {
+	switch providerType {
+	case "mistral":
+		req.MaxTokens, req.MaxCompletionTokens = req.MaxCompletionTokens, nil
+		req.RandomSeed, req.Seed = req.Seed, nil
+		req.ReasoningEffort = ""
+	case "groq":
+		model := strings.ToLower(req.Model)
+		switch {
+		case strings.Contains(model, "gpt-oss"):
+			if req.ReasoningEffort == "none" {
+				req.ReasoningEffort = ""
+			}
+		case strings.Contains(model, "qwen3"):
+			req.ReasoningEffort = ""
+			if think != nil {
+				switch strings.ToLower(strings.TrimSpace(*think)) {
+				case "", "false", "none":
+					req.ReasoningEffort = "none"
+				default:
+					req.ReasoningEffort = "default"
+				}
+			}
+		default:
+			req.ReasoningEffort = ""
+		}
+	}
+}
+
+var _ modelrepo.ConditionalCatalogProvider = (*hostedCatalogProvider)(nil)
@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostedCatalogProvider_Mistral(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/models", r.URL.Path)
+		require.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","data":[
+			{"id":"mistral-large-latest","capabilities":{"completion_chat":true,"function_calling":true},"max_context_length":131072},
+			{"id":"mistral-embed","capabilities":{"completion_chat":false},"max_context_length":8192},
+			{"id":"mistral-ocr-latest","capabilities":{"completion_chat":false},"max_context_length":32768}
+		]}`)
+	}))
+	defer srv.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "mistral", BaseURL: srv.URL, APIKey: "test-key"})
+	require.NoError(t, err)
+	models, err := catalog.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+
+	assert.Equal(t, "mistral-large-latest", models[0].Name)
+	assert.Equal(t, 131072, models[0].ContextLength)
+	assert.True(t, models[0].CanChat)
+	assert.True(t, models[0].CanStream)
+	assert.True(t, models[1].CanEmbed)
+	assert.False(t, models[1].CanChat)
+	assert.False(t, models[2].CanChat)
+
+	provider := catalog.ProviderFor(models[0])
+	assert.Equal(t, "mistral", provider.GetType())
+	assert.Equal(t, []string{srv.URL}, provider.GetBackendIDs())
+}
+
+func TestHostedCatalogProvider_Groq(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, `{"object":"list","data":[
+			{"id":"llama-3.3-70b-versatile","active":true,"context_window":131072},
+			{"id":"whisper-large-v3","active":true,"context_window":448},
+			{"id":"gemma-7b-it","active":false,"context_window":8192}
+		]}`)
+	}))
+	defer srv.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "groq", BaseURL: srv.URL, APIKey: "test-key"})
+	require.NoError(t, err)
+	conditional, ok := catalog.(modelrepo.ConditionalCatalogProvider)
+	require.True(t, ok)
+
+	listing, err := conditional.ListModelsConditional(context.Background(), modelrepo.CatalogValidators{})
+	require.NoError(t, err)
+	require.Len(t, listing.Models, 2, "inactive models are skipped")
+	assert.True(t, listing.Models[0].CanChat)
+	assert.Equal(t, 131072, listing.Models[0].ContextLength)
+	assert.False(t, listing.Models[1].CanChat, "speech models cannot chat")
+
+	listing, err = conditional.ListModelsConditional(context.Background(), listing.Validators)
+	require.NoError(t, err)
+	assert.True(t, listing.NotModified)
+}
+
+func TestHostedProvider_MistralRequestDialect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chat/completions", r.URL.Path)
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, float64(64), req["max_tokens"])
+		assert.Equal(t, float64(7), req["random_seed"])
+		assert.NotContains(t, req, "max_completion_tokens")
+		assert.NotContains(t, req, "seed")
+		assert.NotContains(t, req, "reasoning_effort")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","model":"mistral-small-latest","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer srv.Close()
+
+	var capability modelrepo.CapabilityConfig
+	capability.CanChat = true
+	provider := NewHostedProvider("mistral", "test-key", "mistral-small-latest", srv.URL, capability, srv.Client(), libtracker.NoopTracker{})
+	chat, err := provider.GetChatConnection(context.Background(), "backend")
+	require.NoError(t, err)
+	result, err := chat.Chat(context.Background(), []modelrepo.Message{{Role: "user", Content: "hello"}},
+		modelrepo.WithMaxTokens(64), modelrepo.WithSeed(7), modelrepo.WithThink("high"))
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Message.Content)
+}
+
+func TestAdaptHostedRequest_GroqReasoningEffort(t *testing.T) {
+	think := func(level string) *string { return &level }
+	cases := []struct {
+		model string
+		think *string
+		want  string
+	}{
+		{"openai/gpt-oss-120b", think("medium"), "medium"},
+		{"openai/gpt-oss-120b", think("none"), ""},
+		{"qwen/qwen3-32b", think("high"), "default"},
+		{"qwen/qwen3-32b", think("false"), "none"},
+		{"qwen/qwen3-32b", nil, ""},
+		{"llama-3.3-70b-versatile", think("high"), ""},
+	}
+	for _, tc := range cases {
+		var args []modelrepo.ChatArgument
+		if tc.think != nil {
+			args = append(args, modelrepo.WithThink(*tc.think))
+		}
+		client := openAIClient{modelName: tc.model, providerType: "groq"}
+		req, _ := client.buildRequest([]modelrepo.Message{{Role: "user", Content: "hi"}}, args)
+		assert.Equal(t, tc.want, req.ReasoningEffort, tc.model)
+	}
+}
@@ -11,7 +11,7 @@ type OpenAIPromptClient struct {
 	openAIClient
 }
 
-func (c *OpenAIPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error) {
+func (c *OpenAIPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
 	// Start tracking the operation
 	reportErr, reportChange, end := c.tracker.Start(ctx, "prompt", "openai", "model", c.modelName)
 	defer end()
@@ -25,12 +25,13 @@ func (c *OpenAIPromptClient) Prompt(ctx context.Context, systemInstruction strin
 	// Use the chat client to handle the prompt. Keep the provider-specific
 	// parameter rules internal: legacy GPT-5 chat completions reject sampling
 	// params, while newer GPT-5.x snapshots may allow them in `reasoning=none`.
-	var args []modelrepo.ChatArgument
+	var chatArgs []modelrepo.ChatArgument
 	if !openAIShouldOmitSamplingParams(c.modelName, "") {
-		args = append(args, modelrepo.WithTemperature(float64(temperature)))
+		chatArgs = append(chatArgs, modelrepo.WithTemperature(float64(temperature)))
 	}
+	chatArgs = append(chatArgs, args...)
 
-	response, err := c.Chat(ctx, messages, args...)
+	response, err := c.Chat(ctx, messages, chatArgs...)
 	if err != nil {
 		reportErr(err)
 		return "", fmt.Errorf("OpenAI prompt execution failed: %w", err)
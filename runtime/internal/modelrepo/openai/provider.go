@@ -21,6 +21,8 @@ type OpenAIProvider struct {
 	canEmbed      bool
 	canStream     bool
 	tracker       libtracker.ActivityTracker
+	providerType  string
+	apiVersion    string
 }
 
 func NewOpenAIProvider(apiKey, modelName string, backendURLs []string, capability modelrepo.CapabilityConfig, httpClient *http.Client, tracker libtracker.ActivityTracker) modelrepo.Provider {
@@ -50,6 +52,7 @@ func NewOpenAIProvider(apiKey, modelName string, backendURLs []string, capabilit
 		canEmbed:      capability.CanEmbed,
 		canStream:     capability.CanStream,
 		tracker:       tracker,
+		providerType:  "openai",
 	}
 }
 
@@ -66,7 +69,7 @@ func (p *OpenAIProvider) GetID() string {
 }
 
 func (p *OpenAIProvider) GetType() string {
-	return "openai"
+	return p.providerType
 }
 
 func (p *OpenAIProvider) GetContextLength() int {
@@ -99,12 +102,14 @@ func (p *OpenAIProvider) GetChatConnection(ctx context.Context, backendID string
 	}
 	return &OpenAIChatClient{
 		openAIClient: openAIClient{
-			baseURL:    p.baseURL,
-			apiKey:     p.apiKey,
-			httpClient: p.httpClient,
-			modelName:  p.modelName,
-			maxTokens:  p.contextLength,
-			tracker:    p.tracker,
+			baseURL:      p.baseURL,
+			apiKey:       p.apiKey,
+			httpClient:   p.httpClient,
+			modelName:    p.modelName,
+			maxTokens:    p.contextLength,
+			tracker:      p.tracker,
+			apiVersion:   p.apiVersion,
+			providerType: p.providerType,
 		},
 	}, nil
 }
@@ -115,12 +120,14 @@ func (p *OpenAIProvider) GetPromptConnection(ctx context.Context, backendID stri
 	}
 	return &OpenAIPromptClient{
 		openAIClient: openAIClient{
-			baseURL:    p.baseURL,
-			apiKey:     p.apiKey,
-			httpClient: p.httpClient,
-			modelName:  p.modelName,
-			maxTokens:  p.contextLength,
-			tracker:    p.tracker,
+			baseURL:      p.baseURL,
+			apiKey:       p.apiKey,
+			httpClient:   p.httpClient,
+			modelName:    p.modelName,
+			maxTokens:    p.contextLength,
+			tracker:      p.tracker,
+			apiVersion:   p.apiVersion,
+			providerType: p.providerType,
 		},
 	}, nil
 }
@@ -131,11 +138,13 @@ func (p *OpenAIProvider) GetEmbedConnection(ctx context.Context, backendID strin
 	}
 	return &OpenAIEmbedClient{
 		openAIClient: openAIClient{
-			baseURL:    p.baseURL,
-			apiKey:     p.apiKey,
-			httpClient: p.httpClient,
-			modelName:  p.modelName,
-			tracker:    p.tracker,
+			baseURL:      p.baseURL,
+			apiKey:       p.apiKey,
+			httpClient:   p.httpClient,
+			modelName:    p.modelName,
+			tracker:      p.tracker,
+			apiVersion:   p.apiVersion,
+			providerType: p.providerType,
 		},
 	}, nil
 }
@@ -146,12 +155,14 @@ func (p *OpenAIProvider) GetStreamConnection(ctx context.Context, backendID stri
 	}
 	return &OpenAIStreamClient{
 		openAIClient: openAIClient{
-			baseURL:    p.baseURL,
-			apiKey:     p.apiKey,
-			httpClient: p.httpClient,
-			modelName:  p.modelName,
-			maxTokens:  p.contextLength,
-			tracker:    p.tracker,
+			baseURL:      p.baseURL,
+			apiKey:       p.apiKey,
+			httpClient:   p.httpClient,
+			modelName:    p.modelName,
+			maxTokens:    p.contextLength,
+			tracker:      p.tracker,
+			apiVersion:   p.apiVersion,
+			providerType: p.providerType,
 		},
 	}, nil
 }
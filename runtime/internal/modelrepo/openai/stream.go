@@ -46,11 +46,11 @@ func (c *OpenAIStreamClient) Stream(ctx context.Context, messages []modelrepo.Me
 	reportErr, reportChange, end := c.tracker.Start(ctx, "stream", "openai", "model", c.modelName)
 	// Note: We don't defer end() here because the stream is asynchronous
 
-	// buildOpenAIRequest now returns (request, nameMap); we only need the request here.
-	request, _ := buildOpenAIRequest(c.modelName, messages, args)
+	// buildRequest returns (request, nameMap); we only need the request here.
+	request, _ := c.buildRequest(messages, args)
 	request.Stream = true
 
-	url := c.baseURL + "/chat/completions"
+	url := c.endpointURL("/chat/completions")
 	reqBody, err := json.Marshal(request)
 	if err != nil {
 		end()
@@ -63,7 +63,7 @@ func (c *OpenAIStreamClient) Stream(ctx context.Context, messages []modelrepo.Me
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.setAuth(req)
 
 	streamCh := make(chan *modelrepo.StreamParcel)
 
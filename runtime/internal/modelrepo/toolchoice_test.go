@@ -0,0 +1,38 @@
+package modelrepo_test
+
+import (
+	"testing"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_EmulateToolChoice(t *testing.T) {
+	tools := func() []modelrepo.Tool {
+		return []modelrepo.Tool{
+			{Type: "function", Function: &modelrepo.FunctionTool{Name: "fs.read"}},
+			{Type: "function", Function: &modelrepo.FunctionTool{Name: "fs.write"}},
+		}
+	}
+	msgs := []modelrepo.Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "hi"}}
+
+	cfg := &modelrepo.ChatConfig{Tools: tools(), ToolChoice: &modelrepo.ToolChoice{Name: "fs.read"}}
+	out := modelrepo.EmulateToolChoice(cfg, msgs)
+	require.Len(t, cfg.Tools, 1)
+	assert.Equal(t, "fs.read", cfg.Tools[0].Function.Name)
+	require.Len(t, out, 2)
+	assert.Equal(t, "Be brief.\n\nYou must respond with a call of the tool \"fs.read\".", out[0].Content)
+	assert.Equal(t, "Be brief.", msgs[0].Content, "the caller's messages are not modified")
+
+	cfg = &modelrepo.ChatConfig{Tools: tools(), ToolChoice: &modelrepo.ToolChoice{Mode: modelrepo.ToolChoiceRequired}}
+	out = modelrepo.EmulateToolChoice(cfg, msgs[1:])
+	require.Len(t, out, 2)
+	assert.Equal(t, "system", out[0].Role)
+	assert.Len(t, cfg.Tools, 2)
+
+	cfg = &modelrepo.ChatConfig{Tools: tools(), ToolChoice: &modelrepo.ToolChoice{Mode: modelrepo.ToolChoiceNone}}
+	out = modelrepo.EmulateToolChoice(cfg, msgs)
+	assert.Empty(t, cfg.Tools)
+	assert.Equal(t, msgs, out)
+}
@@ -9,8 +9,8 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/contenox/contenox/runtime/internal/modelrepo"
 	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
 )
 
 type vertexClient struct {
@@ -171,14 +171,38 @@ func buildVertexRequest(messages []modelrepo.Message, args []modelrepo.ChatArgum
 		GenerationConfig:  &vertexGenerationConfig{},
 		Tools:             tools,
 	}
+	if len(tools) > 0 {
+		req.ToolConfig = vertexToolChoice(cfg.ToolChoice)
+	}
 	req.GenerationConfig.Temperature = cfg.Temperature
 	req.GenerationConfig.TopP = cfg.TopP
 	req.GenerationConfig.MaxOutputTokens = cfg.MaxTokens
 	req.GenerationConfig.Seed = cfg.Seed
+	req.GenerationConfig.TopK = cfg.TopK
+	req.GenerationConfig.FrequencyPenalty = cfg.FrequencyPenalty
+	req.GenerationConfig.PresencePenalty = cfg.PresencePenalty
+	req.GenerationConfig.StopSequences = cfg.Stop
 
 	return req, nil
 }
 
+// vertexToolChoice maps a tool choice to the function calling mode, or
+// returns nil for the default. Mirrors geminiToolChoice in the gemini package.
+func vertexToolChoice(choice *modelrepo.ToolChoice) *vertexToolConfig {
+	switch {
+	case choice == nil:
+		return nil
+	case choice.Name != "":
+		return &vertexToolConfig{FunctionCallingConfig: vertexFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{choice.Name}}}
+	case choice.Mode == modelrepo.ToolChoiceNone:
+		return &vertexToolConfig{FunctionCallingConfig: vertexFunctionCallingConfig{Mode: "NONE"}}
+	case choice.Mode == modelrepo.ToolChoiceRequired:
+		return &vertexToolConfig{FunctionCallingConfig: vertexFunctionCallingConfig{Mode: "ANY"}}
+	default:
+		return nil
+	}
+}
+
 // convertToVertexContents maps modelrepo messages to Vertex AI content format.
 // Mirrors convertToGeminiMessages in the gemini package.
 func convertToVertexContents(messages []modelrepo.Message) []vertexContent {
@@ -203,6 +227,13 @@ func convertToVertexContents(messages []modelrepo.Message) []vertexContent {
 		if m.Content != "" && m.Role != "tool" {
 			parts = append(parts, vertexPart{Text: m.Content})
 		}
+		for _, a := range m.Attachments {
+			if a.URL != "" {
+				parts = append(parts, vertexPart{FileData: &vertexFileData{MimeType: a.MimeType, FileURI: a.URL}})
+			} else {
+				parts = append(parts, vertexPart{InlineData: &vertexBlob{MimeType: a.MimeType, Data: a.Data}})
+			}
+		}
 
 		if len(m.ToolCalls) > 0 {
 			for _, tc := range m.ToolCalls {
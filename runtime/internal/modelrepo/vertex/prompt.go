@@ -13,7 +13,7 @@ type vertexPromptClient struct {
 }
 
 // Prompt implements modelrepo.LLMPromptExecClient.
-func (c *vertexPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error) {
+func (c *vertexPromptClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
 	reportErr, reportChange, end := c.tracker.Start(ctx, "prompt", "vertex", "model", c.modelName)
 	defer end()
 
@@ -25,7 +25,7 @@ func (c *vertexPromptClient) Prompt(ctx context.Context, systemInstruction strin
 	}
 
 	chat := &vertexChatClient{vertexClient: c.vertexClient}
-	resp, err := chat.Chat(ctx, messages, modelrepo.WithTemperature(float64(temperature)))
+	resp, err := chat.Chat(ctx, messages, append([]modelrepo.ChatArgument{modelrepo.WithTemperature(float64(temperature))}, args...)...)
 	if err != nil {
 		reportErr(err)
 		return "", fmt.Errorf("vertex prompt execution failed: %w", err)
@@ -3,23 +3,39 @@ package vertex
 // vertexRequest is the wire format for generateContent / streamGenerateContent.
 // The schema is identical to the Gemini AI Studio API.
 type vertexRequest struct {
-	SystemInstruction *vertexContent        `json:"system_instruction,omitempty"`
-	Contents          []vertexContent       `json:"contents"`
+	SystemInstruction *vertexContent          `json:"system_instruction,omitempty"`
+	Contents          []vertexContent         `json:"contents"`
 	GenerationConfig  *vertexGenerationConfig `json:"generationConfig,omitempty"`
-	Tools             []vertexToolRequest   `json:"tools,omitempty"`
+	Tools             []vertexToolRequest     `json:"tools,omitempty"`
+	ToolConfig        *vertexToolConfig       `json:"toolConfig,omitempty"`
 }
 
 type vertexGenerationConfig struct {
 	Temperature     *float64 `json:"temperature,omitempty"`
 	TopP            *float64 `json:"topP,omitempty"`
+	TopK            *int     `json:"topK,omitempty"`
 	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
 	Seed            *int     `json:"seed,omitempty"`
+	// Penalties are only honoured by some Gemini models.
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	PresencePenalty  *float64 `json:"presencePenalty,omitempty"`
 }
 
 type vertexToolRequest struct {
 	FunctionDeclarations []vertexFunctionDeclaration `json:"functionDeclarations,omitempty"`
 }
 
+type vertexToolConfig struct {
+	FunctionCallingConfig vertexFunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+type vertexFunctionCallingConfig struct {
+	// Mode is AUTO, ANY or NONE.
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
 type vertexFunctionDeclaration struct {
 	Name        string         `json:"name"`
 	Description string         `json:"description,omitempty"`
@@ -46,6 +62,21 @@ type vertexPart struct {
 	Thought          bool                    `json:"thought,omitempty"`
 	FunctionCall     *vertexFunctionCall     `json:"functionCall,omitempty"`
 	FunctionResponse *vertexFunctionResponse `json:"functionResponse,omitempty"`
+	InlineData       *vertexBlob             `json:"inlineData,omitempty"`
+	FileData         *vertexFileData         `json:"fileData,omitempty"`
+}
+
+// vertexBlob is inline media, e.g. an image attached to a message. Data is
+// base64-encoded by encoding/json.
+type vertexBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     []byte `json:"data"`
+}
+
+// vertexFileData references media by URI.
+type vertexFileData struct {
+	MimeType string `json:"mimeType"`
+	FileURI  string `json:"fileUri"`
 }
 
 type vertexFunctionCall struct {
@@ -58,6 +58,7 @@ func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedM
 	var payload struct {
 		Data []struct {
 			ID          string `json:"id"`
+			Parent      string `json:"parent"`
 			MaxModelLen int    `json:"max_model_len"`
 		} `json:"data"`
 	}
@@ -65,9 +66,16 @@ func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedM
 		return nil, fmt.Errorf("decode vLLM catalog response: %w", err)
 	}
 
+	// A server started with --lora-modules lists each adapter next to its
+	// base model, with the base model as parent. Adapters are served under
+	// their own ID and inherit the context window of their base model.
+	contextLengths := make(map[string]int, len(payload.Data))
+	for _, item := range payload.Data {
+		contextLengths[item.ID] = item.MaxModelLen
+	}
 	models := make([]modelrepo.ObservedModel, 0, len(payload.Data))
 	for _, item := range payload.Data {
-		models = append(models, modelrepo.ObservedModel{
+		observed := modelrepo.ObservedModel{
 			Name:          item.ID,
 			ContextLength: item.MaxModelLen,
 			CapabilityConfig: modelrepo.CapabilityConfig{
@@ -76,7 +84,15 @@ func (p *catalogProvider) ListModels(ctx context.Context) ([]modelrepo.ObservedM
 				CanPrompt:     true,
 				CanStream:     true,
 			},
-		})
+		}
+		if item.Parent != "" && item.Parent != item.ID {
+			observed.Meta = map[string]string{modelrepo.MetaParentModel: item.Parent}
+			if observed.ContextLength == 0 {
+				observed.ContextLength = contextLengths[item.Parent]
+				observed.CapabilityConfig.ContextLength = observed.ContextLength
+			}
+		}
+		models = append(models, observed)
 	}
 	return models, nil
 }
@@ -49,3 +49,32 @@ func TestCatalogProvider_ListModels(t *testing.T) {
 	require.Equal(t, "vllm", provider.GetType())
 	require.Equal(t, "qwen3:32b", provider.ModelName())
 }
+
+func TestCatalogProvider_ListModels_LoRAAdapters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{
+				{"id": "meta-llama/Llama-3.1-8B-Instruct", "root": "meta-llama/Llama-3.1-8B-Instruct", "parent": nil, "max_model_len": 131072},
+				{"id": "sql-lora", "root": "/adapters/sql", "parent": "meta-llama/Llama-3.1-8B-Instruct"},
+				{"id": "Qwen/Qwen3-4B", "max_model_len": 40960},
+			},
+		})
+	}))
+	defer server.Close()
+
+	catalog, err := modelrepo.NewCatalogProvider(modelrepo.BackendSpec{Type: "vllm", BaseURL: server.URL})
+	require.NoError(t, err)
+	models, err := catalog.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+
+	require.Empty(t, models[0].Meta)
+	adapter := models[1]
+	require.Equal(t, "sql-lora", adapter.Name)
+	require.Equal(t, "meta-llama/Llama-3.1-8B-Instruct", adapter.Meta[modelrepo.MetaParentModel])
+	require.Equal(t, 131072, adapter.ContextLength, "adapters inherit the context window of their base model")
+	require.Equal(t, 131072, adapter.CapabilityConfig.ContextLength)
+	require.Equal(t, 40960, models[2].ContextLength)
+
+	require.Equal(t, "sql-lora", catalog.ProviderFor(adapter).ModelName())
+}
@@ -32,21 +32,66 @@ type vLLMClient struct {
 }
 
 type chatRequest struct {
-	Model       string              `json:"model"`
-	Messages    []modelrepo.Message `json:"messages"`
-	Temperature *float64            `json:"temperature,omitempty"`
-	MaxTokens   *int                `json:"max_tokens,omitempty"`
-	TopP        *float64            `json:"top_p,omitempty"`
-	Seed        *int                `json:"seed,omitempty"`
-	Stream      bool                `json:"stream,omitempty"`
-	Tools       []modelrepo.Tool    `json:"tools,omitempty"`
+	Model       string               `json:"model"`
+	Messages    []chatRequestMessage `json:"messages"`
+	Temperature *float64             `json:"temperature,omitempty"`
+	MaxTokens   *int                 `json:"max_tokens,omitempty"`
+	TopP        *float64             `json:"top_p,omitempty"`
+	Seed        *int                 `json:"seed,omitempty"`
+	// TopK is a vLLM extension of the OpenAI chat completions API.
+	TopK             *int             `json:"top_k,omitempty"`
+	FrequencyPenalty *float64         `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64         `json:"presence_penalty,omitempty"`
+	Stop             []string         `json:"stop,omitempty"`
+	Stream           bool             `json:"stream,omitempty"`
+	Tools            []modelrepo.Tool `json:"tools,omitempty"`
+	// ToolChoice is "none", "auto", "required" or a named function.
+	ToolChoice any `json:"tool_choice,omitempty"`
 	// ExtraBody passes provider-specific parameters (e.g. enable_thinking for Qwen3/Granite).
-	// We intentionally defer vLLM-only request fields such as tool_choice,
+	// We intentionally defer vLLM-only request fields such as
 	// parallel_tool_calls, response_format, structured_outputs, and /v1/responses
 	// until modelrepo grows matching shared request fields.
 	ExtraBody map[string]any `json:"extra_body,omitempty"`
 }
 
+// chatRequestMessage is a request message. Content is a string, or a list of
+// text and image_url parts for messages with attachments.
+type chatRequestMessage struct {
+	Role       string               `json:"role"`
+	Content    any                  `json:"content"`
+	ToolCalls  []modelrepo.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string               `json:"tool_call_id,omitempty"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+func buildRequestMessages(messages []modelrepo.Message) []chatRequestMessage {
+	out := make([]chatRequestMessage, len(messages))
+	for i, msg := range messages {
+		out[i] = chatRequestMessage{Role: msg.Role, Content: msg.Content, ToolCalls: msg.ToolCalls, ToolCallID: msg.ToolCallID}
+		if len(msg.Attachments) == 0 {
+			continue
+		}
+		parts := make([]contentPart, 0, len(msg.Attachments)+1)
+		if msg.Content != "" {
+			parts = append(parts, contentPart{Type: "text", Text: msg.Content})
+		}
+		for _, a := range msg.Attachments {
+			parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: a.URLOrData()}})
+		}
+		out[i].Content = parts
+	}
+	return out
+}
+
 type chatResponse struct {
 	ID      string       `json:"id"`
 	Object  string       `json:"object"`
@@ -179,14 +224,21 @@ func buildChatRequest(modelName string, messages []modelrepo.Message, args []mod
 
 func buildChatRequestFromConfig(modelName string, messages []modelrepo.Message, config *modelrepo.ChatConfig) chatRequest {
 	req := chatRequest{
-		Model:       modelName,
-		Messages:    messages,
-		Temperature: config.Temperature,
-		MaxTokens:   config.MaxTokens,
-		TopP:        config.TopP,
-		Seed:        config.Seed,
-		Stream:      false,
-		Tools:       config.Tools,
+		Model:            modelName,
+		Messages:         buildRequestMessages(messages),
+		Temperature:      config.Temperature,
+		MaxTokens:        config.MaxTokens,
+		TopP:             config.TopP,
+		Seed:             config.Seed,
+		TopK:             config.TopK,
+		FrequencyPenalty: config.FrequencyPenalty,
+		PresencePenalty:  config.PresencePenalty,
+		Stop:             config.Stop,
+		Stream:           false,
+		Tools:            config.Tools,
+	}
+	if len(config.Tools) > 0 {
+		req.ToolChoice = vllmToolChoice(config.ToolChoice)
 	}
 
 	// Wire enable_thinking for Qwen3, Granite, and DeepSeek-V3.1 served via vLLM.
@@ -201,6 +253,21 @@ func buildChatRequestFromConfig(modelName string, messages []modelrepo.Message,
 	return req
 }
 
+// vllmToolChoice returns the OpenAI-style tool_choice of the request, or nil
+// for the default.
+func vllmToolChoice(choice *modelrepo.ToolChoice) any {
+	switch {
+	case choice == nil:
+		return nil
+	case choice.Name != "":
+		return map[string]any{"type": "function", "function": map[string]string{"name": choice.Name}}
+	case choice.Mode != "":
+		return choice.Mode
+	default:
+		return nil
+	}
+}
+
 func vllmThinkingEnabled(think *string) (bool, bool) {
 	if think == nil {
 		return false, false
@@ -33,7 +33,7 @@ func NewVLLMPromptClient(ctx context.Context, baseURL, modelName string, context
 }
 
 // Prompt implements LLMPromptExecClient interface
-func (c *vLLMClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string) (string, error) {
+func (c *vLLMClient) Prompt(ctx context.Context, systemInstruction string, temperature float32, prompt string, args ...modelrepo.ChatArgument) (string, error) {
 	// Start tracking the operation
 	reportErr, reportChange, end := c.tracker.Start(ctx, "prompt", "vllm", "model", c.modelName)
 	defer end()
@@ -43,10 +43,10 @@ func (c *vLLMClient) Prompt(ctx context.Context, systemInstruction string, tempe
 		{Role: "user", Content: prompt},
 	}
 
-	request := buildChatRequest(c.modelName, messages, []modelrepo.ChatArgument{
+	request := buildChatRequest(c.modelName, messages, append([]modelrepo.ChatArgument{
 		modelrepo.WithTemperature(float64(temperature)),
 		modelrepo.WithMaxTokens(c.maxTokens),
-	})
+	}, args...))
 
 	// Send request to the chat completions endpoint
 	var response chatResponse
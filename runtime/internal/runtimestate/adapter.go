@@ -25,6 +25,7 @@ func LocalProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracke
 				Type:    backendType,
 				BaseURL: state.Backend.BaseURL,
 				APIKey:  state.GetAPIKey(),
+				Headers: state.Backend.Headers,
 			},
 			modelrepo.WithCatalogHTTPClient(http.DefaultClient),
 			modelrepo.WithCatalogTracker(tracker),
@@ -39,7 +40,7 @@ func LocalProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracke
 		for _, model := range state.PulledModels {
 			providersByType[backendType] = append(
 				providersByType[backendType],
-				catalog.ProviderFor(observedModelFromPullStatus(model)),
+				backendProvider{Provider: catalog.ProviderFor(observedModelFromPullStatus(model)), backendID: state.ID},
 			)
 		}
 	}
@@ -73,3 +74,38 @@ func LocalProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracke
 
 // ProviderFromRuntimeState retrieves available model providers
 type ProviderFromRuntimeState func(ctx context.Context, backendTypes ...string) ([]modelrepo.Provider, error)
+
+// backendProvider reports the ID of the backend it was built for as its only
+// backend ID, so calls are attributed to that backend even when several
+// backends share a base URL. Connections are opened on the base URL of the
+// wrapped provider.
+type backendProvider struct {
+	modelrepo.Provider
+	backendID string
+}
+
+func (p backendProvider) GetBackendIDs() []string { return []string{p.backendID} }
+
+func (p backendProvider) GetChatConnection(ctx context.Context, backendID string) (modelrepo.LLMChatClient, error) {
+	return p.Provider.GetChatConnection(ctx, p.target(backendID))
+}
+
+func (p backendProvider) GetPromptConnection(ctx context.Context, backendID string) (modelrepo.LLMPromptExecClient, error) {
+	return p.Provider.GetPromptConnection(ctx, p.target(backendID))
+}
+
+func (p backendProvider) GetEmbedConnection(ctx context.Context, backendID string) (modelrepo.LLMEmbedClient, error) {
+	return p.Provider.GetEmbedConnection(ctx, p.target(backendID))
+}
+
+func (p backendProvider) GetStreamConnection(ctx context.Context, backendID string) (modelrepo.LLMStreamClient, error) {
+	return p.Provider.GetStreamConnection(ctx, p.target(backendID))
+}
+
+// target maps the backend ID to the backend ID of the wrapped provider.
+func (p backendProvider) target(backendID string) string {
+	if ids := p.Provider.GetBackendIDs(); backendID == p.backendID && len(ids) > 0 {
+		return ids[0]
+	}
+	return backendID
+}
@@ -242,7 +242,7 @@ func TestUnit_ModelProviderAdapter_CreatesOpenAIProviderViaCatalog(t *testing.T)
 	provider := providers[0]
 	require.Equal(t, "openai", provider.GetType())
 	require.Equal(t, "gpt-5", provider.ModelName())
-	require.Equal(t, []string{backendURL}, provider.GetBackendIDs())
+	require.Equal(t, []string{backendID}, provider.GetBackendIDs(), "calls are attributed to the backend, not its URL")
 	require.True(t, provider.CanChat())
 	require.True(t, provider.CanPrompt())
 	require.True(t, provider.CanStream())
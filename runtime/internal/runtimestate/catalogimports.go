@@ -1,6 +1,8 @@
 package runtimestate
 
 import (
+	_ "github.com/contenox/contenox/runtime/internal/modelrepo/anthropic"
+	_ "github.com/contenox/contenox/runtime/internal/modelrepo/bedrock"
 	_ "github.com/contenox/contenox/runtime/internal/modelrepo/gemini"
 	_ "github.com/contenox/contenox/runtime/internal/modelrepo/local"
 	_ "github.com/contenox/contenox/runtime/internal/modelrepo/ollama"
@@ -19,6 +19,9 @@ func observedModelFromPullStatus(model statetype.ModelPullStatus) modelrepo.Obse
 	if display := strings.TrimSpace(model.Name); display != "" && display != name {
 		meta[observedDisplayNameMetaKey] = display
 	}
+	if parent := model.Details.ParentModel; parent != "" {
+		meta[modelrepo.MetaParentModel] = parent
+	}
 	if len(meta) == 0 {
 		meta = nil
 	}
@@ -54,6 +57,7 @@ func pullStatusFromObservedModel(model modelrepo.ObservedModel) statetype.ModelP
 		ModifiedAt:    model.ModifiedAt,
 		Size:          model.Size,
 		Digest:        model.Digest,
+		Details:       statetype.ModelDetails{ParentModel: model.Meta[modelrepo.MetaParentModel]},
 		ContextLength: model.ContextLength,
 		CanChat:       model.CanChat,
 		CanEmbed:      model.CanEmbed,
@@ -3,10 +3,15 @@ package runtimestate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/libkvstore"
 	"github.com/contenox/contenox/runtime/internal/modelrepo"
 	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/contenox/contenox/runtime/statetype"
@@ -20,6 +25,16 @@ func providerConfigKey(backendType string) (string, bool) {
 		return OpenaiKey, true
 	case "gemini":
 		return GeminiKey, true
+	case "anthropic":
+		return AnthropicKey, true
+	case "azure-openai":
+		return AzureOpenAIKey, true
+	case "bedrock":
+		return BedrockKey, true
+	case "mistral":
+		return MistralKey, true
+	case "groq":
+		return GroqKey, true
 	case "vllm":
 		// vLLM reuses the OpenAI-compatible bearer token configuration.
 		return OpenaiKey, true
@@ -50,44 +65,125 @@ func (s *State) loadProviderAPIKey(ctx context.Context, backendType string) (str
 	return cfg.APIKey, nil
 }
 
+// loadBackendAPIKey returns the credential of backend: its own key when set,
+// otherwise the provider-wide key of its type.
+func (s *State) loadBackendAPIKey(ctx context.Context, backend *runtimetypes.Backend) (string, error) {
+	if backend.APIKey != "" {
+		return backend.APIKey, nil
+	}
+	return s.loadProviderAPIKey(ctx, backend.Type)
+}
+
 func (s *State) newCatalogProvider(backend *runtimetypes.Backend, apiKey string) (modelrepo.CatalogProvider, error) {
 	return modelrepo.NewCatalogProvider(
 		modelrepo.BackendSpec{
 			Type:    backend.Type,
 			BaseURL: backend.BaseURL,
 			APIKey:  apiKey,
+			Headers: backend.Headers,
 		},
 		modelrepo.WithCatalogHTTPClient(http.DefaultClient),
 	)
 }
 
-func (s *State) loadObservedModelCache(ctx context.Context, backendID, apiKey string) ([]modelrepo.ObservedModel, bool) {
+// observeCatalogModels returns the models of a catalog-backed backend together
+// with their source (see statetype.ModelListSource*). Fresh cache entries are
+// served without a provider call; stale ones are revalidated with a conditional
+// request when the catalog supports it, so unchanged lists cost a 304 instead
+// of a full download.
+func (s *State) observeCatalogModels(ctx context.Context, backend *runtimetypes.Backend, apiKey string) ([]modelrepo.ObservedModel, string, error) {
+	now := time.Now().UTC()
+	entry, cached := s.loadProviderCacheEntry(ctx, backend.ID, apiKey)
+	if cached && (entry.FreshUntil.IsZero() || now.Before(entry.FreshUntil)) {
+		return entry.Models, statetype.ModelListSourceCache, nil
+	}
+
+	catalog, err := s.newCatalogProvider(backend, apiKey)
+	if err != nil {
+		return nil, "", err
+	}
+	conditional, ok := catalog.(modelrepo.ConditionalCatalogProvider)
+	if !ok {
+		models, err := catalog.ListModels(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		s.storeProviderCacheEntry(ctx, backend.ID, providerCacheEntry{
+			Models:     models,
+			APIKey:     apiKey,
+			FreshUntil: providerFreshUntil(now, s.syncInterval(backend.ID), nil),
+		})
+		return models, statetype.ModelListSourceNetwork, nil
+	}
+
+	var validators modelrepo.CatalogValidators
+	if cached {
+		validators = entry.Validators
+	}
+	listing, err := conditional.ListModelsConditional(ctx, validators)
+	if err != nil {
+		return nil, "", err
+	}
+	if listing.NotModified {
+		if !cached {
+			return nil, "", fmt.Errorf("%s catalog answered 304 Not Modified without a cached model list", backend.Type)
+		}
+		if !listing.Validators.IsZero() {
+			entry.Validators = listing.Validators
+		}
+		entry.FreshUntil = providerFreshUntil(now, s.syncInterval(backend.ID), listing.MaxAge)
+		s.storeProviderCacheEntry(ctx, backend.ID, entry)
+		return entry.Models, statetype.ModelListSourceRevalidated, nil
+	}
+	s.storeProviderCacheEntry(ctx, backend.ID, providerCacheEntry{
+		Models:     listing.Models,
+		APIKey:     apiKey,
+		Validators: listing.Validators,
+		FreshUntil: providerFreshUntil(now, s.syncInterval(backend.ID), listing.MaxAge),
+	})
+	return listing.Models, statetype.ModelListSourceNetwork, nil
+}
+
+// providerFreshUntil returns when a listing fetched at now goes stale: after
+// the backend's sync interval (ProviderCacheDuration if it has none), or
+// earlier if the provider's Cache-Control says so.
+func providerFreshUntil(now time.Time, interval time.Duration, maxAge *time.Duration) time.Time {
+	ttl := ProviderCacheDuration
+	if interval > 0 {
+		ttl = interval
+	}
+	if maxAge != nil && *maxAge < ttl {
+		ttl = *maxAge
+	}
+	return now.Add(ttl)
+}
+
+func (s *State) loadProviderCacheEntry(ctx context.Context, backendID, apiKey string) (providerCacheEntry, bool) {
 	if s.kvStore != nil {
 		if exec, err := s.kvStore.Executor(ctx); err == nil {
 			if raw, err := exec.Get(ctx, "prov:"+backendID); err == nil {
 				var entry providerCacheEntry
 				if json.Unmarshal(raw, &entry) == nil && entry.APIKey == apiKey && len(entry.Models) > 0 {
-					return entry.Models, true
+					return entry, true
 				}
 			}
 		}
-		return nil, false
+		return providerCacheEntry{}, false
 	}
 
 	if cached, ok := s.providerCache.Load(backendID); ok {
 		if entry, ok := cached.(providerCacheEntry); ok && entry.APIKey == apiKey && len(entry.Models) > 0 {
-			return entry.Models, true
+			return entry, true
 		}
 	}
-	return nil, false
+	return providerCacheEntry{}, false
 }
 
-func (s *State) storeObservedModelCache(ctx context.Context, backendID, apiKey string, models []modelrepo.ObservedModel) {
-	entry := providerCacheEntry{Models: models, APIKey: apiKey}
+func (s *State) storeProviderCacheEntry(ctx context.Context, backendID string, entry providerCacheEntry) {
 	if s.kvStore != nil {
 		if exec, err := s.kvStore.Executor(ctx); err == nil {
 			if data, err := json.Marshal(entry); err == nil {
-				_ = exec.SetWithTTL(ctx, "prov:"+backendID, data, ProviderCacheDuration)
+				_ = exec.SetWithTTL(ctx, "prov:"+backendID, data, providerCacheRetention)
 			}
 		}
 		return
@@ -95,6 +191,36 @@ func (s *State) storeObservedModelCache(ctx context.Context, backendID, apiKey s
 	s.providerCache.Store(backendID, entry)
 }
 
+// CatalogBackendTypes are the backend types whose models come from a provider
+// catalog and are kept in the provider cache.
+var CatalogBackendTypes = []string{"openai", "gemini", "azure-openai", "anthropic", "mistral", "groq", "bedrock"}
+
+// CachedCatalogModels returns the model names each catalog-backed backend
+// listed at its last sync, keyed by backend name, as kept in the provider
+// cache of kv. No provider is contacted; backends without a cached listing,
+// or whose API key changed since, are left out.
+func CachedCatalogModels(ctx context.Context, db libdb.DBManager, kv libkvstore.KVManager) (map[string][]string, error) {
+	backends, err := runtimetypes.New(db.WithoutTransaction()).ListAllBackends(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &State{dbInstance: db, kvStore: kv}
+	catalogs := map[string][]string{}
+	for _, backend := range backends {
+		if !slices.Contains(CatalogBackendTypes, strings.ToLower(backend.Type)) {
+			continue
+		}
+		apiKey, err := s.loadBackendAPIKey(ctx, backend)
+		if err != nil && !errors.Is(err, libdb.ErrNotFound) {
+			return nil, err
+		}
+		if entry, ok := s.loadProviderCacheEntry(ctx, backend.ID, apiKey); ok {
+			catalogs[backend.Name] = observedModelNames(entry.Models)
+		}
+	}
+	return catalogs, nil
+}
+
 func observedModelNames(models []modelrepo.ObservedModel) []string {
 	names := make([]string, 0, len(models))
 	for _, model := range models {
@@ -0,0 +1,102 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveCatalogModels_CacheAndRevalidation(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"catalog-1"`)
+		if r.Header.Get("If-None-Match") == `"catalog-1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "gpt-5"}, {"id": "gpt-5-mini"}},
+		})
+	}))
+	defer server.Close()
+
+	s := &State{}
+	backend := &runtimetypes.Backend{ID: "b1", Type: "openai", BaseURL: server.URL}
+	ctx := context.Background()
+
+	models, source, err := s.observeCatalogModels(ctx, backend, "key")
+	require.NoError(t, err)
+	require.Equal(t, statetype.ModelListSourceNetwork, source)
+	require.Len(t, models, 2)
+
+	models, source, err = s.observeCatalogModels(ctx, backend, "key")
+	require.NoError(t, err)
+	require.Equal(t, statetype.ModelListSourceCache, source)
+	require.Len(t, models, 2)
+	require.Equal(t, 1, full)
+
+	// Let the entry go stale: the next cycle revalidates instead of refetching.
+	entry, ok := s.loadProviderCacheEntry(ctx, backend.ID, "key")
+	require.True(t, ok)
+	entry.FreshUntil = time.Now().Add(-time.Minute)
+	s.storeProviderCacheEntry(ctx, backend.ID, entry)
+
+	models, source, err = s.observeCatalogModels(ctx, backend, "key")
+	require.NoError(t, err)
+	require.Equal(t, statetype.ModelListSourceRevalidated, source)
+	require.Len(t, models, 2)
+	require.Equal(t, 1, full)
+	require.Equal(t, 1, notModified)
+
+	// A rotated API key invalidates the cache entirely.
+	_, source, err = s.observeCatalogModels(ctx, backend, "other-key")
+	require.NoError(t, err)
+	require.Equal(t, statetype.ModelListSourceNetwork, source)
+	require.Equal(t, 2, full)
+}
+
+func TestProviderFreshUntil_RespectsShorterMaxAge(t *testing.T) {
+	now := time.Now()
+	require.Equal(t, now.Add(ProviderCacheDuration), providerFreshUntil(now, 0, nil))
+
+	short := 5 * time.Minute
+	require.Equal(t, now.Add(short), providerFreshUntil(now, 0, &short))
+
+	long := 48 * time.Hour
+	require.Equal(t, now.Add(ProviderCacheDuration), providerFreshUntil(now, 0, &long))
+
+	// A backend sync interval replaces ProviderCacheDuration.
+	require.Equal(t, now.Add(6*time.Hour), providerFreshUntil(now, 6*time.Hour, nil))
+	require.Equal(t, now.Add(short), providerFreshUntil(now, 6*time.Hour, &short))
+}
+
+func TestCatalogPullStatuses_DeclaredModelsAndAutoDiscovery(t *testing.T) {
+	observed := []modelrepo.ObservedModel{
+		{Name: "claude-sonnet-4-5", ContextLength: 200000, CapabilityConfig: modelrepo.CapabilityConfig{CanChat: true, CanPrompt: true}},
+		{Name: "claude-haiku-4-5", CapabilityConfig: modelrepo.CapabilityConfig{CanChat: true}},
+	}
+	declared := []*runtimetypes.Model{{Model: "claude-sonnet-4-5", ContextLength: 64000, CanStream: true}}
+
+	s := &State{}
+	pulled := s.catalogPullStatuses(observed, declared)
+	require.Len(t, pulled, 1, "undeclared models are published only with auto-discovery")
+	require.Equal(t, "claude-sonnet-4-5", pulled[0].Model)
+	require.Equal(t, 64000, pulled[0].ContextLength)
+	require.True(t, pulled[0].CanChat, "declared capabilities add to the observed ones")
+	require.True(t, pulled[0].CanStream)
+
+	s.autoDiscoverModels = true
+	pulled = s.catalogPullStatuses(observed, declared)
+	require.Len(t, pulled, 2)
+	require.Equal(t, "claude-haiku-4-5", pulled[1].Model)
+}
@@ -0,0 +1,47 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessOpenAIBackend_UsesBackendCredentialsAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-gateway" || r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "gpt-5-mini"}}})
+	}))
+	defer server.Close()
+
+	backend := &runtimetypes.Backend{
+		ID:      "b1",
+		Name:    "gateway",
+		Type:    "openai",
+		BaseURL: server.URL,
+		APIKey:  "sk-gateway",
+		Headers: map[string]string{"X-Tenant": "acme"},
+	}
+	// No provider-wide key is configured: the State has no database to read it from.
+	s := &State{autoDiscoverModels: true}
+	s.processOpenAIBackend(context.Background(), backend, nil)
+
+	raw, ok := s.state.Load(backend.ID)
+	require.True(t, ok)
+	got := raw.(*statetype.BackendRuntimeState)
+	require.Empty(t, got.Error)
+	require.Equal(t, []string{"gpt-5-mini"}, got.Models)
+	require.Equal(t, "sk-gateway", got.GetAPIKey())
+
+	providers, err := LocalProviderAdapter(context.Background(), nil, s.Get(context.Background()))(context.Background(), "openai")
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+}
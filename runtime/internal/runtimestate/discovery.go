@@ -0,0 +1,278 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+)
+
+// DiscoveryConfigKey is the KV key of the stored DiscoveryConfig.
+const DiscoveryConfigKey = "backend-discovery"
+
+// DefaultDiscoveryPorts are probed when no ports are configured: the default
+// ports of Ollama and of vLLM's OpenAI-compatible server.
+var DefaultDiscoveryPorts = []int{11434, 8000}
+
+// maxDiscoveryHosts bounds the number of addresses one discovery probes.
+const maxDiscoveryHosts = 4096
+
+// DiscoveryConfig selects where DiscoverBackends looks for Ollama and vLLM
+// instances on the local network.
+type DiscoveryConfig struct {
+	// CIDRs are the IPv4 ranges to probe, e.g. "192.168.1.0/24". Empty means
+	// the networks of the machine's own interfaces.
+	CIDRs []string `json:"cidrs,omitempty"`
+	// Ports are probed on every address. Empty means DefaultDiscoveryPorts.
+	Ports []int `json:"ports,omitempty"`
+	// Timeout bounds each probe, as a Go duration. Empty means 500ms.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// DiscoveredBackend is a backend candidate found by DiscoverBackends.
+type DiscoveredBackend struct {
+	Type    string   `json:"type"`
+	BaseURL string   `json:"baseUrl"`
+	Version string   `json:"version,omitempty"`
+	Models  []string `json:"models"`
+}
+
+// SetDiscoveryConfig stores the discovery settings used when
+// DiscoverBackends is called without explicit ranges.
+func SetDiscoveryConfig(ctx context.Context, store runtimetypes.Store, cfg DiscoveryConfig) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return store.SetKV(ctx, DiscoveryConfigKey, data)
+}
+
+// GetDiscoveryConfig returns the stored discovery settings, or the zero
+// config when none are stored.
+func GetDiscoveryConfig(ctx context.Context, store runtimetypes.Store) (DiscoveryConfig, error) {
+	var cfg DiscoveryConfig
+	if err := store.GetKV(ctx, DiscoveryConfigKey, &cfg); err != nil {
+		if errors.Is(err, libdb.ErrNotFound) {
+			return DiscoveryConfig{}, nil
+		}
+		return DiscoveryConfig{}, err
+	}
+	return cfg, nil
+}
+
+// DiscoverBackends probes every address of the configured ranges on the
+// configured ports and returns the Ollama and vLLM instances that answer,
+// ordered by address. An Ollama answers GET /api/version; anything else
+// serving an OpenAI-compatible GET /v1/models is proposed as vllm.
+// Unreachable addresses are skipped silently.
+func DiscoverBackends(ctx context.Context, cfg DiscoveryConfig, client *http.Client) ([]DiscoveredBackend, error) {
+	prefixes, err := discoveryPrefixes(cfg.CIDRs)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := discoveryHosts(prefixes)
+	if err != nil {
+		return nil, err
+	}
+	ports := cfg.Ports
+	if len(ports) == 0 {
+		ports = DefaultDiscoveryPorts
+	}
+	timeout := 500 * time.Millisecond
+	if cfg.Timeout != "" {
+		if timeout, err = time.ParseDuration(cfg.Timeout); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid discovery timeout %q", cfg.Timeout)
+		}
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	type target struct {
+		order   int
+		baseURL string
+	}
+	targets := make(chan target)
+	var (
+		mu    sync.Mutex
+		found = map[int]DiscoveredBackend{}
+		wg    sync.WaitGroup
+	)
+	for range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range targets {
+				if candidate, ok := probeBackend(ctx, client, t.baseURL, timeout); ok {
+					mu.Lock()
+					found[t.order] = candidate
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	order := 0
+feed:
+	for _, host := range hosts {
+		for _, port := range ports {
+			select {
+			case targets <- target{order: order, baseURL: "http://" + net.JoinHostPort(host.String(), strconv.Itoa(port))}:
+				order++
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(targets)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]int, 0, len(found))
+	for k := range found {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	candidates := make([]DiscoveredBackend, 0, len(keys))
+	for _, k := range keys {
+		candidates = append(candidates, found[k])
+	}
+	return candidates, nil
+}
+
+// discoveryPrefixes parses cidrs, or returns the IPv4 networks of the
+// machine's non-loopback interfaces when cidrs is empty.
+func discoveryPrefixes(cidrs []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q: %w", cidr, err)
+		}
+		if !prefix.Addr().Is4() {
+			return nil, fmt.Errorf("CIDR range %q is not IPv4", cidr)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	if len(cidrs) > 0 {
+		return prefixes, nil
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("list network interfaces: %w", err)
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip, ok := netip.AddrFromSlice(ipNet.IP)
+		if !ok {
+			continue
+		}
+		ip = ip.Unmap()
+		if !ip.Is4() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+			continue
+		}
+		bits, _ := ipNet.Mask.Size()
+		// Probe at most the /24 around the address on wide networks.
+		prefix := netip.PrefixFrom(ip, max(bits, 24)).Masked()
+		if !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return nil, errors.New("no IPv4 network found on this machine; pass a CIDR range")
+	}
+	return prefixes, nil
+}
+
+// discoveryHosts lists the host addresses of prefixes, leaving out the
+// network and broadcast addresses of ranges wider than /31.
+func discoveryHosts(prefixes []netip.Prefix) ([]netip.Addr, error) {
+	var hosts []netip.Addr
+	total := 0
+	for _, prefix := range prefixes {
+		size := 1 << (32 - prefix.Bits())
+		if total += size; total > maxDiscoveryHosts {
+			return nil, fmt.Errorf("CIDR ranges cover more than %d addresses; narrow them", maxDiscoveryHosts)
+		}
+		addr := prefix.Addr()
+		for i := 0; i < size; i++ {
+			if size <= 2 || (i != 0 && i != size-1) {
+				hosts = append(hosts, addr)
+			}
+			addr = addr.Next()
+		}
+	}
+	return hosts, nil
+}
+
+// probeBackend reports whether baseURL serves Ollama or an OpenAI-compatible
+// model listing.
+func probeBackend(ctx context.Context, client *http.Client, baseURL string, timeout time.Duration) (DiscoveredBackend, bool) {
+	var version struct {
+		Version string `json:"version"`
+	}
+	if probeJSON(ctx, client, baseURL+"/api/version", timeout, &version) && version.Version != "" {
+		candidate := DiscoveredBackend{Type: "ollama", BaseURL: baseURL, Version: version.Version, Models: []string{}}
+		var tags struct {
+			Models []struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		}
+		if probeJSON(ctx, client, baseURL+"/api/tags", timeout, &tags) {
+			for _, m := range tags.Models {
+				candidate.Models = append(candidate.Models, m.Name)
+			}
+		}
+		return candidate, true
+	}
+
+	var models struct {
+		Data *[]struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if probeJSON(ctx, client, baseURL+"/v1/models", timeout, &models) && models.Data != nil {
+		candidate := DiscoveredBackend{Type: "vllm", BaseURL: baseURL, Models: []string{}}
+		for _, m := range *models.Data {
+			candidate.Models = append(candidate.Models, m.ID)
+		}
+		return candidate, true
+	}
+	return DiscoveredBackend{}, false
+}
+
+// probeJSON GETs url and decodes a 200 response into out.
+func probeJSON(ctx context.Context, client *http.Client, url string, timeout time.Duration, out any) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out) == nil
+}
@@ -0,0 +1,68 @@
+package runtimestate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serverPort(t *testing.T, srv *httptest.Server) int {
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	return port
+}
+
+func TestUnit_DiscoverBackends(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/version":
+			fmt.Fprint(w, `{"version":"0.12.3"}`)
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"qwen2.5:7b"},{"name":"nomic-embed-text:latest"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ollama.Close()
+	vllm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"meta-llama/Llama-3.1-8B-Instruct","max_model_len":32768}]}`)
+	}))
+	defer vllm.Close()
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+
+	candidates, err := DiscoverBackends(context.Background(), DiscoveryConfig{
+		CIDRs: []string{"127.0.0.1/32"},
+		Ports: []int{serverPort(t, ollama), serverPort(t, other), serverPort(t, vllm)},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []DiscoveredBackend{
+		{Type: "ollama", BaseURL: ollama.URL, Version: "0.12.3", Models: []string{"qwen2.5:7b", "nomic-embed-text:latest"}},
+		{Type: "vllm", BaseURL: vllm.URL, Models: []string{"meta-llama/Llama-3.1-8B-Instruct"}},
+	}, candidates)
+
+	_, err = DiscoverBackends(context.Background(), DiscoveryConfig{CIDRs: []string{"10.0.0.0/8"}}, nil)
+	require.ErrorContains(t, err, "narrow them")
+	_, err = DiscoverBackends(context.Background(), DiscoveryConfig{CIDRs: []string{"fd00::/120"}}, nil)
+	require.ErrorContains(t, err, "not IPv4")
+}
+
+func TestUnit_DiscoveryHosts_SkipsNetworkAndBroadcast(t *testing.T) {
+	hosts, err := discoveryHosts([]netip.Prefix{netip.MustParsePrefix("192.168.1.0/30")})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("192.168.1.1"), netip.MustParseAddr("192.168.1.2")}, hosts)
+}
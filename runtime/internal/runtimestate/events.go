@@ -0,0 +1,133 @@
+package runtimestate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	libbus "github.com/contenox/contenox/libbus"
+	"github.com/contenox/contenox/runtime/statetype"
+)
+
+// reconcileEventTimeout bounds publishing one reconcile event, so a slow bus
+// does not hold up the reconciliation cycle.
+const reconcileEventTimeout = 100 * time.Millisecond
+
+// snapshot returns the reconcile-relevant state of a backend, or nil before
+// its first observation.
+func (s *State) snapshot(backendID string) *statetype.ReconcileSnapshot {
+	value, ok := s.state.Load(backendID)
+	if !ok {
+		return nil
+	}
+	observed, ok := value.(*statetype.BackendRuntimeState)
+	if !ok {
+		return nil
+	}
+	models := make([]string, 0, len(observed.PulledModels))
+	for _, m := range observed.PulledModels {
+		models = append(models, m.Model)
+	}
+	slices.Sort(models)
+	return &statetype.ReconcileSnapshot{Models: slices.Compact(models), Error: observed.Error}
+}
+
+// reportObservation publishes the decisions an observation of backend made,
+// comparing the state before it with the stored state.
+func (s *State) reportObservation(ctx context.Context, backendID, backendName string, before *statetype.ReconcileSnapshot) {
+	after := s.snapshot(backendID)
+	if after == nil {
+		return
+	}
+	event := statetype.ReconcileEvent{BackendID: backendID, BackendName: backendName, Before: before, After: after}
+	prevErr := ""
+	if before != nil {
+		prevErr = before.Error
+	}
+	switch {
+	case after.Error != "" && after.Error != prevErr:
+		event.Kind, event.Reason = statetype.ReconcileBackendError, after.Error
+		s.publishReconcileEvent(ctx, event)
+	case after.Error == "" && prevErr != "":
+		event.Kind, event.Reason = statetype.ReconcileBackendRecovered, "backend answered again"
+		s.publishReconcileEvent(ctx, event)
+	}
+	// A failed observation reports no models; that is not a change of the
+	// models the backend serves.
+	if before == nil || before.Error != "" || after.Error != "" {
+		return
+	}
+	added, removed := diffModels(before.Models, after.Models)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	event.Kind = statetype.ReconcileModelsChanged
+	event.Added, event.Removed = added, removed
+	var reasons []string
+	if len(added) > 0 {
+		reasons = append(reasons, "backend now lists "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		reasons = append(reasons, "backend no longer lists "+strings.Join(removed, ", "))
+	}
+	event.Reason = strings.Join(reasons, "; ")
+	s.publishReconcileEvent(ctx, event)
+}
+
+// reportModelUpdated publishes the write-back of a context length learned
+// from a backend to the declared model.
+func (s *State) reportModelUpdated(ctx context.Context, backendID, backendName, model string, contextLength int) {
+	s.publishReconcileEvent(ctx, statetype.ReconcileEvent{
+		Kind:        statetype.ReconcileModelUpdated,
+		BackendID:   backendID,
+		BackendName: backendName,
+		Model:       model,
+		Reason:      fmt.Sprintf("declared without context length; backend reports %d", contextLength),
+	})
+}
+
+// reportRemoved publishes the removal of a backend that is no longer
+// configured from the runtime state.
+func (s *State) reportRemoved(ctx context.Context, backendID, backendName string, before *statetype.ReconcileSnapshot) {
+	reason := "backend no longer configured"
+	if s.withgroups {
+		reason = "backend no longer in any affinity group"
+	}
+	s.publishReconcileEvent(ctx, statetype.ReconcileEvent{
+		Kind:        statetype.ReconcileBackendRemoved,
+		BackendID:   backendID,
+		BackendName: backendName,
+		Reason:      reason,
+		Before:      before,
+	})
+}
+
+// publishReconcileEvent publishes event on SubjectReconcileEvents. Events
+// are best effort: a failure to publish does not fail the cycle.
+func (s *State) publishReconcileEvent(ctx context.Context, event statetype.ReconcileEvent) {
+	if s.psInstance == nil {
+		return
+	}
+	event.Timestamp = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(ctx, reconcileEventTimeout)
+	defer cancel()
+	_ = libbus.PublishMessage(ctx, s.psInstance, statetype.SubjectReconcileEvents, statetype.ReconcileEventSchema, event)
+}
+
+// diffModels returns the models of after missing from before and those of
+// before missing from after. Both must be sorted.
+func diffModels(before, after []string) (added, removed []string) {
+	for _, m := range after {
+		if _, found := slices.BinarySearch(before, m); !found {
+			added = append(added, m)
+		}
+	}
+	for _, m := range before {
+		if _, found := slices.BinarySearch(after, m); !found {
+			removed = append(removed, m)
+		}
+	}
+	return added, removed
+}
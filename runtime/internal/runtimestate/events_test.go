@@ -0,0 +1,78 @@
+package runtimestate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	libbus "github.com/contenox/contenox/libbus"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileEvents(t *testing.T) {
+	ctx := context.Background()
+	bus := libbus.NewInMem()
+	ch := make(chan []byte, 16)
+	sub, err := bus.Stream(ctx, statetype.SubjectReconcileEvents, ch)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	next := func() statetype.ReconcileEvent {
+		t.Helper()
+		select {
+		case data := <-ch:
+			var ev statetype.ReconcileEvent
+			_, err := statetype.ReconcileEventSchema.Decode(data, &ev)
+			require.NoError(t, err)
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("no reconcile event")
+			return statetype.ReconcileEvent{}
+		}
+	}
+	s := &State{psInstance: bus}
+	backend := &runtimetypes.Backend{ID: "b1", Name: "gpu-1", Type: "unknown"}
+	s.processBackend(ctx, backend, nil)
+	ev := next()
+	assert.Equal(t, statetype.ReconcileBackendError, ev.Kind)
+	assert.Equal(t, "gpu-1", ev.BackendName)
+	assert.Equal(t, "Unsupported backend type: unknown", ev.Reason)
+	assert.Nil(t, ev.Before)
+
+	store := func(errMsg string, models ...string) {
+		st := &statetype.BackendRuntimeState{ID: backend.ID, Name: backend.Name, Error: errMsg}
+		for _, m := range models {
+			st.PulledModels = append(st.PulledModels, statetype.ModelPullStatus{Model: m})
+		}
+		s.state.Store(backend.ID, st)
+	}
+
+	before := s.snapshot(backend.ID)
+	store("", "llama3", "qwen")
+	s.reportObservation(ctx, backend.ID, backend.Name, before)
+	assert.Equal(t, statetype.ReconcileBackendRecovered, next().Kind)
+
+	before = s.snapshot(backend.ID)
+	store("", "mistral", "qwen")
+	s.reportObservation(ctx, backend.ID, backend.Name, before)
+	ev = next()
+	assert.Equal(t, statetype.ReconcileModelsChanged, ev.Kind)
+	assert.Equal(t, []string{"mistral"}, ev.Added)
+	assert.Equal(t, []string{"llama3"}, ev.Removed)
+	assert.Equal(t, "backend now lists mistral; backend no longer lists llama3", ev.Reason)
+	assert.Equal(t, []string{"llama3", "qwen"}, ev.Before.Models)
+	assert.Equal(t, []string{"mistral", "qwen"}, ev.After.Models)
+
+	// Unchanged observations are not reported.
+	s.reportObservation(ctx, backend.ID, backend.Name, s.snapshot(backend.ID))
+
+	require.NoError(t, s.cleanupStaleBackends(ctx, map[string]struct{}{}))
+	ev = next()
+	assert.Equal(t, statetype.ReconcileBackendRemoved, ev.Kind)
+	assert.Equal(t, "backend no longer configured", ev.Reason)
+	assert.Equal(t, []string{"mistral", "qwen"}, ev.Before.Models)
+	assert.Nil(t, ev.After)
+	assert.Empty(t, ch)
+}
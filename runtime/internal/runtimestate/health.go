@@ -0,0 +1,144 @@
+package runtimestate
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/contenox/contenox/runtime/statetype"
+)
+
+// healthWindow is the number of recent requests the latency percentiles of a
+// backend are computed over.
+const healthWindow = 100
+
+// healthSeries is the rolling record of one kind of request to one backend.
+type healthSeries struct {
+	latencies           []time.Duration // ring buffer of up to healthWindow samples
+	next                int
+	consecutiveFailures int
+	lastSuccess         time.Time
+	lastFailure         time.Time
+}
+
+func (h *healthSeries) record(latency time.Duration, failed bool, at time.Time) {
+	if len(h.latencies) < healthWindow {
+		h.latencies = append(h.latencies, latency)
+	} else {
+		h.latencies[h.next] = latency
+	}
+	h.next = (h.next + 1) % healthWindow
+	if failed {
+		h.consecutiveFailures++
+		h.lastFailure = at
+		return
+	}
+	h.consecutiveFailures = 0
+	h.lastSuccess = at
+}
+
+func (h *healthSeries) stats() statetype.HealthStats {
+	sorted := slices.Clone(h.latencies)
+	slices.Sort(sorted)
+	st := statetype.HealthStats{
+		Samples:             len(sorted),
+		P50Ms:               percentileMs(sorted, 50),
+		P90Ms:               percentileMs(sorted, 90),
+		P99Ms:               percentileMs(sorted, 99),
+		ConsecutiveFailures: h.consecutiveFailures,
+	}
+	if !h.lastSuccess.IsZero() {
+		t := h.lastSuccess
+		st.LastSuccess = &t
+	}
+	if !h.lastFailure.IsZero() {
+		t := h.lastFailure
+		st.LastFailure = &t
+	}
+	return st
+}
+
+// percentileMs returns the nearest-rank percentile p of sorted in milliseconds.
+func percentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return float64(sorted[max(rank, 1)-1]) / float64(time.Millisecond)
+}
+
+// healthTracker holds the health series of every backend by backend ID.
+type healthTracker struct {
+	mu        sync.Mutex
+	reconcile map[string]*healthSeries
+	inference map[string]*healthSeries
+}
+
+func (t *healthTracker) record(series *map[string]*healthSeries, backendID string, latency time.Duration, failed bool, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if *series == nil {
+		*series = map[string]*healthSeries{}
+	}
+	h, ok := (*series)[backendID]
+	if !ok {
+		h = &healthSeries{}
+		(*series)[backendID] = h
+	}
+	h.record(latency, failed, at)
+}
+
+// snapshot returns the health of a backend, or nil if nothing was recorded.
+func (t *healthTracker) snapshot(backendID string) *statetype.BackendHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reconcile, hasReconcile := t.reconcile[backendID]
+	inference, hasInference := t.inference[backendID]
+	if !hasReconcile && !hasInference {
+		return nil
+	}
+	health := &statetype.BackendHealth{}
+	if hasReconcile {
+		health.Reconcile = reconcile.stats()
+	}
+	if hasInference {
+		health.Inference = inference.stats()
+	}
+	return health
+}
+
+// forget drops the series of backends not in currentIDs.
+func (t *healthTracker) forget(currentIDs map[string]struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, series := range []map[string]*healthSeries{t.reconcile, t.inference} {
+		for id := range series {
+			if _, ok := currentIDs[id]; !ok {
+				delete(series, id)
+			}
+		}
+	}
+}
+
+// recordReconcile records the outcome of observing a backend. A backend whose
+// stored state carries an error counts as failed.
+func (s *State) recordReconcile(backendID string, latency time.Duration) {
+	failed := true
+	if current, ok := s.state.Load(backendID); ok {
+		if observed, ok := current.(*statetype.BackendRuntimeState); ok {
+			failed = observed.Error != ""
+		}
+	}
+	s.health.record(&s.health.reconcile, backendID, latency, failed, time.Now().UTC())
+}
+
+// RecordCall records the outcome of a model call served by the backend with
+// ID backendID for its inference health statistics. Calls to backends not in
+// the current state are ignored.
+func (s *State) RecordCall(backendID string, latency time.Duration, failed bool) {
+	if _, ok := s.state.Load(backendID); !ok {
+		return
+	}
+	s.health.record(&s.health.inference, backendID, latency, failed, time.Now().UTC())
+}
@@ -0,0 +1,56 @@
+package runtimestate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth_RecordCallPercentilesAndFailures(t *testing.T) {
+	s := &State{}
+	backend := runtimetypes.Backend{ID: "b1", Name: "local", Type: "ollama", BaseURL: "http://ollama:11434"}
+	s.state.Store(backend.ID, &statetype.BackendRuntimeState{ID: backend.ID, Backend: backend})
+
+	for i := 1; i <= 150; i++ {
+		s.RecordCall(backend.ID, time.Duration(i)*time.Millisecond, false)
+	}
+	s.RecordCall(backend.ID, time.Second, true)
+	s.RecordCall("b2", time.Second, true)
+
+	health := s.Get(context.Background())[backend.ID].Health
+	require.NotNil(t, health)
+	st := health.Inference
+	assert.Equal(t, healthWindow, st.Samples, "only the most recent calls are kept")
+	assert.Equal(t, 101.0, st.P50Ms)
+	assert.Equal(t, 141.0, st.P90Ms)
+	assert.Equal(t, 150.0, st.P99Ms)
+	assert.Equal(t, 1, st.ConsecutiveFailures)
+	require.NotNil(t, st.LastSuccess)
+	require.NotNil(t, st.LastFailure)
+	assert.Zero(t, health.Reconcile.Samples)
+
+	s.RecordCall(backend.ID, time.Millisecond, false)
+	assert.Zero(t, s.Get(context.Background())[backend.ID].Health.Inference.ConsecutiveFailures)
+}
+
+func TestHealth_ReconcileFailuresAndCleanup(t *testing.T) {
+	s := &State{}
+	ctx := context.Background()
+	backend := &runtimetypes.Backend{ID: "b1", Type: "unknown"}
+
+	s.processBackend(ctx, backend, nil)
+	s.processBackend(ctx, backend, nil)
+	health := s.Get(ctx)[backend.ID].Health
+	require.NotNil(t, health)
+	assert.Equal(t, 2, health.Reconcile.Samples)
+	assert.Equal(t, 2, health.Reconcile.ConsecutiveFailures)
+	assert.Nil(t, health.Reconcile.LastSuccess)
+
+	require.NoError(t, s.cleanupStaleBackends(ctx, map[string]struct{}{}))
+	assert.Nil(t, s.health.snapshot(backend.ID))
+}
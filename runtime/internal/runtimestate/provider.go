@@ -7,6 +7,11 @@ const (
 	OllamaKey            = ProviderKeyPrefix + "ollama"
 	OpenaiKey            = ProviderKeyPrefix + "openai"
 	GeminiKey            = ProviderKeyPrefix + "gemini"
+	AnthropicKey         = ProviderKeyPrefix + "anthropic"
+	AzureOpenAIKey       = ProviderKeyPrefix + "azure-openai"
+	BedrockKey           = ProviderKeyPrefix + "bedrock"
+	MistralKey           = ProviderKeyPrefix + "mistral"
+	GroqKey              = ProviderKeyPrefix + "groq"
 	VertexGoogleKey      = ProviderKeyPrefix + "vertex-google"
 	VertexAnthropicKey   = ProviderKeyPrefix + "vertex-anthropic"
 	VertexMetaKey        = ProviderKeyPrefix + "vertex-meta"
@@ -23,13 +23,23 @@ import (
 
 // ProviderCacheDuration defines how long the state of models from an external
 // provider (like OpenAI or Gemini) is cached to avoid frequent API calls.
+// A shorter Cache-Control max-age sent by the provider takes precedence.
 const ProviderCacheDuration = 1 * time.Hour
 
+// providerCacheRetention is how long a provider model list is kept after it
+// went stale, so it can be revalidated with a conditional request instead of
+// being downloaded again.
+const providerCacheRetention = 24 * time.Hour
+
 // providerCacheEntry holds the data and metadata for a cached provider state.
 // APIKey is stored so we can detect key rotation and invalidate the cache.
 type providerCacheEntry struct {
-	Models []modelrepo.ObservedModel `json:"models"`
-	APIKey string                    `json:"api_key"`
+	Models     []modelrepo.ObservedModel   `json:"models"`
+	APIKey     string                      `json:"api_key"`
+	Validators modelrepo.CatalogValidators `json:"validators"`
+	// FreshUntil is when the entry must be revalidated. Entries written before
+	// it existed have the zero value and are fresh until they expire.
+	FreshUntil time.Time `json:"fresh_until"`
 }
 
 // State manages the overall runtime status of multiple LLM backends.
@@ -44,6 +54,10 @@ type State struct {
 	// kvStore is used for persistent provider-model caching (nil = fall back to in-memory sync.Map)
 	kvStore       libkvstore.KVManager
 	providerCache sync.Map // fallback when kvStore is nil
+	// sync holds the per-backend sync intervals and last observations.
+	sync syncSchedule
+	// health holds the rolling latency and failure statistics per backend.
+	health healthTracker
 }
 
 type Option func(*State)
@@ -114,7 +128,13 @@ func New(ctx context.Context, dbInstance libdb.DBManager, psInstance libbus.Mess
 // Consequently, this method should be called periodically by an external process
 // responsible for its scheduling and lifecycle.
 // When the group feature is enabled via Withgroups option, it uses group-aware reconciliation.
+//
+// Backends with a sync interval override (see BackendSyncConfig) that were
+// observed less than their interval ago keep their runtime state.
 func (s *State) RunBackendCycle(ctx context.Context) error {
+	if err := s.loadSyncIntervals(ctx); err != nil {
+		return err
+	}
 	if s.withgroups {
 		return s.syncBackendsWithgroups(ctx)
 	}
@@ -142,16 +162,18 @@ func (s *State) Get(ctx context.Context) map[string]statetype.BackendRuntimeStat
 			// log.Fatalf("failed to unmarshal backend: %v", err)
 		}
 		backendCopy.SetAPIKey(backend.GetAPIKey())
+		backendCopy.Health = s.health.snapshot(backend.ID)
 		state[backend.ID] = backendCopy
 		return true
 	})
 	return state
 }
 
-// cleanupStaleBackends removes state entries for backends not present in currentIDs.
+// cleanupStaleBackends removes state entries for backends not present in currentIDs
+// and reports each removal as a reconcile event.
 // It performs type checking on state keys and logs errors for invalid key types.
 // This centralizes the state cleanup logic used by all reconciliation flows.
-func (s *State) cleanupStaleBackends(currentIDs map[string]struct{}) error {
+func (s *State) cleanupStaleBackends(ctx context.Context, currentIDs map[string]struct{}) error {
 	var err error
 	s.state.Range(func(key, value any) bool {
 		id, ok := key.(string)
@@ -161,10 +183,16 @@ func (s *State) cleanupStaleBackends(currentIDs map[string]struct{}) error {
 			return true
 		}
 		if _, exists := currentIDs[id]; !exists {
+			before, name := s.snapshot(id), ""
+			if observed, ok := value.(*statetype.BackendRuntimeState); ok {
+				name = observed.Name
+			}
 			s.state.Delete(id)
+			s.reportRemoved(ctx, id, name, before)
 		}
 		return true
 	})
+	s.health.forget(currentIDs)
 	return err
 }
 
@@ -230,7 +258,7 @@ func (s *State) syncBackendsWithgroups(ctx context.Context) error {
 		s.processBackend(ctx, backendObj, modelsForThisBackend)
 	}
 
-	return s.cleanupStaleBackends(activeBackendIDs)
+	return s.cleanupStaleBackends(ctx, activeBackendIDs)
 }
 
 // syncBackends is the global reconciliation logic called by RunBackendCycle.
@@ -257,7 +285,7 @@ func (s *State) syncBackends(ctx context.Context) error {
 
 	currentIDs := make(map[string]struct{})
 	s.processBackends(ctx, backends, allModels, currentIDs)
-	return s.cleanupStaleBackends(currentIDs)
+	return s.cleanupStaleBackends(ctx, currentIDs)
 }
 
 // Helper method to process backends and collect their IDs
@@ -274,6 +302,13 @@ func (s *State) processBackends(ctx context.Context, backends []*runtimetypes.Ba
 // including any errors encountered for unsupported types.
 // Helper method to process backends and collect their IDs
 func (s *State) processBackend(ctx context.Context, backend *runtimetypes.Backend, declaredModels []*runtimetypes.Model) {
+	now := time.Now()
+	if !s.syncDue(backend, now) {
+		return
+	}
+	defer s.markSynced(backend.ID, now)
+	defer func() { s.recordReconcile(backend.ID, time.Since(now)) }()
+	defer s.reportObservation(ctx, backend.ID, backend.Name, s.snapshot(backend.ID))
 	switch strings.ToLower(backend.Type) {
 	case "ollama":
 		s.processOllamaBackend(ctx, backend, declaredModels)
@@ -283,6 +318,16 @@ func (s *State) processBackend(ctx context.Context, backend *runtimetypes.Backen
 		s.processGeminiBackend(ctx, backend, declaredModels)
 	case "openai":
 		s.processOpenAIBackend(ctx, backend, declaredModels)
+	case "anthropic":
+		s.processCatalogBackend(ctx, backend, declaredModels, "Anthropic", false)
+	case "azure-openai":
+		s.processCatalogBackend(ctx, backend, declaredModels, "Azure OpenAI", false)
+	case "bedrock":
+		s.processCatalogBackend(ctx, backend, declaredModels, "Bedrock", true)
+	case "mistral":
+		s.processCatalogBackend(ctx, backend, declaredModels, "Mistral", false)
+	case "groq":
+		s.processCatalogBackend(ctx, backend, declaredModels, "Groq", false)
 	case "local":
 		s.processLocalBackend(ctx, backend, declaredModels)
 	case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
@@ -311,7 +356,7 @@ func (s *State) processOllamaBackend(ctx context.Context, backend *runtimetypes.
 	}
 
 	apiKey := ""
-	if key, err := s.loadProviderAPIKey(ctx, backend.Type); err == nil {
+	if key, err := s.loadBackendAPIKey(ctx, backend); err == nil {
 		apiKey = key
 	}
 
@@ -349,7 +394,9 @@ func (s *State) processOllamaBackend(ctx context.Context, backend *runtimetypes.
 			declCopy.CanEmbed = lmr.CanEmbed
 			declCopy.CanPrompt = lmr.CanPrompt
 			declCopy.CanStream = lmr.CanStream
-			_ = runtimetypes.New(s.dbInstance.WithoutTransaction()).UpdateModel(ctx, &declCopy)
+			if err := runtimetypes.New(s.dbInstance.WithoutTransaction()).UpdateModel(ctx, &declCopy); err == nil {
+				s.reportModelUpdated(ctx, backend.ID, backend.Name, declCopy.Model, declCopy.ContextLength)
+			}
 		}
 
 		// Declared caps act as explicit overrides (admin intent wins over observed values).
@@ -413,27 +460,29 @@ func (s *State) processLocalBackend(ctx context.Context, backend *runtimetypes.B
 	s.state.Store(backend.ID, stateservice)
 }
 
-// processVLLMBackend handles the state reconciliation for a single vLLM backend.
-// Since vLLM instances typically serve a single model, we verify that the running model
-// matches one of the models assigned to the backend through its groups.
+// processVLLMBackend handles state reconciliation for a vLLM backend. A server
+// may serve several models and LoRA adapters; each listed model is reconciled
+// against the declared models on its own. An adapter that is not declared
+// itself takes the capabilities and context length declared for its base
+// model.
 func (s *State) processVLLMBackend(ctx context.Context, backend *runtimetypes.Backend, models []*runtimetypes.Model) {
 	declaredModelMap := make(map[string]*runtimetypes.Model)
 	for _, m := range models {
 		declaredModelMap[m.Model] = m
 	}
-	catalog, err := s.newCatalogProvider(backend, "")
+	catalog, err := s.newCatalogProvider(backend, backend.APIKey)
 	if err != nil {
-		storeBackendError(s, backend, "", err, nil)
+		storeBackendError(s, backend, backend.APIKey, err, nil)
 		return
 	}
 
 	observedModels, err := catalog.ListModels(ctx)
 	if err != nil {
-		storeBackendError(s, backend, "", err, nil)
+		storeBackendError(s, backend, backend.APIKey, err, nil)
 		return
 	}
 	if len(observedModels) == 0 {
-		storeBackendError(s, backend, "", fmt.Errorf("No models found in response"), nil)
+		storeBackendError(s, backend, backend.APIKey, fmt.Errorf("No models found in response"), nil)
 		return
 	}
 
@@ -443,22 +492,27 @@ func (s *State) processVLLMBackend(ctx context.Context, backend *runtimetypes.Ba
 		Models:  observedModelNames(observedModels),
 		Backend: *backend,
 	}
+	res.SetAPIKey(backend.APIKey)
 
 	pulledModels := make([]statetype.ModelPullStatus, 0, len(observedModels))
 	for _, observed := range observedModels {
+		parent := observed.Meta[modelrepo.MetaParentModel]
 		if declaredModel, exists := declaredModelMap[observed.Name]; exists {
 			effectiveContextLen := declaredModel.ContextLength
 			if effectiveContextLen == 0 && observed.ContextLength > 0 {
 				effectiveContextLen = observed.ContextLength
 				declCopy := *declaredModel
 				declCopy.ContextLength = observed.ContextLength
-				_ = runtimetypes.New(s.dbInstance.WithoutTransaction()).UpdateModel(ctx, &declCopy)
+				if err := runtimetypes.New(s.dbInstance.WithoutTransaction()).UpdateModel(ctx, &declCopy); err == nil {
+					s.reportModelUpdated(ctx, backend.ID, backend.Name, declCopy.Model, declCopy.ContextLength)
+				}
 			}
 
 			pulledModels = append(pulledModels, statetype.ModelPullStatus{
 				Name:          declaredModel.ID,
 				Model:         declaredModel.Model,
 				ModifiedAt:    declaredModel.UpdatedAt,
+				Details:       statetype.ModelDetails{ParentModel: parent},
 				ContextLength: effectiveContextLen,
 				CanChat:       declaredModel.CanChat,
 				CanEmbed:      declaredModel.CanEmbed,
@@ -468,6 +522,19 @@ func (s *State) processVLLMBackend(ctx context.Context, backend *runtimetypes.Ba
 			continue
 		}
 
+		if base, exists := declaredModelMap[parent]; parent != "" && exists {
+			adapter := pullStatusFromObservedModel(observed)
+			if base.ContextLength > 0 {
+				adapter.ContextLength = base.ContextLength
+			}
+			adapter.CanChat = base.CanChat
+			adapter.CanEmbed = base.CanEmbed
+			adapter.CanPrompt = base.CanPrompt
+			adapter.CanStream = base.CanStream
+			pulledModels = append(pulledModels, adapter)
+			continue
+		}
+
 		if s.autoDiscoverModels {
 			pulledModels = append(pulledModels, pullStatusFromObservedModel(observed))
 		}
@@ -488,7 +555,7 @@ func (s *State) processGeminiBackend(ctx context.Context, backend *runtimetypes.
 		PulledModels: []statetype.ModelPullStatus{},
 	}
 	stateInstance.SetAPIKey("")
-	apiKey, err := s.loadProviderAPIKey(ctx, backend.Type)
+	apiKey, err := s.loadBackendAPIKey(ctx, backend)
 	if err != nil {
 		if errors.Is(err, libdb.ErrNotFound) {
 			stateInstance.Error = "API key not configured"
@@ -500,23 +567,7 @@ func (s *State) processGeminiBackend(ctx context.Context, backend *runtimetypes.
 	}
 	stateInstance.SetAPIKey(apiKey)
 
-	if cachedModels, ok := s.loadObservedModelCache(ctx, backend.ID, apiKey); ok {
-		stateInstance.Models = observedModelNames(cachedModels)
-		stateInstance.PulledModels = make([]statetype.ModelPullStatus, 0, len(cachedModels))
-		for _, model := range cachedModels {
-			stateInstance.PulledModels = append(stateInstance.PulledModels, pullStatusFromObservedModel(model))
-		}
-		s.state.Store(backend.ID, stateInstance)
-		return
-	}
-
-	catalog, err := s.newCatalogProvider(backend, apiKey)
-	if err != nil {
-		stateInstance.Error = err.Error()
-		s.state.Store(backend.ID, stateInstance)
-		return
-	}
-	observedModels, err := catalog.ListModels(ctx)
+	observedModels, source, err := s.observeCatalogModels(ctx, backend, apiKey)
 	if err != nil {
 		stateInstance.Error = err.Error()
 		s.state.Store(backend.ID, stateInstance)
@@ -524,15 +575,13 @@ func (s *State) processGeminiBackend(ctx context.Context, backend *runtimetypes.
 	}
 
 	// Update state
+	stateInstance.ModelListSource = source
 	stateInstance.Models = observedModelNames(observedModels)
 	stateInstance.PulledModels = make([]statetype.ModelPullStatus, 0, len(observedModels))
 	for _, model := range observedModels {
 		stateInstance.PulledModels = append(stateInstance.PulledModels, pullStatusFromObservedModel(model))
 	}
 	s.state.Store(backend.ID, stateInstance)
-
-	// Store successful result in cache
-	s.storeObservedModelCache(ctx, backend.ID, apiKey, observedModels)
 }
 
 // processVertexBackend handles state reconciliation for all vertex-* backend types.
@@ -546,39 +595,23 @@ func (s *State) processVertexBackend(ctx context.Context, backend *runtimetypes.
 	}
 
 	// credJSON may be empty (ADC fallback) — that's fine, not an error.
-	credJSON, _ := s.loadProviderAPIKey(ctx, backend.Type)
+	credJSON, _ := s.loadBackendAPIKey(ctx, backend)
 	stateInstance.SetAPIKey(credJSON)
 
-	if cachedModels, ok := s.loadObservedModelCache(ctx, backend.ID, credJSON); ok {
-		stateInstance.Models = observedModelNames(cachedModels)
-		stateInstance.PulledModels = make([]statetype.ModelPullStatus, 0, len(cachedModels))
-		for _, model := range cachedModels {
-			stateInstance.PulledModels = append(stateInstance.PulledModels, pullStatusFromObservedModel(model))
-		}
-		s.state.Store(backend.ID, stateInstance)
-		return
-	}
-
-	catalog, err := s.newCatalogProvider(backend, credJSON)
-	if err != nil {
-		stateInstance.Error = err.Error()
-		s.state.Store(backend.ID, stateInstance)
-		return
-	}
-	observedModels, err := catalog.ListModels(ctx)
+	observedModels, source, err := s.observeCatalogModels(ctx, backend, credJSON)
 	if err != nil {
 		stateInstance.Error = err.Error()
 		s.state.Store(backend.ID, stateInstance)
 		return
 	}
 
+	stateInstance.ModelListSource = source
 	stateInstance.Models = observedModelNames(observedModels)
 	stateInstance.PulledModels = make([]statetype.ModelPullStatus, 0, len(observedModels))
 	for _, model := range observedModels {
 		stateInstance.PulledModels = append(stateInstance.PulledModels, pullStatusFromObservedModel(model))
 	}
 	s.state.Store(backend.ID, stateInstance)
-	s.storeObservedModelCache(ctx, backend.ID, credJSON, observedModels)
 }
 
 func (s *State) processOpenAIBackend(ctx context.Context, backend *runtimetypes.Backend, models []*runtimetypes.Model) {
@@ -589,7 +622,7 @@ func (s *State) processOpenAIBackend(ctx context.Context, backend *runtimetypes.
 		Backend:      *backend,
 	}
 
-	apiKey, err := s.loadProviderAPIKey(ctx, backend.Type)
+	apiKey, err := s.loadBackendAPIKey(ctx, backend)
 	if err != nil {
 		if errors.Is(err, libdb.ErrNotFound) {
 			stateInstance.Error = "API key not configured"
@@ -608,24 +641,15 @@ func (s *State) processOpenAIBackend(ctx context.Context, backend *runtimetypes.
 		declaredModels[name] = model
 	}
 
-	observedModels, ok := s.loadObservedModelCache(ctx, backend.ID, apiKey)
-	if !ok {
-		catalog, err := s.newCatalogProvider(backend, apiKey)
-		if err != nil {
-			stateInstance.Error = err.Error()
-			s.state.Store(backend.ID, stateInstance)
-			return
-		}
-		observedModels, err = catalog.ListModels(ctx)
-		if err != nil {
-			stateInstance.Error = err.Error()
-			s.state.Store(backend.ID, stateInstance)
-			return
-		}
-		s.storeObservedModelCache(ctx, backend.ID, apiKey, observedModels)
+	observedModels, source, err := s.observeCatalogModels(ctx, backend, apiKey)
+	if err != nil {
+		stateInstance.Error = err.Error()
+		s.state.Store(backend.ID, stateInstance)
+		return
 	}
 
 	// Update state
+	stateInstance.ModelListSource = source
 	stateInstance.Models = observedModelNames(observedModels)
 	pulledModels := make([]statetype.ModelPullStatus, 0, len(observedModels))
 	for _, observed := range observedModels {
@@ -653,3 +677,84 @@ func (s *State) processOpenAIBackend(ctx context.Context, backend *runtimetypes.
 
 	s.state.Store(backend.ID, stateInstance)
 }
+
+// processCatalogBackend handles state reconciliation for a backend whose
+// models are listed by its provider catalog: Anthropic, Azure OpenAI (the
+// deployments of the resource, or the ones named in the backend URL when the
+// listing is retired), Mistral, Groq and Bedrock (models and inference
+// profiles). provider names the backend type in errors. With keyOptional a
+// backend without API key is still observed; Bedrock then signs requests
+// with the AWS environment variables or the shared credentials file.
+func (s *State) processCatalogBackend(ctx context.Context, backend *runtimetypes.Backend, models []*runtimetypes.Model, provider string, keyOptional bool) {
+	stateInstance := &statetype.BackendRuntimeState{
+		ID:           backend.ID,
+		Name:         backend.Name,
+		Backend:      *backend,
+		PulledModels: []statetype.ModelPullStatus{},
+	}
+
+	apiKey, err := s.loadBackendAPIKey(ctx, backend)
+	switch {
+	case errors.Is(err, libdb.ErrNotFound) && !keyOptional:
+		stateInstance.Error = "API key not configured"
+		s.state.Store(backend.ID, stateInstance)
+		return
+	case err != nil && !errors.Is(err, libdb.ErrNotFound):
+		stateInstance.Error = fmt.Sprintf("Failed to retrieve API key configuration: %v", err)
+		s.state.Store(backend.ID, stateInstance)
+		return
+	}
+	stateInstance.SetAPIKey(apiKey)
+
+	observedModels, source, err := s.observeCatalogModels(ctx, backend, apiKey)
+	if err != nil {
+		stateInstance.Error = err.Error()
+		s.state.Store(backend.ID, stateInstance)
+		return
+	}
+
+	stateInstance.ModelListSource = source
+	stateInstance.Models = observedModelNames(observedModels)
+	stateInstance.PulledModels = s.catalogPullStatuses(observedModels, models)
+	if len(models) > 0 && len(stateInstance.PulledModels) == 0 && !s.autoDiscoverModels {
+		stateInstance.Error = declaredModelsUnavailableError(provider, declaredModelsByName(models), stateInstance.Models).Error()
+	}
+	s.state.Store(backend.ID, stateInstance)
+}
+
+// catalogPullStatuses returns the models of a catalog backend to publish. A
+// declared model named like an observed one is published with the observed
+// capabilities plus the declared ones, and with the declared context length
+// when it is set. Observed models that are not declared are published only
+// when models are auto-discovered.
+func (s *State) catalogPullStatuses(observed []modelrepo.ObservedModel, models []*runtimetypes.Model) []statetype.ModelPullStatus {
+	declaredModels := declaredModelsByName(models)
+	pulled := make([]statetype.ModelPullStatus, 0, len(observed))
+	for _, model := range observed {
+		lmr := pullStatusFromObservedModel(model)
+		declaredModel, exists := declaredModels[model.Name]
+		if !exists {
+			if s.autoDiscoverModels {
+				pulled = append(pulled, lmr)
+			}
+			continue
+		}
+		if declaredModel.ContextLength > 0 {
+			lmr.ContextLength = declaredModel.ContextLength
+		}
+		lmr.CanChat = lmr.CanChat || declaredModel.CanChat
+		lmr.CanEmbed = lmr.CanEmbed || declaredModel.CanEmbed
+		lmr.CanPrompt = lmr.CanPrompt || declaredModel.CanPrompt
+		lmr.CanStream = lmr.CanStream || declaredModel.CanStream
+		pulled = append(pulled, lmr)
+	}
+	return pulled
+}
+
+func declaredModelsByName(models []*runtimetypes.Model) map[string]*runtimetypes.Model {
+	declared := make(map[string]*runtimetypes.Model, len(models))
+	for _, model := range models {
+		declared[model.Model] = model
+	}
+	return declared
+}
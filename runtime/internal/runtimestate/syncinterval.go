@@ -0,0 +1,141 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+)
+
+// BackendSyncKeyPrefix prefixes the KV keys holding per-backend
+// reconciliation settings; the backend ID follows.
+const BackendSyncKeyPrefix = runtimetypes.BackendSyncKeyPrefix
+
+// BackendSyncConfig overrides how often a single backend is reconciled.
+//
+// Without an override every RunBackendCycle observes local backends (Ollama,
+// vLLM, llama.cpp) and cloud model lists are cached for ProviderCacheDuration.
+// With one, a backend observed less than Interval ago keeps its runtime state
+// and cloud model lists stay fresh for Interval, so a local Ollama can be
+// synced every few seconds while a rate-limited provider is asked every few
+// hours.
+type BackendSyncConfig struct {
+	// Interval is a Go duration such as "10s" or "6h".
+	Interval string `json:"interval"`
+}
+
+// ParseSyncInterval parses a backend sync interval. It must be positive.
+func ParseSyncInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid sync interval: %v", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("sync interval must be positive, got %q", s)
+	}
+	return d, nil
+}
+
+// SetBackendSyncInterval stores the sync interval of a backend. Zero removes
+// the override.
+func SetBackendSyncInterval(ctx context.Context, store runtimetypes.Store, backendID string, interval time.Duration) error {
+	key := BackendSyncKeyPrefix + backendID
+	if interval == 0 {
+		if err := store.DeleteKV(ctx, key); err != nil && !errors.Is(err, libdb.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(BackendSyncConfig{Interval: interval.String()})
+	if err != nil {
+		return err
+	}
+	return store.SetKV(ctx, key, data)
+}
+
+// GetBackendSyncInterval returns the sync interval override of a backend, or
+// zero if it has none.
+func GetBackendSyncInterval(ctx context.Context, store runtimetypes.Store, backendID string) (time.Duration, error) {
+	var cfg BackendSyncConfig
+	if err := store.GetKV(ctx, BackendSyncKeyPrefix+backendID, &cfg); err != nil {
+		if errors.Is(err, libdb.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return ParseSyncInterval(cfg.Interval)
+}
+
+// syncSchedule tracks the sync interval overrides and the last observation
+// of each backend across RunBackendCycle calls.
+type syncSchedule struct {
+	mu        sync.Mutex
+	intervals map[string]time.Duration
+	lastSync  map[string]time.Time
+}
+
+// loadSyncIntervals refreshes the interval overrides from the database.
+// Invalid entries are ignored so a bad value never stops reconciliation.
+func (s *State) loadSyncIntervals(ctx context.Context) error {
+	kvs, err := runtimetypes.New(s.dbInstance.WithoutTransaction()).ListKVPrefix(ctx, BackendSyncKeyPrefix, nil, runtimetypes.MAXLIMIT)
+	if err != nil {
+		return fmt.Errorf("fetching backend sync intervals: %v", err)
+	}
+	intervals := make(map[string]time.Duration, len(kvs))
+	for _, kv := range kvs {
+		var cfg BackendSyncConfig
+		if json.Unmarshal(kv.Value, &cfg) != nil {
+			continue
+		}
+		if d, err := ParseSyncInterval(cfg.Interval); err == nil {
+			intervals[strings.TrimPrefix(kv.Key, BackendSyncKeyPrefix)] = d
+		}
+	}
+	s.sync.mu.Lock()
+	s.sync.intervals = intervals
+	s.sync.mu.Unlock()
+	return nil
+}
+
+// syncInterval returns the interval override of a backend, or zero.
+func (s *State) syncInterval(backendID string) time.Duration {
+	s.sync.mu.Lock()
+	defer s.sync.mu.Unlock()
+	return s.sync.intervals[backendID]
+}
+
+// syncDue reports whether backend must be observed in this cycle: it has no
+// interval override, was not observed yet, changed since, or its interval
+// elapsed.
+func (s *State) syncDue(backend *runtimetypes.Backend, now time.Time) bool {
+	s.sync.mu.Lock()
+	interval := s.sync.intervals[backend.ID]
+	last, seen := s.sync.lastSync[backend.ID]
+	s.sync.mu.Unlock()
+	if interval == 0 || !seen || !now.Before(last.Add(interval)) {
+		return true
+	}
+	current, ok := s.state.Load(backend.ID)
+	if !ok {
+		return true
+	}
+	observed, ok := current.(*statetype.BackendRuntimeState)
+	return !ok || !observed.Backend.UpdatedAt.Equal(backend.UpdatedAt) ||
+		observed.Backend.BaseURL != backend.BaseURL || observed.Backend.Type != backend.Type
+}
+
+func (s *State) markSynced(backendID string, now time.Time) {
+	s.sync.mu.Lock()
+	defer s.sync.mu.Unlock()
+	if s.sync.lastSync == nil {
+		s.sync.lastSync = map[string]time.Time{}
+	}
+	s.sync.lastSync[backendID] = now
+}
@@ -0,0 +1,44 @@
+package runtimestate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncDue_RespectsBackendInterval(t *testing.T) {
+	s := &State{}
+	now := time.Now()
+	backend := &runtimetypes.Backend{ID: "b1", Type: "openai", BaseURL: "https://api.openai.com/v1"}
+	s.sync.intervals = map[string]time.Duration{backend.ID: time.Hour}
+
+	require.True(t, s.syncDue(backend, now), "never observed")
+	s.state.Store(backend.ID, &statetype.BackendRuntimeState{ID: backend.ID, Backend: *backend})
+	s.markSynced(backend.ID, now)
+
+	require.False(t, s.syncDue(backend, now.Add(30*time.Minute)))
+	require.True(t, s.syncDue(backend, now.Add(time.Hour)))
+
+	changed := *backend
+	changed.BaseURL = "https://proxy.example.com/v1"
+	require.True(t, s.syncDue(&changed, now.Add(time.Minute)), "a changed backend is observed again")
+
+	other := &runtimetypes.Backend{ID: "b2", Type: "ollama"}
+	s.state.Store(other.ID, &statetype.BackendRuntimeState{ID: other.ID, Backend: *other})
+	s.markSynced(other.ID, now)
+	require.True(t, s.syncDue(other, now), "backends without an override are observed every cycle")
+}
+
+func TestParseSyncInterval(t *testing.T) {
+	d, err := ParseSyncInterval("90s")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, d)
+
+	_, err = ParseSyncInterval("0s")
+	require.Error(t, err)
+	_, err = ParseSyncInterval("soon")
+	require.Error(t, err)
+}
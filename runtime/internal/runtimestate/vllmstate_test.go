@@ -0,0 +1,70 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessVLLMBackend_MultipleModelsAndAdapters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{
+				{"id": "llama-8b", "max_model_len": 131072},
+				{"id": "sql-lora", "parent": "llama-8b"},
+				{"id": "chat-lora", "parent": "llama-8b"},
+				{"id": "qwen-4b", "max_model_len": 40960},
+			},
+		})
+	}))
+	defer server.Close()
+
+	backend := &runtimetypes.Backend{ID: "b1", Name: "gpu-box", Type: "vllm", BaseURL: server.URL}
+	declared := []*runtimetypes.Model{
+		{ID: "m1", Model: "llama-8b", ContextLength: 32768, CanChat: true, CanPrompt: true},
+		{ID: "m2", Model: "chat-lora", ContextLength: 8192, CanChat: true, CanStream: true},
+	}
+
+	s := &State{}
+	s.processVLLMBackend(context.Background(), backend, declared)
+	raw, ok := s.state.Load(backend.ID)
+	require.True(t, ok)
+	got := raw.(*statetype.BackendRuntimeState)
+	require.Empty(t, got.Error)
+	require.Equal(t, []string{"llama-8b", "sql-lora", "chat-lora", "qwen-4b"}, got.Models)
+
+	pulled := map[string]statetype.ModelPullStatus{}
+	for _, m := range got.PulledModels {
+		pulled[m.Model] = m
+	}
+	require.Len(t, pulled, 3, "undeclared models without a declared base are not published")
+
+	require.Equal(t, 32768, pulled["llama-8b"].ContextLength)
+	require.Empty(t, pulled["llama-8b"].Details.ParentModel)
+
+	sql := pulled["sql-lora"]
+	require.Equal(t, "llama-8b", sql.Details.ParentModel)
+	require.Equal(t, 32768, sql.ContextLength, "an undeclared adapter takes what is declared for its base")
+	require.True(t, sql.CanChat)
+	require.False(t, sql.CanStream)
+
+	chat := pulled["chat-lora"]
+	require.Equal(t, "m2", chat.Name)
+	require.Equal(t, 8192, chat.ContextLength, "a declared adapter keeps its own declaration")
+	require.True(t, chat.CanStream)
+	require.Equal(t, "llama-8b", chat.Details.ParentModel)
+
+	s = &State{autoDiscoverModels: true}
+	s.processVLLMBackend(context.Background(), backend, nil)
+	raw, _ = s.state.Load(backend.ID)
+	got = raw.(*statetype.BackendRuntimeState)
+	require.Len(t, got.PulledModels, 4)
+	require.Equal(t, "llama-8b", got.PulledModels[1].Details.ParentModel)
+	require.Equal(t, 131072, got.PulledModels[1].ContextLength)
+}
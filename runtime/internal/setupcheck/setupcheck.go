@@ -468,10 +468,12 @@ func backendHint(backend runtimetypes.Backend, kind backendErrorKind) string {
 	switch kind {
 	case backendErrorAPIKeyMissing:
 		switch strings.ToLower(strings.TrimSpace(backend.Type)) {
-		case "openai", "gemini":
+		case "openai", "gemini", "anthropic", "mistral", "groq", "azure-openai":
 			return fmt.Sprintf("Save credentials on Cloud providers, or re-add backend %q after exporting the provider API key.", backend.Name)
 		case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 			return fmt.Sprintf("Backend %q uses ADC (Application Default Credentials). Run: gcloud auth application-default login", backend.Name)
+		case "bedrock":
+			return fmt.Sprintf("Backend %q found no AWS credentials. Export AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or AWS_PROFILE, then rerun the backend cycle.", backend.Name)
 		case "ollama":
 			if isHostedOllamaBackend(backend) {
 				return fmt.Sprintf("Save the Ollama Cloud API key on Cloud providers, or re-add backend %q after exporting OLLAMA_API_KEY.", backend.Name)
@@ -482,10 +484,12 @@ func backendHint(backend runtimetypes.Backend, kind backendErrorKind) string {
 		}
 	case backendErrorAuth:
 		switch strings.ToLower(strings.TrimSpace(backend.Type)) {
-		case "openai", "gemini":
+		case "openai", "gemini", "anthropic", "mistral", "groq", "azure-openai":
 			return fmt.Sprintf("The stored API key for backend %q was rejected. Update the key on Cloud providers.", backend.Name)
 		case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 			return fmt.Sprintf("ADC credentials for backend %q were rejected. Refresh with: gcloud auth application-default login", backend.Name)
+		case "bedrock":
+			return fmt.Sprintf("AWS rejected the credentials of backend %q. Check that they are current and allow bedrock:ListFoundationModels and bedrock:InvokeModel.", backend.Name)
 		case "ollama":
 			if isHostedOllamaBackend(backend) {
 				return fmt.Sprintf("The stored Ollama Cloud API key for backend %q was rejected. Update the key on Cloud providers.", backend.Name)
@@ -498,6 +502,8 @@ func backendHint(backend runtimetypes.Backend, kind backendErrorKind) string {
 		switch strings.ToLower(strings.TrimSpace(backend.Type)) {
 		case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 			return fmt.Sprintf("Check connectivity to Vertex AI and confirm GOOGLE_CLOUD_PROJECT is set. Backend %q URL: %s", backend.Name, backend.BaseURL)
+		case "bedrock":
+			return fmt.Sprintf("Check connectivity to AWS and that Bedrock is offered in the region of backend %q (%s).", backend.Name, backend.BaseURL)
 		case "ollama":
 			if isHostedOllamaBackend(backend) {
 				return fmt.Sprintf("Check connectivity to Ollama Cloud and confirm the stored API key for backend %q.", backend.Name)
@@ -594,7 +600,7 @@ func modelNamePresent(available []string, wanted string) bool {
 
 func providerFixPath(provider string) string {
 	switch strings.ToLower(strings.TrimSpace(provider)) {
-	case "openai", "gemini", "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
+	case "openai", "gemini", "anthropic", "mistral", "groq", "azure-openai", "bedrock", "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 		return "/backends?tab=cloud-providers"
 	default:
 		return "/backends?tab=backends"
@@ -603,7 +609,7 @@ func providerFixPath(provider string) string {
 
 func providerFixPathForChecks(provider string, checks []BackendCheck) string {
 	switch strings.ToLower(strings.TrimSpace(provider)) {
-	case "openai", "gemini", "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
+	case "openai", "gemini", "anthropic", "mistral", "groq", "azure-openai", "bedrock", "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 		return "/backends?tab=cloud-providers"
 	case "ollama":
 		if anyHostedOllamaCheck(checks) {
@@ -619,6 +625,16 @@ func providerAddCommand(provider string) string {
 		return "contenox backend add openai --type openai --api-key-env OPENAI_API_KEY"
 	case "gemini":
 		return "contenox backend add gemini --type gemini --api-key-env GEMINI_API_KEY"
+	case "anthropic":
+		return "contenox backend add anthropic --type anthropic --api-key-env ANTHROPIC_API_KEY"
+	case "mistral":
+		return "contenox backend add mistral --type mistral --api-key-env MISTRAL_API_KEY"
+	case "groq":
+		return "contenox backend add groq --type groq --api-key-env GROQ_API_KEY"
+	case "azure-openai":
+		return "contenox backend add azure-openai --type azure-openai --api-key-env AZURE_OPENAI_API_KEY --url \"https://<resource>.openai.azure.com/?api-version=2024-10-21\""
+	case "bedrock":
+		return "contenox backend add bedrock --type bedrock --url https://bedrock-runtime.us-east-1.amazonaws.com   # signs with the AWS credentials of the environment"
 	case "local":
 		return "contenox backend add local --type local --url ~/.contenox/models/"
 	case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
@@ -630,8 +646,12 @@ func providerAddCommand(provider string) string {
 
 func noChatModelsCommand(provider string) string {
 	switch strings.ToLower(strings.TrimSpace(provider)) {
-	case "openai", "gemini":
+	case "openai", "gemini", "anthropic", "mistral", "groq":
 		return "contenox model list   # confirm which chat models the provider exposes"
+	case "azure-openai":
+		return "contenox model list   # models are deployment names; add ?deployments=<name> to the backend URL if none are listed"
+	case "bedrock":
+		return "contenox model list   # only models enabled under Model access in the Bedrock console are listed"
 	case "vertex-google":
 		return "contenox model list   # Gemini models from AI Studio metadata; set default-model to a gemini-* name"
 	case "vertex-anthropic", "vertex-meta", "vertex-mistralai":
@@ -645,10 +665,12 @@ func noChatModelsCommand(provider string) string {
 
 func primaryDiagnosticCommand(provider string) string {
 	switch strings.ToLower(strings.TrimSpace(provider)) {
-	case "openai", "gemini":
+	case "openai", "gemini", "anthropic", "mistral", "groq", "azure-openai":
 		return "contenox doctor --json   # inspect backendChecks.error for the provider backend"
 	case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 		return "gcloud auth application-default print-access-token   # verify ADC is working; also check GOOGLE_CLOUD_PROJECT is set"
+	case "bedrock":
+		return "aws bedrock list-foundation-models --by-inference-type ON_DEMAND   # verify the AWS credentials and region"
 	case "local":
 		return "ls ~/.contenox/models/   # confirm at least one *.gguf model exists; run 'contenox model pull <name>' if empty"
 	default:
@@ -672,6 +694,16 @@ func repairBackendCommand(check *BackendCheck) string {
 		return fmt.Sprintf("export OPENAI_API_KEY=... && contenox backend remove %q && contenox backend add %q --type openai --url %q --api-key-env OPENAI_API_KEY", check.Name, check.Name, chooseBaseURL(check.BaseURL, "https://api.openai.com/v1"))
 	case "gemini":
 		return fmt.Sprintf("export GEMINI_API_KEY=... && contenox backend remove %q && contenox backend add %q --type gemini --url %q --api-key-env GEMINI_API_KEY", check.Name, check.Name, chooseBaseURL(check.BaseURL, "https://generativelanguage.googleapis.com"))
+	case "anthropic":
+		return fmt.Sprintf("export ANTHROPIC_API_KEY=... && contenox backend remove %q && contenox backend add %q --type anthropic --url %q --api-key-env ANTHROPIC_API_KEY", check.Name, check.Name, chooseBaseURL(check.BaseURL, "https://api.anthropic.com/v1"))
+	case "mistral":
+		return fmt.Sprintf("export MISTRAL_API_KEY=... && contenox backend remove %q && contenox backend add %q --type mistral --url %q --api-key-env MISTRAL_API_KEY", check.Name, check.Name, chooseBaseURL(check.BaseURL, "https://api.mistral.ai/v1"))
+	case "groq":
+		return fmt.Sprintf("export GROQ_API_KEY=... && contenox backend remove %q && contenox backend add %q --type groq --url %q --api-key-env GROQ_API_KEY", check.Name, check.Name, chooseBaseURL(check.BaseURL, "https://api.groq.com/openai/v1"))
+	case "azure-openai":
+		return fmt.Sprintf("export AZURE_OPENAI_API_KEY=... && contenox backend remove %q && contenox backend add %q --type azure-openai --url %q --api-key-env AZURE_OPENAI_API_KEY", check.Name, check.Name, check.BaseURL)
+	case "bedrock":
+		return fmt.Sprintf("export AWS_ACCESS_KEY_ID=... AWS_SECRET_ACCESS_KEY=... && contenox backend remove %q && contenox backend add %q --type bedrock --url %q", check.Name, check.Name, check.BaseURL)
 	case "vertex-google", "vertex-anthropic", "vertex-meta", "vertex-mistralai":
 		return fmt.Sprintf("gcloud auth application-default login && contenox backend remove %q && contenox backend add %q --type %s --url %q", check.Name, check.Name, backendType, check.BaseURL)
 	default:
@@ -714,6 +746,16 @@ func providerDisplayName(provider string) string {
 		return "OpenAI"
 	case "gemini":
 		return "Gemini"
+	case "anthropic":
+		return "Anthropic"
+	case "mistral":
+		return "Mistral AI"
+	case "groq":
+		return "Groq"
+	case "azure-openai":
+		return "Azure OpenAI"
+	case "bedrock":
+		return "AWS Bedrock"
 	case "vllm":
 		return "vLLM"
 	case "local":
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
@@ -230,14 +231,19 @@ func (p *OpenAPIToolProtocol) ExecuteTool(
 		return nil, taskengine.DataTypeNil, nil
 	}
 
-	// Return structured JSON if possible, otherwise fall back to a raw string.
-	if strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+	// Return structured JSON if possible, binary payloads as files, otherwise
+	// fall back to a raw string.
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "application/json") {
 		var result interface{}
 		if err := json.Unmarshal(responseBody, &result); err != nil {
 			return nil, taskengine.DataTypeAny, fmt.Errorf("failed to parse JSON response: %w", err)
 		}
 		return result, taskengine.DataTypeJSON, nil
 	}
+	if isBinaryContentType(contentType) {
+		return taskengine.NewFile(responseFileName(resp.Header), contentType, responseBody), taskengine.DataTypeFile, nil
+	}
 	return string(responseBody), taskengine.DataTypeString, nil
 }
 
@@ -469,3 +475,29 @@ func isValidToolName(name string) bool {
 	}
 	return true
 }
+
+// isBinaryContentType reports whether a response of the given content type
+// carries a binary artifact rather than text.
+func isBinaryContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return false
+	}
+	switch mediaType {
+	case "application/xml", "application/javascript", "application/x-www-form-urlencoded", "application/yaml":
+		return false
+	}
+	return true
+}
+
+// responseFileName returns the file name of a Content-Disposition header.
+func responseFileName(header http.Header) string {
+	_, params, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
@@ -0,0 +1,905 @@
+package localtools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const calcToolsName = "calc"
+
+const (
+	// calcDefaultPrecision is the number of decimals shown for results without
+	// a finite decimal expansion, e.g. 1/3.
+	calcDefaultPrecision = 20
+	calcMaxPrecision     = 100
+	// calcFloatPrec is the mantissa size in bits of irrational intermediate
+	// results (square roots), comfortably above calcMaxPrecision digits.
+	calcFloatPrec = 512
+	// calcMaxBits bounds the size of powers so one call cannot exhaust memory.
+	calcMaxBits  = 1 << 20
+	calcMaxDepth = 200
+)
+
+// CalcTools evaluates arithmetic, converts units and does date math
+// deterministically, so chains do not need a model to do arithmetic.
+//
+// Arithmetic is exact on rational numbers; only square roots and the
+// constants pi and e are approximated (to more than 100 digits).
+type CalcTools struct {
+	now func() time.Time
+}
+
+// NewCalcTools creates the calc tools.
+func NewCalcTools() taskengine.ToolsRepo {
+	return &CalcTools{now: time.Now}
+}
+
+// Exec implements taskengine.ToolsRepo.
+// Arguments come from tools.Args or, for execute_tool_calls, from the input
+// map. A chain task calling "calculate" may pass the expression as its
+// string input instead.
+func (h *CalcTools) Exec(ctx context.Context, startTime time.Time, input any, debug bool, toolsCall *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	if toolsCall == nil {
+		return nil, taskengine.DataTypeAny, errors.New("calc: tools required")
+	}
+	toolName := toolsCall.ToolName
+	if toolName == "" {
+		toolName = toolsCall.Name
+	}
+	precision, err := calcPrecision(calcArg(toolsCall, input, "precision"))
+	if err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+
+	switch toolName {
+	case calcToolsName, "calculate":
+		expr := calcArg(toolsCall, input, "expression")
+		if s, ok := input.(string); ok && expr == "" {
+			expr = s
+		}
+		v, err := evalCalcExpression(expr)
+		if err != nil {
+			return nil, taskengine.DataTypeAny, fmt.Errorf("calc: %w", err)
+		}
+		return formatCalcRat(v, precision), taskengine.DataTypeString, nil
+	case "convert_units":
+		out, err := convertCalcUnits(calcArg(toolsCall, input, "value"), calcArg(toolsCall, input, "from"), calcArg(toolsCall, input, "to"), precision)
+		if err != nil {
+			return nil, taskengine.DataTypeAny, fmt.Errorf("calc: %w", err)
+		}
+		return out, taskengine.DataTypeJSON, nil
+	case "date_math":
+		out, err := h.dateMath(calcArg(toolsCall, input, "date"), calcArg(toolsCall, input, "add"), calcArg(toolsCall, input, "until"), calcArg(toolsCall, input, "timezone"))
+		if err != nil {
+			return nil, taskengine.DataTypeAny, fmt.Errorf("calc: %w", err)
+		}
+		return out, taskengine.DataTypeJSON, nil
+	default:
+		return nil, taskengine.DataTypeAny, fmt.Errorf("calc: unknown tool %s", toolName)
+	}
+}
+
+// calcArg returns the argument key from the tools call args or the input map.
+func calcArg(toolsCall *taskengine.ToolsCall, input any, key string) string {
+	if toolsCall != nil {
+		if v := strings.TrimSpace(toolsCall.Args[key]); v != "" {
+			return v
+		}
+	}
+	args, ok := input.(map[string]any)
+	if !ok {
+		return ""
+	}
+	switch v := args[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func calcPrecision(raw string) (int, error) {
+	if raw == "" {
+		return calcDefaultPrecision, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 || n > calcMaxPrecision {
+		return 0, fmt.Errorf("calc: precision must be an integer between 0 and %d", calcMaxPrecision)
+	}
+	return n, nil
+}
+
+// formatCalcRat renders r exactly when its decimal expansion ends within
+// precision digits and rounded to precision decimals otherwise.
+func formatCalcRat(r *big.Rat, precision int) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	den := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, p := range []int64{2, 5} {
+		n := 0
+		prime, m := big.NewInt(p), new(big.Int)
+		for {
+			q, rem := new(big.Int).QuoRem(den, prime, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			den, n = q, n+1
+		}
+		digits = max(digits, n)
+	}
+	if den.Cmp(big.NewInt(1)) == 0 && digits <= precision {
+		return r.FloatString(digits)
+	}
+	s := r.FloatString(precision)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// calcValue is an intermediate result; percent marks a literal such as 15%
+// so that "200 + 15%" adds 15 percent of 200.
+type calcValue struct {
+	r       *big.Rat
+	percent bool
+}
+
+type calcToken struct {
+	kind byte // 'n' number, 'i' identifier, 'o' operator, 0 end
+	text string
+}
+
+type calcParser struct {
+	toks  []calcToken
+	pos   int
+	depth int
+}
+
+// evalCalcExpression evaluates an arithmetic expression. It supports
+// + - * / ^ (or **), mod, parentheses, percentages ("15%", "15% of 80",
+// "200 - 10%"), the constants pi and e and the functions sqrt, abs, floor,
+// ceil, round(x[, digits]), min and max.
+func evalCalcExpression(expr string) (*big.Rat, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New("expression is required")
+	}
+	toks, err := lexCalc(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &calcParser{toks: toks}
+	v, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != 0 {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return v.r, nil
+}
+
+func lexCalc(s string) ([]calcToken, error) {
+	var toks []calcToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.' || rs[j] == '_') {
+				j++
+			}
+			// An exponent only when digits follow: in "2e" the e is the constant.
+			if j < len(rs) && (rs[j] == 'e' || rs[j] == 'E') {
+				k := j + 1
+				if k < len(rs) && (rs[k] == '+' || rs[k] == '-') {
+					k++
+				}
+				if k < len(rs) && unicode.IsDigit(rs[k]) {
+					for k < len(rs) && unicode.IsDigit(rs[k]) {
+						k++
+					}
+					j = k
+				}
+			}
+			toks = append(toks, calcToken{kind: 'n', text: strings.ReplaceAll(string(rs[i:j]), "_", "")})
+			i = j
+		case unicode.IsLetter(c):
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, calcToken{kind: 'i', text: strings.ToLower(string(rs[i:j]))})
+			i = j
+		case c == '*' && i+1 < len(rs) && rs[i+1] == '*':
+			toks = append(toks, calcToken{kind: 'o', text: "^"})
+			i += 2
+		case strings.ContainsRune("+-*/%^(),", c):
+			toks = append(toks, calcToken{kind: 'o', text: string(c)})
+			i++
+		case c == '×':
+			toks = append(toks, calcToken{kind: 'o', text: "*"})
+			i++
+		case c == '÷':
+			toks = append(toks, calcToken{kind: 'o', text: "/"})
+			i++
+		case c == '−':
+			toks = append(toks, calcToken{kind: 'o', text: "-"})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return toks, nil
+}
+
+func (p *calcParser) peek() calcToken {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return calcToken{}
+}
+
+func (p *calcParser) next() calcToken {
+	t := p.peek()
+	if t.kind != 0 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *calcParser) isOp(t calcToken, ops ...string) bool {
+	if t.kind != 'o' && t.kind != 'i' {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *calcParser) expr() (calcValue, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > calcMaxDepth {
+		return calcValue{}, errors.New("expression nested too deeply")
+	}
+	left, err := p.term()
+	if err != nil {
+		return calcValue{}, err
+	}
+	for t := p.peek(); t.kind == 'o' && (t.text == "+" || t.text == "-"); t = p.peek() {
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return calcValue{}, err
+		}
+		delta := right.r
+		if right.percent && !left.percent {
+			delta = new(big.Rat).Mul(left.r, right.r)
+		}
+		sum := new(big.Rat)
+		if t.text == "+" {
+			sum.Add(left.r, delta)
+		} else {
+			sum.Sub(left.r, delta)
+		}
+		left = calcValue{r: sum, percent: left.percent && right.percent}
+	}
+	return left, nil
+}
+
+func (p *calcParser) term() (calcValue, error) {
+	left, err := p.unary()
+	if err != nil {
+		return calcValue{}, err
+	}
+	for t := p.peek(); p.isOp(t, "*", "/", "%", "of", "mod"); t = p.peek() {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return calcValue{}, err
+		}
+		out := new(big.Rat)
+		switch t.text {
+		case "*", "of":
+			out.Mul(left.r, right.r)
+		case "/":
+			if right.r.Sign() == 0 {
+				return calcValue{}, errors.New("division by zero")
+			}
+			out.Quo(left.r, right.r)
+		default:
+			if right.r.Sign() == 0 {
+				return calcValue{}, errors.New("modulo by zero")
+			}
+			// a mod b = a - b*floor(a/b), the sign follows b.
+			q := calcFloor(new(big.Rat).Quo(left.r, right.r))
+			out.Sub(left.r, new(big.Rat).Mul(right.r, new(big.Rat).SetInt(q)))
+		}
+		left = calcValue{r: out}
+	}
+	return left, nil
+}
+
+func (p *calcParser) unary() (calcValue, error) {
+	if t := p.peek(); t.kind == 'o' && (t.text == "-" || t.text == "+") {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > calcMaxDepth {
+			return calcValue{}, errors.New("expression nested too deeply")
+		}
+		v, err := p.unary()
+		if err != nil {
+			return calcValue{}, err
+		}
+		if t.text == "-" {
+			v.r = new(big.Rat).Neg(v.r)
+		}
+		return v, nil
+	}
+	return p.power()
+}
+
+func (p *calcParser) power() (calcValue, error) {
+	base, err := p.postfix()
+	if err != nil {
+		return calcValue{}, err
+	}
+	if t := p.peek(); t.kind != 'o' || t.text != "^" {
+		return base, nil
+	}
+	p.next()
+	exp, err := p.unary()
+	if err != nil {
+		return calcValue{}, err
+	}
+	r, err := calcPow(base.r, exp.r)
+	if err != nil {
+		return calcValue{}, err
+	}
+	return calcValue{r: r}, nil
+}
+
+func (p *calcParser) postfix() (calcValue, error) {
+	v, err := p.primary()
+	if err != nil {
+		return calcValue{}, err
+	}
+	if t := p.peek(); t.kind == 'o' && t.text == "%" {
+		// "10 % 3" is modulo; a trailing or operator-followed % is a percentage.
+		if p.pos+1 < len(p.toks) {
+			n := p.toks[p.pos+1]
+			if n.kind == 'n' || (n.kind == 'i' && n.text != "of" && n.text != "mod") || (n.kind == 'o' && n.text == "(") {
+				return v, nil
+			}
+		}
+		p.next()
+		v = calcValue{r: new(big.Rat).Quo(v.r, big.NewRat(100, 1)), percent: true}
+	}
+	return v, nil
+}
+
+func (p *calcParser) primary() (calcValue, error) {
+	t := p.next()
+	switch t.kind {
+	case 'n':
+		r, ok := new(big.Rat).SetString(t.text)
+		if !ok {
+			return calcValue{}, fmt.Errorf("invalid number %q", t.text)
+		}
+		return calcValue{r: r}, nil
+	case 'o':
+		if t.text != "(" {
+			return calcValue{}, fmt.Errorf("unexpected %q", t.text)
+		}
+		v, err := p.expr()
+		if err != nil {
+			return calcValue{}, err
+		}
+		if c := p.next(); c.kind != 'o' || c.text != ")" {
+			return calcValue{}, errors.New("missing closing parenthesis")
+		}
+		return calcValue{r: v.r}, nil
+	case 'i':
+		if n := p.peek(); n.kind == 'o' && n.text == "(" {
+			p.next()
+			args, err := p.args()
+			if err != nil {
+				return calcValue{}, err
+			}
+			r, err := calcFunc(t.text, args)
+			if err != nil {
+				return calcValue{}, err
+			}
+			return calcValue{r: r}, nil
+		}
+		if c, ok := calcConstants[t.text]; ok {
+			return calcValue{r: new(big.Rat).Set(c)}, nil
+		}
+		return calcValue{}, fmt.Errorf("unknown name %q", t.text)
+	default:
+		return calcValue{}, errors.New("unexpected end of expression")
+	}
+}
+
+func (p *calcParser) args() ([]*big.Rat, error) {
+	var args []*big.Rat
+	if t := p.peek(); t.kind == 'o' && t.text == ")" {
+		p.next()
+		return args, nil
+	}
+	for {
+		v, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v.r)
+		switch t := p.next(); {
+		case t.kind == 'o' && t.text == ",":
+		case t.kind == 'o' && t.text == ")":
+			return args, nil
+		default:
+			return nil, errors.New("missing closing parenthesis")
+		}
+	}
+}
+
+var calcConstants = map[string]*big.Rat{
+	"pi": mustCalcRat("3.1415926535897932384626433832795028841971693993751058209749445923078164062862089986280348253421170679"),
+	"π":  mustCalcRat("3.1415926535897932384626433832795028841971693993751058209749445923078164062862089986280348253421170679"),
+	"e":  mustCalcRat("2.7182818284590452353602874713526624977572470936999595749669676277240766303535475945713821785251664274"),
+}
+
+func mustCalcRat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("calc: invalid constant " + s)
+	}
+	return r
+}
+
+func calcFunc(name string, args []*big.Rat) (*big.Rat, error) {
+	arity := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%s takes %d argument(s), got %d", name, n, len(args))
+		}
+		return nil
+	}
+	switch name {
+	case "sqrt":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return calcSqrt(args[0])
+	case "abs":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return new(big.Rat).Abs(args[0]), nil
+	case "floor":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt(calcFloor(args[0])), nil
+	case "ceil":
+		if err := arity(1); err != nil {
+			return nil, err
+		}
+		f := calcFloor(new(big.Rat).Neg(args[0]))
+		return new(big.Rat).SetInt(f.Neg(f)), nil
+	case "round":
+		if len(args) != 1 && len(args) != 2 {
+			return nil, fmt.Errorf("round takes 1 or 2 arguments, got %d", len(args))
+		}
+		digits := int64(0)
+		if len(args) == 2 {
+			if !args[1].IsInt() || !args[1].Num().IsInt64() || args[1].Num().Int64() < 0 || args[1].Num().Int64() > calcMaxPrecision {
+				return nil, fmt.Errorf("round digits must be an integer between 0 and %d", calcMaxPrecision)
+			}
+			digits = args[1].Num().Int64()
+		}
+		return calcRound(args[0], digits), nil
+	case "min", "max":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s takes at least one argument", name)
+		}
+		best := args[0]
+		for _, a := range args[1:] {
+			if c := a.Cmp(best); (name == "min" && c < 0) || (name == "max" && c > 0) {
+				best = a
+			}
+		}
+		return new(big.Rat).Set(best), nil
+	default:
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+}
+
+// calcFloor returns the largest integer not greater than r.
+func calcFloor(r *big.Rat) *big.Int {
+	q, m := new(big.Int), new(big.Int)
+	// Euclidean division by the positive denominator rounds toward -inf.
+	q.DivMod(r.Num(), r.Denom(), m)
+	return q
+}
+
+// calcRound rounds r to digits decimals, halves away from zero.
+func calcRound(r *big.Rat, digits int64) *big.Rat {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(digits), nil))
+	y := new(big.Rat).Mul(new(big.Rat).Abs(r), scale)
+	y.Add(y, big.NewRat(1, 2))
+	out := new(big.Rat).SetInt(calcFloor(y))
+	out.Quo(out, scale)
+	if r.Sign() < 0 {
+		out.Neg(out)
+	}
+	return out
+}
+
+func calcSqrt(r *big.Rat) (*big.Rat, error) {
+	if r.Sign() < 0 {
+		return nil, errors.New("square root of a negative number")
+	}
+	num, den := new(big.Int).Sqrt(r.Num()), new(big.Int).Sqrt(r.Denom())
+	if new(big.Int).Mul(num, num).Cmp(r.Num()) == 0 && new(big.Int).Mul(den, den).Cmp(r.Denom()) == 0 {
+		return new(big.Rat).SetFrac(num, den), nil
+	}
+	f := new(big.Float).SetPrec(calcFloatPrec).SetRat(r)
+	out, _ := f.Sqrt(f).Rat(nil)
+	return out, nil
+}
+
+func calcPow(base, exp *big.Rat) (*big.Rat, error) {
+	if !exp.IsInt() {
+		return nil, errors.New("only integer exponents are supported; use sqrt() for square roots")
+	}
+	if !exp.Num().IsInt64() {
+		return nil, errors.New("exponent too large")
+	}
+	e := exp.Num().Int64()
+	if base.Sign() == 0 {
+		if e < 0 {
+			return nil, errors.New("division by zero")
+		}
+		if e == 0 {
+			return big.NewRat(1, 1), nil
+		}
+		return new(big.Rat), nil
+	}
+	n := e
+	if n < 0 {
+		n = -n
+	}
+	if bits := int64(max(base.Num().BitLen(), base.Denom().BitLen())); n < 0 || bits > 1 && n > calcMaxBits/bits {
+		return nil, errors.New("result too large")
+	}
+	num := new(big.Int).Exp(base.Num(), big.NewInt(n), nil)
+	den := new(big.Int).Exp(base.Denom(), big.NewInt(n), nil)
+	if e < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// convertCalcUnits converts value from one unit to another of the same
+// dimension.
+func convertCalcUnits(value, from, to string, precision int) (map[string]any, error) {
+	if value == "" || from == "" || to == "" {
+		return nil, errors.New("value, from and to are required")
+	}
+	v, err := evalCalcExpression(value)
+	if err != nil {
+		return nil, fmt.Errorf("value: %w", err)
+	}
+	src, err := lookupCalcUnit(from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := lookupCalcUnit(to)
+	if err != nil {
+		return nil, err
+	}
+	if src.dim != dst.dim {
+		return nil, fmt.Errorf("cannot convert %s (%s) to %s (%s)", src.symbol, src.dim, dst.symbol, dst.dim)
+	}
+	base := new(big.Rat).Mul(v, src.factor)
+	base.Add(base, src.offset)
+	out := base.Sub(base, dst.offset)
+	out.Quo(out, dst.factor)
+	return map[string]any{
+		"value": formatCalcRat(out, precision),
+		"unit":  dst.symbol,
+	}, nil
+}
+
+var calcDateAmountRe = regexp.MustCompile(`(\d+)\s*([a-z]+)`)
+
+// dateMath describes date, optionally shifted by add, or the difference
+// between date and until.
+func (h *CalcTools) dateMath(date, add, until, timezone string) (map[string]any, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("timezone: %w", err)
+		}
+	}
+	from, dateOnly, err := h.parseCalcDate(date, loc)
+	if err != nil {
+		return nil, err
+	}
+	if until != "" {
+		to, _, err := h.parseCalcDate(until, loc)
+		if err != nil {
+			return nil, fmt.Errorf("until: %w", err)
+		}
+		diff := to.Sub(from)
+		years, months, days := calcCalendarDiff(from, to)
+		return map[string]any{
+			"days":          diff.Hours() / 24,
+			"weeks":         diff.Hours() / (24 * 7),
+			"hours":         diff.Hours(),
+			"business_days": calcBusinessDays(from, to),
+			"calendar":      map[string]any{"years": years, "months": months, "days": days},
+		}, nil
+	}
+	if add != "" {
+		var subDay bool
+		if from, subDay, err = calcAddToDate(from, add); err != nil {
+			return nil, err
+		}
+		dateOnly = dateOnly && !subDay
+	}
+	formatted := from.Format(time.RFC3339)
+	if dateOnly {
+		formatted = from.Format(time.DateOnly)
+	}
+	year, week := from.ISOWeek()
+	return map[string]any{
+		"date":        formatted,
+		"weekday":     from.Weekday().String(),
+		"iso_week":    fmt.Sprintf("%d-W%02d", year, week),
+		"day_of_year": from.YearDay(),
+	}, nil
+}
+
+func (h *CalcTools) parseCalcDate(s string, loc *time.Location) (time.Time, bool, error) {
+	switch strings.ToLower(s) {
+	case "", "today":
+		y, m, d := h.now().In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), true, nil
+	case "now":
+		return h.now().In(loc), false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q: use YYYY-MM-DD, RFC 3339, today or now", s)
+}
+
+// calcAddToDate shifts t by an amount such as "+1y2mo", "-3 weeks" or
+// "10 business days". subDay reports whether hours, minutes or seconds were
+// added.
+func calcAddToDate(t time.Time, amount string) (out time.Time, subDay bool, err error) {
+	s := strings.ToLower(strings.TrimSpace(amount))
+	sign := 1
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	s = strings.ReplaceAll(s, "business days", "bd")
+	s = strings.ReplaceAll(s, "business day", "bd")
+	matches := calcDateAmountRe.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return time.Time{}, false, fmt.Errorf("invalid amount %q: use e.g. +3d, -2w, 1y6mo, 10 business days", amount)
+	}
+	var years, months, days, businessDays int
+	var dur time.Duration
+	last := 0
+	for _, m := range matches {
+		if strings.Trim(s[last:m[0]], " ,") != "" {
+			return time.Time{}, false, fmt.Errorf("invalid amount %q", amount)
+		}
+		last = m[1]
+		n, err := strconv.Atoi(s[m[2]:m[3]])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid amount %q: %w", amount, err)
+		}
+		switch s[m[4]:m[5]] {
+		case "y", "yr", "yrs", "year", "years":
+			years += n
+		case "mo", "month", "months":
+			months += n
+		case "w", "wk", "wks", "week", "weeks":
+			days += 7 * n
+		case "d", "day", "days":
+			days += n
+		case "bd":
+			businessDays += n
+		case "h", "hr", "hrs", "hour", "hours":
+			dur += time.Duration(n) * time.Hour
+		case "m", "min", "mins", "minute", "minutes":
+			dur += time.Duration(n) * time.Minute
+		case "s", "sec", "secs", "second", "seconds":
+			dur += time.Duration(n) * time.Second
+		default:
+			return time.Time{}, false, fmt.Errorf("invalid amount %q: unknown unit %q", amount, s[m[4]:m[5]])
+		}
+	}
+	if strings.Trim(s[last:], " ,") != "" {
+		return time.Time{}, false, fmt.Errorf("invalid amount %q", amount)
+	}
+	t = t.AddDate(sign*years, sign*months, sign*days)
+	nextBusinessDay := func() {
+		t = t.AddDate(0, 0, sign)
+		for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+			t = t.AddDate(0, 0, sign)
+		}
+	}
+	if businessDays > 0 {
+		// After the first step t is a weekday, so every further five
+		// business days are exactly one calendar week.
+		nextBusinessDay()
+		businessDays--
+		t = t.AddDate(0, 0, sign*7*(businessDays/5))
+		for i := 0; i < businessDays%5; i++ {
+			nextBusinessDay()
+		}
+	}
+	return t.Add(time.Duration(sign) * dur), dur != 0, nil
+}
+
+// calcBusinessDays counts Monday to Friday days from from (inclusive) to to
+// (exclusive); negative when to is before from.
+func calcBusinessDays(from, to time.Time) int {
+	sign := 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	total := int(end.Sub(start).Hours() / 24)
+	count := total / 7 * 5
+	for d := start.AddDate(0, 0, total/7*7); d.Before(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			count++
+		}
+	}
+	return sign * count
+}
+
+// calcCalendarDiff splits the span between from and to into whole years,
+// months and days; all are negative when to is before from.
+func calcCalendarDiff(from, to time.Time) (years, months, days int) {
+	sign := 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+	total := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if from.AddDate(0, total, 0).After(to) {
+		total--
+	}
+	days = int(to.Sub(from.AddDate(0, total, 0)).Hours() / 24)
+	return sign * (total / 12), sign * (total % 12), sign * days
+}
+
+// Supports implements taskengine.ToolsRegistry.
+func (h *CalcTools) Supports(ctx context.Context) ([]string, error) {
+	return []string{calcToolsName, "calculate", "convert_units", "date_math"}, nil
+}
+
+// GetSchemasForSupportedTools implements taskengine.ToolsWithSchema.
+func (h *CalcTools) GetSchemasForSupportedTools(ctx context.Context) (map[string]*openapi3.T, error) {
+	return map[string]*openapi3.T{}, nil
+}
+
+// GetToolsForToolsByName implements taskengine.ToolsWithSchema. "calc"
+// returns all three tools.
+func (h *CalcTools) GetToolsForToolsByName(ctx context.Context, name string) ([]taskengine.Tool, error) {
+	precision := map[string]interface{}{
+		"type":        "integer",
+		"description": fmt.Sprintf("Decimals shown for results without a finite decimal expansion (default %d, max %d)", calcDefaultPrecision, calcMaxPrecision),
+	}
+	allTools := []taskengine.Tool{
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name: "calculate",
+				Description: "Evaluate an arithmetic expression exactly. Use it instead of doing arithmetic yourself. Supports + - * / ^ mod, " +
+					"parentheses, percentages (\"15% of 80\", \"200 + 10%\"), pi, e and sqrt, abs, floor, ceil, round(x, digits), min, max.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"expression": map[string]interface{}{"type": "string", "description": "Expression, e.g. (1250 * 1.19 - 3%) / 12"},
+						"precision":  precision,
+					},
+					"required": []string{"expression"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name:        "convert_units",
+				Description: "Convert a value between units of the same dimension. Units: " + calcUnitSummary() + ".",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"value":     map[string]interface{}{"type": "string", "description": "Value or expression, e.g. 3.5 or 5/8"},
+						"from":      map[string]interface{}{"type": "string", "description": "Source unit, e.g. mi"},
+						"to":        map[string]interface{}{"type": "string", "description": "Target unit, e.g. km"},
+						"precision": precision,
+					},
+					"required": []string{"value", "from", "to"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name: "date_math",
+				Description: "Date arithmetic. With add, shift the date (e.g. +3d, -2w, 1y6mo, 10 business days, 36h). " +
+					"With until, return the difference in days, weeks, hours, business days and calendar years/months/days. " +
+					"Otherwise describe the date (weekday, ISO week, day of year).",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"date":     map[string]interface{}{"type": "string", "description": "YYYY-MM-DD, RFC 3339, today or now (default today)"},
+						"add":      map[string]interface{}{"type": "string", "description": "Amount to add; prefix with - to subtract"},
+						"until":    map[string]interface{}{"type": "string", "description": "Second date to compute the difference to"},
+						"timezone": map[string]interface{}{"type": "string", "description": "IANA time zone, e.g. Europe/Berlin (default UTC)"},
+					},
+				},
+			},
+		},
+	}
+
+	if name == calcToolsName {
+		return allTools, nil
+	}
+	for _, t := range allTools {
+		if t.Function.Name == name {
+			return []taskengine.Tool{t}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown tools: %s", name)
+}
+
+var _ taskengine.ToolsRepo = (*CalcTools)(nil)
@@ -0,0 +1,154 @@
+package localtools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCalc(t *testing.T, h *CalcTools, tool string, input any) (any, error) {
+	t.Helper()
+	out, _, err := h.Exec(context.Background(), time.Now(), input, false, &taskengine.ToolsCall{Name: "calc", ToolName: tool})
+	return out, err
+}
+
+func TestCalc_Calculate(t *testing.T) {
+	h := NewCalcTools().(*CalcTools)
+	cases := map[string]string{
+		"0.1 + 0.2":                     "0.3",
+		"2^100":                         "1267650600228229401496703205376",
+		"-2^2":                          "-4",
+		"2**-2":                         "0.25",
+		"1/3":                           "0.33333333333333333333",
+		"(1 + 2) * 3 - 4 / 8":           "8.5",
+		"15% of 80":                     "12",
+		"200 + 10%":                     "220",
+		"200 - 10% - 10%":               "162",
+		"10 % 3":                        "1",
+		"-7 mod 3":                      "2",
+		"sqrt(16/9)":                    "1.33333333333333333333",
+		"sqrt(2)":                       "1.4142135623730950488",
+		"round(2.345, 2) + floor(-1.5)": "0.35",
+		"max(1, 3, 2) × ceil(1.2)":      "6",
+		"1_000_000 * 1.5e3":             "1500000000",
+		"round(pi, 4)":                  "3.1416",
+	}
+	for expr, want := range cases {
+		out, err := runCalc(t, h, "calculate", map[string]any{"expression": expr})
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, out, expr)
+	}
+
+	// Chain tasks may pass the expression as input or as tools args.
+	out, _, err := h.Exec(context.Background(), time.Now(), "7 * 6", false, &taskengine.ToolsCall{Name: "calc"})
+	require.NoError(t, err)
+	assert.Equal(t, "42", out)
+	out, _, err = h.Exec(context.Background(), time.Now(), nil, false, &taskengine.ToolsCall{Name: "calc", ToolName: "calculate", Args: map[string]string{"expression": "22/7", "precision": "3"}})
+	require.NoError(t, err)
+	assert.Equal(t, "3.143", out)
+
+	for expr, msg := range map[string]string{
+		"1/0":      "division by zero",
+		"2^0.5":    "integer exponents",
+		"10^10^10": "too large",
+		// bits*n used to overflow int64 and slip past the size check.
+		"3^9223372036854775807":  "too large",
+		"3^-9223372036854775808": "too large",
+		"(1 + 2":                 "missing closing parenthesis",
+		"foo(1)":                 "unknown function",
+		"1 +":                    "unexpected end",
+		"sqrt(-1)":               "negative",
+		"":                       "expression is required",
+		"2 $ 3":                  "unexpected character",
+	} {
+		_, err := runCalc(t, h, "calculate", map[string]any{"expression": expr})
+		require.ErrorContains(t, err, msg, expr)
+	}
+}
+
+func TestCalc_ConvertUnits(t *testing.T) {
+	h := NewCalcTools().(*CalcTools)
+	cases := []struct {
+		value    any
+		from, to string
+		want     string
+	}{
+		{float64(1), "mi", "km", "1.609344"},
+		{"100", "C", "F", "212"},
+		{"-40", "fahrenheit", "celsius", "-40"},
+		{"0", "K", "C", "-273.15"},
+		{"1", "GiB", "MB", "1073.741824"},
+		{"5/8", "in", "mm", "15.875"},
+		{"1", "lb", "oz", "16"},
+		{"60", "mph", "km/h", "96.56064"},
+		{"2", "fl oz", "ml", "59.147059125"},
+	}
+	for _, c := range cases {
+		out, err := runCalc(t, h, "convert_units", map[string]any{"value": c.value, "from": c.from, "to": c.to})
+		require.NoError(t, err, c.from)
+		assert.Equal(t, c.want, out.(map[string]any)["value"], "%v %s to %s", c.value, c.from, c.to)
+	}
+
+	_, err := runCalc(t, h, "convert_units", map[string]any{"value": "1", "from": "kg", "to": "m"})
+	require.ErrorContains(t, err, "cannot convert kg (mass) to m (length)")
+	_, err = runCalc(t, h, "convert_units", map[string]any{"value": "1", "from": "mb", "to": "B"})
+	require.ErrorContains(t, err, `unknown unit "mb"`, "MB and Mb differ only in case")
+}
+
+func TestCalc_DateMath(t *testing.T) {
+	h := &CalcTools{now: func() time.Time { return time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC) }}
+
+	out, err := runCalc(t, h, "date_math", map[string]any{"date": "2024-01-31", "add": "+1mo"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-02", out.(map[string]any)["date"])
+
+	out, err = runCalc(t, h, "date_math", map[string]any{"add": "3 business days"})
+	require.NoError(t, err, "today is a Friday")
+	assert.Equal(t, "2026-10-21", out.(map[string]any)["date"])
+	assert.Equal(t, "Wednesday", out.(map[string]any)["weekday"])
+
+	out, err = runCalc(t, h, "date_math", map[string]any{"date": "2026-10-17", "add": "5 business days"})
+	require.NoError(t, err, "from a Saturday")
+	assert.Equal(t, "2026-10-23", out.(map[string]any)["date"])
+
+	out, err = runCalc(t, h, "date_math", map[string]any{"add": "1000000 business days"})
+	require.NoError(t, err)
+	assert.Equal(t, "5859-11-11", out.(map[string]any)["date"])
+
+	out, err = runCalc(t, h, "date_math", map[string]any{"date": "now", "add": "-1d 2h"})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-15T13:30:00Z", out.(map[string]any)["date"])
+
+	out, err = runCalc(t, h, "date_math", map[string]any{"date": "2026-01-01", "until": "2027-03-15"})
+	require.NoError(t, err)
+	diff := out.(map[string]any)
+	assert.Equal(t, float64(438), diff["days"])
+	assert.Equal(t, 312, diff["business_days"])
+	assert.Equal(t, map[string]any{"years": 1, "months": 2, "days": 14}, diff["calendar"])
+
+	out, err = runCalc(t, h, "date_math", map[string]any{"date": "2026-12-31"})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-W53", out.(map[string]any)["iso_week"])
+	assert.Equal(t, 365, out.(map[string]any)["day_of_year"])
+
+	_, err = runCalc(t, h, "date_math", map[string]any{"date": "2026-01-01", "add": "3 fortnights"})
+	require.ErrorContains(t, err, "unknown unit")
+	_, err = runCalc(t, h, "date_math", map[string]any{"date": "01/02/2026"})
+	require.ErrorContains(t, err, "invalid date")
+}
+
+func TestCalc_Tools(t *testing.T) {
+	h := NewCalcTools()
+	tools, err := h.GetToolsForToolsByName(context.Background(), "calc")
+	require.NoError(t, err)
+	require.Len(t, tools, 3)
+	assert.Contains(t, tools[1].Function.Description, "temperature (K, C, F)")
+
+	tools, err = h.GetToolsForToolsByName(context.Background(), "date_math")
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+}
@@ -0,0 +1,174 @@
+package localtools
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// calcUnit converts to the base unit of its dimension as
+// base = value*factor + offset.
+type calcUnit struct {
+	dim    string
+	symbol string
+	factor *big.Rat
+	offset *big.Rat
+}
+
+// calcUnitDefs lists the units per dimension; the first name is the symbol
+// reported in results. Factors are exact expressions in the base unit.
+var calcUnitDefs = []struct {
+	dim    string
+	names  []string
+	factor string
+	offset string
+}{
+	{"length", []string{"m", "meter", "meters", "metre", "metres"}, "1", ""},
+	{"length", []string{"km", "kilometer", "kilometers", "kilometre", "kilometres"}, "1000", ""},
+	{"length", []string{"cm", "centimeter", "centimeters"}, "1/100", ""},
+	{"length", []string{"mm", "millimeter", "millimeters"}, "1/1000", ""},
+	{"length", []string{"um", "µm", "micrometer", "micrometers"}, "1e-6", ""},
+	{"length", []string{"nm", "nanometer", "nanometers"}, "1e-9", ""},
+	{"length", []string{"in", "inch", "inches"}, "0.0254", ""},
+	{"length", []string{"ft", "foot", "feet"}, "0.3048", ""},
+	{"length", []string{"yd", "yard", "yards"}, "0.9144", ""},
+	{"length", []string{"mi", "mile", "miles"}, "1609.344", ""},
+	{"length", []string{"nmi", "nautical mile", "nautical miles"}, "1852", ""},
+
+	{"mass", []string{"kg", "kilogram", "kilograms"}, "1", ""},
+	{"mass", []string{"g", "gram", "grams"}, "1/1000", ""},
+	{"mass", []string{"mg", "milligram", "milligrams"}, "1e-6", ""},
+	{"mass", []string{"t", "tonne", "tonnes"}, "1000", ""},
+	{"mass", []string{"lb", "lbs", "pound", "pounds"}, "0.45359237", ""},
+	{"mass", []string{"oz", "ounce", "ounces"}, "0.45359237/16", ""},
+	{"mass", []string{"st", "stone"}, "0.45359237*14", ""},
+
+	{"time", []string{"s", "sec", "second", "seconds"}, "1", ""},
+	{"time", []string{"ms", "millisecond", "milliseconds"}, "1/1000", ""},
+	{"time", []string{"us", "µs", "microsecond", "microseconds"}, "1e-6", ""},
+	{"time", []string{"ns", "nanosecond", "nanoseconds"}, "1e-9", ""},
+	{"time", []string{"min", "minute", "minutes"}, "60", ""},
+	{"time", []string{"h", "hr", "hour", "hours"}, "3600", ""},
+	{"time", []string{"d", "day", "days"}, "86400", ""},
+	{"time", []string{"wk", "week", "weeks"}, "604800", ""},
+
+	{"volume", []string{"l", "L", "liter", "liters", "litre", "litres"}, "1/1000", ""},
+	{"volume", []string{"ml", "mL", "milliliter", "milliliters", "millilitre", "millilitres"}, "1e-6", ""},
+	{"volume", []string{"m3", "m³", "cubic meter", "cubic meters"}, "1", ""},
+	{"volume", []string{"gal", "gallon", "gallons"}, "0.003785411784", ""},
+	{"volume", []string{"qt", "quart", "quarts"}, "0.003785411784/4", ""},
+	{"volume", []string{"pt", "pint", "pints"}, "0.003785411784/8", ""},
+	{"volume", []string{"cup", "cups"}, "0.003785411784/16", ""},
+	{"volume", []string{"floz", "fl oz", "fluid ounce", "fluid ounces"}, "0.003785411784/128", ""},
+	{"volume", []string{"tbsp", "tablespoon", "tablespoons"}, "0.003785411784/256", ""},
+	{"volume", []string{"tsp", "teaspoon", "teaspoons"}, "0.003785411784/768", ""},
+
+	{"area", []string{"m2", "m²", "square meter", "square meters"}, "1", ""},
+	{"area", []string{"km2", "km²", "square kilometer", "square kilometers"}, "1e6", ""},
+	{"area", []string{"cm2", "cm²"}, "1e-4", ""},
+	{"area", []string{"ha", "hectare", "hectares"}, "1e4", ""},
+	{"area", []string{"acre", "acres"}, "4046.8564224", ""},
+	{"area", []string{"ft2", "ft²", "square foot", "square feet"}, "0.09290304", ""},
+	{"area", []string{"mi2", "mi²", "square mile", "square miles"}, "2589988.110336", ""},
+
+	{"speed", []string{"m/s", "mps"}, "1", ""},
+	{"speed", []string{"km/h", "kph", "kmh"}, "1000/3600", ""},
+	{"speed", []string{"mph"}, "1609.344/3600", ""},
+	{"speed", []string{"kn", "knot", "knots"}, "1852/3600", ""},
+	{"speed", []string{"ft/s", "fps"}, "0.3048", ""},
+
+	{"temperature", []string{"K", "kelvin"}, "1", ""},
+	{"temperature", []string{"C", "°C", "celsius"}, "1", "273.15"},
+	{"temperature", []string{"F", "°F", "fahrenheit"}, "5/9", "459.67*5/9"},
+
+	{"data", []string{"B", "byte", "bytes"}, "1", ""},
+	{"data", []string{"bit", "bits"}, "1/8", ""},
+	{"data", []string{"kB", "KB", "kilobyte", "kilobytes"}, "1e3", ""},
+	{"data", []string{"MB", "megabyte", "megabytes"}, "1e6", ""},
+	{"data", []string{"GB", "gigabyte", "gigabytes"}, "1e9", ""},
+	{"data", []string{"TB", "terabyte", "terabytes"}, "1e12", ""},
+	{"data", []string{"PB", "petabyte", "petabytes"}, "1e15", ""},
+	{"data", []string{"KiB", "kibibyte", "kibibytes"}, "2^10", ""},
+	{"data", []string{"MiB", "mebibyte", "mebibytes"}, "2^20", ""},
+	{"data", []string{"GiB", "gibibyte", "gibibytes"}, "2^30", ""},
+	{"data", []string{"TiB", "tebibyte", "tebibytes"}, "2^40", ""},
+	{"data", []string{"kbit", "kb", "kilobit", "kilobits"}, "1e3/8", ""},
+	{"data", []string{"Mbit", "Mb", "megabit", "megabits"}, "1e6/8", ""},
+	{"data", []string{"Gbit", "Gb", "gigabit", "gigabits"}, "1e9/8", ""},
+
+	{"energy", []string{"J", "joule", "joules"}, "1", ""},
+	{"energy", []string{"kJ", "kilojoule", "kilojoules"}, "1000", ""},
+	{"energy", []string{"cal", "calorie", "calories"}, "4.184", ""},
+	{"energy", []string{"kcal", "kilocalorie", "kilocalories"}, "4184", ""},
+	{"energy", []string{"Wh", "watt hour", "watt hours"}, "3600", ""},
+	{"energy", []string{"kWh", "kilowatt hour", "kilowatt hours"}, "3.6e6", ""},
+
+	{"pressure", []string{"Pa", "pascal", "pascals"}, "1", ""},
+	{"pressure", []string{"kPa", "kilopascal", "kilopascals"}, "1000", ""},
+	{"pressure", []string{"bar"}, "1e5", ""},
+	{"pressure", []string{"atm", "atmosphere", "atmospheres"}, "101325", ""},
+	{"pressure", []string{"psi"}, "0.45359237*9.80665/0.0254^2", ""},
+}
+
+// calcUnits maps exact unit names to units; calcUnitsFolded maps
+// lower-cased names that are unambiguous ignoring case.
+var calcUnits, calcUnitsFolded = buildCalcUnits()
+
+func buildCalcUnits() (map[string]*calcUnit, map[string]*calcUnit) {
+	exact := map[string]*calcUnit{}
+	folded := map[string]*calcUnit{}
+	ambiguous := map[string]bool{}
+	for _, def := range calcUnitDefs {
+		factor, err := evalCalcExpression(def.factor)
+		if err != nil {
+			panic(fmt.Sprintf("calc: unit %s: %v", def.names[0], err))
+		}
+		offset := new(big.Rat)
+		if def.offset != "" {
+			if offset, err = evalCalcExpression(def.offset); err != nil {
+				panic(fmt.Sprintf("calc: unit %s: %v", def.names[0], err))
+			}
+		}
+		u := &calcUnit{dim: def.dim, symbol: def.names[0], factor: factor, offset: offset}
+		for _, name := range def.names {
+			exact[name] = u
+			key := strings.ToLower(name)
+			if prev, ok := folded[key]; ok && prev != u {
+				ambiguous[key] = true
+			}
+			folded[key] = u
+		}
+	}
+	for key := range ambiguous {
+		delete(folded, key)
+	}
+	return exact, folded
+}
+
+func lookupCalcUnit(name string) (*calcUnit, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if u, ok := calcUnits[name]; ok {
+		return u, nil
+	}
+	if u, ok := calcUnitsFolded[strings.ToLower(name)]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("unknown unit %q", name)
+}
+
+// calcUnitSummary lists the unit symbols per dimension for tool descriptions.
+func calcUnitSummary() string {
+	var parts []string
+	var symbols []string
+	dim := ""
+	for _, def := range calcUnitDefs {
+		if def.dim != dim && dim != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", dim, strings.Join(symbols, ", ")))
+			symbols = nil
+		}
+		dim = def.dim
+		symbols = append(symbols, def.names[0])
+	}
+	parts = append(parts, fmt.Sprintf("%s (%s)", dim, strings.Join(symbols, ", ")))
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,302 @@
+package localtools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const desktopToolsName = "desktop"
+
+// desktopCommandTimeout bounds every clipboard/notification helper invocation.
+const desktopCommandTimeout = 5 * time.Second
+
+// DesktopRunner runs a desktop helper binary, feeding stdin when non-empty.
+type DesktopRunner func(ctx context.Context, stdin string, name string, args ...string) error
+
+// DesktopTools integrates chains with the user's desktop: it copies results to
+// the system clipboard and raises OS notifications, so long-running chains and
+// plan executions can be left unattended.
+//
+// It shells out to the platform helpers (pbcopy/osascript on macOS,
+// wl-copy/xclip/xsel and notify-send on Linux, clip on Windows).
+type DesktopTools struct {
+	goos     string
+	lookPath func(file string) (string, error)
+	run      DesktopRunner
+}
+
+// DesktopOption configures DesktopTools.
+type DesktopOption func(*DesktopTools)
+
+// WithDesktopRunner replaces the function used to invoke helper binaries.
+func WithDesktopRunner(run DesktopRunner) DesktopOption {
+	return func(h *DesktopTools) {
+		h.run = run
+	}
+}
+
+// WithDesktopPlatform overrides the detected OS and the helper lookup.
+func WithDesktopPlatform(goos string, lookPath func(file string) (string, error)) DesktopOption {
+	return func(h *DesktopTools) {
+		h.goos = goos
+		h.lookPath = lookPath
+	}
+}
+
+// NewDesktopTools creates a new DesktopTools for the current platform.
+func NewDesktopTools(opts ...DesktopOption) taskengine.ToolsRepo {
+	h := &DesktopTools{
+		goos:     runtime.GOOS,
+		lookPath: exec.LookPath,
+		run:      runDesktopCommand,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Exec implements taskengine.ToolsRepo.
+//
+// From a chain (handler "tools"), arguments come from tools.Args and the task
+// input is used as the text when none is given; the input is returned unchanged
+// so the desktop step can be placed anywhere in a chain.
+// From an LLM tool call, arguments come from the input map and a short
+// confirmation is returned.
+func (h *DesktopTools) Exec(ctx context.Context, startTime time.Time, input any, debug bool, toolsCall *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	if toolsCall == nil {
+		return nil, taskengine.DataTypeAny, errors.New("desktop: tools required")
+	}
+	toolName := toolsCall.ToolName
+	if toolName == "" || toolName == desktopToolsName {
+		toolName = toolsCall.Args["action"]
+	}
+
+	args := map[string]string{}
+	for k, v := range toolsCall.Args {
+		args[k] = v
+	}
+	fromToolCall := false
+	if m, ok := input.(map[string]any); ok {
+		fromToolCall = true
+		for k, v := range m {
+			if s, ok := v.(string); ok {
+				args[k] = s
+			}
+		}
+	}
+
+	var (
+		confirmation string
+		err          error
+	)
+	switch toolName {
+	case "copy_to_clipboard":
+		text := args["text"]
+		if text == "" {
+			text = desktopTextFromInput(input)
+		}
+		err = h.copyToClipboard(ctx, text)
+		confirmation = fmt.Sprintf("copied %d characters to the clipboard", len(text))
+	case "notify":
+		message := args["message"]
+		if message == "" {
+			message = desktopTextFromInput(input)
+		}
+		title := args["title"]
+		if title == "" {
+			title = "contenox"
+		}
+		err = h.notify(ctx, title, message)
+		confirmation = "notification sent"
+	default:
+		return nil, taskengine.DataTypeAny, fmt.Errorf("desktop: unknown tool %q", toolName)
+	}
+	if err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+
+	if fromToolCall {
+		return confirmation, taskengine.DataTypeString, nil
+	}
+	switch v := input.(type) {
+	case string:
+		return v, taskengine.DataTypeString, nil
+	case taskengine.ChatHistory:
+		return v, taskengine.DataTypeChatHistory, nil
+	default:
+		return input, taskengine.InferDataType(input), nil
+	}
+}
+
+// desktopTextFromInput extracts the text to hand to the desktop from a chain input.
+func desktopTextFromInput(input any) string {
+	switch v := input.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case taskengine.ChatHistory:
+		for i := len(v.Messages) - 1; i >= 0; i-- {
+			if v.Messages[i].Role == "assistant" && v.Messages[i].Content != "" {
+				return v.Messages[i].Content
+			}
+		}
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (h *DesktopTools) copyToClipboard(ctx context.Context, text string) error {
+	if text == "" {
+		return errors.New("desktop: nothing to copy")
+	}
+	var candidates [][]string
+	switch h.goos {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	cmd, err := h.firstAvailable(candidates)
+	if err != nil {
+		return fmt.Errorf("desktop: no clipboard helper found: %w", err)
+	}
+	if err := h.run(ctx, text, cmd[0], cmd[1:]...); err != nil {
+		return fmt.Errorf("desktop: copy to clipboard: %w", err)
+	}
+	return nil
+}
+
+func (h *DesktopTools) notify(ctx context.Context, title, message string) error {
+	if message == "" {
+		return errors.New("desktop: notification message is empty")
+	}
+	var cmd []string
+	switch h.goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(message), appleScriptQuote(title))
+		cmd = []string{"osascript", "-e", script}
+	case "windows":
+		return errors.New("desktop: notifications are not supported on windows")
+	default:
+		cmd = []string{"notify-send", "--app-name=contenox", title, message}
+	}
+	if _, err := h.lookPath(cmd[0]); err != nil {
+		return fmt.Errorf("desktop: notification helper %s not found: %w", cmd[0], err)
+	}
+	if err := h.run(ctx, "", cmd[0], cmd[1:]...); err != nil {
+		return fmt.Errorf("desktop: notify: %w", err)
+	}
+	return nil
+}
+
+// firstAvailable returns the first candidate command whose binary is on PATH.
+func (h *DesktopTools) firstAvailable(candidates [][]string) ([]string, error) {
+	var names []string
+	for _, c := range candidates {
+		if _, err := h.lookPath(c[0]); err == nil {
+			return c, nil
+		}
+		names = append(names, c[0])
+	}
+	return nil, fmt.Errorf("tried %s", strings.Join(names, ", "))
+}
+
+// appleScriptQuote renders s as an AppleScript string literal.
+func appleScriptQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func runDesktopCommand(ctx context.Context, stdin string, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, desktopCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func (h *DesktopTools) Supports(ctx context.Context) ([]string, error) {
+	return []string{desktopToolsName, "copy_to_clipboard", "notify"}, nil
+}
+
+func (h *DesktopTools) GetSchemasForSupportedTools(ctx context.Context) (map[string]*openapi3.T, error) {
+	return map[string]*openapi3.T{}, nil
+}
+
+func (h *DesktopTools) GetToolsForToolsByName(ctx context.Context, name string) ([]taskengine.Tool, error) {
+	allTools := []taskengine.Tool{
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name:        "copy_to_clipboard",
+				Description: "Copy text to the user's system clipboard.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"text": map[string]interface{}{"type": "string", "description": "Text to copy"},
+					},
+					"required": []string{"text"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name:        "notify",
+				Description: "Show a desktop notification to the user, e.g. when a long-running task has finished.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"title":   map[string]interface{}{"type": "string", "description": "Notification title (default: contenox)"},
+						"message": map[string]interface{}{"type": "string", "description": "Notification body"},
+					},
+					"required": []string{"message"},
+				},
+			},
+		},
+	}
+
+	if name == desktopToolsName {
+		return allTools, nil
+	}
+	for _, t := range allTools {
+		if t.Function.Name == name {
+			return []taskengine.Tool{t}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown tools tool: %s", name)
+}
+
+var _ taskengine.ToolsRepo = (*DesktopTools)(nil)
@@ -0,0 +1,102 @@
+package localtools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type desktopCall struct {
+	stdin string
+	name  string
+	args  []string
+}
+
+func newTestDesktopTools(goos string, available ...string) (*DesktopTools, *[]desktopCall) {
+	var calls []desktopCall
+	lookPath := func(file string) (string, error) {
+		for _, a := range available {
+			if a == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	h := NewDesktopTools(
+		WithDesktopPlatform(goos, lookPath),
+		WithDesktopRunner(func(_ context.Context, stdin string, name string, args ...string) error {
+			calls = append(calls, desktopCall{stdin: stdin, name: name, args: args})
+			return nil
+		}),
+	).(*DesktopTools)
+	return h, &calls
+}
+
+func TestDesktopTools_CopyPassesInputThrough(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	h, calls := newTestDesktopTools("linux", "xsel")
+
+	out, dt, err := h.Exec(context.Background(), time.Now(), "final answer", false, &taskengine.ToolsCall{
+		Name:     "desktop",
+		ToolName: "copy_to_clipboard",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "final answer", out)
+	assert.Equal(t, taskengine.DataTypeString, dt)
+
+	require.Len(t, *calls, 1)
+	assert.Equal(t, "xsel", (*calls)[0].name)
+	assert.Equal(t, "final answer", (*calls)[0].stdin)
+}
+
+func TestDesktopTools_NotifyFromChatHistory(t *testing.T) {
+	h, calls := newTestDesktopTools("darwin", "osascript")
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "run the plan"},
+		{Role: "assistant", Content: `step "3" done`},
+	}}
+
+	out, dt, err := h.Exec(context.Background(), time.Now(), history, false, &taskengine.ToolsCall{
+		Name: "desktop",
+		Args: map[string]string{"action": "notify", "title": "plan"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, history, out)
+	assert.Equal(t, taskengine.DataTypeChatHistory, dt)
+
+	require.Len(t, *calls, 1)
+	assert.Equal(t, "osascript", (*calls)[0].name)
+	assert.Equal(t, []string{"-e", `display notification "step \"3\" done" with title "plan"`}, (*calls)[0].args)
+}
+
+func TestDesktopTools_ToolCallReturnsConfirmation(t *testing.T) {
+	h, calls := newTestDesktopTools("linux", "notify-send")
+
+	out, dt, err := h.Exec(context.Background(), time.Now(), map[string]any{"message": "done"}, false, &taskengine.ToolsCall{
+		Name:     "desktop",
+		ToolName: "notify",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "notification sent", out)
+	assert.Equal(t, taskengine.DataTypeString, dt)
+	require.Len(t, *calls, 1)
+	assert.Equal(t, []string{"--app-name=contenox", "contenox", "done"}, (*calls)[0].args)
+}
+
+func TestDesktopTools_MissingHelper(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	h, calls := newTestDesktopTools("linux")
+
+	_, _, err := h.Exec(context.Background(), time.Now(), "x", false, &taskengine.ToolsCall{
+		Name:     "desktop",
+		ToolName: "copy_to_clipboard",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no clipboard helper found")
+	assert.Empty(t, *calls)
+}
@@ -0,0 +1,279 @@
+package localtools
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const pythonSandboxToolsName = "python_sandbox"
+
+// pythonSandboxPrelude runs before the snippet. It applies the resource limits,
+// installs an audit hook that refuses file access outside the workspace and
+// the Python installation as well as process creation, then executes the
+// snippet with the decoded input bound to `input`.
+//
+//go:embed pysandbox_prelude.py
+var pythonSandboxPrelude string
+
+// PythonSandboxTools executes short Python snippets in a separate process.
+//
+// The process runs in its own Linux user, network, PID and IPC namespaces, so
+// the kernel denies it any network access and any view of other processes,
+// with rlimits on CPU time, memory and file size. A fresh temporary directory
+// is its working directory. File access elsewhere is only refused by a Python
+// audit hook, which catches careless snippets but not a deliberate escape, so
+// the snippet can still read files the user can read. The tools are therefore
+// opt-in (contenox --python) and meant for trusted chains.
+type PythonSandboxTools struct {
+	interpreter    string
+	timeout        time.Duration
+	memoryLimit    int64 // bytes of address space
+	maxOutputBytes int
+}
+
+// PythonSandboxOption configures PythonSandboxTools.
+type PythonSandboxOption func(*PythonSandboxTools)
+
+// WithPythonInterpreter sets the Python executable (default python3 from PATH).
+func WithPythonInterpreter(path string) PythonSandboxOption {
+	return func(h *PythonSandboxTools) {
+		h.interpreter = path
+	}
+}
+
+// WithPythonTimeout sets the maximum wall-clock time of a snippet. Calls may
+// request a shorter timeout but never a longer one.
+func WithPythonTimeout(d time.Duration) PythonSandboxOption {
+	return func(h *PythonSandboxTools) {
+		h.timeout = d
+	}
+}
+
+// WithPythonMemoryLimit sets the address space limit of the interpreter in bytes.
+func WithPythonMemoryLimit(bytes int64) PythonSandboxOption {
+	return func(h *PythonSandboxTools) {
+		h.memoryLimit = bytes
+	}
+}
+
+// NewPythonSandboxTools creates the python_sandbox tools. It fails when no
+// Python 3 interpreter is available or the process cannot be isolated.
+func NewPythonSandboxTools(opts ...PythonSandboxOption) (taskengine.ToolsRepo, error) {
+	h := &PythonSandboxTools{
+		interpreter:    "python3",
+		timeout:        30 * time.Second,
+		memoryLimit:    512 << 20,
+		maxOutputBytes: 1 << 20,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	path, err := exec.LookPath(h.interpreter)
+	if err != nil {
+		return nil, fmt.Errorf("python_sandbox: interpreter %q not found: %w", h.interpreter, err)
+	}
+	h.interpreter = path
+	if err := checkIsolation(h.interpreter); err != nil {
+		return nil, fmt.Errorf("python_sandbox: cannot isolate the interpreter: %w", err)
+	}
+	return h, nil
+}
+
+// Exec implements taskengine.ToolsRepo.
+// The snippet comes from tools.Args["code"] or, for execute_tool_calls, from
+// the "code" field of the input. The task input (or the "input" field of a
+// tool call) is available to the snippet as the variable `input`. When the
+// snippet assigns `result`, its JSON value is returned; otherwise stdout is.
+func (h *PythonSandboxTools) Exec(ctx context.Context, startTime time.Time, input any, debug bool, tools *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	code, data, timeout, err := h.parseArgs(tools, input)
+	if err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+	stdin, err := json.Marshal(map[string]any{"code": code, "input": data})
+	if err != nil {
+		return nil, taskengine.DataTypeAny, fmt.Errorf("python_sandbox: encode input: %w", err)
+	}
+
+	workspace, err := os.MkdirTemp("", "contenox-python-")
+	if err != nil {
+		return nil, taskengine.DataTypeAny, fmt.Errorf("python_sandbox: create workspace: %w", err)
+	}
+	defer os.RemoveAll(workspace)
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, h.interpreter, "-I", "-c", pythonSandboxPrelude)
+	cmd.Dir = workspace
+	// A minimal environment: no proxies, credentials or PYTHON* overrides leak in.
+	cmd.Env = []string{
+		"HOME=" + workspace,
+		"TMPDIR=" + workspace,
+		"PYTHONIOENCODING=utf-8",
+		"PYTHONDONTWRITEBYTECODE=1",
+		"CONTENOX_SANDBOX_CPU_SECONDS=" + strconv.Itoa(int(timeout.Seconds())+1),
+		"CONTENOX_SANDBOX_MEMORY_BYTES=" + strconv.FormatInt(h.memoryLimit, 10),
+	}
+	isolate(cmd)
+	cmd.Stdin = bytes.NewReader(stdin)
+	stdout := &cappedBuffer{limit: h.maxOutputBytes}
+	stderr := &cappedBuffer{limit: h.maxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	runErr := cmd.Run()
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return nil, taskengine.DataTypeAny, fmt.Errorf("python_sandbox: timed out after %s", timeout)
+	}
+	if runErr != nil {
+		return nil, taskengine.DataTypeAny, fmt.Errorf("python_sandbox: %s", lastLine(stderr.String(), runErr.Error()))
+	}
+	if stdout.truncated {
+		return nil, taskengine.DataTypeAny, fmt.Errorf("python_sandbox: output exceeds %d bytes", h.maxOutputBytes)
+	}
+
+	if raw, err := os.ReadFile(filepath.Join(workspace, ".contenox_result.json")); err == nil {
+		var result any
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return nil, taskengine.DataTypeAny, fmt.Errorf("python_sandbox: decode result: %w", err)
+		}
+		if s, ok := result.(string); ok {
+			return s, taskengine.DataTypeString, nil
+		}
+		return result, taskengine.DataTypeJSON, nil
+	}
+	return strings.TrimRight(stdout.String(), "\n"), taskengine.DataTypeString, nil
+}
+
+func (h *PythonSandboxTools) parseArgs(tools *taskengine.ToolsCall, input any) (code string, data any, timeout time.Duration, err error) {
+	timeout = h.timeout
+	data = input
+	if tools != nil {
+		code = tools.Args["code"]
+		if t := tools.Args["timeout"]; t != "" {
+			if d, e := time.ParseDuration(t); e == nil && d > 0 && d < timeout {
+				timeout = d
+			}
+		}
+	}
+	if v, ok := input.(map[string]any); ok && code == "" {
+		// Tool call from execute_tool_calls: {"code": "...", "input": ...}.
+		code, _ = v["code"].(string)
+		data = v["input"]
+		if t, ok := v["timeout"].(string); ok {
+			if d, e := time.ParseDuration(t); e == nil && d > 0 && d < timeout {
+				timeout = d
+			}
+		}
+	}
+	if strings.TrimSpace(code) == "" {
+		return "", nil, 0, errors.New("python_sandbox: code is required (tools.args.code or input)")
+	}
+	return code, data, timeout, nil
+}
+
+// lastLine returns the last non-empty line of s, e.g. the exception of a
+// Python traceback, or fallback when s is empty.
+func lastLine(s, fallback string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return fallback
+}
+
+// cappedBuffer keeps the first limit bytes written to it.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.truncated = true
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// Supports implements taskengine.ToolsRegistry.
+func (h *PythonSandboxTools) Supports(ctx context.Context) ([]string, error) {
+	return []string{pythonSandboxToolsName}, nil
+}
+
+// GetSchemasForSupportedTools implements taskengine.ToolsWithSchema.
+func (h *PythonSandboxTools) GetSchemasForSupportedTools(ctx context.Context) (map[string]*openapi3.T, error) {
+	schema := &openapi3.T{
+		OpenAPI: "3.1.0",
+		Info:    &openapi3.Info{Title: "Python Sandbox Tools", Description: "Run Python snippets in a separate process", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: map[string]*openapi3.SchemaRef{
+				"PythonSandboxRequest": {
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{openapi3.TypeObject},
+						Properties: map[string]*openapi3.SchemaRef{
+							"code":    {Value: &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Description: "Python source; assign `result` to return JSON"}},
+							"input":   {Value: &openapi3.Schema{Description: "Value bound to the variable `input`"}},
+							"timeout": {Value: &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Description: "Duration e.g. 10s"}},
+						},
+						Required: []string{"code"},
+					},
+				},
+			},
+		},
+	}
+	return map[string]*openapi3.T{pythonSandboxToolsName: schema}, nil
+}
+
+// GetToolsForToolsByName implements taskengine.ToolsWithSchema.
+func (h *PythonSandboxTools) GetToolsForToolsByName(ctx context.Context, name string) ([]taskengine.Tool, error) {
+	if name != pythonSandboxToolsName {
+		return nil, fmt.Errorf("unknown tools: %s", name)
+	}
+	return []taskengine.Tool{
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name: pythonSandboxToolsName,
+				Description: fmt.Sprintf("Run a short Python 3 snippet for data manipulation. The variable `input` holds the input; "+
+					"assign `result` to return a JSON value, otherwise stdout is returned. Only the standard library and installed "+
+					"packages are available and there is no network access. Write scratch files to the working directory. "+
+					"Time limit %s.", h.timeout),
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"code": map[string]interface{}{
+							"type":        "string",
+							"description": "Python source code (required)",
+						},
+						"input": map[string]interface{}{
+							"description": "Value bound to the variable `input`",
+						},
+						"timeout": map[string]interface{}{
+							"type":        "string",
+							"description": "Duration e.g. 10s",
+						},
+					},
+					"required": []string{"code"},
+				},
+			},
+		},
+	}, nil
+}
+
+var _ taskengine.ToolsRepo = (*PythonSandboxTools)(nil)
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package localtools
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func isolate(cmd *exec.Cmd) {}
+
+func checkIsolation(interpreter string) error {
+	return errors.New("process isolation requires Linux namespaces")
+}
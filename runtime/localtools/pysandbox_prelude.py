@@ -0,0 +1,114 @@
+# Prelude of the python_sandbox tools (see pysandbox.go). It reads
+# {"code": ..., "input": ...} from stdin, restricts the interpreter and runs
+# the code with `input` bound. A `result` variable is written as JSON to
+# .contenox_result.json in the workspace.
+import json
+import os
+import sys
+
+_req = json.loads(sys.stdin.read())
+_workspace = os.path.realpath(os.getcwd())
+_result_path = os.path.join(_workspace, ".contenox_result.json")
+
+try:
+    import resource
+
+    _cpu = int(os.environ.get("CONTENOX_SANDBOX_CPU_SECONDS", "0"))
+    _mem = int(os.environ.get("CONTENOX_SANDBOX_MEMORY_BYTES", "0"))
+    if _cpu > 0:
+        resource.setrlimit(resource.RLIMIT_CPU, (_cpu, _cpu))
+    if _mem > 0:
+        resource.setrlimit(resource.RLIMIT_AS, (_mem, _mem))
+        resource.setrlimit(resource.RLIMIT_FSIZE, (_mem, _mem))
+except (ImportError, ValueError, OSError):
+    pass  # not enforceable on this platform; the wall-clock timeout still applies
+
+_read_roots = {_workspace}
+for _p in [sys.prefix, sys.base_prefix, sys.exec_prefix, sys.base_exec_prefix] + sys.path:
+    if _p and os.path.isabs(_p) and os.path.realpath(_p) != "/":
+        _read_roots.add(os.path.realpath(_p))
+_write_roots = {_workspace}
+_write_flags = os.O_WRONLY | os.O_RDWR | os.O_APPEND | os.O_CREAT | os.O_TRUNC
+
+_blocked = {
+    "socket.__new__": "network access",
+    "socket.connect": "network access",
+    "socket.bind": "network access",
+    "socket.getaddrinfo": "network access",
+    "socket.gethostbyname": "network access",
+    "subprocess.Popen": "starting processes",
+    "os.system": "starting processes",
+    "os.exec": "starting processes",
+    "os.posix_spawn": "starting processes",
+    "os.spawn": "starting processes",
+    "os.fork": "starting processes",
+    "os.forkpty": "starting processes",
+    "os.kill": "signalling processes",
+    "os.killpg": "signalling processes",
+    "ctypes.dlopen": "loading native libraries",
+    "ctypes.dlsym": "loading native libraries",
+}
+_path_writes = {"os.remove", "os.rmdir", "os.mkdir", "os.chmod", "os.chown", "os.truncate", "os.utime"}
+_path_pairs = {"os.rename", "os.link", "os.symlink"}
+_path_reads = {"os.listdir", "os.scandir"}
+
+
+def _inside(path, roots):
+    if isinstance(path, int):
+        return True  # an already open descriptor
+    try:
+        real = os.path.realpath(os.fsdecode(path))
+    except (TypeError, ValueError):
+        return False
+    return any(real == r or real.startswith(r + os.sep) for r in roots)
+
+
+def _deny(what):
+    raise PermissionError("%s is not allowed in python_sandbox" % what)
+
+
+def _check(event, args):
+    if event in _blocked:
+        _deny(_blocked[event])
+    elif event == "open":
+        path, mode, flags = args
+        if path is None:
+            return
+        writing = (mode is not None and any(c in mode for c in "wax+")) or bool((flags or 0) & _write_flags)
+        if not _inside(path, _write_roots if writing else _read_roots):
+            _deny(("writing " if writing else "reading ") + os.fsdecode(path))
+    elif event in _path_writes:
+        if not _inside(args[0], _write_roots):
+            _deny("modifying " + os.fsdecode(args[0]))
+    elif event in _path_pairs:
+        if not (_inside(args[0], _write_roots) and _inside(args[1], _write_roots)):
+            _deny("modifying files outside the workspace")
+    elif event in _path_reads:
+        if args[0] is not None and not _inside(args[0], _read_roots):
+            _deny("listing " + os.fsdecode(args[0]))
+
+
+def _make_audit():
+    # The reentrancy guard lives in this closure rather than in a module
+    # global, which the snippet could reset through sys.modules["__main__"].
+    in_hook = [False]
+
+    def audit(event, args):
+        if in_hook[0]:
+            return
+        in_hook[0] = True
+        try:
+            _check(event, args)
+        finally:
+            in_hook[0] = False
+
+    return audit
+
+
+_globals = {"__name__": "__main__", "input": _req.get("input")}
+_code = compile(_req["code"], "<snippet>", "exec")
+sys.addaudithook(_make_audit())
+exec(_code, _globals)
+if "result" in _globals:
+    with open(_result_path, "w") as _f:
+        json.dump(_globals["result"], _f, default=str)
@@ -0,0 +1,93 @@
+package localtools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPythonSandbox(t *testing.T, opts ...PythonSandboxOption) *PythonSandboxTools {
+	t.Helper()
+	h, err := NewPythonSandboxTools(opts...)
+	if err != nil {
+		t.Skipf("python3 not available: %v", err)
+	}
+	return h.(*PythonSandboxTools)
+}
+
+func runSnippet(t *testing.T, h *PythonSandboxTools, code string, input any) (any, taskengine.DataType, error) {
+	t.Helper()
+	return h.Exec(context.Background(), time.Now(), input, false, &taskengine.ToolsCall{Name: "python_sandbox", Args: map[string]string{"code": code}})
+}
+
+func TestPythonSandbox_ResultAndStdout(t *testing.T) {
+	h := newTestPythonSandbox(t)
+
+	out, dt, err := runSnippet(t, h, "result = {'total': sum(r['n'] for r in input), 'rows': len(input)}", []any{
+		map[string]any{"n": 2}, map[string]any{"n": 3},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeJSON, dt)
+	assert.Equal(t, map[string]any{"total": float64(5), "rows": float64(2)}, out)
+
+	out, dt, err = runSnippet(t, h, "import csv, io\nfor row in csv.reader(io.StringIO(input)):\n    print(row[1].upper())", "a,x\nb,y")
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeString, dt)
+	assert.Equal(t, "X\nY", out)
+
+	// Tool calls from execute_tool_calls carry the code in the input.
+	out, _, err = h.Exec(context.Background(), time.Now(), map[string]any{"code": "result = input * 2", "input": "ab"}, false, &taskengine.ToolsCall{Name: "python_sandbox"})
+	require.NoError(t, err)
+	assert.Equal(t, "abab", out)
+}
+
+func TestPythonSandbox_Restrictions(t *testing.T) {
+	h := newTestPythonSandbox(t)
+	secret := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(secret, []byte("token"), 0o600))
+
+	cases := map[string]string{
+		"network":     "import socket\nsocket.create_connection(('example.com', 80))",
+		"subprocess":  "import subprocess\nsubprocess.run(['ls'])",
+		"os.system":   "import os\nos.system('ls')",
+		"read secret": "open(" + quotePy(secret) + ").read()",
+		"hook guard":  "import sys\nsys.modules['__main__']._in_hook = True\nopen(" + quotePy(secret) + ").read()",
+		"write out":   "open(" + quotePy(filepath.Join(filepath.Dir(secret), "x.txt")) + ", 'w')",
+		"exception":   "raise ValueError('bad row')",
+	}
+	for name, code := range cases {
+		_, _, err := runSnippet(t, h, code, nil)
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), "python_sandbox:", name)
+	}
+	_, _, err := runSnippet(t, h, "raise ValueError('bad row')", nil)
+	assert.EqualError(t, err, "python_sandbox: ValueError: bad row")
+
+	// The interpreter is PID 1 of its own PID namespace.
+	out, _, err := runSnippet(t, h, "import os\nresult = os.getpid()", nil)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), out)
+
+	out, _, err = runSnippet(t, h, "open('scratch.txt', 'w').write('ok')\nresult = open('scratch.txt').read()", nil)
+	require.NoError(t, err, "the workspace is writable")
+	assert.Equal(t, "ok", out)
+}
+
+func TestPythonSandbox_Timeout(t *testing.T) {
+	h := newTestPythonSandbox(t, WithPythonTimeout(500*time.Millisecond))
+	_, _, err := runSnippet(t, h, "while True:\n    pass", nil)
+	require.ErrorContains(t, err, "timed out")
+
+	_, _, err = runSnippet(t, h, "", nil)
+	require.ErrorContains(t, err, "code is required")
+}
+
+func quotePy(s string) string {
+	return "r'" + s + "'"
+}
@@ -51,6 +51,22 @@ type MCPDeletedEvent struct {
 	Name string `json:"name"`
 }
 
+// MCPCreatedSchema versions the runtimetypes.MCPServer payload published on SubjectCreated.
+var MCPCreatedSchema = libbus.MessageSchema{
+	Name:       "mcp.server_created",
+	Version:    1,
+	MinVersion: 0,
+	Required:   []string{"name"},
+}
+
+// MCPDeletedSchema versions the MCPDeletedEvent payload published on SubjectDeleted.
+var MCPDeletedSchema = libbus.MessageSchema{
+	Name:       "mcp.server_deleted",
+	Version:    1,
+	MinVersion: 0,
+	Required:   []string{"name"},
+}
+
 // poolEntry wraps a session pool with its last-access timestamp for idle eviction.
 type poolEntry struct {
 	pool       *localtools.MCPSessionPool
@@ -340,7 +356,7 @@ func (m *Manager) WatchEvents(ctx context.Context) error {
 					return
 				}
 				var srv runtimetypes.MCPServer
-				if err := json.Unmarshal(data, &srv); err != nil {
+				if _, err := MCPCreatedSchema.Decode(data, &srv); err != nil {
 					_, report, end := m.tracker.Start(ctx, "watch", "mcp_event", "event", "created")
 					report("decode_error", map[string]any{"error": err.Error()})
 					end()
@@ -356,7 +372,7 @@ func (m *Manager) WatchEvents(ctx context.Context) error {
 					return
 				}
 				var ev MCPDeletedEvent
-				if err := json.Unmarshal(data, &ev); err != nil {
+				if _, err := MCPDeletedSchema.Decode(data, &ev); err != nil {
 					_, report, end := m.tracker.Start(ctx, "watch", "mcp_event", "event", "deleted")
 					report("decode_error", map[string]any{"error": err.Error()})
 					end()
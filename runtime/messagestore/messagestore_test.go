@@ -3,6 +3,7 @@ package messagestore_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -67,6 +68,31 @@ func TestMessageStore_AppendAndList(t *testing.T) {
 	require.Equal(t, "m2", listed[1].ID)
 }
 
+func TestMessageStore_AppendMessages_Bulk(t *testing.T) {
+	ctx, db := setupDB(t)
+	store := messagestore.New(db.WithoutTransaction(), "")
+
+	require.NoError(t, store.CreateMessageIndex(ctx, "idx-bulk", "alice"))
+
+	// More rows than fit into a single statement.
+	now := time.Now().UTC()
+	msgs := make([]*messagestore.Message, 20000)
+	for i := range msgs {
+		msgs[i] = &messagestore.Message{
+			ID:      fmt.Sprintf("m%05d", i),
+			IDX:     "idx-bulk",
+			Payload: marshal(t, taskengine.Message{Role: "user", Content: fmt.Sprint(i)}),
+			AddedAt: now.Add(time.Duration(i) * time.Microsecond),
+		}
+	}
+	require.NoError(t, store.AppendMessages(ctx, msgs...))
+
+	listed, err := store.ListMessages(ctx, "idx-bulk")
+	require.NoError(t, err)
+	require.Len(t, listed, len(msgs))
+	require.Equal(t, "m19999", listed[len(listed)-1].ID)
+}
+
 func TestMessageStore_LastMessage(t *testing.T) {
 	ctx, db := setupDB(t)
 	store := messagestore.New(db.WithoutTransaction(), "")
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/contenox/contenox/libdbexec"
@@ -155,31 +154,22 @@ func (s *store) RenameSession(ctx context.Context, id string, name string) error
 	return checkRowsAffected(result)
 }
 
-// AppendMessages appends multiple messages in a single batch insert.
+// AppendMessages appends multiple messages with batched inserts.
 func (s *store) AppendMessages(ctx context.Context, messages ...*Message) error {
 	if len(messages) == 0 {
 		return nil
 	}
 
 	now := time.Now().UTC()
-	valueStrings := make([]string, 0, len(messages))
-	valueArgs := make([]any, 0, len(messages)*4)
-
-	for i, msg := range messages {
+	rows := make([][]any, 0, len(messages))
+	for _, msg := range messages {
 		if msg.AddedAt.IsZero() {
 			msg.AddedAt = now
 		}
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4))
-		valueArgs = append(valueArgs, msg.ID, msg.IDX, msg.Payload, msg.AddedAt)
+		rows = append(rows, []any{msg.ID, msg.IDX, msg.Payload, msg.AddedAt})
 	}
 
-	stmt := fmt.Sprintf(`
-		INSERT INTO messages (id, idx_id, payload, added_at)
-		VALUES %s`,
-		strings.Join(valueStrings, ","),
-	)
-
-	_, err := s.Exec.ExecContext(ctx, stmt, valueArgs...)
+	_, err := libdbexec.InsertRows(ctx, s.Exec, "messages", []string{"id", "idx_id", "payload", "added_at"}, rows)
 	if err != nil {
 		return fmt.Errorf("failed to append messages: %w", err)
 	}
@@ -263,6 +253,50 @@ func (s *store) CountMessages(ctx context.Context, stream string) (int, error) {
 	return count, nil
 }
 
+// SetFeedback records the feedback on a message, replacing earlier feedback
+// on the same message.
+func (s *store) SetFeedback(ctx context.Context, feedback *Feedback) error {
+	if feedback.CreatedAt.IsZero() {
+		feedback.CreatedAt = time.Now().UTC()
+	}
+	_, err := libdbexec.UpsertRows(ctx, s.Exec, "message_feedback",
+		[]string{"message_id", "idx_id", "rating", "comment", "created_at"},
+		[]string{"message_id", "idx_id"},
+		[][]any{{feedback.MessageID, feedback.IDX, feedback.Rating, feedback.Comment, feedback.CreatedAt}})
+	if err != nil {
+		return fmt.Errorf("failed to set feedback: %w", err)
+	}
+	return nil
+}
+
+// ListFeedback lists the feedback on the messages of a stream.
+func (s *store) ListFeedback(ctx context.Context, stream string) ([]*Feedback, error) {
+	rows, err := s.Exec.QueryContext(ctx, `
+		SELECT message_id, idx_id, rating, comment, created_at
+		FROM message_feedback
+		WHERE idx_id = $1
+		ORDER BY created_at ASC`,
+		stream,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback: %w", err)
+	}
+	defer rows.Close()
+
+	var feedback []*Feedback
+	for rows.Next() {
+		var f Feedback
+		if err := rows.Scan(&f.MessageID, &f.IDX, &f.Rating, &f.Comment, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedback = append(feedback, &f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return feedback, nil
+}
+
 func checkRowsAffected(result sql.Result) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
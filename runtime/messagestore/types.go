@@ -13,6 +13,16 @@ type Message struct {
 	AddedAt time.Time `json:"added_at"`
 }
 
+// Feedback is a user rating of a stored message.
+type Feedback struct {
+	MessageID string `json:"message_id"`
+	IDX       string `json:"idx_id"`
+	// Rating is 1 for thumbs-up and -1 for thumbs-down.
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // SessionInfo represents a chat session index row.
 type SessionInfo struct {
 	ID       string
@@ -38,4 +48,8 @@ type Store interface {
 	ListMessages(ctx context.Context, stream string) ([]*Message, error)
 	LastMessage(ctx context.Context, stream string) (*Message, error)
 	CountMessages(ctx context.Context, stream string) (int, error)
+
+	// Feedback operations
+	SetFeedback(ctx context.Context, feedback *Feedback) error
+	ListFeedback(ctx context.Context, stream string) ([]*Feedback, error)
 }
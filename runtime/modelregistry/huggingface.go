@@ -0,0 +1,208 @@
+package modelregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// HuggingFaceURL is the default Hugging Face hub address.
+const HuggingFaceURL = "https://huggingface.co"
+
+// ErrNoGGUF is returned by HuggingFaceModel.Quantization when the repository
+// has no single-file GGUF weights.
+var ErrNoGGUF = errors.New("repository has no GGUF files")
+
+// HuggingFaceModel is the metadata of a Hugging Face hub model needed to
+// declare it: context length, capabilities and the available GGUF
+// quantizations.
+type HuggingFaceModel struct {
+	// ID is the repository, e.g. "bartowski/Qwen_Qwen3-4B-GGUF".
+	ID string `json:"id" example:"bartowski/Qwen_Qwen3-4B-GGUF"`
+	// ContextLength is the trained context window, 0 when unknown.
+	ContextLength int  `json:"contextLength" example:"40960"`
+	CanChat       bool `json:"canChat" example:"true"`
+	CanEmbed      bool `json:"canEmbed" example:"false"`
+	CanPrompt     bool `json:"canPrompt" example:"true"`
+	CanStream     bool `json:"canStream" example:"true"`
+	// Quantizations lists the GGUF files, recommended first.
+	Quantizations []GGUFQuantization `json:"quantizations"`
+
+	baseURL string
+}
+
+// GGUFQuantization is one GGUF file of a repository.
+type GGUFQuantization struct {
+	// Name is the quantization as written in the file name, upper-cased.
+	Name      string `json:"name" example:"Q4_K_M"`
+	File      string `json:"file" example:"Qwen3-4B-Q4_K_M.gguf"`
+	SizeBytes int64  `json:"sizeBytes" example:"2497280736"`
+}
+
+// recommendedQuantizations ranks quantizations by their balance of quality
+// and size; the first one present is recommended.
+var recommendedQuantizations = []string{"Q4_K_M", "Q4_K_S", "Q5_K_M", "Q4_0", "Q5_K_S", "Q6_K", "Q8_0", "IQ4_XS", "Q3_K_M", "BF16", "F16"}
+
+var (
+	quantPattern = regexp.MustCompile(`(?i)(?:^|[-_.])((?:I?Q\d(?:_[A-Z0-9]+)*)|BF16|F16|F32)$`)
+	splitPattern = regexp.MustCompile(`-\d{5}-of-\d{5}\.gguf$`)
+)
+
+// FetchHuggingFaceModel reads the metadata of the hub model id from baseURL
+// (HuggingFaceURL when empty). The context length is taken from the GGUF
+// header summary and otherwise from config.json.
+func FetchHuggingFaceModel(ctx context.Context, client *http.Client, baseURL, id string) (*HuggingFaceModel, error) {
+	id = strings.Trim(strings.TrimPrefix(strings.TrimPrefix(id, "hf.co/"), HuggingFaceURL+"/"), "/")
+	if strings.Count(id, "/") != 1 {
+		return nil, fmt.Errorf("hugging face model id must be <owner>/<repo>, got %q", id)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = HuggingFaceURL
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	var info struct {
+		ID          string   `json:"id"`
+		PipelineTag string   `json:"pipeline_tag"`
+		Tags        []string `json:"tags"`
+		Siblings    []struct {
+			RFilename string `json:"rfilename"`
+			Size      int64  `json:"size"`
+		} `json:"siblings"`
+		GGUF *struct {
+			ContextLength int `json:"context_length"`
+		} `json:"gguf"`
+	}
+	if err := getJSON(ctx, client, baseURL+"/api/models/"+id+"?blobs=true", &info); err != nil {
+		return nil, fmt.Errorf("hugging face model %s: %w", id, err)
+	}
+	m := &HuggingFaceModel{ID: id, baseURL: baseURL}
+	if info.ID != "" {
+		m.ID = info.ID
+	}
+
+	switch {
+	case info.PipelineTag == "feature-extraction" || info.PipelineTag == "sentence-similarity" ||
+		slices.Contains(info.Tags, "sentence-transformers"):
+		m.CanEmbed = true
+	default:
+		m.CanChat, m.CanPrompt, m.CanStream = true, true, true
+	}
+
+	hasConfig := false
+	for _, s := range info.Siblings {
+		if s.RFilename == "config.json" {
+			hasConfig = true
+		}
+		if q, ok := parseGGUFQuantization(s.RFilename); ok {
+			q.SizeBytes = s.Size
+			m.Quantizations = append(m.Quantizations, q)
+		}
+	}
+	slices.SortStableFunc(m.Quantizations, func(a, b GGUFQuantization) int {
+		return quantizationRank(a.Name) - quantizationRank(b.Name)
+	})
+
+	if info.GGUF != nil && info.GGUF.ContextLength > 0 {
+		m.ContextLength = info.GGUF.ContextLength
+	} else if hasConfig {
+		var cfg struct {
+			MaxPositionEmbeddings int `json:"max_position_embeddings"`
+			TextConfig            *struct {
+				MaxPositionEmbeddings int `json:"max_position_embeddings"`
+			} `json:"text_config"`
+		}
+		if err := getJSON(ctx, client, baseURL+"/"+m.ID+"/resolve/main/config.json", &cfg); err == nil {
+			m.ContextLength = cfg.MaxPositionEmbeddings
+			if m.ContextLength == 0 && cfg.TextConfig != nil {
+				m.ContextLength = cfg.TextConfig.MaxPositionEmbeddings
+			}
+		}
+	}
+	return m, nil
+}
+
+// Quantization returns the GGUF file of quantization name, matched
+// case-insensitively, or the recommended one when name is empty.
+func (m *HuggingFaceModel) Quantization(name string) (*GGUFQuantization, error) {
+	if len(m.Quantizations) == 0 {
+		return nil, fmt.Errorf("%s: %w", m.ID, ErrNoGGUF)
+	}
+	if name == "" {
+		return &m.Quantizations[0], nil
+	}
+	names := make([]string, len(m.Quantizations))
+	for i, q := range m.Quantizations {
+		if strings.EqualFold(q.Name, name) {
+			return &m.Quantizations[i], nil
+		}
+		names[i] = q.Name
+	}
+	return nil, fmt.Errorf("%s has no %s quantization, available: %s", m.ID, name, strings.Join(names, ", "))
+}
+
+// DownloadURL returns the URL of the GGUF file of q.
+func (m *HuggingFaceModel) DownloadURL(q *GGUFQuantization) string {
+	base := m.baseURL
+	if base == "" {
+		base = HuggingFaceURL
+	}
+	return base + "/" + m.ID + "/resolve/main/" + (&url.URL{Path: q.File}).EscapedPath()
+}
+
+// OllamaPullSpec returns the name Ollama pulls the GGUF file of q by.
+func (m *HuggingFaceModel) OllamaPullSpec(q *GGUFQuantization) string {
+	return "hf.co/" + m.ID + ":" + q.Name
+}
+
+// parseGGUFQuantization recognizes the quantization of a GGUF file name.
+// Split files and multimodal projectors are skipped.
+func parseGGUFQuantization(file string) (GGUFQuantization, bool) {
+	base := path.Base(file)
+	if !strings.HasSuffix(strings.ToLower(base), ".gguf") || splitPattern.MatchString(base) ||
+		strings.Contains(strings.ToLower(base), "mmproj") {
+		return GGUFQuantization{}, false
+	}
+	match := quantPattern.FindStringSubmatch(base[:len(base)-len(".gguf")])
+	if match == nil {
+		return GGUFQuantization{}, false
+	}
+	return GGUFQuantization{Name: strings.ToUpper(match[1]), File: file}, true
+}
+
+func quantizationRank(name string) int {
+	if i := slices.Index(recommendedQuantizations, name); i >= 0 {
+		return i
+	}
+	return len(recommendedQuantizations)
+}
+
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: HTTP %s", rawURL, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", rawURL, err)
+	}
+	return nil
+}
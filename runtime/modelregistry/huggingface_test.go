@@ -0,0 +1,86 @@
+package modelregistry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/modelregistry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeHub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/bartowski/Qwen_Qwen3-4B-GGUF", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("blobs"))
+		_, _ = w.Write([]byte(`{
+			"id": "bartowski/Qwen_Qwen3-4B-GGUF",
+			"pipeline_tag": "text-generation",
+			"gguf": {"context_length": 40960},
+			"siblings": [
+				{"rfilename": "README.md", "size": 100},
+				{"rfilename": "Qwen_Qwen3-4B-Q8_0.gguf", "size": 4280000000},
+				{"rfilename": "Qwen_Qwen3-4B-Q4_K_M.gguf", "size": 2500000000},
+				{"rfilename": "Qwen_Qwen3-4B-bf16-00001-of-00002.gguf", "size": 5000000000},
+				{"rfilename": "mmproj-Qwen_Qwen3-4B-f16.gguf", "size": 600000000}
+			]
+		}`))
+	})
+	mux.HandleFunc("/api/models/acme/embedder", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "acme/embedder", "pipeline_tag": "feature-extraction",
+			"siblings": [{"rfilename": "config.json"}, {"rfilename": "embedder.F16.gguf", "size": 300}]}`))
+	})
+	mux.HandleFunc("/acme/embedder/resolve/main/config.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"max_position_embeddings": 8192}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUnit_FetchHuggingFaceModel_GGUF(t *testing.T) {
+	srv := newFakeHub(t)
+	m, err := modelregistry.FetchHuggingFaceModel(context.Background(), srv.Client(), srv.URL, "hf.co/bartowski/Qwen_Qwen3-4B-GGUF")
+	require.NoError(t, err)
+
+	assert.Equal(t, 40960, m.ContextLength)
+	assert.True(t, m.CanChat && m.CanPrompt && m.CanStream)
+	assert.False(t, m.CanEmbed)
+	require.Len(t, m.Quantizations, 2, "split files and projectors are skipped")
+
+	q, err := m.Quantization("")
+	require.NoError(t, err)
+	assert.Equal(t, "Q4_K_M", q.Name)
+	assert.Equal(t, int64(2500000000), q.SizeBytes)
+	assert.Equal(t, srv.URL+"/bartowski/Qwen_Qwen3-4B-GGUF/resolve/main/Qwen_Qwen3-4B-Q4_K_M.gguf", m.DownloadURL(q))
+	assert.Equal(t, "hf.co/bartowski/Qwen_Qwen3-4B-GGUF:Q4_K_M", m.OllamaPullSpec(q))
+
+	q, err = m.Quantization("q8_0")
+	require.NoError(t, err)
+	assert.Equal(t, "Qwen_Qwen3-4B-Q8_0.gguf", q.File)
+
+	_, err = m.Quantization("Q2_K")
+	require.ErrorContains(t, err, "available: Q4_K_M, Q8_0")
+}
+
+func TestUnit_FetchHuggingFaceModel_EmbeddingFromConfig(t *testing.T) {
+	srv := newFakeHub(t)
+	m, err := modelregistry.FetchHuggingFaceModel(context.Background(), srv.Client(), srv.URL, "acme/embedder")
+	require.NoError(t, err)
+	assert.True(t, m.CanEmbed)
+	assert.False(t, m.CanChat)
+	assert.Equal(t, 8192, m.ContextLength)
+	require.Len(t, m.Quantizations, 1)
+	assert.Equal(t, "F16", m.Quantizations[0].Name)
+}
+
+func TestUnit_FetchHuggingFaceModel_Errors(t *testing.T) {
+	srv := newFakeHub(t)
+	_, err := modelregistry.FetchHuggingFaceModel(context.Background(), srv.Client(), srv.URL, "no-owner")
+	require.Error(t, err)
+	_, err = modelregistry.FetchHuggingFaceModel(context.Background(), srv.Client(), srv.URL, "acme/missing")
+	require.ErrorContains(t, err, "404")
+}
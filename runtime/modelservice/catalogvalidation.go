@@ -0,0 +1,166 @@
+package modelservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+)
+
+// ErrUnknownModel is returned when a model name is missing from every provider
+// catalog but close to names listed there, which almost always is a typo.
+var ErrUnknownModel = errors.New("model not found in provider catalogs")
+
+// maxCatalogSuggestions bounds the number of "did you mean" names reported.
+const maxCatalogSuggestions = 3
+
+// CatalogLookup returns the model names listed by each provider catalog,
+// keyed by backend name. It should answer from cached provider state rather
+// than contacting the providers.
+type CatalogLookup func(ctx context.Context) (map[string][]string, error)
+
+// CatalogCheck is the outcome of checking a model name against the catalogs.
+type CatalogCheck struct {
+	// Known is set when at least one catalog lists the name.
+	Known bool
+	// Suggestions are the catalog names closest to an unknown name.
+	Suggestions []string
+}
+
+// CheckCatalogs checks name against catalogs. A ":latest" suffix is ignored,
+// as the providers do.
+func CheckCatalogs(name string, catalogs map[string][]string) CatalogCheck {
+	name, _ = strings.CutSuffix(strings.TrimSpace(name), ":latest")
+	lower := strings.ToLower(name)
+	dist := map[string]int{}
+	for _, models := range catalogs {
+		for _, model := range models {
+			if model == name {
+				return CatalogCheck{Known: true}
+			}
+			d := editDistance(lower, strings.ToLower(model))
+			if d <= max(2, len(name)/3) {
+				dist[model] = d
+			}
+		}
+	}
+	best := -1
+	for _, d := range dist {
+		if best < 0 || d < best {
+			best = d
+		}
+	}
+	var suggestions []string
+	for model, d := range dist {
+		if d == best {
+			suggestions = append(suggestions, model)
+		}
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > maxCatalogSuggestions {
+		suggestions = suggestions[:maxCatalogSuggestions]
+	}
+	return CatalogCheck{Suggestions: suggestions}
+}
+
+type catalogValidationDecorator struct {
+	service Service
+	lookup  CatalogLookup
+	warn    func(error)
+}
+
+// WithCatalogValidation returns a Service that checks model names against the
+// provider catalogs returned by lookup before they are stored. A name no
+// catalog lists but close to listed names is rejected with ErrUnknownModel
+// and suggestions, or, when warn is non-nil, reported to warn and stored
+// anyway. Names unlike any catalog entry pass: they may be served by a local
+// backend such as Ollama. Without cached catalogs nothing is checked.
+func WithCatalogValidation(service Service, lookup CatalogLookup, warn func(error)) Service {
+	return &catalogValidationDecorator{
+		service: service,
+		lookup:  lookup,
+		warn:    warn,
+	}
+}
+
+func (d *catalogValidationDecorator) Append(ctx context.Context, model *runtimetypes.Model) error {
+	if err := d.check(ctx, model); err != nil {
+		return err
+	}
+	return d.service.Append(ctx, model)
+}
+
+func (d *catalogValidationDecorator) Update(ctx context.Context, data *runtimetypes.Model) error {
+	if err := d.check(ctx, data); err != nil {
+		return err
+	}
+	return d.service.Update(ctx, data)
+}
+
+func (d *catalogValidationDecorator) List(ctx context.Context, createdAtCursor *time.Time, limit int) ([]*runtimetypes.Model, error) {
+	return d.service.List(ctx, createdAtCursor, limit)
+}
+
+func (d *catalogValidationDecorator) Delete(ctx context.Context, modelName string) error {
+	return d.service.Delete(ctx, modelName)
+}
+
+func (d *catalogValidationDecorator) check(ctx context.Context, model *runtimetypes.Model) error {
+	err := CheckModelName(ctx, d.lookup, model.Model)
+	if err == nil || d.warn == nil || !errors.Is(err, ErrUnknownModel) {
+		return err
+	}
+	d.warn(err)
+	return nil
+}
+
+// CheckModelName checks name against the catalogs returned by lookup. It
+// returns an ErrUnknownModel error with suggestions for a name no catalog
+// lists but close to listed names, and nil for every other name.
+func CheckModelName(ctx context.Context, lookup CatalogLookup, name string) error {
+	if name == "" {
+		return nil
+	}
+	catalogs, err := lookup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read provider catalogs: %w", err)
+	}
+	result := CheckCatalogs(name, catalogs)
+	if result.Known || len(result.Suggestions) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(result.Suggestions))
+	for i, s := range result.Suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return fmt.Errorf("%w %w: %q is not listed by any provider catalog; did you mean %s?",
+		errdefs.ErrBadRequest, ErrUnknownModel, name, strings.Join(quoted, ", "))
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+var _ Service = (*catalogValidationDecorator)(nil)
@@ -0,0 +1,92 @@
+package modelservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/modelservice"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubService struct {
+	appended []string
+}
+
+func (s *stubService) Append(_ context.Context, model *runtimetypes.Model) error {
+	s.appended = append(s.appended, model.Model)
+	return nil
+}
+
+func (s *stubService) Update(context.Context, *runtimetypes.Model) error { return nil }
+
+func (s *stubService) List(context.Context, *time.Time, int) ([]*runtimetypes.Model, error) {
+	return nil, nil
+}
+
+func (s *stubService) Delete(context.Context, string) error { return nil }
+
+var catalogs = map[string][]string{
+	"openai": {"gpt-5", "gpt-5-mini", "gpt-4o-mini"},
+	"gemini": {"gemini-2.5-flash", "gemini-2.5-pro"},
+}
+
+func TestUnit_CheckCatalogs(t *testing.T) {
+	assert.True(t, modelservice.CheckCatalogs("gpt-5-mini", catalogs).Known)
+	assert.True(t, modelservice.CheckCatalogs("gemini-2.5-pro:latest", catalogs).Known)
+
+	check := modelservice.CheckCatalogs("gpt-5-mnii", catalogs)
+	assert.False(t, check.Known)
+	assert.Equal(t, "gpt-5-mini", check.Suggestions[0])
+
+	check = modelservice.CheckCatalogs("Gemini-2.5-Flash", catalogs)
+	assert.False(t, check.Known, "catalog names are case-sensitive")
+	assert.Equal(t, []string{"gemini-2.5-flash"}, check.Suggestions)
+
+	assert.Empty(t, modelservice.CheckCatalogs("qwen_qwen3-4b-q4_k_m", catalogs).Suggestions)
+}
+
+func TestUnit_WithCatalogValidation(t *testing.T) {
+	ctx := context.Background()
+	lookup := func(context.Context) (map[string][]string, error) { return catalogs, nil }
+
+	inner := &stubService{}
+	svc := modelservice.WithCatalogValidation(inner, lookup, nil)
+	err := svc.Append(ctx, &runtimetypes.Model{Model: "gemini-2.5-flsh"})
+	require.ErrorIs(t, err, modelservice.ErrUnknownModel)
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+	assert.Contains(t, err.Error(), `did you mean "gemini-2.5-flash"?`)
+
+	require.NoError(t, svc.Append(ctx, &runtimetypes.Model{Model: "gpt-5"}))
+	require.NoError(t, svc.Append(ctx, &runtimetypes.Model{Model: "qwen2.5:7b"}), "names unlike any catalog entry pass")
+	assert.Equal(t, []string{"gpt-5", "qwen2.5:7b"}, inner.appended)
+
+	var warned []error
+	inner = &stubService{}
+	svc = modelservice.WithCatalogValidation(inner, lookup, func(err error) { warned = append(warned, err) })
+	require.NoError(t, svc.Append(ctx, &runtimetypes.Model{Model: "gpt-5-mnii"}))
+	require.Len(t, warned, 1)
+	assert.ErrorIs(t, warned[0], modelservice.ErrUnknownModel)
+	assert.Equal(t, []string{"gpt-5-mnii"}, inner.appended)
+
+	failing := func(context.Context) (map[string][]string, error) { return nil, errors.New("boom") }
+	svc = modelservice.WithCatalogValidation(&stubService{}, failing, nil)
+	assert.Error(t, svc.Update(ctx, &runtimetypes.Model{Model: "gpt-5"}))
+}
+
+func TestUnit_CheckModelName(t *testing.T) {
+	lookup := func(context.Context) (map[string][]string, error) { return catalogs, nil }
+
+	require.NoError(t, modelservice.CheckModelName(context.Background(), lookup, "gpt-5"))
+	require.NoError(t, modelservice.CheckModelName(context.Background(), lookup, "qwen2.5:7b"), "names unlike any catalog model pass")
+	err := modelservice.CheckModelName(context.Background(), lookup, "gpt-5-mnii")
+	require.ErrorIs(t, err, modelservice.ErrUnknownModel)
+	assert.ErrorContains(t, err, `did you mean "gpt-5-mini"`)
+
+	failing := func(context.Context) (map[string][]string, error) { return nil, errors.New("db closed") }
+	assert.ErrorContains(t, modelservice.CheckModelName(context.Background(), failing, "gpt-5"), "db closed")
+}
@@ -105,6 +105,7 @@ func Compile(
 			}
 
 			cp.Transition.OnFailure = remapGoto(cp.Transition.OnFailure, stepIdx, origIDs)
+			cp.Transition.OnError = remapGoto(cp.Transition.OnError, stepIdx, origIDs)
 
 			for j := range cp.Transition.Branches {
 				b := &cp.Transition.Branches[j]
@@ -149,6 +150,7 @@ func Compile(
 			cp.InputVar = rewriteSummarizerRef(cp.InputVar, stepIdx, sumIDs, execDoneID, nextTarget)
 
 			cp.Transition.OnFailure = rewriteSummarizerRef(cp.Transition.OnFailure, stepIdx, sumIDs, execDoneID, nextTarget)
+			cp.Transition.OnError = rewriteSummarizerRef(cp.Transition.OnError, stepIdx, sumIDs, execDoneID, nextTarget)
 			for j := range cp.Transition.Branches {
 				b := &cp.Transition.Branches[j]
 				b.Goto = rewriteSummarizerRef(b.Goto, stepIdx, sumIDs, execDoneID, nextTarget)
@@ -186,6 +188,9 @@ func validateExecutorEdges(ex *taskengine.TaskChainDefinition, ids map[string]bo
 		if t.Transition.OnFailure != "" && !ids[t.Transition.OnFailure] {
 			return fmt.Errorf("plancompile: executor task %q on_failure %q is not in the same chain (unsupported)", t.ID, t.Transition.OnFailure)
 		}
+		if t.Transition.OnError != "" && !ids[t.Transition.OnError] {
+			return fmt.Errorf("plancompile: executor task %q on_error %q is not in the same chain (unsupported)", t.ID, t.Transition.OnError)
+		}
 		for _, b := range t.Transition.Branches {
 			if isTerminalGoto(b.Goto) {
 				continue
@@ -308,6 +313,9 @@ func validateSummarizerEdges(sm *taskengine.TaskChainDefinition, ids map[string]
 		if t.Transition.OnFailure != "" && !isOK(t.Transition.OnFailure) {
 			return fmt.Errorf("plancompile: summarizer task %q on_failure %q is not in the same chain (unsupported)", t.ID, t.Transition.OnFailure)
 		}
+		if t.Transition.OnError != "" && !isOK(t.Transition.OnError) {
+			return fmt.Errorf("plancompile: summarizer task %q on_error %q is not in the same chain (unsupported)", t.ID, t.Transition.OnError)
+		}
 		for _, b := range t.Transition.Branches {
 			if !isOK(b.Goto) {
 				return fmt.Errorf("plancompile: summarizer task %q branch goto %q is not in the same chain (unsupported)", t.ID, b.Goto)
@@ -67,5 +67,8 @@ func retargetNextSeedToEnd(tr taskengine.TaskTransition, nextSeed string) tasken
 	if strings.TrimSpace(out.OnFailure) == nextSeed {
 		out.OnFailure = ""
 	}
+	if strings.TrimSpace(out.OnError) == nextSeed {
+		out.OnError = ""
+	}
 	return out
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/contenox/contenox/libtracker"
 	"github.com/contenox/contenox/runtime/plancompile"
 	"github.com/contenox/contenox/runtime/planstore"
+	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/contenox/contenox/runtime/taskengine"
 	"github.com/contenox/contenox/runtime/taskengine/llmretry"
 	"github.com/contenox/contenox/runtime/vfsservice"
@@ -766,6 +767,13 @@ func (s *service) Next(ctx context.Context, args Args, executorChain, summarizer
 	if err != nil {
 		return "", "", err
 	}
+	// One step of a plan runs at a time: the executor reads the results of
+	// the steps before it.
+	ctx, unlock, err := runtimetypes.LockSession(ctx, runtimetypes.New(s.db.WithoutTransaction()), "plan:"+plan.ID, 0)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
 
 	pending, err := st.ClaimNextPendingStep(ctx, plan.ID)
 	if errors.Is(err, planstore.ErrNotFound) {
@@ -0,0 +1,117 @@
+package planstore
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// StepDuration is how long a completed step took.
+type StepDuration struct {
+	Description string
+	Duration    time.Duration
+}
+
+// Estimate is the expected remaining run time of a plan.
+type Estimate struct {
+	// Remaining is the estimated time until all unfinished steps are done.
+	Remaining time.Duration
+	// Steps is the number of pending and running steps covered.
+	Steps int
+	// Basis is the number of completed steps the estimate is derived from.
+	// Zero means there was no history and Remaining is meaningless.
+	Basis int
+}
+
+// similarStepThreshold is the word overlap (Jaccard index) above which a
+// completed step counts as similar to an unfinished one.
+const similarStepThreshold = 0.5
+
+// Duration returns how long the step ran, or zero if it has not finished or
+// predates start time tracking.
+func (s *PlanStep) Duration() time.Duration {
+	if s.StartedAt.IsZero() || s.ExecutedAt.IsZero() || s.ExecutedAt.Before(s.StartedAt) {
+		return 0
+	}
+	return s.ExecutedAt.Sub(s.StartedAt)
+}
+
+// EstimateRemaining estimates how long the pending and running steps take,
+// from the durations of completed steps in history. Each step is estimated
+// with the median duration of the completed steps with similar descriptions,
+// or of all completed steps if none is similar. A running step is credited
+// with the time it has already run.
+func EstimateRemaining(steps []*PlanStep, history []StepDuration, now time.Time) Estimate {
+	est := Estimate{Basis: len(history)}
+	if len(history) == 0 {
+		return est
+	}
+	all := make([]time.Duration, len(history))
+	words := make([]map[string]bool, len(history))
+	for i, h := range history {
+		all[i] = h.Duration
+		words[i] = descriptionWords(h.Description)
+	}
+	overall := median(all)
+
+	for _, step := range steps {
+		if step.Status != StepStatusPending && step.Status != StepStatusRunning {
+			continue
+		}
+		est.Steps++
+		stepWords := descriptionWords(step.Description)
+		var similar []time.Duration
+		for i, h := range history {
+			if jaccard(stepWords, words[i]) >= similarStepThreshold {
+				similar = append(similar, h.Duration)
+			}
+		}
+		expected := overall
+		if len(similar) > 0 {
+			expected = median(similar)
+		}
+		if step.Status == StepStatusRunning && !step.StartedAt.IsZero() {
+			expected = max(expected-now.Sub(step.StartedAt), 0)
+		}
+		est.Remaining += expected
+	}
+	return est
+}
+
+// descriptionWords returns the lower-cased words of a step description,
+// ignoring words shorter than three letters.
+func descriptionWords(description string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= 3 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func median(durations []time.Duration) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
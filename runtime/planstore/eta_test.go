@@ -0,0 +1,46 @@
+package planstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/planstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_EstimateRemaining(t *testing.T) {
+	now := time.Now()
+	history := []planstore.StepDuration{
+		{Description: "Run the unit tests", Duration: 10 * time.Minute},
+		{Description: "Run unit tests again", Duration: 12 * time.Minute},
+		{Description: "Update the README", Duration: time.Minute},
+		{Description: "Fix lint warnings", Duration: 2 * time.Minute},
+	}
+	steps := []*planstore.PlanStep{
+		{Description: "Update the README", Status: planstore.StepStatusCompleted},
+		{Description: "Run the unit tests", Status: planstore.StepStatusRunning, StartedAt: now.Add(-4 * time.Minute)},
+		{Description: "Write the changelog", Status: planstore.StepStatusPending},
+		{Description: "Dropped", Status: planstore.StepStatusSkipped},
+	}
+
+	est := planstore.EstimateRemaining(steps, history, now)
+	require.Equal(t, 2, est.Steps)
+	require.Equal(t, 4, est.Basis)
+	// Running: median of the two similar test runs (11m) minus 4m elapsed.
+	// Pending: no similar step, so the median of all (6m).
+	require.Equal(t, 7*time.Minute+6*time.Minute, est.Remaining)
+}
+
+func TestUnit_EstimateRemaining_NoHistory(t *testing.T) {
+	steps := []*planstore.PlanStep{{Description: "anything", Status: planstore.StepStatusPending}}
+	est := planstore.EstimateRemaining(steps, nil, time.Now())
+	require.Zero(t, est.Basis)
+	require.Zero(t, est.Remaining)
+}
+
+func TestUnit_PlanStep_Duration(t *testing.T) {
+	start := time.Now()
+	step := &planstore.PlanStep{StartedAt: start, ExecutedAt: start.Add(90 * time.Second)}
+	require.Equal(t, 90*time.Second, step.Duration())
+	require.Zero(t, (&planstore.PlanStep{ExecutedAt: start}).Duration())
+}
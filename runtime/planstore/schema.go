@@ -42,6 +42,7 @@ func InitSchema(ctx context.Context, exec libdbexec.Exec) error {
 			status                VARCHAR(50)  NOT NULL DEFAULT 'pending',
 			execution_result      TEXT         NOT NULL DEFAULT '',
 			executed_at           TIMESTAMP,
+			started_at            TIMESTAMP,
 			summary               TEXT,
 			chat_history_json     TEXT,
 			summary_error         TEXT,
@@ -80,7 +81,8 @@ func migratePlansCompiledColumns(ctx context.Context, exec libdbexec.Exec) error
 }
 
 // migratePlanStepSummaryColumns adds typed-handover columns (summary, chat history, summary error,
-// last failure summary) to plan_steps on databases created before they existed.
+// last failure summary), the failure class and the start time to plan_steps on databases
+// created before they existed.
 func migratePlanStepSummaryColumns(ctx context.Context, exec libdbexec.Exec) error {
 	stmts := []string{
 		`ALTER TABLE plan_steps ADD COLUMN summary TEXT`,
@@ -88,6 +90,7 @@ func migratePlanStepSummaryColumns(ctx context.Context, exec libdbexec.Exec) err
 		`ALTER TABLE plan_steps ADD COLUMN summary_error TEXT`,
 		`ALTER TABLE plan_steps ADD COLUMN last_failure_summary TEXT`,
 		`ALTER TABLE plan_steps ADD COLUMN failure_class VARCHAR(50)`,
+		`ALTER TABLE plan_steps ADD COLUMN started_at TIMESTAMP`,
 	}
 	for _, q := range stmts {
 		_, err := exec.ExecContext(ctx, q)
@@ -165,10 +165,10 @@ func (s *store) GetActivePlan(ctx context.Context) (*Plan, error) {
 func (s *store) ClaimNextPendingStep(ctx context.Context, planID string) (*PlanStep, error) {
 	var step PlanStep
 	var status string
-	var execAt sql.NullTime
+	var execAt, startedAt sql.NullTime
 	query := `
 		UPDATE plan_steps
-		SET status = 'running'
+		SET status = 'running', started_at = $2
 		WHERE id = (
 			SELECT id FROM plan_steps
 			WHERE plan_id = $1 AND status = 'pending'
@@ -177,7 +177,7 @@ func (s *store) ClaimNextPendingStep(ctx context.Context, planID string) (*PlanS
 			{{.Locking}}
 		)
 		AND status = 'pending'
-		RETURNING id, plan_id, ordinal, description, status, execution_result, executed_at`
+		RETURNING id, plan_id, ordinal, description, status, execution_result, executed_at, started_at`
 
 	locking := ""
 	if s.Exec.DriverName() == "postgres" {
@@ -185,7 +185,7 @@ func (s *store) ClaimNextPendingStep(ctx context.Context, planID string) (*PlanS
 	}
 	query = strings.Replace(query, "{{.Locking}}", locking, 1)
 
-	err := s.Exec.QueryRowContext(ctx, query, planID).Scan(&step.ID, &step.PlanID, &step.Ordinal, &step.Description, &status, &step.ExecutionResult, &execAt)
+	err := s.Exec.QueryRowContext(ctx, query, planID, time.Now().UTC()).Scan(&step.ID, &step.PlanID, &step.Ordinal, &step.Description, &status, &step.ExecutionResult, &execAt, &startedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -196,6 +196,9 @@ func (s *store) ClaimNextPendingStep(ctx context.Context, planID string) (*PlanS
 	if execAt.Valid {
 		step.ExecutedAt = execAt.Time
 	}
+	if startedAt.Valid {
+		step.StartedAt = startedAt.Time
+	}
 	return &step, nil
 }
 
@@ -263,10 +266,8 @@ func (s *store) CreatePlanSteps(ctx context.Context, steps ...*PlanStep) error {
 		return nil
 	}
 
-	valueStrings := make([]string, 0, len(steps))
-	valueArgs := make([]any, 0, len(steps)*7)
-
-	for i, step := range steps {
+	rows := make([][]any, 0, len(steps))
+	for _, step := range steps {
 		if step.Status == "" {
 			step.Status = StepStatusPending
 		}
@@ -274,19 +275,11 @@ func (s *store) CreatePlanSteps(ctx context.Context, steps ...*PlanStep) error {
 		if !step.ExecutedAt.IsZero() {
 			execAt = sql.NullTime{Time: step.ExecutedAt, Valid: true}
 		}
-
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-			i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7))
-		valueArgs = append(valueArgs, step.ID, step.PlanID, step.Ordinal, step.Description, string(step.Status), step.ExecutionResult, execAt)
+		rows = append(rows, []any{step.ID, step.PlanID, step.Ordinal, step.Description, string(step.Status), step.ExecutionResult, execAt})
 	}
 
-	stmt := fmt.Sprintf(`
-		INSERT INTO plan_steps (id, plan_id, ordinal, description, status, execution_result, executed_at)
-		VALUES %s`,
-		strings.Join(valueStrings, ","),
-	)
-
-	_, err := s.Exec.ExecContext(ctx, stmt, valueArgs...)
+	_, err := libdbexec.InsertRows(ctx, s.Exec, "plan_steps",
+		[]string{"id", "plan_id", "ordinal", "description", "status", "execution_result", "executed_at"}, rows)
 	if err != nil {
 		return fmt.Errorf("failed to create plan steps: %w", err)
 	}
@@ -296,7 +289,7 @@ func (s *store) CreatePlanSteps(ctx context.Context, steps ...*PlanStep) error {
 func (s *store) ListPlanSteps(ctx context.Context, planID string) ([]*PlanStep, error) {
 	rows, err := s.Exec.QueryContext(ctx, `
 		SELECT id, plan_id, ordinal, description, status, execution_result, executed_at,
-		       summary, chat_history_json, summary_error, last_failure_summary, failure_class
+		       summary, chat_history_json, summary_error, last_failure_summary, failure_class, started_at
 		FROM plan_steps
 		WHERE plan_id = $1
 		ORDER BY ordinal ASC`,
@@ -311,10 +304,10 @@ func (s *store) ListPlanSteps(ctx context.Context, planID string) ([]*PlanStep,
 	for rows.Next() {
 		var step PlanStep
 		var status string
-		var execAt sql.NullTime
+		var execAt, startedAt sql.NullTime
 		var summary, chatHist, summaryErr, lastFail, failureClass sql.NullString
 		if err := rows.Scan(&step.ID, &step.PlanID, &step.Ordinal, &step.Description, &status, &step.ExecutionResult, &execAt,
-			&summary, &chatHist, &summaryErr, &lastFail, &failureClass); err != nil {
+			&summary, &chatHist, &summaryErr, &lastFail, &failureClass, &startedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan plan step: %w", err)
 		}
 		step.Status = StepStatus(status)
@@ -336,6 +329,9 @@ func (s *store) ListPlanSteps(ctx context.Context, planID string) ([]*PlanStep,
 		if failureClass.Valid {
 			step.FailureClass = FailureClass(failureClass.String)
 		}
+		if startedAt.Valid {
+			step.StartedAt = startedAt.Time
+		}
 		steps = append(steps, &step)
 	}
 	if err := rows.Err(); err != nil {
@@ -410,15 +406,20 @@ func (s *store) UpdatePlanStepStatus(ctx context.Context, stepID string, status
 	now := time.Now().UTC()
 	execAt := sql.NullTime{Time: now, Valid: true}
 	// If it's being set back to pending (e.g. from retry), clear the execution time and result
+	query := `
+		UPDATE plan_steps
+		SET status = $2, execution_result = $3, executed_at = $4
+		WHERE id = $1`
 	if status == StepStatusPending {
 		execAt = sql.NullTime{Valid: false}
 		result = ""
+		query = `
+		UPDATE plan_steps
+		SET status = $2, execution_result = $3, executed_at = $4, started_at = NULL
+		WHERE id = $1`
 	}
 
-	res, err := s.Exec.ExecContext(ctx, `
-		UPDATE plan_steps
-		SET status = $2, execution_result = $3, executed_at = $4
-		WHERE id = $1`,
+	res, err := s.Exec.ExecContext(ctx, query,
 		stepID,
 		string(status),
 		result,
@@ -436,6 +437,39 @@ func (s *store) UpdatePlanStepStatus(ctx context.Context, stepID string, status
 	return checkRowsAffected(res)
 }
 
+func (s *store) ListStepDurations(ctx context.Context, limit int) ([]StepDuration, error) {
+	rows, err := s.Exec.QueryContext(ctx, `
+		SELECT s.description, s.started_at, s.executed_at
+		FROM plan_steps s
+		JOIN plans p ON p.id = s.plan_id
+		WHERE p.workspace_id = $1 AND s.status = 'completed'
+		  AND s.started_at IS NOT NULL AND s.executed_at IS NOT NULL
+		ORDER BY s.executed_at DESC
+		LIMIT $2`,
+		s.workspaceID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query step durations: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []StepDuration
+	for rows.Next() {
+		var description string
+		var startedAt, executedAt time.Time
+		if err := rows.Scan(&description, &startedAt, &executedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan step duration: %w", err)
+		}
+		if d := executedAt.Sub(startedAt); d >= 0 {
+			durations = append(durations, StepDuration{Description: description, Duration: d})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return durations, nil
+}
+
 // UpdatePlanStepSummary persists the typed summary JSON + raw executor chat history.
 // Called by the plan_summary persist tools when summarizer output validated successfully.
 func (s *store) UpdatePlanStepSummary(ctx context.Context, stepID string, summaryJSON, chatHistoryJSON string) error {
@@ -240,6 +240,35 @@ func TestUnit_UpdatePlanStepStatus_RetryResetsPending(t *testing.T) {
 	require.True(t, steps[0].ExecutedAt.IsZero())
 }
 
+func TestUnit_ListStepDurations(t *testing.T) {
+	ctx, db := SetupStore(t)
+	st := planstore.New(db.WithoutTransaction(), "")
+
+	plan := &planstore.Plan{ID: uuid.NewString(), Name: "p-" + uuid.NewString()[:8], Goal: "g"}
+	require.NoError(t, st.CreatePlan(ctx, plan))
+
+	steps := []*planstore.PlanStep{
+		{ID: uuid.NewString(), PlanID: plan.ID, Ordinal: 1, Description: "run the tests"},
+		{ID: uuid.NewString(), PlanID: plan.ID, Ordinal: 2, Description: "never claimed"},
+	}
+	require.NoError(t, st.CreatePlanSteps(ctx, steps...))
+	claimed, err := st.ClaimNextPendingStep(ctx, plan.ID)
+	require.NoError(t, err)
+	require.NoError(t, st.UpdatePlanStepStatus(ctx, claimed.ID, planstore.StepStatusCompleted, "ok"))
+	require.NoError(t, st.UpdatePlanStepStatus(ctx, steps[1].ID, planstore.StepStatusCompleted, "ok"))
+
+	durations, err := st.ListStepDurations(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, durations, 1, "steps completed without being claimed have no duration")
+	require.Equal(t, "run the tests", durations[0].Description)
+
+	// A retry clears the start time along with the result.
+	require.NoError(t, st.UpdatePlanStepStatus(ctx, claimed.ID, planstore.StepStatusPending, ""))
+	got, err := st.ListPlanSteps(ctx, plan.ID)
+	require.NoError(t, err)
+	require.True(t, got[0].StartedAt.IsZero())
+}
+
 func TestUnit_DeletePendingPlanSteps(t *testing.T) {
 	ctx, db := SetupStore(t)
 	st := planstore.New(db.WithoutTransaction(), "")
@@ -281,6 +310,7 @@ func TestUnit_ClaimNextPendingStep(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, steps[0].ID, claimed.ID)
 	require.Equal(t, planstore.StepStatusRunning, claimed.Status)
+	require.False(t, claimed.StartedAt.IsZero())
 
 	// Verify DB reflects running status.
 	all, err := st.ListPlanSteps(ctx, plan.ID)
@@ -73,6 +73,9 @@ type PlanStep struct {
 	Status          StepStatus `json:"status"`
 	ExecutionResult string     `json:"execution_result"`
 	ExecutedAt      time.Time  `json:"executed_at"` // Zero time if not executed
+	// StartedAt is when the step was claimed for execution. Zero time if it
+	// never ran or was reset to pending.
+	StartedAt time.Time `json:"started_at"`
 
 	// Summary is a JSON document (schema: outcome/summary/artifacts/handover_for_next/caveats)
 	// produced by the summarizer chain and persisted by the plan_summary persist tools.
@@ -137,6 +140,10 @@ type Store interface {
 	// failure on a step. Pass [FailureClassEmpty] to clear it (e.g. on retry).
 	SetPlanStepFailureClass(ctx context.Context, stepID string, class FailureClass) error
 
+	// ListStepDurations returns the durations of the most recently completed
+	// steps of the workspace, newest first, as input for [EstimateRemaining].
+	ListStepDurations(ctx context.Context, limit int) ([]StepDuration, error)
+
 	// Bulk operations for efficiency
 	// DeleteFinishedPlans removes all completed/archived plans; returns count.
 	DeleteFinishedPlans(ctx context.Context) (int, error)
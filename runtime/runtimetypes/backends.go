@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -20,14 +21,20 @@ func (s *store) CreateBackend(ctx context.Context, backend *Backend) error {
 	if backend.ID == "" {
 		backend.ID = uuid.New().String()
 	}
-	_, err := s.Exec.ExecContext(ctx, `
+	headersJSON, err := json.Marshal(orEmptyMap(backend.Headers))
+	if err != nil {
+		return fmt.Errorf("failed to marshal backend headers: %w", err)
+	}
+	_, err = s.Exec.ExecContext(ctx, `
 		INSERT INTO llm_backends
-		(id, name, base_url, type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`,
+		(id, name, base_url, type, api_key, headers_json, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
 		backend.ID,
 		backend.Name,
 		backend.BaseURL,
 		backend.Type,
+		backend.APIKey,
+		string(headersJSON),
 		backend.CreatedAt,
 		backend.UpdatedAt,
 	)
@@ -35,41 +42,40 @@ func (s *store) CreateBackend(ctx context.Context, backend *Backend) error {
 }
 
 func (s *store) GetBackend(ctx context.Context, id string) (*Backend, error) {
-	var backend Backend
-	err := s.Exec.QueryRowContext(ctx, `
-		SELECT id, name, base_url, type, created_at, updated_at
+	backend, err := scanBackend(s.Exec.QueryRowContext(ctx, `
+		SELECT `+backendColumns+`
 		FROM llm_backends
 		WHERE id = $1`,
 		id,
-	).Scan(
-		&backend.ID,
-		&backend.Name,
-		&backend.BaseURL,
-		&backend.Type,
-		&backend.CreatedAt,
-		&backend.UpdatedAt,
-	)
-
+	))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, libdb.ErrNotFound
 	}
-	return &backend, err
+	return backend, err
 }
 
 func (s *store) UpdateBackend(ctx context.Context, backend *Backend) error {
 	backend.UpdatedAt = time.Now().UTC()
+	headersJSON, err := json.Marshal(orEmptyMap(backend.Headers))
+	if err != nil {
+		return fmt.Errorf("failed to marshal backend headers: %w", err)
+	}
 
 	result, err := s.Exec.ExecContext(ctx, `
 		UPDATE llm_backends
 		SET name = $2,
 			base_url = $3,
 			type = $4,
-			updated_at = $5
+			api_key = $5,
+			headers_json = $6,
+			updated_at = $7
 		WHERE id = $1`,
 		backend.ID,
 		backend.Name,
 		backend.BaseURL,
 		backend.Type,
+		backend.APIKey,
+		string(headersJSON),
 		backend.UpdatedAt,
 	)
 
@@ -80,6 +86,11 @@ func (s *store) UpdateBackend(ctx context.Context, backend *Backend) error {
 	return checkRowsAffected(result)
 }
 
+// BackendSyncKeyPrefix prefixes the KV keys holding per-backend
+// reconciliation settings; the backend ID follows. DeleteBackend removes them
+// with the backend.
+const BackendSyncKeyPrefix = "backend-sync:"
+
 func (s *store) DeleteBackend(ctx context.Context, id string) error {
 	result, err := s.Exec.ExecContext(ctx, `
 		DELETE FROM llm_backends
@@ -90,13 +101,23 @@ func (s *store) DeleteBackend(ctx context.Context, id string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete backend: %w", err)
 	}
+	if err := checkRowsAffected(result); err != nil {
+		return err
+	}
 
-	return checkRowsAffected(result)
+	if _, err := s.Exec.ExecContext(ctx, `
+		DELETE FROM kv
+		WHERE key = $1 AND workspace_id = ''`,
+		BackendSyncKeyPrefix+id,
+	); err != nil {
+		return fmt.Errorf("failed to delete backend settings: %w", err)
+	}
+	return nil
 }
 
 func (s *store) ListAllBackends(ctx context.Context) ([]*Backend, error) {
 	rows, err := s.Exec.QueryContext(ctx, `
-        SELECT id, name, base_url, type, created_at, updated_at
+        SELECT `+backendColumns+`
         FROM llm_backends
         ORDER BY created_at DESC, id DESC;
     `)
@@ -107,18 +128,11 @@ func (s *store) ListAllBackends(ctx context.Context) ([]*Backend, error) {
 
 	backends := []*Backend{}
 	for rows.Next() {
-		var backend Backend
-		if err := rows.Scan(
-			&backend.ID,
-			&backend.Name,
-			&backend.BaseURL,
-			&backend.Type,
-			&backend.CreatedAt,
-			&backend.UpdatedAt,
-		); err != nil {
+		backend, err := scanBackend(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan backend: %w", err)
 		}
-		backends = append(backends, &backend)
+		backends = append(backends, backend)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -137,7 +151,7 @@ func (s *store) ListBackends(ctx context.Context, createdAtCursor *time.Time, li
 		return nil, ErrLimitParamExceeded
 	}
 	rows, err := s.Exec.QueryContext(ctx, `
-        SELECT id, name, base_url, type, created_at, updated_at
+        SELECT `+backendColumns+`
         FROM llm_backends
         WHERE created_at < $1
         ORDER BY created_at DESC, id DESC
@@ -150,18 +164,11 @@ func (s *store) ListBackends(ctx context.Context, createdAtCursor *time.Time, li
 
 	backends := []*Backend{}
 	for rows.Next() {
-		var backend Backend
-		if err := rows.Scan(
-			&backend.ID,
-			&backend.Name,
-			&backend.BaseURL,
-			&backend.Type,
-			&backend.CreatedAt,
-			&backend.UpdatedAt,
-		); err != nil {
+		backend, err := scanBackend(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan backend: %w", err)
 		}
-		backends = append(backends, &backend)
+		backends = append(backends, backend)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -172,25 +179,50 @@ func (s *store) ListBackends(ctx context.Context, createdAtCursor *time.Time, li
 }
 
 func (s *store) GetBackendByName(ctx context.Context, name string) (*Backend, error) {
-	var backend Backend
-	err := s.Exec.QueryRowContext(ctx, `
-		SELECT id, name, base_url, type, created_at, updated_at
+	backend, err := scanBackend(s.Exec.QueryRowContext(ctx, `
+		SELECT `+backendColumns+`
 		FROM llm_backends
 		WHERE name = $1`,
 		name,
-	).Scan(
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, libdb.ErrNotFound
+	}
+	return backend, err
+}
+
+// backendColumns are the llm_backends columns read by scanBackend, in order.
+const backendColumns = "id, name, base_url, type, api_key, headers_json, created_at, updated_at"
+
+// scanBackend reads a row of backendColumns.
+func scanBackend(row interface{ Scan(dest ...any) error }) (*Backend, error) {
+	var (
+		backend     Backend
+		apiKey      sql.NullString
+		headersJSON sql.NullString
+	)
+	if err := row.Scan(
 		&backend.ID,
 		&backend.Name,
 		&backend.BaseURL,
 		&backend.Type,
+		&apiKey,
+		&headersJSON,
 		&backend.CreatedAt,
 		&backend.UpdatedAt,
-	)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, libdb.ErrNotFound
+	); err != nil {
+		return nil, err
+	}
+	backend.APIKey = apiKey.String
+	if headersJSON.String != "" {
+		if err := json.Unmarshal([]byte(headersJSON.String), &backend.Headers); err != nil {
+			backend.Headers = nil
+		}
+	}
+	if len(backend.Headers) == 0 {
+		backend.Headers = nil
 	}
-	return &backend, err
+	return &backend, nil
 }
 
 func checkRowsAffected(result sql.Result) error {
@@ -0,0 +1,221 @@
+package runtimetypes
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Execution log statuses.
+const (
+	ExecutionSucceeded = "succeeded"
+	ExecutionFailed    = "failed"
+)
+
+// ExecutionLogEntry records one finished chain execution.
+type ExecutionLogEntry struct {
+	ID      string `json:"id" example:"e1a2b3c4-d5e6-f7a8-b9c0-d1e2f3a4b5c6"`
+	ChainID string `json:"chainId" example:"chat-chain"`
+	// InputDigest is the hex SHA-256 of the input, so runs of the same input
+	// can be matched without storing it.
+	InputDigest string `json:"inputDigest" example:"9e3a6c0d3b5e7f8a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a"`
+	// InputPreview is the start of the input.
+	InputPreview string    `json:"inputPreview" example:"summarize main.go"`
+	Status       string    `json:"status" example:"succeeded"`
+	Error        string    `json:"error,omitempty" example:"task answer: chat failed: context deadline exceeded"`
+	Steps        int       `json:"steps" example:"4"`
+	InputTokens  int64     `json:"inputTokens" example:"1200"`
+	OutputTokens int64     `json:"outputTokens" example:"350"`
+	Cost         float64   `json:"cost" example:"0.0065"`
+	StartedAt    time.Time `json:"startedAt" example:"2023-11-15T14:30:00Z"`
+	FinishedAt   time.Time `json:"finishedAt" example:"2023-11-15T14:30:45Z"`
+	// PromptVariants lists the prompt variants the execution drew, one per
+	// task visit, in execution order.
+	PromptVariants []ExecutionPromptVariant `json:"promptVariants,omitempty"`
+}
+
+// ExecutionPromptVariant records the prompt variant a task drew and how the
+// task ended.
+type ExecutionPromptVariant struct {
+	TaskID  string `json:"taskId" example:"answer"`
+	Variant string `json:"variant" example:"concise"`
+	// Transition is the transition value of the task's last attempt, e.g.
+	// "ok" or a classification label, or "failed".
+	Transition string `json:"transition" example:"ok"`
+}
+
+// Duration returns how long the execution ran.
+func (e *ExecutionLogEntry) Duration() time.Duration {
+	return e.FinishedAt.Sub(e.StartedAt)
+}
+
+// ExecutionLogFilter selects entries for ListExecutionLog.
+type ExecutionLogFilter struct {
+	// After returns only entries that finished after this time.
+	After time.Time
+	// ChainID returns only executions of this chain when set.
+	ChainID string
+	// FailedOnly returns only failed executions.
+	FailedOnly bool
+	// Limit caps the number of entries, keeping the latest. Zero means MAXLIMIT.
+	Limit int
+}
+
+// AppendExecutionLog stores a finished execution. ID is generated when empty.
+func (s *store) AppendExecutionLog(ctx context.Context, entry *ExecutionLogEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.NewString()
+	}
+	entry.StartedAt = entry.StartedAt.UTC()
+	entry.FinishedAt = entry.FinishedAt.UTC()
+	var variants string
+	if len(entry.PromptVariants) > 0 {
+		data, err := json.Marshal(entry.PromptVariants)
+		if err != nil {
+			return fmt.Errorf("failed to marshal prompt variants: %w", err)
+		}
+		variants = string(data)
+	}
+	_, err := s.Exec.ExecContext(ctx, `
+		INSERT INTO execution_log
+		(id, chain_id, input_digest, input_preview, status, error, steps,
+			input_tokens, output_tokens, cost, started_at, finished_at, prompt_variants)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		entry.ID, entry.ChainID, entry.InputDigest, entry.InputPreview, entry.Status, entry.Error, entry.Steps,
+		entry.InputTokens, entry.OutputTokens, entry.Cost, entry.StartedAt, entry.FinishedAt, variants,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append execution log: %w", err)
+	}
+	return nil
+}
+
+// ListExecutionLog returns the latest entries matching filter, oldest first.
+func (s *store) ListExecutionLog(ctx context.Context, filter ExecutionLogFilter) ([]*ExecutionLogEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = MAXLIMIT
+	}
+	if limit > MAXLIMIT {
+		return nil, ErrLimitParamExceeded
+	}
+	conds := []string{"finished_at > $1"}
+	args := []any{filter.After.UTC()}
+	if filter.ChainID != "" {
+		args = append(args, filter.ChainID)
+		conds = append(conds, fmt.Sprintf("chain_id = $%d", len(args)))
+	}
+	if filter.FailedOnly {
+		args = append(args, ExecutionFailed)
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	args = append(args, limit)
+	rows, err := s.Exec.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, chain_id, input_digest, input_preview, status, error, steps,
+			input_tokens, output_tokens, cost, started_at, finished_at, prompt_variants
+		FROM execution_log
+		WHERE %s
+		ORDER BY finished_at DESC, id DESC
+		LIMIT $%d`, strings.Join(conds, " AND "), len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query execution log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*ExecutionLogEntry
+	for rows.Next() {
+		var e ExecutionLogEntry
+		var variants string
+		if err := rows.Scan(
+			&e.ID, &e.ChainID, &e.InputDigest, &e.InputPreview, &e.Status, &e.Error, &e.Steps,
+			&e.InputTokens, &e.OutputTokens, &e.Cost, &e.StartedAt, &e.FinishedAt, &variants,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan execution log: %w", err)
+		}
+		if variants != "" {
+			if err := json.Unmarshal([]byte(variants), &e.PromptVariants); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal prompt variants: %w", err)
+			}
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// DeleteExecutionLogBefore drops entries that finished before cutoff.
+func (s *store) DeleteExecutionLogBefore(ctx context.Context, cutoff time.Time) error {
+	_, err := s.Exec.ExecContext(ctx, `
+		DELETE FROM execution_log
+		WHERE finished_at < $1`,
+		cutoff.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete execution log: %w", err)
+	}
+	return nil
+}
+
+// PromptVariantStats compares the outcomes of one prompt variant of a task.
+type PromptVariantStats struct {
+	ChainID string `json:"chainId" example:"support-chain"`
+	TaskID  string `json:"taskId" example:"answer"`
+	Variant string `json:"variant" example:"concise"`
+	// Runs is the number of task visits that drew the variant.
+	Runs int `json:"runs" example:"120"`
+	// Succeeded is the number of those visits whose execution succeeded.
+	Succeeded int `json:"succeeded" example:"114"`
+	// Transitions counts the visits per transition value of the task.
+	Transitions map[string]int `json:"transitions" example:"{\"ok\":110,\"failed\":10}"`
+}
+
+// SuccessRate returns the share of runs whose execution succeeded.
+func (s *PromptVariantStats) SuccessRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) / float64(s.Runs)
+}
+
+// SummarizePromptVariants aggregates the prompt variants of entries per chain,
+// task and variant, sorted by chain, task and variant.
+func SummarizePromptVariants(entries []*ExecutionLogEntry) []*PromptVariantStats {
+	type key struct{ chain, task, variant string }
+	byKey := map[key]*PromptVariantStats{}
+	for _, e := range entries {
+		for _, pv := range e.PromptVariants {
+			k := key{e.ChainID, pv.TaskID, pv.Variant}
+			st, ok := byKey[k]
+			if !ok {
+				st = &PromptVariantStats{ChainID: e.ChainID, TaskID: pv.TaskID, Variant: pv.Variant, Transitions: map[string]int{}}
+				byKey[k] = st
+			}
+			st.Runs++
+			if e.Status == ExecutionSucceeded {
+				st.Succeeded++
+			}
+			st.Transitions[pv.Transition]++
+		}
+	}
+	stats := slices.Collect(maps.Values(byKey))
+	slices.SortFunc(stats, func(a, b *PromptVariantStats) int {
+		return cmp.Or(
+			strings.Compare(a.ChainID, b.ChainID),
+			strings.Compare(a.TaskID, b.TaskID),
+			strings.Compare(a.Variant, b.Variant),
+		)
+	})
+	return stats
+}
@@ -0,0 +1,90 @@
+package runtimetypes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ExecutionLog_AppendAndList(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	for i, status := range []string{runtimetypes.ExecutionSucceeded, runtimetypes.ExecutionFailed, runtimetypes.ExecutionSucceeded} {
+		require.NoError(t, s.AppendExecutionLog(ctx, &runtimetypes.ExecutionLogEntry{
+			ChainID:     "chain",
+			InputDigest: "digest",
+			Status:      status,
+			Steps:       i + 1,
+			StartedAt:   now.Add(time.Duration(i)*time.Minute - time.Second),
+			FinishedAt:  now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	all, err := s.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, 1, all[0].Steps, "oldest first")
+	require.Equal(t, time.Second, all[0].Duration())
+
+	latest, err := s.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	require.Equal(t, 2, latest[0].Steps)
+
+	failed, err := s.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{FailedOnly: true})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, runtimetypes.ExecutionFailed, failed[0].Status)
+
+	newer, err := s.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{After: all[1].FinishedAt})
+	require.NoError(t, err)
+	require.Len(t, newer, 1)
+	require.Equal(t, 3, newer[0].Steps)
+
+	variants := []runtimetypes.ExecutionPromptVariant{{TaskID: "answer", Variant: "short", Transition: "ok"}}
+	require.NoError(t, s.AppendExecutionLog(ctx, &runtimetypes.ExecutionLogEntry{
+		ChainID:        "other",
+		Status:         runtimetypes.ExecutionSucceeded,
+		StartedAt:      now,
+		FinishedAt:     now.Add(time.Hour),
+		PromptVariants: variants,
+	}))
+	other, err := s.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{ChainID: "other"})
+	require.NoError(t, err)
+	require.Len(t, other, 1)
+	require.Equal(t, variants, other[0].PromptVariants)
+
+	require.NoError(t, s.DeleteExecutionLogBefore(ctx, now.Add(time.Minute)))
+	rest, err := s.ListExecutionLog(ctx, runtimetypes.ExecutionLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, rest, 3)
+}
+
+func TestUnit_SummarizePromptVariants(t *testing.T) {
+	run := func(status string, variants ...runtimetypes.ExecutionPromptVariant) *runtimetypes.ExecutionLogEntry {
+		return &runtimetypes.ExecutionLogEntry{ChainID: "chain", Status: status, PromptVariants: variants}
+	}
+	short := func(transition string) runtimetypes.ExecutionPromptVariant {
+		return runtimetypes.ExecutionPromptVariant{TaskID: "answer", Variant: "short", Transition: transition}
+	}
+	long := runtimetypes.ExecutionPromptVariant{TaskID: "answer", Variant: "long", Transition: "ok"}
+
+	stats := runtimetypes.SummarizePromptVariants([]*runtimetypes.ExecutionLogEntry{
+		run(runtimetypes.ExecutionSucceeded, short("ok")),
+		run(runtimetypes.ExecutionFailed, short("failed")),
+		run(runtimetypes.ExecutionSucceeded, short("ok"), short("retry")),
+		run(runtimetypes.ExecutionSucceeded, long),
+		run(runtimetypes.ExecutionSucceeded),
+	})
+	require.Len(t, stats, 2)
+	require.Equal(t, "long", stats[0].Variant)
+	require.Equal(t, 1, stats[0].Runs)
+	require.Equal(t, "short", stats[1].Variant)
+	require.Equal(t, 4, stats[1].Runs)
+	require.Equal(t, 3, stats[1].Succeeded)
+	require.InDelta(t, 0.75, stats[1].SuccessRate(), 1e-9)
+	require.Equal(t, map[string]int{"ok": 2, "failed": 1, "retry": 1}, stats[1].Transitions)
+}
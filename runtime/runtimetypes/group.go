@@ -209,7 +209,7 @@ func (s *store) RemoveBackendFromAffinityGroup(ctx context.Context, groupID, bac
 
 func (s *store) ListBackendsForAffinityGroup(ctx context.Context, groupID string) ([]*Backend, error) {
 	rows, err := s.Exec.QueryContext(ctx, `
-		SELECT b.id, b.name, b.base_url, b.type, b.created_at, b.updated_at
+		SELECT b.id, b.name, b.base_url, b.type, b.api_key, b.headers_json, b.created_at, b.updated_at
 		FROM llm_backends b
 		INNER JOIN llm_affinity_group_backend_assignments a ON b.id = a.backend_id
 		WHERE a.group_id = $1
@@ -221,11 +221,11 @@ func (s *store) ListBackendsForAffinityGroup(ctx context.Context, groupID string
 
 	var backends []*Backend
 	for rows.Next() {
-		var b Backend
-		if err := rows.Scan(&b.ID, &b.Name, &b.BaseURL, &b.Type, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		b, err := scanBackend(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan backend: %w", err)
 		}
-		backends = append(backends, &b)
+		backends = append(backends, b)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -2,10 +2,9 @@ package runtimetypes
 
 import (
 	"context"
-	"fmt"
-	"strings"
 	"time"
 
+	libdb "github.com/contenox/contenox/libdbexec"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 )
@@ -40,22 +39,10 @@ func (s *store) AppendJobs(ctx context.Context, jobs ...*Job) error {
 		return ErrAppendLimitExceeded
 	}
 	now := time.Now().UTC()
-	valueStrings := make([]string, 0, len(jobs))
-	valueArgs := make([]interface{}, 0, len(jobs)*7)
-
-	for i, job := range jobs {
+	rows := make([][]any, 0, len(jobs))
+	for _, job := range jobs {
 		job.CreatedAt = now
-
-		// Build placeholders like ($1, $2, ..., $7)
-		startIdx := i*7 + 1
-		placeholders := make([]string, 7)
-		for j := 0; j < 7; j++ {
-			placeholders[j] = fmt.Sprintf("$%d", startIdx+j)
-		}
-		valueStrings = append(valueStrings, "("+strings.Join(placeholders, ", ")+")")
-
-		// Append values in the same order as columns
-		valueArgs = append(valueArgs,
+		rows = append(rows, []any{
 			job.ID,
 			job.TaskType,
 			job.Payload,
@@ -63,17 +50,11 @@ func (s *store) AppendJobs(ctx context.Context, jobs ...*Job) error {
 			job.ValidUntil,
 			job.RetryCount,
 			job.CreatedAt,
-		)
+		})
 	}
 
-	stmt := fmt.Sprintf(`
-        INSERT INTO job_queue_v2
-        (id, task_type, payload, scheduled_for, valid_until, retry_count, created_at)
-        VALUES %s`,
-		strings.Join(valueStrings, ","),
-	)
-
-	_, err := s.Exec.ExecContext(ctx, stmt, valueArgs...)
+	_, err := libdb.InsertRows(ctx, s.Exec, "job_queue_v2",
+		[]string{"id", "task_type", "payload", "scheduled_for", "valid_until", "retry_count", "created_at"}, rows)
 	return err
 }
 
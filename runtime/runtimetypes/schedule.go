@@ -0,0 +1,376 @@
+package runtimetypes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/google/uuid"
+)
+
+// ChangeKind is the mutation a ScheduledChange applies.
+type ChangeKind string
+
+const (
+	// ChangeSetKV sets the KV entry Target to the JSON document Value. When
+	// WorkspaceID is set the workspace-scoped entry is written.
+	ChangeSetKV ChangeKind = "set-kv"
+	// ChangeDeleteKV deletes the KV entry Target.
+	ChangeDeleteKV ChangeKind = "delete-kv"
+	// ChangeDeleteBackend deletes the backend with ID Target. Value may hold
+	// the backend name for display.
+	ChangeDeleteBackend ChangeKind = "delete-backend"
+)
+
+// ChangeStatus is the lifecycle state of a ScheduledChange.
+type ChangeStatus string
+
+const (
+	ChangePending   ChangeStatus = "pending"
+	ChangeApplied   ChangeStatus = "applied"
+	ChangeCancelled ChangeStatus = "cancelled"
+	ChangeFailed    ChangeStatus = "failed"
+)
+
+// ScheduledChange is a configuration mutation deferred until ApplyAt.
+type ScheduledChange struct {
+	ID          string       `json:"id" example:"3f1c2b7e-9a4d-4e8b-8c6f-1d2e3f4a5b6c"`
+	Kind        ChangeKind   `json:"kind" example:"set-kv"`
+	Target      string       `json:"target" example:"cli.default-model"`
+	Value       string       `json:"value,omitempty" example:"\"qwen2.5:7b\""`
+	WorkspaceID string       `json:"workspaceId,omitempty"`
+	ApplyAt     time.Time    `json:"applyAt" example:"2024-01-20T02:00:00Z"`
+	Status      ChangeStatus `json:"status" example:"pending"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt" example:"2024-01-15T10:00:00Z"`
+	UpdatedAt   time.Time    `json:"updatedAt" example:"2024-01-15T10:00:00Z"`
+}
+
+func (s *store) CreateScheduledChange(ctx context.Context, change *ScheduledChange) error {
+	switch change.Kind {
+	case ChangeSetKV, ChangeDeleteKV, ChangeDeleteBackend:
+	default:
+		return fmt.Errorf("unknown scheduled change kind %q", change.Kind)
+	}
+	if change.Target == "" {
+		return fmt.Errorf("scheduled change target is required")
+	}
+	now := time.Now().UTC()
+	if change.ID == "" {
+		change.ID = uuid.NewString()
+	}
+	change.ApplyAt = change.ApplyAt.UTC()
+	change.Status = ChangePending
+	change.Error = ""
+	change.CreatedAt = now
+	change.UpdatedAt = now
+	_, err := s.Exec.ExecContext(ctx, `
+		INSERT INTO scheduled_changes
+		(id, kind, target, value, workspace_id, apply_at, status, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		change.ID, change.Kind, change.Target, change.Value, change.WorkspaceID,
+		change.ApplyAt, change.Status, change.Error, change.CreatedAt, change.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled change: %w", err)
+	}
+	return nil
+}
+
+const scheduledChangeColumns = `id, kind, target, value, workspace_id, apply_at, status, error, created_at, updated_at`
+
+func scanScheduledChange(scan func(dest ...any) error) (*ScheduledChange, error) {
+	var c ScheduledChange
+	if err := scan(&c.ID, &c.Kind, &c.Target, &c.Value, &c.WorkspaceID,
+		&c.ApplyAt, &c.Status, &c.Error, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *store) GetScheduledChange(ctx context.Context, id string) (*ScheduledChange, error) {
+	change, err := scanScheduledChange(s.Exec.QueryRowContext(ctx, `
+		SELECT `+scheduledChangeColumns+`
+		FROM scheduled_changes
+		WHERE id = $1`,
+		id,
+	).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, libdb.ErrNotFound
+	}
+	return change, err
+}
+
+// ListScheduledChanges returns the changes with the given status, or all
+// changes when status is empty, in the order they are due.
+func (s *store) ListScheduledChanges(ctx context.Context, status ChangeStatus) ([]*ScheduledChange, error) {
+	if status == "" {
+		return s.queryScheduledChanges(ctx, `
+		SELECT `+scheduledChangeColumns+`
+		FROM scheduled_changes
+		ORDER BY apply_at ASC, id ASC`)
+	}
+	return s.queryScheduledChanges(ctx, `
+		SELECT `+scheduledChangeColumns+`
+		FROM scheduled_changes
+		WHERE status = $1
+		ORDER BY apply_at ASC, id ASC`,
+		status,
+	)
+}
+
+// ListDueScheduledChanges returns the pending changes whose ApplyAt is not
+// after now, in the order they are due.
+func (s *store) ListDueScheduledChanges(ctx context.Context, now time.Time) ([]*ScheduledChange, error) {
+	return s.queryScheduledChanges(ctx, `
+		SELECT `+scheduledChangeColumns+`
+		FROM scheduled_changes
+		WHERE status = $1 AND apply_at <= $2
+		ORDER BY apply_at ASC, id ASC`,
+		ChangePending, now.UTC(),
+	)
+}
+
+func (s *store) queryScheduledChanges(ctx context.Context, query string, args ...any) ([]*ScheduledChange, error) {
+	rows, err := s.Exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*ScheduledChange{}
+	for rows.Next() {
+		change, err := scanScheduledChange(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return changes, nil
+}
+
+// CancelScheduledChange cancels a pending change. It returns
+// libdb.ErrNotFound when no pending change with id exists.
+func (s *store) CancelScheduledChange(ctx context.Context, id string) error {
+	return s.finishScheduledChange(ctx, id, ChangeCancelled, "")
+}
+
+// finishScheduledChange moves a pending change to its final status.
+func (s *store) finishScheduledChange(ctx context.Context, id string, status ChangeStatus, errMsg string) error {
+	result, err := s.Exec.ExecContext(ctx, `
+		UPDATE scheduled_changes
+		SET status = $2, error = $3, updated_at = $4
+		WHERE id = $1 AND status = $5`,
+		id, status, errMsg, time.Now().UTC(), ChangePending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled change: %w", err)
+	}
+	return checkRowsAffected(result)
+}
+
+// ChangeWindow restricts when scheduled changes may be applied, e.g. to a
+// maintenance window on weekend nights. Due changes outside the window stay
+// pending until it opens.
+type ChangeWindow struct {
+	// Days the window opens on. Empty means every day.
+	Days []time.Weekday
+	// Start and End are offsets from midnight. A window whose End is not after
+	// Start spans midnight and closes on the following day.
+	Start, End time.Duration
+	// Location the window is evaluated in. Nil means time.Local.
+	Location *time.Location
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseChangeWindow parses a window such as "02:00-06:00" or
+// "sat,sun 22:00-04:00". Day ranges like "mon-fri" are accepted.
+func ParseChangeWindow(spec string) (*ChangeWindow, error) {
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("change window %q: want [days] HH:MM-HH:MM", spec)
+	}
+	w := &ChangeWindow{}
+	if len(fields) == 2 {
+		for _, part := range strings.Split(fields[0], ",") {
+			from, to, isRange := strings.Cut(part, "-")
+			first, ok := weekdayNames[from]
+			last, ok2 := weekdayNames[to]
+			if !ok || (isRange && !ok2) {
+				return nil, fmt.Errorf("change window %q: unknown day %q", spec, part)
+			}
+			if !isRange {
+				last = first
+			}
+			for d := first; ; d = (d + 1) % 7 {
+				if !slices.Contains(w.Days, d) {
+					w.Days = append(w.Days, d)
+				}
+				if d == last {
+					break
+				}
+			}
+		}
+	}
+	from, to, ok := strings.Cut(fields[len(fields)-1], "-")
+	if !ok {
+		return nil, fmt.Errorf("change window %q: want HH:MM-HH:MM", spec)
+	}
+	var err error
+	if w.Start, err = parseClock(from); err != nil {
+		return nil, fmt.Errorf("change window %q: %w", spec, err)
+	}
+	if w.End, err = parseClock(to); err != nil {
+		return nil, fmt.Errorf("change window %q: %w", spec, err)
+	}
+	return w, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if !ok || err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Contains reports whether t falls into the window. A nil window is always
+// open.
+func (w *ChangeWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	loc := w.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	offset := t.Sub(midnight)
+	if w.End > w.Start {
+		return w.opensOn(t.Weekday()) && offset >= w.Start && offset < w.End
+	}
+	// Overnight window: the evening part belongs to today's opening, the
+	// morning part to yesterday's.
+	if offset >= w.Start {
+		return w.opensOn(t.Weekday())
+	}
+	return offset < w.End && w.opensOn((t.Weekday()+6)%7)
+}
+
+func (w *ChangeWindow) opensOn(d time.Weekday) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, d)
+}
+
+// ApplyDueChanges applies every pending change that is due at now, provided
+// now falls into window. Each applied or failed change is passed to notify
+// when it is not nil. A failing change is marked failed and does not stop
+// the others; the returned error only reports that the due changes could not
+// be listed.
+func ApplyDueChanges(ctx context.Context, db libdb.DBManager, now time.Time, window *ChangeWindow, notify func(*ScheduledChange)) ([]*ScheduledChange, error) {
+	if !window.Contains(now) {
+		return nil, nil
+	}
+	due, err := New(db.WithoutTransaction()).ListDueScheduledChanges(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	done := make([]*ScheduledChange, 0, len(due))
+	for _, change := range due {
+		applyErr := applyScheduledChange(ctx, db, change)
+		if errors.Is(applyErr, errChangeNotPending) {
+			continue
+		}
+		if applyErr != nil {
+			change.Status, change.Error = ChangeFailed, applyErr.Error()
+			if err := (&store{db.WithoutTransaction()}).finishScheduledChange(ctx, change.ID, ChangeFailed, change.Error); err != nil {
+				log.Printf("scheduled change %s: failed to record failure: %v", change.ID, err)
+				continue
+			}
+		} else {
+			change.Status = ChangeApplied
+		}
+		done = append(done, change)
+		if notify != nil {
+			notify(change)
+		}
+	}
+	return done, nil
+}
+
+// errChangeNotPending reports a due change that was cancelled or applied by
+// another process after it was listed.
+var errChangeNotPending = errors.New("scheduled change is no longer pending")
+
+// applyScheduledChange performs the mutation and marks the change applied in
+// one transaction, so a change cancelled concurrently is never applied.
+func applyScheduledChange(ctx context.Context, db libdb.DBManager, change *ScheduledChange) error {
+	tx, commit, release, err := db.WithTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer release()
+	s := &store{tx}
+	if err := s.finishScheduledChange(ctx, change.ID, ChangeApplied, ""); errors.Is(err, libdb.ErrNotFound) {
+		return errChangeNotPending
+	} else if err != nil {
+		return err
+	}
+	switch change.Kind {
+	case ChangeSetKV:
+		if change.WorkspaceID != "" {
+			err = s.SetWorkspaceKV(ctx, change.WorkspaceID, change.Target, []byte(change.Value))
+		} else {
+			err = s.SetKV(ctx, change.Target, []byte(change.Value))
+		}
+	case ChangeDeleteKV:
+		if change.WorkspaceID != "" {
+			err = s.DeleteWorkspaceKV(ctx, change.WorkspaceID, change.Target)
+		} else {
+			err = s.DeleteKV(ctx, change.Target)
+		}
+	case ChangeDeleteBackend:
+		err = s.DeleteBackend(ctx, change.Target)
+	default:
+		err = fmt.Errorf("unknown scheduled change kind %q", change.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	return commit(ctx)
+}
+
+// RunScheduledChanges applies due changes every interval until ctx is done.
+// Changes that fell due while no process was running are applied on the
+// first tick, which happens immediately. window is called on every tick, so
+// a change window configured meanwhile applies from the next one.
+func RunScheduledChanges(ctx context.Context, db libdb.DBManager, interval time.Duration, window func() *ChangeWindow, notify func(*ScheduledChange)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := ApplyDueChanges(ctx, db, time.Now(), window(), notify); err != nil && ctx.Err() == nil {
+			log.Printf("scheduled changes: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package runtimetypes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ChangeWindow(t *testing.T) {
+	w, err := runtimetypes.ParseChangeWindow("sat,sun 22:00-04:00")
+	require.NoError(t, err)
+	w.Location = time.UTC
+	require.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, w.Days)
+
+	at := func(day, clock string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", day+" "+clock)
+		require.NoError(t, err)
+		return ts
+	}
+	// 2024-06-08 is a Saturday.
+	require.True(t, w.Contains(at("2024-06-08", "23:30")))
+	require.True(t, w.Contains(at("2024-06-09", "03:59")), "morning after Saturday night")
+	require.True(t, w.Contains(at("2024-06-10", "01:00")), "morning after Sunday night")
+	require.False(t, w.Contains(at("2024-06-08", "03:00")), "morning after Friday night")
+	require.False(t, w.Contains(at("2024-06-08", "12:00")))
+
+	w, err = runtimetypes.ParseChangeWindow("mon-fri 02:00-06:00")
+	require.NoError(t, err)
+	w.Location = time.UTC
+	require.Len(t, w.Days, 5)
+	require.True(t, w.Contains(at("2024-06-07", "02:00")))
+	require.False(t, w.Contains(at("2024-06-07", "06:00")))
+	require.False(t, w.Contains(at("2024-06-08", "03:00")))
+
+	var always *runtimetypes.ChangeWindow
+	require.True(t, always.Contains(time.Now()))
+
+	for _, bad := range []string{"", "02:00", "sat 02:00", "funday 02:00-03:00", "25:00-26:00", "a b c"} {
+		_, err := runtimetypes.ParseChangeWindow(bad)
+		require.Error(t, err, bad)
+	}
+}
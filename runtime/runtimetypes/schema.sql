@@ -27,11 +27,15 @@ CREATE TABLE IF NOT EXISTS llm_backends (
     name VARCHAR(512) NOT NULL UNIQUE,
     base_url VARCHAR(512) NOT NULL,
     type VARCHAR(512) NOT NULL,
+    api_key TEXT NOT NULL DEFAULT '',
+    headers_json TEXT NOT NULL DEFAULT '{}',
 
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
     UNIQUE(type, base_url)
 );
+ALTER TABLE llm_backends ADD COLUMN IF NOT EXISTS api_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE llm_backends ADD COLUMN IF NOT EXISTS headers_json TEXT NOT NULL DEFAULT '{}';
 
 CREATE TABLE IF NOT EXISTS llm_affinity_group_backend_assignments (
     group_id VARCHAR(255) NOT NULL REFERENCES llm_affinity_group(id) ON DELETE CASCADE,
@@ -139,6 +143,17 @@ CREATE INDEX IF NOT EXISTS idx_messages_idx_id ON messages (idx_id);
 CREATE INDEX IF NOT EXISTS idx_messages_added_at ON messages (added_at);
 CREATE INDEX IF NOT EXISTS idx_message_indices_identity ON message_indices (identity);
 
+-- message_feedback: user ratings of assistant messages, exported as preference
+-- data. See chatservice.ExportPreferences.
+CREATE TABLE IF NOT EXISTS message_feedback (
+    message_id VARCHAR(255) NOT NULL,
+    idx_id VARCHAR(255) NOT NULL REFERENCES message_indices(id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (message_id, idx_id)
+);
+
 CREATE TABLE IF NOT EXISTS mcp_servers (
     id                      VARCHAR(255) PRIMARY KEY,
     name                    VARCHAR(255) NOT NULL UNIQUE,
@@ -196,6 +211,7 @@ ALTER TABLE plan_steps ADD COLUMN IF NOT EXISTS summary              TEXT;
 ALTER TABLE plan_steps ADD COLUMN IF NOT EXISTS chat_history_json    TEXT;
 ALTER TABLE plan_steps ADD COLUMN IF NOT EXISTS summary_error        TEXT;
 ALTER TABLE plan_steps ADD COLUMN IF NOT EXISTS last_failure_summary TEXT;
+ALTER TABLE plan_steps ADD COLUMN IF NOT EXISTS started_at           TIMESTAMP;
 
 CREATE TABLE IF NOT EXISTS llm_model_registry (
     id          VARCHAR(255) PRIMARY KEY,
@@ -207,3 +223,66 @@ CREATE TABLE IF NOT EXISTS llm_model_registry (
 );
 CREATE INDEX IF NOT EXISTS idx_llm_model_registry_created_at ON llm_model_registry(created_at);
 
+CREATE TABLE IF NOT EXISTS llm_model_usage (
+    backend_id       VARCHAR(255) NOT NULL,
+    model_name       VARCHAR(512) NOT NULL,
+    bucket_start     TIMESTAMP    NOT NULL,
+    request_count    BIGINT       NOT NULL DEFAULT 0,
+    error_count      BIGINT       NOT NULL DEFAULT 0,
+    total_latency_ms BIGINT       NOT NULL DEFAULT 0,
+    updated_at       TIMESTAMP    NOT NULL,
+    PRIMARY KEY (backend_id, model_name, bucket_start)
+);
+CREATE INDEX IF NOT EXISTS idx_llm_model_usage_bucket_start ON llm_model_usage(bucket_start);
+
+CREATE TABLE IF NOT EXISTS llm_model_cost (
+    backend_id       VARCHAR(255)     NOT NULL,
+    model_name       VARCHAR(512)     NOT NULL,
+    bucket_start     TIMESTAMP        NOT NULL,
+    call_count       BIGINT           NOT NULL DEFAULT 0,
+    input_tokens     BIGINT           NOT NULL DEFAULT 0,
+    output_tokens    BIGINT           NOT NULL DEFAULT 0,
+    cost             DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at       TIMESTAMP        NOT NULL,
+    PRIMARY KEY (backend_id, model_name, bucket_start)
+);
+CREATE INDEX IF NOT EXISTS idx_llm_model_cost_bucket_start ON llm_model_cost(bucket_start);
+
+CREATE TABLE IF NOT EXISTS execution_log (
+    id            VARCHAR(255)     PRIMARY KEY,
+    chain_id      VARCHAR(255)     NOT NULL DEFAULT '',
+    input_digest  VARCHAR(64)      NOT NULL DEFAULT '',
+    input_preview TEXT             NOT NULL DEFAULT '',
+    status        VARCHAR(50)      NOT NULL,
+    error         TEXT             NOT NULL DEFAULT '',
+    steps         INTEGER          NOT NULL DEFAULT 0,
+    input_tokens  BIGINT           NOT NULL DEFAULT 0,
+    output_tokens BIGINT           NOT NULL DEFAULT 0,
+    cost          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    started_at    TIMESTAMP        NOT NULL,
+    finished_at   TIMESTAMP        NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_execution_log_finished_at ON execution_log(finished_at);
+ALTER TABLE execution_log ADD COLUMN IF NOT EXISTS prompt_variants TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS scheduled_changes (
+    id           VARCHAR(255) PRIMARY KEY,
+    kind         VARCHAR(50)  NOT NULL,
+    target       VARCHAR(512) NOT NULL,
+    value        TEXT         NOT NULL DEFAULT '',
+    workspace_id VARCHAR(255) NOT NULL DEFAULT '',
+    apply_at     TIMESTAMP    NOT NULL,
+    status       VARCHAR(50)  NOT NULL DEFAULT 'pending',
+    error        TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMP    NOT NULL,
+    updated_at   TIMESTAMP    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_status_apply_at ON scheduled_changes(status, apply_at);
+
+CREATE TABLE IF NOT EXISTS session_locks (
+    session_id  VARCHAR(255) PRIMARY KEY,
+    holder      VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP    NOT NULL,
+    expires_at  TIMESTAMP    NOT NULL
+);
+
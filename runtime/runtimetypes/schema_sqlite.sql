@@ -29,6 +29,8 @@ CREATE TABLE IF NOT EXISTS llm_backends (
     name VARCHAR(512) NOT NULL UNIQUE,
     base_url VARCHAR(512) NOT NULL,
     type VARCHAR(512) NOT NULL,
+    api_key TEXT NOT NULL DEFAULT '',
+    headers_json TEXT NOT NULL DEFAULT '{}',
 
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
@@ -141,6 +143,17 @@ CREATE INDEX IF NOT EXISTS idx_messages_idx_id ON messages (idx_id);
 CREATE INDEX IF NOT EXISTS idx_messages_added_at ON messages (added_at);
 CREATE INDEX IF NOT EXISTS idx_message_indices_identity ON message_indices (identity);
 
+-- message_feedback: user ratings of assistant messages, exported as preference
+-- data. See chatservice.ExportPreferences.
+CREATE TABLE IF NOT EXISTS message_feedback (
+    message_id VARCHAR(255) NOT NULL,
+    idx_id VARCHAR(255) NOT NULL REFERENCES message_indices(id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (message_id, idx_id)
+);
+
 CREATE INDEX IF NOT EXISTS idx_functions_created_at ON functions(created_at);
 CREATE INDEX IF NOT EXISTS idx_event_triggers_created_at ON event_triggers(created_at);
 CREATE INDEX IF NOT EXISTS idx_event_triggers_listen_for_type ON event_triggers(listen_for_type);
@@ -204,6 +217,68 @@ CREATE TABLE IF NOT EXISTS llm_model_registry (
 );
 CREATE INDEX IF NOT EXISTS idx_llm_model_registry_created_at ON llm_model_registry(created_at);
 
+CREATE TABLE IF NOT EXISTS llm_model_usage (
+    backend_id       VARCHAR(255) NOT NULL,
+    model_name       VARCHAR(512) NOT NULL,
+    bucket_start     TIMESTAMP    NOT NULL,
+    request_count    BIGINT       NOT NULL DEFAULT 0,
+    error_count      BIGINT       NOT NULL DEFAULT 0,
+    total_latency_ms BIGINT       NOT NULL DEFAULT 0,
+    updated_at       TIMESTAMP    NOT NULL,
+    PRIMARY KEY (backend_id, model_name, bucket_start)
+);
+CREATE INDEX IF NOT EXISTS idx_llm_model_usage_bucket_start ON llm_model_usage(bucket_start);
+
+CREATE TABLE IF NOT EXISTS llm_model_cost (
+    backend_id       VARCHAR(255)     NOT NULL,
+    model_name       VARCHAR(512)     NOT NULL,
+    bucket_start     TIMESTAMP        NOT NULL,
+    call_count       BIGINT           NOT NULL DEFAULT 0,
+    input_tokens     BIGINT           NOT NULL DEFAULT 0,
+    output_tokens    BIGINT           NOT NULL DEFAULT 0,
+    cost             DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at       TIMESTAMP        NOT NULL,
+    PRIMARY KEY (backend_id, model_name, bucket_start)
+);
+CREATE INDEX IF NOT EXISTS idx_llm_model_cost_bucket_start ON llm_model_cost(bucket_start);
+
+CREATE TABLE IF NOT EXISTS execution_log (
+    id            VARCHAR(255)     PRIMARY KEY,
+    chain_id      VARCHAR(255)     NOT NULL DEFAULT '',
+    input_digest  VARCHAR(64)      NOT NULL DEFAULT '',
+    input_preview TEXT             NOT NULL DEFAULT '',
+    status        VARCHAR(50)      NOT NULL,
+    error         TEXT             NOT NULL DEFAULT '',
+    steps         INTEGER          NOT NULL DEFAULT 0,
+    input_tokens  BIGINT           NOT NULL DEFAULT 0,
+    output_tokens BIGINT           NOT NULL DEFAULT 0,
+    cost          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    started_at    TIMESTAMP        NOT NULL,
+    finished_at   TIMESTAMP        NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_execution_log_finished_at ON execution_log(finished_at);
+
+CREATE TABLE IF NOT EXISTS scheduled_changes (
+    id           VARCHAR(255) PRIMARY KEY,
+    kind         VARCHAR(50)  NOT NULL,
+    target       VARCHAR(512) NOT NULL,
+    value        TEXT         NOT NULL DEFAULT '',
+    workspace_id VARCHAR(255) NOT NULL DEFAULT '',
+    apply_at     TIMESTAMP    NOT NULL,
+    status       VARCHAR(50)  NOT NULL DEFAULT 'pending',
+    error        TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMP    NOT NULL,
+    updated_at   TIMESTAMP    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_status_apply_at ON scheduled_changes(status, apply_at);
+
+CREATE TABLE IF NOT EXISTS session_locks (
+    session_id  VARCHAR(255) PRIMARY KEY,
+    holder      VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP    NOT NULL,
+    expires_at  TIMESTAMP    NOT NULL
+);
+
 -- libbus.SQLiteBus tables -----------------------------------------------
 
 CREATE TABLE IF NOT EXISTS bus_events (
@@ -263,6 +338,18 @@ ALTER TABLE plan_steps ADD COLUMN last_failure_summary TEXT;
 -- plan_steps: failure classification used by 'plan next --auto' to decide
 -- whether to auto-replan a failed step. See planstore.FailureClass.
 ALTER TABLE plan_steps ADD COLUMN failure_class        VARCHAR(50);
+-- plan_steps: claim time, so 'plan show' can estimate the remaining time from
+-- the durations of completed steps. See planstore.EstimateRemaining.
+ALTER TABLE plan_steps ADD COLUMN started_at           TIMESTAMP;
+
+-- execution_log: prompt variants drawn per run (JSON). See
+-- runtimetypes.ExecutionPromptVariant and 'contenox logs variants'.
+ALTER TABLE execution_log ADD COLUMN prompt_variants TEXT NOT NULL DEFAULT '';
+
+-- llm_backends: per-backend credentials and extra HTTP headers, overriding the
+-- provider-wide key of the backend type. See runtimetypes.Backend.
+ALTER TABLE llm_backends ADD COLUMN api_key      TEXT NOT NULL DEFAULT '';
+ALTER TABLE llm_backends ADD COLUMN headers_json TEXT NOT NULL DEFAULT '{}';
 
 -- kv: workspace_id added after initial release (required for workspace-scoped config
 -- and the ON CONFLICT (key, workspace_id) upsert used by SetKV / SetWorkspaceKV).
@@ -283,14 +370,16 @@ CREATE TABLE llm_backends_temp (
     name VARCHAR(512) NOT NULL UNIQUE,
     base_url VARCHAR(512) NOT NULL,
     type VARCHAR(512) NOT NULL,
+    api_key TEXT NOT NULL DEFAULT '',
+    headers_json TEXT NOT NULL DEFAULT '{}',
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
     UNIQUE(type, base_url)
 );
 
 -- 2. Move your data
-INSERT INTO llm_backends_temp (id, name, base_url, type, created_at, updated_at)
-SELECT id, name, base_url, type, created_at, updated_at FROM llm_backends;
+INSERT INTO llm_backends_temp (id, name, base_url, type, api_key, headers_json, created_at, updated_at)
+SELECT id, name, base_url, type, api_key, headers_json, created_at, updated_at FROM llm_backends;
 
 -- 3. Swap them
 DROP TABLE llm_backends;
@@ -0,0 +1,167 @@
+package runtimetypes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/google/uuid"
+)
+
+// ErrSessionBusy is returned when another execution holds the lock of a
+// session.
+var ErrSessionBusy = errors.New("session is busy with another execution")
+
+// ErrSessionLockLost is the cause of the context returned by LockSession when
+// the lock expired or was taken over before it was released.
+var ErrSessionLockLost = errors.New("session lock lost")
+
+// SessionLockTTL is how long a session lock lives without being renewed.
+// LockSession renews it while the lock is held, so a crashed holder frees the
+// session after at most this long.
+const SessionLockTTL = 30 * time.Second
+
+// sessionLockPoll is how often LockSession retries while waiting for a lock.
+const sessionLockPoll = 250 * time.Millisecond
+
+// sessionLockTTL is the TTL LockSession takes and renews locks with. Tests
+// shorten it.
+var sessionLockTTL = SessionLockTTL
+
+// SessionLock is the lease of one execution on a chat or plan session.
+type SessionLock struct {
+	SessionID  string    `json:"sessionId" example:"s1a2b3c4-d5e6-f7a8-b9c0-d1e2f3a4b5c6"`
+	Holder     string    `json:"holder" example:"h1a2b3c4-d5e6-f7a8-b9c0-d1e2f3a4b5c6"`
+	AcquiredAt time.Time `json:"acquiredAt" example:"2023-11-15T14:30:00Z"`
+	ExpiresAt  time.Time `json:"expiresAt" example:"2023-11-15T14:30:30Z"`
+}
+
+// AcquireSessionLock takes the lock of sessionID for holder until ttl from
+// now. An expired lock is taken over; a lock already held by holder is
+// extended. It fails with ErrSessionBusy when another holder has the lock.
+func (s *store) AcquireSessionLock(ctx context.Context, sessionID, holder string, ttl time.Duration) error {
+	now := time.Now().UTC()
+	result, err := s.Exec.ExecContext(ctx, `
+		INSERT INTO session_locks (session_id, holder, acquired_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session_id) DO UPDATE
+		SET holder = excluded.holder, acquired_at = excluded.acquired_at, expires_at = excluded.expires_at
+		WHERE session_locks.expires_at < $3 OR session_locks.holder = excluded.holder`,
+		sessionID, holder, now, now.Add(ttl),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to acquire session lock: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if n > 0 {
+		return nil
+	}
+	var current SessionLock
+	err = s.Exec.QueryRowContext(ctx, `
+		SELECT session_id, holder, acquired_at, expires_at
+		FROM session_locks WHERE session_id = $1`,
+		sessionID,
+	).Scan(&current.SessionID, &current.Holder, &current.AcquiredAt, &current.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrSessionBusy, sessionID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session lock: %w", err)
+	}
+	return fmt.Errorf("%w: %s is running an execution started at %s", ErrSessionBusy, sessionID, current.AcquiredAt.Local().Format(time.DateTime))
+}
+
+// RenewSessionLock extends the lock of holder on sessionID until ttl from
+// now. It returns libdb.ErrNotFound when holder no longer has the lock.
+func (s *store) RenewSessionLock(ctx context.Context, sessionID, holder string, ttl time.Duration) error {
+	result, err := s.Exec.ExecContext(ctx, `
+		UPDATE session_locks SET expires_at = $3
+		WHERE session_id = $1 AND holder = $2`,
+		sessionID, holder, time.Now().UTC().Add(ttl),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to renew session lock: %w", err)
+	}
+	return checkRowsAffected(result)
+}
+
+// ReleaseSessionLock drops the lock of holder on sessionID, if it still has it.
+func (s *store) ReleaseSessionLock(ctx context.Context, sessionID, holder string) error {
+	_, err := s.Exec.ExecContext(ctx, `
+		DELETE FROM session_locks
+		WHERE session_id = $1 AND holder = $2`,
+		sessionID, holder,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release session lock: %w", err)
+	}
+	return nil
+}
+
+// LockSession holds the lock of sessionID until the returned unlock is
+// called, renewing it in the background. When another execution holds the
+// session, it retries for up to wait and then fails with ErrSessionBusy; a
+// zero wait fails at once.
+//
+// The work done under the lock must use the returned context: it is
+// cancelled with ErrSessionLockLost as the cause when the lock cannot be
+// renewed, because another holder took it over or the store could not be
+// reached before it expired.
+func LockSession(ctx context.Context, s Store, sessionID string, wait time.Duration) (lockCtx context.Context, unlock func(), err error) {
+	holder := uuid.NewString()
+	deadline := time.Now().Add(wait)
+	for {
+		err = s.AcquireSessionLock(ctx, sessionID, holder, sessionLockTTL)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrSessionBusy) || !time.Now().Add(sessionLockPoll).Before(deadline) {
+			return nil, nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(sessionLockPoll):
+		}
+	}
+	expires := time.Now().Add(sessionLockTTL)
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		interval := sessionLockTTL / 3
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewedAt := time.Now()
+				err := s.RenewSessionLock(context.WithoutCancel(ctx), sessionID, holder, sessionLockTTL)
+				if err == nil {
+					expires = renewedAt.Add(sessionLockTTL)
+					continue
+				}
+				// A failed renewal is retried on the next tick while the lock
+				// is still valid by then.
+				if errors.Is(err, libdb.ErrNotFound) || !time.Now().Add(interval).Before(expires) {
+					cancel(fmt.Errorf("%w: %s: %w", ErrSessionLockLost, sessionID, err))
+					return
+				}
+			}
+		}
+	}()
+	return lockCtx, func() {
+		close(done)
+		<-stopped
+		cancel(nil)
+		_ = s.ReleaseSessionLock(context.WithoutCancel(ctx), sessionID, holder)
+	}, nil
+}
@@ -0,0 +1,85 @@
+package runtimetypes
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRenewStore fails every renewal while failing is set.
+type flakyRenewStore struct {
+	Store
+	failing atomic.Bool
+}
+
+func (f *flakyRenewStore) RenewSessionLock(ctx context.Context, sessionID, holder string, ttl time.Duration) error {
+	if f.failing.Load() {
+		return errors.New("database is locked")
+	}
+	return f.Store.RenewSessionLock(ctx, sessionID, holder, ttl)
+}
+
+func setupSQLiteLockStore(t *testing.T) (context.Context, Store) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "locks.db"), SchemaSQLite)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ttl := sessionLockTTL
+	sessionLockTTL = 150 * time.Millisecond
+	t.Cleanup(func() { sessionLockTTL = ttl })
+	return ctx, New(db.WithoutTransaction())
+}
+
+func TestUnit_LockSession_CancelsWhenLockExpires(t *testing.T) {
+	ctx, s := setupSQLiteLockStore(t)
+
+	lockCtx, unlock, err := LockSession(ctx, s, "session", 0)
+	require.NoError(t, err)
+	defer unlock()
+
+	// The lock row expires mid-hold and another execution takes it over.
+	_, err = s.(*store).Exec.ExecContext(ctx, `UPDATE session_locks SET expires_at = $1 WHERE session_id = $2`, time.Now().UTC().Add(-time.Second), "session")
+	require.NoError(t, err)
+	require.NoError(t, s.AcquireSessionLock(ctx, "session", "other", time.Minute))
+
+	select {
+	case <-lockCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the lock context was not cancelled")
+	}
+	require.ErrorIs(t, context.Cause(lockCtx), ErrSessionLockLost)
+	require.NoError(t, ctx.Err(), "the parent context is left alone")
+}
+
+func TestUnit_LockSession_SurvivesTransientRenewalErrors(t *testing.T) {
+	ctx, s := setupSQLiteLockStore(t)
+	flaky := &flakyRenewStore{Store: s}
+
+	lockCtx, unlock, err := LockSession(ctx, flaky, "session", 0)
+	require.NoError(t, err)
+	defer unlock()
+
+	// One failed renewal leaves the lock valid until the next one.
+	flaky.failing.Store(true)
+	time.Sleep(sessionLockTTL / 6)
+	flaky.failing.Store(false)
+	time.Sleep(sessionLockTTL)
+	require.NoError(t, lockCtx.Err())
+
+	// Renewals failing until the lock expires lose it.
+	flaky.failing.Store(true)
+	select {
+	case <-lockCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the lock context was not cancelled")
+	}
+	require.ErrorIs(t, context.Cause(lockCtx), ErrSessionLockLost)
+}
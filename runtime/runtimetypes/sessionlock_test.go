@@ -0,0 +1,49 @@
+package runtimetypes_test
+
+import (
+	"testing"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_SessionLock_ExclusiveUntilReleased(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+
+	require.NoError(t, s.AcquireSessionLock(ctx, "session", "a", time.Minute))
+	require.NoError(t, s.AcquireSessionLock(ctx, "session", "a", time.Minute), "the holder can extend its lock")
+	require.ErrorIs(t, s.AcquireSessionLock(ctx, "session", "b", time.Minute), runtimetypes.ErrSessionBusy)
+	require.NoError(t, s.AcquireSessionLock(ctx, "other", "b", time.Minute), "locks are per session")
+
+	require.NoError(t, s.RenewSessionLock(ctx, "session", "a", time.Minute))
+	require.ErrorIs(t, s.RenewSessionLock(ctx, "session", "b", time.Minute), libdb.ErrNotFound)
+
+	require.NoError(t, s.ReleaseSessionLock(ctx, "session", "b"), "releasing a lock not held is a no-op")
+	require.ErrorIs(t, s.AcquireSessionLock(ctx, "session", "b", time.Minute), runtimetypes.ErrSessionBusy)
+	require.NoError(t, s.ReleaseSessionLock(ctx, "session", "a"))
+	require.NoError(t, s.AcquireSessionLock(ctx, "session", "b", time.Minute))
+}
+
+func TestUnit_SessionLock_ExpiredLockIsTakenOver(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+
+	require.NoError(t, s.AcquireSessionLock(ctx, "session", "crashed", -time.Second))
+	require.NoError(t, s.AcquireSessionLock(ctx, "session", "b", time.Minute))
+	require.ErrorIs(t, s.RenewSessionLock(ctx, "session", "crashed", time.Minute), libdb.ErrNotFound)
+}
+
+func TestUnit_LockSession_WaitsForRelease(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+
+	_, unlock, err := runtimetypes.LockSession(ctx, s, "session", 0)
+	require.NoError(t, err)
+	_, _, err = runtimetypes.LockSession(ctx, s, "session", 0)
+	require.ErrorIs(t, err, runtimetypes.ErrSessionBusy)
+
+	time.AfterFunc(300*time.Millisecond, unlock)
+	_, unlock2, err := runtimetypes.LockSession(ctx, s, "session", 5*time.Second)
+	require.NoError(t, err, "a waiting caller gets the lock once it is released")
+	unlock2()
+}
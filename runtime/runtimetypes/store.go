@@ -39,6 +39,12 @@ type Backend struct {
 	Name    string `json:"name" example:"ollama-production"`
 	BaseURL string `json:"baseUrl" example:"http://ollama-prod.internal:11434"`
 	Type    string `json:"type" example:"ollama"`
+	// APIKey is the credential of this backend. Empty means the provider-wide
+	// key of its type (see runtimestate.ProviderConfig). It is never encoded
+	// to JSON.
+	APIKey string `json:"-"`
+	// Headers are extra HTTP headers sent with every request to the backend.
+	Headers map[string]string `json:"headers,omitempty"`
 
 	CreatedAt time.Time `json:"createdAt" example:"2023-11-15T14:30:45Z"`
 	UpdatedAt time.Time `json:"updatedAt" example:"2023-11-15T14:30:45Z"`
@@ -210,6 +216,37 @@ type Store interface {
 	// Used by the CLI to register config-file MCP servers into SQLite at startup.
 	UpsertMCPServerByName(ctx context.Context, srv *MCPServer) error
 
+	// RecordModelUsage folds one model call into the hourly usage bucket for at.
+	RecordModelUsage(ctx context.Context, backendID, modelName string, latency time.Duration, failed bool, at time.Time) error
+	// ListModelUsage aggregates usage per backend and model since the given time.
+	ListModelUsage(ctx context.Context, since time.Time) ([]*ModelUsage, error)
+	// ListDailyModelUsage aggregates usage of all backends and models per UTC day since the given time.
+	ListDailyModelUsage(ctx context.Context, since time.Time) ([]*ModelUsage, error)
+	// RecordModelCost folds the tokens and estimated cost of one model call into the hourly cost bucket for at.
+	RecordModelCost(ctx context.Context, backendID, modelName string, inputTokens, outputTokens int64, cost float64, at time.Time) error
+	// ListModelCost aggregates tokens and cost per backend and model since the given time.
+	ListModelCost(ctx context.Context, since time.Time) ([]*ModelCost, error)
+	// DeleteModelUsageBefore drops usage and cost buckets that started before cutoff.
+	DeleteModelUsageBefore(ctx context.Context, cutoff time.Time) error
+
+	// Local history of chain executions.
+	AppendExecutionLog(ctx context.Context, entry *ExecutionLogEntry) error
+	ListExecutionLog(ctx context.Context, filter ExecutionLogFilter) ([]*ExecutionLogEntry, error)
+	DeleteExecutionLogBefore(ctx context.Context, cutoff time.Time) error
+
+	// Scheduled configuration changes, applied by ApplyDueChanges.
+	CreateScheduledChange(ctx context.Context, change *ScheduledChange) error
+	GetScheduledChange(ctx context.Context, id string) (*ScheduledChange, error)
+	ListScheduledChanges(ctx context.Context, status ChangeStatus) ([]*ScheduledChange, error)
+	ListDueScheduledChanges(ctx context.Context, now time.Time) ([]*ScheduledChange, error)
+	CancelScheduledChange(ctx context.Context, id string) error
+
+	// Leases that keep a chat or plan session to one execution at a time;
+	// see LockSession.
+	AcquireSessionLock(ctx context.Context, sessionID, holder string, ttl time.Duration) error
+	RenewSessionLock(ctx context.Context, sessionID, holder string, ttl time.Duration) error
+	ReleaseSessionLock(ctx context.Context, sessionID, holder string) error
+
 	EnforceMaxRowCount(ctx context.Context, count int64) error
 }
 
@@ -0,0 +1,308 @@
+package runtimetypes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ModelUsageBucket is the width of a persisted usage aggregate row.
+// Samples are folded into the bucket their timestamp falls into, so the
+// table grows with (backends x models x hours) rather than with requests.
+const ModelUsageBucket = time.Hour
+
+// ModelUsage is a rolling aggregate of model calls served by a single backend.
+// Rows returned by ListModelUsage are summed over every bucket in the
+// requested window.
+type ModelUsage struct {
+	BackendID      string    `json:"backendId" example:"b7d9e1a3-8f0c-4a7d-9b1e-2f3a4b5c6d7e"`
+	ModelName      string    `json:"modelName" example:"mistral:instruct"`
+	RequestCount   int64     `json:"requestCount" example:"120"`
+	ErrorCount     int64     `json:"errorCount" example:"3"`
+	TotalLatencyMs int64     `json:"totalLatencyMs" example:"54000"`
+	WindowStart    time.Time `json:"windowStart" example:"2023-11-15T14:00:00Z"`
+	UpdatedAt      time.Time `json:"updatedAt" example:"2023-11-15T14:30:45Z"`
+}
+
+// ErrorRate returns the fraction of failed requests in [0,1].
+func (u *ModelUsage) ErrorRate() float64 {
+	if u.RequestCount == 0 {
+		return 0
+	}
+	return float64(u.ErrorCount) / float64(u.RequestCount)
+}
+
+// AvgLatency returns the mean request latency.
+func (u *ModelUsage) AvgLatency() time.Duration {
+	if u.RequestCount == 0 {
+		return 0
+	}
+	return time.Duration(u.TotalLatencyMs/u.RequestCount) * time.Millisecond
+}
+
+// RecordModelUsage folds a single call into the usage bucket for at.
+func (s *store) RecordModelUsage(ctx context.Context, backendID, modelName string, latency time.Duration, failed bool, at time.Time) error {
+	if backendID == "" || modelName == "" {
+		return fmt.Errorf("backend id and model name are required")
+	}
+	at = at.UTC()
+	bucket := at.Truncate(ModelUsageBucket)
+	var errCount int64
+	if failed {
+		errCount = 1
+	}
+	_, err := s.Exec.ExecContext(ctx, `
+		INSERT INTO llm_model_usage
+		(backend_id, model_name, bucket_start, request_count, error_count, total_latency_ms, updated_at)
+		VALUES ($1, $2, $3, 1, $4, $5, $6)
+		ON CONFLICT (backend_id, model_name, bucket_start) DO UPDATE
+		SET request_count = llm_model_usage.request_count + 1,
+			error_count = llm_model_usage.error_count + $4,
+			total_latency_ms = llm_model_usage.total_latency_ms + $5,
+			updated_at = $6`,
+		backendID, modelName, bucket, errCount, latency.Milliseconds(), at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record model usage: %w", err)
+	}
+	return nil
+}
+
+// ListModelUsage returns usage aggregated per backend and model over all
+// buckets starting at or after since, busiest first.
+func (s *store) ListModelUsage(ctx context.Context, since time.Time) ([]*ModelUsage, error) {
+	since = since.UTC().Truncate(ModelUsageBucket)
+	// Buckets are folded in Go rather than with SUM/MIN/MAX so timestamp columns
+	// keep their declared type on SQLite, where aggregates return plain text.
+	rows, err := s.Exec.QueryContext(ctx, `
+		SELECT backend_id, model_name, bucket_start,
+			request_count, error_count, total_latency_ms, updated_at
+		FROM llm_model_usage
+		WHERE bucket_start >= $1
+		ORDER BY bucket_start ASC`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query model usage: %w", err)
+	}
+	defer rows.Close()
+
+	type usageKey struct{ backendID, modelName string }
+	byKey := map[usageKey]*ModelUsage{}
+	for rows.Next() {
+		var u ModelUsage
+		if err := rows.Scan(
+			&u.BackendID,
+			&u.ModelName,
+			&u.WindowStart,
+			&u.RequestCount,
+			&u.ErrorCount,
+			&u.TotalLatencyMs,
+			&u.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan model usage: %w", err)
+		}
+		k := usageKey{u.BackendID, u.ModelName}
+		agg, ok := byKey[k]
+		if !ok {
+			byKey[k] = &u
+			continue
+		}
+		agg.RequestCount += u.RequestCount
+		agg.ErrorCount += u.ErrorCount
+		agg.TotalLatencyMs += u.TotalLatencyMs
+		if u.UpdatedAt.After(agg.UpdatedAt) {
+			agg.UpdatedAt = u.UpdatedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	usage := make([]*ModelUsage, 0, len(byKey))
+	for _, u := range byKey {
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].RequestCount != usage[j].RequestCount {
+			return usage[i].RequestCount > usage[j].RequestCount
+		}
+		if usage[i].BackendID != usage[j].BackendID {
+			return usage[i].BackendID < usage[j].BackendID
+		}
+		return usage[i].ModelName < usage[j].ModelName
+	})
+	return usage, nil
+}
+
+// ListDailyModelUsage returns usage summed over all backends and models per
+// UTC day, oldest first. WindowStart is the start of the day; BackendID and
+// ModelName are empty. Days without calls are omitted.
+func (s *store) ListDailyModelUsage(ctx context.Context, since time.Time) ([]*ModelUsage, error) {
+	rows, err := s.Exec.QueryContext(ctx, `
+		SELECT bucket_start, request_count, error_count, total_latency_ms, updated_at
+		FROM llm_model_usage
+		WHERE bucket_start >= $1
+		ORDER BY bucket_start ASC`,
+		since.UTC().Truncate(ModelUsageBucket),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query model usage: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*ModelUsage
+	for rows.Next() {
+		var u ModelUsage
+		if err := rows.Scan(&u.WindowStart, &u.RequestCount, &u.ErrorCount, &u.TotalLatencyMs, &u.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan model usage: %w", err)
+		}
+		day := u.WindowStart.UTC().Truncate(24 * time.Hour)
+		if n := len(days); n > 0 && days[n-1].WindowStart.Equal(day) {
+			agg := days[n-1]
+			agg.RequestCount += u.RequestCount
+			agg.ErrorCount += u.ErrorCount
+			agg.TotalLatencyMs += u.TotalLatencyMs
+			if u.UpdatedAt.After(agg.UpdatedAt) {
+				agg.UpdatedAt = u.UpdatedAt
+			}
+			continue
+		}
+		u.WindowStart = day
+		days = append(days, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return days, nil
+}
+
+// DeleteModelUsageBefore drops usage and cost buckets that started before cutoff.
+func (s *store) DeleteModelUsageBefore(ctx context.Context, cutoff time.Time) error {
+	cutoff = cutoff.UTC().Truncate(ModelUsageBucket)
+	_, err := s.Exec.ExecContext(ctx, `
+		DELETE FROM llm_model_usage
+		WHERE bucket_start < $1`,
+		cutoff,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete model usage: %w", err)
+	}
+	_, err = s.Exec.ExecContext(ctx, `
+		DELETE FROM llm_model_cost
+		WHERE bucket_start < $1`,
+		cutoff,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete model cost: %w", err)
+	}
+	return nil
+}
+
+// ModelCost is a rolling aggregate of the tokens and estimated cost of model
+// calls served by a single backend, bucketed like ModelUsage. Cost is in USD
+// as estimated from the pricing table of the executor at call time.
+type ModelCost struct {
+	BackendID    string    `json:"backendId" example:"b7d9e1a3-8f0c-4a7d-9b1e-2f3a4b5c6d7e"`
+	ModelName    string    `json:"modelName" example:"gpt-4o-mini"`
+	CallCount    int64     `json:"callCount" example:"120"`
+	InputTokens  int64     `json:"inputTokens" example:"240000"`
+	OutputTokens int64     `json:"outputTokens" example:"36000"`
+	Cost         float64   `json:"cost" example:"0.0576"`
+	WindowStart  time.Time `json:"windowStart" example:"2023-11-15T14:00:00Z"`
+	UpdatedAt    time.Time `json:"updatedAt" example:"2023-11-15T14:30:45Z"`
+}
+
+// RecordModelCost folds the tokens and cost of a single call into the cost
+// bucket for at.
+func (s *store) RecordModelCost(ctx context.Context, backendID, modelName string, inputTokens, outputTokens int64, cost float64, at time.Time) error {
+	if backendID == "" || modelName == "" {
+		return fmt.Errorf("backend id and model name are required")
+	}
+	at = at.UTC()
+	bucket := at.Truncate(ModelUsageBucket)
+	_, err := s.Exec.ExecContext(ctx, `
+		INSERT INTO llm_model_cost
+		(backend_id, model_name, bucket_start, call_count, input_tokens, output_tokens, cost, updated_at)
+		VALUES ($1, $2, $3, 1, $4, $5, $6, $7)
+		ON CONFLICT (backend_id, model_name, bucket_start) DO UPDATE
+		SET call_count = llm_model_cost.call_count + 1,
+			input_tokens = llm_model_cost.input_tokens + $4,
+			output_tokens = llm_model_cost.output_tokens + $5,
+			cost = llm_model_cost.cost + $6,
+			updated_at = $7`,
+		backendID, modelName, bucket, inputTokens, outputTokens, cost, at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record model cost: %w", err)
+	}
+	return nil
+}
+
+// ListModelCost returns tokens and cost aggregated per backend and model over
+// all buckets starting at or after since, most expensive first.
+func (s *store) ListModelCost(ctx context.Context, since time.Time) ([]*ModelCost, error) {
+	// Buckets are folded in Go for the same reason as in ListModelUsage.
+	rows, err := s.Exec.QueryContext(ctx, `
+		SELECT backend_id, model_name, bucket_start,
+			call_count, input_tokens, output_tokens, cost, updated_at
+		FROM llm_model_cost
+		WHERE bucket_start >= $1
+		ORDER BY bucket_start ASC`,
+		since.UTC().Truncate(ModelUsageBucket),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query model cost: %w", err)
+	}
+	defer rows.Close()
+
+	type costKey struct{ backendID, modelName string }
+	byKey := map[costKey]*ModelCost{}
+	for rows.Next() {
+		var c ModelCost
+		if err := rows.Scan(
+			&c.BackendID,
+			&c.ModelName,
+			&c.WindowStart,
+			&c.CallCount,
+			&c.InputTokens,
+			&c.OutputTokens,
+			&c.Cost,
+			&c.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan model cost: %w", err)
+		}
+		k := costKey{c.BackendID, c.ModelName}
+		agg, ok := byKey[k]
+		if !ok {
+			byKey[k] = &c
+			continue
+		}
+		agg.CallCount += c.CallCount
+		agg.InputTokens += c.InputTokens
+		agg.OutputTokens += c.OutputTokens
+		agg.Cost += c.Cost
+		if c.UpdatedAt.After(agg.UpdatedAt) {
+			agg.UpdatedAt = c.UpdatedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	costs := make([]*ModelCost, 0, len(byKey))
+	for _, c := range byKey {
+		costs = append(costs, c)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Cost != costs[j].Cost {
+			return costs[i].Cost > costs[j].Cost
+		}
+		if costs[i].BackendID != costs[j].BackendID {
+			return costs[i].BackendID < costs[j].BackendID
+		}
+		return costs[i].ModelName < costs[j].ModelName
+	})
+	return costs, nil
+}
@@ -0,0 +1,100 @@
+package runtimetypes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ModelUsage_RecordsAndAggregates(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+	now := time.Now().UTC()
+
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", 100*time.Millisecond, false, now))
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", 300*time.Millisecond, true, now))
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", 200*time.Millisecond, false, now.Add(-2*time.Hour)))
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-b", "mistral", 50*time.Millisecond, false, now))
+
+	usage, err := s.ListModelUsage(ctx, now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+
+	require.Equal(t, "backend-a", usage[0].BackendID)
+	require.Equal(t, int64(3), usage[0].RequestCount)
+	require.Equal(t, int64(1), usage[0].ErrorCount)
+	require.Equal(t, int64(600), usage[0].TotalLatencyMs)
+	require.Equal(t, 200*time.Millisecond, usage[0].AvgLatency())
+	require.InDelta(t, 1.0/3.0, usage[0].ErrorRate(), 0.0001)
+
+	require.Equal(t, "backend-b", usage[1].BackendID)
+	require.Equal(t, int64(1), usage[1].RequestCount)
+}
+
+func TestUnit_ModelUsage_WindowAndRetention(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+	now := time.Now().UTC()
+
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", time.Second, false, now.Add(-48*time.Hour)))
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", time.Second, false, now))
+
+	usage, err := s.ListModelUsage(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	require.Equal(t, int64(1), usage[0].RequestCount)
+
+	require.NoError(t, s.DeleteModelUsageBefore(ctx, now.Add(-24*time.Hour)))
+	usage, err = s.ListModelUsage(ctx, now.Add(-72*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	require.Equal(t, int64(1), usage[0].RequestCount)
+
+	require.Error(t, s.RecordModelUsage(ctx, "", "mistral", time.Second, false, now))
+}
+
+func TestUnit_ModelUsage_Daily(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", time.Second, false, day.Add(2*time.Hour)))
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-b", "qwen", time.Second, true, day.Add(20*time.Hour)))
+	require.NoError(t, s.RecordModelUsage(ctx, "backend-a", "mistral", time.Second, false, day.Add(26*time.Hour)))
+
+	days, err := s.ListDailyModelUsage(ctx, day)
+	require.NoError(t, err)
+	require.Len(t, days, 2)
+	require.Equal(t, day, days[0].WindowStart.UTC())
+	require.Equal(t, int64(2), days[0].RequestCount)
+	require.Equal(t, int64(1), days[0].ErrorCount)
+	require.Equal(t, day.Add(24*time.Hour), days[1].WindowStart.UTC())
+	require.Equal(t, int64(1), days[1].RequestCount)
+}
+
+func TestUnit_ModelCost_RecordsAndAggregates(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+	now := time.Now().UTC()
+
+	require.NoError(t, s.RecordModelCost(ctx, "backend-a", "gpt-4o-mini", 1000, 200, 0.00027, now))
+	require.NoError(t, s.RecordModelCost(ctx, "backend-a", "gpt-4o-mini", 500, 100, 0.000135, now.Add(-2*time.Hour)))
+	require.NoError(t, s.RecordModelCost(ctx, "backend-b", "mistral", 800, 80, 0, now))
+
+	costs, err := s.ListModelCost(ctx, now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, costs, 2)
+
+	require.Equal(t, "backend-a", costs[0].BackendID)
+	require.Equal(t, int64(2), costs[0].CallCount)
+	require.Equal(t, int64(1500), costs[0].InputTokens)
+	require.Equal(t, int64(300), costs[0].OutputTokens)
+	require.InDelta(t, 0.000405, costs[0].Cost, 1e-9)
+
+	require.Equal(t, "backend-b", costs[1].BackendID)
+	require.Zero(t, costs[1].Cost)
+
+	require.NoError(t, s.DeleteModelUsageBefore(ctx, now.Add(-time.Hour)))
+	costs, err = s.ListModelCost(ctx, now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, costs, 2)
+	require.Equal(t, int64(1), costs[0].CallCount)
+}
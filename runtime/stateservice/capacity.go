@@ -0,0 +1,296 @@
+package stateservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/modelrepo/ollama"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/statetype"
+)
+
+// DefaultCapacityWindow is the usage history a capacity report looks at when
+// CapacityOptions.Window is zero.
+const DefaultCapacityWindow = 14 * 24 * time.Hour
+
+// vramProbeTimeout bounds the /api/ps call made per Ollama backend.
+const vramProbeTimeout = 3 * time.Second
+
+// CapacityOptions configures a capacity report. The limits are optional;
+// without them the report shows usage and trends but no exhaustion dates.
+type CapacityOptions struct {
+	// Window is how far back usage trends are computed from.
+	Window time.Duration
+	// DailyRequestLimit is the number of requests per day the deployment can
+	// serve. Used to project when request volume reaches it.
+	DailyRequestLimit int64
+	// DiskLimitBytes is the disk space available for models across all backends.
+	// Used to project when pulled models fill it.
+	DiskLimitBytes int64
+}
+
+// CapacityReport aggregates backend, model, resource and request data into a
+// single view for capacity planning.
+type CapacityReport struct {
+	GeneratedAt       time.Time         `json:"generatedAt" example:"2025-06-01T12:00:00Z"`
+	WindowDays        float64           `json:"windowDays" example:"14"`
+	BackendCount      int               `json:"backendCount" example:"3"`
+	ReachableBackends int               `json:"reachableBackends" example:"2"`
+	Backends          []BackendCapacity `json:"backends"`
+	// DeclaredModels are the models registered in the database; PulledModels
+	// are the models backends report as available.
+	DeclaredModels []string `json:"declaredModels"`
+	PulledModels   []string `json:"pulledModels"`
+	// MissingModels are declared but not available on any backend.
+	MissingModels []string `json:"missingModels,omitempty"`
+	// UndeclaredModels are available on a backend but not declared.
+	UndeclaredModels []string `json:"undeclaredModels,omitempty"`
+	DiskBytes        int64    `json:"diskBytes" example:"9419222016"`
+	// VRAMBytes is the VRAM held by loaded models on reachable Ollama backends.
+	VRAMBytes   int64                `json:"vramBytes" example:"5368709120"`
+	Requests    RequestVolume        `json:"requests"`
+	Projections []CapacityProjection `json:"projections,omitempty"`
+}
+
+// BackendCapacity is the share of a single backend in a CapacityReport.
+type BackendCapacity struct {
+	ID        string `json:"id" example:"b7d9e1a3-8f0c-4a7d-9b1e-2f3a4b5c6d7e"`
+	Name      string `json:"name" example:"ollama-production"`
+	Type      string `json:"type" example:"ollama"`
+	Reachable bool   `json:"reachable" example:"true"`
+	Models    int    `json:"models" example:"4"`
+	DiskBytes int64  `json:"diskBytes" example:"9419222016"`
+	// VRAMBytes is nil when the backend does not report VRAM usage.
+	VRAMBytes *int64 `json:"vramBytes,omitempty" example:"5368709120"`
+	Requests  int64  `json:"requests" example:"1200"`
+	Errors    int64  `json:"errors" example:"12"`
+}
+
+// RequestVolume summarizes model calls over the report window.
+type RequestVolume struct {
+	Total  int64 `json:"total" example:"8400"`
+	Errors int64 `json:"errors" example:"84"`
+	// PerDay is the average number of requests per day.
+	PerDay float64 `json:"perDay" example:"600"`
+	// TrendPerDay is the change in daily requests per day (least-squares slope).
+	TrendPerDay float64                    `json:"trendPerDay" example:"12.5"`
+	Daily       []*runtimetypes.ModelUsage `json:"daily"`
+}
+
+// CapacityProjection is the date a resource is expected to run out at the
+// current trend. ExhaustedAt is nil when the trend does not reach the limit.
+type CapacityProjection struct {
+	Resource    string     `json:"resource" example:"requests"`
+	Limit       int64      `json:"limit" example:"1000"`
+	Current     float64    `json:"current" example:"600"`
+	ExhaustedAt *time.Time `json:"exhaustedAt,omitempty" example:"2025-07-01T00:00:00Z"`
+	Basis       string     `json:"basis" example:"+12.5 requests/day per day"`
+}
+
+// CapacityReport implements Service.
+func (s *service) CapacityReport(ctx context.Context, opts CapacityOptions) (*CapacityReport, error) {
+	if opts.Window <= 0 {
+		opts.Window = DefaultCapacityWindow
+	}
+	now := time.Now().UTC()
+	since := now.Add(-opts.Window)
+	store := runtimetypes.New(s.db.WithoutTransaction())
+
+	declared, err := store.ListAllModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	usage, err := store.ListModelUsage(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := store.ListDailyModelUsage(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	report := &CapacityReport{
+		GeneratedAt:  now,
+		WindowDays:   opts.Window.Hours() / 24,
+		BackendCount: len(states),
+		Backends:     make([]BackendCapacity, 0, len(states)),
+	}
+
+	declaredSet := map[string]bool{}
+	for _, m := range declared {
+		declaredSet[m.Model] = true
+	}
+	pulledSet := map[string]bool{}
+	// addedBytes is the size of models pulled during the window, the basis of
+	// the disk growth trend.
+	var addedBytes int64
+	for _, st := range states {
+		bc := BackendCapacity{
+			ID:        st.ID,
+			Name:      st.Name,
+			Type:      st.Backend.Type,
+			Reachable: st.Error == "",
+			Models:    len(st.PulledModels),
+		}
+		for _, m := range st.PulledModels {
+			bc.DiskBytes += m.Size
+			pulledSet[m.Model] = true
+			if m.ModifiedAt.After(since) {
+				addedBytes += m.Size
+			}
+		}
+		for _, u := range usage {
+			if u.BackendID == st.ID {
+				bc.Requests += u.RequestCount
+				bc.Errors += u.ErrorCount
+			}
+		}
+		if bc.Reachable && st.Backend.Type == "ollama" {
+			if vram, ok := s.loadedVRAM(ctx, st); ok {
+				bc.VRAMBytes = &vram
+				report.VRAMBytes += vram
+			}
+		}
+		if bc.Reachable {
+			report.ReachableBackends++
+		}
+		report.DiskBytes += bc.DiskBytes
+		report.Backends = append(report.Backends, bc)
+	}
+	report.DeclaredModels = sortedKeys(declaredSet)
+	report.PulledModels = sortedKeys(pulledSet)
+	for _, name := range report.DeclaredModels {
+		if !pulledSet[name] {
+			report.MissingModels = append(report.MissingModels, name)
+		}
+	}
+	for _, name := range report.PulledModels {
+		if !declaredSet[name] {
+			report.UndeclaredModels = append(report.UndeclaredModels, name)
+		}
+	}
+
+	report.Requests = requestVolume(daily, report.WindowDays)
+	if opts.DailyRequestLimit > 0 {
+		report.Projections = append(report.Projections, projectRequests(report.Requests, opts.DailyRequestLimit, now))
+	}
+	if opts.DiskLimitBytes > 0 {
+		growth := float64(addedBytes) / report.WindowDays
+		report.Projections = append(report.Projections, projectDisk(report.DiskBytes, growth, opts.DiskLimitBytes, now))
+	}
+	return report, nil
+}
+
+func (s *service) loadedVRAM(ctx context.Context, st statetype.BackendRuntimeState) (int64, bool) {
+	probeCtx, cancel := context.WithTimeout(ctx, vramProbeTimeout)
+	defer cancel()
+	vram, err := ollama.LoadedVRAM(probeCtx, st.Backend.BaseURL, st.GetAPIKey(), nil)
+	if err != nil {
+		return 0, false
+	}
+	return vram, true
+}
+
+func requestVolume(daily []*runtimetypes.ModelUsage, windowDays float64) RequestVolume {
+	v := RequestVolume{Daily: daily}
+	if v.Daily == nil {
+		v.Daily = []*runtimetypes.ModelUsage{}
+	}
+	for _, d := range daily {
+		v.Total += d.RequestCount
+		v.Errors += d.ErrorCount
+	}
+	if windowDays > 0 {
+		v.PerDay = float64(v.Total) / windowDays
+	}
+	v.TrendPerDay, _ = dailyTrend(daily)
+	return v
+}
+
+// dailyTrend fits a least-squares line through the daily request counts and
+// returns its slope in requests per day per day and its value on the last day.
+// Days without calls count as zero; fewer than two days give no trend.
+func dailyTrend(daily []*runtimetypes.ModelUsage) (slope, last float64) {
+	switch len(daily) {
+	case 0:
+		return 0, 0
+	case 1:
+		return 0, float64(daily[0].RequestCount)
+	}
+	first := daily[0].WindowStart
+	var n, sumX, sumY, sumXY, sumXX float64
+	days := int(daily[len(daily)-1].WindowStart.Sub(first).Hours()/24) + 1
+	counts := make([]float64, days)
+	for _, d := range daily {
+		counts[int(d.WindowStart.Sub(first).Hours()/24)] += float64(d.RequestCount)
+	}
+	for x, y := range counts {
+		fx := float64(x)
+		n++
+		sumX += fx
+		sumY += y
+		sumXY += fx * y
+		sumXX += fx * fx
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, sumY / n
+	}
+	slope = (n*sumXY - sumX*sumY) / denom
+	intercept := (sumY - slope*sumX) / n
+	return slope, intercept + slope*float64(days-1)
+}
+
+func projectRequests(v RequestVolume, limit int64, now time.Time) CapacityProjection {
+	// The fitted value smooths out the partial current day.
+	_, current := dailyTrend(v.Daily)
+	p := CapacityProjection{
+		Resource: "requests",
+		Limit:    limit,
+		Current:  current,
+		Basis:    fmt.Sprintf("%+.1f requests/day per day", v.TrendPerDay),
+	}
+	p.ExhaustedAt = exhaustion(current, float64(limit), v.TrendPerDay, now)
+	return p
+}
+
+func projectDisk(used int64, growthPerDay float64, limit int64, now time.Time) CapacityProjection {
+	p := CapacityProjection{
+		Resource: "disk",
+		Limit:    limit,
+		Current:  float64(used),
+		Basis:    fmt.Sprintf("%+.0f bytes/day pulled", growthPerDay),
+	}
+	p.ExhaustedAt = exhaustion(float64(used), float64(limit), growthPerDay, now)
+	return p
+}
+
+// exhaustion returns when current reaches limit growing by perDay, now when
+// it already has, or nil when it does not grow.
+func exhaustion(current, limit, perDay float64, now time.Time) *time.Time {
+	if current >= limit {
+		return &now
+	}
+	if perDay <= 0 {
+		return nil
+	}
+	days := (limit - current) / perDay
+	at := now.Add(time.Duration(days * 24 * float64(time.Hour))).Truncate(24 * time.Hour)
+	return &at
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
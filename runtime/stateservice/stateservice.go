@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/contenox/contenox/runtime/internal/clikv"
 	"github.com/contenox/contenox/runtime/internal/runtimestate"
@@ -15,12 +16,23 @@ import (
 
 // Service exposes runtime backend state plus onboarding/setup evaluation (same inputs as GET /setup-status).
 type Service interface {
+	// Get returns the observed state of every backend, including the rolling
+	// latency and failure statistics in BackendRuntimeState.Health.
 	Get(ctx context.Context) ([]statetype.BackendRuntimeState, error)
 	// SetupStatus returns readiness from KV defaults, registered backends, and current runtime state.
 	SetupStatus(ctx context.Context) (setupcheck.Result, error)
 	// SetCLIConfig updates CLI default keys (model, provider, chain, hitl-policy-name) in SQLite KV (same as contenox config set / PUT /cli-config).
 	// Empty fields in the patch are left unchanged. At least one field must be non-empty after trim.
 	SetCLIConfig(ctx context.Context, patch CLIConfigPatch) (CLIConfigSnapshot, error)
+	// ModelUsage returns request counts, error rates and latency per backend and model
+	// aggregated over all usage buckets since the given time.
+	ModelUsage(ctx context.Context, since time.Time) ([]*runtimetypes.ModelUsage, error)
+	// ModelCost returns tokens and estimated cost per backend and model
+	// aggregated over all cost buckets since the given time.
+	ModelCost(ctx context.Context, since time.Time) ([]*runtimetypes.ModelCost, error)
+	// CapacityReport aggregates backends, declared vs pulled models, disk and VRAM usage,
+	// request volume and projected exhaustion dates (same data as contenox doctor --capacity).
+	CapacityReport(ctx context.Context, opts CapacityOptions) (*CapacityReport, error)
 }
 
 // CLIConfigPatch selects which CLI default keys to write; empty strings mean "do not change".
@@ -112,6 +124,16 @@ func (s *service) SetCLIConfig(ctx context.Context, patch CLIConfigPatch) (CLICo
 	}, nil
 }
 
+// ModelUsage implements Service.
+func (s *service) ModelUsage(ctx context.Context, since time.Time) ([]*runtimetypes.ModelUsage, error) {
+	return runtimetypes.New(s.db.WithoutTransaction()).ListModelUsage(ctx, since)
+}
+
+// ModelCost implements Service.
+func (s *service) ModelCost(ctx context.Context, since time.Time) ([]*runtimetypes.ModelCost, error) {
+	return runtimetypes.New(s.db.WithoutTransaction()).ListModelCost(ctx, since)
+}
+
 // New returns a state service backed by runtime state and the same DB used for backends + CLI KV.
 // workspaceID scopes workspace-specific config (default-chain, hitl-policy-name) with global fallback.
 func New(state *runtimestate.State, db libdbexec.DBManager, workspaceID string) Service {
@@ -2,9 +2,11 @@ package stateservice
 
 import (
 	"context"
+	"time"
 
 	"github.com/contenox/contenox/runtime/internal/setupcheck"
 	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/runtimetypes"
 	"github.com/contenox/contenox/runtime/statetype"
 )
 
@@ -60,6 +62,51 @@ func (d *activityTrackerDecorator) SetCLIConfig(ctx context.Context, patch CLICo
 	return snap, err
 }
 
+func (d *activityTrackerDecorator) ModelUsage(ctx context.Context, since time.Time) ([]*runtimetypes.ModelUsage, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"read",
+		"model_usage",
+	)
+	defer endFn()
+
+	usage, err := d.service.ModelUsage(ctx, since)
+	if err != nil {
+		reportErrFn(err)
+	}
+	return usage, err
+}
+
+func (d *activityTrackerDecorator) ModelCost(ctx context.Context, since time.Time) ([]*runtimetypes.ModelCost, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"read",
+		"model_cost",
+	)
+	defer endFn()
+
+	cost, err := d.service.ModelCost(ctx, since)
+	if err != nil {
+		reportErrFn(err)
+	}
+	return cost, err
+}
+
+func (d *activityTrackerDecorator) CapacityReport(ctx context.Context, opts CapacityOptions) (*CapacityReport, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"read",
+		"capacity_report",
+	)
+	defer endFn()
+
+	report, err := d.service.CapacityReport(ctx, opts)
+	if err != nil {
+		reportErrFn(err)
+	}
+	return report, err
+}
+
 // WithActivityTracker wraps a StateService with activity tracking
 func WithActivityTracker(service Service, tracker libtracker.ActivityTracker) Service {
 	return &activityTrackerDecorator{
@@ -0,0 +1,66 @@
+package statetype
+
+import (
+	"time"
+
+	libbus "github.com/contenox/contenox/libbus"
+)
+
+// SubjectReconcileEvents is the bus subject ReconcileEvents are published on.
+const SubjectReconcileEvents = "runtimestate.events"
+
+// ReconcileEventSchema versions the ReconcileEvent wire format.
+var ReconcileEventSchema = libbus.MessageSchema{
+	Name:       "runtimestate.reconcile_event",
+	Version:    1,
+	MinVersion: 1,
+	Required:   []string{"kind", "backendId", "timestamp"},
+}
+
+// ReconcileEventKind names a decision of a reconciliation cycle.
+type ReconcileEventKind string
+
+const (
+	// ReconcileBackendError marks a backend as failing, or reports that its
+	// error changed. Reason is the new error.
+	ReconcileBackendError ReconcileEventKind = "backend_error"
+	// ReconcileBackendRecovered clears the error of a backend.
+	ReconcileBackendRecovered ReconcileEventKind = "backend_recovered"
+	// ReconcileModelsChanged reports models that appeared on or disappeared
+	// from a backend since the previous observation.
+	ReconcileModelsChanged ReconcileEventKind = "models_changed"
+	// ReconcileModelUpdated reports a declared model whose context length was
+	// learned from the backend and written back to the database.
+	ReconcileModelUpdated ReconcileEventKind = "model_updated"
+	// ReconcileBackendRemoved drops a backend that is no longer configured
+	// from the runtime state.
+	ReconcileBackendRemoved ReconcileEventKind = "backend_removed"
+)
+
+// ReconcileEvent records one decision of a reconciliation cycle with its
+// reason and the backend state before and after it, so changes to the
+// runtime state can be explained from data.
+type ReconcileEvent struct {
+	Kind        ReconcileEventKind `json:"kind" example:"models_changed"`
+	BackendID   string             `json:"backendId" example:"b7d9e1a3-8f0c-4a7d-9b1e-2f3a4b5c6d7e"`
+	BackendName string             `json:"backendName,omitempty" example:"ollama-production"`
+	// Model is the model a model_updated event is about.
+	Model  string `json:"model,omitempty" example:"mistral:instruct"`
+	Reason string `json:"reason" example:"backend no longer lists mistral:instruct"`
+	// Added and Removed list the models of a models_changed event.
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Before and After are the backend state around the decision; Before is
+	// nil on the first observation and After is nil once a backend is removed.
+	Before    *ReconcileSnapshot `json:"before,omitempty"`
+	After     *ReconcileSnapshot `json:"after,omitempty"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// ReconcileSnapshot is the part of a BackendRuntimeState reconcile events
+// compare.
+type ReconcileSnapshot struct {
+	// Models are the models the backend reported.
+	Models []string `json:"models"`
+	Error  string   `json:"error,omitempty"`
+}
@@ -19,10 +19,51 @@ type BackendRuntimeState struct {
 	// Error stores a description of the last encountered error when
 	// interacting with or reconciling this backend's state, if any.
 	Error string `json:"error,omitempty" example:"connection timeout: context deadline exceeded"`
+	// ModelListSource records where the model list of a catalog-backed provider
+	// (OpenAI, Gemini, Vertex) came from during the last reconcile cycle:
+	// ModelListSourceCache, ModelListSourceRevalidated or ModelListSourceNetwork.
+	ModelListSource string `json:"modelListSource,omitempty" example:"cache"`
+	// Health holds rolling latency and failure statistics of the backend
+	// gathered by this process. It is nil before the first observation.
+	Health *BackendHealth `json:"health,omitempty"`
 	// APIKey stores the API key used for authentication with the backend.
 	apiKey string
 }
 
+// Sources of a provider model list, see BackendRuntimeState.ModelListSource.
+const (
+	// ModelListSourceCache means the list was served from the provider cache
+	// without contacting the provider.
+	ModelListSourceCache = "cache"
+	// ModelListSourceRevalidated means the provider confirmed the cached list
+	// is current (HTTP 304 Not Modified).
+	ModelListSourceRevalidated = "revalidated"
+	// ModelListSourceNetwork means the full list was fetched from the provider.
+	ModelListSourceNetwork = "network"
+)
+
+// BackendHealth splits the health statistics of a backend by the kind of
+// request they were gathered from.
+type BackendHealth struct {
+	// Reconcile covers the observations made by reconciliation cycles.
+	Reconcile HealthStats `json:"reconcile"`
+	// Inference covers the model calls served by the backend.
+	Inference HealthStats `json:"inference"`
+}
+
+// HealthStats are rolling statistics over the most recent requests of one kind.
+type HealthStats struct {
+	// Samples is the number of recent requests the percentiles cover.
+	Samples int     `json:"samples" example:"100"`
+	P50Ms   float64 `json:"p50Ms" example:"120.5"`
+	P90Ms   float64 `json:"p90Ms" example:"480"`
+	P99Ms   float64 `json:"p99Ms" example:"1250"`
+	// ConsecutiveFailures counts the failed requests since the last success.
+	ConsecutiveFailures int        `json:"consecutiveFailures" example:"0"`
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty" example:"2023-11-15T14:30:45Z"`
+	LastFailure         *time.Time `json:"lastFailure,omitempty" example:"2023-11-15T14:20:10Z"`
+}
+
 type ModelPullStatus struct {
 	Name          string       `json:"name" example:"Mistral 7B Instruct"`
 	Model         string       `json:"model" example:"mistral:instruct"`
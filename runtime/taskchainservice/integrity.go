@@ -0,0 +1,131 @@
+package taskchainservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// References lists what a saved chain may refer to. Nil fields skip the
+// corresponding check.
+type References struct {
+	// Tools lists the registered hooks (tools).
+	Tools taskengine.ToolsRegistry
+	// Models returns the names of the declared models.
+	Models func(ctx context.Context) ([]string, error)
+	// Providers returns the declared provider types, e.g. "ollama" or "openai".
+	Providers func(ctx context.Context) ([]string, error)
+}
+
+// ValidationError is returned by CreateAtPath and UpdateAtPath of a service
+// wrapped with WithIntegrityChecks when the chain has errors. It wraps
+// errdefs.ErrBadRequest.
+type ValidationError struct {
+	Path        string                 `json:"path"`
+	Diagnostics taskengine.Diagnostics `json:"diagnostics"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("task chain %s: %v", e.Path, e.Diagnostics.Err())
+}
+
+func (e *ValidationError) Unwrap() error {
+	return errdefs.ErrBadRequest
+}
+
+type integrityDecorator struct {
+	service Service
+	refs    References
+}
+
+// WithIntegrityChecks wraps a task chain service so chains are validated
+// before they are written: the chain itself (handlers, transitions,
+// templates), its imports, and the hooks, models and providers it references.
+// A chain with errors is rejected with a *ValidationError; warnings do not
+// block the write.
+func WithIntegrityChecks(service Service, refs References) Service {
+	return &integrityDecorator{service: service, refs: refs}
+}
+
+func (d *integrityDecorator) Get(ctx context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
+	return d.service.Get(ctx, ref)
+}
+
+func (d *integrityDecorator) List(ctx context.Context) ([]string, error) {
+	return d.service.List(ctx)
+}
+
+func (d *integrityDecorator) CreateAtPath(ctx context.Context, path string, chain *taskengine.TaskChainDefinition) error {
+	if err := d.check(ctx, path, chain); err != nil {
+		return err
+	}
+	return d.service.CreateAtPath(ctx, path, chain)
+}
+
+func (d *integrityDecorator) UpdateAtPath(ctx context.Context, path string, chain *taskengine.TaskChainDefinition) error {
+	if err := d.check(ctx, path, chain); err != nil {
+		return err
+	}
+	return d.service.UpdateAtPath(ctx, path, chain)
+}
+
+func (d *integrityDecorator) DeleteByPath(ctx context.Context, path string) error {
+	return d.service.DeleteByPath(ctx, path)
+}
+
+// check validates chain and returns a *ValidationError if it has errors.
+// Lookup failures of the references are returned as is.
+func (d *integrityDecorator) check(ctx context.Context, path string, chain *taskengine.TaskChainDefinition) error {
+	if err := validateChain(chain); err != nil {
+		return err
+	}
+	var diags taskengine.Diagnostics
+
+	// Imported tasks can be transition targets, so the chain is validated
+	// with its imports resolved against the chains already stored.
+	resolved, err := taskengine.ResolveImports(ctx, chain, d.service.Get)
+	if err != nil {
+		diags = append(diags, taskengine.Diagnostic{
+			Severity: taskengine.SeverityError,
+			Field:    "imports",
+			Message:  err.Error(),
+		})
+		resolved = chain
+	}
+	diags = append(diags, taskengine.ValidateChain(resolved)...)
+
+	if d.refs.Tools != nil {
+		names, err := d.refs.Tools.Supports(ctx)
+		if err != nil {
+			return fmt.Errorf("list hooks: %w", err)
+		}
+		diags = append(diags, taskengine.ValidateChainTools(resolved, names)...)
+	}
+	var models, providers []string
+	if d.refs.Models != nil {
+		if models, err = d.refs.Models(ctx); err != nil {
+			return fmt.Errorf("list models: %w", err)
+		}
+		if models == nil {
+			models = []string{}
+		}
+	}
+	if d.refs.Providers != nil {
+		if providers, err = d.refs.Providers(ctx); err != nil {
+			return fmt.Errorf("list providers: %w", err)
+		}
+		if providers == nil {
+			providers = []string{}
+		}
+	}
+	diags = append(diags, taskengine.ValidateChainModels(resolved, models, providers)...)
+
+	if diags.HasErrors() {
+		return &ValidationError{Path: path, Diagnostics: diags}
+	}
+	return nil
+}
+
+var _ Service = (*integrityDecorator)(nil)
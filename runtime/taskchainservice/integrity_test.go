@@ -0,0 +1,99 @@
+package taskchainservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/taskchainservice"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memService is an in-memory Service keyed by path.
+type memService map[string]*taskengine.TaskChainDefinition
+
+func (m memService) Get(_ context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
+	for path, chain := range m {
+		if path == ref || chain.ID == ref {
+			return chain, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m memService) List(context.Context) ([]string, error) { return nil, nil }
+
+func (m memService) CreateAtPath(_ context.Context, path string, chain *taskengine.TaskChainDefinition) error {
+	m[path] = chain
+	return nil
+}
+
+func (m memService) UpdateAtPath(_ context.Context, path string, chain *taskengine.TaskChainDefinition) error {
+	m[path] = chain
+	return nil
+}
+
+func (m memService) DeleteByPath(_ context.Context, path string) error {
+	delete(m, path)
+	return nil
+}
+
+type hooks []string
+
+func (h hooks) Supports(context.Context) ([]string, error) { return h, nil }
+
+func endTransition() taskengine.TaskTransition {
+	return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}}}
+}
+
+func TestIntegrityChecks_RejectBrokenReferences(t *testing.T) {
+	ctx := context.Background()
+	store := memService{}
+	svc := taskchainservice.WithIntegrityChecks(store, taskchainservice.References{
+		Tools:     hooks{"webhook"},
+		Models:    func(context.Context) ([]string, error) { return []string{"qwen2.5:7b"}, nil },
+		Providers: func(context.Context) ([]string, error) { return []string{"ollama"}, nil },
+	})
+
+	good := &taskengine.TaskChainDefinition{
+		ID: "good",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:             "ask",
+			Handler:        taskengine.HandlePromptToString,
+			PromptTemplate: "Summarize {{.input}} for {{var:audience}}",
+			ExecuteConfig:  &taskengine.LLMExecutionConfig{Model: "qwen2.5:7b", Provider: "ollama"},
+			Transition:     endTransition(),
+		}},
+	}
+	require.NoError(t, svc.CreateAtPath(ctx, "good.yaml", good))
+
+	bad := &taskengine.TaskChainDefinition{
+		ID:      "bad",
+		Imports: []taskengine.ChainImport{{Chain: "missing", As: "lib"}},
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "ask",
+				Handler:        taskengine.HandlePromptToString,
+				PromptTemplate: "Summarize {{.input",
+				ExecuteConfig:  &taskengine.LLMExecutionConfig{Model: "qwen2.5:7", Provider: "openai"},
+				Transition:     taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: "notify"}}},
+			},
+			{ID: "notify", Handler: taskengine.HandleTools, Tools: &taskengine.ToolsCall{Name: "webhok"}, Transition: endTransition()},
+		},
+	}
+	err := svc.CreateAtPath(ctx, "bad.yaml", bad)
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+	var verr *taskchainservice.ValidationError
+	require.ErrorAs(t, err, &verr)
+	fields := map[string]bool{}
+	for _, d := range verr.Diagnostics {
+		fields[d.Field] = true
+	}
+	for _, field := range []string{"imports", "prompt_template", "execute_config.model", "execute_config.provider", "tools.name"} {
+		assert.True(t, fields[field], "expected a diagnostic for %s, got %v", field, verr.Diagnostics)
+	}
+	assert.NotContains(t, store, "bad.yaml")
+}
@@ -6,9 +6,10 @@ import (
 	"github.com/contenox/contenox/runtime/taskengine"
 )
 
-// Service loads and stores task chain definitions as JSON in the VFS (see NewVFS).
+// Service loads and stores task chain definitions as JSON or YAML files in the VFS (see NewVFS);
+// the format follows the file extension (.json, .yaml, .yml).
 // Get accepts either a relative VFS path (e.g. default-chain.json) or a logical chain id
-// (inner JSON "id") by scanning root-level *.json files.
+// (inner "id") by scanning root-level chain files.
 type Service interface {
 	Get(ctx context.Context, ref string) (*taskengine.TaskChainDefinition, error)
 	List(ctx context.Context) ([]string, error)
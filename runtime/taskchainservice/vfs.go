@@ -2,7 +2,6 @@ package taskchainservice
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -12,7 +11,7 @@ import (
 	"github.com/contenox/contenox/runtime/vfsservice"
 )
 
-// vfsStore persists task chains as JSON files via vfsservice.Service (same storage as /api/files).
+// vfsStore persists task chains as JSON or YAML files via vfsservice.Service (same storage as /api/files).
 type vfsStore struct {
 	vfs vfsservice.Service
 }
@@ -67,26 +66,22 @@ func validateChain(chain *taskengine.TaskChainDefinition) error {
 	return nil
 }
 
-func parseChainJSON(data []byte) (*taskengine.TaskChainDefinition, error) {
-	var chain taskengine.TaskChainDefinition
-	if err := json.Unmarshal(data, &chain); err != nil {
-		return nil, err
+// chainContentType returns the content type stored with a chain file.
+func chainContentType(name string) string {
+	if taskengine.IsYAMLChainPath(name) {
+		return "application/yaml"
 	}
-	return &chain, nil
-}
-
-func isJSONName(name string) bool {
-	return strings.EqualFold(filepath.Ext(name), ".json")
+	return "application/json"
 }
 
-func (s *vfsStore) listRootJSON(ctx context.Context) ([]vfsservice.File, error) {
+func (s *vfsStore) listRootChains(ctx context.Context) ([]vfsservice.File, error) {
 	files, err := s.vfs.GetFilesByPath(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 	var out []vfsservice.File
 	for _, f := range files {
-		if isJSONName(f.Name) {
+		if taskengine.IsChainPath(f.Name) {
 			out = append(out, f)
 		}
 	}
@@ -98,9 +93,9 @@ func (s *vfsStore) loadFileFull(ctx context.Context, fileID string) (*taskengine
 	if err != nil {
 		return nil, err
 	}
-	chain, err := parseChainJSON(f.Data)
+	chain, err := taskengine.ParseChainDefinition(fileID, f.Data)
 	if err != nil {
-		return nil, fmt.Errorf("parse chain json: %w", err)
+		return nil, fmt.Errorf("parse chain %s: %w", fileID, err)
 	}
 	if chain.ID == "" || len(chain.Tasks) == 0 {
 		return nil, fmt.Errorf("not a valid task chain document")
@@ -108,7 +103,7 @@ func (s *vfsStore) loadFileFull(ctx context.Context, fileID string) (*taskengine
 	return chain, nil
 }
 
-// Get loads a chain by VFS path (e.g. chain-foo.json) or by logical id (scans root *.json for matching chain.id).
+// Get loads a chain by VFS path (e.g. chain-foo.json) or by logical id (scans root *.json, *.yaml and *.yml for matching chain.id).
 func (s *vfsStore) Get(ctx context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
 	if ref == "" {
 		return nil, fmt.Errorf("task chain reference is required")
@@ -118,7 +113,7 @@ func (s *vfsStore) Get(ctx context.Context, ref string) (*taskengine.TaskChainDe
 			return chain, nil
 		}
 	}
-	files, err := s.listRootJSON(ctx)
+	files, err := s.listRootChains(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("list chain files: %w", err)
 	}
@@ -134,9 +129,9 @@ func (s *vfsStore) Get(ctx context.Context, ref string) (*taskengine.TaskChainDe
 	return nil, fmt.Errorf("task chain %q: %w", ref, libdb.ErrNotFound)
 }
 
-// List returns the relative paths of all *.json files in the chain VFS root.
+// List returns the relative paths of all chain files (*.json, *.yaml, *.yml) in the chain VFS root.
 func (s *vfsStore) List(ctx context.Context) ([]string, error) {
-	files, err := s.listRootJSON(ctx)
+	files, err := s.listRootChains(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +142,8 @@ func (s *vfsStore) List(ctx context.Context) ([]string, error) {
 	return paths, nil
 }
 
-// CreateAtPath writes a new JSON file at the given VFS path. Fails if the file already exists.
+// CreateAtPath writes a new chain file at the given VFS path, as YAML for .yaml/.yml
+// names and JSON otherwise. Fails if the file already exists.
 func (s *vfsStore) CreateAtPath(ctx context.Context, path string, chain *taskengine.TaskChainDefinition) error {
 	if err := validateChain(chain); err != nil {
 		return err
@@ -160,14 +156,14 @@ func (s *vfsStore) CreateAtPath(ctx context.Context, path string, chain *taskeng
 	if name == "" {
 		return fmt.Errorf("path must include a file name")
 	}
-	if !isJSONName(name) {
-		return fmt.Errorf("chain file must have .json extension")
+	if !taskengine.IsChainPath(name) {
+		return fmt.Errorf("chain file must have .json, .yaml or .yml extension")
 	}
 	existing, gerr := s.vfs.GetFileByID(ctx, rel)
 	if gerr == nil && existing != nil && len(existing.Data) > 0 {
 		return fmt.Errorf("task chain file already exists: %s", rel)
 	}
-	data, err := json.MarshalIndent(chain, "", "  ")
+	data, err := taskengine.MarshalChainDefinition(name, chain)
 	if err != nil {
 		return fmt.Errorf("marshal chain: %w", err)
 	}
@@ -175,7 +171,7 @@ func (s *vfsStore) CreateAtPath(ctx context.Context, path string, chain *taskeng
 		Name:        name,
 		ParentID:    parentID,
 		Data:        data,
-		ContentType: "application/json",
+		ContentType: chainContentType(name),
 	})
 	if err != nil {
 		return fmt.Errorf("create chain file: %w", err)
@@ -183,7 +179,7 @@ func (s *vfsStore) CreateAtPath(ctx context.Context, path string, chain *taskeng
 	return nil
 }
 
-// UpdateAtPath replaces the file at path with chain JSON.
+// UpdateAtPath replaces the file at path with chain, keeping the file's format.
 func (s *vfsStore) UpdateAtPath(ctx context.Context, path string, chain *taskengine.TaskChainDefinition) error {
 	if err := validateChain(chain); err != nil {
 		return err
@@ -196,14 +192,14 @@ func (s *vfsStore) UpdateAtPath(ctx context.Context, path string, chain *taskeng
 	if err != nil || prev == nil || len(prev.Data) == 0 {
 		return fmt.Errorf("task chain file not found: %w", libdb.ErrNotFound)
 	}
-	data, err := json.MarshalIndent(chain, "", "  ")
+	data, err := taskengine.MarshalChainDefinition(rel, chain)
 	if err != nil {
 		return fmt.Errorf("marshal chain: %w", err)
 	}
 	_, err = s.vfs.UpdateFile(ctx, &vfsservice.File{
 		ID:          rel,
 		Data:        data,
-		ContentType: "application/json",
+		ContentType: chainContentType(rel),
 	})
 	if err != nil {
 		return fmt.Errorf("update chain file: %w", err)
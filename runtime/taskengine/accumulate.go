@@ -0,0 +1,104 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// accumulatorText is the text of a list entry used for prompts and token
+// estimates: strings as they are, everything else as JSON.
+func accumulatorText(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return marshalString(v)
+}
+
+// accumulatorTokens estimates the token size of entries at ~4 characters
+// per token, the approximation used for compaction.
+func accumulatorTokens(entries []any) int {
+	chars := 0
+	for _, e := range entries {
+		chars += len(accumulatorText(e))
+	}
+	return chars / 4
+}
+
+func validateAppendConfig(cfg *AppendConfig) error {
+	switch {
+	case cfg.Var == "":
+		return errors.New("append_to requires a var")
+	case cfg.MaxItems < 0:
+		return errors.New("max_items must not be negative")
+	case cfg.MaxTokens < 0:
+		return errors.New("max_tokens must not be negative")
+	}
+	return nil
+}
+
+// appendOutput adds output to the list task.AppendTo names in store and
+// enforces the configured caps.
+func (env SimpleEnv) appendOutput(ctx context.Context, chain *TaskChainDefinition, task *TaskDefinition, store map[string]any, output any) error {
+	cfg := task.AppendTo
+	if err := validateAppendConfig(cfg); err != nil {
+		return err
+	}
+	var entries []any
+	switch existing := store[cfg.Var].(type) {
+	case nil:
+	case []any:
+		entries = existing
+	default:
+		return fmt.Errorf("variable %q holds a %T, not a list", cfg.Var, existing)
+	}
+	// Copy so lists already captured in checkpoints or outputs stay unchanged.
+	entries = append(entries[:len(entries):len(entries)], output)
+
+	over := func() bool {
+		return (cfg.MaxItems > 0 && len(entries) > cfg.MaxItems) ||
+			(cfg.MaxTokens > 0 && accumulatorTokens(entries) > cfg.MaxTokens)
+	}
+	if over() && cfg.SummarizePrompt != "" {
+		summary, err := env.summarizeEntries(ctx, chain, task, entries)
+		if err != nil {
+			return err
+		}
+		entries = []any{summary}
+	}
+	for len(entries) > 1 && over() {
+		entries = entries[1:]
+	}
+	store[cfg.Var] = entries
+	return nil
+}
+
+// summarizeEntries runs cfg.SummarizePrompt over entries with the execute
+// config and system instruction of task.
+func (env SimpleEnv) summarizeEntries(ctx context.Context, chain *TaskChainDefinition, task *TaskDefinition, entries []any) (string, error) {
+	texts := make([]string, len(entries))
+	for i, e := range entries {
+		texts[i] = accumulatorText(e)
+	}
+	prompt, err := renderTemplate(task.AppendTo.SummarizePrompt, map[string]any{
+		"items":   strings.Join(texts, "\n\n"),
+		"entries": texts,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarize_prompt: %w", err)
+	}
+	summarize := &TaskDefinition{
+		ID:                task.ID + "_summarize",
+		Handler:           HandlePromptToString,
+		SystemInstruction: task.SystemInstruction,
+		ExecuteConfig:     task.ExecuteConfig,
+	}
+	chainContext := &ChainContext{Tools: map[string]ToolWithResolution{}, Debug: chain.Debug}
+	output, _, _, err := env.exec.TaskExec(ctx, time.Now().UTC(), int(chain.TokenLimit), chainContext, summarize, prompt, DataTypeString)
+	if err != nil {
+		return "", fmt.Errorf("summarize: %w", err)
+	}
+	return accumulatorText(output), nil
+}
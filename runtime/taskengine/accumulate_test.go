@@ -0,0 +1,105 @@
+package taskengine_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopExecutor answers the "step" task with a numbered finding and the
+// transition "again" until rounds findings were produced, "report" tasks with
+// their input and summarize tasks with a fixed summary.
+type loopExecutor struct {
+	rounds  int
+	step    int
+	prompts []string
+}
+
+func (l *loopExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, dataType taskengine.DataType) (any, taskengine.DataType, string, error) {
+	switch task.ID {
+	case "step":
+		l.step++
+		eval := "again"
+		if l.step == l.rounds {
+			eval = "done"
+		}
+		return fmt.Sprintf("finding %d", l.step), taskengine.DataTypeString, eval, nil
+	case "step_summarize":
+		l.prompts = append(l.prompts, input.(string))
+		return "summary", taskengine.DataTypeString, "summary", nil
+	}
+	return input, dataType, "ok", nil
+}
+
+func loopChain(cfg *taskengine.AppendConfig) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "research",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:       "step",
+				Handler:  taskengine.HandlePromptToString,
+				AppendTo: cfg,
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpEquals, When: "again", Goto: "step"},
+					{Operator: taskengine.OpDefault, Goto: "report"},
+				}},
+			},
+			{
+				ID:             "report",
+				Handler:        taskengine.HandleNoop,
+				PromptTemplate: "{{range vars.findings}}- {{.}}\n{{end}}",
+				Transition:     taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}}},
+			},
+		},
+	}
+}
+
+func TestAppendTo_CollectsEveryIteration(t *testing.T) {
+	exec := &loopExecutor{rounds: 3}
+	out, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), loopChain(&taskengine.AppendConfig{Var: "findings"}), "topic", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "- finding 1\n- finding 2\n- finding 3\n", out)
+}
+
+func TestAppendTo_DropsOldestWithoutPrompt(t *testing.T) {
+	exec := &loopExecutor{rounds: 4}
+	out, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), loopChain(&taskengine.AppendConfig{Var: "findings", MaxItems: 2}), "topic", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "- finding 3\n- finding 4\n", out)
+}
+
+func TestAppendTo_SummarizesWhenOverCap(t *testing.T) {
+	exec := &loopExecutor{rounds: 4}
+	cfg := &taskengine.AppendConfig{Var: "findings", MaxItems: 2, SummarizePrompt: "Merge:\n{{.items}}"}
+	out, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), loopChain(cfg), "topic", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "- summary\n- finding 4\n", out)
+	require.Len(t, exec.prompts, 1)
+	assert.Equal(t, "Merge:\nfinding 1\n\nfinding 2\n\nfinding 3", exec.prompts[0])
+}
+
+func TestAppendTo_TokenCap(t *testing.T) {
+	exec := &loopExecutor{rounds: 3}
+	// Each finding is 9 characters, about 2 tokens.
+	out, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), loopChain(&taskengine.AppendConfig{Var: "findings", MaxTokens: 4}), "topic", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "- finding 2\n- finding 3\n", out)
+}
+
+func TestAppendTo_Validation(t *testing.T) {
+	chain := loopChain(&taskengine.AppendConfig{MaxItems: -1, SummarizePrompt: "{{.items"})
+	diags := taskengine.ValidateChain(chain)
+	var fields []string
+	for _, d := range diags {
+		if d.Severity == taskengine.SeverityError {
+			fields = append(fields, d.Field)
+		}
+	}
+	assert.Contains(t, fields, "append_to")
+	assert.Contains(t, fields, "append_to.summarize_prompt")
+}
@@ -0,0 +1,58 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const defaultAgentLoopIterations = 10
+
+// TransitionMaxIterations is the transition value of an agent_loop task whose
+// model still requested tools after the last allowed iteration.
+const TransitionMaxIterations = "max_iterations"
+
+// agentLoop runs chat_completion and execute_tool_calls alternately with the
+// task's configuration until the model stops calling tools.
+func (exe *SimpleExec) agentLoop(ctx context.Context, startingTime time.Time, ctxLength int, chainContext *ChainContext, task *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	maxIterations := defaultAgentLoopIterations
+	if task.AgentLoop != nil && task.AgentLoop.MaxIterations > 0 {
+		maxIterations = task.AgentLoop.MaxIterations
+	}
+	chatTask := *task
+	chatTask.Handler = HandleChatCompletion
+	toolTask := *task
+	toolTask.Handler = HandleExecuteToolCalls
+
+	output, outputType := input, dataType
+	for i := 1; i <= maxIterations; i++ {
+		if i > 1 && wrapUpDue(ctx) {
+			return output, outputType, TransitionWrapUp, nil
+		}
+		var eval string
+		var err error
+		output, outputType, eval, err = exe.TaskExec(ctx, startingTime, ctxLength, chainContext, &chatTask, output, outputType)
+		if err != nil {
+			return nil, DataTypeAny, "", fmt.Errorf("agent_loop iteration %d: %w", i, err)
+		}
+		if eval != "tool-call" {
+			return output, outputType, eval, nil
+		}
+		output, outputType, eval, err = exe.TaskExec(ctx, startingTime, ctxLength, chainContext, &toolTask, output, outputType)
+		if err != nil {
+			return output, outputType, eval, fmt.Errorf("agent_loop iteration %d: %w", i, err)
+		}
+		if eval != "tools_executed" {
+			// None of the requested tools is wired into the chain; another
+			// round would request them again.
+			return output, outputType, eval, nil
+		}
+		if i == 1 && chatTask.ExecuteConfig != nil && chatTask.ExecuteConfig.ToolChoice.forcesCall() {
+			// A forced call on every turn would never let the model answer.
+			cfg := *chatTask.ExecuteConfig
+			cfg.ToolChoice = nil
+			chatTask.ExecuteConfig = &cfg
+		}
+	}
+	return output, outputType, TransitionMaxIterations, nil
+}
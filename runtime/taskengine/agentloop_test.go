@@ -0,0 +1,80 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolCallingRepo requests the echo tool until it has seen rounds tool results.
+func toolCallingRepo(rounds int) *mockModelRepo {
+	return &mockModelRepo{
+		chatFunc: func(_ context.Context, _ llmrepo.Request, messages []libmodelprovider.Message) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+			seen := 0
+			for _, m := range messages {
+				if m.Role == "tool" {
+					seen++
+				}
+			}
+			if seen >= rounds {
+				return libmodelprovider.ChatResult{Message: libmodelprovider.Message{Role: "assistant", Content: "done"}}, llmrepo.Meta{}, nil
+			}
+			call := libmodelprovider.ToolCall{ID: "call", Type: "function"}
+			call.Function.Name = "echo.say"
+			call.Function.Arguments = `{"text":"hi"}`
+			return libmodelprovider.ChatResult{
+				Message:   libmodelprovider.Message{Role: "assistant", ToolCalls: []libmodelprovider.ToolCall{call}},
+				ToolCalls: []libmodelprovider.ToolCall{call},
+			}, llmrepo.Meta{}, nil
+		},
+	}
+}
+
+func runAgentLoop(t *testing.T, repo *mockModelRepo, maxIterations int) (any, string, *tools.MockToolsRepo, error) {
+	t.Helper()
+	toolsRepo := tools.NewMockToolsRegistry()
+	exec, err := taskengine.NewExec(context.Background(), repo, toolsRepo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	chainCtx := &taskengine.ChainContext{Tools: map[string]taskengine.ToolWithResolution{
+		"echo.say": {Tool: taskengine.Tool{Type: "function", Function: taskengine.FunctionTool{Name: "echo.say"}}, ToolsName: "echo"},
+	}}
+	task := &taskengine.TaskDefinition{
+		ID:            "agent",
+		Handler:       taskengine.HandleAgentLoop,
+		ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "test-model"},
+		AgentLoop:     &taskengine.AgentLoopConfig{MaxIterations: maxIterations},
+	}
+	out, _, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, chainCtx, task, "say hi twice", taskengine.DataTypeString)
+	return out, transition, toolsRepo, err
+}
+
+func TestUnit_AgentLoop_RunsUntilModelStopsCallingTools(t *testing.T) {
+	out, transition, toolsRepo, err := runAgentLoop(t, toolCallingRepo(2), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "executed", transition)
+	assert.Len(t, toolsRepo.Calls, 2)
+
+	history, ok := out.(taskengine.ChatHistory)
+	require.True(t, ok)
+	roles := make([]string, len(history.Messages))
+	for i, m := range history.Messages {
+		roles[i] = m.Role
+	}
+	assert.Equal(t, []string{"user", "assistant", "tool", "assistant", "tool", "assistant"}, roles)
+	assert.Equal(t, "done", history.Messages[len(history.Messages)-1].Content)
+}
+
+func TestUnit_AgentLoop_StopsAtMaxIterations(t *testing.T) {
+	_, transition, toolsRepo, err := runAgentLoop(t, toolCallingRepo(100), 3)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.TransitionMaxIterations, transition)
+	assert.Len(t, toolsRepo.Calls, 3)
+}
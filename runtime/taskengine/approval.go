@@ -0,0 +1,138 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// Transition values reported by an await_approval task once a decision was made.
+const (
+	TransitionApproved = "approved"
+	TransitionRejected = "rejected"
+)
+
+// ErrApprovalRequired is returned by ExecEnv when a run paused at an
+// await_approval task. The returned error is an *ApprovalRequiredError.
+var ErrApprovalRequired = errors.New("approval required")
+
+// ErrApprovalRejected is the task error of a rejected await_approval task
+// whose transition has no explicit "rejected" branch.
+var ErrApprovalRejected = errors.New("approval rejected")
+
+// ErrNoPendingApproval is returned by Approve and Reject when the run is not
+// waiting for an approval.
+var ErrNoPendingApproval = errors.New("run is not waiting for approval")
+
+// PendingApproval describes the approval a paused run is waiting for.
+type PendingApproval struct {
+	TaskID string `json:"taskId"`
+	// Message is the rendered prompt_template of the task, or its input.
+	Message     string    `json:"message"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// ApprovalDecision is the answer to a PendingApproval.
+type ApprovalDecision struct {
+	TaskID    string    `json:"taskId"`
+	Approved  bool      `json:"approved"`
+	Reason    string    `json:"reason,omitempty"`
+	DecidedAt time.Time `json:"decidedAt"`
+}
+
+func (d *ApprovalDecision) transition() string {
+	if d.Approved {
+		return TransitionApproved
+	}
+	return TransitionRejected
+}
+
+// ApprovalRequiredError reports a run paused at an await_approval task.
+type ApprovalRequiredError struct {
+	RunID   string
+	TaskID  string
+	Message string
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("run %s: task %s: %s: %s", e.RunID, e.TaskID, ErrApprovalRequired, e.Message)
+}
+
+func (e *ApprovalRequiredError) Unwrap() error { return ErrApprovalRequired }
+
+// Approve records the approval of the pending await_approval task of runID.
+// Resuming the run (see WithCheckpoints) then continues with the "approved"
+// transition.
+func Approve(ctx context.Context, store CheckpointStore, runID, reason string) (*Checkpoint, error) {
+	return decideApproval(ctx, store, runID, true, reason)
+}
+
+// Reject records the rejection of the pending await_approval task of runID.
+// Resuming the run then follows the "rejected" branch of the task, or fails
+// the task with ErrApprovalRejected when it has none.
+func Reject(ctx context.Context, store CheckpointStore, runID, reason string) (*Checkpoint, error) {
+	return decideApproval(ctx, store, runID, false, reason)
+}
+
+func decideApproval(ctx context.Context, store CheckpointStore, runID string, approved bool, reason string) (*Checkpoint, error) {
+	cp, err := store.LoadCheckpoint(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if cp.PendingApproval == nil {
+		return nil, fmt.Errorf("run %s: %w", runID, ErrNoPendingApproval)
+	}
+	cp.Approval = &ApprovalDecision{
+		TaskID:    cp.PendingApproval.TaskID,
+		Approved:  approved,
+		Reason:    reason,
+		DecidedAt: time.Now().UTC(),
+	}
+	if err := store.SaveCheckpoint(ctx, cp); err != nil {
+		return nil, fmt.Errorf("save approval for run %s: %w", runID, err)
+	}
+	return cp, nil
+}
+
+// requestApproval pauses the run at task: it persists cp with a pending
+// approval and returns the *ApprovalRequiredError ExecEnv reports.
+func (c *checkpointing) requestApproval(ctx context.Context, cp *Checkpoint, task *TaskDefinition, input any) error {
+	if c == nil {
+		return fmt.Errorf("task %s: await_approval requires a checkpointed run (see WithCheckpoints) %w", task.ID, errdefs.ErrBadRequest)
+	}
+	cp.PendingApproval = &PendingApproval{
+		TaskID:      task.ID,
+		Message:     approvalMessage(input),
+		RequestedAt: time.Now().UTC(),
+	}
+	cp.RunID = c.runID
+	cp.UpdatedAt = time.Now().UTC()
+	// Unlike the per-step checkpoints this one must be stored: without it
+	// the run could not be resumed after the decision.
+	if err := c.store.SaveCheckpoint(ctx, cp); err != nil {
+		return fmt.Errorf("task %s: persist approval request: %w", task.ID, err)
+	}
+	return &ApprovalRequiredError{RunID: c.runID, TaskID: task.ID, Message: cp.PendingApproval.Message}
+}
+
+func approvalMessage(input any) string {
+	switch v := input.(type) {
+	case string:
+		return v
+	case ChatHistory:
+		if len(v.Messages) > 0 {
+			return v.Messages[len(v.Messages)-1].Content
+		}
+		return ""
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
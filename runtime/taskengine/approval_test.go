@@ -0,0 +1,116 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func approvalChain(rejectBranch bool) *taskengine.TaskChainDefinition {
+	gate := taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+		{Operator: taskengine.OpEquals, When: taskengine.TransitionApproved, Goto: "deploy"},
+	}}
+	if rejectBranch {
+		gate.Branches = append(gate.Branches, taskengine.TransitionBranch{Operator: taskengine.OpEquals, When: taskengine.TransitionRejected, Goto: "notify"})
+	}
+	return &taskengine.TaskChainDefinition{
+		ID: "gated",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "plan", Handler: taskengine.HandleNoop, Transition: goTo("gate")},
+			{ID: "gate", Handler: taskengine.HandleAwaitApproval, PromptTemplate: "Deploy {{.plan}}?", Transition: gate},
+			{ID: "deploy", Handler: taskengine.HandleNoop, Transition: goTo(taskengine.TermEnd)},
+			{ID: "notify", Handler: taskengine.HandleNoop, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+}
+
+func TestApproval_PauseAndApprove(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-1")
+
+	first := &flakyExecutor{}
+	_, _, _, err := setupTestEnv(first).ExecEnv(ctx, approvalChain(false), "start", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrApprovalRequired)
+	var pause *taskengine.ApprovalRequiredError
+	require.True(t, errors.As(err, &pause))
+	assert.Equal(t, "run-1", pause.RunID)
+	assert.Equal(t, "gate", pause.TaskID)
+	assert.Equal(t, "Deploy plan-done?", pause.Message)
+	assert.Equal(t, []string{"plan"}, first.ran)
+
+	cp, err := store.LoadCheckpoint(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "gate", cp.NextTaskID)
+	require.NotNil(t, cp.PendingApproval)
+
+	// Resuming without a decision pauses again.
+	_, _, _, err = setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+	require.ErrorIs(t, err, taskengine.ErrApprovalRequired)
+
+	cp, err = taskengine.Approve(ctx, store, "run-1", "looks good")
+	require.NoError(t, err)
+	require.NotNil(t, cp.Approval)
+	assert.True(t, cp.Approval.Approved)
+
+	second := &flakyExecutor{}
+	out, _, _, err := setupTestEnv(second).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy-done", out)
+	assert.Equal(t, []string{"deploy"}, second.ran)
+	assert.Equal(t, "Deploy plan-done?", second.inputs["deploy"], "the approval task passes its input through")
+
+	_, err = store.LoadCheckpoint(ctx, "run-1")
+	assert.ErrorIs(t, err, taskengine.ErrCheckpointNotFound)
+}
+
+func TestApproval_Reject(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		rejectBranch bool
+	}{
+		{name: "branch", rejectBranch: true},
+		{name: "no branch", rejectBranch: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &memCheckpointStore{}
+			ctx := taskengine.WithCheckpoints(context.Background(), store, "run-1")
+			chain := approvalChain(tc.rejectBranch)
+
+			_, _, _, err := setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, chain, "start", taskengine.DataTypeString)
+			require.ErrorIs(t, err, taskengine.ErrApprovalRequired)
+			_, err = taskengine.Reject(ctx, store, "run-1", "not during the freeze")
+			require.NoError(t, err)
+
+			exec := &flakyExecutor{}
+			out, _, _, err := setupTestEnv(exec).ExecEnv(ctx, chain, nil, taskengine.DataTypeAny)
+			if tc.rejectBranch {
+				require.NoError(t, err)
+				assert.Equal(t, "notify-done", out)
+				return
+			}
+			require.ErrorIs(t, err, taskengine.ErrApprovalRejected)
+			assert.Contains(t, err.Error(), "not during the freeze")
+			assert.Empty(t, exec.ran)
+		})
+	}
+}
+
+func TestApproval_Errors(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := context.Background()
+
+	_, err := taskengine.Approve(ctx, store, "missing", "")
+	assert.ErrorIs(t, err, taskengine.ErrCheckpointNotFound)
+
+	require.NoError(t, store.SaveCheckpoint(ctx, &taskengine.Checkpoint{RunID: "running", NextTaskID: "plan"}))
+	_, err = taskengine.Reject(ctx, store, "running", "")
+	assert.ErrorIs(t, err, taskengine.ErrNoPendingApproval)
+
+	_, _, _, err = setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, approvalChain(true), "start", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a checkpointed run")
+}
@@ -0,0 +1,81 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// TransitionWrapUp is the transition value of an agent_loop task that stopped
+// starting new iterations because the chain's max_duration is nearly
+// exhausted.
+const TransitionWrapUp = "wrap_up"
+
+// defaultWrapUpReserve divides MaxDuration into the time kept for the wrap-up
+// task when WrapUp.Reserve is not set.
+const defaultWrapUpReserve = 5
+
+// WrapUpConfig names the task a chain with a max_duration continues with once
+// its budget is nearly exhausted, e.g. a chat_completion that summarizes the
+// progress so far:
+//
+//	max_duration: 10m
+//	wrap_up:
+//	  task: summarize
+//	  reserve: 1m
+type WrapUpConfig struct {
+	// Task is the ID of the wrap-up task. It runs with the output of the task
+	// that finished last and then follows its own transitions.
+	Task string `yaml:"task" json:"task" example:"summarize"`
+
+	// Reserve is the part of MaxDuration kept for wrapping up, e.g. "1m".
+	// Defaults to a fifth of MaxDuration.
+	Reserve string `yaml:"reserve,omitempty" json:"reserve,omitempty" example:"1m"`
+}
+
+type wrapUpDeadlineKey struct{}
+
+// withWrapUpDeadline records the time after which the chain should wrap up.
+func withWrapUpDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return context.WithValue(ctx, wrapUpDeadlineKey{}, deadline)
+}
+
+// wrapUpDue reports whether the wrap-up deadline of the running chain passed.
+func wrapUpDue(ctx context.Context) bool {
+	deadline, ok := ctx.Value(wrapUpDeadlineKey{}).(time.Time)
+	return ok && !time.Now().Before(deadline)
+}
+
+// resolveWrapUp returns how long after its start the chain is routed to its
+// wrap-up task. Zero means the chain has no max_duration.
+func resolveWrapUp(chain *TaskChainDefinition) (time.Duration, error) {
+	if chain.MaxDuration == "" {
+		if chain.WrapUp != nil {
+			return 0, fmt.Errorf("wrap_up requires max_duration %w", errdefs.ErrBadRequest)
+		}
+		return 0, nil
+	}
+	maxDuration, err := time.ParseDuration(chain.MaxDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_duration: %v", err)
+	}
+	if maxDuration <= 0 {
+		return 0, fmt.Errorf("max_duration must be positive, got %q %w", chain.MaxDuration, errdefs.ErrBadRequest)
+	}
+	if chain.WrapUp == nil || chain.WrapUp.Task == "" {
+		return 0, fmt.Errorf("max_duration requires a wrap_up task %w", errdefs.ErrBadRequest)
+	}
+	reserve := maxDuration / defaultWrapUpReserve
+	if chain.WrapUp.Reserve != "" {
+		reserve, err = time.ParseDuration(chain.WrapUp.Reserve)
+		if err != nil {
+			return 0, fmt.Errorf("invalid wrap_up reserve: %v", err)
+		}
+		if reserve < 0 || reserve >= maxDuration {
+			return 0, fmt.Errorf("wrap_up reserve must be between 0 and max_duration, got %q %w", chain.WrapUp.Reserve, errdefs.ErrBadRequest)
+		}
+	}
+	return maxDuration - reserve, nil
+}
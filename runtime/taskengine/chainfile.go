@@ -0,0 +1,138 @@
+package taskengine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsYAMLChainPath reports whether a chain file name uses the YAML format
+// (.yaml or .yml extension). Every other name is treated as JSON.
+func IsYAMLChainPath(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsChainPath reports whether name has a supported chain file extension.
+func IsChainPath(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".json") || IsYAMLChainPath(name)
+}
+
+// ParseChainDefinition decodes a chain document, choosing the format from the
+// file name (see IsYAMLChainPath).
+//
+// YAML documents are converted to JSON before decoding, so both formats use
+// the same field names (the json tags) and the same decoding rules; a YAML
+// chain behaves exactly like the equivalent JSON chain.
+func ParseChainDefinition(name string, data []byte) (*TaskChainDefinition, error) {
+	if IsYAMLChainPath(name) {
+		converted, err := yamlToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse chain yaml: %w", err)
+		}
+		data = converted
+	}
+	var chain TaskChainDefinition
+	if err := json.Unmarshal(data, &chain); err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// MarshalChainDefinition encodes chain in the format implied by name: indented
+// JSON, or YAML with the JSON field names and field order. Multi-line strings
+// such as prompts are written as YAML block scalars.
+func MarshalChainDefinition(name string, chain *TaskChainDefinition) ([]byte, error) {
+	data, err := json.MarshalIndent(chain, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if !IsYAMLChainPath(name) {
+		return data, nil
+	}
+	// JSON is valid YAML: decoding it into a node keeps the field order.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&doc)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlToJSON converts a YAML document to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	normalized, err := normalizeYAMLValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalized)
+}
+
+// normalizeYAMLValue makes a decoded YAML value JSON-encodable.
+func normalizeYAMLValue(v any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			n, err := normalizeYAMLValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			t[k] = n
+		}
+		return t, nil
+	case map[any]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			n, err := normalizeYAMLValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = n
+		}
+		return out, nil
+	case []any:
+		for i, e := range t {
+			n, err := normalizeYAMLValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			t[i] = n
+		}
+		return t, nil
+	default:
+		return v, nil
+	}
+}
+
+// resetYAMLStyle switches a node tree decoded from JSON to block style.
+func resetYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetYAMLStyle(c)
+	}
+}
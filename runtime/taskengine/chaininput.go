@@ -0,0 +1,179 @@
+package taskengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ChainInput declares the input a chain accepts. ExecEnv coerces the input
+// to Type and validates it against Schema before the first task runs.
+//
+// Example:
+//
+//	input:
+//	  type: json
+//	  schema:
+//	    type: object
+//	    required: ["repo"]
+//	    properties:
+//	      repo: {type: string}
+type ChainInput struct {
+	// Type is the data type of the input: string, int, json, chat_history,
+	// vector or file. "any" or empty accepts every input.
+	Type string `yaml:"type,omitempty" json:"type,omitempty" example:"json"`
+	// Schema is the JSON Schema json inputs are validated against.
+	Schema map[string]any `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// ErrInvalidChainInput is wrapped into the error of ExecEnv when the input
+// does not match the chain's ChainInput.
+var ErrInvalidChainInput = errors.New("invalid chain input")
+
+// chainInputType returns the declared input type.
+func chainInputType(in *ChainInput) (DataType, error) {
+	if in == nil || in.Type == "" {
+		return DataTypeAny, nil
+	}
+	dt, err := DataTypeFromString(in.Type)
+	if err != nil {
+		return DataTypeAny, fmt.Errorf("input.type: %v %w", err, errdefs.ErrBadRequest)
+	}
+	if dt == DataTypeNil {
+		return DataTypeAny, fmt.Errorf("input.type: %q is not an input type %w", in.Type, errdefs.ErrBadRequest)
+	}
+	if len(in.Schema) > 0 && dt != DataTypeJSON {
+		return DataTypeAny, fmt.Errorf("input.schema requires input.type json %w", errdefs.ErrBadRequest)
+	}
+	return dt, nil
+}
+
+// compileChainInputSchema returns the validator of the declared schema, or
+// nil when there is none.
+func compileChainInputSchema(in *ChainInput) (*openapi3.Schema, error) {
+	if in == nil || len(in.Schema) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(in.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("input.schema: %w", err)
+	}
+	var schema openapi3.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("input.schema: %v %w", err, errdefs.ErrBadRequest)
+	}
+	return &schema, nil
+}
+
+// checkChainInput coerces input to the type declared by in and validates it
+// against the declared schema. Chains without a declaration accept input
+// unchanged.
+func checkChainInput(in *ChainInput, input any, dataType DataType) (any, DataType, error) {
+	target, err := chainInputType(in)
+	if err != nil {
+		return nil, DataTypeAny, err
+	}
+	schema, err := compileChainInputSchema(in)
+	if err != nil {
+		return nil, DataTypeAny, err
+	}
+	if target == DataTypeAny {
+		return input, dataType, nil
+	}
+	out, err := coerceChainInput(target, input)
+	if err != nil {
+		return nil, DataTypeAny, fmt.Errorf("%w: expected %s, got %s: %v", ErrInvalidChainInput, target.String(), dataType.String(), err)
+	}
+	if schema != nil {
+		if err := schema.VisitJSON(out, openapi3.MultiErrors()); err != nil {
+			return nil, DataTypeAny, fmt.Errorf("%w: does not match input.schema: %v", ErrInvalidChainInput, err)
+		}
+	}
+	return out, target, nil
+}
+
+// coerceChainInput converts input to target. Only lossless conversions are
+// made: strings are parsed as int or JSON, scalars and JSON are formatted as
+// strings and a string becomes a chat history with one user message.
+func coerceChainInput(target DataType, input any) (any, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input is empty")
+	}
+	switch target {
+	case DataTypeString:
+		if hist, ok := input.(ChatHistory); ok {
+			return nil, fmt.Errorf("cannot use a chat history of %d messages as string", len(hist.Messages))
+		}
+		if _, ok := input.(File); ok {
+			return nil, fmt.Errorf("cannot use a file as string")
+		}
+		s, _, err := coerceString(input)
+		return s, err
+	case DataTypeInt:
+		n, _, err := coerceInt(input, false)
+		return n, err
+	case DataTypeJSON:
+		switch input.(type) {
+		case ChatHistory, File:
+			return nil, fmt.Errorf("cannot use %T as json", input)
+		}
+		v, _, err := coerceJSON(input, false)
+		if err != nil {
+			return nil, err
+		}
+		// Round-trip so schema validation sees plain JSON values.
+		return convertToJSON(v)
+	case DataTypeChatHistory:
+		switch v := input.(type) {
+		case ChatHistory:
+			return v, nil
+		case string:
+			return ChatHistory{Messages: []Message{{Role: "user", Content: v}}}, nil
+		}
+	case DataTypeVector:
+		return coerceVector(input)
+	case DataTypeFile:
+		if f, ok := input.(File); ok {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", input, target.String())
+}
+
+// coerceVector converts a list of numbers, or a string holding a JSON list of
+// numbers, to a vector.
+func coerceVector(input any) ([]float64, error) {
+	switch v := input.(type) {
+	case []float64:
+		return v, nil
+	case []float32:
+		out := make([]float64, len(v))
+		for i, f := range v {
+			out[i] = float64(f)
+		}
+		return out, nil
+	case string:
+		var out []float64
+		if err := json.Unmarshal([]byte(v), &out); err != nil {
+			return nil, fmt.Errorf("not a JSON list of numbers: %w", err)
+		}
+		return out, nil
+	case []any:
+		out := make([]float64, len(v))
+		for i, e := range v {
+			switch n := e.(type) {
+			case float64:
+				out[i] = n
+			case int:
+				out[i] = float64(n)
+			default:
+				return nil, fmt.Errorf("element %d is %T, not a number", i, e)
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to vector", input)
+}
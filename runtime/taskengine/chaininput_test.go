@@ -0,0 +1,113 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typedInputChain(input *taskengine.ChainInput) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID:    "typed",
+		Input: input,
+		Tasks: []taskengine.TaskDefinition{{
+			ID:         "pass",
+			Handler:    taskengine.HandleNoop,
+			Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+		}},
+	}
+}
+
+func TestUnit_ChainInput_Coerces(t *testing.T) {
+	repoSchema := map[string]any{
+		"type":       "object",
+		"required":   []any{"repo"},
+		"properties": map[string]any{"repo": map[string]any{"type": "string"}},
+	}
+	tests := []struct {
+		name      string
+		input     *taskengine.ChainInput
+		value     any
+		valueType taskengine.DataType
+		want      any
+	}{
+		{"undeclared", nil, "as is", taskengine.DataTypeString, "as is"},
+		{"json from string", &taskengine.ChainInput{Type: "json", Schema: repoSchema},
+			`{"repo": "vibe"}`, taskengine.DataTypeString, map[string]any{"repo": "vibe"}},
+		{"int from string", &taskengine.ChainInput{Type: "int"}, " 7 ", taskengine.DataTypeString, 7},
+		{"string from int", &taskengine.ChainInput{Type: "string"}, 7, taskengine.DataTypeInt, "7"},
+		{"chat history from string", &taskengine.ChainInput{Type: "chat_history"}, "hi", taskengine.DataTypeString,
+			taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "hi"}}}},
+		{"vector from string", &taskengine.ChainInput{Type: "vector"}, "[1, 0.5]", taskengine.DataTypeString, []float64{1, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &taskengine.MockTaskExecutor{MockOutput: "done", MockTransitionValue: "ok"}
+			env := setupTestEnv(exec)
+			_, _, _, err := env.ExecEnv(context.Background(), typedInputChain(tt.input), tt.value, tt.valueType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exec.CalledWithInput)
+		})
+	}
+}
+
+func TestUnit_ChainInput_Rejects(t *testing.T) {
+	schema := map[string]any{"type": "object", "required": []any{"repo"}}
+	for _, tc := range []struct {
+		name      string
+		input     *taskengine.ChainInput
+		value     any
+		valueType taskengine.DataType
+		wantErr   string
+	}{
+		{"not json", &taskengine.ChainInput{Type: "json"}, "hello", taskengine.DataTypeString, "expected json, got string"},
+		{"schema mismatch", &taskengine.ChainInput{Type: "json", Schema: schema}, map[string]any{}, taskengine.DataTypeJSON, "does not match input.schema"},
+		{"not an int", &taskengine.ChainInput{Type: "int"}, "seven", taskengine.DataTypeString, "expected int"},
+		{"history as string", &taskengine.ChainInput{Type: "string"},
+			taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "hi"}}}, taskengine.DataTypeChatHistory, "expected string, got chat_history"},
+		{"file expected", &taskengine.ChainInput{Type: "file"}, "report.pdf", taskengine.DataTypeString, "expected file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			exec := &taskengine.MockTaskExecutor{MockOutput: "done", MockTransitionValue: "ok"}
+			env := setupTestEnv(exec)
+			_, _, _, err := env.ExecEnv(context.Background(), typedInputChain(tc.input), tc.value, tc.valueType)
+			require.ErrorIs(t, err, taskengine.ErrInvalidChainInput)
+			assert.ErrorContains(t, err, tc.wantErr)
+			assert.Nil(t, exec.CalledWithTask, "no task runs on invalid input")
+		})
+	}
+}
+
+func TestUnit_ChainInput_Validate(t *testing.T) {
+	diags := taskengine.ValidateChain(typedInputChain(&taskengine.ChainInput{Type: "string", Schema: map[string]any{"type": "object"}}))
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "input.type", diags[0].Field)
+
+	diags = taskengine.ValidateChain(typedInputChain(&taskengine.ChainInput{Type: "date"}))
+	require.True(t, diags.HasErrors())
+
+	chain := typedInputChain(&taskengine.ChainInput{Type: "json"})
+	chain.Tasks[0].Handler = taskengine.HandleChatCompletion
+	diags = taskengine.ValidateChain(chain)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags[0].Message, "the chain input produces json")
+}
+
+func TestUnit_ChainInput_NotCheckedOnResume(t *testing.T) {
+	chain := clarifyChain("plan")
+	chain.Input = &taskengine.ChainInput{Type: "json", Schema: map[string]any{"type": "object", "required": []any{"repo"}}}
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-1")
+
+	_, _, _, err := setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, chain, map[string]any{"repo": "vibe"}, taskengine.DataTypeJSON)
+	require.ErrorIs(t, err, taskengine.ErrClarificationRequired)
+	cp, err := taskengine.Answer(ctx, store, "run-1", "staging")
+	require.NoError(t, err)
+
+	out, _, _, err := setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+	require.NoError(t, err, "the run was started with a valid input")
+	assert.Equal(t, "deploy-done", out)
+}
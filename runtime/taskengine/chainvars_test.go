@@ -0,0 +1,125 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ChainVars_StoreAsAndInterpolation(t *testing.T) {
+	exec := &taskengine.MockTaskExecutor{
+		MockOutputSequence:          []any{"draft text", "a short title", "published"},
+		MockTransitionValueSequence: []string{"ok", "ok", "ok"},
+	}
+	env := setupTestEnv(exec)
+
+	next := func(id string) taskengine.TaskTransition {
+		return taskengine.TaskTransition{
+			Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}},
+		}
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID: "vars",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "draft", Handler: taskengine.HandlePromptToString, StoreAs: "draft", Transition: next("title")},
+			{
+				ID:             "title",
+				Handler:        taskengine.HandlePromptToString,
+				PromptTemplate: "Title for: {{vars.draft}}",
+				StoreAs:        "title",
+				Transition:     next("publish"),
+			},
+			{
+				ID:      "publish",
+				Handler: taskengine.HandleTools,
+				Tools: &taskengine.ToolsCall{
+					Name: "webhook",
+					Args: map[string]string{
+						"subject": "{{vars.title}}",
+						"body":    "{{.vars.draft}}",
+						"static":  "unchanged",
+					},
+				},
+				Transition: next(taskengine.TermEnd),
+			},
+		},
+	}
+
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "write a post", taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	require.NotNil(t, exec.CalledWithTask)
+	assert.Equal(t, map[string]string{
+		"subject": "a short title",
+		"body":    "draft text",
+		"static":  "unchanged",
+	}, exec.CalledWithTask.Tools.Args)
+	// The chain definition itself is left untouched.
+	assert.Equal(t, "{{vars.title}}", chain.Tasks[2].Tools.Args["subject"])
+}
+
+func TestUnit_ChainVars_ReservedTaskID(t *testing.T) {
+	env := setupTestEnv(&taskengine.MockTaskExecutor{MockOutput: "x"})
+	chain := &taskengine.TaskChainDefinition{
+		Tasks: []taskengine.TaskDefinition{{ID: "vars", Handler: taskengine.HandleNoop}},
+	}
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "x", taskengine.DataTypeString)
+	require.Error(t, err)
+}
+
+func TestUnit_ChainVars_ToolsArgTemplates(t *testing.T) {
+	next := func(id string) taskengine.TaskTransition {
+		return taskengine.TaskTransition{
+			Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}},
+		}
+	}
+	chain := func(args map[string]string) *taskengine.TaskChainDefinition {
+		return &taskengine.TaskChainDefinition{
+			ID: "args",
+			Tasks: []taskengine.TaskDefinition{
+				{ID: "classify", Handler: taskengine.HandlePromptToStructured, StoreAs: "ticket", Transition: next("notify")},
+				{
+					ID:         "notify",
+					Handler:    taskengine.HandleTools,
+					Tools:      &taskengine.ToolsCall{Name: "webhook", Args: args},
+					Transition: next(taskengine.TermEnd),
+				},
+			},
+		}
+	}
+	ticket := map[string]any{"team": "billing", "tags": []any{"refund", "urgent"}}
+
+	exec := &taskengine.MockTaskExecutor{
+		MockOutputSequence:          []any{ticket, "sent"},
+		MockTransitionValueSequence: []string{"ok", "ok"},
+	}
+	_, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), chain(map[string]string{
+		"team":     "{{vars.ticket.team}}",
+		"tags":     "{{vars.ticket.tags}}",
+		"ticket":   "{{.classify}}",
+		"priority": `{{if has "vars.ticket.priority"}}{{vars.ticket.priority}}{{else}}normal{{end}}`,
+		"quoted":   "{{json vars.ticket.team}}",
+	}), "refund please", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"team":     "billing",
+		"tags":     `["refund","urgent"]`,
+		"ticket":   `{"tags":["refund","urgent"],"team":"billing"}`,
+		"priority": "normal",
+		"quoted":   `"billing"`,
+	}, exec.CalledWithTask.Tools.Args)
+
+	exec = &taskengine.MockTaskExecutor{
+		MockOutputSequence:          []any{ticket, "sent"},
+		MockTransitionValueSequence: []string{"ok", "ok"},
+	}
+	_, _, _, err = setupTestEnv(exec).ExecEnv(context.Background(), chain(map[string]string{
+		"owner": "{{vars.ticket.owner}}",
+	}), "refund please", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `tools arg "owner"`)
+	assert.Contains(t, err.Error(), `"owner"`)
+}
@@ -0,0 +1,186 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrCheckpointNotFound is returned by a CheckpointStore when no checkpoint
+// exists for a run.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// Checkpoint is the state of a chain run after its last completed step.
+// It holds everything ExecEnv needs to continue the run at NextTaskID.
+type Checkpoint struct {
+	RunID string `json:"runId"`
+	// Chain is the definition the run was started with, so a run can be
+	// resumed without the original chain file.
+	Chain      *TaskChainDefinition `json:"chain"`
+	NextTaskID string               `json:"nextTaskId"`
+	// Output is the output of the last completed step, i.e. the input of NextTaskID.
+	Output     any                 `json:"output"`
+	OutputType DataType            `json:"outputType"`
+	Vars       map[string]any      `json:"vars"`
+	VarTypes   map[string]DataType `json:"varTypes"`
+	// StoreTypes records the types of the values written via TaskDefinition.StoreAs.
+	StoreTypes     map[string]DataType `json:"storeTypes,omitempty"`
+	CompletedSteps int                 `json:"completedSteps"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+	// PendingApproval is set while the run waits at an await_approval task.
+	PendingApproval *PendingApproval `json:"pendingApproval,omitempty"`
+	// Approval is the decision recorded by Approve or Reject; it is consumed
+	// by the await_approval task when the run is resumed.
+	Approval *ApprovalDecision `json:"approval,omitempty"`
+	// PendingClarification is set while the run waits at a clarify task.
+	PendingClarification *PendingClarification `json:"pendingClarification,omitempty"`
+	// Clarification is the answer recorded by Answer; it is consumed by the
+	// clarify task when the run is resumed.
+	Clarification *ClarificationAnswer `json:"clarification,omitempty"`
+	// WrapUpDeadline is when a chain with a max_duration is routed to its
+	// wrap-up task; a resumed run keeps it instead of starting a new budget.
+	WrapUpDeadline *time.Time `json:"wrapUpDeadline,omitempty"`
+	// WrappingUp is set once the run was routed to its wrap-up task.
+	WrappingUp bool `json:"wrappingUp,omitempty"`
+}
+
+// CheckpointStore persists checkpoints between runs.
+type CheckpointStore interface {
+	SaveCheckpoint(ctx context.Context, cp *Checkpoint) error
+	// LoadCheckpoint returns ErrCheckpointNotFound when runID has no checkpoint.
+	LoadCheckpoint(ctx context.Context, runID string) (*Checkpoint, error)
+	DeleteCheckpoint(ctx context.Context, runID string) error
+}
+
+type checkpointing struct {
+	store CheckpointStore
+	runID string
+}
+
+type checkpointKey struct{}
+
+// WithCheckpoints attaches store to ctx so ExecEnv saves a checkpoint for runID
+// after every completed step.
+//
+// If store already holds a checkpoint for runID, ExecEnv resumes from it:
+// execution continues at the checkpointed task with the saved variables, and
+// the input passed to ExecEnv is ignored. The checkpoint is deleted when the
+// chain completes successfully and kept when it fails, so the run can be
+// resumed again.
+func WithCheckpoints(ctx context.Context, store CheckpointStore, runID string) context.Context {
+	if store == nil || runID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, checkpointKey{}, &checkpointing{store: store, runID: runID})
+}
+
+// withoutCheckpoints detaches checkpointing, so nested chain executions do not
+// overwrite the checkpoint of the run they belong to.
+func withoutCheckpoints(ctx context.Context) context.Context {
+	if checkpointingFromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, checkpointKey{}, (*checkpointing)(nil))
+}
+
+func checkpointingFromContext(ctx context.Context) *checkpointing {
+	cp, _ := ctx.Value(checkpointKey{}).(*checkpointing)
+	return cp
+}
+
+// load returns the checkpoint to resume from, or nil to start afresh.
+func (c *checkpointing) load(ctx context.Context, chain *TaskChainDefinition) (*Checkpoint, error) {
+	if c == nil {
+		return nil, nil
+	}
+	cp, err := c.store.LoadCheckpoint(ctx, c.runID)
+	if errors.Is(err, ErrCheckpointNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint %s: %w", c.runID, err)
+	}
+	if cp.Chain != nil && cp.Chain.ID != chain.ID {
+		return nil, fmt.Errorf("checkpoint %s belongs to chain %q, not %q", c.runID, cp.Chain.ID, chain.ID)
+	}
+	if err := cp.restoreTypes(); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", c.runID, err)
+	}
+	return cp, nil
+}
+
+// save persists the state after a completed step. Failures are logged and do
+// not abort the run.
+func (c *checkpointing) save(ctx context.Context, cp *Checkpoint) {
+	if c == nil {
+		return
+	}
+	if err := c.persist(ctx, cp); err != nil {
+		log.Printf("checkpoint save failed for run %s: %v", c.runID, err)
+	}
+}
+
+// persist stores cp as the checkpoint of the run and returns the error of the
+// store, for callers that cannot continue without the checkpoint.
+func (c *checkpointing) persist(ctx context.Context, cp *Checkpoint) error {
+	cp.RunID = c.runID
+	cp.UpdatedAt = time.Now().UTC()
+	if store, ok := cp.Vars[chainVarsKey].(map[string]any); ok && len(store) > 0 {
+		cp.StoreTypes = make(map[string]DataType, len(store))
+		for name, v := range store {
+			cp.StoreTypes[name] = InferDataType(v)
+		}
+	}
+	return c.store.SaveCheckpoint(ctx, cp)
+}
+
+// clear removes the checkpoint of a successfully completed run.
+func (c *checkpointing) clear(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	if err := c.store.DeleteCheckpoint(ctx, c.runID); err != nil && !errors.Is(err, ErrCheckpointNotFound) {
+		log.Printf("checkpoint delete failed for run %s: %v", c.runID, err)
+	}
+}
+
+// restoreTypes converts values decoded from JSON back to their DataTypes
+// (e.g. a ChatHistory decoded as map[string]any).
+func (cp *Checkpoint) restoreTypes() error {
+	var err error
+	if cp.Output, err = ConvertToType(cp.Output, cp.OutputType); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if cp.Vars == nil {
+		cp.Vars = map[string]any{}
+	}
+	if cp.VarTypes == nil {
+		cp.VarTypes = map[string]DataType{}
+	}
+	for name, dt := range cp.VarTypes {
+		v, ok := cp.Vars[name]
+		if !ok {
+			continue
+		}
+		if cp.Vars[name], err = ConvertToType(v, dt); err != nil {
+			return fmt.Errorf("variable %q: %w", name, err)
+		}
+	}
+	store, _ := cp.Vars[chainVarsKey].(map[string]any)
+	if store == nil {
+		store = map[string]any{}
+	}
+	for name, dt := range cp.StoreTypes {
+		v, ok := store[name]
+		if !ok {
+			continue
+		}
+		if store[name], err = ConvertToType(v, dt); err != nil {
+			return fmt.Errorf("stored variable %q: %w", name, err)
+		}
+	}
+	cp.Vars[chainVarsKey] = store
+	return nil
+}
@@ -0,0 +1,174 @@
+package taskengine_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memCheckpointStore keeps checkpoints as JSON to exercise the same
+// round-trip a persistent store goes through.
+type memCheckpointStore struct {
+	mu    sync.Mutex
+	saved map[string][]byte
+	saves int
+}
+
+func (m *memCheckpointStore) SaveCheckpoint(_ context.Context, cp *taskengine.Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saved == nil {
+		m.saved = map[string][]byte{}
+	}
+	m.saved[cp.RunID] = data
+	m.saves++
+	return nil
+}
+
+func (m *memCheckpointStore) LoadCheckpoint(_ context.Context, runID string) (*taskengine.Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.saved[runID]
+	if !ok {
+		return nil, taskengine.ErrCheckpointNotFound
+	}
+	var cp taskengine.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (m *memCheckpointStore) DeleteCheckpoint(_ context.Context, runID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.saved[runID]; !ok {
+		return taskengine.ErrCheckpointNotFound
+	}
+	delete(m.saved, runID)
+	return nil
+}
+
+// flakyExecutor records the tasks it runs and fails the ones listed in failOn.
+type flakyExecutor struct {
+	failOn map[string]bool
+	ran    []string
+	inputs map[string]any
+}
+
+func (f *flakyExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, _ taskengine.DataType) (any, taskengine.DataType, string, error) {
+	f.ran = append(f.ran, task.ID)
+	if f.inputs == nil {
+		f.inputs = map[string]any{}
+	}
+	f.inputs[task.ID] = input
+	if f.failOn[task.ID] {
+		return nil, taskengine.DataTypeAny, "", errors.New("interrupted")
+	}
+	if task.ID == "history" {
+		return taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "hi"}}}, taskengine.DataTypeChatHistory, "ok", nil
+	}
+	return task.ID + "-done", taskengine.DataTypeString, "ok", nil
+}
+
+func checkpointChain() *taskengine.TaskChainDefinition {
+	next := func(id string) taskengine.TaskTransition {
+		return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}}}
+	}
+	return &taskengine.TaskChainDefinition{
+		ID: "resumable",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "history", Handler: taskengine.HandleNoop, StoreAs: "conversation", Transition: next("summarize")},
+			{ID: "summarize", Handler: taskengine.HandleNoop, Transition: next("report")},
+			{ID: "report", Handler: taskengine.HandleNoop, PromptTemplate: "{{.summarize}} after {{.input}}", Transition: next(taskengine.TermEnd)},
+		},
+	}
+}
+
+func TestCheckpoint_ResumeAfterFailure(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-1")
+
+	first := &flakyExecutor{failOn: map[string]bool{"report": true}}
+	_, _, _, err := setupTestEnv(first).ExecEnv(ctx, checkpointChain(), "start", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Equal(t, []string{"history", "summarize", "report"}, first.ran)
+
+	cp, err := store.LoadCheckpoint(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "report", cp.NextTaskID)
+	assert.Equal(t, 2, cp.CompletedSteps)
+	require.NotNil(t, cp.Chain)
+	assert.Equal(t, "resumable", cp.Chain.ID)
+
+	second := &flakyExecutor{}
+	out, _, _, err := setupTestEnv(second).ExecEnv(ctx, cp.Chain, "ignored on resume", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "report-done", out)
+	assert.Equal(t, []string{"report"}, second.ran, "completed steps must not run again")
+	assert.Equal(t, "summarize-done after start", second.inputs["report"])
+
+	_, err = store.LoadCheckpoint(ctx, "run-1")
+	assert.ErrorIs(t, err, taskengine.ErrCheckpointNotFound, "checkpoint is removed after success")
+}
+
+func TestCheckpoint_RestoresTypedVariables(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-typed")
+
+	_, _, _, err := setupTestEnv(&flakyExecutor{failOn: map[string]bool{"summarize": true}}).
+		ExecEnv(ctx, checkpointChain(), "start", taskengine.DataTypeString)
+	require.Error(t, err)
+
+	cp, err := store.LoadCheckpoint(ctx, "run-typed")
+	require.NoError(t, err)
+
+	second := &flakyExecutor{}
+	_, _, _, err = setupTestEnv(second).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+	require.NoError(t, err)
+	hist, ok := second.inputs["summarize"].(taskengine.ChatHistory)
+	require.True(t, ok, "chat history must be restored as ChatHistory, got %T", second.inputs["summarize"])
+	require.Len(t, hist.Messages, 1)
+	assert.Equal(t, "hi", hist.Messages[0].Content)
+}
+
+func TestCheckpoint_ChainMismatch(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-x")
+	require.NoError(t, store.SaveCheckpoint(ctx, &taskengine.Checkpoint{
+		RunID:      "run-x",
+		Chain:      &taskengine.TaskChainDefinition{ID: "other"},
+		NextTaskID: "report",
+	}))
+
+	_, _, _, err := setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, checkpointChain(), "start", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "belongs to chain")
+}
+
+func TestCheckpoint_RoundTripsNilType(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := context.Background()
+	require.NoError(t, store.SaveCheckpoint(ctx, &taskengine.Checkpoint{
+		RunID:      "run-nil",
+		NextTaskID: "next",
+		OutputType: taskengine.DataTypeNil,
+		VarTypes:   map[string]taskengine.DataType{"previous_output": taskengine.DataTypeNil},
+	}))
+
+	cp, err := store.LoadCheckpoint(ctx, "run-nil")
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeNil, cp.OutputType)
+	assert.Equal(t, taskengine.DataTypeNil, cp.VarTypes["previous_output"])
+}
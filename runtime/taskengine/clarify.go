@@ -0,0 +1,116 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TransitionAnswered is the transition value of a clarify task once its
+// question was answered.
+const TransitionAnswered = "answered"
+
+// ErrClarificationRequired is returned by ExecEnv when a run paused at a
+// clarify task. The returned error is a *ClarificationRequiredError.
+var ErrClarificationRequired = errors.New("clarification required")
+
+// ErrNoPendingClarification is returned by Answer when the run is not
+// waiting for an answer.
+var ErrNoPendingClarification = errors.New("run is not waiting for clarification")
+
+// PendingClarification describes the question a paused run is waiting on.
+type PendingClarification struct {
+	TaskID string `json:"taskId"`
+	// Question is the rendered prompt_template of the task, or its input.
+	Question    string    `json:"question"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// ClarificationAnswer is the answer to a PendingClarification.
+type ClarificationAnswer struct {
+	TaskID     string    `json:"taskId"`
+	Question   string    `json:"question"`
+	Answer     string    `json:"answer"`
+	AnsweredAt time.Time `json:"answeredAt"`
+}
+
+// ClarificationRequiredError reports a run paused at a clarify task.
+type ClarificationRequiredError struct {
+	RunID    string
+	TaskID   string
+	Question string
+}
+
+func (e *ClarificationRequiredError) Error() string {
+	return fmt.Sprintf("run %s: task %s: %s: %s", e.RunID, e.TaskID, ErrClarificationRequired, e.Question)
+}
+
+func (e *ClarificationRequiredError) Unwrap() error { return ErrClarificationRequired }
+
+// Answer records the answer to the pending clarify task of runID. Resuming
+// the run (see WithCheckpoints) then continues with the answer as the output
+// of the task and the "answered" transition.
+func Answer(ctx context.Context, store CheckpointStore, runID, answer string) (*Checkpoint, error) {
+	cp, err := store.LoadCheckpoint(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if cp.PendingClarification == nil {
+		return nil, fmt.Errorf("run %s: %w", runID, ErrNoPendingClarification)
+	}
+	cp.Clarification = &ClarificationAnswer{
+		TaskID:     cp.PendingClarification.TaskID,
+		Question:   cp.PendingClarification.Question,
+		Answer:     answer,
+		AnsweredAt: time.Now().UTC(),
+	}
+	if err := store.SaveCheckpoint(ctx, cp); err != nil {
+		return nil, fmt.Errorf("save answer for run %s: %w", runID, err)
+	}
+	return cp, nil
+}
+
+// requestClarification pauses the run at task: it persists cp with the
+// pending question and returns the *ClarificationRequiredError ExecEnv reports.
+func (c *checkpointing) requestClarification(ctx context.Context, cp *Checkpoint, task *TaskDefinition, question string) error {
+	cp.PendingClarification = &PendingClarification{
+		TaskID:      task.ID,
+		Question:    question,
+		RequestedAt: time.Now().UTC(),
+	}
+	cp.RunID = c.runID
+	cp.UpdatedAt = time.Now().UTC()
+	if err := c.store.SaveCheckpoint(ctx, cp); err != nil {
+		return fmt.Errorf("task %s: persist clarification request: %w", task.ID, err)
+	}
+	return &ClarificationRequiredError{RunID: c.runID, TaskID: task.ID, Question: question}
+}
+
+// clarifyOutput returns the output of a clarify task. With an answer it is
+// the answer, or conversation extended by the question and the answer when
+// conversation is a chat history. Without one (a run that cannot pause) it is
+// the question, appended to conversation when that is a chat history.
+func clarifyOutput(conversation any, question string, answer *ClarificationAnswer) (any, DataType, string) {
+	hist, isChat := conversation.(ChatHistory)
+	if isChat {
+		hist.Messages = slices.Clip(hist.Messages)
+		if n := len(hist.Messages); n == 0 || hist.Messages[n-1].Role != "assistant" ||
+			strings.TrimSpace(hist.Messages[n-1].Content) != strings.TrimSpace(question) {
+			hist.Messages = append(hist.Messages, Message{Role: "assistant", Content: question, Timestamp: time.Now().UTC()})
+		}
+	}
+	if answer == nil {
+		if isChat {
+			return hist, DataTypeChatHistory, ""
+		}
+		return question, DataTypeString, ""
+	}
+	if isChat {
+		hist.Messages = append(hist.Messages, Message{Role: "user", Content: answer.Answer, Timestamp: answer.AnsweredAt})
+		return hist, DataTypeChatHistory, TransitionAnswered
+	}
+	return answer.Answer, DataTypeString, TransitionAnswered
+}
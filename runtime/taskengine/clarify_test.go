@@ -0,0 +1,108 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clarifyChain(first string) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "deploy",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: first, Handler: taskengine.HandleNoop, Transition: goTo("ask")},
+			{ID: "ask", Handler: taskengine.HandleClarify, PromptTemplate: "Deploy {{.previous_output}} to staging or prod?", Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+				{Operator: taskengine.OpEquals, When: taskengine.TransitionAnswered, Goto: "deploy"},
+			}}},
+			{ID: "deploy", Handler: taskengine.HandleNoop, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+}
+
+func TestClarify_PauseAndAnswer(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-1")
+
+	_, _, _, err := setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, clarifyChain("plan"), "start", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrClarificationRequired)
+	var pause *taskengine.ClarificationRequiredError
+	require.True(t, errors.As(err, &pause))
+	assert.Equal(t, "ask", pause.TaskID)
+	assert.Equal(t, "Deploy plan-done to staging or prod?", pause.Question)
+
+	// Resuming without an answer asks again.
+	cp, err := store.LoadCheckpoint(ctx, "run-1")
+	require.NoError(t, err)
+	_, _, _, err = setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+	require.ErrorIs(t, err, taskengine.ErrClarificationRequired)
+
+	cp, err = taskengine.Answer(ctx, store, "run-1", "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "Deploy plan-done to staging or prod?", cp.Clarification.Question)
+
+	exec := &flakyExecutor{}
+	out, _, _, err := setupTestEnv(exec).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy-done", out)
+	assert.Equal(t, "staging", exec.inputs["deploy"], "the answer is the input of the next task")
+}
+
+func TestClarify_ChatHistory(t *testing.T) {
+	t.Run("without checkpoints the question ends the run", func(t *testing.T) {
+		exec := &flakyExecutor{}
+		out, outType, _, err := setupTestEnv(exec).ExecEnv(context.Background(), clarifyChain("history"), "start", taskengine.DataTypeString)
+		require.NoError(t, err)
+		assert.Equal(t, taskengine.DataTypeChatHistory, outType)
+		hist := out.(taskengine.ChatHistory)
+		require.Len(t, hist.Messages, 2)
+		assert.Equal(t, "assistant", hist.Messages[1].Role)
+		assert.Contains(t, hist.Messages[1].Content, "staging or prod?")
+		assert.Equal(t, []string{"history"}, exec.ran)
+	})
+
+	t.Run("the answer extends the history", func(t *testing.T) {
+		store := &memCheckpointStore{}
+		ctx := taskengine.WithCheckpoints(context.Background(), store, "run-1")
+		_, _, _, err := setupTestEnv(&flakyExecutor{}).ExecEnv(ctx, clarifyChain("history"), "start", taskengine.DataTypeString)
+		require.ErrorIs(t, err, taskengine.ErrClarificationRequired)
+		cp, err := taskengine.Answer(ctx, store, "run-1", "prod")
+		require.NoError(t, err)
+
+		exec := &flakyExecutor{}
+		_, _, _, err = setupTestEnv(exec).ExecEnv(ctx, cp.Chain, nil, taskengine.DataTypeAny)
+		require.NoError(t, err)
+		hist, ok := exec.inputs["deploy"].(taskengine.ChatHistory)
+		require.True(t, ok)
+		var roles []string
+		for _, m := range hist.Messages {
+			roles = append(roles, m.Role)
+		}
+		assert.Equal(t, []string{"user", "assistant", "user"}, roles)
+		assert.Equal(t, "prod", hist.Messages[2].Content)
+	})
+}
+
+func TestClarify_Errors(t *testing.T) {
+	store := &memCheckpointStore{}
+	ctx := context.Background()
+	require.NoError(t, store.SaveCheckpoint(ctx, &taskengine.Checkpoint{RunID: "running", NextTaskID: "plan"}))
+	_, err := taskengine.Answer(ctx, store, "running", "staging")
+	assert.ErrorIs(t, err, taskengine.ErrNoPendingClarification)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID: "fanout",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "each", Handler: taskengine.HandleForEach, ForEach: &taskengine.ForEachConfig{Task: "ask"}, Transition: goTo(taskengine.TermEnd)},
+			{ID: "ask", Handler: taskengine.HandleClarify, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+	var msgs []string
+	for _, d := range taskengine.ValidateChain(chain) {
+		msgs = append(msgs, d.Message)
+	}
+	assert.Contains(t, msgs, "clarify tasks cannot run per element")
+}
@@ -0,0 +1,286 @@
+package taskengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// Coercion targets accepted by CoerceConfig.To.
+const (
+	CoerceToString = "string"
+	CoerceToInt    = "int"
+	CoerceToFloat  = "float"
+	CoerceToBool   = "bool"
+	CoerceToJSON   = "json"
+)
+
+// Coercion modes accepted by CoerceConfig.Mode.
+const (
+	// CoerceStrict requires the whole (trimmed) input to be a valid value.
+	CoerceStrict = "strict"
+	// CoerceLenient extracts the value from surrounding text, e.g.
+	// "Score: 7/10" → 7, "Yes, it is." → true, or a JSON object inside a
+	// markdown code fence.
+	CoerceLenient = "lenient"
+)
+
+// TransitionCoercionFailed is the transition value reported by a coerce task
+// whose input cannot be converted. Chains branch on it with an explicit
+// branch, e.g. {operator: equals, when: coercion_failed, goto: reprompt}.
+const TransitionCoercionFailed = "coercion_failed"
+
+// ErrCoercionFailed is wrapped into the error of a coerce task whose input
+// cannot be converted to the requested type.
+var ErrCoercionFailed = errors.New("coercion failed")
+
+var (
+	leadingIntPattern   = regexp.MustCompile(`[-+]?\d+`)
+	leadingFloatPattern = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+	leadingWordPattern  = regexp.MustCompile(`[A-Za-z0-9]+`)
+)
+
+// coerce converts input to the type requested by cfg. It returns the
+// converted value, its DataType and the transition value: the value itself
+// for scalars and "ok" for JSON.
+func coerce(cfg *CoerceConfig, input any) (any, DataType, string, error) {
+	target, lenient, err := parseCoerceConfig(cfg)
+	if err != nil {
+		return nil, DataTypeAny, "", err
+	}
+
+	if hist, ok := input.(ChatHistory); ok {
+		msg, err := lastAssistantMessage(hist)
+		if err != nil {
+			return nil, DataTypeAny, TransitionCoercionFailed, fmt.Errorf("%w: %v", ErrCoercionFailed, err)
+		}
+		input = msg
+	}
+
+	var (
+		out any
+		dt  DataType
+	)
+	switch target {
+	case CoerceToString:
+		out, dt, err = coerceString(input)
+	case CoerceToInt:
+		out, dt, err = coerceInt(input, lenient)
+	case CoerceToFloat:
+		out, dt, err = coerceFloat(input, lenient)
+	case CoerceToBool:
+		out, dt, err = coerceBool(input, lenient)
+	case CoerceToJSON:
+		out, dt, err = coerceJSON(input, lenient)
+	}
+	if err != nil {
+		return nil, DataTypeAny, TransitionCoercionFailed, fmt.Errorf("%w: to %s: %v", ErrCoercionFailed, cfg.To, err)
+	}
+
+	switch v := out.(type) {
+	case string:
+		return v, dt, v, nil
+	case int:
+		return v, dt, strconv.Itoa(v), nil
+	case float64:
+		return v, dt, strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return v, dt, strconv.FormatBool(v), nil
+	default:
+		return v, dt, "ok", nil
+	}
+}
+
+// parseCoerceConfig returns the normalized target type and whether the
+// lenient mode is requested.
+func parseCoerceConfig(cfg *CoerceConfig) (string, bool, error) {
+	if cfg == nil || cfg.To == "" {
+		return "", false, fmt.Errorf("coerce task requires coerce.to %w", errdefs.ErrBadRequest)
+	}
+	target := strings.ToLower(cfg.To)
+	switch target {
+	case CoerceToString, CoerceToInt, CoerceToFloat, CoerceToBool, CoerceToJSON:
+	default:
+		return "", false, fmt.Errorf("unknown coerce target %q %w", cfg.To, errdefs.ErrBadRequest)
+	}
+	switch strings.ToLower(cfg.Mode) {
+	case "", CoerceStrict:
+		return target, false, nil
+	case CoerceLenient:
+		return target, true, nil
+	default:
+		return "", false, fmt.Errorf("unknown coerce mode %q %w", cfg.Mode, errdefs.ErrBadRequest)
+	}
+}
+
+// lastAssistantMessage returns the content of the last assistant message.
+func lastAssistantMessage(hist ChatHistory) (string, error) {
+	for i := len(hist.Messages) - 1; i >= 0; i-- {
+		if hist.Messages[i].Role == "assistant" {
+			return hist.Messages[i].Content, nil
+		}
+	}
+	return "", fmt.Errorf("chat history has no assistant message")
+}
+
+func coerceString(input any) (any, DataType, error) {
+	switch v := input.(type) {
+	case nil:
+		return nil, DataTypeAny, fmt.Errorf("input is nil")
+	case string:
+		return v, DataTypeString, nil
+	case map[string]any, []any, []float64, []float32:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, DataTypeAny, err
+		}
+		return string(data), DataTypeString, nil
+	default:
+		s, err := convertToString(v)
+		return s, DataTypeString, err
+	}
+}
+
+func coerceInt(input any, lenient bool) (any, DataType, error) {
+	switch v := input.(type) {
+	case int:
+		return v, DataTypeInt, nil
+	case int64:
+		return int(v), DataTypeInt, nil
+	case int32:
+		return int(v), DataTypeInt, nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), DataTypeInt, nil
+		}
+		if lenient {
+			return int(math.Round(v)), DataTypeInt, nil
+		}
+		return nil, DataTypeAny, fmt.Errorf("%v is not a whole number", v)
+	case string:
+		s := strings.TrimSpace(v)
+		if n, err := strconv.Atoi(s); err == nil {
+			return n, DataTypeInt, nil
+		}
+		if lenient {
+			if m := leadingIntPattern.FindString(s); m != "" {
+				n, err := strconv.Atoi(m)
+				if err == nil {
+					return n, DataTypeInt, nil
+				}
+			}
+		}
+		return nil, DataTypeAny, fmt.Errorf("%q is not an integer", truncateForError(s))
+	default:
+		return nil, DataTypeAny, fmt.Errorf("cannot convert %T to int", input)
+	}
+}
+
+func coerceFloat(input any, lenient bool) (any, DataType, error) {
+	switch v := input.(type) {
+	case float64:
+		return v, DataTypeJSON, nil
+	case float32:
+		return float64(v), DataTypeJSON, nil
+	case int:
+		return float64(v), DataTypeJSON, nil
+	case int64:
+		return float64(v), DataTypeJSON, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, DataTypeJSON, nil
+		}
+		if lenient {
+			if m := leadingFloatPattern.FindString(s); m != "" {
+				f, err := strconv.ParseFloat(m, 64)
+				if err == nil {
+					return f, DataTypeJSON, nil
+				}
+			}
+		}
+		return nil, DataTypeAny, fmt.Errorf("%q is not a number", truncateForError(s))
+	default:
+		return nil, DataTypeAny, fmt.Errorf("cannot convert %T to float", input)
+	}
+}
+
+func coerceBool(input any, lenient bool) (any, DataType, error) {
+	switch v := input.(type) {
+	case bool:
+		return v, DataTypeJSON, nil
+	case int:
+		if lenient {
+			return v != 0, DataTypeJSON, nil
+		}
+	case float64:
+		if lenient {
+			return v != 0, DataTypeJSON, nil
+		}
+	case string:
+		s := strings.TrimSpace(v)
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, DataTypeJSON, nil
+		}
+		if lenient {
+			switch strings.ToLower(leadingWordPattern.FindString(s)) {
+			case "true", "yes", "y", "ok", "on", "1", "correct", "valid":
+				return true, DataTypeJSON, nil
+			case "false", "no", "n", "off", "0", "incorrect", "invalid":
+				return false, DataTypeJSON, nil
+			}
+		}
+		return nil, DataTypeAny, fmt.Errorf("%q is not a boolean", truncateForError(s))
+	}
+	return nil, DataTypeAny, fmt.Errorf("cannot convert %T to bool", input)
+}
+
+func coerceJSON(input any, lenient bool) (any, DataType, error) {
+	s, ok := input.(string)
+	if !ok {
+		out, err := convertToJSON(input)
+		if err != nil {
+			return nil, DataTypeAny, err
+		}
+		return out, DataTypeJSON, nil
+	}
+	candidate := strings.TrimSpace(s)
+	if lenient {
+		candidate = extractJSONValue(candidate)
+	}
+	var out any
+	if err := json.Unmarshal([]byte(candidate), &out); err != nil {
+		return nil, DataTypeAny, fmt.Errorf("not valid JSON: %w", err)
+	}
+	return out, DataTypeJSON, nil
+}
+
+// extractJSONValue strips code fences and surrounding prose from a model
+// response, keeping the object or array that starts first.
+func extractJSONValue(s string) string {
+	s = StripCodeFences(s)
+	obj, arr := strings.Index(s, "{"), strings.Index(s, "[")
+	switch {
+	case obj >= 0 && (arr < 0 || obj < arr):
+		return ExtractJSONObject(s)
+	case arr >= 0:
+		return ExtractJSONArray(s)
+	default:
+		return s
+	}
+}
+
+// truncateForError shortens values quoted in coercion errors.
+func truncateForError(s string) string {
+	const max = 64
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
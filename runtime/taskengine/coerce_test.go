@@ -0,0 +1,134 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func coerceTask(to, mode string) *taskengine.TaskDefinition {
+	return &taskengine.TaskDefinition{
+		ID:      "coerce",
+		Handler: taskengine.HandleCoerce,
+		Coerce:  &taskengine.CoerceConfig{To: to, Mode: mode},
+	}
+}
+
+func TestUnit_Coerce_Conversions(t *testing.T) {
+	tests := []struct {
+		name       string
+		to, mode   string
+		input      any
+		want       any
+		wantType   taskengine.DataType
+		transition string
+	}{
+		{"int strict", "int", "", " 42\n", 42, taskengine.DataTypeInt, "42"},
+		{"int lenient", "int", "lenient", "Score: 7/10", 7, taskengine.DataTypeInt, "7"},
+		{"int from whole float", "int", "", 3.0, 3, taskengine.DataTypeInt, "3"},
+		{"float strict", "float", "strict", "0.25", 0.25, taskengine.DataTypeJSON, "0.25"},
+		{"float lenient", "float", "lenient", "about 1.5 hours", 1.5, taskengine.DataTypeJSON, "1.5"},
+		{"bool strict", "bool", "", "true", true, taskengine.DataTypeJSON, "true"},
+		{"bool lenient", "bool", "lenient", "Yes, the input is valid.", true, taskengine.DataTypeJSON, "true"},
+		{"bool lenient no", "bool", "lenient", "no.", false, taskengine.DataTypeJSON, "false"},
+		{"json strict", "json", "", `{"a":1}`, map[string]any{"a": 1.0}, taskengine.DataTypeJSON, "ok"},
+		{"json lenient", "json", "lenient", "Here you go:\n```json\n[1, 2]\n```", []any{1.0, 2.0}, taskengine.DataTypeJSON, "ok"},
+		{"string from int", "string", "", 5, "5", taskengine.DataTypeString, "5"},
+		{"string from json", "string", "", map[string]any{"k": "v"}, `{"k":"v"}`, taskengine.DataTypeString, `{"k":"v"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, dt, transition, err := vectorExec(t, coerceTask(tt.to, tt.mode), tt.input, taskengine.InferDataType(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+			assert.Equal(t, tt.wantType, dt)
+			assert.Equal(t, tt.transition, transition)
+		})
+	}
+}
+
+func TestUnit_Coerce_ChatHistoryUsesLastAssistantMessage(t *testing.T) {
+	hist := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "rate it"},
+		{Role: "assistant", Content: "8"},
+		{Role: "user", Content: "thanks"},
+	}}
+	out, _, _, err := vectorExec(t, coerceTask("int", ""), hist, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	assert.Equal(t, 8, out)
+
+	out, dt, _, err := vectorExec(t, coerceTask("string", ""), hist, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	assert.Equal(t, "8", out)
+	assert.Equal(t, taskengine.DataTypeString, dt)
+}
+
+func TestUnit_Coerce_Failures(t *testing.T) {
+	for _, tc := range []struct {
+		to, mode string
+		input    any
+	}{
+		{"int", "strict", "Score: 7/10"},
+		{"int", "", 2.5},
+		{"bool", "strict", "Yes."},
+		{"json", "", "```json\n{}\n```"},
+		{"float", "lenient", "none"},
+	} {
+		_, _, transition, err := vectorExec(t, coerceTask(tc.to, tc.mode), tc.input, taskengine.InferDataType(tc.input))
+		require.Error(t, err, "%s %v", tc.to, tc.input)
+		assert.ErrorIs(t, err, taskengine.ErrCoercionFailed)
+		assert.Equal(t, taskengine.TransitionCoercionFailed, transition)
+	}
+
+	_, _, _, err := vectorExec(t, coerceTask("date", ""), "x", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, taskengine.ErrCoercionFailed, "an unknown target is a configuration error")
+}
+
+func TestCoerce_FailureTransition(t *testing.T) {
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env := setupTestEnv(exec)
+	chain := &taskengine.TaskChainDefinition{
+		ID: "coerce-branch",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "score",
+				Handler: taskengine.HandleCoerce,
+				Coerce:  &taskengine.CoerceConfig{To: "int"},
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpEquals, When: taskengine.TransitionCoercionFailed, Goto: "fallback"},
+					endBranch(),
+				}},
+			},
+			{
+				ID:         "fallback",
+				Handler:    taskengine.HandleNoop,
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+			},
+		},
+	}
+
+	out, dt, history, err := env.ExecEnv(context.Background(), chain, "12", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, 12, out)
+	assert.Equal(t, taskengine.DataTypeInt, dt)
+	require.Len(t, history, 1)
+
+	out, _, history, err = env.ExecEnv(context.Background(), chain, "twelve", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "twelve", out, "the fallback receives the original input")
+	require.NotEmpty(t, history)
+	assert.Equal(t, taskengine.TransitionCoercionFailed, history[0].Transition)
+
+	// Without an explicit branch the failure fails the chain.
+	chain.Tasks[0].Transition.Branches = []taskengine.TransitionBranch{endBranch()}
+	_, _, _, err = env.ExecEnv(context.Background(), chain, "twelve", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, taskengine.ErrCoercionFailed)
+}
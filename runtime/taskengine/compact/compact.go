@@ -25,6 +25,20 @@ import (
 	"time"
 )
 
+// DefaultTriggerFraction is the TriggerFraction used when a Policy sets none.
+const DefaultTriggerFraction = 0.85
+
+const (
+	summaryOpen  = "<compact-summary>"
+	summaryClose = "</compact-summary>"
+)
+
+// IsSummary reports whether content is a synthetic message produced by a
+// previous compaction.
+func IsSummary(content string) bool {
+	return strings.HasPrefix(strings.TrimSpace(content), summaryOpen)
+}
+
 // Policy controls when and how Maybe summarizes history.
 type Policy struct {
 	// TriggerFraction is the fraction of token_limit that triggers compaction.
@@ -47,7 +61,7 @@ type Policy struct {
 
 func (p Policy) triggerFraction() float64 {
 	if p.TriggerFraction <= 0 {
-		return 0.85
+		return DefaultTriggerFraction
 	}
 	return p.TriggerFraction
 }
@@ -230,7 +244,7 @@ func Maybe(
 	st.LastCompactedAt = time.Now()
 	st.mu.Unlock()
 
-	synthetic := "<compact-summary>\n" + summary + "\n</compact-summary>"
+	synthetic := summaryOpen + "\n" + summary + "\n" + summaryClose
 
 	return Result{
 		Compacted:        true,
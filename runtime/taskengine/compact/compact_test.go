@@ -80,6 +80,9 @@ func TestMaybe_CompactsAndReturnsSplice(t *testing.T) {
 	if !strings.Contains(res.SyntheticContent, "<compact-summary>") {
 		t.Fatalf("synthetic content not wrapped: %q", res.SyntheticContent)
 	}
+	if !IsSummary(res.SyntheticContent) || IsSummary(msgs[0].Content) {
+		t.Fatalf("IsSummary misclassified %q", res.SyntheticContent)
+	}
 	out := applySplice(msgs, res)
 	if len(out) != 5 {
 		t.Fatalf("expected 1 synthetic + 4 kept = 5, got %d", len(out))
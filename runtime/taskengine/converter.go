@@ -20,6 +20,10 @@ func ConvertToType(value interface{}, dataType DataType) (interface{}, error) {
 		return convertToInt(value)
 	case DataTypeJSON:
 		return convertToJSON(value)
+	case DataTypeVector:
+		return convertToVector(value)
+	case DataTypeFile:
+		return convertToFile(value)
 	case DataTypeNil:
 		return nil, nil
 	case DataTypeAny:
@@ -44,12 +48,16 @@ func InferDataType(v any) DataType {
 	switch v.(type) {
 	case ChatHistory:
 		return DataTypeChatHistory
+	case File, *File:
+		return DataTypeFile
 	case string, []byte, json.RawMessage:
 		return DataTypeString
 	case int, int8, int16, int32, int64:
 		return DataTypeInt
 	case uint, uint8, uint16, uint32, uint64, uintptr:
 		return DataTypeInt
+	case []float64, []float32:
+		return DataTypeVector
 	case map[string]any:
 		return DataTypeJSON
 	case []any:
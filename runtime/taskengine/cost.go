@@ -0,0 +1,154 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+)
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	Input  float64 `yaml:"input" json:"input" example:"2.5"`
+	Output float64 `yaml:"output" json:"output" example:"10"`
+}
+
+// Pricing maps model names to prices. A key may be qualified with the
+// provider type, e.g. "openai/gpt-4o"; the qualified key takes precedence
+// over the bare model name. Models missing from the table are not priced.
+type Pricing map[string]ModelPrice
+
+// Validate reports negative prices.
+func (p Pricing) Validate() error {
+	for model, price := range p {
+		if price.Input < 0 || price.Output < 0 {
+			return fmt.Errorf("price of %q must not be negative %w", model, errdefs.ErrBadRequest)
+		}
+	}
+	return nil
+}
+
+// Cost returns the estimated cost of a call to model of provider with the
+// given token counts, and whether the model is priced.
+func (p Pricing) Cost(provider, model string, inputTokens, outputTokens int) (float64, bool) {
+	price, ok := p[provider+"/"+model]
+	if !ok {
+		price, ok = p[model]
+	}
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1e6, true
+}
+
+// CostRecorder persists the token usage and estimated cost of model calls.
+// runtimetypes.Store satisfies it.
+type CostRecorder interface {
+	RecordModelCost(ctx context.Context, backendID, modelName string, inputTokens, outputTokens int64, cost float64, at time.Time) error
+}
+
+type pricingKey struct{}
+
+// WithPricing makes executors created by NewExec with the returned context
+// estimate the cost of every model call from pricing. The cost is reported
+// per step in CapturedStateUnit.Cost; see ChainCost for the total.
+func WithPricing(ctx context.Context, pricing Pricing) context.Context {
+	if len(pricing) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, pricingKey{}, pricing)
+}
+
+func pricingFromContext(ctx context.Context) Pricing {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(pricingKey{}).(Pricing)
+	return p
+}
+
+type costRecorderKey struct{}
+
+// WithCostRecorder makes executors created by NewExec with the returned
+// context record the token usage and cost of every model call with rec.
+func WithCostRecorder(ctx context.Context, rec CostRecorder) context.Context {
+	if rec == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, costRecorderKey{}, rec)
+}
+
+func costRecorderFromContext(ctx context.Context) CostRecorder {
+	if ctx == nil {
+		return nil
+	}
+	rec, _ := ctx.Value(costRecorderKey{}).(CostRecorder)
+	return rec
+}
+
+// costRecordTimeout bounds the cost write so a slow store cannot stall a task.
+const costRecordTimeout = 2 * time.Second
+
+// tracksUsage reports whether the executor needs the token counts of calls
+// whose tokens it does not count anyway.
+func (exe *SimpleExec) tracksUsage() bool {
+	return exe.pricing != nil || exe.costRecorder != nil
+}
+
+// recordUsage notes the tokens of a model call on the step and prices them.
+// Recording is best-effort and never fails the call.
+func (exe *SimpleExec) recordUsage(ctx context.Context, meta llmrepo.Meta, inputTokens, outputTokens int) {
+	cost, priced := exe.pricing.Cost(meta.ProviderType, meta.ModelName, inputTokens, outputTokens)
+	recordServedUsage(ctx, inputTokens, outputTokens, cost, priced)
+	if exe.costRecorder == nil || meta.BackendID == "" || meta.ModelName == "" {
+		return
+	}
+	recCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), costRecordTimeout)
+	defer cancel()
+	_ = exe.costRecorder.RecordModelCost(recCtx, meta.BackendID, meta.ModelName, int64(inputTokens), int64(outputTokens), cost, time.Now())
+}
+
+// recordPromptUsage counts the tokens of a prompt call and records them. Prompt
+// calls are only counted when usage is tracked; token counting errors drop
+// the record.
+func (exe *SimpleExec) recordPromptUsage(ctx context.Context, meta llmrepo.Meta, input, response string) {
+	if !exe.tracksUsage() {
+		return
+	}
+	inputTokens, err := exe.repo.CountTokens(ctx, meta.ModelName, input)
+	if err != nil {
+		return
+	}
+	outputTokens, err := exe.repo.CountTokens(ctx, meta.ModelName, response)
+	if err != nil {
+		return
+	}
+	exe.recordUsage(ctx, meta, inputTokens, outputTokens)
+}
+
+// ChainUsage is the token usage and estimated cost of a chain execution.
+type ChainUsage struct {
+	InputTokens  int     `json:"inputTokens" example:"1200"`
+	OutputTokens int     `json:"outputTokens" example:"350"`
+	Cost         float64 `json:"cost" example:"0.0065"`
+	// Unpriced lists the steps that used tokens of a model without a price,
+	// whose cost is missing from Cost.
+	Unpriced []string `json:"unpriced,omitempty"`
+}
+
+// ChainCost sums the token usage and cost of the steps of an execution
+// history.
+func ChainCost(history []CapturedStateUnit) ChainUsage {
+	var u ChainUsage
+	for _, step := range history {
+		u.InputTokens += step.InputTokens
+		u.OutputTokens += step.OutputTokens
+		u.Cost += step.Cost
+		if !step.Priced && step.InputTokens+step.OutputTokens > 0 {
+			u.Unpriced = append(u.Unpriced, step.TaskID)
+		}
+	}
+	return u
+}
@@ -0,0 +1,102 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedCost struct {
+	backendID, model string
+	input, output    int64
+	cost             float64
+}
+
+type captureCostRecorder struct {
+	calls []capturedCost
+}
+
+func (r *captureCostRecorder) RecordModelCost(_ context.Context, backendID, modelName string, inputTokens, outputTokens int64, cost float64, _ time.Time) error {
+	r.calls = append(r.calls, capturedCost{backendID, modelName, inputTokens, outputTokens, cost})
+	return nil
+}
+
+func TestUnit_Pricing_Cost(t *testing.T) {
+	pricing := taskengine.Pricing{
+		"gpt-4o":        {Input: 5, Output: 15},
+		"openai/gpt-4o": {Input: 2.5, Output: 10},
+	}
+	cost, ok := pricing.Cost("openai", "gpt-4o", 1_000_000, 100_000)
+	require.True(t, ok)
+	require.InDelta(t, 3.5, cost, 1e-9)
+
+	cost, ok = pricing.Cost("vllm", "gpt-4o", 1_000_000, 0)
+	require.True(t, ok, "bare model name applies to any provider")
+	require.InDelta(t, 5, cost, 1e-9)
+
+	_, ok = pricing.Cost("ollama", "llama3", 10, 10)
+	require.False(t, ok)
+
+	require.Error(t, taskengine.Pricing{"m": {Input: -1}}.Validate())
+}
+
+func TestUnit_ChainCost_AggregatesSteps(t *testing.T) {
+	repo := &mockModelRepo{
+		promptFunc: func(_ context.Context, req llmrepo.Request, _ string, _ float32, _ string) (string, llmrepo.Meta, error) {
+			return "answer", llmrepo.Meta{ModelName: req.ModelNames[0], ProviderType: "openai", BackendID: "b1"}, nil
+		},
+	}
+	rec := &captureCostRecorder{}
+	ctx := taskengine.WithPricing(context.Background(), taskengine.Pricing{"gpt-4o-mini": {Input: 1_000_000, Output: 2_000_000}})
+	ctx = taskengine.WithCostRecorder(ctx, rec)
+	exec, err := taskengine.NewExec(ctx, repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(ctx, libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	prompt := func(id, model, next string) taskengine.TaskDefinition {
+		return taskengine.TaskDefinition{
+			ID:            id,
+			Handler:       taskengine.HandlePromptToString,
+			ExecuteConfig: &taskengine.LLMExecutionConfig{Model: model},
+			Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: next}},
+			},
+		}
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID: "cost",
+		Tasks: []taskengine.TaskDefinition{
+			prompt("priced", "gpt-4o-mini", "unpriced"),
+			prompt("unpriced", "llama3", taskengine.TermEnd),
+		},
+	}
+	_, _, history, err := env.ExecEnv(context.Background(), chain, "question", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	// mockModelRepo counts every text as one token.
+	require.Equal(t, 1, history[0].InputTokens)
+	require.Equal(t, 1, history[0].OutputTokens)
+	require.True(t, history[0].Priced)
+	require.InDelta(t, 3.0, history[0].Cost, 1e-9)
+	require.False(t, history[1].Priced)
+	require.Zero(t, history[1].Cost)
+
+	usage := taskengine.ChainCost(history)
+	require.Equal(t, 2, usage.InputTokens)
+	require.Equal(t, 2, usage.OutputTokens)
+	require.InDelta(t, 3.0, usage.Cost, 1e-9)
+	require.Equal(t, []string{"unpriced"}, usage.Unpriced)
+
+	require.Equal(t, []capturedCost{
+		{"b1", "gpt-4o-mini", 1, 1, 3},
+		{"b1", "llama3", 1, 1, 0},
+	}, rec.calls)
+}
@@ -0,0 +1,209 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// ErrNoDryRunFixture is returned by model calls of a dry run when the fixtures
+// have no response for the calling task and no default.
+var ErrNoDryRunFixture = errors.New("no dry-run fixture")
+
+// dryRunModel is the model and provider name reported for dry-run responses.
+const dryRunModel = "dry-run"
+
+// DryRunFixtures are the canned model responses of a dry run.
+// example:
+//
+//	tasks:
+//	  classify: ["billing"]
+//	  agent:
+//	    - tool_calls:
+//	        - {name: local_shell, arguments: {command: "ls"}}
+//	    - content: "There are 3 files."
+//	default: "ok"
+type DryRunFixtures struct {
+	// Tasks maps a task ID to the responses of its model calls, used in
+	// order. The last response repeats once the list is exhausted.
+	Tasks map[string][]DryRunResponse `yaml:"tasks,omitempty" json:"tasks,omitempty"`
+	// Default answers model calls of tasks without an entry in Tasks.
+	Default *DryRunResponse `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// DryRunResponse is one canned model response. In fixture files a plain
+// string is shorthand for a response with only Content.
+type DryRunResponse struct {
+	// Content is the text the model answers with.
+	Content string `yaml:"content,omitempty" json:"content,omitempty" example:"billing"`
+	// ToolCalls are returned by chat_completion calls; prompt calls ignore them.
+	ToolCalls []DryRunToolCall `yaml:"tool_calls,omitempty" json:"tool_calls,omitempty"`
+	// Error makes the call fail with this message, e.g. to exercise
+	// on_failure transitions.
+	Error string `yaml:"error,omitempty" json:"error,omitempty" example:"model overloaded"`
+}
+
+// DryRunToolCall is a tool call requested by a canned chat response.
+type DryRunToolCall struct {
+	Name      string         `yaml:"name" json:"name" example:"local_shell"`
+	Arguments map[string]any `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+}
+
+func (r *DryRunResponse) UnmarshalJSON(data []byte) error {
+	var content string
+	if err := json.Unmarshal(data, &content); err == nil {
+		*r = DryRunResponse{Content: content}
+		return nil
+	}
+	type plain DryRunResponse
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// ParseDryRunFixtures decodes a fixtures document, choosing JSON or YAML from
+// the file name like ParseChainDefinition.
+func ParseDryRunFixtures(name string, data []byte) (*DryRunFixtures, error) {
+	if IsYAMLChainPath(name) {
+		converted, err := yamlToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse fixtures yaml: %w", err)
+		}
+		data = converted
+	}
+	var fixtures DryRunFixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, err
+	}
+	return &fixtures, nil
+}
+
+// dryRun is the per-run state of the fixtures: how many responses each task
+// has used.
+type dryRun struct {
+	fixtures *DryRunFixtures
+	mu       sync.Mutex
+	used     map[string]int
+}
+
+// next returns the response for the next model call of taskID.
+func (d *dryRun) next(taskID string) (DryRunResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	responses := d.fixtures.Tasks[taskID]
+	if len(responses) == 0 {
+		if d.fixtures.Default == nil {
+			return DryRunResponse{}, fmt.Errorf("%w for task %q", ErrNoDryRunFixture, taskID)
+		}
+		return *d.fixtures.Default, nil
+	}
+	i := min(d.used[taskID], len(responses)-1)
+	d.used[taskID]++
+	resp := responses[i]
+	if resp.Error != "" {
+		return resp, fmt.Errorf("dry run: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+type dryRunKey struct{}
+
+type dryRunTaskKey struct{}
+
+// WithDryRun attaches fixtures to ctx. A chain executed with the returned
+// context answers every prompt and chat call from the fixtures instead of a
+// backend, keyed by the ID of the calling task. Everything else, including
+// hooks and tool calls requested by canned responses, runs as usual. Each
+// call of WithDryRun starts the fixtures over.
+func WithDryRun(ctx context.Context, fixtures *DryRunFixtures) context.Context {
+	if fixtures == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dryRunKey{}, &dryRun{fixtures: fixtures, used: map[string]int{}})
+}
+
+func dryRunFromContext(ctx context.Context) *dryRun {
+	d, _ := ctx.Value(dryRunKey{}).(*dryRun)
+	return d
+}
+
+// withDryRunTask stamps the executing task's ID into a dry-run ctx so model
+// calls can find their fixtures.
+func withDryRunTask(ctx context.Context, taskID string) context.Context {
+	if dryRunFromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dryRunTaskKey{}, taskID)
+}
+
+// modelRepo returns the repo serving model calls made with ctx: the
+// fixtures of a dry run, or the executor's repo behind its rate limiter.
+func (exe *SimpleExec) modelRepo(ctx context.Context) llmrepo.ModelRepo {
+	if d := dryRunFromContext(ctx); d != nil {
+		return &dryRunRepo{ModelRepo: exe.repo, run: d}
+	}
+	if exe.limiter != nil {
+		return &rateLimitedRepo{ModelRepo: exe.repo, limiter: exe.limiter}
+	}
+	return exe.repo
+}
+
+// dryRunRepo answers prompt and chat calls from dry-run fixtures. Tokens are
+// still counted by the wrapped repo.
+type dryRunRepo struct {
+	llmrepo.ModelRepo
+	run *dryRun
+}
+
+var _ llmrepo.ModelRepo = (*dryRunRepo)(nil)
+
+func (m *dryRunRepo) respond(ctx context.Context, models []string) (DryRunResponse, llmrepo.Meta, error) {
+	taskID, _ := ctx.Value(dryRunTaskKey{}).(string)
+	meta := llmrepo.Meta{ModelName: dryRunModel, ProviderType: dryRunModel, BackendID: dryRunModel}
+	if len(models) > 0 {
+		meta.ModelName = models[0]
+	}
+	resp, err := m.run.next(taskID)
+	return resp, meta, err
+}
+
+func (m *dryRunRepo) PromptExecute(ctx context.Context, req llmrepo.Request, _ string, _ float32, _ string, _ ...libmodelprovider.ChatArgument) (string, llmrepo.Meta, error) {
+	resp, meta, err := m.respond(ctx, req.ModelNames)
+	return resp.Content, meta, err
+}
+
+func (m *dryRunRepo) Chat(ctx context.Context, req llmrepo.Request, _ []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	resp, meta, err := m.respond(ctx, req.ModelNames)
+	if err != nil {
+		return libmodelprovider.ChatResult{}, meta, err
+	}
+	result := libmodelprovider.ChatResult{
+		Message: libmodelprovider.Message{Role: "assistant", Content: resp.Content},
+	}
+	for i, tc := range resp.ToolCalls {
+		args, err := json.Marshal(tc.Arguments)
+		if err != nil {
+			return libmodelprovider.ChatResult{}, meta, fmt.Errorf("dry run: tool call %q: %w", tc.Name, err)
+		}
+		call := libmodelprovider.ToolCall{ID: fmt.Sprintf("dry-run-%d", i+1), Type: "function"}
+		call.Function.Name = tc.Name
+		call.Function.Arguments = string(args)
+		result.ToolCalls = append(result.ToolCalls, call)
+	}
+	result.Message.ToolCalls = result.ToolCalls
+	return result, meta, nil
+}
+
+// Stream sends the canned content as a single parcel. A canned error is sent
+// as a parcel too: callers fall back to a non-streaming call when Stream
+// itself fails, which would use up the next response.
+func (m *dryRunRepo) Stream(ctx context.Context, req llmrepo.Request, _ []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
+	resp, meta, err := m.respond(ctx, req.ModelNames)
+	out := make(chan *libmodelprovider.StreamParcel, 1)
+	out <- &libmodelprovider.StreamParcel{Data: resp.Content, Error: err}
+	close(out)
+	return out, meta, nil
+}
@@ -0,0 +1,150 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dryRunEnv(t *testing.T) taskengine.EnvExecutor {
+	t.Helper()
+	repo := &mockModelRepo{
+		promptFunc: func(context.Context, llmrepo.Request, string, float32, string) (string, llmrepo.Meta, error) {
+			t.Error("a dry run must not call the model")
+			return "", llmrepo.Meta{}, errors.New("unexpected prompt")
+		},
+		chatFunc: func(context.Context, llmrepo.Request, []libmodelprovider.Message) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+			t.Error("a dry run must not call the model")
+			return libmodelprovider.ChatResult{}, llmrepo.Meta{}, errors.New("unexpected chat")
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	return setupTestEnv(exec)
+}
+
+func classifyChain() *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "dry-run",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "classify",
+				Handler:        taskengine.HandlePromptToString,
+				PromptTemplate: "Classify: {{.input}}",
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpEquals, When: "billing", Goto: "answer"},
+					endBranch(),
+				}},
+			},
+			{
+				ID:             "answer",
+				Handler:        taskengine.HandlePromptToString,
+				PromptTemplate: "Answer: {{.input}}",
+				Transition:     taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+			},
+		},
+	}
+}
+
+func TestDryRun_AnswersFromFixtures(t *testing.T) {
+	env := dryRunEnv(t)
+	fixtures, err := taskengine.ParseDryRunFixtures("fixtures.yaml", []byte(`
+tasks:
+  classify: ["billing", "other"]
+default: "canned answer"
+`))
+	require.NoError(t, err)
+	ctx := taskengine.WithDryRun(context.Background(), fixtures)
+
+	out, _, history, err := env.ExecEnv(ctx, classifyChain(), "my invoice is wrong", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "canned answer", out)
+	require.Len(t, history, 2)
+	assert.Equal(t, "billing", history[0].Transition)
+	assert.Equal(t, "answer", history[1].TaskID)
+
+	// The second call of classify uses the next response, and the last one
+	// repeats from then on.
+	for range 2 {
+		out, _, history, err = env.ExecEnv(ctx, classifyChain(), "hello", taskengine.DataTypeString)
+		require.NoError(t, err)
+		assert.Equal(t, "other", out)
+		require.Len(t, history, 1)
+	}
+
+	// A new dry run starts over.
+	out, _, _, err = env.ExecEnv(taskengine.WithDryRun(context.Background(), fixtures), classifyChain(), "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "canned answer", out)
+}
+
+func TestDryRun_MissingFixtureFails(t *testing.T) {
+	env := dryRunEnv(t)
+	fixtures := &taskengine.DryRunFixtures{Tasks: map[string][]taskengine.DryRunResponse{
+		"classify": {{Content: "billing"}},
+	}}
+	_, _, _, err := env.ExecEnv(taskengine.WithDryRun(context.Background(), fixtures), classifyChain(), "x", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, taskengine.ErrNoDryRunFixture)
+	assert.Contains(t, err.Error(), `"answer"`)
+}
+
+func TestDryRun_CannedErrorTakesFailureTransition(t *testing.T) {
+	env := dryRunEnv(t)
+	chain := classifyChain()
+	chain.Tasks[0].Transition.OnFailure = "answer"
+	fixtures := &taskengine.DryRunFixtures{Tasks: map[string][]taskengine.DryRunResponse{
+		"classify": {{Error: "model overloaded"}},
+		"answer":   {{Content: "fallback answer"}},
+	}}
+	out, _, _, err := env.ExecEnv(taskengine.WithDryRun(context.Background(), fixtures), chain, "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "fallback answer", out)
+}
+
+func TestDryRun_ChatToolCalls(t *testing.T) {
+	env := dryRunEnv(t)
+	fixtures, err := taskengine.ParseDryRunFixtures("fixtures.json", []byte(`{
+		"tasks": {"chat": [
+			{"tool_calls": [{"name": "local_shell", "arguments": {"command": "ls"}}]},
+			"done"
+		]}
+	}`))
+	require.NoError(t, err)
+	chain := &taskengine.TaskChainDefinition{
+		ID: "dry-run-chat",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:      "chat",
+			Handler: taskengine.HandleChatCompletion,
+			Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+				{Operator: taskengine.OpEquals, When: "tool-call", Goto: taskengine.TermEnd},
+				endBranch(),
+			}},
+		}},
+	}
+	input := taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "list files"}}}
+	ctx := taskengine.WithDryRun(context.Background(), fixtures)
+
+	out, _, history, err := env.ExecEnv(ctx, chain, input, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	assert.Equal(t, "tool-call", history[0].Transition)
+	hist := out.(taskengine.ChatHistory)
+	last := hist.Messages[len(hist.Messages)-1]
+	require.Len(t, last.CallTools, 1)
+	assert.Equal(t, "local_shell", last.CallTools[0].Function.Name)
+	assert.JSONEq(t, `{"command":"ls"}`, last.CallTools[0].Function.Arguments)
+
+	out, _, history, err = env.ExecEnv(ctx, chain, input, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	assert.Equal(t, "executed", history[0].Transition)
+	hist = out.(taskengine.ChatHistory)
+	assert.Equal(t, "done", hist.Messages[len(hist.Messages)-1].Content)
+}
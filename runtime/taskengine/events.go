@@ -2,7 +2,6 @@ package taskengine
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -16,6 +15,15 @@ const (
 	TaskEventSubjectAll = "taskengine.events"
 )
 
+// TaskEventSchema versions the TaskEvent wire format published on the bus.
+// Events from runtimes that predate versioning (version 0) are still accepted.
+var TaskEventSchema = libbus.MessageSchema{
+	Name:       "taskengine.task_event",
+	Version:    1,
+	MinVersion: 0,
+	Required:   []string{"kind", "timestamp"},
+}
+
 type TaskEventKind string
 
 const (
@@ -27,6 +35,11 @@ const (
 	TaskEventChainCompleted TaskEventKind = "chain_completed"
 	TaskEventChainFailed    TaskEventKind = "chain_failed"
 
+	// TaskEventToolCalled is emitted after every tool call, whether requested
+	// by the model (execute_tool_calls) or made by a tools task. ToolsName,
+	// ToolName, DurationMS and Error describe the call.
+	TaskEventToolCalled TaskEventKind = "tool_called"
+
 	// Plan run lifecycle (load/compile before chain execution); same bus + SSE as other task events.
 	TaskEventPlanRunStarted  TaskEventKind = "plan_run_started"
 	TaskEventPlanRunCompiled TaskEventKind = "plan_run_compiled"
@@ -54,6 +67,17 @@ type TaskEvent struct {
 	Content      string        `json:"content,omitempty"`
 	Thinking     string        `json:"thinking,omitempty"`
 	Error        string        `json:"error,omitempty"`
+	// Variant is the branch a weighted transition picked, set on
+	// step_completed events; see CapturedStateUnit.Variant.
+	Variant string `json:"variant,omitempty"`
+	// PromptVariant is the prompt variant the step sent, set on
+	// step_completed and step_failed events.
+	PromptVariant string `json:"prompt_variant,omitempty"`
+	// DurationMS is the duration of the call reported by a tool_called event.
+	DurationMS int64 `json:"duration_ms,omitempty"`
+	// Usage is the token usage and estimated cost of the whole chain, set on
+	// chain_completed and chain_failed events when the chain used tokens.
+	Usage *ChainUsage `json:"usage,omitempty"`
 	// Attachments are widget hints produced by tools during the step that just
 	// completed (Phase 5 of the canvas-vision plan). Drained from the
 	// context-bound [WidgetHintSink] at publish time. The Beam UI maps each
@@ -62,7 +86,8 @@ type TaskEvent struct {
 	// tools fired.
 	Attachments []WidgetHint `json:"attachments,omitempty"`
 
-	// Approval fields — populated only for TaskEventApprovalRequested events.
+	// Approval fields — populated only for TaskEventApprovalRequested events,
+	// except ToolsName and ToolName, which tool_called events set as well.
 	// ApprovalID is the unique key used to resume or cancel via POST /api/approvals/{id}.
 	ApprovalID   string         `json:"approval_id,omitempty"`
 	ToolsName     string         `json:"tools_name,omitempty"`
@@ -117,9 +142,9 @@ func (s *BusTaskEventSink) PublishTaskEvent(ctx context.Context, event TaskEvent
 		return nil
 	}
 
-	payload, err := json.Marshal(event)
+	payload, err := TaskEventSchema.Encode(event)
 	if err != nil {
-		return fmt.Errorf("marshal task event: %w", err)
+		return fmt.Errorf("encode task event: %w", err)
 	}
 
 	subjects := []string{TaskEventSubjectAll}
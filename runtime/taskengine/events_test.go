@@ -28,7 +28,12 @@ func (s *captureTaskEventSink) PublishTaskEvent(ctx context.Context, event taske
 func (s *captureTaskEventSink) Enabled() bool { return true }
 
 type mockModelRepo struct {
-	streamFunc func(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error)
+	promptFunc     func(ctx context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string) (string, llmrepo.Meta, error)
+	streamFunc     func(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error)
+	chatFunc       func(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message) (libmodelprovider.ChatResult, llmrepo.Meta, error)
+	embedBatchFunc func(ctx context.Context, embedReq llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error)
+	// chatOpts records the arguments of every Chat call.
+	chatOpts [][]libmodelprovider.ChatArgument
 }
 
 func (m *mockModelRepo) Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error) {
@@ -39,11 +44,18 @@ func (m *mockModelRepo) CountTokens(ctx context.Context, modelName string, promp
 	return 1, nil
 }
 
-func (m *mockModelRepo) PromptExecute(ctx context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string) (string, llmrepo.Meta, error) {
+func (m *mockModelRepo) PromptExecute(ctx context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string, _ ...libmodelprovider.ChatArgument) (string, llmrepo.Meta, error) {
+	if m.promptFunc != nil {
+		return m.promptFunc(ctx, req, systeminstruction, temperature, prompt)
+	}
 	return "", llmrepo.Meta{}, errors.New("PromptExecute should not be called")
 }
 
 func (m *mockModelRepo) Chat(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	m.chatOpts = append(m.chatOpts, opts)
+	if m.chatFunc != nil {
+		return m.chatFunc(ctx, req, messages)
+	}
 	return libmodelprovider.ChatResult{}, llmrepo.Meta{}, errors.New("Chat should not be called")
 }
 
@@ -51,6 +63,13 @@ func (m *mockModelRepo) Embed(ctx context.Context, embedReq llmrepo.EmbedRequest
 	return nil, llmrepo.Meta{}, errors.New("Embed should not be called")
 }
 
+func (m *mockModelRepo) EmbedBatch(ctx context.Context, embedReq llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	if m.embedBatchFunc != nil {
+		return m.embedBatchFunc(ctx, embedReq, prompts)
+	}
+	return nil, llmrepo.Meta{}, errors.New("EmbedBatch should not be called")
+}
+
 func (m *mockModelRepo) Stream(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
 	if m.streamFunc == nil {
 		return nil, llmrepo.Meta{}, errors.New("streamFunc not configured")
@@ -201,6 +220,48 @@ func TestTaskEvents_PromptStreamingPublishesChunks(t *testing.T) {
 	assert.Equal(t, "test-model", chunks[2].ModelName)
 }
 
+func TestTaskEvents_ToolsTaskPublishesToolCalled(t *testing.T) {
+	sink := &captureTaskEventSink{}
+	constructorCtx := taskengine.WithTaskEventSink(context.Background(), sink)
+	toolsRepo := tools.NewMockToolsRegistry()
+	toolsRepo.DefaultResponse.Output = "sent"
+
+	exec, err := taskengine.NewExec(constructorCtx, &mockModelRepo{}, toolsRepo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(constructorCtx, libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), toolsRepo)
+	require.NoError(t, err)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID: "chain.tools",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "notify",
+				Handler: taskengine.HandleTools,
+				Tools:   &taskengine.ToolsCall{Name: "slack", ToolName: "post"},
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+
+	_, _, _, err = env.ExecEnv(context.Background(), chain, "hello", taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	var called []taskengine.TaskEvent
+	for _, event := range sink.events {
+		if event.Kind == taskengine.TaskEventToolCalled {
+			called = append(called, event)
+		}
+	}
+	require.Len(t, called, 1)
+	assert.Equal(t, "chain.tools", called[0].ChainID)
+	assert.Equal(t, "notify", called[0].TaskID)
+	assert.Equal(t, "slack", called[0].ToolsName)
+	assert.Equal(t, "post", called[0].ToolName)
+	assert.Empty(t, called[0].Error)
+}
+
 func TestBusTaskEventSink_PublishesBroadAndRequestSubjects(t *testing.T) {
 	bus := libbus.NewInMem()
 	defer bus.Close()
@@ -0,0 +1,174 @@
+package taskengine
+
+import (
+	"fmt"
+	"maps"
+
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// inheritExecuteConfig returns the execute config a task runs with when its
+// chain declares defaults. Fields set on task win; unset fields are taken from
+// defaults. Model/Models and Provider/Providers are inherited as pairs so a task
+// that picks its own model does not also get the chain's model as a fallback
+// candidate. Neither argument is modified.
+func inheritExecuteConfig(defaults, task *LLMExecutionConfig) *LLMExecutionConfig {
+	if defaults == nil {
+		return task
+	}
+	if task == nil {
+		merged := *defaults
+		return &merged
+	}
+	merged := *task
+	if merged.Model == "" && len(merged.Models) == 0 {
+		merged.Model = defaults.Model
+		merged.Models = defaults.Models
+	}
+	if merged.ModelFallback == nil {
+		merged.ModelFallback = defaults.ModelFallback
+	}
+	if merged.Provider == "" && len(merged.Providers) == 0 {
+		merged.Provider = defaults.Provider
+		merged.Providers = defaults.Providers
+	}
+	if merged.Temperature == 0 {
+		merged.Temperature = defaults.Temperature
+	}
+	if merged.TopP == 0 {
+		merged.TopP = defaults.TopP
+	}
+	if merged.TopK == 0 {
+		merged.TopK = defaults.TopK
+	}
+	if merged.FrequencyPenalty == 0 {
+		merged.FrequencyPenalty = defaults.FrequencyPenalty
+	}
+	if merged.PresencePenalty == 0 {
+		merged.PresencePenalty = defaults.PresencePenalty
+	}
+	if merged.Stop == nil {
+		merged.Stop = defaults.Stop
+	}
+	if merged.MaxTokens == 0 {
+		merged.MaxTokens = defaults.MaxTokens
+	}
+	// A nil allowlist means "not set" here; an explicit [] still disables tools.
+	if merged.Tools == nil {
+		merged.Tools = defaults.Tools
+	}
+	if merged.HideTools == nil {
+		merged.HideTools = defaults.HideTools
+	}
+	if merged.ToolChoice == nil {
+		merged.ToolChoice = defaults.ToolChoice
+	}
+	if len(defaults.ToolsPolicies) > 0 {
+		policies := maps.Clone(defaults.ToolsPolicies)
+		maps.Copy(policies, merged.ToolsPolicies)
+		merged.ToolsPolicies = policies
+	}
+	merged.PassClientsTools = merged.PassClientsTools || defaults.PassClientsTools
+	if merged.Think == "" {
+		merged.Think = defaults.Think
+	}
+	merged.Shift = merged.Shift || defaults.Shift
+	if merged.RetryPolicy == nil {
+		merged.RetryPolicy = defaults.RetryPolicy
+	}
+	if merged.CompactPolicy == nil {
+		merged.CompactPolicy = defaults.CompactPolicy
+	}
+	return &merged
+}
+
+// System instruction modes of TaskDefinition.SystemInstructionMode.
+const (
+	SystemInstructionAppend  = "append"
+	SystemInstructionReplace = "replace"
+)
+
+// ResolveChainDefaults returns chain with DefaultExecuteConfig folded into the
+// execute config of every task and the chain's SystemInstruction layered
+// under the system instruction of every task. The input chain is left
+// untouched; when it declares no defaults it is returned as is.
+func ResolveChainDefaults(chain *TaskChainDefinition) *TaskChainDefinition {
+	if chain == nil || (chain.DefaultExecuteConfig == nil && chain.SystemInstruction == "") {
+		return chain
+	}
+	resolved := *chain
+	resolved.Tasks = make([]TaskDefinition, len(chain.Tasks))
+	for i, task := range chain.Tasks {
+		if chain.DefaultExecuteConfig != nil {
+			task.ExecuteConfig = inheritExecuteConfig(chain.DefaultExecuteConfig, task.ExecuteConfig)
+		}
+		task.SystemInstruction = layerSystemInstruction(chain.SystemInstruction, &task)
+		resolved.Tasks[i] = task
+	}
+	return &resolved
+}
+
+// layerSystemInstruction combines the chain-wide instruction with the one of
+// task according to its SystemInstructionMode.
+func layerSystemInstruction(chainInstruction string, task *TaskDefinition) string {
+	switch {
+	case chainInstruction == "":
+		return task.SystemInstruction
+	case task.SystemInstruction == "":
+		return chainInstruction
+	case task.SystemInstructionMode == SystemInstructionReplace:
+		return task.SystemInstruction
+	default:
+		return chainInstruction + "\n\n" + task.SystemInstruction
+	}
+}
+
+// samplingArgs returns the chat arguments for the sampling options of llmCall
+// other than temperature, which callers pass according to their own defaults.
+func samplingArgs(llmCall *LLMExecutionConfig) []libmodelprovider.ChatArgument {
+	if llmCall == nil {
+		return nil
+	}
+	var args []libmodelprovider.ChatArgument
+	if llmCall.TopP != 0 {
+		args = append(args, libmodelprovider.WithTopP(float64(llmCall.TopP)))
+	}
+	if llmCall.TopK != 0 {
+		args = append(args, libmodelprovider.WithTopK(llmCall.TopK))
+	}
+	if llmCall.FrequencyPenalty != 0 {
+		args = append(args, libmodelprovider.WithFrequencyPenalty(float64(llmCall.FrequencyPenalty)))
+	}
+	if llmCall.PresencePenalty != 0 {
+		args = append(args, libmodelprovider.WithPresencePenalty(float64(llmCall.PresencePenalty)))
+	}
+	if len(llmCall.Stop) > 0 {
+		args = append(args, libmodelprovider.WithStop(llmCall.Stop...))
+	}
+	if llmCall.MaxTokens != 0 {
+		args = append(args, libmodelprovider.WithMaxTokens(llmCall.MaxTokens))
+	}
+	return args
+}
+
+// checkSampling reports sampling options outside the ranges providers accept.
+// It returns the offending field and the problem.
+func checkSampling(llmCall *LLMExecutionConfig) (string, error) {
+	switch {
+	case llmCall == nil:
+		return "", nil
+	case llmCall.Temperature < 0 || llmCall.Temperature > 2:
+		return "execute_config.temperature", fmt.Errorf("temperature must be between 0 and 2, got %g", llmCall.Temperature)
+	case llmCall.TopP < 0 || llmCall.TopP > 1:
+		return "execute_config.top_p", fmt.Errorf("top_p must be between 0 and 1, got %g", llmCall.TopP)
+	case llmCall.TopK < 0:
+		return "execute_config.top_k", fmt.Errorf("top_k must not be negative, got %d", llmCall.TopK)
+	case llmCall.FrequencyPenalty < -2 || llmCall.FrequencyPenalty > 2:
+		return "execute_config.frequency_penalty", fmt.Errorf("frequency_penalty must be between -2 and 2, got %g", llmCall.FrequencyPenalty)
+	case llmCall.PresencePenalty < -2 || llmCall.PresencePenalty > 2:
+		return "execute_config.presence_penalty", fmt.Errorf("presence_penalty must be between -2 and 2, got %g", llmCall.PresencePenalty)
+	case llmCall.MaxTokens < 0:
+		return "execute_config.max_tokens", fmt.Errorf("max_tokens must not be negative, got %d", llmCall.MaxTokens)
+	}
+	return "", nil
+}
@@ -0,0 +1,166 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveChainDefaults_TaskFieldsWin(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "defaults",
+		DefaultExecuteConfig: &taskengine.LLMExecutionConfig{
+			Model:       "qwen3:8b",
+			Provider:    "ollama",
+			Temperature: 0.2,
+			Think:       "low",
+			ToolsPolicies: map[string]map[string]string{
+				"local_shell": {"_allowed_commands": "ls"},
+			},
+		},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "inherit", Handler: taskengine.HandleChatCompletion},
+			{
+				ID:      "override",
+				Handler: taskengine.HandleChatCompletion,
+				ExecuteConfig: &taskengine.LLMExecutionConfig{
+					Models:      []string{"gpt-4o", "gpt-4o-mini"},
+					Temperature: 0.9,
+					Tools:       []string{},
+					ToolsPolicies: map[string]map[string]string{
+						"local_fs": {"_allowed_dir": "/tmp"},
+					},
+				},
+			},
+		},
+	}
+
+	resolved := taskengine.ResolveChainDefaults(chain)
+
+	inherit := resolved.Tasks[0].ExecuteConfig
+	require.NotNil(t, inherit)
+	assert.Equal(t, "qwen3:8b", inherit.Model)
+	assert.Equal(t, "ollama", inherit.Provider)
+	assert.Equal(t, float32(0.2), inherit.Temperature)
+	assert.Equal(t, "low", inherit.Think)
+	assert.Nil(t, inherit.Tools)
+
+	override := resolved.Tasks[1].ExecuteConfig
+	require.NotNil(t, override)
+	assert.Empty(t, override.Model, "a task choosing its own models must not inherit the chain model")
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, override.Models)
+	assert.Equal(t, "ollama", override.Provider)
+	assert.Equal(t, float32(0.9), override.Temperature)
+	assert.Equal(t, []string{}, override.Tools, "explicit empty allowlist must be kept")
+	assert.Len(t, override.ToolsPolicies, 2)
+
+	// The original chain is not modified.
+	assert.Nil(t, chain.Tasks[0].ExecuteConfig)
+	assert.Len(t, chain.Tasks[1].ExecuteConfig.ToolsPolicies, 1)
+}
+
+func TestResolveChainDefaults_TaskOptsOutOfModelFallback(t *testing.T) {
+	on, off := true, false
+	chain := &taskengine.TaskChainDefinition{
+		ID: "fallback",
+		DefaultExecuteConfig: &taskengine.LLMExecutionConfig{
+			Models:        []string{"local", "cloud"},
+			ModelFallback: &on,
+		},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "inherit", Handler: taskengine.HandleChatCompletion, ExecuteConfig: &taskengine.LLMExecutionConfig{}},
+			{ID: "opt-out", Handler: taskengine.HandleChatCompletion, ExecuteConfig: &taskengine.LLMExecutionConfig{ModelFallback: &off}},
+		},
+	}
+
+	resolved := taskengine.ResolveChainDefaults(chain)
+	require.NotNil(t, resolved.Tasks[0].ExecuteConfig.ModelFallback)
+	assert.True(t, *resolved.Tasks[0].ExecuteConfig.ModelFallback)
+	require.NotNil(t, resolved.Tasks[1].ExecuteConfig.ModelFallback)
+	assert.False(t, *resolved.Tasks[1].ExecuteConfig.ModelFallback)
+}
+
+func TestResolveChainDefaults_NoDefaults(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID:    "plain",
+		Tasks: []taskengine.TaskDefinition{{ID: "a", Handler: taskengine.HandleNoop}},
+	}
+	assert.Same(t, chain, taskengine.ResolveChainDefaults(chain))
+}
+
+func TestExecEnv_InheritsChainExecuteConfig(t *testing.T) {
+	mockExec := &taskengine.MockTaskExecutor{
+		MockOutput:          "ok",
+		MockTransitionValue: "ok",
+	}
+	env := setupTestEnv(mockExec)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID:                   "inherit",
+		DefaultExecuteConfig: &taskengine.LLMExecutionConfig{Model: "default-model"},
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "ask",
+				Handler:        taskengine.HandlePromptToString,
+				PromptTemplate: "{{.input}}",
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "hi", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.NotNil(t, mockExec.CalledWithTask)
+	require.NotNil(t, mockExec.CalledWithTask.ExecuteConfig)
+	assert.Equal(t, "default-model", mockExec.CalledWithTask.ExecuteConfig.Model)
+}
+
+func TestResolveChainDefaults_InheritsSampling(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "sampling",
+		DefaultExecuteConfig: &taskengine.LLMExecutionConfig{
+			TopP:      0.9,
+			TopK:      40,
+			Stop:      []string{"###"},
+			MaxTokens: 512,
+		},
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:            "override",
+				Handler:       taskengine.HandlePromptToString,
+				ExecuteConfig: &taskengine.LLMExecutionConfig{TopP: 0.5, PresencePenalty: 1},
+			},
+		},
+	}
+
+	cfg := taskengine.ResolveChainDefaults(chain).Tasks[0].ExecuteConfig
+	require.NotNil(t, cfg)
+	assert.Equal(t, float32(0.5), cfg.TopP)
+	assert.Equal(t, 40, cfg.TopK)
+	assert.Equal(t, float32(1), cfg.PresencePenalty)
+	assert.Equal(t, []string{"###"}, cfg.Stop)
+	assert.Equal(t, 512, cfg.MaxTokens)
+}
+
+func TestResolveChainDefaults_LayersSystemInstruction(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID:                "persona",
+		SystemInstruction: "You are Acme's support assistant.",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "inherit", Handler: taskengine.HandleChatCompletion},
+			{ID: "append", Handler: taskengine.HandleChatCompletion, SystemInstruction: "Answer in one sentence."},
+			{ID: "replace", Handler: taskengine.HandleChatCompletion, SystemInstruction: "Reply with JSON only.", SystemInstructionMode: taskengine.SystemInstructionReplace},
+		},
+	}
+
+	resolved := taskengine.ResolveChainDefaults(chain)
+	assert.Equal(t, "You are Acme's support assistant.", resolved.Tasks[0].SystemInstruction)
+	assert.Equal(t, "You are Acme's support assistant.\n\nAnswer in one sentence.", resolved.Tasks[1].SystemInstruction)
+	assert.Equal(t, "Reply with JSON only.", resolved.Tasks[2].SystemInstruction)
+	assert.Empty(t, chain.Tasks[0].SystemInstruction, "the original chain is not modified")
+}
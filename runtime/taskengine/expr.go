@@ -0,0 +1,855 @@
+package taskengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Transition expressions (OpExpr) are boolean expressions over the task output
+// and the chain variables, e.g.
+//
+//	output.score > 0.7 && vars.env == "prod"
+//	transition in ["yes", "y"] || len(output.items) == 0
+//
+// Identifiers resolve to "output" (the task output), "transition" (the
+// transition value) and every chain variable: "input", task IDs and "vars"
+// (the values written via store_as). Missing fields evaluate to null, so a
+// comparison against an unset variable is false instead of an error. Field
+// access on a string decodes it as JSON first, which lets chains inspect
+// JSON returned as text by a model.
+//
+// Operators, by increasing precedence: || (or), && (and), == != < <= > >= in,
+// + -, * / %, and the unary ! (not) and -. Functions: len, lower, upper,
+// trim, contains, starts_with, ends_with, matches (regular expression),
+// number, string and bool.
+
+// exprScope resolves the identifiers of a transition expression.
+type exprScope struct {
+	output     any
+	transition string
+	vars       map[string]any
+}
+
+func (s *exprScope) lookup(name string) any {
+	switch name {
+	case "output":
+		return s.output
+	case "transition":
+		return s.transition
+	}
+	return s.vars[name]
+}
+
+var exprCache sync.Map // expression source → *exprNode
+
+// compileExpr parses src, caching the result.
+func compileExpr(src string) (*exprNode, error) {
+	if n, ok := exprCache.Load(src); ok {
+		return n.(*exprNode), nil
+	}
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	exprCache.Store(src, n)
+	return n, nil
+}
+
+// evalCondition evaluates src in scope and reports whether the result is truthy.
+func evalCondition(src string, scope *exprScope) (bool, error) {
+	n, err := compileExpr(src)
+	if err != nil {
+		return false, fmt.Errorf("expression %q: %w", src, err)
+	}
+	v, err := n.eval(scope)
+	if err != nil {
+		return false, fmt.Errorf("expression %q: %w", src, err)
+	}
+	return truthy(v), nil
+}
+
+// --- lexer ---
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind tokKind
+	text string
+	pos  int
+	num  float64
+}
+
+var exprOps = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ".", ","}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.' || src[i] == 'e' || src[i] == 'E' ||
+				(src[i] == '-' || src[i] == '+') && (src[i-1] == 'e' || src[i-1] == 'E')) {
+				i++
+			}
+			f, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at offset %d", src[start:i], start)
+			}
+			toks = append(toks, exprToken{kind: tokNumber, text: src[start:i], pos: start, num: f})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			var b strings.Builder
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at offset %d", start)
+				}
+				if rune(src[i]) == c {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+					switch src[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(src[i])
+					}
+					i++
+					continue
+				}
+				b.WriteByte(src[i])
+				i++
+			}
+			toks = append(toks, exprToken{kind: tokString, text: b.String(), pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			word := src[start:i]
+			switch word {
+			case "and":
+				toks = append(toks, exprToken{kind: tokOp, text: "&&", pos: start})
+			case "or":
+				toks = append(toks, exprToken{kind: tokOp, text: "||", pos: start})
+			case "not":
+				toks = append(toks, exprToken{kind: tokOp, text: "!", pos: start})
+			default:
+				toks = append(toks, exprToken{kind: tokIdent, text: word, pos: start})
+			}
+		default:
+			matched := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(src[i:], op) {
+					toks = append(toks, exprToken{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return append(toks, exprToken{kind: tokEOF, text: "end of expression", pos: len(src)}), nil
+}
+
+// --- parser ---
+
+type exprKind int
+
+const (
+	exprLiteral exprKind = iota
+	exprIdent
+	exprList
+	exprUnary
+	exprBinary
+	exprField
+	exprIndex
+	exprCall
+)
+
+type exprNode struct {
+	kind  exprKind
+	op    string
+	value any
+	name  string
+	args  []*exprNode
+}
+
+type exprParser struct {
+	toks []exprToken
+	pos  int
+}
+
+func (p *exprParser) peek() exprToken { return p.toks[p.pos] }
+
+func (p *exprParser) next() exprToken {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) expectOp(op string) error {
+	if _, ok := p.acceptOp(op); !ok {
+		t := p.peek()
+		return fmt.Errorf("expected %q, got %q at offset %d", op, t.text, t.pos)
+	}
+	return nil
+}
+
+func (p *exprParser) binary(ops []string, operand func() (*exprNode, error)) (*exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprNode{kind: exprBinary, op: op, args: []*exprNode{left, right}}
+	}
+}
+
+func (p *exprParser) parseOr() (*exprNode, error) {
+	return p.binary([]string{"||"}, p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (*exprNode, error) {
+	return p.binary([]string{"&&"}, p.parseCompare)
+}
+
+func (p *exprParser) parseCompare() (*exprNode, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		if t := p.peek(); t.kind == tokIdent && t.text == "in" {
+			p.pos++
+			op, ok = "in", true
+		}
+	}
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	return &exprNode{kind: exprBinary, op: op, args: []*exprNode{left, right}}, nil
+}
+
+func (p *exprParser) parseAdd() (*exprNode, error) {
+	return p.binary([]string{"+", "-"}, p.parseMul)
+}
+
+func (p *exprParser) parseMul() (*exprNode, error) {
+	return p.binary([]string{"*", "/", "%"}, p.parseUnary)
+}
+
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	if op, ok := p.acceptOp("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{kind: exprUnary, op: op, args: []*exprNode{operand}}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (*exprNode, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch op, _ := p.acceptOp(".", "[", "("); op {
+		case ".":
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected field name after '.', got %q at offset %d", t.text, t.pos)
+			}
+			n = &exprNode{kind: exprField, name: t.text, args: []*exprNode{n}}
+		case "[":
+			idx, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+			n = &exprNode{kind: exprIndex, args: []*exprNode{n, idx}}
+		case "(":
+			if n.kind != exprIdent {
+				return nil, fmt.Errorf("only functions can be called")
+			}
+			if _, ok := exprFuncs[n.name]; !ok {
+				return nil, fmt.Errorf("unknown function %q", n.name)
+			}
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			n = &exprNode{kind: exprCall, name: n.name, args: args}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *exprParser) parseList(closing string) ([]*exprNode, error) {
+	var items []*exprNode
+	if _, ok := p.acceptOp(closing); ok {
+		return items, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if _, ok := p.acceptOp(","); ok {
+			continue
+		}
+		if err := p.expectOp(closing); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+func (p *exprParser) parsePrimary() (*exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &exprNode{kind: exprLiteral, value: t.num}, nil
+	case tokString:
+		return &exprNode{kind: exprLiteral, value: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &exprNode{kind: exprLiteral, value: true}, nil
+		case "false":
+			return &exprNode{kind: exprLiteral, value: false}, nil
+		case "null", "nil":
+			return &exprNode{kind: exprLiteral}, nil
+		}
+		return &exprNode{kind: exprIdent, name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expectOp(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &exprNode{kind: exprList, args: items}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+// --- evaluation ---
+
+func (n *exprNode) eval(scope *exprScope) (any, error) {
+	switch n.kind {
+	case exprLiteral:
+		return n.value, nil
+	case exprIdent:
+		return scope.lookup(n.name), nil
+	case exprList:
+		out := make([]any, len(n.args))
+		for i, a := range n.args {
+			v, err := a.eval(scope)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	case exprUnary:
+		v, err := n.args[0].eval(scope)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "!" {
+			return !truthy(v), nil
+		}
+		f, ok := exprNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", describeValue(v))
+		}
+		return -f, nil
+	case exprBinary:
+		return n.evalBinary(scope)
+	case exprField:
+		v, err := n.args[0].eval(scope)
+		if err != nil {
+			return nil, err
+		}
+		return exprFieldValue(v, n.name), nil
+	case exprIndex:
+		v, err := n.args[0].eval(scope)
+		if err != nil {
+			return nil, err
+		}
+		idx, err := n.args[1].eval(scope)
+		if err != nil {
+			return nil, err
+		}
+		return exprIndexValue(v, idx), nil
+	case exprCall:
+		args := make([]any, len(n.args))
+		for i, a := range n.args {
+			v, err := a.eval(scope)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return exprFuncs[n.name](args)
+	}
+	return nil, fmt.Errorf("invalid expression")
+}
+
+func (n *exprNode) evalBinary(scope *exprScope) (any, error) {
+	left, err := n.args[0].eval(scope)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit logic operators.
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := n.args[1].eval(scope)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := n.args[1].eval(scope)
+		return truthy(right), err
+	}
+	right, err := n.args[1].eval(scope)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		return exprOrder(n.op, left, right)
+	case "in":
+		return exprContains(right, left), nil
+	case "+":
+		if ls, ok := left.(string); ok {
+			if rs, ok := right.(string); ok {
+				return ls + rs, nil
+			}
+		}
+	}
+	l, lok := exprNumber(left)
+	r, rok := exprNumber(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s needs numbers, got %s and %s", n.op, describeValue(left), describeValue(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+// exprValue converts typed values (e.g. ChatHistory) to their generic JSON
+// form so fields can be accessed by their JSON names.
+func exprValue(v any) any {
+	switch v.(type) {
+	case nil, bool, string, float64, map[string]any, []any:
+		return v
+	}
+	if _, ok := exprNumber(v); ok {
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func exprFieldValue(v any, name string) any {
+	v = exprValue(v)
+	if s, ok := v.(string); ok {
+		var decoded any
+		if err := json.Unmarshal([]byte(extractJSONValue(strings.TrimSpace(s))), &decoded); err != nil {
+			return nil
+		}
+		v = decoded
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m[name]
+	}
+	return nil
+}
+
+func exprIndexValue(v, idx any) any {
+	v = exprValue(v)
+	if s, ok := idx.(string); ok {
+		return exprFieldValue(v, s)
+	}
+	f, ok := exprNumber(idx)
+	if !ok {
+		return nil
+	}
+	i := int(f)
+	switch c := v.(type) {
+	case []any:
+		if i < 0 {
+			i += len(c)
+		}
+		if i >= 0 && i < len(c) {
+			return c[i]
+		}
+	case string:
+		r := []rune(c)
+		if i < 0 {
+			i += len(r)
+		}
+		if i >= 0 && i < len(r) {
+			return string(r[i])
+		}
+	}
+	return nil
+}
+
+// exprNumber converts numbers and numeric strings to float64.
+func exprNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func isExprNumber(v any) bool {
+	if _, ok := v.(string); ok {
+		return false
+	}
+	_, ok := exprNumber(v)
+	return ok
+}
+
+func exprEqual(a, b any) bool {
+	// Numbers compare numerically, also against numeric strings such as a
+	// transition value "7".
+	if isExprNumber(a) || isExprNumber(b) {
+		l, lok := exprNumber(a)
+		r, rok := exprNumber(b)
+		return lok && rok && l == r
+	}
+	return reflect.DeepEqual(exprValue(a), exprValue(b))
+}
+
+func exprOrder(op string, a, b any) (bool, error) {
+	var cmp int
+	l, lok := exprNumber(a)
+	r, rok := exprNumber(b)
+	switch {
+	case lok && rok:
+		cmp = compareFloats(l, r)
+	default:
+		ls, lok := a.(string)
+		rs, rok := b.(string)
+		if !lok || !rok {
+			return false, fmt.Errorf("cannot compare %s %s %s", describeValue(a), op, describeValue(b))
+		}
+		cmp = strings.Compare(ls, rs)
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// exprContains implements "needle in haystack" for lists, object keys and substrings.
+func exprContains(haystack, needle any) bool {
+	switch h := exprValue(haystack).(type) {
+	case []any:
+		for _, item := range h {
+			if exprEqual(item, needle) {
+				return true
+			}
+		}
+	case map[string]any:
+		if s, ok := needle.(string); ok {
+			_, found := h[s]
+			return found
+		}
+	case string:
+		if s, ok := needle.(string); ok {
+			return strings.Contains(h, s)
+		}
+	}
+	return false
+}
+
+func truthy(v any) bool {
+	switch t := exprValue(v).(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case []any:
+		return len(t) > 0
+	case map[string]any:
+		return len(t) > 0
+	}
+	if f, ok := exprNumber(v); ok {
+		return f != 0
+	}
+	return true
+}
+
+func describeValue(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	}
+	if isExprNumber(v) {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// --- functions ---
+
+var exprFuncs map[string]func(args []any) (any, error)
+
+func init() {
+	exprFuncs = map[string]func(args []any) (any, error){
+		"len": func(args []any) (any, error) {
+			if err := exprArity("len", args, 1); err != nil {
+				return nil, err
+			}
+			switch v := exprValue(args[0]).(type) {
+			case nil:
+				return 0.0, nil
+			case string:
+				return float64(len([]rune(v))), nil
+			case []any:
+				return float64(len(v)), nil
+			case map[string]any:
+				return float64(len(v)), nil
+			}
+			return nil, fmt.Errorf("len: unsupported %s", describeValue(args[0]))
+		},
+		"lower":       stringFunc("lower", strings.ToLower),
+		"upper":       stringFunc("upper", strings.ToUpper),
+		"trim":        stringFunc("trim", strings.TrimSpace),
+		"contains":    stringPredicate("contains", strings.Contains),
+		"starts_with": stringPredicate("starts_with", strings.HasPrefix),
+		"ends_with":   stringPredicate("ends_with", strings.HasSuffix),
+		"matches": func(args []any) (any, error) {
+			if err := exprArity("matches", args, 2); err != nil {
+				return nil, err
+			}
+			re, err := regexp.Compile(exprString(args[1]))
+			if err != nil {
+				return nil, fmt.Errorf("matches: %w", err)
+			}
+			return re.MatchString(exprString(args[0])), nil
+		},
+		"number": func(args []any) (any, error) {
+			if err := exprArity("number", args, 1); err != nil {
+				return nil, err
+			}
+			if f, ok := exprNumber(args[0]); ok {
+				return f, nil
+			}
+			if s, ok := args[0].(string); ok {
+				if f, err := parseNumber(s); err == nil {
+					return f, nil
+				}
+			}
+			return nil, fmt.Errorf("number: cannot convert %s", describeValue(args[0]))
+		},
+		"string": func(args []any) (any, error) {
+			if err := exprArity("string", args, 1); err != nil {
+				return nil, err
+			}
+			return exprString(args[0]), nil
+		},
+		"bool": func(args []any) (any, error) {
+			if err := exprArity("bool", args, 1); err != nil {
+				return nil, err
+			}
+			return truthy(args[0]), nil
+		},
+	}
+}
+
+func exprArity(name string, args []any, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("%s expects %d argument(s), got %d", name, n, len(args))
+	}
+	return nil
+}
+
+func exprString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	switch t := exprValue(v).(type) {
+	case map[string]any, []any:
+		data, _ := json.Marshal(t)
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+func stringFunc(name string, fn func(string) string) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if err := exprArity(name, args, 1); err != nil {
+			return nil, err
+		}
+		return fn(exprString(args[0])), nil
+	}
+}
+
+func stringPredicate(name string, fn func(string, string) bool) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if err := exprArity(name, args, 2); err != nil {
+			return nil, err
+		}
+		return fn(exprString(args[0]), exprString(args[1])), nil
+	}
+}
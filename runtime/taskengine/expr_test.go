@@ -0,0 +1,64 @@
+package taskengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_EvalCondition(t *testing.T) {
+	scope := &exprScope{
+		output:     map[string]any{"score": 0.82, "labels": []any{"bug", "ui"}, "meta": map[string]any{"lang": "go"}},
+		transition: "7",
+		vars: map[string]any{
+			"input":      "deploy the api",
+			"classify":   `Sure! {"category": "billing", "confidence": 0.4}`,
+			"history":    ChatHistory{Messages: []Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}},
+			"count":      3,
+			chainVarsKey: map[string]any{"env": "prod"},
+		},
+	}
+	for _, tc := range []struct {
+		expr string
+		want bool
+	}{
+		{`output.score > 0.7 && vars.env == "prod"`, true},
+		{`output.score > 0.9 || vars.env != "prod"`, false},
+		{`transition == 7 and transition >= "7"`, true},
+		{`"ui" in output.labels && !("ops" in output.labels)`, true},
+		{`output.meta.lang == "go" && output["meta"]["lang"] == "go"`, true},
+		{`classify.category == "billing" && classify.confidence < 0.5`, true},
+		{`len(history.messages) == 2 && history.messages[-1].content == "hello"`, true},
+		{`count * 2 + 1 == 7 && count % 2 == 1`, true},
+		{`starts_with(lower(input), "deploy") && contains(input, "api")`, true},
+		{`matches(input, "^deploy\\s+")`, true},
+		{`vars.missing == null && !vars.missing.deeper`, true},
+		{`number("score: 9") > 8`, true},
+		{`len(output.labels) == 0`, false},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := evalCondition(tc.expr, scope)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestUnit_EvalCondition_Errors(t *testing.T) {
+	scope := &exprScope{output: "text"}
+	for _, expr := range []string{
+		`output.score >`,
+		`output == "x`,
+		`unknown_fn(output)`,
+		`(output == "text"`,
+		`output # 1`,
+	} {
+		_, err := compileExpr(expr)
+		assert.Error(t, err, expr)
+	}
+
+	_, err := evalCondition(`output > 3`, scope)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot compare string > number")
+}
@@ -0,0 +1,115 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/llmresolver"
+)
+
+// Actions of a ChainFallback.
+const (
+	// FallbackMessage ends the run with a canned message.
+	FallbackMessage = "message"
+	// FallbackHook ends the run with the output of a hook call.
+	FallbackHook = "hook"
+	// FallbackQueue checkpoints the run at the failing task so it can be
+	// resumed once a model is available again.
+	FallbackQueue = "queue"
+)
+
+// ErrRunQueued is returned by ExecEnv when a chain with a "queue" fallback
+// could not resolve a model. The returned error is a *RunQueuedError.
+var ErrRunQueued = errors.New("run queued until a model is available")
+
+// ChainFallback is the policy a chain applies when a task fails because no
+// model could be resolved (no backend reachable, no model matching the
+// request). Other task failures are not affected.
+//
+// example:
+//
+//	fallback:
+//	  action: message
+//	  message: "The assistant is unavailable right now. Please try again later."
+type ChainFallback struct {
+	// Action is one of "message", "hook" or "queue".
+	Action string `yaml:"action" json:"action" example:"message"`
+
+	// Message is the answer returned by the "message" action. It is a
+	// template rendered with the chain variables. A chat history input is
+	// answered with an assistant message, any other input with a string.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Hook is called by the "hook" action with the input of the failing task;
+	// its output becomes the output of the chain.
+	Hook *ToolsCall `yaml:"hook,omitempty" json:"hook,omitempty" openapi_include_type:"taskengine.ToolsCall"`
+}
+
+// RunQueuedError reports a run that was checkpointed by a "queue" fallback.
+type RunQueuedError struct {
+	RunID  string
+	TaskID string
+	// Cause is the model resolution error of the task.
+	Cause error
+}
+
+func (e *RunQueuedError) Error() string {
+	return fmt.Sprintf("run %s: task %s: %s: %v", e.RunID, e.TaskID, ErrRunQueued, e.Cause)
+}
+
+func (e *RunQueuedError) Unwrap() []error { return []error{ErrRunQueued, e.Cause} }
+
+// IsModelUnavailable reports whether err was caused by a failed model
+// resolution, i.e. no backend served a model matching the request.
+func IsModelUnavailable(err error) bool {
+	return errors.Is(err, llmresolver.ErrNoAvailableModels) || errors.Is(err, llmresolver.ErrNoSatisfactoryModel)
+}
+
+// degrade applies the fallback of chain to task, which failed with cause.
+// The checkpoint is used by the "queue" action and holds the state before task.
+func (env SimpleEnv) degrade(ctx context.Context, chain *TaskChainDefinition, task *TaskDefinition, input any, inputType DataType, vars map[string]any, cp *Checkpoint, cause error) (any, DataType, error) {
+	fallback := chain.Fallback
+	switch fallback.Action {
+	case FallbackMessage:
+		msg, err := renderTemplate(fallback.Message, vars)
+		if err != nil {
+			return nil, DataTypeAny, fmt.Errorf("fallback message template error: %v", err)
+		}
+		if history, ok := input.(ChatHistory); ok && inputType == DataTypeChatHistory {
+			messages := make([]Message, len(history.Messages), len(history.Messages)+1)
+			copy(messages, history.Messages)
+			history.Messages = append(messages, Message{Role: "assistant", Content: msg, Timestamp: time.Now().UTC()})
+			return history, DataTypeChatHistory, nil
+		}
+		return msg, DataTypeString, nil
+	case FallbackHook:
+		call := *fallback.Hook
+		hook, err := renderToolsArgs(&TaskDefinition{
+			ID:      task.ID + "_fallback",
+			Handler: HandleTools,
+			Tools:   &call,
+		}, vars)
+		if err != nil {
+			return nil, DataTypeAny, fmt.Errorf("fallback hook: %w", err)
+		}
+		chainContext := &ChainContext{Tools: map[string]ToolWithResolution{}, Debug: chain.Debug}
+		output, outputType, _, err := env.exec.TaskExec(ctx, time.Now().UTC(), int(chain.TokenLimit), chainContext, hook, input, inputType)
+		if err != nil {
+			return nil, DataTypeAny, fmt.Errorf("fallback hook %s: %w (after %w)", fallback.Hook.Name, err, cause)
+		}
+		return output, outputType, nil
+	case FallbackQueue:
+		checkpoints := checkpointingFromContext(ctx)
+		if checkpoints == nil {
+			return nil, DataTypeAny, fmt.Errorf("queue fallback requires checkpointing: %w", cause)
+		}
+		if err := checkpoints.persist(ctx, cp); err != nil {
+			return nil, DataTypeAny, fmt.Errorf("queue fallback: save checkpoint: %w (after %w)", err, cause)
+		}
+		return nil, DataTypeAny, &RunQueuedError{RunID: checkpoints.runID, TaskID: task.ID, Cause: cause}
+	default:
+		return nil, DataTypeAny, fmt.Errorf("unknown fallback action %q: %w", fallback.Action, cause)
+	}
+}
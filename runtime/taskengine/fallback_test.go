@@ -0,0 +1,120 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/internal/llmresolver"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableExecutor fails every model task as if no backend were reachable
+// and answers tools tasks with their input.
+type unreachableExecutor struct {
+	ran []string
+}
+
+func (u *unreachableExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, dataType taskengine.DataType) (any, taskengine.DataType, string, error) {
+	u.ran = append(u.ran, task.ID)
+	switch task.Handler {
+	case taskengine.HandleNoop:
+		return input, dataType, "ok", nil
+	case taskengine.HandleTools:
+		return fmt.Sprintf("%s(%s): %v", task.Tools.Name, task.Tools.Args["note"], input), taskengine.DataTypeString, "ok", nil
+	}
+	return nil, taskengine.DataTypeAny, "", fmt.Errorf("prompt execute: client resolution failed: %w", llmresolver.ErrNoAvailableModels)
+}
+
+func fallbackChain(fallback *taskengine.ChainFallback) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID:       "support",
+		Fallback: fallback,
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "prepare", Handler: taskengine.HandleNoop, Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: "answer"}}}},
+			{ID: "answer", Handler: taskengine.HandleChatCompletion, Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}}}},
+		},
+	}
+}
+
+func TestFallback_Message(t *testing.T) {
+	chain := fallbackChain(&taskengine.ChainFallback{Action: taskengine.FallbackMessage, Message: "Sorry, {{.input}} has to wait."})
+
+	out, dt, _, err := setupTestEnv(&unreachableExecutor{}).ExecEnv(context.Background(), chain, "your question", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeString, dt)
+	assert.Equal(t, "Sorry, your question has to wait.", out)
+
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "hi"}}}
+	chain.Fallback.Message = "Unavailable."
+	out, dt, _, err = setupTestEnv(&unreachableExecutor{}).ExecEnv(context.Background(), chain, history, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.DataTypeChatHistory, dt)
+	got := out.(taskengine.ChatHistory)
+	require.Len(t, got.Messages, 2)
+	assert.Equal(t, "assistant", got.Messages[1].Role)
+	assert.Equal(t, "Unavailable.", got.Messages[1].Content)
+	assert.Len(t, history.Messages, 1, "input history must not be modified")
+}
+
+func TestFallback_Hook(t *testing.T) {
+	chain := fallbackChain(&taskengine.ChainFallback{
+		Action: taskengine.FallbackHook,
+		Hook:   &taskengine.ToolsCall{Name: "notify", Args: map[string]string{"note": "{{.prepare}}"}},
+	})
+	exec := &unreachableExecutor{}
+
+	out, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), chain, "ticket", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "notify(ticket): ticket", out)
+	assert.Equal(t, []string{"prepare", "answer", "answer_fallback"}, exec.ran)
+}
+
+func TestFallback_Queue(t *testing.T) {
+	chain := fallbackChain(&taskengine.ChainFallback{Action: taskengine.FallbackQueue})
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-q")
+
+	_, _, _, err := setupTestEnv(&unreachableExecutor{}).ExecEnv(ctx, chain, "later", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrRunQueued)
+	var queued *taskengine.RunQueuedError
+	require.True(t, errors.As(err, &queued))
+	assert.Equal(t, "answer", queued.TaskID)
+	assert.True(t, taskengine.IsModelUnavailable(err))
+
+	cp, err := store.LoadCheckpoint(ctx, "run-q")
+	require.NoError(t, err)
+	assert.Equal(t, "answer", cp.NextTaskID)
+	assert.Equal(t, "later", cp.Output)
+
+	// Without checkpointing the run cannot be queued and the cause surfaces.
+	_, _, _, err = setupTestEnv(&unreachableExecutor{}).ExecEnv(context.Background(), chain, "later", taskengine.DataTypeString)
+	assert.ErrorIs(t, err, llmresolver.ErrNoAvailableModels)
+	assert.NotErrorIs(t, err, taskengine.ErrRunQueued)
+
+	// A run whose checkpoint cannot be written is not reported as queued.
+	ctx = taskengine.WithCheckpoints(context.Background(), &failingCheckpointStore{}, "run-lost")
+	_, _, _, err = setupTestEnv(&unreachableExecutor{}).ExecEnv(ctx, chain, "later", taskengine.DataTypeString)
+	assert.ErrorContains(t, err, "disk full")
+	assert.ErrorIs(t, err, llmresolver.ErrNoAvailableModels)
+	assert.NotErrorIs(t, err, taskengine.ErrRunQueued)
+}
+
+// failingCheckpointStore has no checkpoints and fails every write.
+type failingCheckpointStore struct{ memCheckpointStore }
+
+func (*failingCheckpointStore) SaveCheckpoint(context.Context, *taskengine.Checkpoint) error {
+	return errors.New("disk full")
+}
+
+func TestFallback_IgnoresOtherErrors(t *testing.T) {
+	chain := fallbackChain(&taskengine.ChainFallback{Action: taskengine.FallbackMessage, Message: "Unavailable."})
+	exec := &flakyExecutor{failOn: map[string]bool{"answer": true}}
+
+	_, _, _, err := setupTestEnv(exec).ExecEnv(context.Background(), chain, "q", taskengine.DataTypeString)
+	assert.ErrorContains(t, err, "interrupted")
+}
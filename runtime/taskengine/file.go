@@ -0,0 +1,121 @@
+package taskengine
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxFileSize bounds the size of DataTypeFile values in chains that do
+// not set TaskChainDefinition.MaxFileSize.
+const DefaultMaxFileSize int64 = 20 << 20
+
+// ErrFileTooLarge is the task error of a task that produced, or received as
+// chain input, a file larger than the chain's file size limit.
+var ErrFileTooLarge = errors.New("file exceeds size limit")
+
+// File is a binary payload passed between tasks as DataTypeFile, e.g. an
+// image, PDF or archive returned by a hook. Data is base64-encoded when the
+// file is marshalled to JSON, so hooks exchange files without extra encoding.
+type File struct {
+	Name     string `json:"name,omitempty" example:"report.pdf"`
+	MimeType string `json:"mimeType,omitempty" example:"application/pdf"`
+	Data     []byte `json:"data" openapi_include_type:"string"`
+}
+
+// NewFile returns a File for data. When mimeType is empty it is derived from
+// the extension of name or, failing that, sniffed from data.
+func NewFile(name, mimeType string, data []byte) File {
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return File{Name: name, MimeType: mimeType, Data: data}
+}
+
+// String describes the file without its content. It is what prompt templates
+// and string conversions see, so a binary payload never ends up in a prompt.
+func (f File) String() string {
+	name := f.Name
+	if name == "" {
+		name = "unnamed"
+	}
+	return fmt.Sprintf("[file %s, %s, %d bytes]", name, f.MimeType, len(f.Data))
+}
+
+// DataURL returns the file as an RFC 2397 data URL.
+func (f File) DataURL() string {
+	return "data:" + f.MimeType + ";base64," + base64.StdEncoding.EncodeToString(f.Data)
+}
+
+func convertToFile(value any) (File, error) {
+	switch v := value.(type) {
+	case File:
+		return v, nil
+	case *File:
+		if v == nil {
+			return File{}, fmt.Errorf("cannot convert nil *File to File")
+		}
+		return *v, nil
+	case []byte:
+		return NewFile("", "", v), nil
+	case string:
+		return parseDataURL(v)
+	case map[string]any:
+		// A File that went through JSON, e.g. the response of a remote hook.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return File{}, err
+		}
+		var f File
+		if err := json.Unmarshal(data, &f); err != nil {
+			return File{}, fmt.Errorf("cannot convert JSON to File: %w", err)
+		}
+		if f.MimeType == "" {
+			f = NewFile(f.Name, "", f.Data)
+		}
+		return f, nil
+	default:
+		return File{}, fmt.Errorf("cannot convert %T to File", value)
+	}
+}
+
+func parseDataURL(s string) (File, error) {
+	rest, ok := strings.CutPrefix(s, "data:")
+	meta, payload, ok2 := strings.Cut(rest, ",")
+	mimeType, isBase64 := strings.CutSuffix(meta, ";base64")
+	if !ok || !ok2 || !isBase64 {
+		return File{}, fmt.Errorf("cannot convert string to File: want a base64 data URL")
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return File{}, fmt.Errorf("cannot convert string to File: %w", err)
+	}
+	return NewFile("", mimeType, data), nil
+}
+
+// checkFileSize enforces the chain's file size limit on a DataTypeFile value.
+func checkFileSize(chain *TaskChainDefinition, value any, dataType DataType) error {
+	if dataType != DataTypeFile {
+		return nil
+	}
+	f, err := convertToFile(value)
+	if err != nil {
+		return err
+	}
+	limit := chain.MaxFileSize
+	if limit <= 0 {
+		limit = DefaultMaxFileSize
+	}
+	if int64(len(f.Data)) > limit {
+		return fmt.Errorf("%w: %s is larger than %d bytes", ErrFileTooLarge, f, limit)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package taskengine_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileExecutor answers tools tasks with a PNG file and passes the input of
+// other tasks through.
+type fileExecutor struct {
+	inputs map[string]any
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func (f *fileExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, dataType taskengine.DataType) (any, taskengine.DataType, string, error) {
+	if f.inputs == nil {
+		f.inputs = map[string]any{}
+	}
+	f.inputs[task.ID] = input
+	if task.Handler == taskengine.HandleTools {
+		return taskengine.NewFile("chart.png", "", pngHeader), taskengine.DataTypeFile, "ok", nil
+	}
+	return input, dataType, "ok", nil
+}
+
+func fileChain(maxSize int64) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID:          "render",
+		MaxFileSize: maxSize,
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "plot", Handler: taskengine.HandleTools, Tools: &taskengine.ToolsCall{Name: "charts"}, Transition: goTo("upload")},
+			{ID: "upload", Handler: taskengine.HandleNoop, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+}
+
+func TestFile_PassedBetweenTasks(t *testing.T) {
+	exec := &fileExecutor{}
+
+	out, dt, _, err := setupTestEnv(exec).ExecEnv(context.Background(), fileChain(0), "q", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeFile, dt)
+	file := out.(taskengine.File)
+	assert.Equal(t, "image/png", file.MimeType)
+	assert.Equal(t, pngHeader, file.Data)
+	assert.Equal(t, file, exec.inputs["upload"])
+}
+
+func TestFile_SizeLimit(t *testing.T) {
+	_, _, _, err := setupTestEnv(&fileExecutor{}).ExecEnv(context.Background(), fileChain(8), "q", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrFileTooLarge)
+	assert.ErrorContains(t, err, "task plot")
+
+	big := taskengine.NewFile("dump.bin", "", make([]byte, 16))
+	_, _, _, err = setupTestEnv(&fileExecutor{}).ExecEnv(context.Background(), fileChain(8), big, taskengine.DataTypeFile)
+	require.ErrorIs(t, err, taskengine.ErrFileTooLarge)
+}
+
+func TestUnit_ConvertToFile(t *testing.T) {
+	file := taskengine.NewFile("report.pdf", "", []byte("%PDF-1.7"))
+	assert.Equal(t, "application/pdf", file.MimeType)
+	assert.Equal(t, "[file report.pdf, application/pdf, 8 bytes]", file.String())
+
+	s, err := taskengine.ConvertToType(file, taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, file.String(), s, "files never render their content into strings")
+
+	// A file that went through JSON, e.g. the response of a remote hook.
+	data, err := json.Marshal(file)
+	require.NoError(t, err)
+	var decoded any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	got, err := taskengine.ConvertToType(decoded, taskengine.DataTypeFile)
+	require.NoError(t, err)
+	assert.Equal(t, file, got)
+
+	got, err = taskengine.ConvertToType(file.DataURL(), taskengine.DataTypeFile)
+	require.NoError(t, err)
+	assert.Equal(t, file.Data, got.(taskengine.File).Data)
+
+	_, err = taskengine.ConvertToType("plain text", taskengine.DataTypeFile)
+	assert.Error(t, err)
+	assert.Equal(t, taskengine.DataTypeFile, taskengine.InferDataType(file))
+
+	dt, err := taskengine.DataTypeFromString("file")
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeFile, dt)
+}
@@ -0,0 +1,153 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"golang.org/x/sync/errgroup"
+)
+
+// execForEach runs the ForEachConfig body once per element of the JSON array
+// input, bounded by ForEachConfig.MaxConcurrency, and collects the outputs into
+// an array in input order.
+//
+// Without FailFast every iteration runs and all errors are joined. With FailFast
+// the first error cancels in-flight iterations and skips the remaining ones.
+//
+// The returned steps are ordered by element index.
+func (env SimpleEnv) execForEach(
+	ctx context.Context,
+	startingTime time.Time,
+	chain *TaskChainDefinition,
+	chainContext *ChainContext,
+	task *TaskDefinition,
+	vars map[string]any,
+	input any,
+) ([]any, []CapturedStateUnit, error) {
+	cfg := task.ForEach
+	if cfg == nil || (cfg.Task == "" && cfg.Chain == nil) {
+		return nil, nil, fmt.Errorf("foreach task requires a task or chain %w", errdefs.ErrBadRequest)
+	}
+	if cfg.Task != "" && cfg.Chain != nil {
+		return nil, nil, fmt.Errorf("foreach task must set either task or chain, not both %w", errdefs.ErrBadRequest)
+	}
+	items, err := forEachItems(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body TaskDefinition
+	if cfg.Task != "" {
+		target, err := findTaskByID(chain.Tasks, cfg.Task)
+		if err != nil {
+			return nil, nil, fmt.Errorf("foreach task %q: %w", cfg.Task, err)
+		}
+		if target.Handler == HandleParallel || target.Handler == HandleForEach {
+			return nil, nil, fmt.Errorf("foreach task %q: nested fan-out tasks are not supported %w", cfg.Task, errdefs.ErrBadRequest)
+		}
+		body = *target
+	}
+
+	iterCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outputs := make([]any, len(items))
+	steps := make([][]CapturedStateUnit, len(items))
+	errs := make([]error, len(items))
+	var failOnce sync.Once
+
+	var g errgroup.Group
+	if cfg.MaxConcurrency > 0 {
+		g.SetLimit(cfg.MaxConcurrency)
+	}
+	for i, item := range items {
+		g.Go(func() error {
+			if cfg.FailFast && iterCtx.Err() != nil {
+				return nil
+			}
+			if cfg.Chain != nil {
+				sub := *cfg.Chain
+				if sub.TokenLimit == 0 {
+					sub.TokenLimit = chain.TokenLimit
+				}
+				if sub.DefaultExecuteConfig == nil {
+					sub.DefaultExecuteConfig = chain.DefaultExecuteConfig
+				}
+				if sub.SystemInstruction == "" {
+					sub.SystemInstruction = chain.SystemInstruction
+				}
+				sub.Debug = sub.Debug || chain.Debug
+				outputs[i], _, steps[i], errs[i] = env.ExecEnv(withoutCheckpoints(iterCtx), &sub, item, InferDataType(item))
+			} else {
+				// Each iteration gets its own copy of the body and of vars so
+				// templates can refer to {{.item}} and {{.index}} safely.
+				iterTask := body
+				iterVars := maps.Clone(vars)
+				iterVars["item"] = item
+				iterVars["index"] = i
+				res := env.execSubtask(iterCtx, startingTime, chain, chainContext, "foreach_iteration", task.ID, &iterTask, iterVars, item, InferDataType(item))
+				outputs[i], errs[i] = res.output, res.err
+				steps[i] = res.steps
+			}
+			if errs[i] != nil && cfg.FailFast {
+				failOnce.Do(cancel)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var captured []CapturedStateUnit
+	for _, s := range steps {
+		captured = append(captured, s...)
+	}
+	var joined []error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		// Iterations aborted by a fail-fast cancellation are not reported on their own.
+		if cfg.FailFast && errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			continue
+		}
+		joined = append(joined, fmt.Errorf("item %d: %w", i, err))
+	}
+	if len(joined) > 0 {
+		return nil, captured, errors.Join(joined...)
+	}
+	if err := iterCtx.Err(); err != nil && ctx.Err() != nil {
+		return nil, captured, err
+	}
+	return outputs, captured, nil
+}
+
+// forEachItems normalizes a foreach input into a slice. Besides []any it
+// accepts JSON array strings and any value that marshals to a JSON array.
+func forEachItems(input any) ([]any, error) {
+	var raw []byte
+	switch v := input.(type) {
+	case []any:
+		return v, nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("foreach input is not a JSON array: %w", err)
+		}
+		raw = b
+	}
+	var items []any
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("foreach input is not a JSON array %w", errdefs.ErrBadRequest)
+	}
+	return items, nil
+}
@@ -0,0 +1,173 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func forEachChain(cfg *taskengine.ForEachConfig) *taskengine.TaskChainDefinition {
+	end := taskengine.TaskTransition{
+		Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+	}
+	return &taskengine.TaskChainDefinition{
+		ID: "foreach",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "loop", Handler: taskengine.HandleForEach, ForEach: cfg, Transition: end},
+			{ID: "body", Handler: taskengine.HandlePromptToString, PromptTemplate: "{{.index}}:{{.item}}", Transition: end},
+		},
+	}
+}
+
+func TestUnit_ForEach_CollectsOutputsInOrder(t *testing.T) {
+	exec := &branchExecutor{delay: 5 * time.Millisecond}
+	env := setupTestEnv(exec)
+
+	output, outputType, history, err := env.ExecEnv(context.Background(),
+		forEachChain(&taskengine.ForEachConfig{Task: "body"}),
+		`["a","b","c"]`, taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	assert.Equal(t, taskengine.DataTypeJSON, outputType)
+	assert.Equal(t, []any{"0:a", "1:b", "2:c"}, output)
+	require.Len(t, history, 4)
+	assert.Equal(t, "body", history[0].TaskID)
+	assert.Equal(t, "loop", history[3].TaskID)
+}
+
+func TestUnit_ForEach_RespectsMaxConcurrency(t *testing.T) {
+	exec := &branchExecutor{delay: 20 * time.Millisecond}
+	env := setupTestEnv(exec)
+
+	_, _, _, err := env.ExecEnv(context.Background(),
+		forEachChain(&taskengine.ForEachConfig{Task: "body", MaxConcurrency: 2}),
+		[]any{1, 2, 3, 4, 5}, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&exec.peak))
+}
+
+func TestUnit_ForEach_SubChain(t *testing.T) {
+	exec := &branchExecutor{outputs: map[string]any{"double": "twice"}}
+	env := setupTestEnv(exec)
+
+	sub := &taskengine.TaskChainDefinition{
+		ID: "per_item",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "double",
+				Handler: taskengine.HandlePromptToString,
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+	output, _, history, err := env.ExecEnv(context.Background(),
+		forEachChain(&taskengine.ForEachConfig{Chain: sub}),
+		[]any{"x", "y"}, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"twice", "twice"}, output)
+	assert.Len(t, history, 3)
+}
+
+func TestUnit_ForEach_Errors(t *testing.T) {
+	t.Run("joins errors by default", func(t *testing.T) {
+		exec := &taskengine.MockTaskExecutor{ErrorSequence: []error{errors.New("boom")}}
+		env := setupTestEnv(exec)
+
+		_, _, _, err := env.ExecEnv(context.Background(),
+			forEachChain(&taskengine.ForEachConfig{Task: "body", MaxConcurrency: 1}),
+			[]any{"a", "b"}, taskengine.DataTypeJSON)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "item 0: boom")
+		assert.Contains(t, err.Error(), "item 1: boom")
+	})
+
+	t.Run("fail fast skips remaining items", func(t *testing.T) {
+		exec := &taskengine.MockTaskExecutor{ErrorSequence: []error{errors.New("boom"), nil}}
+		env := setupTestEnv(exec)
+
+		_, _, history, err := env.ExecEnv(context.Background(),
+			forEachChain(&taskengine.ForEachConfig{Task: "body", MaxConcurrency: 1, FailFast: true}),
+			[]any{"a", "b", "c"}, taskengine.DataTypeJSON)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "item 0: boom")
+		assert.NotContains(t, err.Error(), "item 1")
+		// Only the failed iteration and the foreach task itself are recorded.
+		assert.Len(t, history, 2)
+	})
+
+	t.Run("rejects non-array input", func(t *testing.T) {
+		env := setupTestEnv(&branchExecutor{})
+
+		_, _, _, err := env.ExecEnv(context.Background(),
+			forEachChain(&taskengine.ForEachConfig{Task: "body"}),
+			"not an array", taskengine.DataTypeString)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a JSON array")
+	})
+}
+
+// firstAttemptFailsExecutor fails the first attempt for every input and
+// echoes the input on the next one.
+type firstAttemptFailsExecutor struct {
+	mu   sync.Mutex
+	seen map[any]bool
+}
+
+func (f *firstAttemptFailsExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, _ *taskengine.TaskDefinition, input any, dataType taskengine.DataType) (any, taskengine.DataType, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.seen[input] {
+		if f.seen == nil {
+			f.seen = map[any]bool{}
+		}
+		f.seen[input] = true
+		return nil, taskengine.DataTypeAny, "", errors.New("flaky")
+	}
+	return input, dataType, "ok", nil
+}
+
+func TestUnit_ForEach_IterationsRunLikeTasks(t *testing.T) {
+	t.Run("retries", func(t *testing.T) {
+		chain := forEachChain(&taskengine.ForEachConfig{Task: "body"})
+		chain.Tasks[1].Retry = &taskengine.TaskRetry{MaxAttempts: 2}
+
+		output, _, history, err := setupTestEnv(&firstAttemptFailsExecutor{}).ExecEnv(context.Background(), chain, `["a","b"]`, taskengine.DataTypeString)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"0:a", "1:b"}, output)
+		require.Len(t, history, 5)
+		assert.Equal(t, 1, history[0].Attempt)
+		assert.NotEmpty(t, history[0].Error.Error)
+		assert.Equal(t, 2, history[1].Attempt)
+		assert.Equal(t, 2, history[1].MaxAttempts)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		chain := forEachChain(&taskengine.ForEachConfig{Task: "body"})
+		chain.Tasks[1].Timeout = "20ms"
+
+		env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"body": time.Second}})
+		_, _, _, err := env.ExecEnv(context.Background(), chain, `["a"]`, taskengine.DataTypeString)
+		require.ErrorIs(t, err, taskengine.ErrTaskTimeout)
+	})
+
+	t.Run("guardrails", func(t *testing.T) {
+		ctx := taskengine.WithGuardrails(context.Background(), taskengine.NewPIIGuardrail())
+
+		exec := &branchExecutor{outputs: map[string]any{"body": "mail jane.doe@example.com"}}
+		output, _, history, err := setupTestEnv(exec).ExecEnv(ctx, forEachChain(&taskengine.ForEachConfig{Task: "body"}), `["a"]`, taskengine.DataTypeString)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"mail [REDACTED:email]"}, output)
+		require.Len(t, history, 2)
+		require.Len(t, history[0].Guardrails, 1)
+		assert.Equal(t, taskengine.GuardrailOutput, history[0].Guardrails[0].Stage)
+	})
+}
@@ -0,0 +1,214 @@
+package taskengine
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// Glossary modes accepted by GlossaryConfig.Mode.
+const (
+	// GlossaryCorrect replaces variants and miscased terms with the preferred
+	// term; only banned terms count as violations.
+	GlossaryCorrect = "correct"
+	// GlossaryCheck leaves the text unchanged and reports every rule it breaks.
+	GlossaryCheck = "check"
+)
+
+// TransitionGlossaryViolation is the transition value of a glossary task whose
+// text still breaks the glossary. Chains branch on it to a revision task.
+const TransitionGlossaryViolation = "glossary_violation"
+
+// Glossary rules reported in GlossaryViolation.Rule.
+const (
+	glossaryRuleVariant = "variant"
+	glossaryRuleCasing  = "casing"
+	glossaryRuleBanned  = "banned"
+)
+
+// GlossaryViolation is one occurrence of text breaking a glossary rule.
+type GlossaryViolation struct {
+	// Found is the text as it appears in the checked output.
+	Found string `json:"found" example:"e-mail"`
+	// Term is the preferred term; empty for banned terms.
+	Term string `json:"term,omitempty" example:"email"`
+	// Rule is "variant", "casing" or "banned".
+	Rule string `json:"rule" example:"variant"`
+}
+
+func (v GlossaryViolation) String() string {
+	switch v.Rule {
+	case glossaryRuleBanned:
+		return fmt.Sprintf("banned term %q", v.Found)
+	case glossaryRuleCasing:
+		return fmt.Sprintf("%q should be written %q", v.Found, v.Term)
+	default:
+		return fmt.Sprintf("%q should be %q", v.Found, v.Term)
+	}
+}
+
+// glossaryEntry is what a matched phrase (lowercased) maps to.
+type glossaryEntry struct {
+	term       string
+	rule       string
+	ignoreCase bool
+}
+
+// glossary is a compiled GlossaryConfig.
+type glossary struct {
+	check   bool
+	pattern *regexp.Regexp
+	entries map[string]glossaryEntry
+}
+
+// compileGlossary validates cfg and builds the matcher for its terms.
+func compileGlossary(cfg *GlossaryConfig) (*glossary, error) {
+	if cfg == nil || (len(cfg.Terms) == 0 && len(cfg.Banned) == 0) {
+		return nil, fmt.Errorf("glossary task requires glossary terms or banned terms %w", errdefs.ErrBadRequest)
+	}
+	g := &glossary{entries: map[string]glossaryEntry{}}
+	switch strings.ToLower(cfg.Mode) {
+	case "", GlossaryCorrect:
+	case GlossaryCheck:
+		g.check = true
+	default:
+		return nil, fmt.Errorf("unknown glossary mode %q %w", cfg.Mode, errdefs.ErrBadRequest)
+	}
+	add := func(phrase string, entry glossaryEntry) error {
+		phrase = strings.TrimSpace(phrase)
+		if phrase == "" {
+			return fmt.Errorf("glossary contains an empty term %w", errdefs.ErrBadRequest)
+		}
+		key := strings.ToLower(phrase)
+		if _, dup := g.entries[key]; dup {
+			return fmt.Errorf("glossary lists %q more than once %w", phrase, errdefs.ErrBadRequest)
+		}
+		g.entries[key] = entry
+		return nil
+	}
+	for _, t := range cfg.Terms {
+		if err := add(t.Term, glossaryEntry{term: t.Term, rule: glossaryRuleCasing, ignoreCase: t.IgnoreCase}); err != nil {
+			return nil, err
+		}
+		for _, variant := range t.Variants {
+			if err := add(variant, glossaryEntry{term: t.Term, rule: glossaryRuleVariant}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, banned := range cfg.Banned {
+		if err := add(banned, glossaryEntry{rule: glossaryRuleBanned}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Longest phrases first so "pay pal account" wins over "pay pal".
+	phrases := make([]string, 0, len(g.entries))
+	for key := range g.entries {
+		phrases = append(phrases, regexp.QuoteMeta(key))
+	}
+	slices.SortFunc(phrases, func(a, b string) int {
+		if d := len(b) - len(a); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	g.pattern = regexp.MustCompile(`(?i)` + strings.Join(phrases, "|"))
+	return g, nil
+}
+
+// apply checks text and returns it with corrections applied (unless in check
+// mode) together with the violations that remain.
+func (g *glossary) apply(text string) (string, []GlossaryViolation) {
+	var (
+		out        strings.Builder
+		violations []GlossaryViolation
+		last       int
+	)
+	for _, loc := range g.pattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if !wordBoundary(text, start, end) {
+			continue
+		}
+		found := text[start:end]
+		entry := g.entries[strings.ToLower(found)]
+		if entry.rule == glossaryRuleCasing && (entry.ignoreCase || found == entry.term) {
+			continue
+		}
+		v := GlossaryViolation{Found: found, Term: entry.term, Rule: entry.rule}
+		if g.check || entry.rule == glossaryRuleBanned {
+			violations = append(violations, v)
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(entry.term)
+		last = end
+	}
+	out.WriteString(text[last:])
+	return out.String(), violations
+}
+
+// wordBoundary reports whether text[start:end] is not part of a longer word.
+// Edges that are not letters or digits, as in "C++", need no boundary.
+func wordBoundary(text string, start, end int) bool {
+	isWord := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	first, _ := utf8.DecodeRuneInString(text[start:])
+	lastRune, _ := utf8.DecodeLastRuneInString(text[:end])
+	if isWord(first) && start > 0 {
+		if before, _ := utf8.DecodeLastRuneInString(text[:start]); isWord(before) {
+			return false
+		}
+	}
+	if isWord(lastRune) && end < len(text) {
+		if after, _ := utf8.DecodeRuneInString(text[end:]); isWord(after) {
+			return false
+		}
+	}
+	return true
+}
+
+// enforceGlossary runs a glossary task on input, a string or the last
+// assistant message of a chat history, and returns the corrected input of the
+// same type, the transition value ("ok" or TransitionGlossaryViolation) and
+// the remaining violations.
+func enforceGlossary(cfg *GlossaryConfig, input any) (any, DataType, string, []GlossaryViolation, error) {
+	g, err := compileGlossary(cfg)
+	if err != nil {
+		return nil, DataTypeAny, "", nil, err
+	}
+	var (
+		output     any
+		outputType DataType
+		violations []GlossaryViolation
+	)
+	switch v := input.(type) {
+	case string:
+		output, violations = g.apply(v)
+		outputType = DataTypeString
+	case ChatHistory:
+		idx := -1
+		for i := len(v.Messages) - 1; i >= 0 && idx < 0; i-- {
+			if v.Messages[i].Role == "assistant" {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return nil, DataTypeAny, "", nil, fmt.Errorf("glossary: chat history has no assistant message")
+		}
+		hist := v
+		hist.Messages = slices.Clone(v.Messages)
+		hist.Messages[idx].Content, violations = g.apply(hist.Messages[idx].Content)
+		output, outputType = hist, DataTypeChatHistory
+	default:
+		return nil, DataTypeAny, "", nil, fmt.Errorf("glossary: unsupported input type %T", input)
+	}
+	if len(violations) > 0 {
+		return output, outputType, TransitionGlossaryViolation, violations, nil
+	}
+	return output, outputType, "ok", nil, nil
+}
@@ -0,0 +1,119 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func glossaryTask(mode string) *taskengine.TaskDefinition {
+	return &taskengine.TaskDefinition{
+		ID:      "glossary",
+		Handler: taskengine.HandleGlossary,
+		Glossary: &taskengine.GlossaryConfig{
+			Mode: mode,
+			Terms: []taskengine.GlossaryTerm{
+				{Term: "Contenox"},
+				{Term: "email", Variants: []string{"e-mail"}},
+				{Term: "sign in", Variants: []string{"log in", "login"}, IgnoreCase: true},
+			},
+			Banned: []string{"guarantee"},
+		},
+	}
+}
+
+func TestUnit_Glossary_CorrectsVariantsAndCasing(t *testing.T) {
+	out, dt, transition, err := vectorExec(t, glossaryTask(""),
+		"Log in to CONTENOX and check your E-Mail. Contenox is ready; contenoxes are not.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "sign in to Contenox and check your email. Contenox is ready; contenoxes are not.", out)
+	assert.Equal(t, taskengine.DataTypeString, dt)
+	assert.Equal(t, "ok", transition)
+}
+
+func TestUnit_Glossary_BannedTermIsViolation(t *testing.T) {
+	out, _, transition, err := vectorExec(t, glossaryTask(taskengine.GlossaryCorrect),
+		"We Guarantee delivery by e-mail.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "We Guarantee delivery by email.", out, "variants are corrected even when a banned term remains")
+	assert.Equal(t, taskengine.TransitionGlossaryViolation, transition)
+}
+
+func TestUnit_Glossary_CheckModeLeavesTextUnchanged(t *testing.T) {
+	in := "Send an e-mail."
+	out, _, transition, err := vectorExec(t, glossaryTask(taskengine.GlossaryCheck), in, taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+	assert.Equal(t, taskengine.TransitionGlossaryViolation, transition)
+
+	_, _, transition, err = vectorExec(t, glossaryTask(taskengine.GlossaryCheck), "Send an email from Contenox.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", transition)
+}
+
+func TestUnit_Glossary_ChatHistoryCorrectsLastAssistantMessage(t *testing.T) {
+	hist := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "how do I log in?"},
+		{Role: "assistant", Content: "Use the login button."},
+	}}
+	out, dt, transition, err := vectorExec(t, glossaryTask(""), hist, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.DataTypeChatHistory, dt)
+	assert.Equal(t, "ok", transition)
+	got := out.(taskengine.ChatHistory)
+	assert.Equal(t, "how do I log in?", got.Messages[0].Content, "user messages are not rewritten")
+	assert.Equal(t, "Use the sign in button.", got.Messages[1].Content)
+	assert.Equal(t, "Use the login button.", hist.Messages[1].Content, "the input history is not modified")
+}
+
+func TestUnit_Glossary_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]*taskengine.GlossaryConfig{
+		"missing":   nil,
+		"empty":     {},
+		"bad mode":  {Mode: "fix", Banned: []string{"x"}},
+		"duplicate": {Terms: []taskengine.GlossaryTerm{{Term: "email", Variants: []string{"Email"}}}},
+	} {
+		task := glossaryTask("")
+		task.Glossary = cfg
+		_, _, _, err := vectorExec(t, task, "text", taskengine.DataTypeString)
+		require.Error(t, err, name)
+	}
+}
+
+func TestGlossary_ViolationBranchesToRevision(t *testing.T) {
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env := setupTestEnv(exec)
+	glossary := glossaryTask("")
+	glossary.Transition = taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+		{Operator: taskengine.OpEquals, When: taskengine.TransitionGlossaryViolation, Goto: "revise"},
+		endBranch(),
+	}}
+	chain := &taskengine.TaskChainDefinition{
+		ID: "glossary-branch",
+		Tasks: []taskengine.TaskDefinition{
+			*glossary,
+			{
+				ID:         "revise",
+				Handler:    taskengine.HandleNoop,
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+			},
+		},
+	}
+
+	_, _, history, err := env.ExecEnv(context.Background(), chain, "Write an e-mail.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	out, _, history, err := env.ExecEnv(context.Background(), chain, "We guarantee it.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "We guarantee it.", out)
+	require.Len(t, history, 2)
+	assert.Equal(t, taskengine.TransitionGlossaryViolation, history[0].Transition)
+	assert.Equal(t, "revise", history[1].TaskID)
+}
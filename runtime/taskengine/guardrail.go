@@ -0,0 +1,311 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrGuardrailRejected is the task error of a task whose input or output was
+// rejected by a guardrail. Rejected tasks are not retried.
+var ErrGuardrailRejected = errors.New("rejected by guardrail")
+
+// GuardrailStage is the hook point a guardrail runs at.
+type GuardrailStage string
+
+const (
+	// GuardrailInput checks the input of a task before it runs.
+	GuardrailInput GuardrailStage = "input"
+	// GuardrailOutput checks the output of a task after it succeeded.
+	GuardrailOutput GuardrailStage = "output"
+)
+
+// GuardrailAction is the outcome of a guardrail check.
+type GuardrailAction string
+
+const (
+	GuardrailAllow  GuardrailAction = "allow"
+	GuardrailRedact GuardrailAction = "redact"
+	GuardrailReject GuardrailAction = "reject"
+)
+
+// GuardrailResult is returned by Guardrail.Check.
+type GuardrailResult struct {
+	Action GuardrailAction
+	Reason string
+	// Content replaces the checked content when Action is GuardrailRedact.
+	Content any
+}
+
+// GuardrailVerdict records a redact or reject decision in the
+// CapturedStateUnit of the task it was made for.
+type GuardrailVerdict struct {
+	Guardrail string          `json:"guardrail" example:"pii"`
+	Stage     GuardrailStage  `json:"stage" example:"input"`
+	Action    GuardrailAction `json:"action" example:"redact"`
+	Reason    string          `json:"reason,omitempty" example:"email address"`
+}
+
+// Guardrail moderates the content flowing into and out of tasks, e.g. with
+// regular expressions, PII detection or an LLM-based classifier.
+//
+// Check is called with the input of every task before it runs and with its
+// output after it succeeded. An error fails the task, so a guardrail that
+// cannot decide blocks the content.
+type Guardrail interface {
+	Name() string
+	Check(ctx context.Context, stage GuardrailStage, task *TaskDefinition, content any, dataType DataType) (GuardrailResult, error)
+}
+
+type guardrailsKey struct{}
+
+// WithGuardrails attaches guardrails to ctx. ExecEnv runs them in order at
+// both stages of every task; a redaction is seen by the guardrails after it.
+// Environments created by NewEnv with the returned context run them for every
+// execution, before the guardrails of the execution's own context.
+func WithGuardrails(ctx context.Context, guardrails ...Guardrail) context.Context {
+	if len(guardrails) == 0 {
+		return ctx
+	}
+	existing := guardrailsFromContext(ctx)
+	all := make([]Guardrail, 0, len(existing)+len(guardrails))
+	all = append(append(all, existing...), guardrails...)
+	return context.WithValue(ctx, guardrailsKey{}, all)
+}
+
+func guardrailsFromContext(ctx context.Context) []Guardrail {
+	guardrails, _ := ctx.Value(guardrailsKey{}).([]Guardrail)
+	return guardrails
+}
+
+// runGuardrails checks content with envGuardrails and then the guardrails of
+// ctx, and returns the possibly redacted content and the verdicts of
+// guardrails that did not allow it. A rejection is returned as an error
+// wrapping ErrGuardrailRejected.
+func runGuardrails(ctx context.Context, envGuardrails []Guardrail, stage GuardrailStage, task *TaskDefinition, content any, dataType DataType) (any, []GuardrailVerdict, error) {
+	var verdicts []GuardrailVerdict
+	for _, g := range append(slices.Clip(envGuardrails), guardrailsFromContext(ctx)...) {
+		res, err := g.Check(ctx, stage, task, content, dataType)
+		if err != nil {
+			return content, verdicts, fmt.Errorf("guardrail %s: %w", g.Name(), err)
+		}
+		switch res.Action {
+		case GuardrailAllow, "":
+			continue
+		case GuardrailRedact:
+			content = res.Content
+		case GuardrailReject:
+		default:
+			return content, verdicts, fmt.Errorf("guardrail %s: unknown action %q", g.Name(), res.Action)
+		}
+		verdicts = append(verdicts, GuardrailVerdict{Guardrail: g.Name(), Stage: stage, Action: res.Action, Reason: res.Reason})
+		if res.Action == GuardrailReject {
+			return content, verdicts, fmt.Errorf("%s %w %s: %s", stage, ErrGuardrailRejected, g.Name(), res.Reason)
+		}
+	}
+	return content, verdicts, nil
+}
+
+// GuardrailFunc adapts a function to a Guardrail, e.g. to wrap an LLM-based
+// classifier.
+type GuardrailFunc struct {
+	GuardrailName string
+	Fn            func(ctx context.Context, stage GuardrailStage, task *TaskDefinition, content any, dataType DataType) (GuardrailResult, error)
+}
+
+func (g GuardrailFunc) Name() string { return g.GuardrailName }
+
+func (g GuardrailFunc) Check(ctx context.Context, stage GuardrailStage, task *TaskDefinition, content any, dataType DataType) (GuardrailResult, error) {
+	return g.Fn(ctx, stage, task, content, dataType)
+}
+
+// RegexGuardrail rejects or redacts text matching any of its patterns.
+// Strings, the messages of chat histories and the strings of JSON values are
+// checked; other content is allowed.
+type RegexGuardrail struct {
+	name     string
+	action   GuardrailAction
+	patterns []namedPattern
+	// Replacement substitutes matches when redacting. When empty, matches are
+	// replaced with "[REDACTED:<pattern>]".
+	Replacement string
+}
+
+type namedPattern struct {
+	label string
+	re    *regexp.Regexp
+	// valid, if set, confirms a match before it counts.
+	valid func(match string) bool
+}
+
+// NewRegexGuardrail compiles patterns into a guardrail that applies action
+// (GuardrailReject or GuardrailRedact) to matching text.
+func NewRegexGuardrail(name string, action GuardrailAction, patterns ...string) (*RegexGuardrail, error) {
+	if action != GuardrailReject && action != GuardrailRedact {
+		return nil, fmt.Errorf("guardrail %s: action must be %q or %q", name, GuardrailReject, GuardrailRedact)
+	}
+	g := &RegexGuardrail{name: name, action: action, Replacement: "[REDACTED]"}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("guardrail %s: %w", name, err)
+		}
+		g.patterns = append(g.patterns, namedPattern{label: p, re: re})
+	}
+	return g, nil
+}
+
+// NewPIIGuardrail returns a guardrail redacting email addresses, phone
+// numbers, credit card numbers, IBANs and US social security numbers. Each
+// match is replaced with "[REDACTED:<kind>]". Card numbers must pass the Luhn
+// check, so timestamps and other long numbers are left alone.
+func NewPIIGuardrail() *RegexGuardrail {
+	return &RegexGuardrail{
+		name:   "pii",
+		action: GuardrailRedact,
+		patterns: []namedPattern{
+			{label: "email", re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+			{label: "iban", re: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){3,7}(?: ?[A-Z0-9]{1,3})?\b`)},
+			{label: "credit_card", re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhnValid},
+			{label: "ssn", re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+			{label: "phone", re: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b`)},
+		},
+	}
+}
+
+func (g *RegexGuardrail) Name() string { return g.name }
+
+func (g *RegexGuardrail) Check(_ context.Context, _ GuardrailStage, _ *TaskDefinition, content any, dataType DataType) (GuardrailResult, error) {
+	var found []string
+	redacted, ok := mapGuardedText(content, dataType, func(text string) string {
+		for _, p := range g.patterns {
+			replacement := g.Replacement
+			if replacement == "" {
+				replacement = "[REDACTED:" + p.label + "]"
+			}
+			matched := false
+			replaced := p.re.ReplaceAllStringFunc(text, func(match string) string {
+				if p.valid != nil && !p.valid(match) {
+					return match
+				}
+				matched = true
+				return replacement
+			})
+			if !matched {
+				continue
+			}
+			if !slices.Contains(found, p.label) {
+				found = append(found, p.label)
+			}
+			if g.action == GuardrailRedact {
+				text = replaced
+			}
+		}
+		return text
+	})
+	if !ok || len(found) == 0 {
+		return GuardrailResult{Action: GuardrailAllow}, nil
+	}
+	res := GuardrailResult{Action: g.action, Reason: "matched " + strings.Join(found, ", ")}
+	if g.action == GuardrailRedact {
+		res.Content = redacted
+	}
+	return res, nil
+}
+
+// mapGuardedText applies fn to the text of content: strings, the messages of
+// chat histories and every string in a JSON value. It reports false for
+// content that carries no text.
+func mapGuardedText(content any, dataType DataType, fn func(string) string) (any, bool) {
+	switch dataType {
+	case DataTypeString:
+		s, ok := content.(string)
+		if !ok {
+			return content, false
+		}
+		return fn(s), true
+	case DataTypeChatHistory:
+		history, ok := content.(ChatHistory)
+		if !ok {
+			return content, false
+		}
+		messages := make([]Message, len(history.Messages))
+		for i, m := range history.Messages {
+			m.Content = fn(m.Content)
+			messages[i] = m
+		}
+		history.Messages = messages
+		return history, true
+	case DataTypeJSON, DataTypeAny:
+		return mapJSONText(content, fn)
+	}
+	return content, false
+}
+
+// mapJSONText applies fn to every string in v, walking maps and slices. Other
+// values are walked in their JSON form, e.g. the chat histories in the merged
+// output of a parallel task.
+func mapJSONText(v any, fn func(string) string) (any, bool) {
+	switch v := v.(type) {
+	case nil, bool, float64, float32, int, int64, json.Number, []float64, []float32, []int:
+		return v, false
+	case string:
+		return fn(v), true
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		found := false
+		for k, e := range v {
+			var ok bool
+			out[k], ok = mapJSONText(e, fn)
+			found = found || ok
+		}
+		return out, found
+	case []any:
+		out := make([]any, len(v))
+		found := false
+		for i, e := range v {
+			var ok bool
+			out[i], ok = mapJSONText(e, fn)
+			found = found || ok
+		}
+		return out, found
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v, false
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return v, false
+	}
+	switch generic.(type) {
+	case string, map[string]any, []any:
+		return mapJSONText(generic, fn)
+	}
+	return v, false
+}
+
+// luhnValid reports whether the digits of match pass the Luhn checksum.
+func luhnValid(match string) bool {
+	sum, double := 0, false
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
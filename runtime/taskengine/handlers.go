@@ -0,0 +1,64 @@
+package taskengine
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// HandlerFunc runs a task with a handler registered through RegisterHandler.
+// It receives the task, whose Config carries the handler's settings, and the
+// task input, and returns the output, its type and the value transitions are
+// evaluated against. Returning an error fails the task like a built-in
+// handler's error does, including retries and on_failure.
+type HandlerFunc func(ctx context.Context, task *TaskDefinition, input any, dataType DataType) (any, DataType, string, error)
+
+var (
+	handlerRegistryMu sync.RWMutex
+	handlerRegistry   = map[TaskHandler]HandlerFunc{}
+)
+
+// RegisterHandler makes fn run every task whose handler is name, so programs
+// embedding the engine can add handlers of their own. Call it from init() or
+// before chains are executed or validated. It panics when name is empty,
+// fn is nil, or name is a built-in handler or already registered.
+func RegisterHandler(name TaskHandler, fn HandlerFunc) {
+	if name == "" {
+		panic("taskengine: handler name cannot be empty")
+	}
+	if fn == nil {
+		panic("taskengine: handler func cannot be nil")
+	}
+	if slices.Contains(knownHandlers, name) {
+		panic("taskengine: cannot register built-in handler " + string(name))
+	}
+
+	handlerRegistryMu.Lock()
+	defer handlerRegistryMu.Unlock()
+	if _, exists := handlerRegistry[name]; exists {
+		panic("taskengine: handler already registered: " + string(name))
+	}
+	handlerRegistry[name] = fn
+}
+
+// registeredHandler returns the func registered for name.
+func registeredHandler(name TaskHandler) (HandlerFunc, bool) {
+	handlerRegistryMu.RLock()
+	defer handlerRegistryMu.RUnlock()
+	fn, ok := handlerRegistry[name]
+	return fn, ok
+}
+
+// RegisteredHandlers returns the names of the handlers added with
+// RegisterHandler, sorted.
+func RegisteredHandlers() []TaskHandler {
+	handlerRegistryMu.RLock()
+	defer handlerRegistryMu.RUnlock()
+	names := make([]TaskHandler, 0, len(handlerRegistry))
+	for name := range handlerRegistry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,63 @@
+package taskengine_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const handleShout taskengine.TaskHandler = "test_shout"
+
+func init() {
+	taskengine.RegisterHandler(handleShout, func(_ context.Context, task *taskengine.TaskDefinition, input any, _ taskengine.DataType) (any, taskengine.DataType, string, error) {
+		s, ok := input.(string)
+		if !ok {
+			return nil, taskengine.DataTypeAny, "", fmt.Errorf("shout expects a string, got %T", input)
+		}
+		out := strings.ToUpper(s) + fmt.Sprint(task.Config["suffix"])
+		return out, taskengine.DataTypeString, "shouted", nil
+	})
+}
+
+func TestRegisterHandler_RunsCustomHandler(t *testing.T) {
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	chain := &taskengine.TaskChainDefinition{
+		ID: "custom",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:      "shout",
+			Handler: handleShout,
+			Config:  map[string]any{"suffix": "!"},
+			Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpEquals, When: "shouted", Goto: taskengine.TermEnd}},
+			},
+		}},
+	}
+	require.NoError(t, taskengine.ValidateChain(chain).Err())
+
+	out, outType, _, err := setupTestEnv(exec).ExecEnv(context.Background(), chain, "hello", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO!", out)
+	assert.Equal(t, taskengine.DataTypeString, outType)
+
+	_, _, _, err = setupTestEnv(exec).ExecEnv(context.Background(), chain, 42, taskengine.DataTypeInt)
+	require.ErrorContains(t, err, "shout expects a string")
+}
+
+func TestRegisterHandler_Rejects(t *testing.T) {
+	noop := func(context.Context, *taskengine.TaskDefinition, any, taskengine.DataType) (any, taskengine.DataType, string, error) {
+		return nil, taskengine.DataTypeAny, "", nil
+	}
+	assert.Panics(t, func() { taskengine.RegisterHandler(taskengine.HandleNoop, noop) })
+	assert.Panics(t, func() { taskengine.RegisterHandler(handleShout, noop) })
+	assert.Panics(t, func() { taskengine.RegisterHandler("", noop) })
+	assert.Panics(t, func() { taskengine.RegisterHandler("test_nil", nil) })
+	assert.Contains(t, taskengine.RegisteredHandlers(), handleShout)
+}
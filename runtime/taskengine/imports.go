@@ -0,0 +1,216 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// ChainImport merges the tasks of another chain into the importing chain.
+// example:
+//
+//	imports:
+//	  - chain: tool-setup.yaml
+//	    as: setup
+//	    then: answer
+//	  - chain: error-handlers
+//	    as: errors
+type ChainImport struct {
+	// Chain is the imported chain: a chain file, relative to the importing
+	// chain, or a chain ID, depending on the host's ChainLoader.
+	Chain string `yaml:"chain" json:"chain" example:"tool-setup.yaml"`
+
+	// As prefixes the IDs of the imported tasks with "<as>_", including all
+	// references between them and the {{.task_id}} and {{has "task_id"}}
+	// references to their outputs in prompts, print and tools args, so
+	// imports cannot collide. Empty keeps the IDs as they are.
+	As string `yaml:"as,omitempty" json:"as,omitempty" example:"setup"`
+
+	// Then makes the import a preamble: its tasks run before the tasks of
+	// the importing chain, and their transitions to "end" continue with the
+	// task Then instead. Without Then the imported tasks are only reached
+	// through transitions of the importing chain.
+	Then string `yaml:"then,omitempty" json:"then,omitempty" example:"answer"`
+}
+
+// ChainLoader returns the chain a ChainImport refers to.
+type ChainLoader func(ctx context.Context, ref string) (*TaskChainDefinition, error)
+
+// ResolveImports returns chain with its imports merged in; chains without
+// imports are returned as they are. Imports are resolved recursively.
+//
+// Preamble imports (see ChainImport.Then) are placed first, in import order,
+// followed by the tasks of chain and then the remaining imports. A task of
+// chain whose ID equals the (prefixed) ID of an imported task overrides it in
+// place. Only tasks are imported; the other settings of imported chains are
+// ignored.
+func ResolveImports(ctx context.Context, chain *TaskChainDefinition, load ChainLoader) (*TaskChainDefinition, error) {
+	return resolveImports(ctx, chain, load, nil)
+}
+
+func resolveImports(ctx context.Context, chain *TaskChainDefinition, load ChainLoader, stack []string) (*TaskChainDefinition, error) {
+	if chain == nil || len(chain.Imports) == 0 {
+		return chain, nil
+	}
+	if load == nil {
+		return nil, fmt.Errorf("chain %s: imports are not supported here", chain.ID)
+	}
+
+	var preamble, library []TaskDefinition
+	imported := map[string]string{}
+	for i, imp := range chain.Imports {
+		if imp.Chain == "" {
+			return nil, fmt.Errorf("chain %s: imports[%d]: chain is required", chain.ID, i)
+		}
+		if slices.Contains(stack, imp.Chain) {
+			return nil, fmt.Errorf("chain %s: import cycle through %q", chain.ID, imp.Chain)
+		}
+		base, err := load(ctx, imp.Chain)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: import %q: %w", chain.ID, imp.Chain, err)
+		}
+		base, err = resolveImports(ctx, base, load, append(stack, imp.Chain))
+		if err != nil {
+			return nil, err
+		}
+		tasks := namespaceTasks(base.Tasks, imp.As, imp.Then)
+		for _, task := range tasks {
+			if from, dup := imported[task.ID]; dup {
+				return nil, fmt.Errorf("chain %s: task %q is imported from both %q and %q; set a distinct 'as'", chain.ID, task.ID, from, imp.Chain)
+			}
+			imported[task.ID] = imp.Chain
+		}
+		if imp.Then != "" {
+			preamble = append(preamble, tasks...)
+		} else {
+			library = append(library, tasks...)
+		}
+	}
+
+	own := make(map[string]int, len(chain.Tasks))
+	for i, task := range chain.Tasks {
+		own[task.ID] = i
+	}
+	overridden := map[string]bool{}
+	override := func(tasks []TaskDefinition) []TaskDefinition {
+		for i, task := range tasks {
+			if j, ok := own[task.ID]; ok {
+				tasks[i] = chain.Tasks[j]
+				overridden[task.ID] = true
+			}
+		}
+		return tasks
+	}
+	preamble, library = override(preamble), override(library)
+
+	merged := *chain
+	merged.Imports = nil
+	merged.Tasks = preamble
+	for _, task := range chain.Tasks {
+		if !overridden[task.ID] {
+			merged.Tasks = append(merged.Tasks, task)
+		}
+	}
+	merged.Tasks = append(merged.Tasks, library...)
+	return &merged, nil
+}
+
+// namespaceTasks copies tasks with their IDs and references prefixed with
+// "<prefix>_", and their transitions to the end of the chain redirected to
+// then if set. Template references to task outputs are prefixed as well.
+func namespaceTasks(tasks []TaskDefinition, prefix, then string) []TaskDefinition {
+	ids := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		ids[task.ID] = true
+	}
+	ref := func(id string) string {
+		if prefix != "" && ids[id] {
+			return prefix + "_" + id
+		}
+		return id
+	}
+	next := func(id string) string {
+		if then != "" && (id == "" || id == TermEnd) {
+			return then
+		}
+		return ref(id)
+	}
+
+	tmpl := func(src string) string {
+		if prefix == "" {
+			return src
+		}
+		return prefixTemplateRefs(src, ref)
+	}
+
+	out := make([]TaskDefinition, len(tasks))
+	for i, task := range tasks {
+		task.ID = ref(task.ID)
+		task.InputVar = ref(task.InputVar)
+		task.PromptTemplate = tmpl(task.PromptTemplate)
+		task.Print = tmpl(task.Print)
+		if task.PromptVariants != nil {
+			task.PromptVariants = slices.Clone(task.PromptVariants)
+			for j := range task.PromptVariants {
+				task.PromptVariants[j].PromptTemplate = tmpl(task.PromptVariants[j].PromptTemplate)
+			}
+		}
+		if task.Tools != nil && prefix != "" {
+			tools := *task.Tools
+			tools.Args = maps.Clone(tools.Args)
+			for k, v := range tools.Args {
+				tools.Args[k] = tmpl(v)
+			}
+			task.Tools = &tools
+		}
+		task.Transition.OnFailure = ref(task.Transition.OnFailure)
+		task.Transition.OnError = ref(task.Transition.OnError)
+		task.Transition.Branches = slices.Clone(task.Transition.Branches)
+		for j := range task.Transition.Branches {
+			b := &task.Transition.Branches[j]
+			b.Goto = next(b.Goto)
+			if b.Compose != nil {
+				compose := *b.Compose
+				compose.WithVar = ref(compose.WithVar)
+				b.Compose = &compose
+			}
+		}
+		if task.Parallel != nil {
+			parallel := *task.Parallel
+			parallel.Branches = make([]string, len(task.Parallel.Branches))
+			for j, id := range task.Parallel.Branches {
+				parallel.Branches[j] = ref(id)
+			}
+			task.Parallel = &parallel
+		}
+		if task.ForEach != nil {
+			forEach := *task.ForEach
+			forEach.Task = ref(forEach.Task)
+			task.ForEach = &forEach
+		}
+		out[i] = task
+	}
+	return out
+}
+
+var (
+	// templateActionRe matches a template action.
+	templateActionRe = regexp.MustCompile(`\{\{.*?\}\}`)
+	// templateFieldRe matches the first field of a variable reference such as
+	// .summary, $.summary or .summary.text, and the first path segment of
+	// has "...".
+	templateFieldRe = regexp.MustCompile(`(^|[^\w.)\]])(\.|has\s+")([A-Za-z_]\w*)`)
+)
+
+// prefixTemplateRefs rewrites the variable references in the actions of the
+// template src with ref.
+func prefixTemplateRefs(src string, ref func(string) string) string {
+	return templateActionRe.ReplaceAllStringFunc(src, func(action string) string {
+		return templateFieldRe.ReplaceAllStringFunc(action, func(m string) string {
+			sub := templateFieldRe.FindStringSubmatch(m)
+			return sub[1] + sub[2] + ref(sub[3])
+		})
+	})
+}
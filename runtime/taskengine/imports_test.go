@@ -0,0 +1,132 @@
+package taskengine_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chainLoader(chains ...*taskengine.TaskChainDefinition) taskengine.ChainLoader {
+	return func(_ context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
+		for _, c := range chains {
+			if c.ID == ref {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown chain %q", ref)
+	}
+}
+
+func gotoBranch(id string) taskengine.TaskTransition {
+	return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}}}
+}
+
+func TestImports_PreambleIsNamespacedAndRunsFirst(t *testing.T) {
+	setup := &taskengine.TaskChainDefinition{
+		ID: "setup",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "tools", Handler: taskengine.HandleNoop, Transition: gotoBranch("greet")},
+			{ID: "greet", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)},
+		},
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID:      "main",
+		Imports: []taskengine.ChainImport{{Chain: "setup", As: "setup", Then: "answer"}},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "answer", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)},
+		},
+	}
+
+	resolved, err := taskengine.ResolveImports(context.Background(), chain, chainLoader(setup))
+	require.NoError(t, err)
+	require.Empty(t, resolved.Imports)
+	require.Len(t, resolved.Tasks, 3)
+	assert.Equal(t, "setup_tools", resolved.Tasks[0].ID)
+	assert.Equal(t, "setup_greet", resolved.Tasks[0].Transition.Branches[0].Goto)
+	assert.Equal(t, "answer", resolved.Tasks[1].Transition.Branches[0].Goto)
+	assert.Equal(t, "answer", resolved.Tasks[2].ID)
+	assert.Equal(t, "greet", setup.Tasks[0].Transition.Branches[0].Goto, "imported chain must not be modified")
+
+	env := setupTestEnv(&slowExecutor{})
+	out, _, _, err := env.ExecEnv(context.Background(), resolved, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "answer:setup_greet:setup_tools:in", out)
+}
+
+func TestImports_OwnTaskOverridesImported(t *testing.T) {
+	base := &taskengine.TaskChainDefinition{
+		ID: "base",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "a", Handler: taskengine.HandleNoop, Transition: gotoBranch("b")},
+			{ID: "b", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)},
+		},
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID:      "main",
+		Imports: []taskengine.ChainImport{{Chain: "base", As: "base", Then: "answer"}},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "answer", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)},
+			{ID: "base_b", Handler: taskengine.HandleRaiseError, Transition: gotoBranch("answer")},
+		},
+	}
+
+	resolved, err := taskengine.ResolveImports(context.Background(), chain, chainLoader(base))
+	require.NoError(t, err)
+	require.Len(t, resolved.Tasks, 3)
+	assert.Equal(t, []string{"base_a", "base_b", "answer"}, []string{resolved.Tasks[0].ID, resolved.Tasks[1].ID, resolved.Tasks[2].ID})
+	assert.Equal(t, taskengine.HandleRaiseError, resolved.Tasks[1].Handler)
+}
+
+func TestImports_Errors(t *testing.T) {
+	loop := &taskengine.TaskChainDefinition{
+		ID:      "loop",
+		Imports: []taskengine.ChainImport{{Chain: "loop"}},
+		Tasks:   []taskengine.TaskDefinition{{ID: "x", Handler: taskengine.HandleNoop}},
+	}
+	lib := &taskengine.TaskChainDefinition{
+		ID:    "lib",
+		Tasks: []taskengine.TaskDefinition{{ID: "x", Handler: taskengine.HandleNoop}},
+	}
+	load := chainLoader(loop, lib)
+
+	_, err := taskengine.ResolveImports(context.Background(), &taskengine.TaskChainDefinition{ID: "main", Imports: []taskengine.ChainImport{{Chain: "loop"}}}, load)
+	assert.ErrorContains(t, err, "import cycle")
+
+	_, err = taskengine.ResolveImports(context.Background(), &taskengine.TaskChainDefinition{ID: "main", Imports: []taskengine.ChainImport{{Chain: "lib"}, {Chain: "lib"}}}, load)
+	assert.ErrorContains(t, err, "distinct 'as'")
+
+	_, err = taskengine.ResolveImports(context.Background(), &taskengine.TaskChainDefinition{ID: "main", Imports: []taskengine.ChainImport{{Chain: "missing"}}}, load)
+	assert.ErrorContains(t, err, "unknown chain")
+}
+
+func TestImports_TemplateRefsAreNamespaced(t *testing.T) {
+	lib := &taskengine.TaskChainDefinition{
+		ID: "lib",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "fetch", Handler: taskengine.HandleNoop, Transition: gotoBranch("sum")},
+			{
+				ID: "sum", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd),
+				PromptTemplate: "Summarize {{.fetch}} for {{.input}} ({{ len .fetch.items }}, {{$.fetch}})",
+				Print:          "{{if has \"fetch\"}}{{.fetch}}{{end}}",
+				Tools:          &taskengine.ToolsCall{Name: "echo", Args: map[string]string{"text": "{{json .fetch}}"}},
+			},
+		},
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID:      "main",
+		Imports: []taskengine.ChainImport{{Chain: "lib", As: "lib"}},
+		Tasks:   []taskengine.TaskDefinition{{ID: "answer", Handler: taskengine.HandleNoop, Transition: gotoBranch("lib_fetch")}},
+	}
+
+	resolved, err := taskengine.ResolveImports(context.Background(), chain, chainLoader(lib))
+	require.NoError(t, err)
+	sum := resolved.Tasks[2]
+	assert.Equal(t, "Summarize {{.lib_fetch}} for {{.input}} ({{ len .lib_fetch.items }}, {{$.lib_fetch}})", sum.PromptTemplate)
+	assert.Equal(t, "{{if has \"lib_fetch\"}}{{.lib_fetch}}{{end}}", sum.Print)
+	assert.Equal(t, "{{json .lib_fetch}}", sum.Tools.Args["text"])
+	assert.Equal(t, "{{json .fetch}}", lib.Tasks[1].Tools.Args["text"], "imported chain must not be modified")
+}
@@ -43,6 +43,30 @@ type CapturedStateUnit struct {
 	Input       string        `json:"input" example:"This is a test input that needs validation"`
 	Output      string        `json:"output" example:"valid"`
 	InputVar    string        `json:"inputVar" example:"input"` // Which variable was used as input
+	Attempt     int           `json:"attempt" example:"2"`      // 1-based attempt number of this execution
+	MaxAttempts int           `json:"maxAttempts" example:"3"`  // Attempts allowed by the task's retry settings
+	// Guardrails lists the redact and reject verdicts of guardrails for this step.
+	Guardrails []GuardrailVerdict `json:"guardrails,omitempty" openapi_include_type:"taskengine.GuardrailVerdict"`
+	// Model, Provider and BackendID identify what served the step's last LLM call.
+	Model     string `json:"model,omitempty" example:"qwen2.5:7b"`
+	Provider  string `json:"provider,omitempty" example:"ollama"`
+	BackendID string `json:"backendID,omitempty" example:"b7d9e1a3-8f0c-4a7d-9b1e-2f3a4b5c6d7e"`
+	// FallbackFrom lists the models that failed with a retryable error before
+	// Model served the call (model_fallback only).
+	FallbackFrom []string `json:"fallbackFrom,omitempty" example:"[\"gpt-4o\"]"`
+	// InputTokens and OutputTokens are the tokens of the step's LLM calls.
+	InputTokens  int `json:"inputTokens,omitempty" example:"1200"`
+	OutputTokens int `json:"outputTokens,omitempty" example:"350"`
+	// Cost is the estimated cost in USD of the step's LLM calls, from the
+	// executor's Pricing. Priced is false when a call used a model without
+	// a price.
+	Cost   float64 `json:"cost,omitempty" example:"0.0065"`
+	Priced bool    `json:"priced,omitempty" example:"true"`
+	// Variant is the goto of the branch a weighted transition picked for the
+	// step ("end" when it ends the chain).
+	Variant string `json:"variant,omitempty" example:"prompt_b"`
+	// PromptVariant is the name of the prompt variant the step sent.
+	PromptVariant string `json:"promptVariant,omitempty" example:"concise"`
 }
 
 type ErrorResponse struct {
@@ -0,0 +1,112 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/itchyny/gojq"
+)
+
+var jqCache sync.Map // query source → *gojq.Code
+
+// compileJQ parses and compiles the query of a jq_transform task, caching the
+// result.
+func compileJQ(cfg *JQConfig) (*gojq.Code, error) {
+	if cfg == nil || strings.TrimSpace(cfg.Query) == "" {
+		return nil, fmt.Errorf("jq_transform task requires jq.query %w", errdefs.ErrBadRequest)
+	}
+	if code, ok := jqCache.Load(cfg.Query); ok {
+		return code.(*gojq.Code), nil
+	}
+	query, err := gojq.Parse(cfg.Query)
+	if err != nil {
+		return nil, fmt.Errorf("jq query %q: %v %w", cfg.Query, err, errdefs.ErrBadRequest)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("jq query %q: %v %w", cfg.Query, err, errdefs.ErrBadRequest)
+	}
+	jqCache.Store(cfg.Query, code)
+	return code, nil
+}
+
+// jqTransform runs the query of cfg on input. It returns the single result of
+// the query, its DataType and the transition value: the result itself for
+// strings, numbers and booleans, and "ok" for objects, arrays and null.
+func jqTransform(ctx context.Context, cfg *JQConfig, input any) (any, DataType, string, error) {
+	code, err := compileJQ(cfg)
+	if err != nil {
+		return nil, DataTypeAny, "", err
+	}
+	value, err := jqInput(input)
+	if err != nil {
+		return nil, DataTypeAny, "", err
+	}
+
+	var results []any
+	iter := code.RunWithContext(ctx, value)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := v.(error); isErr {
+			if haltErr, isHalt := err.(*gojq.HaltError); isHalt && haltErr.Value() == nil {
+				break
+			}
+			return nil, DataTypeAny, "", fmt.Errorf("jq: %w", err)
+		}
+		results = append(results, v)
+	}
+	switch len(results) {
+	case 0:
+		return nil, DataTypeAny, "", fmt.Errorf("jq query %q produced no result", cfg.Query)
+	case 1:
+	default:
+		return nil, DataTypeAny, "", fmt.Errorf("jq query %q produced %d results; wrap it in [...] to collect them into an array", cfg.Query, len(results))
+	}
+
+	switch v := results[0].(type) {
+	case string:
+		return v, DataTypeString, v, nil
+	case int:
+		return v, DataTypeInt, strconv.Itoa(v), nil
+	case float64:
+		return v, DataTypeJSON, strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return v, DataTypeJSON, strconv.FormatBool(v), nil
+	default:
+		// Objects, arrays and null; big integers are normalized through JSON.
+		out, err := convertToJSON(v)
+		if err != nil {
+			return nil, DataTypeAny, "", fmt.Errorf("jq: %w", err)
+		}
+		return out, DataTypeJSON, "ok", nil
+	}
+}
+
+// jqInput converts a task input to the plain JSON values gojq operates on.
+// A string holding JSON is decoded, any other string is passed as is.
+func jqInput(input any) (any, error) {
+	if s, ok := input.(string); ok {
+		var v any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s)), &v); err == nil {
+			return v, nil
+		}
+		return s, nil
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("jq: encode input: %w", err)
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("jq: decode input: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,68 @@
+package taskengine_test
+
+import (
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jqTask(query string) *taskengine.TaskDefinition {
+	return &taskengine.TaskDefinition{
+		ID:      "reshape",
+		Handler: taskengine.HandleJQTransform,
+		JQ:      &taskengine.JQConfig{Query: query},
+	}
+}
+
+func TestUnit_JQTransform_Reshapes(t *testing.T) {
+	input := map[string]any{
+		"user":  map[string]any{"name": "ada"},
+		"items": []any{map[string]any{"tag": "b"}, map[string]any{"tag": "a"}, map[string]any{"tag": "b"}},
+	}
+	tests := []struct {
+		name       string
+		query      string
+		input      any
+		want       any
+		wantType   taskengine.DataType
+		transition string
+	}{
+		{"object", "{name: .user.name, tags: [.items[].tag] | unique}", input,
+			map[string]any{"name": "ada", "tags": []any{"a", "b"}}, taskengine.DataTypeJSON, "ok"},
+		{"string", ".user.name", input, "ada", taskengine.DataTypeString, "ada"},
+		{"int", ".items | length", input, 3, taskengine.DataTypeInt, "3"},
+		{"bool", `any(.items[]; .tag == "a")`, input, true, taskengine.DataTypeJSON, "true"},
+		{"json string input", ".score * 2", `{"score": 0.25}`, 0.5, taskengine.DataTypeJSON, "0.5"},
+		{"chat history", ".messages[-1].content",
+			taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "hi"}}}, "hi", taskengine.DataTypeString, "hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, dt, transition, err := vectorExec(t, jqTask(tt.query), tt.input, taskengine.InferDataType(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+			assert.Equal(t, tt.wantType, dt)
+			assert.Equal(t, tt.transition, transition)
+		})
+	}
+}
+
+func TestUnit_JQTransform_Errors(t *testing.T) {
+	input := map[string]any{"items": []any{1.0, 2.0}}
+	_, _, _, err := vectorExec(t, jqTask(".items[]"), input, taskengine.DataTypeJSON)
+	assert.ErrorContains(t, err, "produced 2 results")
+	_, _, _, err = vectorExec(t, jqTask(".items[] | select(. > 5)"), input, taskengine.DataTypeJSON)
+	assert.ErrorContains(t, err, "produced no result")
+
+	diags := taskengine.ValidateChain(&taskengine.TaskChainDefinition{
+		ID: "bad-jq",
+		Tasks: []taskengine.TaskDefinition{{
+			ID: "reshape", Handler: taskengine.HandleJQTransform, JQ: &taskengine.JQConfig{Query: ".items | map("},
+			Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}}},
+		}},
+	})
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "jq.query", diags[0].Field)
+}
@@ -35,6 +35,9 @@ const (
 	ClassServerError ErrorClass = "server_error"
 	// ClassTimeout is context.DeadlineExceeded or i/o timeout. Retried.
 	ClassTimeout ErrorClass = "timeout"
+	// ClassConnection is a failure to reach the provider (connection refused,
+	// reset, unknown host). Retried.
+	ClassConnection ErrorClass = "connection"
 	// ClassAuth is HTTP 401/403 or "invalid api key". Never retried.
 	ClassAuth ErrorClass = "auth"
 	// ClassCapacity is a context-length / token-overflow error. Never retried;
@@ -50,7 +53,7 @@ const (
 // IsRetryable reports whether an error of class c warrants another attempt.
 func (c ErrorClass) IsRetryable() bool {
 	switch c {
-	case ClassRateLimit, ClassServerError, ClassTimeout:
+	case ClassRateLimit, ClassServerError, ClassTimeout, ClassConnection:
 		return true
 	default:
 		return false
@@ -82,6 +85,8 @@ func ClassifyError(err error) ErrorClass {
 		return ClassServerError
 	case containsAny(s, "i/o timeout", "deadline exceeded", "timed out"):
 		return ClassTimeout
+	case containsAny(s, "connection refused", "connection reset", "no such host", "network is unreachable", "broken pipe", "unexpected eof"):
+		return ClassConnection
 	}
 	return ClassPermanent
 }
@@ -167,6 +172,11 @@ type Outcome struct {
 	UsedFallback   bool
 	LastErrorClass ErrorClass
 	Elapsed        time.Duration
+	// Model is the model id passed to the last call.
+	Model string
+	// FailedModels lists, in order, the candidates DoCandidates gave up on
+	// before Model.
+	FailedModels []string
 }
 
 // Do invokes call with primaryModel, retrying on transient errors per p.
@@ -200,6 +210,7 @@ func Do(ctx context.Context, p RetryPolicy, primaryModel string, call func(model
 		}
 		result, err := call(model)
 		out.Attempts = i
+		out.Model = model
 		if err == nil {
 			out.LastErrorClass = ClassNone
 			out.Elapsed = time.Since(start)
@@ -234,6 +245,36 @@ func Do(ctx context.Context, p RetryPolicy, primaryModel string, call func(model
 	return nil, out, fmt.Errorf("llmretry: exhausted attempts with no error captured")
 }
 
+// DoCandidates tries candidates in order, running [Do] with p for each. It
+// moves on to the next candidate only when the current one failed with a
+// retryable error class (rate limit, server error, timeout, connection);
+// any other error is returned right away. A single fallback model configured
+// in p applies to every candidate.
+//
+// The returned Outcome sums the attempts across candidates and reports the
+// candidate that produced the result (or the last error) in Model, with the
+// candidates that failed before it in FailedModels.
+func DoCandidates(ctx context.Context, p RetryPolicy, candidates []string, call func(modelID string) (any, error)) (any, Outcome, error) {
+	if len(candidates) == 0 {
+		return Do(ctx, p, "", call)
+	}
+	start := time.Now()
+	total := Outcome{}
+	for i, candidate := range candidates {
+		result, out, err := Do(ctx, p, candidate, call)
+		total.Attempts += out.Attempts
+		total.UsedFallback = total.UsedFallback || out.UsedFallback || i > 0
+		total.LastErrorClass = out.LastErrorClass
+		total.Model = out.Model
+		total.Elapsed = time.Since(start)
+		if err == nil || !out.LastErrorClass.IsRetryable() || i == len(candidates)-1 {
+			return result, total, err
+		}
+		total.FailedModels = append(total.FailedModels, out.Model)
+	}
+	return nil, total, fmt.Errorf("llmretry: exhausted candidates with no error captured")
+}
+
 func backoffFor(p RetryPolicy, attempt int, class ErrorClass) time.Duration {
 	base := p.InitialBackoff.D()
 	if base <= 0 {
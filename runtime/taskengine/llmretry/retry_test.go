@@ -25,6 +25,8 @@ func TestClassifyError(t *testing.T) {
 		{"invalid api key", fmt.Errorf("invalid api key supplied"), ClassAuth},
 		{"capacity exceeded", fmt.Errorf("input token count 200000 exceeds context length 128000"), ClassCapacity},
 		{"timeout", fmt.Errorf("Post \"https://api/x\": net/http: request canceled (i/o timeout)"), ClassTimeout},
+		{"connection refused", fmt.Errorf("Post \"http://localhost:11434/api/chat\": dial tcp [::1]:11434: connect: connection refused"), ClassConnection},
+		{"unknown host", fmt.Errorf("dial tcp: lookup gpu-1: no such host"), ClassConnection},
 		{"unknown", fmt.Errorf("totally unexpected provider error"), ClassPermanent},
 	}
 	for _, tc := range cases {
@@ -38,7 +40,7 @@ func TestClassifyError(t *testing.T) {
 }
 
 func TestErrorClass_IsRetryable(t *testing.T) {
-	retryable := []ErrorClass{ClassRateLimit, ClassServerError, ClassTimeout}
+	retryable := []ErrorClass{ClassRateLimit, ClassServerError, ClassTimeout, ClassConnection}
 	for _, c := range retryable {
 		if !c.IsRetryable() {
 			t.Errorf("expected %q retryable", c)
@@ -148,6 +150,46 @@ func TestDo_FallbackAfterThreshold(t *testing.T) {
 	}
 }
 
+func TestDoCandidates_AdvancesOnRetryableErrors(t *testing.T) {
+	var models []string
+	result, out, err := DoCandidates(context.Background(), fastPolicy(RetryPolicy{MaxAttempts: 2}), []string{"a", "b", "c"}, func(model string) (any, error) {
+		models = append(models, model)
+		switch model {
+		case "a":
+			return nil, fmt.Errorf("dial tcp 10.0.0.1:11434: connect: connection refused")
+		case "b":
+			return nil, fmt.Errorf("status: 429 too many requests")
+		}
+		return "ok from " + model, nil
+	})
+	if err != nil {
+		t.Fatalf("expected success on the third candidate, got %v", err)
+	}
+	if result != "ok from c" {
+		t.Fatalf("result = %v", result)
+	}
+	if want := []string{"a", "a", "b", "b", "c"}; fmt.Sprint(models) != fmt.Sprint(want) {
+		t.Fatalf("calls = %v, want %v", models, want)
+	}
+	if out.Model != "c" || fmt.Sprint(out.FailedModels) != "[a b]" || !out.UsedFallback || out.Attempts != 5 {
+		t.Fatalf("unexpected outcome %+v", out)
+	}
+}
+
+func TestDoCandidates_StopsOnNonRetryableError(t *testing.T) {
+	var models []string
+	_, out, err := DoCandidates(context.Background(), fastPolicy(RetryPolicy{}), []string{"a", "b"}, func(model string) (any, error) {
+		models = append(models, model)
+		return nil, fmt.Errorf("status: 401 unauthorized")
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if len(models) != 1 || out.Model != "a" || out.LastErrorClass != ClassAuth || len(out.FailedModels) != 0 {
+		t.Fatalf("calls=%v outcome=%+v", models, out)
+	}
+}
+
 func TestDo_ExhaustsAttempts(t *testing.T) {
 	calls := 0
 	_, out, err := Do(context.Background(), fastPolicy(RetryPolicy{MaxAttempts: 3}), "primary", func(model string) (any, error) {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -63,6 +64,15 @@ func (m *MacroEnv) ExecEnv(
 			h := *clone.Tasks[i].Tools
 			clone.Tasks[i].Tools = &h
 		}
+		clone.Tasks[i].PromptVariants = slices.Clone(clone.Tasks[i].PromptVariants)
+	}
+
+	if clone.SystemInstruction != "" {
+		var err error
+		clone.SystemInstruction, err = m.expandSpecialTemplates(ctx, &clone, nil, clone.SystemInstruction)
+		if err != nil {
+			return nil, DataTypeAny, nil, fmt.Errorf("chain %s: system_instruction macro error: %w", clone.ID, err)
+		}
 	}
 
 	// Expand macros in all relevant string fields of each task.
@@ -82,6 +92,13 @@ func (m *MacroEnv) ExecEnv(
 				return nil, DataTypeAny, nil, fmt.Errorf("task %s: prompt_template macro error: %w", t.ID, err)
 			}
 		}
+		for j := range t.PromptVariants {
+			pv := &t.PromptVariants[j]
+			pv.PromptTemplate, err = m.expandSpecialTemplates(ctx, &clone, allowlist, pv.PromptTemplate)
+			if err != nil {
+				return nil, DataTypeAny, nil, fmt.Errorf("task %s: prompt_variants[%d] macro error: %w", t.ID, j, err)
+			}
+		}
 		if t.Print != "" {
 			t.Print, err = m.expandSpecialTemplates(ctx, &clone, allowlist, t.Print)
 			if err != nil {
@@ -137,6 +154,58 @@ func (m *MacroEnv) ExecEnv(
 // unified macro: {{namespace}} or {{namespace:payload}}
 var macroRe = regexp.MustCompile(`\{\{([a-zA-Z0-9_]+)(?::([^}]*))?\}\}`)
 
+// TemplateVarRef is a {{var:NAME}} reference in a chain.
+type TemplateVarRef struct {
+	Name string `json:"name"`
+	// TaskID is empty for references in the chain-level system_instruction.
+	TaskID string `json:"taskId"`
+	// Field is the task field holding the reference, e.g. "prompt_template"
+	// or "execute_config.model".
+	Field string `json:"field"`
+}
+
+// TemplateVarRefs lists the {{var:NAME}} references in the chain-level
+// system_instruction and the task fields MacroEnv expands, in chain order.
+func TemplateVarRefs(chain *TaskChainDefinition) []TemplateVarRef {
+	var refs []TemplateVarRef
+	scan := func(taskID, field, in string) {
+		for _, m := range macroRe.FindAllStringSubmatch(in, -1) {
+			if m[1] == "var" {
+				refs = append(refs, TemplateVarRef{Name: strings.TrimSpace(m[2]), TaskID: taskID, Field: field})
+			}
+		}
+	}
+	scan("", "system_instruction", chain.SystemInstruction)
+	for _, t := range chain.Tasks {
+		scan(t.ID, "prompt_template", t.PromptTemplate)
+		for j, pv := range t.PromptVariants {
+			scan(t.ID, fmt.Sprintf("prompt_variants[%d].prompt_template", j), pv.PromptTemplate)
+		}
+		scan(t.ID, "print", t.Print)
+		scan(t.ID, "output_template", t.OutputTemplate)
+		scan(t.ID, "system_instruction", t.SystemInstruction)
+		if t.ExecuteConfig != nil {
+			scan(t.ID, "execute_config.model", t.ExecuteConfig.Model)
+			scan(t.ID, "execute_config.provider", t.ExecuteConfig.Provider)
+		}
+	}
+	return refs
+}
+
+// UnresolvedTemplateVars returns the sorted names of the template variables
+// chain references that vars does not set or sets to an empty string. MacroEnv
+// fails on the former but renders the latter as empty text.
+func UnresolvedTemplateVars(chain *TaskChainDefinition, vars map[string]string) []string {
+	var missing []string
+	for _, ref := range TemplateVarRefs(chain) {
+		if vars[ref.Name] == "" && !slices.Contains(missing, ref.Name) {
+			missing = append(missing, ref.Name)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
 func (m *MacroEnv) expandSpecialTemplates(ctx context.Context, chain *TaskChainDefinition, allowlist []string, in string) (string, error) {
 	matches := macroRe.FindAllStringSubmatchIndex(in, -1)
 	if len(matches) == 0 {
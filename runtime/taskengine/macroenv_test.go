@@ -219,3 +219,36 @@ func keys(m map[string][]string) []string {
 	}
 	return ks
 }
+
+func TestTemplateVarRefs(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{SystemInstruction: "Sign as {{var:signature}}.", Tasks: []taskengine.TaskDefinition{
+		{
+			ID:                "ask",
+			SystemInstruction: "You work for {{var:company}}.",
+			PromptTemplate:    "{{var:greeting}} {{.input}} {{now}}",
+			ExecuteConfig:     &taskengine.LLMExecutionConfig{Model: "{{var:model}}"},
+		},
+		{ID: "done", Print: "Sent by {{var: company }}"},
+	}}
+	refs := taskengine.TemplateVarRefs(chain)
+	want := []taskengine.TemplateVarRef{
+		{Name: "signature", Field: "system_instruction"},
+		{Name: "greeting", TaskID: "ask", Field: "prompt_template"},
+		{Name: "company", TaskID: "ask", Field: "system_instruction"},
+		{Name: "model", TaskID: "ask", Field: "execute_config.model"},
+		{Name: "company", TaskID: "done", Field: "print"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %v, want %v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ref %d: got %v, want %v", i, refs[i], want[i])
+		}
+	}
+
+	missing := taskengine.UnresolvedTemplateVars(chain, map[string]string{"company": "ACME", "model": ""})
+	if strings.Join(missing, ",") != "greeting,model,signature" {
+		t.Errorf("unresolved: got %v, want [greeting model signature]", missing)
+	}
+}
@@ -0,0 +1,245 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"golang.org/x/sync/errgroup"
+)
+
+const defaultChunkTokens = 2000
+
+// maxReduceRounds bounds the grouped reduce rounds so a reduce prompt that
+// does not shrink its input cannot loop forever.
+const maxReduceRounds = 8
+
+// tokenCounter counts the tokens of s for the configured model.
+type tokenCounter func(ctx context.Context, s string) (int, error)
+
+// mapReduce runs a map_reduce task over input.
+func (exe *SimpleExec) mapReduce(ctx context.Context, task *TaskDefinition, input string, ctxLength int) (string, error) {
+	cfg := task.MapReduce
+	if err := validateMapReduceConfig(cfg); err != nil {
+		return "", err
+	}
+	llmCall := LLMExecutionConfig{}
+	if task.ExecuteConfig != nil {
+		llmCall = *task.ExecuteConfig
+	}
+	model := getPrimaryModel(&llmCall)
+	count := func(ctx context.Context, s string) (int, error) {
+		return exe.repo.CountTokens(ctx, model, s)
+	}
+	budget := cfg.ChunkTokens
+	if budget <= 0 {
+		budget = defaultChunkTokens
+	}
+
+	chunks, err := splitTokenBounded(ctx, count, input, budget, cfg.ChunkOverlap)
+	if err != nil {
+		return "", fmt.Errorf("map_reduce: split input: %w", err)
+	}
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("map_reduce: input is empty %w", errdefs.ErrBadRequest)
+	}
+
+	prompt := func(ctx context.Context, tmpl string, data map[string]any) (string, error) {
+		rendered, err := renderTemplate(tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		return exe.Prompt(ctx, task.SystemInstruction, llmCall, rendered, ctxLength)
+	}
+
+	// The first failed chunk cancels the map prompts still running.
+	partials := make([]string, len(chunks))
+	g, mapCtx := errgroup.WithContext(ctx)
+	if cfg.MaxConcurrency > 0 {
+		g.SetLimit(cfg.MaxConcurrency)
+	}
+	for i, chunk := range chunks {
+		g.Go(func() error {
+			out, err := prompt(mapCtx, cfg.MapPrompt, map[string]any{"chunk": chunk, "index": i + 1, "total": len(chunks)})
+			if err != nil {
+				return fmt.Errorf("map_reduce: chunk %d/%d: %w", i+1, len(chunks), err)
+			}
+			partials[i] = out
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return "", err
+	}
+
+	// Reduce groups of partial results until they fit into one reduce prompt.
+	for round := 0; ; round++ {
+		groups, err := groupTokenBounded(ctx, count, partials, budget)
+		if err != nil {
+			return "", fmt.Errorf("map_reduce: reduce: %w", err)
+		}
+		if len(groups) == 1 || round == maxReduceRounds || len(groups) == len(partials) {
+			return prompt(ctx, cfg.ReducePrompt, reduceData(partials))
+		}
+		next := make([]string, len(groups))
+		for i, group := range groups {
+			out, err := prompt(ctx, cfg.ReducePrompt, reduceData(group))
+			if err != nil {
+				return "", fmt.Errorf("map_reduce: reduce round %d group %d: %w", round+1, i+1, err)
+			}
+			next[i] = out
+		}
+		partials = next
+	}
+}
+
+func validateMapReduceConfig(cfg *MapReduceConfig) error {
+	switch {
+	case cfg == nil:
+		return fmt.Errorf("map_reduce task requires map_reduce config %w", errdefs.ErrBadRequest)
+	case cfg.MapPrompt == "" || cfg.ReducePrompt == "":
+		return fmt.Errorf("map_reduce task requires map_prompt and reduce_prompt %w", errdefs.ErrBadRequest)
+	case cfg.ChunkTokens < 0 || cfg.ChunkOverlap < 0 || cfg.MaxConcurrency < 0:
+		return fmt.Errorf("map_reduce chunk_tokens, chunk_overlap and max_concurrency must not be negative %w", errdefs.ErrBadRequest)
+	case cfg.ChunkTokens > 0 && cfg.ChunkOverlap >= cfg.ChunkTokens:
+		return fmt.Errorf("map_reduce chunk_overlap must be smaller than chunk_tokens %w", errdefs.ErrBadRequest)
+	}
+	return nil
+}
+
+func reduceData(partials []string) map[string]any {
+	return map[string]any{"results": strings.Join(partials, "\n\n"), "partials": partials}
+}
+
+// splitTokenBounded splits text into chunks of at most maxTokens tokens,
+// preferring paragraph, line and word boundaries. Each chunk starts with up
+// to overlap tokens from the end of the previous chunk.
+func splitTokenBounded(ctx context.Context, count tokenCounter, text string, maxTokens, overlap int) ([]string, error) {
+	units, err := splitUnits(ctx, count, text, maxTokens, []string{"\n\n", "\n", " "})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		chunks  []string
+		current []tokenUnit
+		used    int
+	)
+	emit := func() {
+		var b strings.Builder
+		for _, u := range current {
+			b.WriteString(u.text)
+		}
+		if s := strings.TrimSpace(b.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+	}
+	for _, u := range units {
+		n := u.tokens
+		if used+n > maxTokens && len(current) > 0 {
+			emit()
+			// Carry the tail of the chunk over as overlap if it leaves room for s.
+			var kept []tokenUnit
+			keptTokens := 0
+			for i := len(current) - 1; i >= 0; i-- {
+				if keptTokens+current[i].tokens > overlap || keptTokens+current[i].tokens+n > maxTokens {
+					break
+				}
+				keptTokens += current[i].tokens
+				kept = append([]tokenUnit{current[i]}, kept...)
+			}
+			current, used = kept, keptTokens
+		}
+		current = append(current, u)
+		used += n
+	}
+	if len(current) > 0 {
+		emit()
+	}
+	return chunks, nil
+}
+
+// tokenUnit is a piece of text that is never split further.
+type tokenUnit struct {
+	text   string
+	tokens int
+}
+
+// splitUnits breaks text at the first separator that yields pieces within
+// maxTokens, recursing into oversized pieces. Separators stay attached to the
+// preceding piece so joining the units restores the text. Pieces without any
+// separator are cut by runes.
+func splitUnits(ctx context.Context, count tokenCounter, text string, maxTokens int, seps []string) ([]tokenUnit, error) {
+	n, err := count(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if n <= maxTokens {
+		return []tokenUnit{{text: text, tokens: n}}, nil
+	}
+	if len(seps) == 0 {
+		var out []tokenUnit
+		for _, piece := range splitRunes(text, n, maxTokens) {
+			out = append(out, tokenUnit{text: piece, tokens: min(maxTokens, n)})
+		}
+		return out, nil
+	}
+	parts := strings.SplitAfter(text, seps[0])
+	if len(parts) == 1 {
+		return splitUnits(ctx, count, text, maxTokens, seps[1:])
+	}
+	var out []tokenUnit
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		units, err := splitUnits(ctx, count, part, maxTokens, seps[1:])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, units...)
+	}
+	return out, nil
+}
+
+// splitRunes cuts text into pieces of roughly maxTokens tokens, assuming the
+// tokens of text are spread evenly over its runes.
+func splitRunes(text string, tokens, maxTokens int) []string {
+	runes := []rune(text)
+	size := len(runes) * maxTokens / tokens
+	if size < 1 {
+		size = 1
+	}
+	var out []string
+	for start := 0; start < len(runes); start += size {
+		end := min(start+size, len(runes))
+		out = append(out, string(runes[start:end]))
+	}
+	return out
+}
+
+// groupTokenBounded packs consecutive items into groups of at most maxTokens.
+// An item larger than maxTokens forms its own group.
+func groupTokenBounded(ctx context.Context, count tokenCounter, items []string, maxTokens int) ([][]string, error) {
+	var (
+		groups  [][]string
+		current []string
+		used    int
+	)
+	for _, item := range items {
+		n, err := count(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		if used+n > maxTokens && len(current) > 0 {
+			groups = append(groups, current)
+			current, used = nil, 0
+		}
+		current = append(current, item)
+		used += n
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups, nil
+}
@@ -0,0 +1,47 @@
+package taskengine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countWords(_ context.Context, s string) (int, error) {
+	return len(strings.Fields(s)), nil
+}
+
+func TestUnit_SplitTokenBounded(t *testing.T) {
+	ctx := context.Background()
+
+	chunks, err := splitTokenBounded(ctx, countWords, "one two three\n\nfour five\nsix seven eight nine ten", 4, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one two three", "four five\nsix seven", "eight nine ten"}, chunks)
+	for _, c := range chunks {
+		n, _ := countWords(ctx, c)
+		assert.LessOrEqual(t, n, 4)
+	}
+
+	chunks, err = splitTokenBounded(ctx, countWords, "a b c d e f g", 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a b c", "c d e", "e f g"}, chunks)
+
+	chunks, err = splitTokenBounded(ctx, countWords, "   ", 3, 0)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestUnit_GroupTokenBounded(t *testing.T) {
+	groups, err := groupTokenBounded(context.Background(), countWords, []string{"a b", "c", "d e f g", "h"}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a b", "c"}, {"d e f g"}, {"h"}}, groups)
+}
+
+func TestUnit_ValidateMapReduceConfig(t *testing.T) {
+	assert.Error(t, validateMapReduceConfig(nil))
+	assert.Error(t, validateMapReduceConfig(&MapReduceConfig{MapPrompt: "m"}))
+	assert.Error(t, validateMapReduceConfig(&MapReduceConfig{MapPrompt: "m", ReducePrompt: "r", ChunkTokens: 10, ChunkOverlap: 10}))
+	assert.NoError(t, validateMapReduceConfig(&MapReduceConfig{MapPrompt: "m", ReducePrompt: "r"}))
+}
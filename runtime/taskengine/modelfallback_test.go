@@ -0,0 +1,86 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runModelFallbackChain(t *testing.T, promptErrs map[string]error) ([]string, []taskengine.CapturedStateUnit, error) {
+	t.Helper()
+	var tried []string
+	repo := &mockModelRepo{
+		promptFunc: func(ctx context.Context, req llmrepo.Request, _ string, _ float32, _ string) (string, llmrepo.Meta, error) {
+			require.Len(t, req.ModelNames, 1)
+			model := req.ModelNames[0]
+			tried = append(tried, model)
+			if err := promptErrs[model]; err != nil {
+				return "", llmrepo.Meta{}, err
+			}
+			return "answer from " + model, llmrepo.Meta{ModelName: model, ProviderType: "ollama", BackendID: "backend-" + model}, nil
+		},
+	}
+	ctx := context.Background()
+	fallback := true
+	exec, err := taskengine.NewExec(ctx, repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(ctx, libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID: "chain.fallback",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:             "ask",
+			Handler:        taskengine.HandlePromptToString,
+			PromptTemplate: "{{.input}}",
+			ExecuteConfig: &taskengine.LLMExecutionConfig{
+				Model:         "local",
+				Models:        []string{"cloud", "backup"},
+				ModelFallback: &fallback,
+			},
+			Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+			},
+		}},
+	}
+	_, _, history, err := env.ExecEnv(ctx, chain, "hi", taskengine.DataTypeString)
+	return tried, history, err
+}
+
+func TestModelFallback_AdvancesOnRetryableErrors(t *testing.T) {
+	tried, history, err := runModelFallbackChain(t, map[string]error{
+		"local": errors.New(`Post "http://localhost:11434/api/generate": dial tcp 127.0.0.1:11434: connect: connection refused`),
+		"cloud": errors.New("OpenAI API returned non-200 status: 429, body: rate limited"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local", "cloud", "backup"}, tried)
+	require.Len(t, history, 1)
+	assert.Equal(t, "backup", history[0].Model)
+	assert.Equal(t, "ollama", history[0].Provider)
+	assert.Equal(t, "backend-backup", history[0].BackendID)
+	assert.Equal(t, []string{"local", "cloud"}, history[0].FallbackFrom)
+}
+
+func TestModelFallback_StopsOnPermanentError(t *testing.T) {
+	tried, _, err := runModelFallbackChain(t, map[string]error{
+		"local": errors.New("OpenAI API returned non-200 status: 401, body: invalid api key"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{"local"}, tried)
+}
+
+func TestModelFallback_RecordsServingModel(t *testing.T) {
+	tried, history, err := runModelFallbackChain(t, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local"}, tried)
+	require.Len(t, history, 1)
+	assert.Equal(t, "local", history[0].Model)
+	assert.Empty(t, history[0].FallbackFrom)
+}
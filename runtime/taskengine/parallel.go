@@ -0,0 +1,218 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"golang.org/x/sync/errgroup"
+)
+
+// subtaskResult is the outcome of a single branch or iteration of a fan-out
+// task, with a step per attempt.
+type subtaskResult struct {
+	taskID     string
+	output     any
+	outputType DataType
+	steps      []CapturedStateUnit
+	err        error
+}
+
+// execParallel runs every branch of a parallel task concurrently, bounded by
+// ParallelConfig.MaxConcurrency, and merges the branch outputs into a JSON map
+// keyed by branch task ID. Branches never short-circuit each other: all of them
+// run to completion and their errors are joined into the returned error so the
+// parallel task's retry and on_failure handling apply to the group as a whole.
+//
+// The returned results are ordered like ParallelConfig.Branches so callers can
+// record steps and store branch variables deterministically.
+func (env SimpleEnv) execParallel(
+	ctx context.Context,
+	startingTime time.Time,
+	chain *TaskChainDefinition,
+	chainContext *ChainContext,
+	task *TaskDefinition,
+	vars map[string]any,
+	input any,
+	dataType DataType,
+) (map[string]any, []subtaskResult, error) {
+	if task.Parallel == nil || len(task.Parallel.Branches) == 0 {
+		return nil, nil, fmt.Errorf("parallel task missing branches %w", errdefs.ErrBadRequest)
+	}
+
+	branches := make([]TaskDefinition, len(task.Parallel.Branches))
+	for i, id := range task.Parallel.Branches {
+		branch, err := findTaskByID(chain.Tasks, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parallel branch %q: %w", id, err)
+		}
+		if branch.Handler == HandleParallel {
+			return nil, nil, fmt.Errorf("parallel branch %q: nested parallel tasks are not supported %w", id, errdefs.ErrBadRequest)
+		}
+		// Copy so concurrent executors never share (and mutate) the same definition.
+		branches[i] = *branch
+	}
+
+	results := make([]subtaskResult, len(branches))
+	var g errgroup.Group
+	if task.Parallel.MaxConcurrency > 0 {
+		g.SetLimit(task.Parallel.MaxConcurrency)
+	}
+	for i := range branches {
+		g.Go(func() error {
+			results[i] = env.execSubtask(ctx, startingTime, chain, chainContext, "parallel_branch", task.ID, &branches[i], vars, input, dataType)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	merged := make(map[string]any, len(results))
+	var errs []error
+	for i, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("branch %s: %w", branches[i].ID, res.err))
+			continue
+		}
+		merged[branches[i].ID] = res.output
+	}
+	if len(errs) > 0 {
+		return nil, results, errors.Join(errs...)
+	}
+	return merged, results, nil
+}
+
+// execSubtask executes a single branch or iteration of a fan-out task the way
+// the chain loop executes a task, with its retries, timeout and guardrails,
+// and captures a step per attempt for the stack trace. Events are published
+// with the subtask's own task scope so consumers can tell concurrent subtasks
+// apart. vars is only read: the chain loop is blocked until all subtasks
+// return.
+func (env SimpleEnv) execSubtask(
+	ctx context.Context,
+	startingTime time.Time,
+	chain *TaskChainDefinition,
+	chainContext *ChainContext,
+	operation string,
+	parentID string,
+	branch *TaskDefinition,
+	vars map[string]any,
+	input any,
+	dataType DataType,
+) subtaskResult {
+	res := subtaskResult{taskID: branch.ID}
+	step, err := env.prepareStep(ctx, branch, vars, input, dataType)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	retrySched, err := resolveRetry(branch)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	for retry := 0; retry < retrySched.maxAttempts; retry++ {
+		if retry > 0 {
+			if err := retrySched.wait(ctx, retry); err != nil {
+				res.err = fmt.Errorf("retry %d aborted: %w", retry, err)
+				return res
+			}
+		}
+		var captured CapturedStateUnit
+		var transition string
+		res.output, res.outputType, transition, captured, res.err = env.attemptSubtask(ctx, startingTime, chain, chainContext, operation, parentID, step, retry, retrySched.maxAttempts)
+		if captured.TaskID != "" {
+			res.steps = append(res.steps, captured)
+		}
+		if res.err != nil {
+			if errors.Is(res.err, ErrGuardrailRejected) {
+				return res
+			}
+			continue
+		}
+		if retry < retrySched.maxAttempts-1 && retrySched.retriesOn(transition) {
+			continue
+		}
+		return res
+	}
+	return res
+}
+
+// attemptSubtask runs one attempt of a fan-out subtask and returns its step.
+func (env SimpleEnv) attemptSubtask(
+	ctx context.Context,
+	startingTime time.Time,
+	chain *TaskChainDefinition,
+	chainContext *ChainContext,
+	operation string,
+	parentID string,
+	step *taskStep,
+	retry int,
+	maxAttempts int,
+) (any, DataType, string, CapturedStateUnit, error) {
+	branch := step.task
+	taskCtx, cancel, err := withTaskTimeout(ctx, branch)
+	if err != nil {
+		return nil, DataTypeAny, "", CapturedStateUnit{}, err
+	}
+	defer cancel()
+	taskCtx = WithTaskEventScope(taskCtx, TaskEventScope{
+		ChainID:     chain.ID,
+		TaskID:      branch.ID,
+		TaskHandler: branch.Handler.String(),
+		Retry:       retry,
+	})
+	served := &servedModel{}
+	taskCtx = withServedModel(taskCtx, served)
+	publishTaskEventBestEffort(taskCtx, env.eventSink, NewTaskEvent(taskCtx, TaskEventStepStarted))
+
+	reportErr, reportChange, end := env.tracker.Start(taskCtx, operation, branch.ID, "parent_task", parentID, "retry", retry)
+	defer end()
+
+	start := time.Now().UTC()
+	output, outputType, transitionEval, verdicts, err := env.runAttempt(taskCtx, chain, step, func(taskCtx context.Context) (any, DataType, string, error) {
+		return env.exec.TaskExec(taskCtx, startingTime, int(chain.TokenLimit), chainContext, branch, step.input, step.inputType)
+	})
+	captured := CapturedStateUnit{
+		TaskID:        branch.ID,
+		TaskHandler:   branch.Handler.String(),
+		InputType:     step.inputType,
+		OutputType:    outputType,
+		InputVar:      parentID,
+		Transition:    transitionEval,
+		Duration:      time.Since(start),
+		Error:         ErrorResponse{ErrorInternal: err},
+		Attempt:       retry + 1,
+		MaxAttempts:   maxAttempts,
+		Guardrails:    verdicts,
+		PromptVariant: step.promptVariant,
+	}
+	served.apply(&captured)
+	if chain.Debug {
+		captured.Input = fmt.Sprintf("%v", step.input)
+		if outputBytes, mErr := json.Marshal(output); mErr == nil {
+			captured.Output = string(outputBytes)
+		} else {
+			captured.Output = fmt.Sprintf("%v", output)
+		}
+	}
+
+	event := NewTaskEvent(taskCtx, TaskEventStepCompleted)
+	event.OutputType = outputType.String()
+	event.Transition = transitionEval
+	event.PromptVariant = step.promptVariant
+	if err != nil {
+		captured.Error.Error = err.Error()
+		reportErr(err)
+		event.Kind = TaskEventStepFailed
+		event.Error = err.Error()
+		event.OutputType = ""
+		publishTaskEventBestEffort(taskCtx, env.eventSink, event)
+		return nil, DataTypeAny, transitionEval, captured, err
+	}
+	publishTaskEventBestEffort(taskCtx, env.eventSink, event)
+	reportChange(branch.ID, output)
+	return output, outputType, transitionEval, captured, nil
+}
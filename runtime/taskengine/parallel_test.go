@@ -0,0 +1,167 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// branchExecutor is a concurrency-safe TaskExecutor that answers per task ID
+// and records the peak number of in-flight calls.
+type branchExecutor struct {
+	outputs map[string]any
+	errs    map[string]error
+	delay   time.Duration
+
+	mu       sync.Mutex
+	inflight int32
+	peak     int32
+	inputs   map[string]any
+	args     map[string]map[string]string
+}
+
+func (b *branchExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, dataType taskengine.DataType) (any, taskengine.DataType, string, error) {
+	n := atomic.AddInt32(&b.inflight, 1)
+	defer atomic.AddInt32(&b.inflight, -1)
+	for {
+		peak := atomic.LoadInt32(&b.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&b.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(b.delay)
+
+	b.mu.Lock()
+	if b.inputs == nil {
+		b.inputs = map[string]any{}
+	}
+	b.inputs[task.ID] = input
+	if task.Tools != nil {
+		if b.args == nil {
+			b.args = map[string]map[string]string{}
+		}
+		b.args[task.ID] = task.Tools.Args
+	}
+	b.mu.Unlock()
+
+	if err := b.errs[task.ID]; err != nil {
+		return nil, taskengine.DataTypeAny, "", err
+	}
+	if out, ok := b.outputs[task.ID]; ok {
+		return out, taskengine.InferDataType(out), "ok", nil
+	}
+	return input, dataType, "ok", nil
+}
+
+func parallelChain(maxConcurrency int) *taskengine.TaskChainDefinition {
+	end := taskengine.TaskTransition{
+		Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+	}
+	return &taskengine.TaskChainDefinition{
+		ID: "parallel",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "fanout",
+				Handler: taskengine.HandleParallel,
+				Parallel: &taskengine.ParallelConfig{
+					Branches:       []string{"a", "b", "c"},
+					MaxConcurrency: maxConcurrency,
+				},
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: "merge"}},
+				},
+			},
+			{ID: "a", Handler: taskengine.HandlePromptToString, Transition: end},
+			{ID: "b", Handler: taskengine.HandlePromptToString, Transition: end},
+			{ID: "c", Handler: taskengine.HandlePromptToString, PromptTemplate: "c got {{.input}}", Transition: end},
+			{ID: "merge", Handler: taskengine.HandleNoop, Transition: end},
+		},
+	}
+}
+
+func TestUnit_Parallel_MergesBranchOutputs(t *testing.T) {
+	exec := &branchExecutor{
+		outputs: map[string]any{"a": "alpha", "b": 2},
+	}
+	env := setupTestEnv(exec)
+
+	output, outputType, history, err := env.ExecEnv(context.Background(), parallelChain(0), "hello", taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	assert.Equal(t, taskengine.DataTypeJSON, outputType)
+	assert.Equal(t, map[string]any{"a": "alpha", "b": 2, "c": "c got hello"}, output)
+	assert.Equal(t, "hello", exec.inputs["a"])
+
+	var ids []string
+	for _, step := range history {
+		ids = append(ids, step.TaskID)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "fanout", "merge"}, ids)
+}
+
+func TestUnit_Parallel_RespectsMaxConcurrency(t *testing.T) {
+	exec := &branchExecutor{delay: 20 * time.Millisecond}
+	env := setupTestEnv(exec)
+
+	_, _, _, err := env.ExecEnv(context.Background(), parallelChain(1), "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&exec.peak))
+
+	exec = &branchExecutor{delay: 20 * time.Millisecond}
+	env = setupTestEnv(exec)
+	_, _, _, err = env.ExecEnv(context.Background(), parallelChain(0), "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&exec.peak))
+}
+
+func TestUnit_Parallel_BranchFailureFailsTask(t *testing.T) {
+	exec := &branchExecutor{errs: map[string]error{"b": errors.New("boom")}}
+	env := setupTestEnv(exec)
+
+	_, _, _, err := env.ExecEnv(context.Background(), parallelChain(0), "x", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "branch b: boom")
+}
+
+func TestUnit_Parallel_BranchTimeoutBranchesOnTimeout(t *testing.T) {
+	env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"a": time.Second}})
+	chain := parallelChain(0)
+	chain.Tasks[0].Transition.Branches = []taskengine.TransitionBranch{
+		{Operator: taskengine.OpEquals, When: taskengine.TransitionTimeout, Goto: "merge"},
+		endBranch(),
+	}
+	chain.Tasks[1].Timeout = "20ms"
+
+	_, _, history, err := env.ExecEnv(context.Background(), chain, "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	var branch taskengine.CapturedStateUnit
+	for _, step := range history {
+		if step.TaskID == "a" {
+			branch = step
+		}
+	}
+	assert.Equal(t, taskengine.TransitionTimeout, branch.Transition)
+	assert.ErrorIs(t, branch.Error.ErrorInternal, taskengine.ErrTaskTimeout)
+	// The fan-out task takes its timeout branch.
+	assert.Equal(t, "merge", history[len(history)-1].TaskID)
+}
+
+func TestUnit_Parallel_RendersBranchToolsArgs(t *testing.T) {
+	exec := &branchExecutor{}
+	env := setupTestEnv(exec)
+	chain := parallelChain(0)
+	chain.Tasks[1].Tools = &taskengine.ToolsCall{Name: "webhook", Args: map[string]string{"q": "{{.input}}", "static": "unchanged"}}
+
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"q": "x", "static": "unchanged"}, exec.args["a"])
+	assert.Equal(t, "{{.input}}", chain.Tasks[1].Tools.Args["q"])
+}
@@ -0,0 +1,105 @@
+package taskengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// PromptCache stores prompt responses by key. Implementations must be safe for
+// concurrent use. Get reports false for missing and expired entries.
+type PromptCache interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, response string, ttl time.Duration) error
+}
+
+type promptCacheContextKey struct{}
+
+type promptCacheConfig struct {
+	cache PromptCache
+	ttl   time.Duration
+}
+
+// WithPromptCache attaches cache to ctx. A SimpleExec created from the
+// returned context answers prompts with the same system instruction, prompt,
+// models, providers and temperature from the cache for ttl. A non-positive
+// ttl or nil cache disables caching.
+func WithPromptCache(ctx context.Context, cache PromptCache, ttl time.Duration) context.Context {
+	if cache == nil || ttl <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, promptCacheContextKey{}, &promptCacheConfig{cache: cache, ttl: ttl})
+}
+
+func promptCacheFromContext(ctx context.Context) *promptCacheConfig {
+	if ctx == nil {
+		return nil
+	}
+	cfg, _ := ctx.Value(promptCacheContextKey{}).(*promptCacheConfig)
+	return cfg
+}
+
+// PromptCacheKey returns the cache key of a prompt call.
+func PromptCacheKey(systemInstruction string, llmCall LLMExecutionConfig, prompt string) string {
+	data, _ := json.Marshal(struct {
+		System      string   `json:"system"`
+		Prompt      string   `json:"prompt"`
+		Model       string   `json:"model"`
+		Models      []string `json:"models"`
+		Provider    string   `json:"provider"`
+		Providers   []string `json:"providers"`
+		Temperature float32  `json:"temperature"`
+		Think       string   `json:"think"`
+		// Further sampling options are omitted when unset so keys of
+		// existing cache entries stay valid.
+		TopP             float32  `json:"top_p,omitempty"`
+		TopK             int      `json:"top_k,omitempty"`
+		FrequencyPenalty float32  `json:"frequency_penalty,omitempty"`
+		PresencePenalty  float32  `json:"presence_penalty,omitempty"`
+		Stop             []string `json:"stop,omitempty"`
+		MaxTokens        int      `json:"max_tokens,omitempty"`
+	}{systemInstruction, prompt, llmCall.Model, llmCall.Models, llmCall.Provider, llmCall.Providers, llmCall.Temperature, llmCall.Think,
+		llmCall.TopP, llmCall.TopK, llmCall.FrequencyPenalty, llmCall.PresencePenalty, llmCall.Stop, llmCall.MaxTokens})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryPromptCache is an in-process PromptCache.
+type MemoryPromptCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	response  string
+	expiresAt time.Time
+}
+
+// NewMemoryPromptCache returns an empty in-memory PromptCache.
+func NewMemoryPromptCache() *MemoryPromptCache {
+	return &MemoryPromptCache{entries: map[string]memoryCacheEntry{}}
+}
+
+func (c *MemoryPromptCache) Get(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	if !time.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false, nil
+	}
+	return entry.response, true, nil
+}
+
+func (c *MemoryPromptCache) Set(_ context.Context, key, response string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = memoryCacheEntry{response: response, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
@@ -0,0 +1,64 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_PromptCache_ReusesIdenticalPrompts(t *testing.T) {
+	calls := 0
+	repo := &mockModelRepo{
+		promptFunc: func(context.Context, llmrepo.Request, string, float32, string) (string, llmrepo.Meta, error) {
+			calls++
+			return " answer ", llmrepo.Meta{}, nil
+		},
+	}
+	ctx := taskengine.WithPromptCache(context.Background(), taskengine.NewMemoryPromptCache(), time.Minute)
+	exec, err := taskengine.NewExec(ctx, repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	task := func(temperature float32) *taskengine.TaskDefinition {
+		return &taskengine.TaskDefinition{
+			ID:            "ask",
+			Handler:       taskengine.HandlePromptToString,
+			ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "test-model", Temperature: temperature},
+		}
+	}
+	run := func(td *taskengine.TaskDefinition, input string) any {
+		out, _, _, err := exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, td, input, taskengine.DataTypeString)
+		require.NoError(t, err)
+		return out
+	}
+
+	assert.Equal(t, "answer", run(task(0), "question"))
+	assert.Equal(t, "answer", run(task(0), "question"))
+	assert.Equal(t, 1, calls)
+
+	run(task(0.7), "question")
+	run(task(0), "other question")
+	assert.Equal(t, 3, calls)
+}
+
+func TestUnit_MemoryPromptCache_Expires(t *testing.T) {
+	cache := taskengine.NewMemoryPromptCache()
+	ctx := context.Background()
+	require.NoError(t, cache.Set(ctx, "k", "v", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, ok, err := cache.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "k", "v", time.Minute))
+	got, ok, err := cache.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "v", got)
+}
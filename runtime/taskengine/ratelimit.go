@@ -0,0 +1,185 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"golang.org/x/time/rate"
+)
+
+// AnyProvider is the key of a rate limit that applies to every provider
+// without a limit of its own, and to calls that do not request a provider.
+const AnyProvider = "*"
+
+// RateLimiter caps the model calls per minute per provider type, e.g.
+// {"openai": 500, "*": 60}. It is safe for concurrent use; share one limiter
+// between all executors of a server via WithRateLimiter so that concurrent
+// chain executions draw from the same budget.
+//
+// Limits are token buckets: up to the limit of calls start at once, after
+// which calls are spread evenly over the minute. A call waits for a free slot
+// until its context is done.
+type RateLimiter struct {
+	limits map[string]int
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+}
+
+// NewRateLimiter returns a limiter enforcing limits, which maps provider
+// types or AnyProvider to calls per minute. Limits must be positive; a nil
+// map limits only chains that set rate_limits.
+func NewRateLimiter(limits map[string]int) (*RateLimiter, error) {
+	if err := checkRateLimits(limits); err != nil {
+		return nil, err
+	}
+	l := &RateLimiter{limits: make(map[string]int, len(limits)), buckets: map[string]*rate.Limiter{}}
+	for provider, perMinute := range limits {
+		l.limits[provider] = perMinute
+	}
+	return l, nil
+}
+
+// checkRateLimits reports the first limit that is not positive.
+func checkRateLimits(limits map[string]int) error {
+	providers := make([]string, 0, len(limits))
+	for provider := range limits {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		if provider == "" {
+			return fmt.Errorf("rate limit without provider %w", errdefs.ErrBadRequest)
+		}
+		if limits[provider] <= 0 {
+			return fmt.Errorf("rate limit of %q must be positive, got %d %w", provider, limits[provider], errdefs.ErrBadRequest)
+		}
+	}
+	return nil
+}
+
+type rateLimiterKey struct{}
+
+// WithRateLimiter makes executors created by NewExec with the returned
+// context wait for limiter before every model call.
+func WithRateLimiter(ctx context.Context, limiter *RateLimiter) context.Context {
+	if limiter == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, rateLimiterKey{}, limiter)
+}
+
+func rateLimiterFromContext(ctx context.Context) *RateLimiter {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value(rateLimiterKey{}).(*RateLimiter)
+	return l
+}
+
+// chainRateLimits is the rate_limits of the running chain.
+type chainRateLimits struct {
+	chainID string
+	limits  map[string]int
+}
+
+type chainRateLimitsKey struct{}
+
+// withChainRateLimits records the rate_limits of the running chain.
+func withChainRateLimits(ctx context.Context, chain *TaskChainDefinition) context.Context {
+	if len(chain.RateLimits) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, chainRateLimitsKey{}, chainRateLimits{chainID: chain.ID, limits: chain.RateLimits})
+}
+
+// Wait blocks until a call to provider may start, or ctx is done. An empty
+// provider counts against AnyProvider. The rate_limits of the running chain
+// apply on top of the limiter's own limits.
+func (l *RateLimiter) Wait(ctx context.Context, provider string) error {
+	if provider == "" {
+		provider = AnyProvider
+	}
+	chain, _ := ctx.Value(chainRateLimitsKey{}).(chainRateLimits)
+	if perMinute, ok := limitFor(l.limits, provider); ok {
+		if err := l.bucket(provider, perMinute).Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit of %s: %w", provider, err)
+		}
+	}
+	if perMinute, ok := limitFor(chain.limits, provider); ok {
+		// Chain buckets are shared by every run of the chain.
+		if err := l.bucket(chain.chainID+"/"+provider, perMinute).Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit of %s in chain %s: %w", provider, chain.chainID, err)
+		}
+	}
+	return nil
+}
+
+// limitFor returns the limit of provider in limits, falling back to
+// AnyProvider.
+func limitFor(limits map[string]int, provider string) (int, bool) {
+	if perMinute, ok := limits[provider]; ok {
+		return perMinute, true
+	}
+	perMinute, ok := limits[AnyProvider]
+	return perMinute, ok
+}
+
+// bucket returns the token bucket of key, creating it or updating its limit.
+func (l *RateLimiter) bucket(key string, perMinute int) *rate.Limiter {
+	every := rate.Every(time.Minute / time.Duration(perMinute))
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = rate.NewLimiter(every, perMinute)
+		l.buckets[key] = b
+	} else if b.Burst() != perMinute {
+		// The chain was updated with a new limit.
+		b.SetLimit(every)
+		b.SetBurst(perMinute)
+	}
+	return b
+}
+
+// rateLimitedRepo waits for the executor's RateLimiter before every model
+// call. The wait happens once the repo resolved the provider serving the
+// call, so only that provider's budget is drawn from. Token counting is not
+// limited.
+type rateLimitedRepo struct {
+	llmrepo.ModelRepo
+	limiter *RateLimiter
+}
+
+var _ llmrepo.ModelRepo = (*rateLimitedRepo)(nil)
+
+func (m *rateLimitedRepo) gate(ctx context.Context) context.Context {
+	return llmrepo.WithCallGate(ctx, m.limiter.Wait)
+}
+
+func (m *rateLimitedRepo) PromptExecute(ctx context.Context, req llmrepo.Request, systemInstruction string, temperature float32, prompt string, opts ...libmodelprovider.ChatArgument) (string, llmrepo.Meta, error) {
+	return m.ModelRepo.PromptExecute(m.gate(ctx), req, systemInstruction, temperature, prompt, opts...)
+}
+
+func (m *rateLimitedRepo) Chat(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	return m.ModelRepo.Chat(m.gate(ctx), req, messages, opts...)
+}
+
+func (m *rateLimitedRepo) Stream(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
+	return m.ModelRepo.Stream(m.gate(ctx), req, messages, opts...)
+}
+
+func (m *rateLimitedRepo) Embed(ctx context.Context, req llmrepo.EmbedRequest, prompt string) ([]float64, llmrepo.Meta, error) {
+	return m.ModelRepo.Embed(m.gate(ctx), req, prompt)
+}
+
+// EmbedBatch counts one call per batch of EmbedRequest.BatchSize prompts.
+func (m *rateLimitedRepo) EmbedBatch(ctx context.Context, req llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	return m.ModelRepo.EmbedBatch(m.gate(ctx), req, prompts)
+}
@@ -0,0 +1,129 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/stretchr/testify/require"
+)
+
+// waitBriefly reports whether a call to provider may start within 50ms.
+func waitBriefly(t *testing.T, ctx context.Context, l *RateLimiter, provider string) bool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	return l.Wait(ctx, provider) == nil
+}
+
+func TestUnit_RateLimiter_PerProvider(t *testing.T) {
+	l, err := NewRateLimiter(map[string]int{"openai": 2, AnyProvider: 1})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.True(t, waitBriefly(t, ctx, l, "openai"))
+	require.True(t, waitBriefly(t, ctx, l, "openai"))
+	require.False(t, waitBriefly(t, ctx, l, "openai"), "third call within the minute must wait")
+
+	// Other providers have their own budget of the "*" limit.
+	require.True(t, waitBriefly(t, ctx, l, "gemini"))
+	require.False(t, waitBriefly(t, ctx, l, "gemini"))
+	require.True(t, waitBriefly(t, ctx, l, "vllm"))
+	require.True(t, waitBriefly(t, ctx, l, ""))
+}
+
+// resolvingRepo serves every call with provider, like a repo resolving
+// the request to it.
+type resolvingRepo struct {
+	llmrepo.ModelRepo
+	provider string
+}
+
+func (r *resolvingRepo) Chat(ctx context.Context, _ llmrepo.Request, _ []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	if err := llmrepo.PassCallGate(ctx, r.provider); err != nil {
+		return libmodelprovider.ChatResult{}, llmrepo.Meta{}, err
+	}
+	return libmodelprovider.ChatResult{}, llmrepo.Meta{ProviderType: r.provider}, nil
+}
+
+func TestUnit_RateLimitedRepo_WaitsForResolvedProvider(t *testing.T) {
+	l, err := NewRateLimiter(map[string]int{"openai": 1, "gemini": 1})
+	require.NoError(t, err)
+	repo := &rateLimitedRepo{ModelRepo: &resolvingRepo{provider: "openai"}, limiter: l}
+	req := llmrepo.Request{ProviderTypes: []string{"openai", "gemini"}}
+	msgs := []libmodelprovider.Message{{Role: "user", Content: "hi"}}
+
+	_, _, err = repo.Chat(context.Background(), req, msgs)
+	require.NoError(t, err)
+	require.True(t, waitBriefly(t, context.Background(), l, "gemini"), "only the provider serving the call is charged")
+	require.False(t, waitBriefly(t, context.Background(), l, "openai"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = repo.Chat(ctx, req, msgs)
+	require.ErrorContains(t, err, "rate limit of openai")
+}
+
+func TestUnit_RateLimiter_Concurrent(t *testing.T) {
+	l, err := NewRateLimiter(map[string]int{"openai": 5})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	started := 0
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.Wait(ctx, "openai") == nil {
+				mu.Lock()
+				started++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 5, started)
+}
+
+func TestUnit_RateLimiter_ChainOverride(t *testing.T) {
+	l, err := NewRateLimiter(map[string]int{"openai": 100})
+	require.NoError(t, err)
+	chain := &TaskChainDefinition{ID: "summarize", RateLimits: map[string]int{"openai": 1}}
+	chainCtx := withChainRateLimits(context.Background(), chain)
+
+	require.True(t, waitBriefly(t, chainCtx, l, "openai"))
+	require.False(t, waitBriefly(t, chainCtx, l, "openai"), "chain limit applies to every run of the chain")
+	require.True(t, waitBriefly(t, context.Background(), l, "openai"), "other chains keep the server limit")
+
+	// Without server limits, the chain limit still applies.
+	unlimited, err := NewRateLimiter(nil)
+	require.NoError(t, err)
+	require.True(t, waitBriefly(t, chainCtx, unlimited, "ollama"))
+	require.True(t, waitBriefly(t, chainCtx, unlimited, "ollama"), "ollama has no limit in the chain")
+}
+
+func TestUnit_RateLimiter_InvalidLimits(t *testing.T) {
+	_, err := NewRateLimiter(map[string]int{"openai": 0})
+	require.True(t, errors.Is(err, errdefs.ErrBadRequest))
+
+	chain := &TaskChainDefinition{
+		ID:         "c",
+		RateLimits: map[string]int{"openai": -1},
+		Tasks: []TaskDefinition{{
+			ID:         "a",
+			Handler:    HandleNoop,
+			Transition: TaskTransition{Branches: []TransitionBranch{{Operator: OpDefault, Goto: TermEnd}}},
+		}},
+	}
+	diags := ValidateChain(chain)
+	require.True(t, diags.HasErrors())
+	require.Equal(t, "rate_limits", diags[0].Field)
+}
@@ -0,0 +1,198 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/contenox/contenox/libtracker"
+)
+
+// Redactor masks secrets in what the engine records about an execution: the
+// input, output, transition and error of captured steps and the transition and
+// error of task events. It does not change the data flowing between tasks;
+// use a guardrail for that.
+type Redactor struct {
+	patterns []redactPattern
+	// Replacement substitutes matches. When empty, matches are replaced with
+	// "[REDACTED:<pattern>]".
+	Replacement string
+}
+
+// redactPattern is a pattern to mask. With keepPrefix set, the text of its
+// first group is kept in front of the replacement.
+type redactPattern struct {
+	label      string
+	re         *regexp.Regexp
+	keepPrefix bool
+}
+
+// defaultRedactPatterns match common credentials: API keys and tokens of
+// well-known services, bearer tokens, JWTs, private key blocks and
+// password/secret/token assignments, whose key is kept.
+var defaultRedactPatterns = []redactPattern{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), false},
+	{"api_key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`), false},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), false},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), false},
+	{"slack_token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), false},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), false},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`), false},
+	{"bearer", regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/=-]{16,}`), true},
+	{"secret", regexp.MustCompile(`(?i)\b((?:password|passwd|pwd|secret|client_secret|api[_-]?key|access[_-]?token|auth[_-]?token|token)["']?\s*[:=]\s*["']?)[^\s"',;]{4,}`), true},
+}
+
+// NewRedactor compiles patterns into a Redactor replacing matches with
+// "[REDACTED]". With defaults set, common credentials are masked as well.
+func NewRedactor(defaults bool, patterns ...string) (*Redactor, error) {
+	r := &Redactor{Replacement: "[REDACTED]"}
+	if defaults {
+		r.patterns = append(r.patterns, defaultRedactPatterns...)
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, redactPattern{label: p, re: re})
+	}
+	return r, nil
+}
+
+// Redact returns s with every match of the redactor's patterns masked. A nil
+// Redactor returns s unchanged.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, p := range r.patterns {
+		if !p.re.MatchString(s) {
+			continue
+		}
+		replacement := r.Replacement
+		if replacement == "" {
+			replacement = "[REDACTED:" + p.label + "]"
+		}
+		if !p.keepPrefix {
+			s = p.re.ReplaceAllLiteralString(s, replacement)
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			return p.re.FindStringSubmatch(match)[1] + replacement
+		})
+	}
+	return s
+}
+
+// RedactStep returns step with its input, output, transition and error
+// message masked.
+func (r *Redactor) RedactStep(step CapturedStateUnit) CapturedStateUnit {
+	if r == nil {
+		return step
+	}
+	step.Input = r.Redact(step.Input)
+	step.Output = r.Redact(step.Output)
+	step.Transition = r.Redact(step.Transition)
+	step.Error.Error = r.Redact(step.Error.Error)
+	return step
+}
+
+type redactorKey struct{}
+
+// WithRedactor makes environments created by NewEnv with the returned context
+// mask what they record about executions with r: the captured steps returned
+// as history and the transitions and errors of published task events.
+func WithRedactor(ctx context.Context, r *Redactor) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, redactorKey{}, r)
+}
+
+func redactorFromContext(ctx context.Context) *Redactor {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(redactorKey{}).(*Redactor)
+	return r
+}
+
+// redactingStackTrace masks every step before it is recorded.
+type redactingStackTrace struct {
+	StackTrace
+	redactor *Redactor
+}
+
+func (s redactingStackTrace) RecordStep(step CapturedStateUnit) {
+	s.StackTrace.RecordStep(s.redactor.RedactStep(step))
+}
+
+// redactingEventSink masks the transition and error of events before they
+// are published. Streamed content is left as is: it is the answer the
+// caller asked for, and secrets split across chunks cannot be matched.
+type redactingEventSink struct {
+	TaskEventSink
+	redactor *Redactor
+}
+
+func (s redactingEventSink) PublishTaskEvent(ctx context.Context, event TaskEvent) error {
+	event.Transition = s.redactor.Redact(event.Transition)
+	event.Error = s.redactor.Redact(event.Error)
+	return s.TaskEventSink.PublishTaskEvent(ctx, event)
+}
+
+// redactingTracker masks the errors and changed data reported to the
+// activity tracker, which logs or exports them like the execution history.
+type redactingTracker struct {
+	libtracker.ActivityTracker
+	redactor *Redactor
+}
+
+func (t redactingTracker) Start(ctx context.Context, operation, subject string, kvArgs ...any) (func(error), func(string, any), func()) {
+	for i, arg := range kvArgs {
+		if s, ok := arg.(string); ok {
+			kvArgs[i] = t.redactor.Redact(s)
+		}
+	}
+	reportErr, reportChange, end := t.ActivityTracker.Start(ctx, operation, subject, kvArgs...)
+	return func(err error) {
+			if err != nil {
+				if msg := t.redactor.Redact(err.Error()); msg != err.Error() {
+					err = redactedError{msg: msg, err: err}
+				}
+			}
+			reportErr(err)
+		}, func(id string, data any) {
+			reportChange(id, t.redactor.redactValue(data))
+		}, end
+}
+
+// redactValue masks v for logging: strings directly, anything else through
+// its JSON encoding, which replaces v only when something was masked.
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.Redact(v)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	if masked := r.Redact(string(raw)); masked != string(raw) {
+		return json.RawMessage(masked)
+	}
+	return v
+}
+
+// redactedError carries a masked message but still unwraps to the original
+// error, so errors.Is keeps working for whoever inspects it.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e redactedError) Error() string { return e.msg }
+func (e redactedError) Unwrap() error { return e.err }
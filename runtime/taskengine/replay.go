@@ -0,0 +1,234 @@
+package taskengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReplayStep is the result of one task execution captured for replay.
+type ReplayStep struct {
+	TaskID string `json:"taskId"`
+	// InputKey identifies the task input, so replay can tell apart executions
+	// of the same task (e.g. foreach iterations) that finish in another order.
+	InputKey   string          `json:"inputKey"`
+	Output     json.RawMessage `json:"output,omitempty"`
+	OutputType DataType        `json:"outputType" openapi_include_type:"string"`
+	Transition string          `json:"transition"`
+	Error      string          `json:"error,omitempty"`
+	// Timeout is set when the task ran into its own timeout.
+	Timeout bool `json:"timeout,omitempty"`
+}
+
+// ReplayBundle holds the task results of a recorded run, in the order the
+// tasks finished.
+type ReplayBundle struct {
+	Steps []ReplayStep `json:"steps"`
+}
+
+// ReplayRecorder captures the result of every task ExecEnv runs, including
+// parallel branches and foreach iterations. A chain started by a task itself,
+// e.g. from a hook, is recorded only as that task's result. Safe for
+// concurrent use.
+type ReplayRecorder struct {
+	mu    sync.Mutex
+	steps []ReplayStep
+}
+
+// NewReplayRecorder returns an empty recorder; attach it with
+// WithReplayRecorder.
+func NewReplayRecorder() *ReplayRecorder {
+	return &ReplayRecorder{}
+}
+
+// Bundle returns the steps recorded so far.
+func (r *ReplayRecorder) Bundle() *ReplayBundle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &ReplayBundle{Steps: append([]ReplayStep(nil), r.steps...)}
+}
+
+func (r *ReplayRecorder) record(ctx context.Context, task *TaskDefinition, input any, output any, outputType DataType, transition string, err error) {
+	step := ReplayStep{
+		TaskID:     task.ID,
+		InputKey:   replayInputKey(task.ID, input),
+		OutputType: outputType,
+		Transition: transition,
+	}
+	if err != nil {
+		step.Error = err.Error()
+		step.Timeout = errors.Is(context.Cause(ctx), ErrTaskTimeout)
+	} else if step.Output, err = json.Marshal(output); err != nil {
+		step.Output = nil
+		step.Error = fmt.Sprintf("record output: %v", err)
+	}
+	r.mu.Lock()
+	r.steps = append(r.steps, step)
+	r.mu.Unlock()
+}
+
+// Replayer answers task executions from a ReplayBundle instead of running
+// them. Safe for concurrent use.
+type Replayer struct {
+	mu    sync.Mutex
+	steps []ReplayStep
+	used  []bool
+}
+
+// NewReplayer returns a replayer for bundle; attach it with WithReplay.
+func NewReplayer(bundle *ReplayBundle) *Replayer {
+	var steps []ReplayStep
+	if bundle != nil {
+		steps = bundle.Steps
+	}
+	return &Replayer{steps: steps, used: make([]bool, len(steps))}
+}
+
+// Unused returns the number of recorded steps the replay did not request,
+// i.e. whether the replayed run took a different path.
+func (r *Replayer) Unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, u := range r.used {
+		if !u {
+			n++
+		}
+	}
+	return n
+}
+
+// next returns the recorded result for task: the first unused step with the
+// same task and input, or else the first unused step of the same task.
+func (r *Replayer) next(task *TaskDefinition, input any) (any, DataType, string, error) {
+	key := replayInputKey(task.ID, input)
+	r.mu.Lock()
+	idx := -1
+	for i, s := range r.steps {
+		if !r.used[i] && s.InputKey == key {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		for i, s := range r.steps {
+			if !r.used[i] && s.TaskID == task.ID {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx >= 0 {
+		r.used[idx] = true
+	}
+	r.mu.Unlock()
+	if idx < 0 {
+		return nil, DataTypeAny, "", fmt.Errorf("replay: no recorded result left for task %s", task.ID)
+	}
+	step := r.steps[idx]
+	if step.Error != "" {
+		return nil, DataTypeAny, step.Transition, &replayedError{msg: step.Error, timeout: step.Timeout}
+	}
+	var output any
+	if err := json.Unmarshal(step.Output, &output); err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("replay: decode output of task %s: %w", task.ID, err)
+	}
+	output, err := ConvertToType(output, step.OutputType)
+	if err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("replay: output of task %s: %w", task.ID, err)
+	}
+	return output, step.OutputType, step.Transition, nil
+}
+
+// replayedError is a recorded task error. A replayed timeout is handled like
+// the task ran into its timeout again.
+type replayedError struct {
+	msg     string
+	timeout bool
+}
+
+func (e *replayedError) Error() string { return e.msg }
+
+func isReplayedTimeout(err error) bool {
+	var replayed *replayedError
+	return errors.As(err, &replayed) && replayed.timeout
+}
+
+func replayInputKey(taskID string, input any) string {
+	data, err := json.Marshal(input)
+	if err != nil {
+		data = fmt.Appendf(nil, "%v", input)
+	}
+	sum := sha256.Sum256(append([]byte(taskID+"\x00"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+type replayRecorderKey struct{}
+type replayerKey struct{}
+
+// WithReplayRecorder attaches rec to ctx so ExecEnv records the result of
+// every task it runs into a ReplayBundle.
+func WithReplayRecorder(ctx context.Context, rec *ReplayRecorder) context.Context {
+	if rec == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, replayRecorderKey{}, rec)
+}
+
+// WithReplay attaches r to ctx so ExecEnv re-executes the chain with the
+// recorded task results instead of calling its TaskExecutor: no model,
+// tool or hook is called, while transitions, variables, compose, guardrails
+// and the other chain logic run as usual. Use it to reproduce a
+// non-deterministic agent failure step by step.
+func WithReplay(ctx context.Context, r *Replayer) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, replayerKey{}, r)
+}
+
+// withoutReplay detaches replay and recording from the context a task runs
+// with, so a chain the task runs itself (e.g. from a hook) is recorded only
+// as the result of that task.
+func withoutReplay(ctx context.Context) context.Context {
+	if ctx.Value(replayerKey{}) == nil && ctx.Value(replayRecorderKey{}) == nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, replayerKey{}, (*Replayer)(nil))
+	return context.WithValue(ctx, replayRecorderKey{}, (*ReplayRecorder)(nil))
+}
+
+// replayExecutor wraps the TaskExecutor of an ExecEnv call in replay or
+// recording mode; with neither attached it returns exec unchanged.
+func replayExecutor(ctx context.Context, exec TaskExecutor) TaskExecutor {
+	if _, wrapped := exec.(*replayingExecutor); wrapped {
+		// Nested chain executions share the wrapper of their parent run.
+		return exec
+	}
+	replayer, _ := ctx.Value(replayerKey{}).(*Replayer)
+	recorder, _ := ctx.Value(replayRecorderKey{}).(*ReplayRecorder)
+	if replayer == nil && recorder == nil {
+		return exec
+	}
+	return &replayingExecutor{inner: exec, replayer: replayer, recorder: recorder}
+}
+
+type replayingExecutor struct {
+	inner    TaskExecutor
+	replayer *Replayer
+	recorder *ReplayRecorder
+}
+
+func (e *replayingExecutor) TaskExec(ctx context.Context, startingTime time.Time, ctxLength int, chainContext *ChainContext, task *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	if e.replayer != nil {
+		return e.replayer.next(task, input)
+	}
+	output, outputType, transition, err := e.inner.TaskExec(withoutReplay(ctx), startingTime, ctxLength, chainContext, task, input, dataType)
+	e.recorder.record(ctx, task, input, output, outputType, transition, err)
+	return output, outputType, transition, err
+}
@@ -0,0 +1,111 @@
+package taskengine_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay_ReproducesRecordedRun(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "replay",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "slow",
+				Handler: taskengine.HandleNoop,
+				Timeout: "20ms",
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpEquals, When: taskengine.TransitionTimeout, Goto: "fallback"},
+						endBranch(),
+					},
+				},
+			},
+			{ID: "fallback", Handler: taskengine.HandleNoop, Transition: gotoBranch("finish")},
+			{ID: "finish", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)},
+		},
+	}
+
+	rec := taskengine.NewReplayRecorder()
+	env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"slow": time.Second}})
+	recordedOut, _, recordedHistory, err := env.ExecEnv(taskengine.WithReplayRecorder(context.Background(), rec), chain, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "finish:fallback:in", recordedOut)
+
+	data, err := json.Marshal(rec.Bundle())
+	require.NoError(t, err)
+	var bundle taskengine.ReplayBundle
+	require.NoError(t, json.Unmarshal(data, &bundle))
+	require.Len(t, bundle.Steps, 3)
+	assert.True(t, bundle.Steps[0].Timeout)
+
+	live := &unreachableExecutor{}
+	replayEnv := setupTestEnv(live)
+	replayer := taskengine.NewReplayer(&bundle)
+	start := time.Now()
+	out, _, history, err := replayEnv.ExecEnv(taskengine.WithReplay(context.Background(), replayer), chain, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 20*time.Millisecond, "replay must not wait for the recorded timeout")
+	assert.Equal(t, recordedOut, out)
+	assert.Zero(t, replayer.Unused())
+	assert.Empty(t, live.ran, "replay must not call the executor")
+	require.Len(t, history, len(recordedHistory))
+	for i := range history {
+		assert.Equal(t, recordedHistory[i].TaskID, history[i].TaskID)
+		assert.Equal(t, recordedHistory[i].Transition, history[i].Transition)
+		assert.Equal(t, recordedHistory[i].Error.Error, history[i].Error.Error)
+	}
+}
+
+func TestReplay_MissingStepFails(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID:    "replay-missing",
+		Tasks: []taskengine.TaskDefinition{{ID: "only", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)}},
+	}
+	env := setupTestEnv(&unreachableExecutor{})
+	_, _, _, err := env.ExecEnv(taskengine.WithReplay(context.Background(), taskengine.NewReplayer(&taskengine.ReplayBundle{})), chain, "in", taskengine.DataTypeString)
+	assert.ErrorContains(t, err, "no recorded result left for task only")
+}
+
+// nestedRunExecutor runs a chain of its own with env for every task, like a
+// hook that starts another chain.
+type nestedRunExecutor struct {
+	env   taskengine.EnvExecutor
+	chain *taskengine.TaskChainDefinition
+}
+
+func (n *nestedRunExecutor) TaskExec(ctx context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, _ taskengine.DataType) (any, taskengine.DataType, string, error) {
+	out, outType, _, err := n.env.ExecEnv(ctx, n.chain, input, taskengine.DataTypeString)
+	return out, outType, "ok", err
+}
+
+func TestReplay_RecordsNestedRunsOnce(t *testing.T) {
+	inner := &taskengine.TaskChainDefinition{
+		ID:    "inner",
+		Tasks: []taskengine.TaskDefinition{{ID: "step", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)}},
+	}
+	outer := &taskengine.TaskChainDefinition{
+		ID:    "outer",
+		Tasks: []taskengine.TaskDefinition{{ID: "hook", Handler: taskengine.HandleNoop, Transition: gotoBranch(taskengine.TermEnd)}},
+	}
+	env := setupTestEnv(&nestedRunExecutor{env: setupTestEnv(&slowExecutor{}), chain: inner})
+
+	rec := taskengine.NewReplayRecorder()
+	out, _, _, err := env.ExecEnv(taskengine.WithReplayRecorder(context.Background(), rec), outer, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "step:in", out)
+	steps := rec.Bundle().Steps
+	require.Len(t, steps, 1, "the nested run is part of the hook's result")
+	assert.Equal(t, "hook", steps[0].TaskID)
+
+	replayer := taskengine.NewReplayer(rec.Bundle())
+	out, _, _, err = setupTestEnv(&unreachableExecutor{}).ExecEnv(taskengine.WithReplay(context.Background(), replayer), outer, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "step:in", out)
+	assert.Zero(t, replayer.Unused())
+}
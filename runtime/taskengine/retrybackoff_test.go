@@ -0,0 +1,26 @@
+package taskengine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_RetryBackoff_Exponential(t *testing.T) {
+	s := retrySchedule{strategy: BackoffExponential, delay: 500 * time.Millisecond, maxDelay: 5 * time.Second}
+	require.Equal(t, 500*time.Millisecond, s.backoff(1))
+	require.Equal(t, 2*time.Second, s.backoff(3))
+	require.Equal(t, 5*time.Second, s.backoff(5))
+	require.Equal(t, 5*time.Second, s.backoff(math.MaxInt))
+
+	// Without max_delay the wait is capped instead of overflowing.
+	s.maxDelay = 0
+	require.Equal(t, defaultRetryMaxDelay, s.backoff(100))
+	s.delay = 2 * time.Hour
+	require.Equal(t, 2*time.Hour, s.backoff(100))
+
+	s = retrySchedule{strategy: BackoffExponential, delay: time.Second, maxDelay: time.Duration(math.MaxInt64)}
+	require.Positive(t, s.backoff(100))
+}
@@ -0,0 +1,83 @@
+package taskengine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+)
+
+// servedModel records which model served the LLM calls of one task attempt
+// and the tokens they used. The environment attaches one per attempt and
+// copies it into the CapturedStateUnit; the last call of the attempt wins
+// the model, tokens and cost are summed over all calls.
+type servedModel struct {
+	mu           sync.Mutex
+	meta         llmrepo.Meta
+	fallbackFrom []string
+	inputTokens  int
+	outputTokens int
+	cost         float64
+	unpriced     bool
+}
+
+func (s *servedModel) record(meta llmrepo.Meta, fallbackFrom []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.meta = meta
+	s.fallbackFrom = fallbackFrom
+	s.mu.Unlock()
+}
+
+func (s *servedModel) addUsage(inputTokens, outputTokens int, cost float64, priced bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.inputTokens += inputTokens
+	s.outputTokens += outputTokens
+	s.cost += cost
+	if !priced && inputTokens+outputTokens > 0 {
+		s.unpriced = true
+	}
+	s.mu.Unlock()
+}
+
+// apply copies the recorded model and usage into step.
+func (s *servedModel) apply(step *CapturedStateUnit) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	step.Model = s.meta.ModelName
+	step.Provider = s.meta.ProviderType
+	step.BackendID = s.meta.BackendID
+	step.FallbackFrom = s.fallbackFrom
+	step.InputTokens = s.inputTokens
+	step.OutputTokens = s.outputTokens
+	step.Cost = s.cost
+	step.Priced = s.inputTokens+s.outputTokens > 0 && !s.unpriced
+}
+
+type servedModelKey struct{}
+
+func withServedModel(ctx context.Context, s *servedModel) context.Context {
+	return context.WithValue(ctx, servedModelKey{}, s)
+}
+
+// recordServedModel notes the model that answered an LLM call on the
+// context-bound recorder, if any.
+func recordServedModel(ctx context.Context, meta llmrepo.Meta, fallbackFrom []string) {
+	s, _ := ctx.Value(servedModelKey{}).(*servedModel)
+	s.record(meta, fallbackFrom)
+}
+
+// recordServedUsage adds the tokens and cost of an LLM call to the
+// context-bound recorder, if any.
+func recordServedUsage(ctx context.Context, inputTokens, outputTokens int, cost float64, priced bool) {
+	s, _ := ctx.Value(servedModelKey{}).(*servedModel)
+	s.addUsage(inputTokens, outputTokens, cost, priced)
+}
@@ -0,0 +1,89 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// defaultStructuredAttempts is used when StructuredOutputConfig.MaxAttempts is unset.
+const defaultStructuredAttempts = 3
+
+// compileStructuredSchema turns the task's JSON Schema into a validator.
+func compileStructuredSchema(cfg *StructuredOutputConfig) (*openapi3.Schema, []byte, error) {
+	if cfg == nil || len(cfg.Schema) == 0 {
+		return nil, nil, fmt.Errorf("prompt_to_structured task requires structured.schema %w", errdefs.ErrBadRequest)
+	}
+	raw, err := json.Marshal(cfg.Schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid structured schema: %w", err)
+	}
+	var schema openapi3.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, nil, fmt.Errorf("invalid structured schema: %v %w", err, errdefs.ErrBadRequest)
+	}
+	return &schema, raw, nil
+}
+
+// parseStructuredResponse extracts the JSON value from a model response and
+// validates it against schema.
+func parseStructuredResponse(schema *openapi3.Schema, response string) (any, error) {
+	candidate := StripCodeFences(response)
+	switch {
+	case schema.Type.Is(openapi3.TypeObject):
+		candidate = ExtractJSONObject(response)
+	case schema.Type.Is(openapi3.TypeArray):
+		candidate = ExtractJSONArray(response)
+	}
+	var value any
+	if err := json.Unmarshal([]byte(candidate), &value); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	if err := schema.VisitJSON(value, openapi3.MultiErrors()); err != nil {
+		return nil, fmt.Errorf("response does not match the schema: %w", err)
+	}
+	return value, nil
+}
+
+// structured prompts the model for a JSON value conforming to the task's schema.
+// Invalid responses are sent back to the model together with the validation
+// error until StructuredOutputConfig.MaxAttempts is exhausted.
+func (exe *SimpleExec) structured(ctx context.Context, systemInstruction string, llmCall LLMExecutionConfig, cfg *StructuredOutputConfig, prompt string, ctxLength int) (any, error) {
+	schema, rawSchema, err := compileStructuredSchema(cfg)
+	if err != nil {
+		return nil, err
+	}
+	attempts := cfg.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultStructuredAttempts
+	}
+
+	basePrompt := fmt.Sprintf("%s\n\nRespond only with JSON that conforms to this JSON Schema, without any explanation:\n%s", prompt, rawSchema)
+	current := basePrompt
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		response, err := exe.Prompt(ctx, systemInstruction, llmCall, current, ctxLength)
+		if err != nil {
+			return nil, fmt.Errorf("structured: prompt execution failed: %w", err)
+		}
+		value, err := parseStructuredResponse(schema, response)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+
+		var b strings.Builder
+		b.WriteString(basePrompt)
+		b.WriteString("\n\nYour previous response was:\n")
+		b.WriteString(response)
+		b.WriteString("\n\nIt was rejected: ")
+		b.WriteString(err.Error())
+		b.WriteString("\nReturn corrected JSON only.")
+		current = b.String()
+	}
+	return nil, fmt.Errorf("structured output invalid after %d attempts: %w", attempts, lastErr)
+}
@@ -0,0 +1,80 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func structuredTask(maxAttempts int) *taskengine.TaskDefinition {
+	return &taskengine.TaskDefinition{
+		ID:      "extract",
+		Handler: taskengine.HandlePromptToStructured,
+		Structured: &taskengine.StructuredOutputConfig{
+			Schema: map[string]any{
+				"type":     "object",
+				"required": []any{"title", "score"},
+				"properties": map[string]any{
+					"title": map[string]any{"type": "string"},
+					"score": map[string]any{"type": "integer", "minimum": 0},
+				},
+			},
+			MaxAttempts: maxAttempts,
+		},
+		ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "test-model"},
+	}
+}
+
+func TestUnit_Structured_RetriesWithValidationError(t *testing.T) {
+	responses := []string{
+		`{"title": "x"}`,
+		"```json\n{\"title\": \"report\", \"score\": 7}\n```",
+	}
+	var prompts []string
+	repo := &mockModelRepo{
+		promptFunc: func(_ context.Context, _ llmrepo.Request, _ string, _ float32, prompt string) (string, llmrepo.Meta, error) {
+			prompts = append(prompts, prompt)
+			r := responses[0]
+			responses = responses[1:]
+			return r, llmrepo.Meta{}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	out, outType, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, structuredTask(0), "summarize", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeJSON, outType)
+	assert.Equal(t, "valid", transition)
+	assert.Equal(t, map[string]any{"title": "report", "score": float64(7)}, out)
+
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], `"required":["title","score"]`)
+	assert.Contains(t, prompts[1], `{"title": "x"}`)
+	assert.Contains(t, prompts[1], "score")
+}
+
+func TestUnit_Structured_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	repo := &mockModelRepo{
+		promptFunc: func(context.Context, llmrepo.Request, string, float32, string) (string, llmrepo.Meta, error) {
+			calls++
+			return "not json", llmrepo.Meta{}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	_, _, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, structuredTask(2), "summarize", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid after 2 attempts")
+	assert.Equal(t, "invalid", transition)
+	assert.Equal(t, 2, calls)
+}
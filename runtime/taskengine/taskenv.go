@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"regexp"
 	"strconv"
 	"strings"
@@ -27,6 +28,9 @@ const (
 	DataTypeJSON
 	DataTypeChatHistory
 	DataTypeNil
+	DataTypeVector
+	// DataTypeFile is a binary payload carried as a File.
+	DataTypeFile
 )
 
 // String returns the string representation of the data type.
@@ -44,6 +48,10 @@ func (d *DataType) String() string {
 		return "chat_history"
 	case DataTypeNil:
 		return "nil"
+	case DataTypeVector:
+		return "vector"
+	case DataTypeFile:
+		return "file"
 	default:
 		return "unknown"
 	}
@@ -64,6 +72,10 @@ func DataTypeFromString(s string) (DataType, error) {
 		return DataTypeChatHistory, nil
 	case "nil":
 		return DataTypeNil, nil
+	case "vector":
+		return DataTypeVector, nil
+	case "file":
+		return DataTypeFile, nil
 	default:
 		return DataTypeAny, fmt.Errorf("unknown data type: %s", s)
 	}
@@ -140,6 +152,8 @@ type SimpleEnv struct {
 	inspector    Inspector
 	toolsProvider ToolsRepo
 	eventSink    TaskEventSink
+	redactor     *Redactor
+	guardrails   []Guardrail
 }
 
 // NewEnv creates a new SimpleEnv with the given tracker and task executor.
@@ -153,13 +167,20 @@ func NewEnv(
 	if tracker == nil {
 		tracker = libtracker.NoopTracker{}
 	}
-	return &SimpleEnv{
+	env := &SimpleEnv{
 		exec:         exec,
 		tracker:      tracker,
 		inspector:    inspector,
 		toolsProvider: toolsProvider,
 		eventSink:    taskEventSinkFromContext(ctx),
-	}, nil
+		redactor:     redactorFromContext(ctx),
+		guardrails:   guardrailsFromContext(ctx),
+	}
+	if env.redactor != nil {
+		env.eventSink = redactingEventSink{TaskEventSink: env.eventSink, redactor: env.redactor}
+		env.tracker = redactingTracker{ActivityTracker: env.tracker, redactor: env.redactor}
+	}
+	return env, nil
 }
 
 type ChainContext struct {
@@ -175,15 +196,23 @@ type ToolWithResolution struct {
 
 // ExecEnv executes the given chain with the provided input.
 func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, input any, dataType DataType) (result any, resultType DataType, history []CapturedStateUnit, retErr error) {
+	chain = ResolveChainDefaults(chain)
+	env.exec = replayExecutor(ctx, env.exec)
 	reportErrChain, _, endChain := env.tracker.Start(ctx, "chain_exec", chain.ID, "chain_id", chain.ID)
 	defer endChain()
 
 	stack := env.inspector.Start(ctx)
+	if env.redactor != nil {
+		stack = redactingStackTrace{StackTrace: stack, redactor: env.redactor}
+	}
 	defer func() {
 		history = stack.GetExecutionHistory()
 		chainEvent := NewTaskEvent(ctx, TaskEventChainCompleted)
 		chainEvent.ChainID = chain.ID
 		chainEvent.OutputType = resultType.String()
+		if usage := ChainCost(history); usage.InputTokens+usage.OutputTokens > 0 {
+			chainEvent.Usage = &usage
+		}
 		if retErr != nil {
 			chainEvent.Kind = TaskEventChainFailed
 			chainEvent.Error = retErr.Error()
@@ -195,16 +224,66 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 	chainStarted.ChainID = chain.ID
 	publishTaskEventBestEffort(ctx, env.eventSink, chainStarted)
 
+	checkpoints := checkpointingFromContext(ctx)
+	resumed, err := checkpoints.load(ctx, chain)
+	if err != nil {
+		return nil, DataTypeAny, stack.GetExecutionHistory(), err
+	}
+	// A resumed run continues from its checkpoint; the input it was started
+	// with was checked then, and the caller may not pass it again.
+	if resumed == nil {
+		input, dataType, err = checkChainInput(chain.Input, input, dataType)
+		if err != nil {
+			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("chain %s: %w", chain.ID, err)
+		}
+	}
+
+	// store holds the named outputs written via TaskDefinition.StoreAs.
+	store := map[string]any{}
 	vars := map[string]any{
-		"input": input,
+		"input":      input,
+		chainVarsKey: store,
 	}
 	varTypes := map[string]DataType{"input": dataType}
 	startingTime := time.Now().UTC()
-	var err error
+
+	chainTimeout, err := parseTimeout(chain.Timeout)
+	if err != nil {
+		return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("chain %s: %w", chain.ID, err)
+	}
+	if chainTimeout > 0 {
+		var cancelChain context.CancelFunc
+		ctx, cancelChain = context.WithTimeoutCause(ctx, chainTimeout, ErrChainTimeout)
+		defer cancelChain()
+	}
+	if err := checkRateLimits(chain.RateLimits); err != nil {
+		return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("chain %s: %w", chain.ID, err)
+	}
+	ctx = withChainRateLimits(ctx, chain)
+	wrapUpAfter, err := resolveWrapUp(chain)
+	if err != nil {
+		return nil, DataTypeAny, stack.GetExecutionHistory(), err
+	}
+	// A resumed run keeps the deadline of the run it continues.
+	var wrapUpDeadline *time.Time
+	if wrapUpAfter > 0 {
+		deadline := time.Now().Add(wrapUpAfter)
+		if resumed != nil && resumed.WrapUpDeadline != nil {
+			deadline = *resumed.WrapUpDeadline
+		}
+		wrapUpDeadline = &deadline
+		ctx = withWrapUpDeadline(ctx, deadline)
+	}
+	// wrappingUp is set once the wrap-up task ran or was scheduled, so the
+	// chain is routed to it at most once.
+	wrappingUp := resumed != nil && resumed.WrappingUp
 
 	if err := validateChain(chain.Tasks); err != nil {
 		return nil, DataTypeAny, stack.GetExecutionHistory(), err
 	}
+	if err := checkFileSize(chain, input, dataType); err != nil {
+		return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("chain %s input: %w", chain.ID, err)
+	}
 
 	currentTask, err := findTaskByID(chain.Tasks, chain.Tasks[0].ID)
 	if err != nil {
@@ -217,6 +296,23 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 	var outputType DataType = dataType
 	var taskErr error
 	var inputVar string
+	completedSteps := 0
+	// approval and clarification are the decision or answer recorded for
+	// the task the run paused at.
+	var approval *ApprovalDecision
+	var clarification *ClarificationAnswer
+	if resumed != nil {
+		currentTask, err = findTaskByID(chain.Tasks, resumed.NextTaskID)
+		if err != nil {
+			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("resume task %s not found: %w", resumed.NextTaskID, err)
+		}
+		output, outputType = resumed.Output, resumed.OutputType
+		vars, varTypes = resumed.Vars, resumed.VarTypes
+		store = vars[chainVarsKey].(map[string]any)
+		completedSteps = resumed.CompletedSteps
+		approval = resumed.Approval
+		clarification = resumed.Clarification
+	}
 
 	chainContext := &ChainContext{
 		Tools:       map[string]ToolWithResolution{},
@@ -274,9 +370,15 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 
 	for {
 		if ctx.Err() != nil {
+			if errors.Is(context.Cause(ctx), ErrChainTimeout) {
+				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: %w after %s", currentTask.ID, ErrChainTimeout, chain.Timeout)
+			}
 			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: context canceled", currentTask.ID)
 		}
 
+		// stepOutput is the state a queued run resumes the current task with.
+		stepOutput, stepOutputType := output, outputType
+
 		// Determine task input
 		taskInput := output
 		taskInputType := outputType
@@ -295,33 +397,84 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			}
 		}
 
-		// Render prompt template if exists
-		if currentTask.PromptTemplate != "" {
-			rendered, err := renderTemplate(currentTask.PromptTemplate, vars)
-			if err != nil {
-				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: template error: %v", currentTask.ID, err)
+		// Guardrails check the input once; a rejected task does not run.
+		prepared, err := env.prepareStep(ctx, currentTask, vars, taskInput, taskInputType)
+		if err != nil {
+			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: %w", currentTask.ID, err)
+		}
+		taskInput, taskInputType = prepared.input, prepared.inputType
+		retrySched, err := resolveRetry(currentTask)
+		if err != nil {
+			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: %w", currentTask.ID, err)
+		}
+		maxRetries := retrySched.maxAttempts - 1
+
+		// An await_approval task pauses the run until a decision is recorded
+		// and the run is resumed.
+		var decision *ApprovalDecision
+		if currentTask.Handler == HandleAwaitApproval {
+			if approval == nil || approval.TaskID != currentTask.ID {
+				return nil, DataTypeAny, stack.GetExecutionHistory(), checkpoints.requestApproval(ctx, &Checkpoint{
+					Chain:          chain,
+					NextTaskID:     currentTask.ID,
+					Output:         output,
+					OutputType:     outputType,
+					Vars:           vars,
+					VarTypes:       varTypes,
+					CompletedSteps: completedSteps,
+					WrapUpDeadline: wrapUpDeadline,
+					WrappingUp:     wrappingUp,
+				}, currentTask, taskInput)
+			}
+			decision, approval = approval, nil
+		}
+
+		// A clarify task pauses the run with its question until an answer is
+		// recorded and the run is resumed. A run without checkpoints cannot
+		// pause, so it ends with the question and the caller answers with
+		// its next input.
+		var answer *ClarificationAnswer
+		var question string
+		asking := false
+		if currentTask.Handler == HandleClarify {
+			question = approvalMessage(taskInput)
+			switch {
+			case clarification != nil && clarification.TaskID == currentTask.ID:
+				answer, clarification = clarification, nil
+			case checkpoints != nil:
+				return nil, DataTypeAny, stack.GetExecutionHistory(), checkpoints.requestClarification(ctx, &Checkpoint{
+					Chain:          chain,
+					NextTaskID:     currentTask.ID,
+					Output:         output,
+					OutputType:     outputType,
+					Vars:           vars,
+					VarTypes:       varTypes,
+					CompletedSteps: completedSteps,
+					WrapUpDeadline: wrapUpDeadline,
+					WrappingUp:     wrappingUp,
+				}, currentTask, question)
+			default:
+				asking = true
 			}
-			taskInput = rendered
-			taskInputType = DataTypeString
 		}
-		maxRetries := max(currentTask.RetryOnFailure, 0)
 
+		// variant is the branch a weighted transition drew for the task.
+		var variant *TransitionBranch
 		for retry := 0; retry <= maxRetries; retry++ {
 			if stack.HasBreakpoint(currentTask.ID) {
 				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: breakpoint set", currentTask.ID)
 			}
+			if retry > 0 {
+				if err := retrySched.wait(ctx, retry); err != nil {
+					return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: retry %d aborted: %w", currentTask.ID, retry, err)
+				}
+			}
 
 			// Keep task execution attached to the caller so cancellation from
 			// Ctrl+C, request shutdown, or parent timeouts stops in-flight work.
-			taskCtx := ctx
-
-			var cancel context.CancelFunc
-			if currentTask.Timeout != "" {
-				timeout, err := time.ParseDuration(currentTask.Timeout)
-				if err != nil {
-					return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: invalid timeout: %v", currentTask.ID, err)
-				}
-				taskCtx, cancel = context.WithTimeout(taskCtx, timeout)
+			taskCtx, cancel, err := withTaskTimeout(ctx, currentTask)
+			if err != nil {
+				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: %w", currentTask.ID, err)
 			}
 			taskCtx = WithTaskEventScope(taskCtx, TaskEventScope{
 				ChainID:     chain.ID,
@@ -329,6 +482,8 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 				TaskHandler: currentTask.Handler.String(),
 				Retry:       retry,
 			})
+			served := &servedModel{}
+			taskCtx = withServedModel(taskCtx, served)
 			stepStarted := NewTaskEvent(taskCtx, TaskEventStepStarted)
 			publishTaskEventBestEffort(taskCtx, env.eventSink, stepStarted)
 			reportErrAttempt, reportChangeAttempt, endAttempt := env.tracker.Start(
@@ -341,15 +496,55 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 
 			startTime := time.Now().UTC()
 
-			output, outputType, transitionEval, taskErr = env.exec.TaskExec(taskCtx, startingTime, int(chain.TokenLimit), chainContext, currentTask, taskInput, taskInputType)
+			var branchResults []subtaskResult
+			var verdicts []GuardrailVerdict
+			output, outputType, transitionEval, verdicts, taskErr = env.runAttempt(taskCtx, chain, prepared, func(taskCtx context.Context) (any, DataType, string, error) {
+				switch currentTask.Handler {
+				case HandleParallel:
+					merged, results, err := env.execParallel(taskCtx, startingTime, chain, chainContext, currentTask, vars, taskInput, taskInputType)
+					branchResults = results
+					for _, res := range results {
+						for _, st := range res.steps {
+							stack.RecordStep(st)
+						}
+					}
+					if err != nil {
+						return nil, DataTypeAny, "failed", err
+					}
+					return merged, DataTypeJSON, "ok", nil
+				case HandleForEach:
+					collected, iterSteps, err := env.execForEach(taskCtx, startingTime, chain, chainContext, currentTask, vars, taskInput)
+					for _, st := range iterSteps {
+						stack.RecordStep(st)
+					}
+					if err != nil {
+						return nil, DataTypeAny, "failed", err
+					}
+					return collected, DataTypeJSON, "ok", nil
+				case HandleAwaitApproval:
+					if !decision.Approved && !handlesFailure(currentTask.Transition, TransitionRejected) {
+						return taskInput, taskInputType, decision.transition(), fmt.Errorf("%w: %s", ErrApprovalRejected, decision.Reason)
+					}
+					return taskInput, taskInputType, decision.transition(), nil
+				case HandleClarify:
+					conversation := taskInput
+					if _, ok := conversation.(ChatHistory); !ok {
+						// A prompt_template replaces the input with the question;
+						// the conversation is then the previous output.
+						conversation = stepOutput
+					}
+					output, outputType, transition := clarifyOutput(conversation, question, answer)
+					return output, outputType, transition, nil
+				default:
+					return env.exec.TaskExec(taskCtx, startingTime, int(chain.TokenLimit), chainContext, prepared.task, taskInput, taskInputType)
+				}
+			})
 			if taskErr != nil {
 				taskErr = fmt.Errorf("task %s: %w", currentTask.ID, taskErr)
 				reportErrAttempt(taskErr)
 			}
 			endAttempt()
-			if cancel != nil {
-				cancel()
-			}
+			cancel()
 			duration := time.Since(startTime)
 			errState := ErrorResponse{
 				ErrorInternal: taskErr,
@@ -359,14 +554,24 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			}
 			// Record execution step
 			step := CapturedStateUnit{
-				TaskID:      currentTask.ID,
-				TaskHandler: currentTask.Handler.String(),
-				InputType:   taskInputType,
-				OutputType:  outputType,
-				InputVar:    inputVar,
-				Transition:  transitionEval,
-				Duration:    duration,
-				Error:       errState,
+				TaskID:        currentTask.ID,
+				TaskHandler:   currentTask.Handler.String(),
+				InputType:     taskInputType,
+				OutputType:    outputType,
+				InputVar:      inputVar,
+				Transition:    transitionEval,
+				Duration:      duration,
+				Error:         errState,
+				Attempt:       retry + 1,
+				MaxAttempts:   retrySched.maxAttempts,
+				Guardrails:    verdicts,
+				PromptVariant: prepared.promptVariant,
+			}
+			served.apply(&step)
+			if taskErr == nil {
+				if variant = drawWeightedBranch(currentTask.Transition); variant != nil {
+					step.Variant = variantName(variant)
+				}
 			}
 			if chain.Debug {
 				step.Input = fmt.Sprintf("%v", taskInput)
@@ -381,6 +586,8 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			stepEvent := NewTaskEvent(taskCtx, TaskEventStepCompleted)
 			stepEvent.OutputType = outputType.String()
 			stepEvent.Transition = transitionEval
+			stepEvent.Variant = step.Variant
+			stepEvent.PromptVariant = step.PromptVariant
 			// Drain any UI hints emitted by tools during this step (Phase 5
 			// of the canvas-vision plan). Hints go out exactly once per
 			// publish — Drain() also clears them so the next step starts
@@ -397,16 +604,59 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 				stepEvent.OutputType = ""
 				publishTaskEventBestEffort(taskCtx, env.eventSink, stepEvent)
 				reportErrAttempt(taskErr)
+				if errors.Is(taskErr, ErrGuardrailRejected) {
+					break
+				}
 				continue
 			}
 			publishTaskEventBestEffort(taskCtx, env.eventSink, stepEvent)
 
+			if retry < maxRetries && retrySched.retriesOn(transitionEval) {
+				continue
+			}
+
+			// Expose every branch output as its own variable for downstream templates.
+			for _, res := range branchResults {
+				vars[res.taskID] = res.output
+				varTypes[res.taskID] = res.outputType
+			}
+
 			// Report successful attempt
 			reportChangeAttempt(currentTask.ID, output)
 			break
 		}
 
+		// A chain that branches on "timeout" or "coercion_failed" continues
+		// with the task input instead of failing.
+		if taskErr != nil && errors.Is(taskErr, ErrTaskTimeout) && handlesFailure(currentTask.Transition, TransitionTimeout) {
+			output, outputType, transitionEval, taskErr = taskInput, taskInputType, TransitionTimeout, nil
+		}
+		if taskErr != nil && errors.Is(taskErr, ErrCoercionFailed) && handlesFailure(currentTask.Transition, TransitionCoercionFailed) {
+			output, outputType, transitionEval, taskErr = taskInput, taskInputType, TransitionCoercionFailed, nil
+		}
+
 		if taskErr != nil {
+			if currentTask.Transition.OnError != "" {
+				previousTaskID := currentTask.ID
+				currentTask, err = findTaskByID(chain.Tasks, currentTask.Transition.OnError)
+				if err != nil {
+					return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("error transition target not found: %v", err)
+				}
+				// The error handler gets the error message as its input.
+				output, outputType = taskErr.Error(), DataTypeString
+				vars["previous_output"] = output
+				varTypes["previous_output"] = outputType
+				_, reportChangeErrTransition, endErrTransition := env.tracker.Start(
+					ctx,
+					"next_task",
+					previousTaskID,
+					"next_task", currentTask.ID,
+					"reason", "error",
+				)
+				reportChangeErrTransition(currentTask.ID, taskErr)
+				endErrTransition()
+				continue
+			}
 			if currentTask.Transition.OnFailure != "" {
 				previousTaskID := currentTask.ID
 				currentTask, err = findTaskByID(chain.Tasks, currentTask.Transition.OnFailure)
@@ -425,7 +675,34 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 				endErrTransition() // Fix 2: direct call, not defer — defers inside loops leak
 				continue
 			}
-			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s failed after %d retries: %v", currentTask.ID, maxRetries, taskErr)
+			if chain.Fallback != nil && IsModelUnavailable(taskErr) {
+				reportErrChain(taskErr)
+				finalOutput, outputType, err = env.degrade(ctx, chain, currentTask, taskInput, taskInputType, vars, &Checkpoint{
+					Chain:          chain,
+					NextTaskID:     currentTask.ID,
+					Output:         stepOutput,
+					OutputType:     stepOutputType,
+					Vars:           vars,
+					VarTypes:       varTypes,
+					CompletedSteps: completedSteps,
+					WrapUpDeadline: wrapUpDeadline,
+					WrappingUp:     wrappingUp,
+				}, taskErr)
+				if err != nil {
+					return nil, DataTypeAny, stack.GetExecutionHistory(), err
+				}
+				break
+			}
+			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s failed after %d retries: %w", currentTask.ID, maxRetries, taskErr)
+		}
+
+		if currentTask.StoreAs != "" {
+			store[currentTask.StoreAs] = output
+		}
+		if currentTask.AppendTo != nil {
+			if err := env.appendOutput(ctx, chain, currentTask, store, output); err != nil {
+				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: append_to: %w", currentTask.ID, err)
+			}
 		}
 
 		// Handle print statement
@@ -437,14 +714,35 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			fmt.Println(printMsg)
 		}
 
-		// Evaluate transitions and get chosen branch
-		nextTaskID, chosenBranch, err := env.evaluateTransitions(ctx, currentTask.ID, currentTask.Transition, transitionEval)
-		if err != nil {
-			return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: transition error: %v", currentTask.ID, err)
+		// Evaluate transitions and get chosen branch; a weighted transition
+		// takes the branch drawn when the step was recorded.
+		nextTaskID, chosenBranch := "", variant
+		switch {
+		case asking:
+			nextTaskID = TermEnd
+		case chosenBranch != nil:
+			nextTaskID = chosenBranch.Goto
+		default:
+			nextTaskID, chosenBranch, err = env.evaluateTransitions(ctx, currentTask.ID, currentTask.Transition, transitionEval, &exprScope{
+				output:     output,
+				transition: transitionEval,
+				vars:       vars,
+			})
+			// An agent_loop stopped by the time budget continues with the
+			// wrap-up task even when its transitions do not name wrap_up.
+			if err != nil && transitionEval == TransitionWrapUp && chain.WrapUp != nil {
+				nextTaskID, chosenBranch, err = chain.WrapUp.Task, nil, nil
+				if wrappingUp || currentTask.ID == chain.WrapUp.Task {
+					nextTaskID = TermEnd
+				}
+			}
+			if err != nil {
+				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: transition error: %v", currentTask.ID, err)
+			}
 		}
 
 		// Handle branch-specific compose
-		if chosenBranch.Compose != nil {
+		if chosenBranch != nil && chosenBranch.Compose != nil {
 			compose := chosenBranch.Compose
 
 			// Validate compose variables exist
@@ -484,6 +782,16 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 		varTypes["previous_output"] = outputType
 		varTypes[currentTask.ID] = outputType
 
+		if chain.WrapUp != nil && currentTask.ID == chain.WrapUp.Task {
+			wrappingUp = true
+		}
+		transitionAttrs := []any{"next_task", nextTaskID}
+		if !wrappingUp && nextTaskID != "" && nextTaskID != TermEnd && wrapUpDue(ctx) {
+			nextTaskID = chain.WrapUp.Task
+			transitionAttrs = []any{"next_task", nextTaskID, "reason", "max_duration"}
+			wrappingUp = true
+		}
+
 		if nextTaskID == "" || nextTaskID == TermEnd {
 			finalOutput = output
 			// Track final output
@@ -496,12 +804,25 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			break
 		}
 
+		completedSteps++
+		checkpoints.save(ctx, &Checkpoint{
+			Chain:          chain,
+			NextTaskID:     nextTaskID,
+			Output:         output,
+			OutputType:     outputType,
+			Vars:           vars,
+			VarTypes:       varTypes,
+			CompletedSteps: completedSteps,
+			WrapUpDeadline: wrapUpDeadline,
+			WrappingUp:     wrappingUp,
+		})
+
 		// Track normal transition to next task
 		_, reportChangeTransition, endTransition := env.tracker.Start(
 			ctx,
 			"next_task",
 			currentTask.ID,
-			"next_task", nextTaskID,
+			transitionAttrs...,
 		)
 		reportChangeTransition(nextTaskID, transitionEval)
 		endTransition() // Fix 2: direct call, not defer
@@ -517,6 +838,7 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 	if normErr != nil {
 		return nil, DataTypeAny, nil, normErr
 	}
+	checkpoints.clear(ctx)
 	return normOut, normDT, nil, nil
 }
 
@@ -635,6 +957,131 @@ func (env SimpleEnv) composeMergeChatHistories(leftVal any, leftType DataType, r
 	return merged, DataTypeChatHistory, nil
 }
 
+// renderToolsArgs returns task with templated tools args rendered against vars.
+// The chain definition is never mutated: a copy is returned when any arg changes.
+func renderToolsArgs(task *TaskDefinition, vars map[string]any) (*TaskDefinition, error) {
+	if task.Tools == nil || len(task.Tools.Args) == 0 {
+		return task, nil
+	}
+	var rendered map[string]string
+	for k, v := range task.Tools.Args {
+		if !strings.Contains(v, "{{") {
+			continue
+		}
+		out, err := renderArgTemplate(v, vars)
+		if err != nil {
+			return nil, fmt.Errorf("tools arg %q: template error: %v", k, err)
+		}
+		if rendered == nil {
+			rendered = maps.Clone(task.Tools.Args)
+		}
+		rendered[k] = out
+	}
+	if rendered == nil {
+		return task, nil
+	}
+	t := *task
+	tools := *task.Tools
+	tools.Args = rendered
+	t.Tools = &tools
+	return &t, nil
+}
+
+// renderArgTemplate renders a tools arg template against vars. Unlike prompt
+// templates, a reference to a missing variable fails instead of rendering
+// "<no value>", and JSON objects and arrays render as JSON rather than in Go
+// syntax. {{has "vars.name"}} tests whether a variable exists, for optional
+// args, and {{json .x}} renders any value as JSON.
+func renderArgTemplate(tmplStr string, vars map[string]any) (string, error) {
+	data := make(map[string]any, len(vars))
+	for k, v := range vars {
+		data[k] = jsonRendered(v)
+	}
+	store, _ := data[chainVarsKey].(jsonObject)
+	tmpl, err := template.New("arg").Option("missingkey=error").Funcs(template.FuncMap{
+		chainVarsKey: func() jsonObject { return store },
+		"has":        func(path string) bool { return hasPath(data, path) },
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// jsonObject and jsonArray are JSON values that print as JSON in templates
+// while still allowing field access, index and range.
+type (
+	jsonObject map[string]any
+	jsonArray  []any
+)
+
+func (o jsonObject) String() string { return marshalString(map[string]any(o)) }
+func (a jsonArray) String() string  { return marshalString([]any(a)) }
+
+func marshalString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// jsonRendered wraps the JSON objects and arrays in v as jsonObject and
+// jsonArray. Other values are returned unchanged.
+func jsonRendered(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		out := make(jsonObject, len(x))
+		for k, e := range x {
+			out[k] = jsonRendered(e)
+		}
+		return out
+	case []any:
+		out := make(jsonArray, len(x))
+		for i, e := range x {
+			out[i] = jsonRendered(e)
+		}
+		return out
+	case []string, []float64, []int, map[string]string:
+		var decoded any
+		if err := json.Unmarshal([]byte(marshalString(x)), &decoded); err != nil {
+			return v
+		}
+		return jsonRendered(decoded)
+	default:
+		return v
+	}
+}
+
+// hasPath reports whether the dot-separated path, e.g. "vars.title" or
+// "classify.score", resolves to a value in data.
+func hasPath(data map[string]any, path string) bool {
+	var cur any = data
+	for _, key := range strings.Split(path, ".") {
+		var next any
+		var ok bool
+		switch m := cur.(type) {
+		case map[string]any:
+			next, ok = m[key]
+		case jsonObject:
+			next, ok = m[key]
+		}
+		if !ok {
+			return false
+		}
+		cur = next
+	}
+	return true
+}
+
 func sanitizeBranchName(branchName string) string {
 	safe := strings.ReplaceAll(branchName, " ", "_")
 	safe = strings.ReplaceAll(safe, "-", "_")
@@ -642,8 +1089,20 @@ func sanitizeBranchName(branchName string) string {
 	return safe
 }
 
+// chainVarsKey is the template variable holding the chain's named outputs.
+// They are reachable as {{vars.name}} (or {{.vars.name}}) in every template.
+const chainVarsKey = "vars"
+
 func renderTemplate(tmplStr string, vars any) (string, error) {
-	tmpl, err := template.New("prompt").Parse(tmplStr)
+	store := map[string]any{}
+	if m, ok := vars.(map[string]any); ok {
+		if s, ok := m[chainVarsKey].(map[string]any); ok {
+			store = s
+		}
+	}
+	tmpl, err := template.New("prompt").Funcs(template.FuncMap{
+		chainVarsKey: func() map[string]any { return store },
+	}).Parse(tmplStr)
 	if err != nil {
 		return "", err
 	}
@@ -654,14 +1113,25 @@ func renderTemplate(tmplStr string, vars any) (string, error) {
 	return buf.String(), nil
 }
 
-func (exe SimpleEnv) evaluateTransitions(_ context.Context, _ string, transition TaskTransition, eval string) (string, *TransitionBranch, error) {
+func (exe SimpleEnv) evaluateTransitions(_ context.Context, _ string, transition TaskTransition, eval string, scope *exprScope) (string, *TransitionBranch, error) {
+	// exprErr keeps the last expression error to explain a missing match.
+	var exprErr error
 	// First check explicit matches
 	for _, branch := range transition.Branches {
 		if branch.Operator == OpDefault {
 			continue
 		}
 
-		match, err := compare(branch.Operator, eval, branch.When)
+		var match bool
+		var err error
+		if branch.Operator == OpExpr {
+			match, err = evalCondition(branch.When, scope)
+			if err != nil {
+				exprErr = err
+			}
+		} else {
+			match, err = compare(branch.Operator, eval, branch.When)
+		}
 		if err != nil {
 			// Fix 8: treat parse errors as non-match so OpDefault can still fire.
 			// Returning an error here would bypass the safe fallback branch entirely.
@@ -679,6 +1149,9 @@ func (exe SimpleEnv) evaluateTransitions(_ context.Context, _ string, transition
 		}
 	}
 
+	if exprErr != nil {
+		return "", nil, fmt.Errorf("no matching transition found for eval: %s (%v)", eval, exprErr)
+	}
 	return "", nil, fmt.Errorf("no matching transition found for eval: %s", eval)
 }
 
@@ -741,6 +1214,9 @@ func compare(operator OperatorTerm, response, when string) (bool, error) {
 			return false, err
 		}
 		return resNum < targetNum, nil
+	case OpExpr:
+		// Without task output and variables only the transition value is known.
+		return evalCondition(when, &exprScope{transition: response})
 	case OpInRange:
 		// Fix 11: use regex so negative bounds like "-10--2" or "-5-5" parse correctly.
 		// strings.Split(when, "-") breaks on any leading '-' in a negative number.
@@ -785,6 +1261,9 @@ func validateChain(tasks []TaskDefinition) error {
 		return fmt.Errorf("chain has no tasks %w", errdefs.ErrBadRequest)
 	}
 	for _, ct := range tasks {
+		if ct.ID == chainVarsKey {
+			return fmt.Errorf("task ID cannot be '%s' %w", chainVarsKey, errdefs.ErrBadRequest)
+		}
 		if ct.ID == "" || ct.ID == TermEnd {
 			if ct.ID == "" {
 				return fmt.Errorf("task ID cannot be empty %w", errdefs.ErrBadRequest)
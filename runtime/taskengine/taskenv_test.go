@@ -196,6 +196,45 @@ func TestUnit_SimpleEnv_ExecEnv_ErrorTransition(t *testing.T) {
 	require.Equal(t, "error recovered", result)
 }
 
+func TestUnit_SimpleEnv_ExecEnv_OnErrorReceivesErrorMessage(t *testing.T) {
+	mockExec := &taskengine.MockTaskExecutor{
+		ErrorSequence:       []error{errors.New("backend exploded"), nil},
+		MockOutput:          "recovered",
+		MockTransitionValue: "recovered",
+	}
+
+	tracker := libtracker.NoopTracker{}
+	env, err := taskengine.NewEnv(context.Background(), tracker, mockExec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	chain := &taskengine.TaskChainDefinition{
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "task1",
+				Handler: taskengine.HandlePromptToString,
+				Transition: taskengine.TaskTransition{
+					OnError:  "recover",
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+			{
+				ID:      "recover",
+				Handler: taskengine.HandlePromptToString,
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+	require.False(t, taskengine.ValidateChain(chain).HasErrors())
+
+	result, _, _, err := env.ExecEnv(context.Background(), chain, "oops", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "recovered", result)
+	require.Equal(t, "recover", mockExec.CalledWithTask.ID)
+	require.Equal(t, "task task1: backend exploded", mockExec.CalledWithInput)
+}
+
 func TestUnit_SimpleEnv_ExecEnv_PrintTemplate(t *testing.T) {
 	mockExec := &taskengine.MockTaskExecutor{
 		MockOutput:          "printed-value",
@@ -422,3 +461,41 @@ func TestUnit_SimpleEnv_ExecEnv_InputVar_DefaultBehavior(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "second", result)
 }
+
+func TestUnit_SimpleEnv_ExecEnv_ExprTransition(t *testing.T) {
+	mockExec := &taskengine.MockTaskExecutor{
+		MockOutput:          map[string]any{"score": 0.9},
+		MockTransitionValue: "ok",
+	}
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, mockExec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	chain := &taskengine.TaskChainDefinition{
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "score",
+				Handler: taskengine.HandleNoop,
+				StoreAs: "env",
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpExpr, When: `output.score > 0.7 && input == "prod"`, Goto: "ship"},
+						{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd},
+					},
+				},
+			},
+			{
+				ID:      "ship",
+				Handler: taskengine.HandleNoop,
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpExpr, When: `vars.env.score == score.score`, Goto: taskengine.TermEnd},
+					},
+				},
+			},
+		},
+	}
+
+	_, _, _, err = env.ExecEnv(context.Background(), chain, "prod", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, 2, mockExec.CallCount())
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -45,6 +46,10 @@ type SimpleExec struct {
 	toolsProvider ToolsRepo
 	tracker      libtracker.ActivityTracker
 	eventSink    TaskEventSink
+	promptCache  *promptCacheConfig
+	limiter      *RateLimiter
+	pricing      Pricing
+	costRecorder CostRecorder
 }
 
 // NewExec creates a new SimpleExec instance
@@ -60,11 +65,20 @@ func NewExec(
 	if repo == nil {
 		return nil, fmt.Errorf("repo executor is nil")
 	}
+	limiter := rateLimiterFromContext(ctx)
+	if limiter == nil {
+		// Without a shared limiter, only chains with rate_limits are limited.
+		limiter, _ = NewRateLimiter(nil)
+	}
 	return &SimpleExec{
 		toolsProvider: toolsProvider,
 		repo:         repo,
 		tracker:      tracker,
 		eventSink:    taskEventSinkFromContext(ctx),
+		promptCache:  promptCacheFromContext(ctx),
+		limiter:      limiter,
+		pricing:      pricingFromContext(ctx),
+		costRecorder: costRecorderFromContext(ctx),
 	}, nil
 }
 
@@ -79,6 +93,27 @@ func (exe *SimpleExec) publishStepChunk(ctx context.Context, meta llmrepo.Meta,
 	event.Content = content
 	event.Thinking = thinking
 	publishTaskEventBestEffort(ctx, exe.eventSink, event)
+	if fn := tokenStreamFromContext(ctx); fn != nil {
+		fn(TokenChunk{
+			ChainID:   event.ChainID,
+			TaskID:    event.TaskID,
+			ModelName: meta.ModelName,
+			Content:   content,
+			Thinking:  thinking,
+		})
+	}
+}
+
+// publishToolCalled reports a finished tool call.
+func (exe *SimpleExec) publishToolCalled(ctx context.Context, call *ToolsCall, start time.Time, err error) {
+	event := NewTaskEvent(ctx, TaskEventToolCalled)
+	event.ToolsName = call.Name
+	event.ToolName = call.ToolName
+	event.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		event.Error = err.Error()
+	}
+	publishTaskEventBestEffort(ctx, exe.eventSink, event)
 }
 
 // countTokensAndCheckLimit counts tokens for text and checks against context limit
@@ -131,6 +166,22 @@ func (exe *SimpleExec) countChatHistoryTokens(ctx context.Context, modelName str
 	return totalTokens, nil
 }
 
+// fallbackCandidates returns the models to try in order when llmCall enables
+// ModelFallback: Model first, then Models, without duplicates. It returns nil
+// when the models are resolved at random instead.
+func fallbackCandidates(llmCall *LLMExecutionConfig) []string {
+	if llmCall == nil || llmCall.ModelFallback == nil || !*llmCall.ModelFallback {
+		return nil
+	}
+	var candidates []string
+	for _, m := range append([]string{llmCall.Model}, llmCall.Models...) {
+		if m != "" && !slices.Contains(candidates, m) {
+			candidates = append(candidates, m)
+		}
+	}
+	return candidates
+}
+
 // getPrimaryModel extracts the primary model name from execution config
 func getPrimaryModel(llmCall *LLMExecutionConfig) string {
 	if llmCall.Model != "" {
@@ -145,7 +196,7 @@ func getPrimaryModel(llmCall *LLMExecutionConfig) string {
 // Prompt resolves a model client using the resolver policy and sends the prompt
 // to be executed. Returns the trimmed response string or an error.
 func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llmCall LLMExecutionConfig, prompt string, ctxLength int) (string, error) {
-	reportErr, _, end := exe.tracker.Start(ctx, "SimpleExec", "prompt_model",
+	reportErr, reportChange, end := exe.tracker.Start(ctx, "SimpleExec", "prompt_model",
 		"model_name", llmCall.Model,
 		"model_names", llmCall.Models,
 		"provider_types", llmCall.Providers,
@@ -167,6 +218,18 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 		return "", err
 	}
 
+	// A failing cache lookup is treated as a miss. Dry runs bypass the cache
+	// so canned responses never mix with real ones.
+	var cacheKey string
+	if exe.promptCache != nil && dryRunFromContext(ctx) == nil {
+		cacheKey = PromptCacheKey(systemInstruction, llmCall, prompt)
+		if cached, ok, err := exe.promptCache.cache.Get(ctx, cacheKey); err == nil && ok {
+			reportChange("prompt_cache_hit", cacheKey)
+			exe.publishStepChunk(ctx, llmrepo.Meta{ModelName: modelName}, cached, "")
+			return cached, nil
+		}
+	}
+
 	providerNames := []string{}
 	if llmCall.Provider != "" {
 		providerNames = append(providerNames, llmCall.Provider)
@@ -190,6 +253,7 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 	streamArgs := []libmodelprovider.ChatArgument{
 		libmodelprovider.WithTemperature(float64(llmCall.Temperature)),
 	}
+	streamArgs = append(streamArgs, samplingArgs(&llmCall)...)
 	if llmCall.Think != "" {
 		streamArgs = append(streamArgs, libmodelprovider.WithThink(llmCall.Think))
 	}
@@ -197,7 +261,11 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 		streamArgs = append(streamArgs, libmodelprovider.WithShift{})
 	}
 
-	if exe.eventSink.Enabled() {
+	if exe.streamingRequested(ctx) {
+		streamReq := req
+		if candidates := fallbackCandidates(&llmCall); len(candidates) > 0 {
+			streamReq.ModelNames = candidates[:1]
+		}
 		messages := make([]libmodelprovider.Message, 0, 2)
 		if systemInstruction != "" {
 			messages = append(messages, libmodelprovider.Message{
@@ -210,8 +278,9 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 			Content: prompt,
 		})
 
-		stream, meta, err := exe.repo.Stream(ctx, req, messages, streamArgs...)
+		stream, meta, err := exe.modelRepo(ctx).Stream(ctx, streamReq, messages, streamArgs...)
 		if err == nil {
+			recordServedModel(ctx, meta, nil)
 			var fullResponse strings.Builder
 			for parcel := range stream {
 				if parcel.Error != nil {
@@ -222,7 +291,10 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 				fullResponse.WriteString(parcel.Data)
 				exe.publishStepChunk(ctx, meta, parcel.Data, parcel.Thinking)
 			}
-			return strings.TrimSpace(fullResponse.String()), nil
+			response := strings.TrimSpace(fullResponse.String())
+			exe.recordPromptUsage(ctx, meta, combinedText, response)
+			exe.storePrompt(ctx, cacheKey, response)
+			return response, nil
 		}
 	}
 
@@ -232,9 +304,21 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 		reportErr(err)
 		return "", err
 	}
-	exe.publishStepChunk(ctx, meta, strings.TrimSpace(response), "")
+	response = strings.TrimSpace(response)
+	exe.publishStepChunk(ctx, meta, response, "")
+	exe.recordPromptUsage(ctx, meta, combinedText, response)
+	exe.storePrompt(ctx, cacheKey, response)
 
-	return strings.TrimSpace(response), nil
+	return response, nil
+}
+
+// storePrompt caches a prompt response. Caching is best-effort; errors are
+// dropped so a broken cache never fails the task.
+func (exe *SimpleExec) storePrompt(ctx context.Context, key, response string) {
+	if exe.promptCache == nil || key == "" || response == "" {
+		return
+	}
+	_ = exe.promptCache.cache.Set(ctx, key, response, exe.promptCache.ttl)
 }
 
 // promptWithRetry wraps repo.PromptExecute with [llmretry.Do] when the task's
@@ -252,21 +336,12 @@ func (exe *SimpleExec) promptWithRetry(
 	req llmrepo.Request,
 	systemInstruction, prompt string,
 ) (string, llmrepo.Meta, error) {
-	policy := llmretry.RetryPolicy{}
-	if llmCall != nil && llmCall.RetryPolicy != nil {
-		policy = *llmCall.RetryPolicy
-	}
-	primary := getPrimaryModel(llmCall)
 	type promptResult struct {
 		response string
 		meta     llmrepo.Meta
 	}
-	result, outcome, err := llmretry.Do(ctx, policy, primary, func(modelID string) (any, error) {
-		callReq := req
-		if modelID != "" && modelID != primary {
-			callReq.ModelNames = []string{modelID}
-		}
-		r, m, e := exe.repo.PromptExecute(ctx, callReq, systemInstruction, float32(llmCall.Temperature), prompt)
+	result, outcome, err := doLLMCall(ctx, llmCall, req, func(callReq llmrepo.Request) (any, error) {
+		r, m, e := exe.modelRepo(ctx).PromptExecute(ctx, callReq, systemInstruction, float32(llmCall.Temperature), prompt, samplingArgs(llmCall)...)
 		if e != nil {
 			return nil, e
 		}
@@ -277,9 +352,37 @@ func (exe *SimpleExec) promptWithRetry(
 		return "", llmrepo.Meta{}, err
 	}
 	pr := result.(promptResult)
+	recordServedModel(ctx, pr.meta, outcome.FailedModels)
 	return pr.response, pr.meta, nil
 }
 
+// doLLMCall runs call under the task's RetryPolicy. With ModelFallback the
+// candidates from [fallbackCandidates] are tried in order, each targeted
+// exclusively; otherwise the request goes to the configured models with the
+// policy's single fallback model, if any.
+func doLLMCall(ctx context.Context, llmCall *LLMExecutionConfig, req llmrepo.Request, call func(llmrepo.Request) (any, error)) (any, llmretry.Outcome, error) {
+	policy := llmretry.RetryPolicy{}
+	if llmCall != nil && llmCall.RetryPolicy != nil {
+		policy = *llmCall.RetryPolicy
+	}
+	if candidates := fallbackCandidates(llmCall); len(candidates) > 0 {
+		return llmretry.DoCandidates(ctx, policy, candidates, func(modelID string) (any, error) {
+			callReq := req
+			callReq.ModelNames = []string{modelID}
+			return call(callReq)
+		})
+	}
+	primary := getPrimaryModel(llmCall)
+	return llmretry.Do(ctx, policy, primary, func(modelID string) (any, error) {
+		callReq := req
+		if modelID != "" && modelID != primary {
+			// Fallback path: target the fallback model exclusively.
+			callReq.ModelNames = []string{modelID}
+		}
+		return call(callReq)
+	})
+}
+
 // Prompt resolves a model client and sends the prompt
 // to be executed. Returns the trimmed response string or an error.
 
@@ -313,6 +416,7 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 	if taskCtx.Err() != nil {
 		return nil, DataTypeAny, "request was canceled", fmt.Errorf("task execution failed: %w", taskCtx.Err())
 	}
+	taskCtx = withDryRunTask(taskCtx, currentTask.ID)
 	if currentTask.Handler == HandleNoop {
 		return output, outputType, "noop", nil
 	}
@@ -349,6 +453,7 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 	switch currentTask.Handler {
 	case HandlePromptToString,
 		HandlePromptToInt,
+		HandlePromptToStructured,
 		HandleRaiseError:
 		prompt, err := getPrompt()
 		if err != nil {
@@ -372,6 +477,14 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 			outputType = DataTypeInt
 			transitionEval = strconv.FormatInt(int64(number), 10)
 
+		case HandlePromptToStructured:
+			output, taskErr = exe.structured(taskCtx, currentTask.SystemInstruction, *currentTask.ExecuteConfig, currentTask.Structured, prompt, ctxLength)
+			outputType = DataTypeJSON
+			transitionEval = "valid"
+			if taskErr != nil {
+				output, transitionEval = nil, "invalid"
+			}
+
 		case HandleRaiseError:
 			message, err := getPrompt()
 			if err != nil {
@@ -473,6 +586,11 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 		}
 
 		executedAny := false
+		model := chatHistory.Model
+		if model == "" && currentTask.ExecuteConfig != nil {
+			model = getPrimaryModel(currentTask.ExecuteConfig)
+		}
+		toolCallCtx := withToolCallModel(taskCtx, model)
 
 		for _, toolCall := range lastMessage.CallTools {
 			// robust resolution: try direct key, then scan by Function.Name / ToolsName
@@ -508,8 +626,24 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 				Args: toolsArgs,
 			}
 
+			// Arguments that break the tool's schema are sent back to the model
+			// instead of to the tool, so the model can correct them.
+			if err := validateToolArgs(resolutionInfo.Tool, args); err != nil {
+				exe.publishToolCalled(toolCallCtx, toolsCall, time.Now(), err)
+				executedAny = true
+				chatHistory.Messages = append(chatHistory.Messages, Message{
+					Role:       "tool",
+					Content:    invalidToolArgsMessage(toolCall.Function.Name, err, resolutionInfo.Function.Parameters),
+					ToolCallID: toolCall.ID,
+					Timestamp:  time.Now().UTC(),
+				})
+				continue
+			}
+
 			// `args` are the per-call dynamic tool arguments
-			result, resultType, err := exe.toolsProvider.Exec(taskCtx, startingTime, args, chainContext.Debug, toolsCall)
+			callStart := time.Now()
+			result, resultType, err := exe.toolsProvider.Exec(toolCallCtx, startingTime, args, chainContext.Debug, toolsCall)
+			exe.publishToolCalled(toolCallCtx, toolsCall, callStart, err)
 			if err != nil {
 				result = fmt.Sprintf("tool %s execution failed: %s", toolCall.Function.Name, err)
 				err = nil
@@ -579,8 +713,117 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 			)
 		}
 
+	case HandleCosineSimilarity:
+		a, b, err := vectorPair(input)
+		if err != nil {
+			taskErr = fmt.Errorf("cosine_similarity: %w", err)
+			break
+		}
+		score, err := cosineSimilarity(a, b)
+		if err != nil {
+			taskErr = fmt.Errorf("cosine_similarity: %w", err)
+			break
+		}
+		output, outputType, transitionEval = score, DataTypeJSON, formatScore(score)
+
+	case HandleVectorTopK:
+		k := 1
+		if currentTask.Vector != nil && currentTask.Vector.TopK > 0 {
+			k = currentTask.Vector.TopK
+		}
+		matches, err := vectorTopK(input, k)
+		if err != nil {
+			taskErr = fmt.Errorf("vector_top_k: %w", err)
+			break
+		}
+		best := matches[0].(map[string]any)
+		transitionEval = fmt.Sprintf("%v", best["index"])
+		if id, ok := best["id"]; ok {
+			transitionEval = fmt.Sprintf("%v", id)
+		}
+		output, outputType = matches, DataTypeJSON
+
+	case HandleVectorAverage:
+		avg, err := vectorAverage(input)
+		if err != nil {
+			taskErr = fmt.Errorf("vector_average: %w", err)
+			break
+		}
+		output, outputType, transitionEval = avg, DataTypeVector, "ok"
+
+	case HandleEmbedBatch:
+		vectors, err := exe.embedBatch(taskCtx, currentTask, input)
+		if err != nil {
+			taskErr = fmt.Errorf("embed_batch: %w", err)
+			break
+		}
+		output, outputType, transitionEval = vectors, DataTypeJSON, strconv.Itoa(len(vectors))
+
+	case HandleVectorSearch:
+		matches, err := exe.vectorSearch(taskCtx, currentTask, input)
+		if err != nil {
+			taskErr = fmt.Errorf("vector_search: %w", err)
+			break
+		}
+		transitionEval = "no_match"
+		if len(matches) > 0 {
+			best := matches[0].(map[string]any)
+			transitionEval = fmt.Sprintf("%v", best["index"])
+			if id, ok := best["id"]; ok {
+				transitionEval = fmt.Sprintf("%v", id)
+			}
+		}
+		output, outputType = matches, DataTypeJSON
+
+	case HandleCoerce:
+		output, outputType, transitionEval, taskErr = coerce(currentTask.Coerce, input)
+
+	case HandleGlossary:
+		var violations []GlossaryViolation
+		output, outputType, transitionEval, violations, taskErr = enforceGlossary(currentTask.Glossary, input)
+		if len(violations) > 0 {
+			_, reportChange, end := exe.tracker.Start(taskCtx, "SimpleExec", "glossary", "task_id", currentTask.ID)
+			reportChange("glossary_violations", violations)
+			end()
+		}
+
+	case HandleJQTransform:
+		output, outputType, transitionEval, taskErr = jqTransform(taskCtx, currentTask.JQ, input)
+
+	case HandleAgentLoop:
+		output, outputType, transitionEval, taskErr = exe.agentLoop(taskCtx, startingTime, ctxLength, chainContext, currentTask, input, dataType)
+
+	case HandleMapReduce:
+		var text string
+		switch outputType {
+		case DataTypeString, DataTypeChatHistory:
+			text, taskErr = getPrompt()
+		default:
+			text, taskErr = convertToString(input)
+		}
+		if taskErr != nil {
+			break
+		}
+		var reduced string
+		reduced, taskErr = exe.mapReduce(taskCtx, currentTask, text, ctxLength)
+		output, outputType, transitionEval = reduced, DataTypeString, reduced
+		if taskErr != nil {
+			output, outputType, transitionEval = nil, DataTypeAny, ""
+		}
+
+	case HandleAwaitApproval, HandleClarify:
+		// Approvals and clarifications pause the whole run, which ExecEnv
+		// handles; parallel branches and foreach bodies run here and cannot
+		// be paused.
+		taskErr = fmt.Errorf("%s is only supported as a top-level chain task: %w", currentTask.Handler, ErrUnsupportedTaskType)
+
 	default:
-		taskErr = fmt.Errorf("unknown task type: %w -- %s", ErrUnsupportedTaskType, currentTask.Handler.String())
+		fn, ok := registeredHandler(currentTask.Handler)
+		if !ok {
+			taskErr = fmt.Errorf("unknown task type: %w -- %s", ErrUnsupportedTaskType, currentTask.Handler.String())
+			break
+		}
+		output, outputType, transitionEval, taskErr = fn(taskCtx, currentTask, input, dataType)
 	}
 
 	return output, outputType, transitionEval, taskErr
@@ -722,7 +965,7 @@ func (exe *SimpleExec) executeLLM(
 
 	// Convert chat history to model repo messages
 	messagesC := make([]libmodelprovider.Message, 0, len(input.Messages))
-	for _, m := range input.Messages {
+	for mi, m := range input.Messages {
 		var toolCalls []libmodelprovider.ToolCall
 		if len(m.CallTools) > 0 {
 			toolCalls = make([]libmodelprovider.ToolCall, len(m.CallTools))
@@ -734,16 +977,30 @@ func (exe *SimpleExec) executeLLM(
 				toolCalls[i].ProviderMeta = tc.ProviderMeta
 			}
 		}
+		var attachments []libmodelprovider.Attachment
+		for i, a := range m.Attachments {
+			if err := a.Validate(); err != nil {
+				err = fmt.Errorf("message %d: attachment %d: %w", mi, i, err)
+				reportErr(err)
+				return nil, DataTypeAny, "", err
+			}
+			attachments = append(attachments, libmodelprovider.Attachment{MimeType: a.MimeType, URL: a.URL, Data: a.Data})
+		}
 		messagesC = append(messagesC, libmodelprovider.Message{
-			Role:       m.Role,
-			Content:    m.Content,
-			ToolCalls:  toolCalls,
-			ToolCallID: m.ToolCallID,
+			Role:        m.Role,
+			Content:     m.Content,
+			ToolCalls:   toolCalls,
+			ToolCallID:  m.ToolCallID,
+			Attachments: attachments,
 		})
 	}
 
 	// Prepare chat arguments
 	chatArgs := []libmodelprovider.ChatArgument{libmodelprovider.WithTools(tools...)}
+	if llmCall.Temperature != 0 {
+		chatArgs = append(chatArgs, libmodelprovider.WithTemperature(float64(llmCall.Temperature)))
+	}
+	chatArgs = append(chatArgs, samplingArgs(llmCall)...)
 	reportChange("tools_prepared", map[string]any{
 		"count": len(tools),
 		"model": llmCall.Model,
@@ -754,6 +1011,14 @@ func (exe *SimpleExec) executeLLM(
 	if llmCall.Shift {
 		chatArgs = append(chatArgs, libmodelprovider.WithShift{})
 	}
+	toolChoice, err := resolveToolChoice(llmCall.ToolChoice, tools)
+	if err != nil {
+		reportErr(err)
+		return nil, DataTypeAny, "", err
+	}
+	if toolChoice != nil {
+		chatArgs = append(chatArgs, libmodelprovider.WithToolChoice(*toolChoice))
+	}
 
 	providerNames := []string{}
 	if llmCall.Provider != "" {
@@ -778,9 +1043,14 @@ func (exe *SimpleExec) executeLLM(
 
 	// When no tools are exposed, we can stream the assistant turn and still
 	// preserve task semantics by buffering the final content locally.
-	if exe.eventSink.Enabled() && len(tools) == 0 {
-		stream, meta, err := exe.repo.Stream(ctx, req, messagesC, chatArgs...)
+	if exe.streamingRequested(ctx) && len(tools) == 0 {
+		streamReq := req
+		if candidates := fallbackCandidates(llmCall); len(candidates) > 0 {
+			streamReq.ModelNames = candidates[:1]
+		}
+		stream, meta, err := exe.modelRepo(ctx).Stream(ctx, streamReq, messagesC, chatArgs...)
 		if err == nil {
+			recordServedModel(ctx, meta, nil)
 			var streamedContent strings.Builder
 			var streamedThinking strings.Builder
 			for parcel := range stream {
@@ -814,6 +1084,7 @@ func (exe *SimpleExec) executeLLM(
 				}
 			}
 			input.OutputTokens = outputTokensCount
+			exe.recordUsage(ctx, meta, totalTokens, outputTokensCount)
 			return input, DataTypeChatHistory, "executed", nil
 		}
 	}
@@ -858,6 +1129,10 @@ func (exe *SimpleExec) executeLLM(
 		}
 	}
 	input.OutputTokens = outputTokensCount
+	exe.recordUsage(ctx, meta, totalTokens, outputTokensCount)
+	if meta.ModelName != "" {
+		input.Model = meta.ModelName
+	}
 
 	if len(callTools) > 0 {
 		return input, DataTypeChatHistory, "tool-call", nil
@@ -879,7 +1154,9 @@ func (exe *SimpleExec) toolsengine(
 	}
 
 	// Call the provider with the new, simple signature.
+	callStart := time.Now()
 	toolsOutput, dataType, err := exe.toolsProvider.Exec(ctx, startingTime, input, debug, tools)
+	exe.publishToolCalled(ctx, tools, callStart, err)
 	if err != nil {
 		return nil, dataType, "failed", err
 	}
@@ -994,7 +1271,7 @@ func (exe *SimpleExec) maybeCompact(
 			if modelID != "" && modelID != m {
 				r.ModelNames = []string{modelID}
 			}
-			resp, _, e := exe.repo.PromptExecute(c, r, sysInstruction, 0.2, prompt)
+			resp, _, e := exe.modelRepo(c).PromptExecute(c, r, sysInstruction, 0.2, prompt)
 			if e != nil {
 				return nil, e
 			}
@@ -1063,10 +1340,11 @@ func getTaskCompactID(ctx context.Context) string {
 	return v
 }
 
-// chatWithRetry wraps repo.Chat with [llmretry.Do] when llmCall.RetryPolicy is
-// set; otherwise it issues a single call (preserving today's behavior). On
-// fallback, the request's ModelNames slice is replaced with the fallback id so
-// the underlying resolver targets that model directly.
+// chatWithRetry wraps repo.Chat with [doLLMCall]: classified retry when
+// llmCall.RetryPolicy is set, ordered model fallback when ModelFallback is,
+// and otherwise a single call. On fallback, the request's ModelNames slice is
+// replaced with the fallback id so the underlying resolver targets that model
+// directly.
 //
 // Every invocation appends an [llmretry.Outcome] to the context-bound sink
 // (see [WithRetryOutcomeSink]) so callers like planservice can inspect what
@@ -1078,22 +1356,12 @@ func (exe *SimpleExec) chatWithRetry(
 	messages []libmodelprovider.Message,
 	chatArgs []libmodelprovider.ChatArgument,
 ) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
-	policy := llmretry.RetryPolicy{}
-	if llmCall != nil && llmCall.RetryPolicy != nil {
-		policy = *llmCall.RetryPolicy
-	}
-	primary := getPrimaryModel(llmCall)
 	type chatResult struct {
 		resp libmodelprovider.ChatResult
 		meta llmrepo.Meta
 	}
-	result, outcome, err := llmretry.Do(ctx, policy, primary, func(modelID string) (any, error) {
-		callReq := req
-		if modelID != "" && modelID != primary {
-			// Fallback path: target the fallback model exclusively.
-			callReq.ModelNames = []string{modelID}
-		}
-		r, m, e := exe.repo.Chat(ctx, callReq, messages, chatArgs...)
+	result, outcome, err := doLLMCall(ctx, llmCall, req, func(callReq llmrepo.Request) (any, error) {
+		r, m, e := exe.modelRepo(ctx).Chat(ctx, callReq, messages, chatArgs...)
 		if e != nil {
 			return nil, e
 		}
@@ -1104,6 +1372,7 @@ func (exe *SimpleExec) chatWithRetry(
 		return libmodelprovider.ChatResult{}, llmrepo.Meta{}, err
 	}
 	cr := result.(chatResult)
+	recordServedModel(ctx, cr.meta, outcome.FailedModels)
 	return cr.resp, cr.meta, nil
 }
 
@@ -0,0 +1,115 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// defaultRetryMaxDelay caps the exponential backoff of tasks without
+// max_delay, unless their delay is longer.
+const defaultRetryMaxDelay = time.Hour
+
+// retrySchedule is the resolved retry behaviour of a single task.
+type retrySchedule struct {
+	maxAttempts int
+	strategy    BackoffStrategy
+	delay       time.Duration
+	maxDelay    time.Duration
+	retryOn     []string
+}
+
+// resolveRetry builds the retry schedule for task. The Retry block takes
+// precedence; otherwise RetryOnFailure retries failures without waiting.
+func resolveRetry(task *TaskDefinition) (retrySchedule, error) {
+	r := task.Retry
+	if r == nil {
+		return retrySchedule{
+			maxAttempts: max(task.RetryOnFailure, 0) + 1,
+			strategy:    BackoffNone,
+		}, nil
+	}
+
+	sched := retrySchedule{
+		maxAttempts: max(r.MaxAttempts, 1),
+		strategy:    r.Backoff,
+		retryOn:     r.RetryOn,
+	}
+	if r.Delay != "" {
+		d, err := time.ParseDuration(r.Delay)
+		if err != nil || d < 0 {
+			return sched, fmt.Errorf("invalid retry delay %q %w", r.Delay, errdefs.ErrBadRequest)
+		}
+		sched.delay = d
+	}
+	if r.MaxDelay != "" {
+		d, err := time.ParseDuration(r.MaxDelay)
+		if err != nil || d < 0 {
+			return sched, fmt.Errorf("invalid retry max_delay %q %w", r.MaxDelay, errdefs.ErrBadRequest)
+		}
+		sched.maxDelay = d
+	}
+	switch sched.strategy {
+	case "":
+		sched.strategy = BackoffNone
+		if sched.delay > 0 {
+			sched.strategy = BackoffFixed
+		}
+	case BackoffNone, BackoffFixed, BackoffExponential:
+	default:
+		return sched, fmt.Errorf("unknown retry backoff %q %w", r.Backoff, errdefs.ErrBadRequest)
+	}
+	return sched, nil
+}
+
+// retriesOn reports whether a successful attempt that evaluated to transition
+// should be retried.
+func (s retrySchedule) retriesOn(transition string) bool {
+	return slices.Contains(s.retryOn, transition)
+}
+
+// backoff returns the wait before the given retry (1 for the first retry).
+func (s retrySchedule) backoff(retry int) time.Duration {
+	switch s.strategy {
+	case BackoffFixed:
+		return s.delay
+	case BackoffExponential:
+		limit := s.maxDelay
+		if limit <= 0 {
+			limit = defaultRetryMaxDelay
+			if s.delay > limit {
+				limit = s.delay
+			}
+		}
+		wait := s.delay
+		for k := 1; k < retry && wait < limit; k++ {
+			if wait > limit/2 {
+				// Doubling would pass the cap, or overflow.
+				return limit
+			}
+			wait *= 2
+		}
+		return min(wait, limit)
+	default:
+		return 0
+	}
+}
+
+// wait blocks for the backoff of the given retry or until ctx is done.
+func (s retrySchedule) wait(ctx context.Context, retry int) error {
+	d := s.backoff(retry)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,99 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retryChain(retry *taskengine.TaskRetry) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "retry",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "flaky",
+				Handler: taskengine.HandlePromptToString,
+				Retry:   retry,
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+}
+
+func TestUnit_TaskRetry_RetriesFailuresWithBackoff(t *testing.T) {
+	exec := &taskengine.MockTaskExecutor{
+		MockOutput:          "done",
+		MockTransitionValue: "ok",
+		ErrorSequence:       []error{errors.New("boom"), errors.New("boom"), nil},
+	}
+	env := setupTestEnv(exec)
+
+	start := time.Now()
+	output, _, history, err := env.ExecEnv(context.Background(), retryChain(&taskengine.TaskRetry{
+		MaxAttempts: 3,
+		Backoff:     taskengine.BackoffExponential,
+		Delay:       "10ms",
+	}), "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "done", output)
+	// 10ms before the second attempt, 20ms before the third.
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	require.Len(t, history, 3)
+	for i, step := range history {
+		assert.Equal(t, i+1, step.Attempt)
+		assert.Equal(t, 3, step.MaxAttempts)
+	}
+	assert.NotEmpty(t, history[0].Error.Error)
+	assert.Empty(t, history[2].Error.Error)
+}
+
+func TestUnit_TaskRetry_RetriesOnTransition(t *testing.T) {
+	exec := &taskengine.MockTaskExecutor{
+		MockOutputSequence:          []any{"bad", "good"},
+		MockTransitionValueSequence: []string{"invalid", "valid"},
+	}
+	env := setupTestEnv(exec)
+
+	output, _, history, err := env.ExecEnv(context.Background(), retryChain(&taskengine.TaskRetry{
+		MaxAttempts: 3,
+		RetryOn:     []string{"invalid"},
+	}), "x", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "good", output)
+	require.Len(t, history, 2)
+	assert.Equal(t, "invalid", history[0].Transition)
+	assert.Equal(t, 2, history[1].Attempt)
+}
+
+func TestUnit_TaskRetry_ExhaustedAttemptsFail(t *testing.T) {
+	exec := &taskengine.MockTaskExecutor{MockError: errors.New("boom")}
+	env := setupTestEnv(exec)
+
+	_, _, history, err := env.ExecEnv(context.Background(), retryChain(&taskengine.TaskRetry{
+		MaxAttempts: 2,
+		Backoff:     taskengine.BackoffFixed,
+		Delay:       "1ms",
+	}), "x", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed after 1 retries")
+	assert.Len(t, history, 2)
+}
+
+func TestUnit_TaskRetry_InvalidConfig(t *testing.T) {
+	env := setupTestEnv(&taskengine.MockTaskExecutor{MockOutput: "x"})
+
+	_, _, _, err := env.ExecEnv(context.Background(), retryChain(&taskengine.TaskRetry{
+		MaxAttempts: 2,
+		Backoff:     "linear",
+	}), "x", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown retry backoff")
+}
@@ -0,0 +1,85 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// taskStep is a task visit prepared for its attempts: the task with its tools
+// args rendered, its input after the prompt template and the input
+// guardrails, and the verdicts of those guardrails.
+type taskStep struct {
+	task          *TaskDefinition
+	input         any
+	inputType     DataType
+	promptVariant string
+	verdicts      []GuardrailVerdict
+	// rejected is set when an input guardrail rejected the task; its
+	// attempts then fail without running it.
+	rejected error
+}
+
+// prepareStep renders the prompt template and tools args of task against vars
+// and checks the resulting input with the guardrails. A prompt variant is
+// drawn once per visit, so retries resend the same prompt.
+func (env SimpleEnv) prepareStep(ctx context.Context, task *TaskDefinition, vars map[string]any, input any, inputType DataType) (*taskStep, error) {
+	step := &taskStep{input: input, inputType: inputType}
+	promptTemplate, promptVariant := promptFor(task)
+	step.promptVariant = promptVariant
+	if promptTemplate != "" {
+		rendered, err := renderTemplate(promptTemplate, vars)
+		if err != nil {
+			return nil, fmt.Errorf("template error: %v", err)
+		}
+		step.input, step.inputType = rendered, DataTypeString
+	}
+	execTask, err := renderToolsArgs(task, vars)
+	if err != nil {
+		return nil, err
+	}
+	step.task = execTask
+	step.input, step.verdicts, step.rejected = runGuardrails(ctx, env.guardrails, GuardrailInput, task, step.input, step.inputType)
+	return step, nil
+}
+
+// runAttempt runs one attempt of step with taskCtx, which carries the task
+// timeout: exec produces the output unless an input guardrail rejected the
+// task, and a successful output is then checked with the guardrails and the
+// chain's file size limit. It returns the verdicts of both stages. An attempt
+// ended by the task timeout fails with ErrTaskTimeout and evaluates to
+// TransitionTimeout.
+func (env SimpleEnv) runAttempt(
+	taskCtx context.Context,
+	chain *TaskChainDefinition,
+	step *taskStep,
+	exec func(ctx context.Context) (any, DataType, string, error),
+) (any, DataType, string, []GuardrailVerdict, error) {
+	if step.rejected != nil {
+		return nil, DataTypeAny, "failed", step.verdicts, step.rejected
+	}
+	output, outputType, transition, err := exec(taskCtx)
+	verdicts := step.verdicts
+	if err == nil {
+		var outputVerdicts []GuardrailVerdict
+		output, outputVerdicts, err = runGuardrails(taskCtx, env.guardrails, GuardrailOutput, step.task, output, outputType)
+		verdicts = append(slices.Clip(verdicts), outputVerdicts...)
+		if err == nil {
+			err = checkFileSize(chain, output, outputType)
+		}
+		if err != nil {
+			output, outputType, transition = nil, DataTypeAny, "failed"
+		}
+	}
+	if err != nil {
+		switch cause := context.Cause(taskCtx); {
+		case errors.Is(cause, ErrTaskTimeout), isReplayedTimeout(err):
+			err = fmt.Errorf("%w after %s: %w", ErrTaskTimeout, step.task.Timeout, err)
+			transition = TransitionTimeout
+		case errors.Is(cause, ErrChainTimeout):
+			err = fmt.Errorf("%w after %s: %w", ErrChainTimeout, chain.Timeout, err)
+		}
+	}
+	return output, outputType, transition, verdicts, err
+}
@@ -18,11 +18,78 @@ type TaskHandler string
 const (
 	HandlePromptToString TaskHandler = "prompt_to_string"
 	HandlePromptToInt    TaskHandler = "prompt_to_int"
+	// HandlePromptToStructured prompts for JSON matching TaskDefinition.Structured.Schema,
+	// re-prompting with the validation error until the response conforms.
+	HandlePromptToStructured TaskHandler = "prompt_to_structured"
 	HandleRaiseError     TaskHandler = "raise_error"
 	HandleChatCompletion TaskHandler = "chat_completion"
 	HandleExecuteToolCalls TaskHandler = "execute_tool_calls"
 	HandleNoop TaskHandler = "noop"
 	HandleTools TaskHandler = "tools"
+	// HandleParallel fans the task input out to the branches listed in
+	// TaskDefinition.Parallel, runs them concurrently and merges their outputs
+	// into a JSON map keyed by branch task ID.
+	HandleParallel TaskHandler = "parallel"
+	// HandleForEach runs the task or sub-chain configured in
+	// TaskDefinition.ForEach once per element of a JSON array input and
+	// collects the outputs into a JSON array.
+	HandleForEach TaskHandler = "foreach"
+	// HandleCosineSimilarity computes the cosine similarity of the two vectors in
+	// the input ({"a": [...], "b": [...]} or a pair). The transition value is the
+	// score formatted with four decimals.
+	HandleCosineSimilarity TaskHandler = "cosine_similarity"
+	// HandleVectorTopK ranks {"candidates": [...]} by cosine similarity to
+	// {"query": [...]} and returns the best TaskDefinition.Vector.TopK matches.
+	// The transition value is the ID (or index) of the best match.
+	HandleVectorTopK TaskHandler = "vector_top_k"
+	// HandleVectorAverage returns the element-wise mean of an array of vectors.
+	HandleVectorAverage TaskHandler = "vector_average"
+	// HandleEmbedBatch embeds a JSON array of strings and returns a JSON array
+	// holding one vector per string, in input order. The strings are sent to
+	// the embedding model in batches of TaskDefinition.Vector.BatchSize. The
+	// transition value is the number of vectors.
+	HandleEmbedBatch TaskHandler = "embed_batch"
+	// HandleVectorSearch reranks {"candidates": [...]} by cosine similarity to
+	// {"query": ...} and returns the candidates best first, each with its own
+	// fields plus "index" and "score". A query or candidate given as text is
+	// embedded first. The transition value is the ID (or index) of the best
+	// match, or "no_match" when no candidate reaches Vector.MinScore.
+	HandleVectorSearch TaskHandler = "vector_search"
+	// HandleCoerce converts the task input to the type in TaskDefinition.Coerce.
+	// The transition value is the converted scalar ("ok" for JSON), or
+	// "coercion_failed" when the input cannot be converted.
+	HandleCoerce TaskHandler = "coerce"
+	// HandleAwaitApproval pauses a checkpointed run until the pending step is
+	// approved or rejected (see Approve and Reject). The rendered
+	// prompt_template, or the task input, is shown to the approver. The input
+	// is passed through and the transition value is "approved" or "rejected".
+	HandleAwaitApproval TaskHandler = "await_approval"
+	// HandleClarify asks the caller a question about ambiguous input instead
+	// of letting the model guess. In a checkpointed run it pauses until the
+	// question is answered (see Answer); the output is then the answer, or
+	// the chat history extended by the question and the answer, and the
+	// transition value is "answered". The question is the rendered
+	// prompt_template, or the task input. A run without checkpoints, such as
+	// a chat turn, ends with the question as its output instead.
+	HandleClarify TaskHandler = "clarify"
+	// HandleMapReduce splits a long input into token-bounded chunks, runs
+	// TaskDefinition.MapReduce.MapPrompt per chunk and combines the partial
+	// results with MapReduce.ReducePrompt. The output is the reduced string.
+	HandleMapReduce TaskHandler = "map_reduce"
+	// HandleAgentLoop alternates chat_completion and execute_tool_calls until
+	// the model answers without tool calls or TaskDefinition.AgentLoop's
+	// MaxIterations is reached. The output is the final chat history.
+	HandleAgentLoop TaskHandler = "agent_loop"
+	// HandleGlossary checks a string, or the last assistant message of a chat
+	// history, against TaskDefinition.Glossary. The corrected input is passed
+	// on; the transition value is "ok", or "glossary_violation" when the text
+	// still breaks the glossary.
+	HandleGlossary TaskHandler = "glossary"
+	// HandleJQTransform reshapes the task input with the jq query in
+	// TaskDefinition.JQ. The output is the single result of the query; the
+	// transition value is the result for strings, numbers and booleans, and
+	// "ok" for objects, arrays and null.
+	HandleJQTransform TaskHandler = "jq_transform"
 )
 
 func (t TaskHandler) String() string {
@@ -54,6 +121,12 @@ func (dt *DataType) UnmarshalJSON(data []byte) error {
 		*dt = DataTypeJSON
 	case "chat_history":
 		*dt = DataTypeChatHistory
+	case "nil":
+		*dt = DataTypeNil
+	case "vector":
+		*dt = DataTypeVector
+	case "file":
+		*dt = DataTypeFile
 	default:
 		return fmt.Errorf("unknown data type: %q", s)
 	}
@@ -76,6 +149,10 @@ func (dt *DataType) UnmarshalYAML(data []byte) error {
 		*dt = DataTypeJSON
 	case "chat_history":
 		*dt = DataTypeChatHistory
+	case "vector":
+		*dt = DataTypeVector
+	case "file":
+		*dt = DataTypeFile
 	default:
 		return fmt.Errorf("unknown data type: %q", s)
 	}
@@ -89,6 +166,12 @@ type TaskTransition struct {
 	// OnFailure is the task ID to jump to in case of failure.
 	OnFailure string `yaml:"on_failure" json:"on_failure" example:"error_handler"`
 
+	// OnError is the task ID to jump to in case of failure, like OnFailure,
+	// but the target receives the error message as its input
+	// (DataTypeString) so it can implement a recovery path. It takes
+	// precedence over OnFailure.
+	OnError string `yaml:"on_error,omitempty" json:"on_error,omitempty" example:"recover"`
+
 	// Branches defines conditional branches for successful task completion.
 	Branches []TransitionBranch `yaml:"branches" json:"branches" openapi_include_type:"taskengine.TransitionBranch"`
 }
@@ -109,11 +192,29 @@ type TransitionBranch struct {
 	// Leave empty or use taskengine.TermEnd to end the chain.
 	Goto string `yaml:"goto" json:"goto" example:"positive_response"`
 
+	// Weight is the relative share of a weighted branch, e.g. 90 and 10 for
+	// a 90/10 split. Ignored by other operators.
+	Weight float64 `yaml:"weight,omitempty" json:"weight,omitempty" example:"90"`
+
 	// Compose defines how to transform data when taking this branch.
 	// Optional - if not specified, the current task output is passed as-is.
 	Compose *BranchCompose `yaml:"compose,omitempty" json:"compose,omitempty" openapi_include_type:"taskengine.BranchCompose"`
 }
 
+// PromptVariant is one of several prompts a task draws from per execution.
+type PromptVariant struct {
+	// Name identifies the variant in the execution history and statistics.
+	Name string `yaml:"name" json:"name" example:"concise"`
+
+	// Weight is the relative share of executions that use the variant,
+	// e.g. 90 and 10 for a 90/10 split.
+	Weight float64 `yaml:"weight" json:"weight" example:"50"`
+
+	// PromptTemplate is the prompt sent when the variant is drawn. It
+	// supports the same template variables as the task's prompt_template.
+	PromptTemplate string `yaml:"prompt_template" json:"prompt_template" example:"Answer in one sentence: {{.input}}"`
+}
+
 // OperatorTerm represents logical operators used for task transition evaluation
 type OperatorTerm string
 
@@ -128,6 +229,13 @@ const (
 	OpLt          OperatorTerm = "lt"
 	OpInRange     OperatorTerm = "in_range"
 	OpDefault     OperatorTerm = "default"
+	// OpExpr evaluates When as a boolean expression over the task output and
+	// the chain variables, e.g. `output.score > 0.7 && vars.env == "prod"`.
+	OpExpr OperatorTerm = "expr"
+	// OpWeighted splits traffic at random: one weighted branch is picked with
+	// probability proportional to its Weight. A transition with weighted
+	// branches cannot have branches of other operators.
+	OpWeighted OperatorTerm = "weighted"
 )
 
 func (t OperatorTerm) String() string {
@@ -146,6 +254,8 @@ func SupportedOperators() []string {
 		string(OpLt),
 		string(OpInRange),
 		string(OpDefault),
+		string(OpExpr),
+		string(OpWeighted),
 	}
 }
 
@@ -171,6 +281,10 @@ func ToOperatorTerm(s string) (OperatorTerm, error) {
 		return OpInRange, nil
 	case string(OpDefault):
 		return OpDefault, nil
+	case string(OpExpr):
+		return OpExpr, nil
+	case string(OpWeighted):
+		return OpWeighted, nil
 	default:
 		return "", fmt.Errorf("unsupported operator: %s", s)
 	}
@@ -182,7 +296,24 @@ type LLMExecutionConfig struct {
 	Models           []string `yaml:"models,omitempty" json:"models,omitempty" example:"[\"gpt-4\", \"gpt-3.5-turbo\"]"`
 	Provider         string   `yaml:"provider,omitempty" json:"provider,omitempty" example:"ollama"`
 	Providers        []string `yaml:"providers,omitempty" json:"providers,omitempty" example:"[\"ollama\", \"openai\"]"`
+	// ModelFallback tries Model and then Models in the listed order instead of
+	// picking one of them at random: the next model is used only when the
+	// current one fails with a retryable error (timeout, rate limit, server
+	// error, connection failure). The model that served the call is recorded
+	// in the step's CapturedStateUnit. A task that sets it to false opts out
+	// of a chain default that enables it.
+	ModelFallback *bool   `yaml:"model_fallback,omitempty" json:"model_fallback,omitempty"`
 	Temperature      float32  `yaml:"temperature,omitempty" json:"temperature,omitempty" example:"0.7"`
+	// Sampling options beyond temperature. Zero values leave the provider
+	// default in place; providers ignore options they do not support (OpenAI
+	// has no top_k).
+	TopP             float32  `yaml:"top_p,omitempty" json:"top_p,omitempty" example:"0.9"`
+	TopK             int      `yaml:"top_k,omitempty" json:"top_k,omitempty" example:"40"`
+	FrequencyPenalty float32  `yaml:"frequency_penalty,omitempty" json:"frequency_penalty,omitempty" example:"0.5"`
+	PresencePenalty  float32  `yaml:"presence_penalty,omitempty" json:"presence_penalty,omitempty" example:"0.5"`
+	Stop             []string `yaml:"stop,omitempty" json:"stop,omitempty" example:"[\"###\"]"`
+	// MaxTokens caps the number of generated tokens.
+	MaxTokens int `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty" example:"1024"`
 	// Tools is the allowlist of tools names this task may invoke.
 	//
 	// Patterns supported:
@@ -195,6 +326,10 @@ type LLMExecutionConfig struct {
 	// Exclusions ("!name") are only meaningful when combined with "*".
 	Tools     []string `yaml:"tools,omitempty" json:"tools,omitempty" example:"[\"local_shell\", \"nws\"]"`
 	HideTools []string `yaml:"hide_tools,omitempty" json:"hide_tools,omitempty" example:"[\"tool1\", \"tools_name1.tool1\"]"`
+	// ToolChoice forces a call of a named tool, or of any tool ("required"),
+	// or disallows tool calls ("none") for each chat turn of the task. In an
+	// agent_loop a forced call applies to the first turn only.
+	ToolChoice *ToolChoice `yaml:"tool_choice,omitempty" json:"tool_choice,omitempty" openapi_include_type:"taskengine.ToolChoice"`
 	// ToolsPolicies carries per-tools policy overrides for this task.
 	// Keys are tools names; values are maps of policy key → value pairs.
 	// These are injected into the context before GetToolsForToolsByName is called,
@@ -250,6 +385,11 @@ type TaskDefinition struct {
 	// SystemInstruction provides additional instructions to the LLM, if applicable system level will be used.
 	SystemInstruction string `yaml:"system_instruction,omitempty" json:"system_instruction,omitempty" example:"You are a quality control assistant. Respond only with 'valid' or 'invalid'."`
 
+	// SystemInstructionMode controls how SystemInstruction combines with the
+	// chain's system_instruction: "append" (default) adds it after the chain
+	// instruction, "replace" uses it instead.
+	SystemInstructionMode string `yaml:"system_instruction_mode,omitempty" json:"system_instruction_mode,omitempty" example:"append"`
+
 	// ExecuteConfig defines the configuration for executing prompt or chat model tasks.
 	ExecuteConfig *LLMExecutionConfig `yaml:"execute_config,omitempty" json:"execute_config,omitempty" openapi_include_type:"taskengine.LLMExecutionConfig"`
 
@@ -270,6 +410,13 @@ type TaskDefinition struct {
 	// Example: "Rate the quality from 1-10: {{.input}}"
 	PromptTemplate string `yaml:"prompt_template" json:"prompt_template" example:"Is this input valid? {{.input}}"`
 
+	// PromptVariants are alternative prompts for A/B experiments. When set,
+	// each execution of the task draws one variant with probability
+	// proportional to its weight and sends its prompt instead of
+	// PromptTemplate. The variant is recorded in the execution history.
+	// Optional for all task types that take a prompt.
+	PromptVariants []PromptVariant `yaml:"prompt_variants,omitempty" json:"prompt_variants,omitempty" openapi_include_type:"taskengine.PromptVariant"`
+
 	// OutputTemplate is an optional go template to format the output of a tools.
 	// If specified, the tools's JSON output will be used as data for the template.
 	// The final output of the task will be the rendered string.
@@ -281,18 +428,337 @@ type TaskDefinition struct {
 	// Each task stores its output in a variable named with it's task id.
 	InputVar string `yaml:"input_var,omitempty" json:"input_var,omitempty" example:"input"`
 
+	// StoreAs writes the task's output into the chain variable store under this name.
+	// Stored values can be referenced by any later task as {{vars.name}} in
+	// prompt templates, print messages and tools args.
+	StoreAs string `yaml:"store_as,omitempty" json:"store_as,omitempty" example:"summary"`
+
+	// AppendTo adds the task's output to a list in the chain variable store
+	// every time the task runs, so loop iterations can build up results.
+	// Optional for all task types.
+	AppendTo *AppendConfig `yaml:"append_to,omitempty" json:"append_to,omitempty" openapi_include_type:"taskengine.AppendConfig"`
+
 	// Transition defines what to do after this task completes.
 	Transition TaskTransition `yaml:"transition" json:"transition" openapi_include_type:"taskengine.TaskTransition"`
 
 	// Timeout optionally sets a timeout for task execution.
 	// Format: "10s", "2m", "1h" etc.
-	// Optional for all task types.
+	// Optional for all task types. A timed out attempt reports the transition
+	// value "timeout"; if a branch matches it, the chain follows that branch
+	// with the task input as output once retries are exhausted, otherwise the
+	// timeout is handled like any other failure.
 	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty" example:"30s"`
 
 	// RetryOnFailure sets how many times to retry this task on failure.
 	// Applies to all task types including Tools.
 	// Default: 0 (no retries)
 	RetryOnFailure int `yaml:"retry_on_failure,omitempty" json:"retry_on_failure,omitempty" example:"2"`
+
+	// Retry configures re-execution of this task with backoff.
+	// When set, it takes precedence over RetryOnFailure.
+	// Optional for all task types.
+	Retry *TaskRetry `yaml:"retry,omitempty" json:"retry,omitempty" openapi_include_type:"taskengine.TaskRetry"`
+
+	// Parallel configures the fan-out of a parallel task.
+	// Required for Parallel tasks, must be nil/omitted for all other types.
+	Parallel *ParallelConfig `yaml:"parallel,omitempty" json:"parallel,omitempty" openapi_include_type:"taskengine.ParallelConfig"`
+
+	// ForEach configures the loop body of a foreach task.
+	// Required for ForEach tasks, must be nil/omitted for all other types.
+	ForEach *ForEachConfig `yaml:"foreach,omitempty" json:"foreach,omitempty" openapi_include_type:"taskengine.ForEachConfig"`
+
+	// Structured declares the JSON Schema the response must conform to.
+	// Required for PromptToStructured tasks, ignored for all other types.
+	Structured *StructuredOutputConfig `yaml:"structured,omitempty" json:"structured,omitempty" openapi_include_type:"taskengine.StructuredOutputConfig"`
+
+	// Vector configures the vector math handlers.
+	// Optional for VectorTopK tasks, ignored for all other types.
+	Vector *VectorConfig `yaml:"vector,omitempty" json:"vector,omitempty" openapi_include_type:"taskengine.VectorConfig"`
+
+	// Coerce configures the target type of a coerce task.
+	// Required for Coerce tasks, ignored for all other types.
+	Coerce *CoerceConfig `yaml:"coerce,omitempty" json:"coerce,omitempty" openapi_include_type:"taskengine.CoerceConfig"`
+
+	// MapReduce configures the chunking and prompts of a map_reduce task.
+	// Required for MapReduce tasks, ignored for all other types.
+	MapReduce *MapReduceConfig `yaml:"map_reduce,omitempty" json:"map_reduce,omitempty" openapi_include_type:"taskengine.MapReduceConfig"`
+
+	// AgentLoop bounds the iterations of an agent_loop task.
+	// Optional for AgentLoop tasks, ignored for all other types.
+	AgentLoop *AgentLoopConfig `yaml:"agent_loop,omitempty" json:"agent_loop,omitempty" openapi_include_type:"taskengine.AgentLoopConfig"`
+
+	// Glossary lists the preferred, variant and banned terms of a glossary task.
+	// Required for Glossary tasks, ignored for all other types.
+	Glossary *GlossaryConfig `yaml:"glossary,omitempty" json:"glossary,omitempty" openapi_include_type:"taskengine.GlossaryConfig"`
+
+	// JQ holds the query of a jq_transform task.
+	// Required for JQTransform tasks, ignored for all other types.
+	JQ *JQConfig `yaml:"jq,omitempty" json:"jq,omitempty" openapi_include_type:"taskengine.JQConfig"`
+
+	// Config holds the settings of a handler added with RegisterHandler.
+	// Ignored by the built-in handlers.
+	Config map[string]any `yaml:"config,omitempty" json:"config,omitempty"`
+}
+
+// AppendConfig describes the list a task appends its output to. The list
+// is reachable as {{vars.name}} like values written via store_as. When an
+// append exceeds a cap, the entries are replaced by the result of
+// SummarizePrompt, or without one the oldest entries are dropped until the
+// list fits again. The newest entry is always kept.
+// example:
+//
+// append_to:
+//
+//	var: findings
+//	max_items: 20
+//	max_tokens: 4000
+//	summarize_prompt: "Merge these findings into one list:\n{{.items}}"
+type AppendConfig struct {
+	// Var is the name of the list in the chain variable store.
+	Var string `yaml:"var" json:"var" example:"findings"`
+	// MaxItems caps the number of entries. 0 means unlimited.
+	MaxItems int `yaml:"max_items,omitempty" json:"max_items,omitempty" example:"20"`
+	// MaxTokens caps the estimated token size of all entries (~4 characters
+	// per token). 0 means unlimited.
+	MaxTokens int `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty" example:"4000"`
+	// SummarizePrompt is rendered with {{.items}} (the entries separated by
+	// blank lines) and {{.entries}} (the list) and runs with the task's
+	// execute_config and system_instruction. Its result becomes the only entry.
+	SummarizePrompt string `yaml:"summarize_prompt,omitempty" json:"summarize_prompt,omitempty" example:"Merge these findings into one list:\n{{.items}}"`
+}
+
+// AgentLoopConfig describes an agent_loop task. One iteration is a
+// chat_completion followed by execute_tool_calls for the requested tools.
+// example:
+//
+// agent_loop:
+//
+//	max_iterations: 8
+type AgentLoopConfig struct {
+	// MaxIterations caps the chat/tool rounds. Defaults to 10. When the
+	// model still calls tools after the last round, the task ends with the
+	// "max_iterations" transition value.
+	MaxIterations int `yaml:"max_iterations,omitempty" json:"max_iterations,omitempty" example:"8"`
+}
+
+// MapReduceConfig describes a map_reduce task. The map prompt is rendered
+// per chunk with {{.chunk}}, {{.index}} (1-based) and {{.total}}; the reduce
+// prompt with {{.results}} (the partial results separated by blank lines)
+// and {{.partials}} (the list). Both run with the task's execute_config and
+// system_instruction.
+// example:
+//
+// map_reduce:
+//
+//	chunk_tokens: 2000
+//	chunk_overlap: 100
+//	map_prompt: "List the decisions made in this part of the transcript:\n{{.chunk}}"
+//	reduce_prompt: "Merge these decision lists, removing duplicates:\n{{.results}}"
+type MapReduceConfig struct {
+	// ChunkTokens is the token budget of a chunk. Defaults to 2000.
+	ChunkTokens int `yaml:"chunk_tokens,omitempty" json:"chunk_tokens,omitempty" example:"2000"`
+	// ChunkOverlap is the number of tokens repeated from the end of the
+	// previous chunk. Defaults to 0.
+	ChunkOverlap int `yaml:"chunk_overlap,omitempty" json:"chunk_overlap,omitempty" example:"100"`
+	// MapPrompt is the template run once per chunk.
+	MapPrompt string `yaml:"map_prompt" json:"map_prompt" example:"Summarize:\n{{.chunk}}"`
+	// ReducePrompt is the template run over the partial results. When the
+	// partial results exceed ChunkTokens they are reduced in groups first.
+	ReducePrompt string `yaml:"reduce_prompt" json:"reduce_prompt" example:"Combine these summaries:\n{{.results}}"`
+	// MaxConcurrency caps the number of chunks mapped at the same time.
+	// 0 means unlimited.
+	MaxConcurrency int `yaml:"max_concurrency,omitempty" json:"max_concurrency,omitempty" example:"4"`
+}
+
+// CoerceConfig describes the conversion done by a coerce task.
+// Chat history input is reduced to its last assistant message first.
+// float and bool results are passed on as JSON scalars.
+// example:
+//
+// coerce:
+//
+//	to: "int"
+//	mode: "lenient"
+type CoerceConfig struct {
+	// To is the target type: string, int, float, bool or json.
+	To string `yaml:"to" json:"to" example:"int"`
+	// Mode is "strict" (default), which requires the whole input to be a
+	// valid value, or "lenient", which extracts the value from surrounding
+	// text such as "Score: 7/10" or a fenced JSON block.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty" example:"lenient"`
+}
+
+// JQConfig describes the transformation done by a jq_transform task.
+// A string input holding JSON is decoded first; a chat history is seen as
+// {"messages": [...]}.
+// example:
+//
+// jq:
+//
+//	query: "{title: .items[0].title, tags: [.items[].tag] | unique}"
+type JQConfig struct {
+	// Query is a jq program (https://jqlang.github.io/jq/manual/). It must
+	// produce exactly one result; wrap it in [...] to collect several.
+	Query string `yaml:"query" json:"query" example:".items | map(.name)"`
+}
+
+// GlossaryConfig describes the terminology enforced by a glossary task.
+// Terms match case-insensitively on word boundaries.
+// example:
+//
+// glossary:
+//
+//	mode: "correct"
+//	terms:
+//	  - term: "Contenox"
+//	  - term: "email"
+//	    variants: ["e-mail", "e mail"]
+//	banned: ["cheap", "guarantee"]
+type GlossaryConfig struct {
+	// Terms are the preferred terms and the variants to replace with them.
+	Terms []GlossaryTerm `yaml:"terms,omitempty" json:"terms,omitempty"`
+	// Banned are terms that must not appear. They are never corrected
+	// automatically and always cause a glossary_violation.
+	Banned []string `yaml:"banned,omitempty" json:"banned,omitempty" example:"[\"cheap\"]"`
+	// Mode is "correct" (default), which rewrites variants and miscased terms
+	// to the preferred term, or "check", which leaves the text unchanged and
+	// treats every match as a violation.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty" example:"correct"`
+}
+
+// GlossaryTerm is a preferred term and its unwanted spellings.
+type GlossaryTerm struct {
+	// Term is the preferred spelling, including its casing.
+	Term string `yaml:"term" json:"term" example:"Contenox"`
+	// Variants are spellings replaced by Term, e.g. "e-mail" for "email".
+	Variants []string `yaml:"variants,omitempty" json:"variants,omitempty" example:"[\"e-mail\"]"`
+	// IgnoreCase accepts Term in any casing.
+	IgnoreCase bool `yaml:"ignore_case,omitempty" json:"ignore_case,omitempty" example:"false"`
+}
+
+// StructuredOutputConfig describes the expected shape of a structured response.
+// example:
+//
+// structured:
+//
+//	schema:
+//	  type: object
+//	  required: ["title", "tags"]
+//	  properties:
+//	    title: {type: string}
+//	    tags: {type: array, items: {type: string}}
+//	max_attempts: 3
+type StructuredOutputConfig struct {
+	// Schema is the JSON Schema the model output is validated against.
+	Schema map[string]any `yaml:"schema" json:"schema"`
+	// MaxAttempts is the number of model calls made before giving up,
+	// each retry carrying the previous validation error. Default: 3
+	MaxAttempts int `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty" example:"3"`
+}
+
+// VectorConfig holds the options of the vector math handlers.
+type VectorConfig struct {
+	// TopK is the number of best matches returned by vector_top_k and
+	// vector_search. Default: 1 for vector_top_k, all for vector_search
+	TopK int `yaml:"top_k,omitempty" json:"top_k,omitempty" example:"3"`
+	// MinScore drops vector_search candidates whose similarity is below it.
+	// Zero keeps all candidates.
+	MinScore float64 `yaml:"min_score,omitempty" json:"min_score,omitempty" example:"0.6"`
+	// BatchSize is the number of strings embed_batch sends per embedding
+	// request. Default: 32
+	BatchSize int `yaml:"batch_size,omitempty" json:"batch_size,omitempty" example:"64"`
+	// Dimensions is the size the embedding model is expected to produce. An
+	// embedding of any other size fails the task instead of mixing vectors of
+	// different models. Zero accepts any size.
+	Dimensions int `yaml:"dimensions,omitempty" json:"dimensions,omitempty" example:"768"`
+	// TargetDimensions truncates and re-normalizes embeddings to this size,
+	// for Matryoshka models. Zero keeps the size the model produced.
+	TargetDimensions int `yaml:"target_dimensions,omitempty" json:"target_dimensions,omitempty" example:"256"`
+}
+
+// ForEachConfig describes the loop body of a foreach task.
+// Exactly one of Task or Chain must be set. With Task, each element is the
+// input of the referenced task and is also available to its prompt template as
+// {{.item}} (with {{.index}}); the task's own transitions are ignored. With
+// Chain, each element is the input of a full run of the inline sub-chain.
+// example:
+//
+// foreach:
+//
+//	task: "summarize_file"
+//	max_concurrency: 4
+//	fail_fast: true
+type ForEachConfig struct {
+	// Task is the ID of the task in this chain to run per element.
+	Task string `yaml:"task,omitempty" json:"task,omitempty" example:"summarize_file"`
+	// Chain is an inline sub-chain to run per element.
+	Chain *TaskChainDefinition `yaml:"chain,omitempty" json:"chain,omitempty" openapi_include_type:"taskengine.TaskChainDefinition"`
+	// MaxConcurrency caps the number of elements processed at the same time.
+	// 0 means unlimited.
+	MaxConcurrency int `yaml:"max_concurrency,omitempty" json:"max_concurrency,omitempty" example:"4"`
+	// FailFast cancels in-flight iterations and skips the remaining ones on the
+	// first error. By default every element is processed and all errors are reported.
+	FailFast bool `yaml:"fail_fast,omitempty" json:"fail_fast,omitempty" example:"true"`
+}
+
+// BackoffStrategy controls how the wait between task retries grows.
+type BackoffStrategy string
+
+const (
+	// BackoffNone retries immediately.
+	BackoffNone BackoffStrategy = "none"
+	// BackoffFixed waits Delay before every retry.
+	BackoffFixed BackoffStrategy = "fixed"
+	// BackoffExponential doubles Delay after every retry, capped at MaxDelay.
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// TaskRetry describes how a task is re-executed when an attempt fails or
+// evaluates to one of the RetryOn transition values.
+// example:
+//
+// retry:
+//
+//	max_attempts: 3
+//	backoff: exponential
+//	delay: 500ms
+//	max_delay: 5s
+//	retry_on: ["invalid"]
+type TaskRetry struct {
+	// MaxAttempts is the total number of attempts including the first.
+	// 0 or 1 disables retries.
+	MaxAttempts int `yaml:"max_attempts" json:"max_attempts" example:"3"`
+	// Backoff selects the wait strategy between attempts.
+	// Default: "fixed" when Delay is set, "none" otherwise.
+	Backoff BackoffStrategy `yaml:"backoff,omitempty" json:"backoff,omitempty" example:"exponential"`
+	// Delay is the wait before the first retry. Format: "500ms", "2s" etc.
+	Delay string `yaml:"delay,omitempty" json:"delay,omitempty" example:"500ms"`
+	// MaxDelay caps the exponential backoff. Empty means one hour, or Delay
+	// when that is longer.
+	MaxDelay string `yaml:"max_delay,omitempty" json:"max_delay,omitempty" example:"5s"`
+	// RetryOn lists transition values that trigger a retry even though the
+	// attempt succeeded. Failed attempts are always retried.
+	// When attempts are exhausted the last result is used as usual.
+	RetryOn []string `yaml:"retry_on,omitempty" json:"retry_on,omitempty" example:"[\"invalid\"]"`
+}
+
+// ParallelConfig describes the independent branches of a parallel task.
+// Each branch is the ID of another task in the chain; it receives the parallel
+// task's input, runs exactly once and its own transitions are ignored.
+// The parallel task's output is a JSON map of branch task ID -> branch output,
+// and every branch output is also stored as a variable named after the branch.
+// example:
+//
+// parallel:
+//
+//	branches: ["summarize", "extract_entities"]
+//	max_concurrency: 2
+type ParallelConfig struct {
+	// Branches lists the task IDs to run concurrently.
+	Branches []string `yaml:"branches" json:"branches" example:"[\"summarize\", \"extract_entities\"]"`
+	// MaxConcurrency caps the number of branches running at the same time.
+	// Zero or negative runs all branches at once.
+	MaxConcurrency int `yaml:"max_concurrency,omitempty" json:"max_concurrency,omitempty" example:"2"`
 }
 
 // BranchCompose is a task that composes multiple variables into a single output.
@@ -335,11 +801,60 @@ type TaskChainDefinition struct {
 	// Description provides a human-readable summary of the chain's purpose.
 	Description string `yaml:"description" json:"description"`
 
+	// Input optionally declares the type and schema of the chain input.
+	// ExecEnv coerces the input to it, or fails with ErrInvalidChainInput
+	// before the first task runs.
+	Input *ChainInput `yaml:"input,omitempty" json:"input,omitempty" openapi_include_type:"taskengine.ChainInput"`
+
 	// Tasks is the list of tasks to execute in sequence.
 	Tasks []TaskDefinition `yaml:"tasks" json:"tasks" openapi_include_type:"taskengine.TaskDefinition"`
 
+	// Imports merges the tasks of other chains into this one; see
+	// ChainImport and ResolveImports. Hosts resolve imports when loading the
+	// chain, so ExecEnv never sees them.
+	Imports []ChainImport `yaml:"imports,omitempty" json:"imports,omitempty" openapi_include_type:"taskengine.ChainImport"`
+
 	// TokenLimit is the token limit for the context window (used during execution).
 	TokenLimit int64 `yaml:"token_limit" json:"token_limit"`
+
+	// Timeout optionally bounds the execution of the whole chain.
+	// Format: "30s", "5m" etc. When it expires the running task is cancelled
+	// and ExecEnv fails with ErrChainTimeout.
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty" example:"5m"`
+
+	// MaxDuration optionally sets a soft wall-clock budget for the chain.
+	// Unlike Timeout it never cancels a running task: once only the reserve
+	// of WrapUp is left, the chain continues with the wrap-up task after the
+	// current task, and agent loops stop after their current iteration.
+	MaxDuration string `yaml:"max_duration,omitempty" json:"max_duration,omitempty" example:"10m"`
+
+	// WrapUp configures the task run when MaxDuration is nearly exhausted.
+	// Required with MaxDuration.
+	WrapUp *WrapUpConfig `yaml:"wrap_up,omitempty" json:"wrap_up,omitempty" openapi_include_type:"taskengine.WrapUpConfig"`
+
+	// SystemInstruction is the chain-wide system instruction, e.g. a persona.
+	// Every task starts from it; see TaskDefinition.SystemInstructionMode.
+	SystemInstruction string `yaml:"system_instruction,omitempty" json:"system_instruction,omitempty" example:"You are a concise support assistant for Acme."`
+
+	// DefaultExecuteConfig is inherited by every task of the chain. Fields a task
+	// sets in its own execute_config take precedence; unset (zero-valued) fields
+	// fall back to the chain default, so swapping the model of a chain is a
+	// one-line change.
+	DefaultExecuteConfig *LLMExecutionConfig `yaml:"default_execute_config,omitempty" json:"default_execute_config,omitempty" openapi_include_type:"taskengine.LLMExecutionConfig"`
+
+	// Fallback optionally replaces the error of a run whose task could not
+	// resolve a model, e.g. because no backend is reachable.
+	Fallback *ChainFallback `yaml:"fallback,omitempty" json:"fallback,omitempty" openapi_include_type:"taskengine.ChainFallback"`
+
+	// MaxFileSize bounds the size in bytes of DataTypeFile values flowing
+	// through the chain. Zero means DefaultMaxFileSize.
+	MaxFileSize int64 `yaml:"max_file_size,omitempty" json:"max_file_size,omitempty" example:"10485760"`
+
+	// RateLimits caps the model calls per minute of this chain per provider
+	// type, across all of its concurrent runs; "*" applies to every other
+	// provider. They apply on top of the server-wide limits of the
+	// executor's RateLimiter, so they can only lower them.
+	RateLimits map[string]int `yaml:"rate_limits,omitempty" json:"rate_limits,omitempty"`
 }
 
 // ChatHistory represents a conversation history with an LLM.
@@ -371,10 +886,31 @@ type Message struct {
 	ToolCallID string `json:"tool_call_id,omitempty"`
 	// CallTools is the tool call of the message sender.
 	CallTools []ToolCall `json:"callTools,omitempty"`
+	// Attachments are images sent along with the message to vision models.
+	Attachments []Attachment `json:"attachments,omitempty"`
 	// Timestamp is the time the message was sent.
 	Timestamp time.Time `json:"timestamp" example:"2023-11-15T14:30:45Z"`
 }
 
+// Attachment is an image attached to a chat message, given either by URL or
+// inline as Data. Data is base64-encoded when marshalled to JSON.
+type Attachment struct {
+	MimeType string `json:"mimeType" example:"image/png"`
+	URL      string `json:"url,omitempty" example:"https://example.com/cat.png"`
+	Data     []byte `json:"data,omitempty" openapi_include_type:"string"`
+}
+
+// Validate reports whether the attachment can be sent to a model.
+func (a Attachment) Validate() error {
+	if !strings.HasPrefix(a.MimeType, "image/") {
+		return fmt.Errorf("attachment mime type %q is not supported, only image/* is", a.MimeType)
+	}
+	if (a.URL == "") == (len(a.Data) == 0) {
+		return fmt.Errorf("attachment must set exactly one of url and data")
+	}
+	return nil
+}
+
 // Tool represents a tool that can be called by the model.
 type Tool struct {
 	Type     string       `json:"type"`
@@ -0,0 +1,66 @@
+package taskengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// TransitionTimeout is the transition value reported by a task whose own
+// timeout expired. Chains branch on it with an explicit branch, e.g.
+// {operator: equals, when: timeout, goto: fallback}.
+const TransitionTimeout = "timeout"
+
+// ErrTaskTimeout is wrapped into the error of a task whose timeout expired.
+var ErrTaskTimeout = errors.New("task timed out")
+
+// ErrChainTimeout is returned by ExecEnv when the chain timeout expired.
+var ErrChainTimeout = errors.New("chain timed out")
+
+// parseTimeout parses a chain or task timeout. Empty means no timeout.
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %v", err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %q %w", s, errdefs.ErrBadRequest)
+	}
+	return d, nil
+}
+
+// withTaskTimeout bounds ctx by the timeout of task, if it has one, with
+// ErrTaskTimeout as the cause.
+func withTaskTimeout(ctx context.Context, task *TaskDefinition) (context.Context, context.CancelFunc, error) {
+	timeout, err := parseTimeout(task.Timeout)
+	if err != nil {
+		return ctx, func() {}, err
+	}
+	if timeout == 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTaskTimeout)
+	return ctx, cancel, nil
+}
+
+// handlesFailure reports whether transition has an explicit branch matching
+// the failure transition value (TransitionTimeout, TransitionCoercionFailed).
+// Default branches do not count, so chains without such a branch keep
+// treating the failure like any other task error.
+func handlesFailure(transition TaskTransition, value string) bool {
+	for _, branch := range transition.Branches {
+		if branch.Operator == OpDefault {
+			continue
+		}
+		if ok, err := compare(branch.Operator, value, branch.When); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowExecutor blocks tasks listed in delays until the delay passes or the
+// context is done; every other task echoes its input.
+type slowExecutor struct {
+	delays map[string]time.Duration
+}
+
+func (s *slowExecutor) TaskExec(ctx context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, _ taskengine.DataType) (any, taskengine.DataType, string, error) {
+	if d, ok := s.delays[task.ID]; ok {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return nil, taskengine.DataTypeAny, "", ctx.Err()
+		}
+	}
+	return task.ID + ":" + input.(string), taskengine.DataTypeString, "ok", nil
+}
+
+func endBranch() taskengine.TransitionBranch {
+	return taskengine.TransitionBranch{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}
+}
+
+func TestTimeout_TaskBranchesOnTimeout(t *testing.T) {
+	env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"slow": time.Second}})
+	chain := &taskengine.TaskChainDefinition{
+		ID: "timeout-branch",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:      "slow",
+				Handler: taskengine.HandleNoop,
+				Timeout: "20ms",
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpEquals, When: taskengine.TransitionTimeout, Goto: "fallback"},
+						endBranch(),
+					},
+				},
+			},
+			{
+				ID:         "fallback",
+				Handler:    taskengine.HandleNoop,
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+			},
+		},
+	}
+
+	out, _, history, err := env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "fallback:in", out)
+	require.NotEmpty(t, history)
+	assert.Equal(t, taskengine.TransitionTimeout, history[0].Transition)
+	assert.Contains(t, history[0].Error.Error, "task timed out")
+}
+
+func TestTimeout_TaskWithoutTimeoutBranchFails(t *testing.T) {
+	env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"slow": time.Second}})
+	chain := &taskengine.TaskChainDefinition{
+		ID: "timeout-fail",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:         "slow",
+				Handler:    taskengine.HandleNoop,
+				Timeout:    "20ms",
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+			},
+		},
+	}
+
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task timed out")
+}
+
+func TestTimeout_Chain(t *testing.T) {
+	env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"second": time.Second}})
+	chain := &taskengine.TaskChainDefinition{
+		ID:      "chain-timeout",
+		Timeout: "50ms",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:         "first",
+				Handler:    taskengine.HandleNoop,
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: "second"}}},
+			},
+			{
+				ID:      "second",
+				Handler: taskengine.HandleNoop,
+				// A task-level timeout branch does not catch the chain deadline.
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpEquals, When: taskengine.TransitionTimeout, Goto: taskengine.TermEnd},
+						endBranch(),
+					},
+				},
+			},
+		},
+	}
+
+	start := time.Now()
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, taskengine.ErrChainTimeout)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestTimeout_InvalidChainTimeout(t *testing.T) {
+	env := setupTestEnv(&slowExecutor{})
+	chain := &taskengine.TaskChainDefinition{
+		ID:      "bad",
+		Timeout: "soon",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "a", Handler: taskengine.HandleNoop, Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}}},
+		},
+	}
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid timeout")
+}
+
+func TestTimeout_MaxDurationRoutesToWrapUp(t *testing.T) {
+	env := setupTestEnv(&slowExecutor{delays: map[string]time.Duration{"work": 50 * time.Millisecond}})
+	next := func(id string) taskengine.TaskTransition {
+		return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}}}
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID:          "wrap-up",
+		MaxDuration: "100ms",
+		WrapUp:      &taskengine.WrapUpConfig{Task: "summarize", Reserve: "80ms"},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "work", Handler: taskengine.HandleNoop, Transition: next("more")},
+			{ID: "more", Handler: taskengine.HandleNoop, Transition: next(taskengine.TermEnd)},
+			{ID: "summarize", Handler: taskengine.HandleNoop, Transition: next(taskengine.TermEnd)},
+		},
+	}
+	require.NoError(t, taskengine.ValidateChain(chain).Err())
+
+	out, _, _, err := env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "summarize:work:in", out)
+
+	chain.WrapUp.Reserve = ""
+	out, _, _, err = env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "more:work:in", out, "the chain ends before its budget is nearly exhausted")
+}
+
+func TestTimeout_MaxDurationRequiresWrapUp(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID:          "no-wrap-up",
+		MaxDuration: "10m",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "work", Handler: taskengine.HandleNoop, Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}}},
+		},
+	}
+	assert.True(t, taskengine.ValidateChain(chain).HasErrors())
+
+	_, _, _, err := setupTestEnv(&slowExecutor{}).ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wrap_up")
+}
+
+// budgetExecutor echoes its input like slowExecutor, but reports the
+// transition listed for a task and fails the tasks in failOn.
+type budgetExecutor struct {
+	transitions map[string]string
+	failOn      map[string]bool
+}
+
+func (b *budgetExecutor) TaskExec(_ context.Context, _ time.Time, _ int, _ *taskengine.ChainContext, task *taskengine.TaskDefinition, input any, _ taskengine.DataType) (any, taskengine.DataType, string, error) {
+	if b.failOn[task.ID] {
+		return nil, taskengine.DataTypeAny, "", errors.New("interrupted")
+	}
+	transition, ok := b.transitions[task.ID]
+	if !ok {
+		transition = "ok"
+	}
+	return task.ID + ":" + input.(string), taskengine.DataTypeString, transition, nil
+}
+
+func TestTimeout_WrapUpTransitionWithoutBranch(t *testing.T) {
+	env := setupTestEnv(&budgetExecutor{transitions: map[string]string{"agent": taskengine.TransitionWrapUp}})
+	chain := &taskengine.TaskChainDefinition{
+		ID:          "wrap-up-implicit",
+		MaxDuration: "10m",
+		WrapUp:      &taskengine.WrapUpConfig{Task: "summarize"},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "agent", Handler: taskengine.HandleNoop, Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+				{Operator: taskengine.OpEquals, When: "ok", Goto: taskengine.TermEnd},
+			}}},
+			{ID: "summarize", Handler: taskengine.HandleNoop, Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}}},
+		},
+	}
+
+	out, _, _, err := env.ExecEnv(context.Background(), chain, "in", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "summarize:agent:in", out)
+}
+
+func TestTimeout_ResumeKeepsWrapUpDeadline(t *testing.T) {
+	next := func(id string) taskengine.TaskTransition {
+		return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}}}
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID:          "wrap-up-resume",
+		MaxDuration: "10m",
+		WrapUp:      &taskengine.WrapUpConfig{Task: "summarize"},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "work", Handler: taskengine.HandleNoop, Transition: next("more")},
+			{ID: "more", Handler: taskengine.HandleNoop, Transition: next("report")},
+			{ID: "report", Handler: taskengine.HandleNoop, Transition: next(taskengine.TermEnd)},
+			{ID: "summarize", Handler: taskengine.HandleNoop, Transition: next(taskengine.TermEnd)},
+		},
+	}
+	store := &memCheckpointStore{}
+	ctx := taskengine.WithCheckpoints(context.Background(), store, "run-budget")
+
+	_, _, _, err := setupTestEnv(&budgetExecutor{failOn: map[string]bool{"more": true}}).
+		ExecEnv(ctx, chain, "in", taskengine.DataTypeString)
+	require.Error(t, err)
+
+	cp, err := store.LoadCheckpoint(ctx, "run-budget")
+	require.NoError(t, err)
+	require.NotNil(t, cp.WrapUpDeadline)
+	assert.WithinDuration(t, time.Now().Add(8*time.Minute), *cp.WrapUpDeadline, time.Minute)
+
+	// The budget of the interrupted run is used up by the time it resumes.
+	past := time.Now().Add(-time.Second)
+	cp.WrapUpDeadline = &past
+	require.NoError(t, store.SaveCheckpoint(ctx, cp))
+
+	out, _, _, err := setupTestEnv(&budgetExecutor{}).ExecEnv(ctx, cp.Chain, "ignored on resume", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "summarize:more:work:in", out)
+}
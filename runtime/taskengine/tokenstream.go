@@ -0,0 +1,57 @@
+package taskengine
+
+import "context"
+
+// TokenChunk is a partial model response emitted while a prompt or
+// chat_completion task is streaming.
+type TokenChunk struct {
+	ChainID   string `json:"chainId,omitempty"`
+	TaskID    string `json:"taskId,omitempty"`
+	ModelName string `json:"modelName,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Thinking  string `json:"thinking,omitempty"`
+}
+
+// TokenStreamFunc receives partial tokens as they are generated. It is called
+// synchronously from the executing task and must not block for long.
+type TokenStreamFunc func(chunk TokenChunk)
+
+type tokenStreamKey struct{}
+
+// WithTokenStream attaches fn to ctx so LLM tasks stream their response and
+// hand each chunk to fn while it is generated. The task output is unchanged:
+// the full response is still buffered and returned when the stream ends.
+//
+// Streaming is skipped for chat_completion turns that expose tools, since tool
+// calls are only available on the non-streaming path.
+func WithTokenStream(ctx context.Context, fn TokenStreamFunc) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tokenStreamKey{}, fn)
+}
+
+// WithTokenChannel is like WithTokenStream but delivers chunks on ch.
+// Sends block until received or ctx is done, so the consumer controls pacing.
+func WithTokenChannel(ctx context.Context, ch chan<- TokenChunk) context.Context {
+	if ch == nil {
+		return ctx
+	}
+	return WithTokenStream(ctx, func(chunk TokenChunk) {
+		select {
+		case ch <- chunk:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// tokenStreamFromContext returns the callback attached via WithTokenStream, if any.
+func tokenStreamFromContext(ctx context.Context) TokenStreamFunc {
+	fn, _ := ctx.Value(tokenStreamKey{}).(TokenStreamFunc)
+	return fn
+}
+
+// streamingRequested reports whether the task should use the streaming path.
+func (exe *SimpleExec) streamingRequested(ctx context.Context) bool {
+	return exe.eventSink.Enabled() || tokenStreamFromContext(ctx) != nil
+}
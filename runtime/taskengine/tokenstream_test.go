@@ -0,0 +1,97 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenStreamEnv(t *testing.T) taskengine.EnvExecutor {
+	t.Helper()
+	repo := &mockModelRepo{
+		streamFunc: func(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
+			ch := make(chan *libmodelprovider.StreamParcel, 3)
+			ch <- &libmodelprovider.StreamParcel{Thinking: "hmm"}
+			ch <- &libmodelprovider.StreamParcel{Data: "hello "}
+			ch <- &libmodelprovider.StreamParcel{Data: "world"}
+			close(ch)
+			return ch, llmrepo.Meta{ModelName: "test-model"}, nil
+		},
+	}
+	// No task event sink: streaming must be driven by the token callback alone.
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+	return env
+}
+
+func tokenStreamChain() *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "chain.tokens",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "reply",
+				Handler:        taskengine.HandlePromptToString,
+				PromptTemplate: "Say hi to {{.input}}",
+				ExecuteConfig:  &taskengine.LLMExecutionConfig{Model: "test-model"},
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+}
+
+func TestTokenStream_CallbackReceivesChunks(t *testing.T) {
+	env := newTokenStreamEnv(t)
+
+	var chunks []taskengine.TokenChunk
+	ctx := taskengine.WithTokenStream(context.Background(), func(chunk taskengine.TokenChunk) {
+		chunks = append(chunks, chunk)
+	})
+
+	result, _, _, err := env.ExecEnv(ctx, tokenStreamChain(), "world", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", result)
+
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "hmm", chunks[0].Thinking)
+	assert.Equal(t, "hello ", chunks[1].Content)
+	assert.Equal(t, "world", chunks[2].Content)
+	for _, c := range chunks {
+		assert.Equal(t, "chain.tokens", c.ChainID)
+		assert.Equal(t, "reply", c.TaskID)
+		assert.Equal(t, "test-model", c.ModelName)
+	}
+}
+
+func TestTokenStream_Channel(t *testing.T) {
+	env := newTokenStreamEnv(t)
+
+	ch := make(chan taskengine.TokenChunk)
+	ctx := taskengine.WithTokenChannel(context.Background(), ch)
+
+	var content string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunk := range ch {
+			content += chunk.Content
+		}
+	}()
+
+	result, _, _, err := env.ExecEnv(ctx, tokenStreamChain(), "world", taskengine.DataTypeString)
+	close(ch)
+	<-done
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", result)
+	assert.Equal(t, "hello world", content)
+}
@@ -0,0 +1,120 @@
+package taskengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrInvalidToolArgs is returned by validateToolArgs when model-produced
+// arguments do not match the tool's parameter schema.
+var ErrInvalidToolArgs = errors.New("invalid tool arguments")
+
+// validateToolArgs checks args against the JSON Schema of tool.Parameters.
+// Local references into $defs or definitions are inlined first. Tools without
+// a schema, or with one that cannot be parsed or resolved (e.g. remote or
+// recursive references), accept any arguments: the tool itself remains the
+// final judge.
+func validateToolArgs(tool Tool, args map[string]any) error {
+	if tool.Function.Parameters == nil {
+		return nil
+	}
+	raw, err := json.Marshal(tool.Function.Parameters)
+	if err != nil {
+		return nil
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+	resolved, ok := inlineLocalRefs(doc, doc, nil)
+	if !ok {
+		return nil
+	}
+	if raw, err = json.Marshal(resolved); err != nil {
+		return nil
+	}
+	var schema openapi3.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil
+	}
+	if err := schema.VisitJSON(args, openapi3.MultiErrors()); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToolArgs, err)
+	}
+	return nil
+}
+
+// inlineLocalRefs returns node with every {"$ref": "#/$defs/..."} or
+// "#/definitions/..." replaced by the referenced schema of root, and the
+// definition sections dropped. It reports false for references it cannot
+// inline; seen holds the references being expanded to detect recursion.
+func inlineLocalRefs(node any, root map[string]any, seen []string) (any, bool) {
+	switch v := node.(type) {
+	case map[string]any:
+		if ref, isRef := v["$ref"].(string); isRef {
+			if len(v) > 1 || slices.Contains(seen, ref) {
+				return nil, false
+			}
+			target, found := lookupLocalRef(root, ref)
+			if !found {
+				return nil, false
+			}
+			return inlineLocalRefs(target, root, append(seen, ref))
+		}
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			if k == "$defs" || k == "definitions" {
+				continue
+			}
+			resolved, ok := inlineLocalRefs(child, root, seen)
+			if !ok {
+				return nil, false
+			}
+			out[k] = resolved
+		}
+		return out, true
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			resolved, ok := inlineLocalRefs(child, root, seen)
+			if !ok {
+				return nil, false
+			}
+			out[i] = resolved
+		}
+		return out, true
+	default:
+		return node, true
+	}
+}
+
+// lookupLocalRef resolves a JSON pointer such as "#/$defs/Address" in root.
+func lookupLocalRef(root map[string]any, ref string) (any, bool) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	var node any = root
+	for _, part := range strings.Split(pointer, "/") {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, isMap := node.(map[string]any)
+		if !isMap {
+			return nil, false
+		}
+		if node, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// invalidToolArgsMessage is the tool result sent back to the model for a call
+// rejected by validateToolArgs, so it can retry with corrected arguments.
+func invalidToolArgsMessage(toolName string, err error, schema any) string {
+	raw, _ := json.Marshal(schema)
+	return fmt.Sprintf("tool %s was not called: %v\nCall it again with arguments that conform to this JSON Schema:\n%s", toolName, err, raw)
+}
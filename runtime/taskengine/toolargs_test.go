@@ -0,0 +1,93 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ExecuteToolCalls_RejectsArgsBreakingSchema(t *testing.T) {
+	toolsRepo := tools.NewMockToolsRegistry()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, toolsRepo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"text"},
+		"properties": map[string]any{
+			"text": map[string]any{"type": "string"},
+		},
+	}
+	chainCtx := &taskengine.ChainContext{Tools: map[string]taskengine.ToolWithResolution{
+		"echo.say": {Tool: taskengine.Tool{Type: "function", Function: taskengine.FunctionTool{Name: "echo.say", Parameters: schema}}, ToolsName: "echo"},
+	}}
+	call := func(id, args string) taskengine.ToolCall {
+		return taskengine.ToolCall{ID: id, Type: "function", Function: taskengine.FunctionCall{Name: "echo.say", Arguments: args}}
+	}
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "say hi"},
+		{Role: "assistant", CallTools: []taskengine.ToolCall{
+			call("bad", `{"text": 42}`),
+			call("good", `{"text": "hi"}`),
+		}},
+	}}
+	task := &taskengine.TaskDefinition{ID: "tools", Handler: taskengine.HandleExecuteToolCalls}
+
+	out, _, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, chainCtx, task, history, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	assert.Equal(t, "tools_executed", transition)
+	require.Len(t, toolsRepo.Calls, 1, "only the valid call reaches the tool")
+	assert.Equal(t, map[string]any{"text": "hi"}, toolsRepo.Calls[0].Input)
+
+	msgs := out.(taskengine.ChatHistory).Messages
+	require.Len(t, msgs, 4)
+	assert.Equal(t, "bad", msgs[2].ToolCallID)
+	assert.Contains(t, msgs[2].Content, "tool echo.say was not called")
+	assert.Contains(t, msgs[2].Content, `"required":["text"]`)
+	assert.Equal(t, "good", msgs[3].ToolCallID)
+}
+
+func TestUnit_ExecuteToolCalls_ResolvesLocalSchemaRefs(t *testing.T) {
+	toolsRepo := tools.NewMockToolsRegistry()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, toolsRepo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"to"},
+		"properties": map[string]any{
+			"to": map[string]any{"$ref": "#/$defs/address"},
+		},
+		"$defs": map[string]any{
+			"address": map[string]any{
+				"type":       "object",
+				"required":   []any{"city"},
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			},
+		},
+	}
+	chainCtx := &taskengine.ChainContext{Tools: map[string]taskengine.ToolWithResolution{
+		"mail.send": {Tool: taskengine.Tool{Type: "function", Function: taskengine.FunctionTool{Name: "mail.send", Parameters: schema}}, ToolsName: "mail"},
+	}}
+	call := func(id, args string) taskengine.ToolCall {
+		return taskengine.ToolCall{ID: id, Type: "function", Function: taskengine.FunctionCall{Name: "mail.send", Arguments: args}}
+	}
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "send it"},
+		{Role: "assistant", CallTools: []taskengine.ToolCall{
+			call("bad", `{"to": {"city": 7}}`),
+			call("good", `{"to": {"city": "Berlin"}}`),
+		}},
+	}}
+	task := &taskengine.TaskDefinition{ID: "tools", Handler: taskengine.HandleExecuteToolCalls}
+
+	out, _, _, err := exec.TaskExec(context.Background(), time.Now(), 0, chainCtx, task, history, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Len(t, toolsRepo.Calls, 1, "the referenced schema is enforced and a valid call still passes")
+	assert.Equal(t, map[string]any{"to": map[string]any{"city": "Berlin"}}, toolsRepo.Calls[0].Input)
+	assert.Contains(t, out.(taskengine.ChatHistory).Messages[2].Content, "tool mail.send was not called")
+}
@@ -0,0 +1,118 @@
+package taskengine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+)
+
+// Tool choice modes of ToolChoice.Mode.
+const (
+	// ToolChoiceAuto lets the model decide whether to call a tool.
+	ToolChoiceAuto = "auto"
+	// ToolChoiceNone disallows tool calls for the turn.
+	ToolChoiceNone = "none"
+	// ToolChoiceRequired makes the model call one of the exposed tools.
+	ToolChoiceRequired = "required"
+)
+
+// ToolChoice controls the tool calls of a chat_completion turn. It is written
+// either as a mode string or as an object naming the tool to call:
+//
+//	tool_choice: none
+//	tool_choice: {name: local_shell}
+//
+// Providers with a native tool_choice parameter enforce it; for the others
+// the choice is emulated by hiding tools and instructing the model.
+type ToolChoice struct {
+	// Mode is auto, none or required. Leave it empty when Name is set.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty" example:"required"`
+	// Name is the tool the model must call. It must be one of the tools
+	// exposed to the task.
+	Name string `yaml:"name,omitempty" json:"name,omitempty" example:"local_shell"`
+}
+
+// UnmarshalJSON accepts a mode string as well as the object form.
+func (c *ToolChoice) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '"' {
+		var mode string
+		if err := json.Unmarshal(trimmed, &mode); err != nil {
+			return err
+		}
+		*c = ToolChoice{Mode: mode}
+		return nil
+	}
+	type plain ToolChoice
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*c = ToolChoice(p)
+	return nil
+}
+
+// forcesCall reports whether the choice makes the model call a tool.
+func (c *ToolChoice) forcesCall() bool {
+	return c != nil && (c.Name != "" || c.Mode == ToolChoiceRequired)
+}
+
+// checkToolChoice reports an unknown mode or a mode combined with a name.
+func checkToolChoice(c *ToolChoice) error {
+	if c == nil {
+		return nil
+	}
+	switch c.Mode {
+	case "", ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+	default:
+		return fmt.Errorf("unknown tool_choice mode %q, use auto, none or required %w", c.Mode, errdefs.ErrBadRequest)
+	}
+	if c.Name != "" && c.Mode != "" {
+		return fmt.Errorf("tool_choice takes either a mode or a tool name %w", errdefs.ErrBadRequest)
+	}
+	return nil
+}
+
+// resolveToolChoice maps c to the provider tool choice for the exposed tools.
+// A tool name matches an exposed tool exactly or, when that is unambiguous,
+// by the name after its "toolsName." prefix.
+func resolveToolChoice(c *ToolChoice, tools []libmodelprovider.Tool) (*libmodelprovider.ToolChoice, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if err := checkToolChoice(c); err != nil {
+		return nil, err
+	}
+	if c.Name == "" {
+		if c.Mode == "" {
+			return nil, nil
+		}
+		if c.Mode == ToolChoiceRequired && len(tools) == 0 {
+			return nil, fmt.Errorf("tool_choice required but no tools are exposed to the task %w", errdefs.ErrBadRequest)
+		}
+		return &libmodelprovider.ToolChoice{Mode: c.Mode}, nil
+	}
+	var matches []string
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		if t.Function.Name == c.Name {
+			return &libmodelprovider.ToolChoice{Name: c.Name}, nil
+		}
+		if strings.HasSuffix(t.Function.Name, "."+c.Name) {
+			matches = append(matches, t.Function.Name)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return &libmodelprovider.ToolChoice{Name: matches[0]}, nil
+	case 0:
+		return nil, fmt.Errorf("tool_choice names tool %q, which is not exposed to the task %w", c.Name, errdefs.ErrBadRequest)
+	default:
+		return nil, fmt.Errorf("tool_choice name %q is ambiguous: %s %w", c.Name, strings.Join(matches, ", "), errdefs.ErrBadRequest)
+	}
+}
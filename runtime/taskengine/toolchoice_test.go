@@ -0,0 +1,94 @@
+package taskengine_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	libmodelprovider "github.com/contenox/contenox/runtime/internal/modelrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatToolChoices returns the tool choice passed to every Chat call of repo.
+func chatToolChoices(repo *mockModelRepo) []*libmodelprovider.ToolChoice {
+	out := make([]*libmodelprovider.ToolChoice, len(repo.chatOpts))
+	for i, opts := range repo.chatOpts {
+		cfg := &libmodelprovider.ChatConfig{}
+		for _, o := range opts {
+			o.Apply(cfg)
+		}
+		out[i] = cfg.ToolChoice
+	}
+	return out
+}
+
+func runToolChoiceLoop(t *testing.T, repo *mockModelRepo, choice *taskengine.ToolChoice) (string, error) {
+	t.Helper()
+	toolsRepo := tools.NewMockToolsRegistry()
+	toolsRepo.ResponseMap["echo"] = tools.ToolsResponse{Output: "hi"}
+	exec, err := taskengine.NewExec(context.Background(), repo, toolsRepo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	chainCtx := &taskengine.ChainContext{Tools: map[string]taskengine.ToolWithResolution{
+		"echo.say": {Tool: taskengine.Tool{Type: "function", Function: taskengine.FunctionTool{Name: "echo.say"}}, ToolsName: "echo"},
+	}}
+	task := &taskengine.TaskDefinition{
+		ID:      "agent",
+		Handler: taskengine.HandleAgentLoop,
+		ExecuteConfig: &taskengine.LLMExecutionConfig{
+			Model:      "test-model",
+			Tools:      []string{"echo"},
+			ToolChoice: choice,
+		},
+	}
+	_, _, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, chainCtx, task, "say hi", taskengine.DataTypeString)
+	return transition, err
+}
+
+func TestUnit_ToolChoice_ForcesFirstTurnOfAgentLoop(t *testing.T) {
+	repo := toolCallingRepo(1)
+	transition, err := runToolChoiceLoop(t, repo, &taskengine.ToolChoice{Name: "say"})
+	require.NoError(t, err)
+	assert.Equal(t, "executed", transition)
+	assert.Equal(t, []*libmodelprovider.ToolChoice{{Name: "echo.say"}, nil}, chatToolChoices(repo),
+		"the short name resolves to the exposed tool, and later turns are not forced")
+}
+
+func TestUnit_ToolChoice_None(t *testing.T) {
+	repo := toolCallingRepo(0)
+	_, err := runToolChoiceLoop(t, repo, &taskengine.ToolChoice{Mode: taskengine.ToolChoiceNone})
+	require.NoError(t, err)
+	assert.Equal(t, []*libmodelprovider.ToolChoice{{Mode: "none"}}, chatToolChoices(repo))
+}
+
+func TestUnit_ToolChoice_UnknownTool(t *testing.T) {
+	repo := toolCallingRepo(1)
+	_, err := runToolChoiceLoop(t, repo, &taskengine.ToolChoice{Name: "missing"})
+	require.ErrorContains(t, err, `tool_choice names tool "missing"`)
+	assert.Empty(t, repo.chatOpts, "the model is not called")
+}
+
+func TestUnit_ToolChoice_Decode(t *testing.T) {
+	var cfg taskengine.LLMExecutionConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"tool_choice": "required"}`), &cfg))
+	assert.Equal(t, &taskengine.ToolChoice{Mode: taskengine.ToolChoiceRequired}, cfg.ToolChoice)
+	require.NoError(t, json.Unmarshal([]byte(`{"tool_choice": {"name": "local_shell"}}`), &cfg))
+	assert.Equal(t, &taskengine.ToolChoice{Name: "local_shell"}, cfg.ToolChoice)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID: "c",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:            "chat",
+			Handler:       taskengine.HandleChatCompletion,
+			ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "m", ToolChoice: &taskengine.ToolChoice{Mode: "sometimes"}},
+			Transition:    taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{endBranch()}},
+		}},
+	}
+	diags := taskengine.ValidateChain(chain)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "execute_config.tool_choice", diags[0].Field)
+}
@@ -29,3 +29,29 @@ func ToolsArgsFromContext(ctx context.Context, toolsName string) map[string]stri
 	m, _ := ctx.Value(toolsArgsKey{toolsName}).(map[string]string)
 	return m
 }
+
+// ToolCallOrigin describes where a tool call was made: the chain and task it
+// ran in and, for model-requested calls, the model that requested it.
+type ToolCallOrigin struct {
+	ChainID string
+	TaskID  string
+	Model   string
+}
+
+type toolCallModelKey struct{}
+
+// withToolCallModel records the model that requested the tool calls made with ctx.
+func withToolCallModel(ctx context.Context, model string) context.Context {
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, toolCallModelKey{}, model)
+}
+
+// ToolCallOriginFromContext returns the origin of a tool call; fields that are
+// unknown are empty.
+func ToolCallOriginFromContext(ctx context.Context) ToolCallOrigin {
+	scope, _ := taskEventScopeFromContext(ctx)
+	model, _ := ctx.Value(toolCallModelKey{}).(string)
+	return ToolCallOrigin{ChainID: scope.ChainID, TaskID: scope.TaskID, Model: model}
+}
@@ -0,0 +1,877 @@
+package taskengine
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/contenox/contenox/runtime/errdefs"
+)
+
+// Severity classifies a chain Diagnostic.
+type Severity string
+
+const (
+	// SeverityError marks a problem that makes the chain fail at runtime.
+	SeverityError Severity = "error"
+	// SeverityWarning marks a likely mistake that does not stop execution.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single finding reported by ValidateChain.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	// TaskID is the task the finding is about; empty for chain-level findings.
+	TaskID string `json:"taskId,omitempty"`
+	// Field is the path of the offending field, e.g. "transition.branches[1].goto".
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+	// Hint suggests how to fix the problem.
+	Hint string `json:"hint,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	b.WriteString(string(d.Severity))
+	b.WriteString(":")
+	if d.TaskID != "" {
+		fmt.Fprintf(&b, " task %q", d.TaskID)
+	}
+	if d.Field != "" {
+		fmt.Fprintf(&b, " %s", d.Field)
+	}
+	if d.TaskID != "" || d.Field != "" {
+		b.WriteString(":")
+	}
+	b.WriteString(" ")
+	b.WriteString(d.Message)
+	if d.Hint != "" {
+		fmt.Fprintf(&b, " (%s)", d.Hint)
+	}
+	return b.String()
+}
+
+// Diagnostics is the result of ValidateChain.
+type Diagnostics []Diagnostic
+
+// HasErrors reports whether any diagnostic has SeverityError.
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil when there are no errors, otherwise an error listing them.
+// Warnings are not included.
+func (ds Diagnostics) Err() error {
+	var msgs []string
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			msgs = append(msgs, d.String())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid chain:\n  %s\n%w", strings.Join(msgs, "\n  "), errdefs.ErrBadRequest)
+}
+
+// knownHandlers lists every handler the executors support.
+var knownHandlers = []TaskHandler{
+	HandlePromptToString,
+	HandlePromptToInt,
+	HandlePromptToStructured,
+	HandleRaiseError,
+	HandleChatCompletion,
+	HandleExecuteToolCalls,
+	HandleNoop,
+	HandleTools,
+	HandleParallel,
+	HandleForEach,
+	HandleCosineSimilarity,
+	HandleVectorTopK,
+	HandleVectorAverage,
+	HandleEmbedBatch,
+	HandleVectorSearch,
+	HandleCoerce,
+	HandleAwaitApproval,
+	HandleClarify,
+	HandleMapReduce,
+	HandleAgentLoop,
+	HandleGlossary,
+	HandleJQTransform,
+}
+
+// handlerInputTypes lists the input types a handler accepts. Handlers that
+// accept any input are not listed.
+var handlerInputTypes = map[TaskHandler][]DataType{
+	HandlePromptToString:     {DataTypeString, DataTypeInt, DataTypeChatHistory},
+	HandlePromptToInt:        {DataTypeString, DataTypeInt, DataTypeChatHistory},
+	HandlePromptToStructured: {DataTypeString, DataTypeInt, DataTypeChatHistory},
+	HandleRaiseError:         {DataTypeString, DataTypeInt, DataTypeChatHistory},
+	HandleChatCompletion:     {DataTypeString, DataTypeChatHistory},
+	HandleExecuteToolCalls:   {DataTypeChatHistory},
+	HandleAgentLoop:          {DataTypeString, DataTypeChatHistory},
+	HandleForEach:            {DataTypeJSON, DataTypeString, DataTypeVector},
+	HandleCosineSimilarity:   {DataTypeJSON, DataTypeString, DataTypeVector},
+	HandleVectorTopK:         {DataTypeJSON, DataTypeString},
+	HandleVectorAverage:      {DataTypeJSON, DataTypeString},
+	HandleEmbedBatch:         {DataTypeJSON, DataTypeString},
+	HandleVectorSearch:       {DataTypeJSON, DataTypeString},
+	HandleGlossary:           {DataTypeString, DataTypeChatHistory},
+}
+
+// pausesRun reports whether tasks of handler can pause the run, which only
+// top-level chain tasks can do.
+func pausesRun(handler TaskHandler) bool {
+	return handler == HandleAwaitApproval || handler == HandleClarify
+}
+
+var inRangePattern = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)-(-?\d+(?:\.\d+)?)$`)
+
+// ValidateChain checks a chain definition before execution and reports
+// unknown handlers, missing or dangling transitions, unreachable tasks,
+// incomplete handler configuration and type mismatches between the output of
+// a task and the input the next task expects.
+//
+// Tools references are not checked here since they depend on the tools
+// registered at runtime; see ValidateChainTools.
+func ValidateChain(chain *TaskChainDefinition) Diagnostics {
+	if chain == nil {
+		return Diagnostics{{Severity: SeverityError, Message: "chain is nil"}}
+	}
+	v := &chainValidator{chain: ResolveChainDefaults(chain), ids: map[string]int{}}
+	v.run()
+	return v.diags
+}
+
+// ValidateChainTools reports tools references that do not match any of the
+// known tools names: the tools of tools tasks (errors) and the names in
+// execute_config.tools allowlists (warnings, since unknown names are ignored
+// at runtime).
+func ValidateChainTools(chain *TaskChainDefinition, knownTools []string) Diagnostics {
+	if chain == nil {
+		return nil
+	}
+	known := make(map[string]bool, len(knownTools))
+	for _, name := range knownTools {
+		known[name] = true
+	}
+	var diags Diagnostics
+	if fb := chain.Fallback; fb != nil && fb.Action == FallbackHook && fb.Hook != nil && fb.Hook.Name != "" && !known[fb.Hook.Name] {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Field:    "fallback.hook.name",
+			Message:  fmt.Sprintf("unknown tools %q", fb.Hook.Name),
+			Hint:     suggest(fb.Hook.Name, knownTools, "register it before running the chain"),
+		})
+	}
+	for _, task := range ResolveChainDefaults(chain).Tasks {
+		if task.Handler == HandleTools && task.Tools != nil && task.Tools.Name != "" && !known[task.Tools.Name] {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				TaskID:   task.ID,
+				Field:    "tools.name",
+				Message:  fmt.Sprintf("unknown tools %q", task.Tools.Name),
+				Hint:     suggest(task.Tools.Name, knownTools, "register it before running the chain"),
+			})
+		}
+		if task.ExecuteConfig != nil {
+			for i, name := range task.ExecuteConfig.Tools {
+				name = strings.TrimPrefix(name, "!")
+				if name == "*" || known[name] {
+					continue
+				}
+				diags = append(diags, Diagnostic{
+					Severity: SeverityWarning,
+					TaskID:   task.ID,
+					Field:    fmt.Sprintf("execute_config.tools[%d]", i),
+					Message:  fmt.Sprintf("unknown tools %q is ignored", name),
+					Hint:     suggest(name, knownTools, ""),
+				})
+			}
+		}
+		if task.ForEach != nil && task.ForEach.Chain != nil {
+			diags = append(diags, nestDiagnostics(task.ID, "foreach.chain", ValidateChainTools(task.ForEach.Chain, knownTools))...)
+		}
+	}
+	return diags
+}
+
+// ValidateChainModels reports execute_config models and providers that are
+// not in knownModels and knownProviders. A nil list skips that check;
+// references holding a {{var:...}} macro are resolved at runtime and skipped.
+func ValidateChainModels(chain *TaskChainDefinition, knownModels, knownProviders []string) Diagnostics {
+	if chain == nil {
+		return nil
+	}
+	var diags Diagnostics
+	check := func(taskID, field, kind, name string, known []string) {
+		if known == nil || name == "" || strings.Contains(name, "{{") || slices.Contains(known, name) {
+			return
+		}
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			TaskID:   taskID,
+			Field:    field,
+			Message:  fmt.Sprintf("unknown %s %q", kind, name),
+			Hint:     suggest(name, known, "declare it before saving the chain"),
+		})
+	}
+	for _, task := range ResolveChainDefaults(chain).Tasks {
+		if cfg := task.ExecuteConfig; cfg != nil {
+			check(task.ID, "execute_config.model", "model", cfg.Model, knownModels)
+			for i, name := range cfg.Models {
+				check(task.ID, fmt.Sprintf("execute_config.models[%d]", i), "model", name, knownModels)
+			}
+			check(task.ID, "execute_config.provider", "provider", cfg.Provider, knownProviders)
+			for i, name := range cfg.Providers {
+				check(task.ID, fmt.Sprintf("execute_config.providers[%d]", i), "provider", name, knownProviders)
+			}
+		}
+		if task.ForEach != nil && task.ForEach.Chain != nil {
+			diags = append(diags, nestDiagnostics(task.ID, "foreach.chain", ValidateChainModels(task.ForEach.Chain, knownModels, knownProviders))...)
+		}
+	}
+	return diags
+}
+
+type chainValidator struct {
+	chain *TaskChainDefinition
+	// ids maps task IDs to their index in chain.Tasks.
+	ids map[string]int
+	// bodies holds the tasks run as parallel branches or foreach bodies;
+	// their own transitions are not used.
+	bodies map[string]bool
+	diags  Diagnostics
+}
+
+func (v *chainValidator) add(sev Severity, taskID, field, hint, format string, args ...any) {
+	v.diags = append(v.diags, Diagnostic{
+		Severity: sev,
+		TaskID:   taskID,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+		Hint:     hint,
+	})
+}
+
+func (v *chainValidator) taskIDs() []string {
+	ids := make([]string, 0, len(v.chain.Tasks))
+	for _, t := range v.chain.Tasks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func (v *chainValidator) run() {
+	if v.chain.ID == "" {
+		v.add(SeverityWarning, "", "id", "", "chain has no id")
+	}
+	if _, err := parseTimeout(v.chain.Timeout); err != nil {
+		v.add(SeverityError, "", "timeout", `use a Go duration such as "90s" or "5m"`, "%v", err)
+	}
+	if err := checkRateLimits(v.chain.RateLimits); err != nil {
+		v.add(SeverityError, "", "rate_limits", "use calls per minute per provider type, e.g. openai: 60", "%v", err)
+	}
+	if _, err := chainInputType(v.chain.Input); err != nil {
+		v.add(SeverityError, "", "input.type", "use string, int, json, chat_history, vector or file", "%v", err)
+	} else if _, err := compileChainInputSchema(v.chain.Input); err != nil {
+		v.add(SeverityError, "", "input.schema", "", "%v", err)
+	}
+	v.checkFallback()
+	if len(v.chain.Tasks) == 0 {
+		v.add(SeverityError, "", "tasks", "", "chain has no tasks")
+		return
+	}
+
+	for i, task := range v.chain.Tasks {
+		switch task.ID {
+		case "":
+			v.add(SeverityError, "", fmt.Sprintf("tasks[%d].id", i), "", "task id is empty")
+			continue
+		case TermEnd, chainVarsKey:
+			v.add(SeverityError, task.ID, "id", "", "task id %q is reserved", task.ID)
+			continue
+		}
+		if _, dup := v.ids[task.ID]; dup {
+			v.add(SeverityError, task.ID, "id", "", "duplicate task id")
+			continue
+		}
+		v.ids[task.ID] = i
+	}
+
+	v.bodies = map[string]bool{}
+	for _, task := range v.chain.Tasks {
+		if task.Parallel != nil {
+			for _, id := range task.Parallel.Branches {
+				v.bodies[id] = true
+			}
+		}
+		if task.ForEach != nil && task.ForEach.Task != "" {
+			v.bodies[task.ForEach.Task] = true
+		}
+	}
+
+	for i := range v.chain.Tasks {
+		task := &v.chain.Tasks[i]
+		if task.ID == "" {
+			continue
+		}
+		v.checkHandler(task)
+		v.checkTransition(task)
+		v.checkInputVar(task)
+		v.checkTemplates(task)
+		v.checkPromptVariants(task)
+	}
+	v.checkWrapUp()
+	v.checkReachability()
+	v.checkTypes()
+}
+
+// checkTaskRef reports ref if it is not a task of the chain. extra lists
+// further valid names that are offered as suggestions.
+func (v *chainValidator) checkTaskRef(taskID, field, ref string, extra ...string) bool {
+	if _, ok := v.ids[ref]; ok {
+		return true
+	}
+	v.add(SeverityError, taskID, field, suggest(ref, append(v.taskIDs(), extra...), ""), "unknown task %q", ref)
+	return false
+}
+
+func (v *chainValidator) checkHandler(task *TaskDefinition) {
+	if task.Handler == "" {
+		v.add(SeverityError, task.ID, "handler", "", "missing handler")
+		return
+	}
+	registered := RegisteredHandlers()
+	if !slices.Contains(knownHandlers, task.Handler) && !slices.Contains(registered, task.Handler) {
+		names := make([]string, 0, len(knownHandlers)+len(registered))
+		for _, h := range append(slices.Clone(knownHandlers), registered...) {
+			names = append(names, string(h))
+		}
+		v.add(SeverityError, task.ID, "handler", suggest(string(task.Handler), names, "supported: "+strings.Join(names, ", ")), "unknown handler %q", task.Handler)
+		return
+	}
+
+	if _, err := parseTimeout(task.Timeout); err != nil {
+		v.add(SeverityError, task.ID, "timeout", `use a Go duration such as "30s"`, "%v", err)
+	}
+	if _, err := resolveRetry(task); err != nil {
+		v.add(SeverityError, task.ID, "retry", "", "%v", err)
+	}
+	if field, err := checkSampling(task.ExecuteConfig); err != nil {
+		v.add(SeverityError, task.ID, field, "", "%v", err)
+	}
+	if task.ExecuteConfig != nil && task.ExecuteConfig.ToolChoice != nil {
+		if err := checkToolChoice(task.ExecuteConfig.ToolChoice); err != nil {
+			v.add(SeverityError, task.ID, "execute_config.tool_choice", `use auto, none, required or {name: <tool>}`, "%v", err)
+		} else if task.Handler != HandleChatCompletion && task.Handler != HandleAgentLoop && !v.inheritsToolChoice(task) {
+			v.add(SeverityWarning, task.ID, "execute_config.tool_choice", "", "tool_choice only applies to chat_completion and agent_loop tasks")
+		}
+	}
+	if task.AppendTo != nil {
+		if err := validateAppendConfig(task.AppendTo); err != nil {
+			v.add(SeverityError, task.ID, "append_to", "", "%v", err)
+		}
+	}
+	switch task.SystemInstructionMode {
+	case "", SystemInstructionAppend, SystemInstructionReplace:
+	default:
+		v.add(SeverityError, task.ID, "system_instruction_mode", `use "append" or "replace"`, "unknown system_instruction_mode %q", task.SystemInstructionMode)
+	}
+
+	switch task.Handler {
+	case HandleTools:
+		if task.Tools == nil || task.Tools.Name == "" {
+			v.add(SeverityError, task.ID, "tools.name", "", "tools task requires a tools name")
+		}
+	case HandleParallel:
+		if task.Parallel == nil || len(task.Parallel.Branches) == 0 {
+			v.add(SeverityError, task.ID, "parallel.branches", "", "parallel task requires at least one branch")
+			return
+		}
+		for i, id := range task.Parallel.Branches {
+			field := fmt.Sprintf("parallel.branches[%d]", i)
+			if id == task.ID {
+				v.add(SeverityError, task.ID, field, "", "parallel task cannot run itself")
+				continue
+			}
+			if v.checkTaskRef(task.ID, field, id) && pausesRun(v.chain.Tasks[v.ids[id]].Handler) {
+				v.add(SeverityError, task.ID, field, "", "%s tasks cannot run as parallel branches", v.chain.Tasks[v.ids[id]].Handler)
+			}
+		}
+	case HandleForEach:
+		cfg := task.ForEach
+		switch {
+		case cfg == nil || (cfg.Task == "" && cfg.Chain == nil):
+			v.add(SeverityError, task.ID, "foreach", "", "foreach task requires a task or chain")
+		case cfg.Task != "" && cfg.Chain != nil:
+			v.add(SeverityError, task.ID, "foreach", "", "foreach task must set either task or chain, not both")
+		case cfg.Task != "":
+			if cfg.Task == task.ID {
+				v.add(SeverityError, task.ID, "foreach.task", "", "foreach task cannot run itself")
+			} else if v.checkTaskRef(task.ID, "foreach.task", cfg.Task) {
+				body := v.chain.Tasks[v.ids[cfg.Task]]
+				if body.Handler == HandleParallel || body.Handler == HandleForEach {
+					v.add(SeverityError, task.ID, "foreach.task", "", "nested fan-out tasks are not supported")
+				}
+				if pausesRun(body.Handler) {
+					v.add(SeverityError, task.ID, "foreach.task", "", "%s tasks cannot run per element", body.Handler)
+				}
+			}
+		default:
+			v.diags = append(v.diags, nestDiagnostics(task.ID, "foreach.chain", ValidateChain(cfg.Chain))...)
+		}
+	case HandlePromptToStructured:
+		if _, _, err := compileStructuredSchema(task.Structured); err != nil {
+			v.add(SeverityError, task.ID, "structured.schema", "", "%v", err)
+		}
+	case HandleCoerce:
+		if _, _, err := parseCoerceConfig(task.Coerce); err != nil {
+			v.add(SeverityError, task.ID, "coerce", "", "%v", err)
+		}
+	case HandleGlossary:
+		if _, err := compileGlossary(task.Glossary); err != nil {
+			v.add(SeverityError, task.ID, "glossary", "", "%v", err)
+		}
+	case HandleJQTransform:
+		if _, err := compileJQ(task.JQ); err != nil {
+			v.add(SeverityError, task.ID, "jq.query", "", "%v", err)
+		}
+	case HandleMapReduce:
+		if err := validateMapReduceConfig(task.MapReduce); err != nil {
+			v.add(SeverityError, task.ID, "map_reduce", "", "%v", err)
+		}
+	case HandleEmbedBatch:
+		if task.Vector != nil && task.Vector.BatchSize < 0 {
+			v.add(SeverityError, task.ID, "vector.batch_size", "omit it for the default of 32", "batch_size must not be negative")
+		}
+	case HandleVectorSearch:
+		if task.Vector != nil && task.Vector.TopK < 0 {
+			v.add(SeverityError, task.ID, "vector.top_k", "omit it to return all candidates", "top_k must not be negative")
+		}
+	case HandleAgentLoop:
+		if task.AgentLoop != nil && task.AgentLoop.MaxIterations < 0 {
+			v.add(SeverityError, task.ID, "agent_loop.max_iterations", "omit it for the default of 10", "max_iterations must not be negative")
+		}
+	}
+	if task.Vector != nil && (task.Vector.Dimensions < 0 || task.Vector.TargetDimensions < 0) {
+		v.add(SeverityError, task.ID, "vector.dimensions", "omit it to accept any size", "embedding dimensions must not be negative")
+	}
+}
+
+func (v *chainValidator) checkFallback() {
+	fallback := v.chain.Fallback
+	if fallback == nil {
+		return
+	}
+	switch fallback.Action {
+	case FallbackMessage:
+		if fallback.Message == "" {
+			v.add(SeverityError, "", "fallback.message", "", "message fallback requires a message")
+		} else if err := parseTemplate(fallback.Message, false); err != nil {
+			v.add(SeverityError, "", "fallback.message", "", "%v", err)
+		}
+	case FallbackHook:
+		if fallback.Hook == nil || fallback.Hook.Name == "" {
+			v.add(SeverityError, "", "fallback.hook.name", "", "hook fallback requires a hook name")
+		}
+	case FallbackQueue:
+	default:
+		v.add(SeverityError, "", "fallback.action", `use "message", "hook" or "queue"`, "unknown fallback action %q", fallback.Action)
+	}
+}
+
+// checkPromptVariants reports prompt variants of task that cannot be drawn
+// or told apart.
+func (v *chainValidator) checkPromptVariants(task *TaskDefinition) {
+	if len(task.PromptVariants) == 0 {
+		return
+	}
+	if task.PromptTemplate != "" {
+		v.add(SeverityWarning, task.ID, "prompt_template", "remove it, or add it as a variant", "prompt_template is ignored when prompt_variants are set")
+	}
+	names := map[string]bool{}
+	for i, pv := range task.PromptVariants {
+		field := fmt.Sprintf("prompt_variants[%d]", i)
+		switch {
+		case pv.Name == "":
+			v.add(SeverityError, task.ID, field+".name", "", "prompt variant name is empty")
+		case names[pv.Name]:
+			v.add(SeverityError, task.ID, field+".name", "", "duplicate prompt variant %q", pv.Name)
+		}
+		names[pv.Name] = true
+		if pv.Weight <= 0 {
+			v.add(SeverityError, task.ID, field+".weight", "give every variant a positive weight, e.g. 50 and 50", "prompt variant needs a positive weight")
+		}
+		if pv.PromptTemplate == "" {
+			v.add(SeverityError, task.ID, field+".prompt_template", "", "prompt variant has no prompt_template")
+		}
+	}
+}
+
+// checkTemplates reports templates of task that do not parse.
+func (v *chainValidator) checkTemplates(task *TaskDefinition) {
+	check := func(field, src string, arg bool) {
+		if err := parseTemplate(src, arg); err != nil {
+			v.add(SeverityError, task.ID, field, "", "%v", err)
+		}
+	}
+	check("prompt_template", task.PromptTemplate, false)
+	for i, pv := range task.PromptVariants {
+		check(fmt.Sprintf("prompt_variants[%d].prompt_template", i), pv.PromptTemplate, false)
+	}
+	check("print", task.Print, false)
+	check("output_template", task.OutputTemplate, false)
+	if task.MapReduce != nil {
+		check("map_reduce.map_prompt", task.MapReduce.MapPrompt, false)
+		check("map_reduce.reduce_prompt", task.MapReduce.ReducePrompt, false)
+	}
+	if task.AppendTo != nil {
+		check("append_to.summarize_prompt", task.AppendTo.SummarizePrompt, false)
+	}
+	if task.Tools != nil {
+		for _, k := range slices.Sorted(maps.Keys(task.Tools.Args)) {
+			if strings.Contains(task.Tools.Args[k], "{{") {
+				check("tools.args."+k, task.Tools.Args[k], true)
+			}
+		}
+	}
+}
+
+// parseTemplate parses src like renderTemplate, or renderArgTemplate when arg
+// is set, after removing the macros MacroEnv expands before rendering.
+func parseTemplate(src string, arg bool) error {
+	if !strings.Contains(src, "{{") {
+		return nil
+	}
+	src = macroRe.ReplaceAllStringFunc(src, func(m string) string {
+		switch macroRe.FindStringSubmatch(m)[1] {
+		case "toolservice", "var", "now", "chain":
+			return ""
+		}
+		return m
+	})
+	funcs := template.FuncMap{chainVarsKey: func() map[string]any { return nil }}
+	if arg {
+		funcs["has"] = func(string) bool { return false }
+		funcs["json"] = func(any) (string, error) { return "", nil }
+	}
+	if _, err := template.New("check").Funcs(funcs).Parse(src); err != nil {
+		return fmt.Errorf("template does not parse: %v", err)
+	}
+	return nil
+}
+
+func (v *chainValidator) checkTransition(task *TaskDefinition) {
+	tr := task.Transition
+	if tr.OnFailure != "" && tr.OnFailure != TermEnd {
+		v.checkTaskRef(task.ID, "transition.on_failure", tr.OnFailure, TermEnd)
+	}
+	if tr.OnError != "" {
+		v.checkTaskRef(task.ID, "transition.on_error", tr.OnError)
+		if tr.OnFailure != "" {
+			v.add(SeverityWarning, task.ID, "transition.on_failure", "remove on_failure or on_error", "on_failure is ignored since on_error is set")
+		}
+	}
+	if task.Handler == HandleRaiseError {
+		return
+	}
+	if len(tr.Branches) == 0 {
+		if v.bodies[task.ID] {
+			return
+		}
+		v.add(SeverityError, task.ID, "transition.branches", `add a branch such as {operator: default, goto: end}`, "missing transition")
+		return
+	}
+
+	defaults, weighted := 0, 0
+	for i, branch := range tr.Branches {
+		field := fmt.Sprintf("transition.branches[%d]", i)
+		if branch.Goto != "" && branch.Goto != TermEnd {
+			v.checkTaskRef(task.ID, field+".goto", branch.Goto, TermEnd)
+		}
+		op, err := ToOperatorTerm(string(branch.Operator))
+		if err != nil {
+			v.add(SeverityError, task.ID, field+".operator", suggest(string(branch.Operator), SupportedOperators(), "supported: "+strings.Join(SupportedOperators(), ", ")), "unknown operator %q", branch.Operator)
+			continue
+		}
+		switch op {
+		case OpDefault:
+			defaults++
+			if defaults > 1 {
+				v.add(SeverityWarning, task.ID, field, "remove it", "only the first default branch is ever taken")
+			}
+		case OpGreaterThan, OpGt, OpLessThan, OpLt:
+			if _, err := parseNumber(branch.When); err != nil {
+				v.add(SeverityError, task.ID, field+".when", "", "operator %s needs a number: %v", op, err)
+			}
+		case OpInRange:
+			if !inRangePattern.MatchString(strings.TrimSpace(branch.When)) {
+				v.add(SeverityError, task.ID, field+".when", `use "min-max", e.g. "1-5"`, "invalid range %q", branch.When)
+			}
+		case OpExpr:
+			if _, err := compileExpr(branch.When); err != nil {
+				v.add(SeverityError, task.ID, field+".when", "", "invalid expression: %v", err)
+			}
+		case OpWeighted:
+			weighted++
+			if branch.Weight <= 0 {
+				v.add(SeverityError, task.ID, field+".weight", "give every weighted branch a positive weight, e.g. 90 and 10", "weighted branch needs a positive weight")
+			}
+		}
+		if branch.Compose != nil && branch.Compose.WithVar != "" && !v.isKnownVar(branch.Compose.WithVar) {
+			v.add(SeverityWarning, task.ID, field+".compose.with_var", suggest(branch.Compose.WithVar, v.taskIDs(), ""), "variable %q is not set by any task", branch.Compose.WithVar)
+		}
+	}
+	if weighted > 0 {
+		if weighted < len(tr.Branches) {
+			v.add(SeverityError, task.ID, "transition.branches", "make every branch weighted, or route with a separate task", "weighted branches cannot be mixed with other operators")
+		}
+		return
+	}
+	if defaults == 0 {
+		v.add(SeverityWarning, task.ID, "transition.branches", `add {operator: default, goto: ...} as a fallback`, "no default branch: the chain fails when no branch matches")
+	}
+}
+
+func (v *chainValidator) isKnownVar(name string) bool {
+	if name == "input" || name == "previous_output" || strings.HasSuffix(name, "_composed") {
+		return true
+	}
+	_, ok := v.ids[name]
+	return ok
+}
+
+func (v *chainValidator) checkInputVar(task *TaskDefinition) {
+	if task.InputVar == "" || v.isKnownVar(task.InputVar) {
+		return
+	}
+	v.add(SeverityError, task.ID, "input_var", suggest(task.InputVar, append(v.taskIDs(), "input"), ""), "variable %q is not set by any task", task.InputVar)
+}
+
+// checkWrapUp reports an invalid max_duration budget or wrap-up task.
+func (v *chainValidator) checkWrapUp() {
+	if _, err := resolveWrapUp(v.chain); err != nil {
+		v.add(SeverityError, "", "max_duration", `use a Go duration such as "10m" together with wrap_up.task`, "%v", err)
+		return
+	}
+	if v.chain.WrapUp != nil {
+		v.checkTaskRef("", "wrap_up.task", v.chain.WrapUp.Task)
+	}
+}
+
+// checkReachability reports tasks that cannot be reached from the first task.
+func (v *chainValidator) checkReachability() {
+	seen := map[string]bool{}
+	queue := []string{v.chain.Tasks[0].ID}
+	if v.chain.WrapUp != nil {
+		queue = append(queue, v.chain.WrapUp.Task)
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		idx, ok := v.ids[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		task := v.chain.Tasks[idx]
+		for _, b := range task.Transition.Branches {
+			queue = append(queue, b.Goto)
+		}
+		queue = append(queue, task.Transition.OnFailure, task.Transition.OnError)
+		if task.Parallel != nil {
+			queue = append(queue, task.Parallel.Branches...)
+		}
+		if task.ForEach != nil {
+			queue = append(queue, task.ForEach.Task)
+		}
+	}
+	for _, task := range v.chain.Tasks {
+		if _, ok := v.ids[task.ID]; ok && !seen[task.ID] {
+			v.add(SeverityWarning, task.ID, "", "reference it from a transition or remove it", "task is unreachable from the first task %q", v.chain.Tasks[0].ID)
+		}
+	}
+}
+
+// outputType returns the type a task produces, if it is known statically.
+func outputType(task *TaskDefinition) (DataType, bool) {
+	switch task.Handler {
+	case HandlePromptToString, HandleMapReduce:
+		return DataTypeString, true
+	case HandlePromptToInt:
+		return DataTypeInt, true
+	case HandlePromptToStructured, HandleCosineSimilarity, HandleVectorTopK, HandleEmbedBatch, HandleVectorSearch, HandleParallel, HandleForEach:
+		return DataTypeJSON, true
+	case HandleChatCompletion, HandleExecuteToolCalls, HandleAgentLoop:
+		return DataTypeChatHistory, true
+	case HandleVectorAverage:
+		return DataTypeVector, true
+	case HandleCoerce:
+		target, _, err := parseCoerceConfig(task.Coerce)
+		if err != nil {
+			return DataTypeAny, false
+		}
+		switch target {
+		case CoerceToString:
+			return DataTypeString, true
+		case CoerceToInt:
+			return DataTypeInt, true
+		default:
+			return DataTypeJSON, true
+		}
+	default:
+		return DataTypeAny, false
+	}
+}
+
+// checkTypes reports tasks whose input cannot have a type their handler accepts.
+func (v *chainValidator) checkTypes() {
+	mismatch := func(to *TaskDefinition, field string, from string, dt DataType) {
+		accepted := handlerInputTypes[to.Handler]
+		for _, a := range accepted {
+			if a == dt {
+				return
+			}
+		}
+		names := make([]string, len(accepted))
+		for i, a := range accepted {
+			names[i] = a.String()
+		}
+		v.add(SeverityError, to.ID, field, "insert a task that converts it, e.g. a coerce task, or set input_var",
+			"%s produces %s but handler %s expects %s", from, dt.String(), to.Handler, strings.Join(names, " or "))
+	}
+
+	for i := range v.chain.Tasks {
+		to := &v.chain.Tasks[i]
+		if _, ok := handlerInputTypes[to.Handler]; !ok || to.ID == "" {
+			continue
+		}
+		switch {
+		case to.hasPrompt():
+			mismatch(to, "prompt_template", "the prompt template", DataTypeString)
+		case to.InputVar != "":
+			if idx, ok := v.ids[to.InputVar]; ok {
+				if dt, known := outputType(&v.chain.Tasks[idx]); known {
+					mismatch(to, "input_var", fmt.Sprintf("task %q", to.InputVar), dt)
+				}
+			}
+		}
+	}
+
+	if dt, err := chainInputType(v.chain.Input); err == nil && dt != DataTypeAny {
+		first := &v.chain.Tasks[0]
+		if _, ok := handlerInputTypes[first.Handler]; ok && first.ID != "" && !first.hasPrompt() && first.InputVar == "" {
+			mismatch(first, "", "the chain input", dt)
+		}
+	}
+
+	for i := range v.chain.Tasks {
+		from := &v.chain.Tasks[i]
+		dt, known := outputType(from)
+		if !known || from.ID == "" {
+			continue
+		}
+		for j, branch := range from.Transition.Branches {
+			// Composed outputs have a different type, and failure branches
+			// pass the task input on.
+			if branch.Compose != nil || (branch.Operator == OpEquals && (branch.When == TransitionTimeout || branch.When == TransitionCoercionFailed)) {
+				continue
+			}
+			idx, ok := v.ids[branch.Goto]
+			if !ok {
+				continue
+			}
+			to := &v.chain.Tasks[idx]
+			if _, ok := handlerInputTypes[to.Handler]; !ok || to.hasPrompt() || to.InputVar != "" {
+				continue
+			}
+			mismatch(to, "", fmt.Sprintf("task %q (via transition.branches[%d])", from.ID, j), dt)
+		}
+	}
+
+	// on_error targets receive the error message.
+	for i := range v.chain.Tasks {
+		from := &v.chain.Tasks[i]
+		idx, ok := v.ids[from.Transition.OnError]
+		if !ok || from.ID == "" {
+			continue
+		}
+		to := &v.chain.Tasks[idx]
+		if _, ok := handlerInputTypes[to.Handler]; !ok || to.hasPrompt() || to.InputVar != "" {
+			continue
+		}
+		mismatch(to, "", fmt.Sprintf("the error of task %q (via transition.on_error)", from.ID), DataTypeString)
+	}
+}
+
+// inheritsToolChoice reports whether the tool_choice of task comes from the
+// chain's default_execute_config.
+func (v *chainValidator) inheritsToolChoice(task *TaskDefinition) bool {
+	defaults := v.chain.DefaultExecuteConfig
+	return defaults != nil && defaults.ToolChoice == task.ExecuteConfig.ToolChoice
+}
+
+// nestDiagnostics attributes the diagnostics of an inline sub-chain to the
+// task that owns it.
+func nestDiagnostics(taskID, field string, inner Diagnostics) Diagnostics {
+	out := make(Diagnostics, 0, len(inner))
+	for _, d := range inner {
+		nested := d
+		nested.TaskID = taskID
+		nested.Field = field
+		if d.Field != "" {
+			nested.Field += "." + d.Field
+		}
+		if d.TaskID != "" {
+			nested.Message = fmt.Sprintf("task %q: %s", d.TaskID, d.Message)
+		}
+		out = append(out, nested)
+	}
+	return out
+}
+
+// suggest returns a "did you mean" hint when name is close to one of the
+// candidates, or fallback otherwise.
+func suggest(name string, candidates []string, fallback string) string {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(name), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist >= 0 && bestDist <= max(2, len(name)/3) {
+		return fmt.Sprintf("did you mean %q?", best)
+	}
+	return fallback
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,175 @@
+package taskengine_test
+
+import (
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func goTo(id string) taskengine.TaskTransition {
+	return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: id}}}
+}
+
+// findDiagnostic returns the first diagnostic for taskID whose field matches.
+func findDiagnostic(ds taskengine.Diagnostics, taskID, field string) *taskengine.Diagnostic {
+	for i := range ds {
+		if ds[i].TaskID == taskID && ds[i].Field == field {
+			return &ds[i]
+		}
+	}
+	return nil
+}
+
+func TestUnit_ValidateChain_Valid(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "ok",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "chat", Handler: taskengine.HandleChatCompletion, Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpEquals, When: "tool-call", Goto: "run_tools"},
+					{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd},
+				},
+			}},
+			{ID: "run_tools", Handler: taskengine.HandleExecuteToolCalls, Transition: goTo("chat")},
+		},
+	}
+	diags := taskengine.ValidateChain(chain)
+	assert.Empty(t, diags)
+	assert.NoError(t, diags.Err())
+}
+
+func TestUnit_ValidateChain_Diagnostics(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "broken",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "summarize", Handler: taskengine.HandlePromptToString, PromptTemplate: "{{.input}}", Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{
+					{Operator: "equal", When: "x", Goto: "report"},
+					{Operator: taskengine.OpDefault, Goto: "reprot"},
+				},
+			}},
+			{ID: "report", Handler: "prompt_to_strin", Transition: goTo(taskengine.TermEnd)},
+			{ID: "orphan", Handler: taskengine.HandleNoop},
+			{ID: "score", Handler: taskengine.HandlePromptToInt, PromptTemplate: "rate", Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpInRange, When: "1..5", Goto: taskengine.TermEnd}},
+			}},
+		},
+	}
+	diags := taskengine.ValidateChain(chain)
+	require.True(t, diags.HasErrors())
+
+	d := findDiagnostic(diags, "summarize", "transition.branches[0].operator")
+	require.NotNil(t, d, "%v", diags)
+	assert.Equal(t, `did you mean "equals"?`, d.Hint)
+
+	d = findDiagnostic(diags, "summarize", "transition.branches[1].goto")
+	require.NotNil(t, d, "%v", diags)
+	assert.Equal(t, taskengine.SeverityError, d.Severity)
+	assert.Equal(t, `did you mean "report"?`, d.Hint)
+
+	d = findDiagnostic(diags, "report", "handler")
+	require.NotNil(t, d, "%v", diags)
+	assert.Contains(t, d.Message, "unknown handler")
+	assert.Equal(t, `did you mean "prompt_to_string"?`, d.Hint)
+
+	d = findDiagnostic(diags, "orphan", "transition.branches")
+	require.NotNil(t, d, "%v", diags)
+	assert.Equal(t, "missing transition", d.Message)
+
+	d = findDiagnostic(diags, "orphan", "")
+	require.NotNil(t, d, "%v", diags)
+	assert.Equal(t, taskengine.SeverityWarning, d.Severity)
+	assert.Contains(t, d.Message, "unreachable")
+
+	assert.NotNil(t, findDiagnostic(diags, "score", "transition.branches[0].when"))
+	assert.NotNil(t, findDiagnostic(diags, "score", "transition.branches"), "missing default branch is a warning")
+
+	err := diags.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `error: task "report" handler: unknown handler "prompt_to_strin"`)
+}
+
+func TestUnit_ValidateChain_TypeMismatch(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "types",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "count", Handler: taskengine.HandlePromptToInt, Transition: goTo("tools")},
+			{ID: "tools", Handler: taskengine.HandleExecuteToolCalls, Transition: goTo("avg")},
+			{ID: "avg", Handler: taskengine.HandleVectorAverage, InputVar: "count", Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+	diags := taskengine.ValidateChain(chain)
+
+	d := findDiagnostic(diags, "tools", "")
+	require.NotNil(t, d, "%v", diags)
+	assert.Equal(t, taskengine.SeverityError, d.Severity)
+	assert.Contains(t, d.Message, `task "count" (via transition.branches[0]) produces int but handler execute_tool_calls expects chat_history`)
+
+	d = findDiagnostic(diags, "avg", "input_var")
+	require.NotNil(t, d, "%v", diags)
+	assert.Contains(t, d.Message, "produces int")
+}
+
+func TestUnit_ValidateChain_HandlerConfig(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID:       "config",
+		Timeout:  "later",
+		Fallback: &taskengine.ChainFallback{Action: "retry"},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "fan", Handler: taskengine.HandleParallel, Parallel: &taskengine.ParallelConfig{Branches: []string{"a", "missing"}}, Transition: goTo("loop")},
+			{ID: "a", Handler: taskengine.HandleNoop},
+			{ID: "loop", Handler: taskengine.HandleForEach, ForEach: &taskengine.ForEachConfig{Chain: &taskengine.TaskChainDefinition{
+				ID:    "inner",
+				Tasks: []taskengine.TaskDefinition{{ID: "x", Handler: taskengine.HandleNoop, Transition: goTo("y")}},
+			}}, Transition: goTo("fetch")},
+			{ID: "fetch", Handler: taskengine.HandleTools, Transition: goTo("convert")},
+			{ID: "convert", Handler: taskengine.HandleCoerce, Coerce: &taskengine.CoerceConfig{To: "date"}, Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpExpr, When: "output.score >", Goto: taskengine.TermEnd},
+					{Operator: taskengine.OpDefault, Goto: "summarize"},
+				},
+			}},
+			{ID: "summarize", Handler: taskengine.HandleMapReduce, MapReduce: &taskengine.MapReduceConfig{MapPrompt: "{{.chunk}}"}, Transition: goTo("sample")},
+			{ID: "sample", Handler: taskengine.HandlePromptToString, ExecuteConfig: &taskengine.LLMExecutionConfig{TopP: 1.5}, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+	diags := taskengine.ValidateChain(chain)
+
+	assert.NotNil(t, findDiagnostic(diags, "", "timeout"))
+	assert.NotNil(t, findDiagnostic(diags, "", "fallback.action"))
+	assert.NotNil(t, findDiagnostic(diags, "fan", "parallel.branches[1]"))
+	assert.Nil(t, findDiagnostic(diags, "a", "transition.branches"), "parallel branches need no transition")
+	d := findDiagnostic(diags, "loop", "foreach.chain.transition.branches[0].goto")
+	require.NotNil(t, d, "%v", diags)
+	assert.Contains(t, d.Message, `task "x": unknown task "y"`)
+	assert.NotNil(t, findDiagnostic(diags, "fetch", "tools.name"))
+	assert.NotNil(t, findDiagnostic(diags, "convert", "coerce"))
+	d = findDiagnostic(diags, "convert", "transition.branches[0].when")
+	require.NotNil(t, d, "%v", diags)
+	assert.Contains(t, d.Message, "invalid expression")
+	assert.NotNil(t, findDiagnostic(diags, "summarize", "map_reduce"))
+	assert.NotNil(t, findDiagnostic(diags, "sample", "execute_config.top_p"))
+}
+
+func TestUnit_ValidateChainTools(t *testing.T) {
+	chain := &taskengine.TaskChainDefinition{
+		ID: "tools",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "call", Handler: taskengine.HandleTools, Tools: &taskengine.ToolsCall{Name: "webtool"}, Transition: goTo("chat")},
+			{ID: "chat", Handler: taskengine.HandleChatCompletion, ExecuteConfig: &taskengine.LLMExecutionConfig{
+				Tools: []string{"*", "!local_shell", "nws"},
+			}, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+	diags := taskengine.ValidateChainTools(chain, []string{"webtools", "local_shell"})
+	require.Len(t, diags, 2, "%v", diags)
+
+	assert.Equal(t, taskengine.SeverityError, diags[0].Severity)
+	assert.Equal(t, "call", diags[0].TaskID)
+	assert.Equal(t, `did you mean "webtools"?`, diags[0].Hint)
+
+	assert.Equal(t, taskengine.SeverityWarning, diags[1].Severity)
+	assert.Equal(t, "execute_config.tools[2]", diags[1].Field)
+}
@@ -0,0 +1,417 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+)
+
+// convertToVector coerces numeric slices and JSON array strings into a []float64.
+func convertToVector(value any) ([]float64, error) {
+	switch v := value.(type) {
+	case []float64:
+		return v, nil
+	case []float32:
+		out := make([]float64, len(v))
+		for i, f := range v {
+			out[i] = float64(f)
+		}
+		return out, nil
+	case []any:
+		out := make([]float64, len(v))
+		for i, e := range v {
+			f, err := vectorComponent(e)
+			if err != nil {
+				return nil, fmt.Errorf("vector component %d: %w", i, err)
+			}
+			out[i] = f
+		}
+		return out, nil
+	case string:
+		var out []float64
+		if err := json.Unmarshal([]byte(strings.TrimSpace(v)), &out); err != nil {
+			return nil, fmt.Errorf("cannot convert string to vector: %w", err)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %T to vector", value)
+	}
+}
+
+func vectorComponent(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	default:
+		return 0, fmt.Errorf("not a number: %T", v)
+	}
+}
+
+// decodeJSONInput unmarshals string inputs that hold JSON so the vector
+// handlers accept both structured values and their serialized form.
+func decodeJSONInput(input any) any {
+	s, ok := input.(string)
+	if !ok {
+		return input
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+		return input
+	}
+	return decoded
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b.
+func cosineSimilarity(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("vector dimensions differ: %d != %d", len(a), len(b))
+	}
+	if len(a) == 0 {
+		return 0, errors.New("vectors are empty")
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0, errors.New("cosine similarity is undefined for zero vectors")
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), nil
+}
+
+// vectorPair extracts the two operands of a cosine similarity task.
+// Accepted shapes: {"a": [...], "b": [...]} or [[...], [...]].
+func vectorPair(input any) ([]float64, []float64, error) {
+	switch v := decodeJSONInput(input).(type) {
+	case map[string]any:
+		ra, okA := v["a"]
+		rb, okB := v["b"]
+		if !okA || !okB {
+			return nil, nil, errors.New("input must contain vectors \"a\" and \"b\"")
+		}
+		a, err := convertToVector(ra)
+		if err != nil {
+			return nil, nil, fmt.Errorf("a: %w", err)
+		}
+		b, err := convertToVector(rb)
+		if err != nil {
+			return nil, nil, fmt.Errorf("b: %w", err)
+		}
+		return a, b, nil
+	case []any:
+		if len(v) != 2 {
+			return nil, nil, fmt.Errorf("input must be a pair of vectors, got %d elements", len(v))
+		}
+		a, err := convertToVector(v[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("a: %w", err)
+		}
+		b, err := convertToVector(v[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("b: %w", err)
+		}
+		return a, b, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported input %T: expected {\"a\":[...],\"b\":[...]} or a pair of vectors", input)
+	}
+}
+
+// vectorCandidate is a single entry considered by vector_top_k.
+type vectorCandidate struct {
+	id     string
+	vector []float64
+}
+
+// vectorTopK ranks the candidates of input by cosine similarity to its query.
+// Input shape: {"query": [...], "candidates": [[...] | {"id": "...", "vector": [...]}]}.
+// Each result is a JSON object with "index", "score" and, when present, "id".
+func vectorTopK(input any, k int) ([]any, error) {
+	m, ok := decodeJSONInput(input).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unsupported input %T: expected {\"query\":[...],\"candidates\":[...]}", input)
+	}
+	query, err := convertToVector(m["query"])
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	rawCandidates, ok := m["candidates"].([]any)
+	if !ok || len(rawCandidates) == 0 {
+		return nil, errors.New("candidates must be a non-empty array")
+	}
+
+	candidates := make([]vectorCandidate, len(rawCandidates))
+	for i, rc := range rawCandidates {
+		c := vectorCandidate{}
+		if obj, ok := rc.(map[string]any); ok {
+			if id, ok := obj["id"]; ok {
+				c.id = fmt.Sprintf("%v", id)
+			}
+			rc = obj["vector"]
+		}
+		if c.vector, err = convertToVector(rc); err != nil {
+			return nil, fmt.Errorf("candidate %d: %w", i, err)
+		}
+		candidates[i] = c
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	scores := make([]scored, len(candidates))
+	for i, c := range candidates {
+		s, err := cosineSimilarity(query, c.vector)
+		if err != nil {
+			return nil, fmt.Errorf("candidate %d: %w", i, err)
+		}
+		scores[i] = scored{index: i, score: s}
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+
+	if k <= 0 {
+		k = 1
+	}
+	k = min(k, len(scores))
+	out := make([]any, k)
+	for i := range k {
+		entry := map[string]any{
+			"index": scores[i].index,
+			"score": scores[i].score,
+		}
+		if id := candidates[scores[i].index].id; id != "" {
+			entry["id"] = id
+		}
+		out[i] = entry
+	}
+	return out, nil
+}
+
+// vectorAverage returns the element-wise mean of the vectors in input.
+func vectorAverage(input any) ([]float64, error) {
+	list, ok := decodeJSONInput(input).([]any)
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("unsupported input %T: expected a non-empty array of vectors", input)
+	}
+	var sum []float64
+	for i, raw := range list {
+		v, err := convertToVector(raw)
+		if err != nil {
+			return nil, fmt.Errorf("vector %d: %w", i, err)
+		}
+		if i == 0 {
+			sum = make([]float64, len(v))
+		} else if len(v) != len(sum) {
+			return nil, fmt.Errorf("vector %d: dimension %d differs from %d", i, len(v), len(sum))
+		}
+		for j, f := range v {
+			sum[j] += f
+		}
+	}
+	for j := range sum {
+		sum[j] /= float64(len(list))
+	}
+	return sum, nil
+}
+
+// vectorSearch ranks the candidates of input by cosine similarity to its
+// query and returns the task.Vector.TopK best that reach Vector.MinScore,
+// best first.
+// Input shape: {"query": [...] | "text", "candidates": [[...] | "text" | {...}]}.
+// Strings that are not JSON vectors are embedded. Candidate objects carry a
+// "vector" or a "text" to embed; their other fields are returned unchanged
+// next to "index" and "score".
+func (exe *SimpleExec) vectorSearch(ctx context.Context, task *TaskDefinition, input any) ([]any, error) {
+	var cfg VectorConfig
+	if task.Vector != nil {
+		cfg = *task.Vector
+	}
+	m, ok := decodeJSONInput(input).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unsupported input %T: expected {\"query\":...,\"candidates\":[...]}", input)
+	}
+	rawCandidates, ok := m["candidates"].([]any)
+	if !ok || len(rawCandidates) == 0 {
+		return nil, errors.New("candidates must be a non-empty array")
+	}
+
+	// Texts are embedded with one batched request; toEmbed[i] receives the
+	// vector of texts[i].
+	var texts []string
+	var toEmbed []*[]float64
+
+	query, err := convertToVector(m["query"])
+	if text, ok := m["query"].(string); ok && err != nil {
+		texts, toEmbed = append(texts, text), append(toEmbed, &query)
+	} else if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	vectors := make([][]float64, len(rawCandidates))
+	fields := make([]map[string]any, len(rawCandidates))
+	for i, rc := range rawCandidates {
+		fields[i] = map[string]any{}
+		switch c := rc.(type) {
+		case string:
+			if v, err := convertToVector(c); err == nil {
+				vectors[i] = v
+				continue
+			}
+			fields[i]["text"] = c
+			texts, toEmbed = append(texts, c), append(toEmbed, &vectors[i])
+			continue
+		case map[string]any:
+			for k, v := range c {
+				if k != "vector" {
+					fields[i][k] = v
+				}
+			}
+			if _, ok := c["vector"]; !ok {
+				text, ok := c["text"].(string)
+				if !ok {
+					return nil, fmt.Errorf("candidate %d: needs a \"vector\" or a \"text\"", i)
+				}
+				texts, toEmbed = append(texts, text), append(toEmbed, &vectors[i])
+				continue
+			}
+			rc = c["vector"]
+		}
+		v, err := convertToVector(rc)
+		if err != nil {
+			return nil, fmt.Errorf("candidate %d: %w", i, err)
+		}
+		vectors[i] = v
+	}
+
+	if len(texts) > 0 {
+		embedded, err := exe.embedTexts(ctx, task, texts)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range embedded {
+			*toEmbed[i] = v
+		}
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	scores := make([]scored, 0, len(vectors))
+	for i, v := range vectors {
+		s, err := cosineSimilarity(query, v)
+		if err != nil {
+			return nil, fmt.Errorf("candidate %d: %w", i, err)
+		}
+		if cfg.MinScore == 0 || s >= cfg.MinScore {
+			scores = append(scores, scored{index: i, score: s})
+		}
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+	if cfg.TopK > 0 {
+		scores = scores[:min(cfg.TopK, len(scores))]
+	}
+
+	out := make([]any, len(scores))
+	for i, s := range scores {
+		entry := fields[s.index]
+		entry["index"] = s.index
+		entry["score"] = s.score
+		out[i] = entry
+	}
+	return out, nil
+}
+
+// embedBatch embeds the strings of input with the model of
+// task.ExecuteConfig, or the default embedding model, and returns one vector
+// per string.
+func (exe *SimpleExec) embedBatch(ctx context.Context, task *TaskDefinition, input any) ([]any, error) {
+	texts, err := stringList(input)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := exe.embedTexts(ctx, task, texts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, len(vectors))
+	for i, v := range vectors {
+		out[i] = v
+	}
+	return out, nil
+}
+
+// embedTexts embeds texts with the model of task.ExecuteConfig, or the
+// default embedding model, in batches of task.Vector.BatchSize.
+func (exe *SimpleExec) embedTexts(ctx context.Context, task *TaskDefinition, texts []string) ([][]float64, error) {
+	req := llmrepo.EmbedRequest{Tracker: exe.tracker}
+	if task.ExecuteConfig != nil {
+		req.ModelName = task.ExecuteConfig.Model
+		req.ProviderType = task.ExecuteConfig.Provider
+	}
+	if task.Vector != nil {
+		req.BatchSize = task.Vector.BatchSize
+		req.Dimensions = task.Vector.Dimensions
+		req.TargetDimensions = task.Vector.TargetDimensions
+	}
+	vectors, _, err := exe.modelRepo(ctx).EmbedBatch(ctx, req, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("got %d vectors for %d strings", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// stringList extracts the non-empty array of non-empty strings embed_batch
+// embeds.
+func stringList(input any) ([]string, error) {
+	var list []any
+	switch v := decodeJSONInput(input).(type) {
+	case []string:
+		for _, s := range v {
+			list = append(list, s)
+		}
+	case []any:
+		list = v
+	default:
+		return nil, fmt.Errorf("unsupported input %T: expected an array of strings", input)
+	}
+	if len(list) == 0 {
+		return nil, errors.New("input must be a non-empty array of strings")
+	}
+	texts := make([]string, len(list))
+	for i, e := range list {
+		s, ok := e.(string)
+		if !ok {
+			return nil, fmt.Errorf("element %d: expected a string, got %T", i, e)
+		}
+		if strings.TrimSpace(s) == "" {
+			return nil, fmt.Errorf("element %d: string is empty", i)
+		}
+		texts[i] = s
+	}
+	return texts, nil
+}
+
+// formatScore renders a similarity score for transition evaluation.
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 4, 64)
+}
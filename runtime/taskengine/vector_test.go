@@ -0,0 +1,185 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/contenox/libtracker"
+	"github.com/contenox/contenox/runtime/internal/llmrepo"
+	"github.com/contenox/contenox/runtime/internal/tools"
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vectorExec(t *testing.T, task *taskengine.TaskDefinition, input any, dataType taskengine.DataType) (any, taskengine.DataType, string, error) {
+	t.Helper()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	return exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, task, input, dataType)
+}
+
+func TestUnit_Vector_CosineSimilarity(t *testing.T) {
+	task := &taskengine.TaskDefinition{ID: "sim", Handler: taskengine.HandleCosineSimilarity}
+
+	out, _, transition, err := vectorExec(t, task, map[string]any{
+		"a": []any{1.0, 0.0},
+		"b": []float64{1, 1},
+	}, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.7071, out, 1e-4)
+	assert.Equal(t, "0.7071", transition)
+
+	_, _, _, err = vectorExec(t, task, `[[1,2,3],[1,2]]`, taskengine.DataTypeString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dimensions differ")
+}
+
+func TestUnit_Vector_TopK(t *testing.T) {
+	task := &taskengine.TaskDefinition{
+		ID:      "route",
+		Handler: taskengine.HandleVectorTopK,
+		Vector:  &taskengine.VectorConfig{TopK: 2},
+	}
+	input := map[string]any{
+		"query": []any{1.0, 0.0},
+		"candidates": []any{
+			map[string]any{"id": "billing", "vector": []any{0.0, 1.0}},
+			map[string]any{"id": "support", "vector": []any{0.9, 0.1}},
+			map[string]any{"id": "sales", "vector": []any{0.5, 0.5}},
+		},
+	}
+
+	out, outType, transition, err := vectorExec(t, task, input, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeJSON, outType)
+	assert.Equal(t, "support", transition)
+
+	matches := out.([]any)
+	require.Len(t, matches, 2)
+	assert.Equal(t, 1, matches[0].(map[string]any)["index"])
+	assert.Equal(t, "sales", matches[1].(map[string]any)["id"])
+}
+
+func TestUnit_Vector_Average(t *testing.T) {
+	task := &taskengine.TaskDefinition{ID: "avg", Handler: taskengine.HandleVectorAverage}
+
+	out, outType, _, err := vectorExec(t, task, []any{[]float64{1, 2}, []any{3.0, 4.0}}, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeVector, outType)
+	assert.Equal(t, []float64{2, 3}, out)
+
+	_, _, _, err = vectorExec(t, task, []any{[]float64{1, 2}, []float64{1}}, taskengine.DataTypeJSON)
+	require.Error(t, err)
+}
+
+func TestUnit_Vector_EmbedBatch(t *testing.T) {
+	var batches [][]string
+	repo := &mockModelRepo{
+		embedBatchFunc: func(_ context.Context, req llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+			assert.Equal(t, "nomic-embed-text", req.ModelName)
+			assert.Equal(t, 2, req.BatchSize)
+			assert.Equal(t, 2, req.Dimensions, "the declared dimensions reach the repo")
+			assert.Equal(t, 1, req.TargetDimensions)
+			batches = append(batches, prompts)
+			vecs := make([][]float64, len(prompts))
+			for i, p := range prompts {
+				vecs[i] = []float64{float64(len(p)), 1}
+			}
+			return vecs, llmrepo.Meta{ModelName: req.ModelName}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	task := &taskengine.TaskDefinition{
+		ID:            "embed",
+		Handler:       taskengine.HandleEmbedBatch,
+		ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "nomic-embed-text"},
+		Vector:        &taskengine.VectorConfig{BatchSize: 2, Dimensions: 2, TargetDimensions: 1},
+	}
+
+	out, outType, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, task, `["a", "bb", "ccc"]`, taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeJSON, outType)
+	assert.Equal(t, "3", transition)
+	assert.Equal(t, []any{[]float64{1, 1}, []float64{2, 1}, []float64{3, 1}}, out)
+	assert.Equal(t, [][]string{{"a", "bb", "ccc"}}, batches, "all strings go to the repo in one call")
+
+	avg := &taskengine.TaskDefinition{ID: "avg", Handler: taskengine.HandleVectorAverage}
+	mean, _, _, err := exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, avg, out, outType)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{2, 1}, mean)
+
+	_, _, _, err = exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, task, []any{"a", 1.0}, taskengine.DataTypeJSON)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "element 1: expected a string")
+}
+
+func TestUnit_Vector_Search(t *testing.T) {
+	task := &taskengine.TaskDefinition{
+		ID:      "search",
+		Handler: taskengine.HandleVectorSearch,
+		Vector:  &taskengine.VectorConfig{TopK: 2, MinScore: 0.5},
+	}
+	input := map[string]any{
+		"query": []any{1.0, 0.0},
+		"candidates": []any{
+			map[string]any{"id": "billing", "text": "Invoices and refunds", "vector": []any{0.0, 1.0}},
+			map[string]any{"id": "support", "text": "Troubleshooting", "vector": []any{0.9, 0.1}},
+			[]any{0.6, 0.4},
+		},
+	}
+
+	out, outType, transition, err := vectorExec(t, task, input, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.Equal(t, taskengine.DataTypeJSON, outType)
+	assert.Equal(t, "support", transition)
+
+	matches := out.([]any)
+	require.Len(t, matches, 2)
+	best := matches[0].(map[string]any)
+	assert.Equal(t, "Troubleshooting", best["text"])
+	assert.Equal(t, 1, best["index"])
+	assert.NotContains(t, best, "vector")
+	assert.Equal(t, 2, matches[1].(map[string]any)["index"])
+
+	task.Vector.MinScore = 0.999
+	out, _, transition, err = vectorExec(t, task, input, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+	assert.Equal(t, "no_match", transition)
+}
+
+func TestUnit_Vector_SearchEmbedsText(t *testing.T) {
+	embeddings := map[string][]float64{
+		"refund my order": {1, 0},
+		"Refund policy":   {0.9, 0.1},
+		"Shipping times":  {0, 1},
+	}
+	var calls int
+	repo := &mockModelRepo{
+		embedBatchFunc: func(_ context.Context, _ llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+			calls++
+			vecs := make([][]float64, len(prompts))
+			for i, p := range prompts {
+				vecs[i] = embeddings[p]
+			}
+			return vecs, llmrepo.Meta{}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	task := &taskengine.TaskDefinition{ID: "search", Handler: taskengine.HandleVectorSearch}
+
+	input := `{"query": "refund my order", "candidates": ["Shipping times", {"id": "policy", "text": "Refund policy"}]}`
+	out, _, transition, err := exec.TaskExec(context.Background(), time.Now(), 0, &taskengine.ChainContext{}, task, input, taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "query and candidates are embedded in one batch")
+	assert.Equal(t, "policy", transition)
+
+	matches := out.([]any)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "Refund policy", matches[0].(map[string]any)["text"])
+	assert.Equal(t, "Shipping times", matches[1].(map[string]any)["text"])
+}
@@ -0,0 +1,93 @@
+package taskengine
+
+import "math/rand/v2"
+
+// drawWeightedBranch picks one of the weighted branches of transition with
+// probability proportional to its weight. It returns nil when the transition
+// has no weighted branches.
+func drawWeightedBranch(transition TaskTransition) *TransitionBranch {
+	return pickWeightedBranch(transition, rand.Float64())
+}
+
+// pickWeightedBranch returns the weighted branch whose share of the total
+// weight covers r, a number in [0, 1).
+func pickWeightedBranch(transition TaskTransition, r float64) *TransitionBranch {
+	i := pickWeighted(len(transition.Branches), func(i int) float64 {
+		if b := transition.Branches[i]; b.Operator == OpWeighted {
+			return b.Weight
+		}
+		return 0
+	}, r)
+	if i < 0 {
+		return nil
+	}
+	return &transition.Branches[i]
+}
+
+// pickWeighted returns the index among n items, weighted by weight, whose
+// share of the total weight covers r, a number in [0, 1). Items without a
+// positive weight are never picked; it returns -1 when no item has one.
+func pickWeighted(n int, weight func(i int) float64, r float64) int {
+	var total float64
+	last := -1
+	for i := range n {
+		if w := weight(i); w > 0 {
+			total += w
+			last = i
+		}
+	}
+	if last < 0 {
+		return -1
+	}
+	target := r * total
+	for i := range n {
+		w := weight(i)
+		if w <= 0 {
+			continue
+		}
+		if target < w {
+			return i
+		}
+		target -= w
+	}
+	// Rounding can leave a remainder past the last share.
+	return last
+}
+
+// variantName names the branch a weighted transition picked by its goto.
+func variantName(b *TransitionBranch) string {
+	if b.Goto == "" {
+		return TermEnd
+	}
+	return b.Goto
+}
+
+// drawPromptVariant picks one of the prompt variants of task with probability
+// proportional to its weight. It returns nil when the task has none.
+func drawPromptVariant(task *TaskDefinition) *PromptVariant {
+	return pickPromptVariant(task.PromptVariants, rand.Float64())
+}
+
+// pickPromptVariant returns the variant whose share of the total weight
+// covers r, a number in [0, 1).
+func pickPromptVariant(variants []PromptVariant, r float64) *PromptVariant {
+	i := pickWeighted(len(variants), func(i int) float64 { return variants[i].Weight }, r)
+	if i < 0 {
+		return nil
+	}
+	return &variants[i]
+}
+
+// promptFor returns the prompt template to render for task and the name of
+// the prompt variant it was drawn from, which is empty without variants.
+func promptFor(task *TaskDefinition) (string, string) {
+	if v := drawPromptVariant(task); v != nil {
+		return v.PromptTemplate, v.Name
+	}
+	return task.PromptTemplate, ""
+}
+
+// hasPrompt reports whether task renders a prompt as its input.
+func (t *TaskDefinition) hasPrompt() bool {
+	return t.PromptTemplate != "" || len(t.PromptVariants) > 0
+}
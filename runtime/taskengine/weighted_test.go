@@ -0,0 +1,119 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/contenox/runtime/taskengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func weightedChain(weightA, weightB float64) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "ab",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "route", Handler: taskengine.HandleNoop, Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpWeighted, Weight: weightA, Goto: "variant_a"},
+					{Operator: taskengine.OpWeighted, Weight: weightB, Goto: "variant_b"},
+				},
+			}},
+			{ID: "variant_a", Handler: taskengine.HandleNoop, Transition: goTo(taskengine.TermEnd)},
+			{ID: "variant_b", Handler: taskengine.HandleNoop, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+}
+
+func TestWeightedTransition_SplitsAndRecordsVariant(t *testing.T) {
+	env := setupTestEnv(&taskengine.MockTaskExecutor{})
+	seen := map[string]int{}
+	for range 200 {
+		_, _, history, err := env.ExecEnv(context.Background(), weightedChain(1, 1), "hi", taskengine.DataTypeString)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, history[1].TaskID, history[0].Variant, "the recorded variant is the route taken")
+		seen[history[0].Variant]++
+	}
+	assert.Positive(t, seen["variant_a"])
+	assert.Positive(t, seen["variant_b"])
+}
+
+func TestWeightedTransition_ZeroShareNeverTaken(t *testing.T) {
+	chain := weightedChain(1, 0)
+	chain.Tasks[0].Transition.Branches[1].Weight = 0
+	env := setupTestEnv(&taskengine.MockTaskExecutor{})
+	for range 50 {
+		_, _, history, err := env.ExecEnv(context.Background(), chain, "hi", taskengine.DataTypeString)
+		require.NoError(t, err)
+		assert.Equal(t, "variant_a", history[0].Variant)
+	}
+}
+
+func TestUnit_ValidateChain_Weighted(t *testing.T) {
+	assert.Empty(t, taskengine.ValidateChain(weightedChain(90, 10)))
+
+	chain := weightedChain(90, 0)
+	d := findDiagnostic(taskengine.ValidateChain(chain), "route", "transition.branches[1].weight")
+	require.NotNil(t, d)
+	assert.Equal(t, taskengine.SeverityError, d.Severity)
+
+	chain = weightedChain(90, 10)
+	chain.Tasks[0].Transition.Branches[1] = taskengine.TransitionBranch{Operator: taskengine.OpDefault, Goto: "variant_b"}
+	d = findDiagnostic(taskengine.ValidateChain(chain), "route", "transition.branches")
+	require.NotNil(t, d)
+	assert.Contains(t, d.Message, "cannot be mixed")
+}
+
+func promptVariantChain(weightA, weightB float64) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "prompts",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "answer", Handler: taskengine.HandlePromptToString, PromptVariants: []taskengine.PromptVariant{
+				{Name: "short", Weight: weightA, PromptTemplate: "briefly: {{.input}}"},
+				{Name: "long", Weight: weightB, PromptTemplate: "in detail: {{.input}}"},
+			}, Transition: goTo(taskengine.TermEnd)},
+		},
+	}
+}
+
+func TestPromptVariants_SendsAndRecordsVariant(t *testing.T) {
+	exec := &taskengine.MockTaskExecutor{MockOutput: "ok"}
+	env := setupTestEnv(exec)
+	want := map[string]string{"short": "briefly: hi", "long": "in detail: hi"}
+	seen := map[string]int{}
+	for range 200 {
+		_, _, history, err := env.ExecEnv(context.Background(), promptVariantChain(1, 1), "hi", taskengine.DataTypeString)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		variant := history[0].PromptVariant
+		assert.Equal(t, want[variant], exec.CalledWithInput, "the recorded variant is the prompt sent")
+		seen[variant]++
+	}
+	assert.Positive(t, seen["short"])
+	assert.Positive(t, seen["long"])
+
+	_, _, history, err := env.ExecEnv(context.Background(), promptVariantChain(1, 0), "hi", taskengine.DataTypeString)
+	require.NoError(t, err)
+	assert.Equal(t, "short", history[0].PromptVariant, "a zero weight is never drawn")
+}
+
+func TestUnit_ValidateChain_PromptVariants(t *testing.T) {
+	assert.Empty(t, taskengine.ValidateChain(promptVariantChain(50, 50)))
+
+	chain := promptVariantChain(50, 0)
+	chain.Tasks[0].PromptVariants[1].Name = "short"
+	diags := taskengine.ValidateChain(chain)
+	d := findDiagnostic(diags, "answer", "prompt_variants[1].weight")
+	require.NotNil(t, d)
+	assert.Equal(t, taskengine.SeverityError, d.Severity)
+	d = findDiagnostic(diags, "answer", "prompt_variants[1].name")
+	require.NotNil(t, d)
+	assert.Contains(t, d.Message, "duplicate")
+
+	chain = promptVariantChain(50, 50)
+	chain.Tasks[0].PromptTemplate = "{{.input}}"
+	d = findDiagnostic(taskengine.ValidateChain(chain), "answer", "prompt_template")
+	require.NotNil(t, d)
+	assert.Equal(t, taskengine.SeverityWarning, d.Severity)
+}
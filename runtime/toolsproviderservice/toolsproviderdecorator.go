@@ -169,6 +169,21 @@ func (d *activityTrackerDecorator) List(ctx context.Context, createdAtCursor *ti
 	return d.service.List(ctx, createdAtCursor, limit)
 }
 
+func (d *activityTrackerDecorator) Stats(ctx context.Context) ([]ToolUsage, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"stats",
+		"tools_usage",
+	)
+	defer endFn()
+
+	stats, err := d.service.Stats(ctx)
+	if err != nil {
+		reportErrFn(err)
+	}
+	return stats, err
+}
+
 // WithActivityTracker wraps a Service with activity tracking functionality.
 func WithActivityTracker(service Service, tracker libtracker.ActivityTracker) Service {
 	return &activityTrackerDecorator{
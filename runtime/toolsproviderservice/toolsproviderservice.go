@@ -35,6 +35,8 @@ type Service interface {
 	List(ctx context.Context, createdAtCursor *time.Time, limit int) ([]*runtimetypes.RemoteTools, error)
 	GetSchemasForSupportedTools(ctx context.Context) (map[string]*openapi3.T, error)
 	ListLocalTools(ctx context.Context) ([]LocalTools, error)
+	// Stats returns the usage statistics recorded by WithUsageTracking.
+	Stats(ctx context.Context) ([]ToolUsage, error)
 }
 
 type LocalTools struct {
@@ -0,0 +1,146 @@
+package toolsproviderservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	libdb "github.com/contenox/contenox/libdbexec"
+	"github.com/contenox/contenox/runtime/runtimetypes"
+	"github.com/contenox/contenox/runtime/taskengine"
+)
+
+// usageKVPrefix namespaces tool usage statistics in the KV table.
+const usageKVPrefix = "tool-usage:"
+
+// ToolUsage aggregates the invocations of one tool of a tools.
+type ToolUsage struct {
+	Tools          string `json:"tools"`
+	Tool           string `json:"tool"`
+	Calls          int64  `json:"calls"`
+	Errors         int64  `json:"errors"`
+	TotalLatencyMS int64  `json:"totalLatencyMs"`
+	MaxLatencyMS   int64  `json:"maxLatencyMs"`
+	// Chains and Models count the invocations per chain ID and per model that
+	// requested the call. Calls made outside a chain or by a tools task are
+	// not attributed to a model.
+	Chains     map[string]int64 `json:"chains,omitempty"`
+	Models     map[string]int64 `json:"models,omitempty"`
+	LastError  string           `json:"lastError,omitempty"`
+	LastUsedAt time.Time        `json:"lastUsedAt"`
+}
+
+// ErrorRate is the share of failed calls in [0,1].
+func (u *ToolUsage) ErrorRate() float64 {
+	if u.Calls == 0 {
+		return 0
+	}
+	return float64(u.Errors) / float64(u.Calls)
+}
+
+// AvgLatency is the mean latency of all calls.
+func (u *ToolUsage) AvgLatency() time.Duration {
+	if u.Calls == 0 {
+		return 0
+	}
+	return time.Duration(u.TotalLatencyMS/u.Calls) * time.Millisecond
+}
+
+func (u *ToolUsage) add(origin taskengine.ToolCallOrigin, latency time.Duration, err error, at time.Time) {
+	ms := latency.Milliseconds()
+	u.Calls++
+	u.TotalLatencyMS += ms
+	u.MaxLatencyMS = max(u.MaxLatencyMS, ms)
+	u.LastUsedAt = at
+	if err != nil {
+		u.Errors++
+		u.LastError = shortenToolsListError(err)
+	}
+	if origin.ChainID != "" {
+		if u.Chains == nil {
+			u.Chains = map[string]int64{}
+		}
+		u.Chains[origin.ChainID]++
+	}
+	if origin.Model != "" {
+		if u.Models == nil {
+			u.Models = map[string]int64{}
+		}
+		u.Models[origin.Model]++
+	}
+}
+
+func usageKey(tools, tool string) string {
+	return usageKVPrefix + tools + "." + tool
+}
+
+// WithUsageTracking wraps repo so every tool call is counted in the usage
+// statistics returned by Service.Stats. Recording is best-effort: failures
+// are logged and never fail the call.
+func WithUsageTracking(repo taskengine.ToolsRepo, db libdb.DBManager) taskengine.ToolsRepo {
+	return &usageTrackingRepo{ToolsRepo: repo, db: db}
+}
+
+type usageTrackingRepo struct {
+	taskengine.ToolsRepo
+	db libdb.DBManager
+	// mu serializes the read-modify-write of the usage entries.
+	mu sync.Mutex
+}
+
+func (r *usageTrackingRepo) Exec(ctx context.Context, startingTime time.Time, input any, debug bool, args *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	start := time.Now()
+	out, dt, err := r.ToolsRepo.Exec(ctx, startingTime, input, debug, args)
+	if args != nil {
+		origin := taskengine.ToolCallOriginFromContext(ctx)
+		if recErr := r.record(context.WithoutCancel(ctx), args.Name, args.ToolName, origin, time.Since(start), err); recErr != nil {
+			log.Printf("tool usage: failed to record %s.%s: %v", args.Name, args.ToolName, recErr)
+		}
+	}
+	return out, dt, err
+}
+
+func (r *usageTrackingRepo) record(ctx context.Context, tools, tool string, origin taskengine.ToolCallOrigin, latency time.Duration, callErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	store := runtimetypes.New(r.db.WithoutTransaction())
+	usage := ToolUsage{Tools: tools, Tool: tool}
+	if err := store.GetKV(ctx, usageKey(tools, tool), &usage); err != nil && !errors.Is(err, libdb.ErrNotFound) {
+		return err
+	}
+	usage.add(origin, latency, callErr, time.Now().UTC())
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("encode tool usage: %w", err)
+	}
+	return store.SetKV(ctx, usageKey(tools, tool), data)
+}
+
+// Stats returns the usage statistics of every tool that was called, most
+// called first.
+func (s *service) Stats(ctx context.Context) ([]ToolUsage, error) {
+	kvs, err := runtimetypes.New(s.dbInstance.WithoutTransaction()).ListKVPrefix(ctx, usageKVPrefix, nil, runtimetypes.MAXLIMIT)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]ToolUsage, 0, len(kvs))
+	for _, kv := range kvs {
+		var usage ToolUsage
+		if err := json.Unmarshal(kv.Value, &usage); err != nil {
+			return nil, fmt.Errorf("decode tool usage %s: %w", kv.Key, err)
+		}
+		stats = append(stats, usage)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Calls != stats[j].Calls {
+			return stats[i].Calls > stats[j].Calls
+		}
+		return stats[i].Tools+"."+stats[i].Tool < stats[j].Tools+"."+stats[j].Tool
+	})
+	return stats, nil
+}
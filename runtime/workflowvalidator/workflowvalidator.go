@@ -82,6 +82,9 @@ func (v *Validator) buildTaskGraph(tasks []taskengine.TaskDefinition) map[string
 		if task.Transition.OnFailure != "" && task.Transition.OnFailure != "end" {
 			nextTasks = append(nextTasks, task.Transition.OnFailure)
 		}
+		if task.Transition.OnError != "" {
+			nextTasks = append(nextTasks, task.Transition.OnError)
+		}
 
 		graph[task.ID] = nextTasks
 	}
@@ -118,7 +121,7 @@ func (v *Validator) canTransitionToEnd(task taskengine.TaskDefinition) bool {
 
 func (v *Validator) taskReturnsCompatibleType(task taskengine.TaskDefinition, profile ValidationProfile) bool {
 	switch task.Handler {
-	case taskengine.HandleChatCompletion, taskengine.HandleExecuteToolCalls:
+	case taskengine.HandleChatCompletion, taskengine.HandleExecuteToolCalls, taskengine.HandleAgentLoop:
 		return profile.RequiredReturnType == "chat_history"
 	case taskengine.HandlePromptToString:
 		// Check if this task composes with chat_history